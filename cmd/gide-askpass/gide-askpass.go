@@ -0,0 +1,31 @@
+// Copyright (c) 2018, The gide / GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// gide-askpass is the GIT_ASKPASS / SSH_ASKPASS helper for the commands run
+// by gide: it sends the prompt given as its arg to the gide that ran the
+// command, which shows a dialog for the credential, and prints the answer
+// for git or ssh.  It fails if run outside of gide, or canceled.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/goki/gide/gide"
+)
+
+func main() {
+	sock := os.Getenv(gide.AskPassEnvVar)
+	if sock == "" {
+		fmt.Fprintf(os.Stderr, "gide-askpass: not run from gide (%v is not set)\n", gide.AskPassEnvVar)
+		os.Exit(1)
+	}
+	ans, err := gide.SendAskPassRequest(sock, strings.Join(os.Args[1:], " "))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gide-askpass: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(ans)
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2018, The gide / GoKi Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// gide-open opens the files and / or project given as args in the running
+// gide, in the window of the project each file is in, and otherwise starts
+// gide with them.  Files can be given as path:line:col, e.g., for use as an
+// $EDITOR or with git jump, or as gide:// URLs (gide://open?file=...&line=...),
+// as the URL handler registered by Register URL Scheme in the preferences.
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/goki/gide/gide"
+)
+
+func main() {
+	req := gide.ParseOpenArgs(os.Args[1:])
+	if err := gide.SendOpenRequest(req); err == nil {
+		return
+	}
+	cmd := exec.Command("gide", os.Args[1:]...)
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "gide-open: no running gide, and could not start it: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -5,10 +5,11 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gimain"
@@ -40,25 +41,44 @@ Version: ` + gide.Prefs.VersionInfo())
 
 	var path string
 	var proj string
+	var diff, merge bool
+	req := &gide.OpenRequest{}
 
 	// process command args
 	if len(os.Args) > 1 {
 		flag.StringVar(&path, "path", "", "path to open -- can be to a directory or a filename within the directory ")
 		flag.StringVar(&proj, "proj", "", "project file to open -- typically has .gide extension")
+		flag.BoolVar(&diff, "diff", false, "show the diffs of the two files given as args, as git difftool: gide -diff $LOCAL $REMOTE")
+		flag.BoolVar(&merge, "merge", false, "merge the base, local, remote and merged files given as args, as git mergetool: gide -merge $BASE $LOCAL $REMOTE $MERGED -- exits with status 0 if resolved, 1 if not")
 		// todo: other args?
 		flag.Parse()
-		if path == "" && proj == "" {
-			if flag.NArg() > 0 {
-				ext := strings.ToLower(filepath.Ext(flag.Arg(0)))
-				if ext == ".gide" {
-					proj = flag.Arg(0)
-				} else {
-					path = flag.Arg(0)
-				}
-			}
+		if diff || merge {
+			mainTool(diff)
+			return
+		}
+		// other args: a project file or directory, and / or files to open,
+		// optionally at path:line:col, in the project they are in
+		req = gide.ParseOpenArgs(flag.Args())
+		if proj != "" {
+			req.Proj, _ = filepath.Abs(proj)
+		} else if path != "" {
+			req.Path, _ = filepath.Abs(path)
+		}
+	}
+
+	if gide.Prefs.SingleInstance {
+		if len(os.Args) > 1 && gide.SendOpenRequest(req) == nil {
+			return // opened in the running gide
+		}
+		if srv, err := gide.StartInstanceServer(gidev.PostOpenReq); err == nil {
+			defer srv.Stop()
 		}
 	}
 
+	if srv, err := gide.StartAskPassServer(gidev.AskPass); err == nil {
+		defer srv.Stop()
+	}
+
 	recv := gi.Node2DBase{}
 	recv.InitName(&recv, "gide_dummy")
 
@@ -74,15 +94,30 @@ Version: ` + gide.Prefs.VersionInfo())
 		}
 	})
 
-	if proj != "" {
-		proj, _ = filepath.Abs(proj)
-		gidev.OpenGideProj(proj)
-	} else {
-		if path != "" {
-			path, _ = filepath.Abs(path)
-		}
-		gidev.NewGideProjPath(path)
-	}
+	gidev.OpenReq(req)
 	// above NewGideProj calls will have added to WinWait..
 	gi.WinWait.Wait()
 }
+
+// mainTool runs gide as a diff tool, or a merge tool, on the files of the
+// args, exiting with the ToolStatus when its window is closed
+func mainTool(diff bool) {
+	args := flag.Args()
+	var err error
+	switch {
+	case diff && len(args) == 2:
+		err = gidev.DiffTool(args[0], args[1])
+	case !diff && len(args) == 4:
+		err = gidev.MergeTool(args[0], args[1], args[2], args[3])
+	case diff:
+		err = errors.New("usage: gide -diff fileA fileB")
+	default:
+		err = errors.New("usage: gide -merge base local remote merged")
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	gi.WinWait.Wait()
+	os.Exit(gidev.ToolStatus)
+}
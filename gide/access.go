@@ -0,0 +1,309 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/token"
+)
+
+// AccessPrefs are the accessibility preferences, for using gide with the
+// keyboard only, with low vision, or with a speech synthesizer.  GoGi has
+// no bridge to the accessibility APIs of the OS, so screen readers do not
+// see its widgets: gide announces the focus itself, in the statusbar and
+// through the SpeakCmd.
+type AccessPrefs struct {
+	HighContrast bool          `desc:"use the HighContrast color scheme (white and yellow on black) and highlighting style -- the colors of the GoGi preferences are restored when turned off"`
+	FocusRing    bool          `desc:"draw a thick ring, in the link color, around the widget with the keyboard focus, so that it is always visible"`
+	Announce     bool          `desc:"announce the widget with the keyboard focus, its role, name and panel, when moving between the panels and with Describe Focus (Shift+F1 by default), in the statusbar and with the SpeakCmd"`
+	SpeakCmd     string        `desc:"command speaking the announcements, given as its last argument, e.g., spd-say on Linux, say on macOS -- empty to only show them in the statusbar"`
+	PrevColors   gi.ColorPrefs `view:"-" desc:"the colors of the GoGi preferences before HighContrast was turned on, restored when it is turned off"`
+}
+
+// Defaults sets the default speech command of the OS
+func (ap *AccessPrefs) Defaults() {
+	switch runtime.GOOS {
+	case "darwin":
+		ap.SpeakCmd = "say"
+	case "linux":
+		ap.SpeakCmd = "spd-say"
+	}
+}
+
+// Apply switches to the HighContrast colors, or back to the previous ones,
+// and adds or removes the focus ring of all the windows
+func (ap *AccessPrefs) Apply() {
+	for _, w := range gi.AllWindows {
+		if ap.ApplyFocusRing(w.Viewport) {
+			w.FullReRender()
+		}
+	}
+	hc := InstallHighContrast()
+	switch {
+	case ap.HighContrast && gi.Prefs.Colors != *hc:
+		ap.PrevColors = gi.Prefs.Colors
+		gi.Prefs.Colors = *hc
+		gi.Prefs.UpdateAll()
+	case !ap.HighContrast && gi.Prefs.Colors == *hc && ap.PrevColors.HiStyle != "":
+		gi.Prefs.Colors = ap.PrevColors
+		gi.Prefs.UpdateAll()
+	}
+}
+
+// ApplyFocusRing adds the FocusRingCSS to the style sheet of given window
+// viewport if FocusRing is on, else removes it -- returns true if changed
+func (ap *AccessPrefs) ApplyFocusRing(vp *gi.Viewport2D) bool {
+	if vp == nil {
+		return false
+	}
+	_, has := vp.CSS["filetreeview"]
+	if ap.FocusRing == has {
+		return false
+	}
+	for k, v := range FocusRingCSS() {
+		if !ap.FocusRing {
+			delete(vp.CSS, k)
+			continue
+		}
+		if vp.CSS == nil {
+			vp.CSS = ki.Props{}
+		}
+		vp.CSS[k] = v
+	}
+	return true
+}
+
+// HighContrastScheme is the name of the color scheme of the HighContrast
+// preference, also offered among the GoGi color schemes
+var HighContrastScheme = "HighContrast"
+
+// HighContrastHiStyle is the name of the highlighting style of the
+// HighContrast color scheme
+var HighContrastHiStyle = "gide-high-contrast"
+
+// HighContrastColors returns the colors of the HighContrast scheme: white
+// text on black, white borders, yellow icons and links, and a saturated
+// blue selection -- all at least 7:1 contrast
+func HighContrastColors() *gi.ColorPrefs {
+	cp := &gi.ColorPrefs{}
+	cp.HiStyle = gi.HiStyleName(HighContrastHiStyle)
+	cp.Font.SetUInt8(255, 255, 255, 255)
+	cp.Background.SetUInt8(0, 0, 0, 255)
+	cp.Shadow.SetUInt8(64, 64, 64, 255)
+	cp.Border.SetUInt8(255, 255, 255, 255)
+	cp.Control.SetUInt8(0, 0, 0, 255)
+	cp.Icon.SetUInt8(255, 255, 0, 255)
+	cp.Select.SetUInt8(0, 48, 192, 255)
+	cp.Highlight.SetUInt8(96, 72, 0, 255)
+	cp.Link.SetUInt8(255, 255, 0, 255)
+	return cp
+}
+
+// HighContrastStyle returns the highlighting style of the HighContrast
+// scheme: pure, saturated colors on black, keywords and functions bold
+func HighContrastStyle() *histyle.Style {
+	clr := func(r, g, b uint8) gist.Color {
+		c := gist.Color{}
+		c.SetUInt8(r, g, b, 255)
+		return c
+	}
+	hs := histyle.Style{}
+	hs[token.Background] = &histyle.StyleEntry{Color: clr(255, 255, 255), Background: clr(0, 0, 0)}
+	hs[token.Text] = &histyle.StyleEntry{Color: clr(255, 255, 255)}
+	hs[token.Comment] = &histyle.StyleEntry{Color: clr(0, 255, 0), Italic: histyle.Yes}
+	hs[token.Keyword] = &histyle.StyleEntry{Color: clr(255, 255, 0), Bold: histyle.Yes}
+	hs[token.KeywordType] = &histyle.StyleEntry{Color: clr(0, 255, 255), Bold: histyle.Yes}
+	hs[token.NameBuiltin] = &histyle.StyleEntry{Color: clr(0, 255, 255)}
+	hs[token.NameFunction] = &histyle.StyleEntry{Color: clr(255, 255, 255), Bold: histyle.Yes}
+	hs[token.NameType] = &histyle.StyleEntry{Color: clr(0, 255, 255)}
+	hs[token.LitStr] = &histyle.StyleEntry{Color: clr(255, 160, 255)}
+	hs[token.LitNum] = &histyle.StyleEntry{Color: clr(255, 192, 96)}
+	hs[token.Literal] = &histyle.StyleEntry{Color: clr(255, 192, 96)}
+	hs[token.Error] = &histyle.StyleEntry{Color: clr(255, 255, 255), Background: clr(192, 0, 0)}
+	hs[token.TextStyleHeading] = &histyle.StyleEntry{Color: clr(255, 255, 0), Bold: histyle.Yes}
+	hs[token.TextStyleLink] = &histyle.StyleEntry{Color: clr(255, 255, 0), Underline: histyle.Yes}
+	hs[token.TextStyleInserted] = &histyle.StyleEntry{Color: clr(0, 255, 0)}
+	hs[token.TextStyleDeleted] = &histyle.StyleEntry{Color: clr(255, 96, 96)}
+	return &hs
+}
+
+// InstallHighContrast adds the HighContrast scheme to the GoGi color
+// schemes, and its highlighting style to the standard ones, if not yet
+// there, returning the scheme
+func InstallHighContrast() *gi.ColorPrefs {
+	if histyle.AvailStyles == nil {
+		histyle.Init()
+	}
+	if _, has := histyle.StdStyles[HighContrastHiStyle]; !has {
+		if histyle.StdStyles == nil {
+			histyle.StdStyles = histyle.Styles{}
+		}
+		histyle.StdStyles[HighContrastHiStyle] = HighContrastStyle()
+		histyle.MergeAvailStyles()
+	}
+	if gi.Prefs.ColorSchemes == nil {
+		gi.Prefs.ColorSchemes = gi.DefaultColorSchemes()
+	}
+	hc, has := gi.Prefs.ColorSchemes[HighContrastScheme]
+	if !has {
+		hc = HighContrastColors()
+		gi.Prefs.ColorSchemes[HighContrastScheme] = hc
+	}
+	return hc
+}
+
+// FocusRingCSS returns the style sheet drawing the focus ring around the
+// widgets with the keyboard focus, for the CSS of a window
+func FocusRingCSS() ki.Props {
+	ring := ki.Props{
+		"border-width": units.NewPx(3),
+		"border-style": "solid",
+		"border-color": &gi.Prefs.Colors.Link,
+	}
+	return ki.Props{
+		"action":       ki.Props{":focus": ring},
+		"button":       ki.Props{":focus": ring},
+		"checkbox":     ki.Props{":focus": ring},
+		"combobox":     ki.Props{":focus": ring},
+		"textfield":    ki.Props{":focus": ring},
+		"textview":     ki.Props{":focus": ring},
+		"treeview":     ki.Props{":focus": ring},
+		"filetreeview": ki.Props{":focus": ring},
+	}
+}
+
+// AccessName returns the accessible name of given widget, as announced:
+// its role (button, editor, file..), its name (label, text, file) and
+// state, from the access-name property if set
+func AccessName(k ki.Ki) string {
+	if k == nil || k.This() == nil {
+		return ""
+	}
+	if an, ok := k.Prop("access-name").(string); ok && an != "" {
+		return an
+	}
+	tip := ""
+	if nii, ok := k.(gi.Node2D); ok {
+		if wb := nii.AsWidget(); wb != nil {
+			tip = wb.Tooltip
+		}
+	}
+	switch {
+	case k.Embed(giv.KiT_TextView) != nil:
+		tv := k.Embed(giv.KiT_TextView).(*giv.TextView)
+		nm := "text"
+		if tv.Buf != nil && tv.Buf.Filename != "" {
+			nm = filepath.Base(string(tv.Buf.Filename))
+		}
+		st := fmt.Sprintf("line %d, column %d", tv.CursorPos.Ln+1, tv.CursorPos.Ch+1)
+		if tv.IsInactive() {
+			return fmt.Sprintf("output %v, %v", nm, st)
+		}
+		return fmt.Sprintf("editor %v, %v", nm, st)
+	case k.Embed(giv.KiT_FileTreeView) != nil:
+		ftv := k.Embed(giv.KiT_FileTreeView).(*giv.FileTreeView)
+		role := "file"
+		if fn := ftv.FileNode(); fn != nil && fn.IsDir() {
+			role = "folder, expanded"
+			if ftv.IsClosed() {
+				role = "folder, collapsed"
+			}
+		}
+		return fmt.Sprintf("%v %v", role, strings.TrimSpace(ftv.Label()))
+	case k.Embed(giv.KiT_TreeView) != nil:
+		tv := k.Embed(giv.KiT_TreeView).(*giv.TreeView)
+		return "tree item " + tv.Label()
+	case k.Embed(gi.KiT_CheckBox) != nil:
+		cb := k.Embed(gi.KiT_CheckBox).(*gi.CheckBox)
+		st := "not checked"
+		if cb.IsChecked() {
+			st = "checked"
+		}
+		return accessJoin("check box", cb.Text, tip, st)
+	case k.Embed(gi.KiT_ComboBox) != nil:
+		cb := k.Embed(gi.KiT_ComboBox).(*gi.ComboBox)
+		return accessJoin("combo box", cb.Text, tip, "")
+	case k.Embed(gi.KiT_Action) != nil:
+		ac := k.Embed(gi.KiT_Action).(*gi.Action)
+		role := "button"
+		if ac.HasMenu() {
+			role = "menu"
+		}
+		st := ""
+		if ac.IsInactive() {
+			st = "unavailable"
+		}
+		return accessJoin(role, ac.Text, tip, st)
+	case k.Embed(gi.KiT_Button) != nil:
+		bt := k.Embed(gi.KiT_Button).(*gi.Button)
+		return accessJoin("button", bt.Text, tip, "")
+	case k.Embed(gi.KiT_TextField) != nil:
+		tf := k.Embed(gi.KiT_TextField).(*gi.TextField)
+		nm := tip
+		if nm == "" {
+			nm = tf.Placeholder
+		}
+		return accessJoin("text field", nm, "", tf.Txt)
+	case k.Embed(gi.KiT_SpinBox) != nil:
+		sb := k.Embed(gi.KiT_SpinBox).(*gi.SpinBox)
+		return accessJoin("spin box", tip, "", fmt.Sprintf("%v", sb.Value))
+	}
+	return accessJoin(strings.ToLower(k.Name()), tip, "", "")
+}
+
+// accessJoin joins the role, name (else the tooltip) and state of an
+// accessible name
+func accessJoin(role, name, tip, state string) string {
+	if name == "" {
+		name = tip
+	}
+	s := role
+	if name != "" {
+		s += " " + name
+	}
+	if state != "" {
+		s += ", " + state
+	}
+	return s
+}
+
+var (
+	speakMu  sync.Mutex
+	speakCmd *exec.Cmd
+)
+
+// Speak speaks given text with given speech command, stopping the previous
+// speech, if any -- does nothing if the command is empty
+func Speak(cmdstr, text string) error {
+	args := strings.Fields(cmdstr)
+	if len(args) == 0 || text == "" {
+		return nil
+	}
+	speakMu.Lock()
+	defer speakMu.Unlock()
+	if speakCmd != nil && speakCmd.Process != nil {
+		speakCmd.Process.Kill()
+	}
+	cmd := exec.Command(args[0], append(args[1:], text)...)
+	if err := cmd.Start(); err != nil {
+		speakCmd = nil
+		return err
+	}
+	speakCmd = cmd
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,364 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// ActionLogDirName is the directory, in the preferences directory, of the
+// action logs of the projects
+var ActionLogDirName = "actionlogs"
+
+// ActionLogMax is the maximum number of actions kept in the log of a
+// project -- the file is trimmed to it when opened with twice as many
+var ActionLogMax = 5000
+
+// the kinds of the actions logged
+const (
+	ActionSave     = "save"
+	ActionCmd      = "cmd"
+	ActionVcs      = "vcs"
+	ActionFile     = "file"
+	ActionRefactor = "refactor"
+)
+
+// ActionEntry is an operation done in a project, as logged in its action
+// log
+type ActionEntry struct {
+	Time time.Time `json:"time" desc:"when it was done"`
+	Kind string    `json:"kind" desc:"kind of operation: save, cmd, vcs, file or refactor"`
+	Op   string    `json:"op" desc:"the operation: save, the name of the command, commit, rename.."`
+	Args string    `json:"args,omitempty" desc:"its arguments: the file saved, the command line, the commit message.."`
+	Exit int       `json:"exit" desc:"exit code: 0 if successful, -1 if it could not run"`
+	Err  string    `json:"err,omitempty" desc:"error, if it failed"`
+}
+
+// Failed returns true if the operation failed
+func (ae *ActionEntry) Failed() bool {
+	return ae.Exit != 0 || ae.Err != ""
+}
+
+// ActionExit returns the exit code and error message of given error of an
+// operation: the exit code of a command, -1 for other errors
+func ActionExit(err error) (int, string) {
+	if err == nil {
+		return 0, ""
+	}
+	if ee, ok := err.(*exec.ExitError); ok {
+		return ee.ExitCode(), err.Error()
+	}
+	return -1, err.Error()
+}
+
+// ActionLogFileName returns the file of the action log of given project
+// root, one JSON entry per line
+func ActionLogFileName(root string) string {
+	h := fnv.New64a()
+	h.Write([]byte(root))
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), ActionLogDirName, fmt.Sprintf("%s-%x.jsonl", filepath.Base(root), h.Sum64()))
+}
+
+// ActionLog is the log of the significant operations done in a project --
+// files saved, commands run, version control actions, files renamed or
+// deleted, refactorings -- kept in a file, safe for concurrent use
+type ActionLog struct {
+	Path    string        `desc:"path of the log file -- empty if not kept in a file"`
+	Entries []ActionEntry `desc:"the actions, oldest first -- at most ActionLogMax"`
+	Mu      sync.Mutex    `json:"-" xml:"-" view:"-" desc:"mutex protecting the log"`
+}
+
+// Open reads the action log of the project at given root, trimming its
+// file to the last ActionLogMax actions if it has twice as many
+func (al *ActionLog) Open(root string) error {
+	al.Mu.Lock()
+	defer al.Mu.Unlock()
+	al.Path = ActionLogFileName(root)
+	al.Entries = nil
+	b, err := ioutil.ReadFile(al.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	n := 0
+	for sc.Scan() {
+		var ae ActionEntry
+		if json.Unmarshal(sc.Bytes(), &ae) == nil {
+			al.Entries = append(al.Entries, ae)
+			n++
+		}
+	}
+	if len(al.Entries) > ActionLogMax {
+		al.Entries = append(al.Entries[:0:0], al.Entries[len(al.Entries)-ActionLogMax:]...)
+	}
+	if n < 2*ActionLogMax {
+		return nil
+	}
+	var buf bytes.Buffer
+	for i := range al.Entries {
+		b, _ := json.Marshal(&al.Entries[i])
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return ioutil.WriteFile(al.Path, buf.Bytes(), 0644)
+}
+
+// Add logs an operation of given kind, name and arguments, failed if err
+// is not nil, appending it to the log file
+func (al *ActionLog) Add(kind, op, args string, err error) error {
+	ae := ActionEntry{Time: time.Now(), Kind: kind, Op: op, Args: args}
+	ae.Exit, ae.Err = ActionExit(err)
+	al.Mu.Lock()
+	defer al.Mu.Unlock()
+	al.Entries = append(al.Entries, ae)
+	if n := len(al.Entries); n > ActionLogMax {
+		al.Entries = append(al.Entries[:0:0], al.Entries[n-ActionLogMax:]...)
+	}
+	if al.Path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(al.Path), 0755); err != nil {
+		return err
+	}
+	f, ferr := os.OpenFile(al.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if ferr != nil {
+		return ferr
+	}
+	defer f.Close()
+	b, _ := json.Marshal(&ae)
+	_, werr := f.Write(append(b, '\n'))
+	return werr
+}
+
+// Query returns the actions matching given query, most recent first: the
+// words of the query must all be in the operation, its arguments or error
+// (regardless of case), except for kind:k (of kind k, e.g., kind:cmd),
+// since:d (within duration d, e.g., since:1h, or since date d, e.g.,
+// since:2006-01-02) and exit:n (with exit code n, or exit:fail for the
+// failed ones)
+func (al *ActionLog) Query(q string) ([]ActionEntry, error) {
+	var words []string
+	var kind, exit string
+	var since time.Time
+	for _, f := range strings.Fields(q) {
+		switch {
+		case strings.HasPrefix(f, "kind:"):
+			kind = strings.TrimPrefix(f, "kind:")
+		case strings.HasPrefix(f, "exit:"):
+			exit = strings.TrimPrefix(f, "exit:")
+		case strings.HasPrefix(f, "since:"):
+			ss := strings.TrimPrefix(f, "since:")
+			if d, err := time.ParseDuration(ss); err == nil {
+				since = time.Now().Add(-d)
+			} else if t, err := time.ParseInLocation("2006-01-02", ss, time.Local); err == nil {
+				since = t
+			} else {
+				return nil, fmt.Errorf("since: must be a duration (e.g., 90m) or a date (e.g., 2006-01-02): %v", ss)
+			}
+		default:
+			words = append(words, strings.ToLower(f))
+		}
+	}
+	al.Mu.Lock()
+	defer al.Mu.Unlock()
+	var res []ActionEntry
+	for i := len(al.Entries) - 1; i >= 0; i-- {
+		ae := al.Entries[i]
+		if !since.IsZero() && ae.Time.Before(since) {
+			break
+		}
+		if kind != "" && ae.Kind != kind {
+			continue
+		}
+		if exit == "fail" && !ae.Failed() || exit != "" && exit != "fail" && strconv.Itoa(ae.Exit) != exit {
+			continue
+		}
+		txt := strings.ToLower(ae.Op + " " + ae.Args + " " + ae.Err)
+		match := true
+		for _, w := range words {
+			if !strings.Contains(txt, w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			res = append(res, ae)
+		}
+	}
+	return res, nil
+}
+
+// IsVcsCmd returns true if given command is a version control command,
+// logged as a vcs action
+func IsVcsCmd(cmdNm string) bool {
+	return strings.HasSuffix(cmdNm, " Git") || strings.HasSuffix(cmdNm, " SVN") || strings.HasSuffix(cmdNm, " Hg")
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    ActionLogView
+
+// ActionLogView shows the action log of a project, most recent first,
+// filtered by a query (see ActionLog.Query)
+type ActionLogView struct {
+	gi.Layout
+	Gide  Gide       `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Log   *ActionLog `json:"-" xml:"-" copy:"-" desc:"the log shown"`
+	Query string     `desc:"the query filtering the actions shown"`
+}
+
+var KiT_ActionLogView = kit.Types.AddType(&ActionLogView{}, ActionLogViewProps)
+
+// Config configures the view to show given log
+func (av *ActionLogView) Config(ge Gide, al *ActionLog) {
+	av.Gide = ge
+	av.Log = al
+	av.Lay = gi.LayoutVert
+	av.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "actlogbar")
+	config.Add(gi.KiT_Layout, "actlogtext")
+	mods, updt := av.ConfigChildren(config)
+	if !mods {
+		updt = av.UpdateStart()
+	}
+	av.ConfigToolbar()
+	ConfigOutputTextView(av.TextViewLay())
+	av.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (av *ActionLogView) ToolBar() *gi.ToolBar {
+	return av.ChildByName("actlogbar", 0).(*gi.ToolBar)
+}
+
+// TextViewLay returns the layout of the TextView
+func (av *ActionLogView) TextViewLay() *gi.Layout {
+	return av.ChildByName("actlogtext", 1).(*gi.Layout)
+}
+
+// TextView returns the TextView of the actions
+func (av *ActionLogView) TextView() *giv.TextView {
+	return av.TextViewLay().ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds the query field and the toolbar actions
+func (av *ActionLogView) ConfigToolbar() {
+	tb := av.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	gi.AddNewLabel(tb, "query-lbl", "Query:")
+	qf := gi.AddNewTextField(tb, "query")
+	qf.SetStretchMaxWidth()
+	qf.Tooltip = "words that the operations, their arguments or errors must all have, and kind:k (save, cmd, vcs, file or refactor), since:d (e.g., since:2h or since:2006-01-02) and exit:n (or exit:fail) -- Enter to apply"
+	qf.SetText(av.Query)
+	qf.TextFieldSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) {
+			avv, _ := recv.Embed(KiT_ActionLogView).(*ActionLogView)
+			avv.Query = send.(*gi.TextField).Text()
+			avv.ShowActions()
+		}
+	})
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "show the actions logged since"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ActionLogView).(*ActionLogView)
+			avv.ShowActions()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Open File", Icon: "file-open", Tooltip: "open the log file, one JSON entry per line"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ActionLogView).(*ActionLogView)
+			if avv.Log.Path == "" {
+				return
+			}
+			if fn := avv.Gide.FileNodeForFile(avv.Log.Path, true); fn != nil {
+				avv.Gide.NextViewFileNode(fn)
+			}
+		})
+}
+
+// ShowActions shows the actions matching the query, most recent first,
+// with links to the files saved and to the outputs of the commands
+func (av *ActionLogView) ShowActions() {
+	if av.Log == nil {
+		return
+	}
+	aes, err := av.Log.Query(av.Query)
+	if err != nil {
+		av.Gide.SetStatus(html.EscapeString(err.Error()))
+		return
+	}
+	root := string(av.Gide.ProjPrefs().ProjRoot)
+	outlns := make([][]byte, 0, len(aes))
+	outmus := make([][]byte, 0, len(aes))
+	for _, ae := range aes {
+		tm := ae.Time.Format("2006-01-02 15:04:05")
+		args := ae.Args
+		if rel, err := filepath.Rel(root, args); err == nil && filepath.IsAbs(args) && !RelOutside(rel) {
+			args = rel
+		}
+		res := ""
+		if ae.Failed() {
+			res = fmt.Sprintf("  -> exit %d: %v", ae.Exit, ae.Err)
+		}
+		ln := fmt.Sprintf("%v [%v] %v %v%v", tm, ae.Kind, ae.Op, args, res)
+		eargs := html.EscapeString(args)
+		switch {
+		case (ae.Kind == ActionSave || ae.Kind == ActionFile) && filepath.IsAbs(ae.Args):
+			eargs = fmt.Sprintf(`<a href="file:///%v">%v</a>`, html.EscapeString(ae.Args), eargs)
+		case ae.Kind == ActionCmd || ae.Kind == ActionVcs:
+			eargs += fmt.Sprintf(`  <a href="tab:///%v">output</a>`, html.EscapeString(ae.Op))
+		}
+		mu := fmt.Sprintf(`<span style="color: grey">%v</span> [%v] <b>%v</b> %v`, tm, ae.Kind, html.EscapeString(ae.Op), eargs)
+		if res != "" {
+			mu += fmt.Sprintf(`<span style="color: %v">%v</span>`, ProblemColors[ProblemError], html.EscapeString(res))
+		}
+		outlns = append(outlns, []byte(ln))
+		outmus = append(outmus, []byte(mu))
+	}
+	if len(aes) == 0 {
+		outlns = append(outlns, []byte("no actions"))
+		outmus = append(outmus, []byte("no actions"))
+	}
+	ab := av.TextView().Buf
+	if ab == nil {
+		return
+	}
+	ab.New(0)
+	ab.SetInactive(true)
+	ab.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), giv.EditSignal)
+}
+
+// ActionLogViewProps are style properties for ActionLogView
+var ActionLogViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
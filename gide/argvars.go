@@ -54,6 +54,14 @@ var ArgVars = map[string]ArgVarInfo{
 	"{RunExecDirPath}":    {"Full path to the directory of the run-time executable file RunExec specified in project prefs.", ArgVarDir},
 	"{RunExecDirPathRel}": {"Project-root relative path to the directory of the run-time executable file RunExec specified in project prefs.", ArgVarDir},
 
+	// BuildConfig
+	"{BuildFlags}":    {"Go build flags of the run mode (-race, -msan, -asan) and of the active build configuration (-tags, -ldflags) -- each flag is a separate arg, none if not set.", ArgVarList},
+	"{BuildOutFlags}": {"Go build output flag (-o path) of the active build configuration -- each flag is a separate arg, none if not set.", ArgVarList},
+
+	// Go module
+	"{ModPath}": {"Module path of the Go module that the project is in (from its go.mod file).", ArgVarText},
+	"{ModDir}":  {"Full path to the directory of the go.mod file of the Go module that the project is in.", ArgVarDir},
+
 	// Cursor, Selection
 	"{CurLine}":      {"Cursor current line number (starts at 1).", ArgVarPos},
 	"{CurCol}":       {"Cursor current column number (starts at 0).", ArgVarPos},
@@ -147,6 +155,21 @@ func (avp *ArgVarVals) Set(fpath string, ppref *ProjPrefs, tv *giv.TextView) {
 	av["{RunExecDirPath}"] = exepath
 	av["{RunExecDirPathRel}"] = exerel
 
+	var bflags []string
+	if fl := RunModeFlags[ppref.ActiveRunMode()]; fl != "" {
+		bflags = append(bflags, fl)
+	}
+	av["{BuildOutFlags}"] = ""
+	if bc := ppref.ActiveBuildConfig(); bc != nil {
+		bflags = append(bflags, bc.Flags()...)
+		av["{BuildOutFlags}"] = strings.Join(bc.OutFlags(), "\n")
+	}
+	av["{BuildFlags}"] = strings.Join(bflags, "\n")
+
+	modroot, modpath, _ := GoModule(projpath)
+	av["{ModPath}"] = modpath
+	av["{ModDir}"] = modroot
+
 	if tv != nil {
 		av["{CurLine}"] = fmt.Sprintf("%v", tv.CursorPos.Ln)
 		av["{CurCol}"] = fmt.Sprintf("%v", tv.CursorPos.Ch)             // not quite col
@@ -290,6 +313,10 @@ const (
 	// ArgVarPrompt is a user-prompted variable
 	ArgVarPrompt
 
+	// ArgVarList is a list of args, one per line -- an arg that is just
+	// the variable expands to one arg per item, and to none if it is empty
+	ArgVarList
+
 	// ArgVarTypesN is the number of ArgVarTypes
 	ArgVarTypesN
 )
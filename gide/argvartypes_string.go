@@ -19,12 +19,13 @@ func _() {
 	_ = x[ArgVarPos-3]
 	_ = x[ArgVarText-4]
 	_ = x[ArgVarPrompt-5]
-	_ = x[ArgVarTypesN-6]
+	_ = x[ArgVarList-6]
+	_ = x[ArgVarTypesN-7]
 }
 
-const _ArgVarTypes_name = "ArgVarFileArgVarDirArgVarExtArgVarPosArgVarTextArgVarPromptArgVarTypesN"
+const _ArgVarTypes_name = "ArgVarFileArgVarDirArgVarExtArgVarPosArgVarTextArgVarPromptArgVarListArgVarTypesN"
 
-var _ArgVarTypes_index = [...]uint8{0, 10, 19, 28, 37, 47, 59, 71}
+var _ArgVarTypes_index = [...]uint8{0, 10, 19, 28, 37, 47, 59, 69, 81}
 
 func (i ArgVarTypes) String() string {
 	if i < 0 || i >= ArgVarTypes(len(_ArgVarTypes_index)-1) {
@@ -0,0 +1,496 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"go/build"
+	"html"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// ArtifactCmds are the words in the names of the commands whose outputs
+// are recorded as artifacts when they succeed
+var ArtifactCmds = []string{"Build", "Install", "Make"}
+
+// ArtifactExts are the extensions of the non-executable files recorded as
+// artifacts
+var ArtifactExts = []string{".exe", ".wasm", ".a", ".so", ".dll", ".dylib", ".jar", ".apk", ".zip", ".tar", ".gz"}
+
+// ArtifactsMax is the maximum number of artifacts kept in the list
+var ArtifactsMax = 200
+
+// IsArtifactCmd returns true if the outputs of given command are recorded
+// as artifacts (see ArtifactCmds)
+func IsArtifactCmd(cmdNm string) bool {
+	return cmdHasWord(cmdNm, ArtifactCmds)
+}
+
+// Artifact is a file made by a build command: an executable, library or
+// archive
+type Artifact struct {
+	Path   string       `width:"50" desc:"full path of the file"`
+	Size   giv.FileSize `desc:"size of the file"`
+	Built  giv.FileTime `desc:"when it was built"`
+	Target string       `desc:"target it was built for, e.g., linux/amd64, if known"`
+	Cmd    string       `desc:"command that built it"`
+	Exec   bool         `view:"-" desc:"true if it is executable"`
+}
+
+// NewArtifact returns the artifact for given file, built by given
+// command for given target -- false if there is no such file
+func NewArtifact(fpath, cmdNm, target string) (Artifact, bool) {
+	fi, err := os.Stat(fpath)
+	if err != nil || fi.IsDir() {
+		return Artifact{}, false
+	}
+	return Artifact{Path: fpath, Size: giv.FileSize(fi.Size()), Built: giv.FileTime(fi.ModTime()), Target: target, Cmd: cmdNm,
+		Exec: fi.Mode()&0111 != 0 || filepath.Ext(fpath) == ".exe"}, true
+}
+
+// IsArtifactFile returns true if the file of given info looks like an
+// artifact: executable, or of one of ArtifactExts
+func IsArtifactFile(fi os.FileInfo) bool {
+	if !fi.Mode().IsRegular() || strings.HasPrefix(fi.Name(), ".") {
+		return false
+	}
+	if fi.Mode()&0111 != 0 {
+		return !strings.Contains(fi.Name(), ".") || filepath.Ext(fi.Name()) == ".exe"
+	}
+	ext := filepath.Ext(fi.Name())
+	for _, ae := range ArtifactExts {
+		if ext == ae {
+			return true
+		}
+	}
+	return false
+}
+
+// ArtifactDirs returns the files and directories where given command args
+// may write their outputs, other than the directory they are run in: the
+// -o path, and the bin directory of go install
+func ArtifactDirs(args []string, env []string) []string {
+	var dirs []string
+	for i, a := range args {
+		switch {
+		case a == "-o" && i+1 < len(args):
+			dirs = append(dirs, args[i+1])
+		case strings.HasPrefix(a, "-o="):
+			dirs = append(dirs, strings.TrimPrefix(a, "-o="))
+		case a == "install" && i == 0:
+			dirs = append(dirs, GoBinDir(env))
+		}
+	}
+	return dirs
+}
+
+// GoBinDir returns the directory where go install writes the executables,
+// with given environment
+func GoBinDir(env []string) string {
+	gobin, gopath := os.Getenv("GOBIN"), os.Getenv("GOPATH")
+	for _, ev := range env {
+		switch {
+		case strings.HasPrefix(ev, "GOBIN="):
+			gobin = strings.TrimPrefix(ev, "GOBIN=")
+		case strings.HasPrefix(ev, "GOPATH="):
+			gopath = strings.TrimPrefix(ev, "GOPATH=")
+		}
+	}
+	if gobin != "" {
+		return gobin
+	}
+	if gopath == "" {
+		gopath = build.Default.GOPATH
+	}
+	return filepath.Join(filepath.SplitList(gopath)[0], "bin")
+}
+
+// EnvTarget returns the GOOS/GOARCH target of given environment, the host
+// one for those not set
+func EnvTarget(env []string) string {
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	for _, ev := range env {
+		switch {
+		case strings.HasPrefix(ev, "GOOS=") && len(ev) > 5:
+			goos = ev[5:]
+		case strings.HasPrefix(ev, "GOARCH=") && len(ev) > 7:
+			goarch = ev[7:]
+		}
+	}
+	return goos + "/" + goarch
+}
+
+// FindArtifacts returns the artifacts written since given time in given
+// directory and files (not recursively), by given command
+func FindArtifacts(paths []string, since time.Time, cmdNm string) []Artifact {
+	var afs []Artifact
+	have := map[string]bool{}
+	add := func(fpath string, fi os.FileInfo) {
+		if have[fpath] || fi.ModTime().Before(since) || !IsArtifactFile(fi) {
+			return
+		}
+		if af, ok := NewArtifact(fpath, cmdNm, ""); ok {
+			have[fpath] = true
+			afs = append(afs, af)
+		}
+	}
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if !fi.IsDir() {
+			add(p, fi)
+			continue
+		}
+		fis, err := ioutil.ReadDir(p)
+		if err != nil {
+			continue
+		}
+		for _, dfi := range fis {
+			add(filepath.Join(p, dfi.Name()), dfi)
+		}
+	}
+	return afs
+}
+
+// RecordArtifacts records the artifacts written by given successful run of
+// a build command, found in its directory and -o or install directories
+func (cm *Command) RecordArtifacts(ge Gide, cr *CmdRun) {
+	if cr == nil || !IsArtifactCmd(cm.Name) {
+		return
+	}
+	dir, _ := os.Getwd()
+	paths := []string{dir}
+	var env []string
+	if cr.Exec != nil {
+		env = cr.Exec.Env
+		if cr.Exec.Dir != "" {
+			paths[0] = cr.Exec.Dir
+		}
+		if len(cr.Exec.Args) > 1 {
+			for _, p := range ArtifactDirs(cr.Exec.Args[1:], cr.Exec.Env) {
+				if !filepath.IsAbs(p) {
+					p = filepath.Join(paths[0], p)
+				}
+				paths = append(paths, p)
+			}
+		}
+	}
+	// mod times can have a coarse resolution
+	afs := FindArtifacts(paths, cr.Start.Add(-time.Second), cm.Name)
+	for i := range afs {
+		afs[i].Target = EnvTarget(env)
+	}
+	if len(afs) > 0 {
+		ge.AddArtifacts(afs)
+	}
+}
+
+// ArtifactList is the list of artifacts built in the project, the most
+// recent first -- safe for concurrent use
+type ArtifactList struct {
+	Artifacts []Artifact `desc:"the artifacts"`
+	Mu        sync.Mutex `json:"-" xml:"-" view:"-" desc:"mutex for the list"`
+}
+
+// Add adds given artifacts, replacing any previous ones at the same paths
+func (al *ArtifactList) Add(afs []Artifact) {
+	al.Mu.Lock()
+	defer al.Mu.Unlock()
+	nw := make([]Artifact, 0, len(afs)+len(al.Artifacts))
+	nw = append(nw, afs...)
+	for _, af := range al.Artifacts {
+		dup := false
+		for i := range afs {
+			if afs[i].Path == af.Path {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			nw = append(nw, af)
+		}
+	}
+	if len(nw) > ArtifactsMax {
+		nw = nw[:ArtifactsMax]
+	}
+	al.Artifacts = nw
+}
+
+// Delete deletes the artifact at given index from the list
+func (al *ArtifactList) Delete(idx int) {
+	al.Mu.Lock()
+	defer al.Mu.Unlock()
+	if idx >= 0 && idx < len(al.Artifacts) {
+		al.Artifacts = append(al.Artifacts[:idx], al.Artifacts[idx+1:]...)
+	}
+}
+
+// Clear clears the list
+func (al *ArtifactList) Clear() {
+	al.Mu.Lock()
+	al.Artifacts = nil
+	al.Mu.Unlock()
+}
+
+// Prune removes the artifacts whose files no longer exist, updating the
+// size and time of the others
+func (al *ArtifactList) Prune() {
+	al.Mu.Lock()
+	defer al.Mu.Unlock()
+	nw := al.Artifacts[:0]
+	for _, af := range al.Artifacts {
+		if naf, ok := NewArtifact(af.Path, af.Cmd, af.Target); ok {
+			nw = append(nw, naf)
+		}
+	}
+	al.Artifacts = nw
+}
+
+// ArtifactsView shows the artifacts built in the project, with actions to
+// run them, reveal them in the file manager, and copy their path
+type ArtifactsView struct {
+	gi.Layout
+	Gide      Gide          `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	List      *ArtifactList `json:"-" xml:"-" desc:"the list of artifacts"`
+	Artifacts []Artifact    `desc:"the artifacts shown"`
+}
+
+var KiT_ArtifactsView = kit.Types.AddType(&ArtifactsView{}, ArtifactsViewProps)
+
+// ArtifactRunCmdName is the name of the artifact run by the ArtifactsView
+// in the CmdRuns of the project, for stopping it
+var ArtifactRunCmdName = "Run Artifact"
+
+// Config configures the view for given list of artifacts
+func (av *ArtifactsView) Config(ge Gide, al *ArtifactList) {
+	av.Gide = ge
+	av.List = al
+	av.Lay = gi.LayoutVert
+	av.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "artbar")
+	config.Add(gi.KiT_SplitView, "artsplit")
+	mods, updt := av.ConfigChildren(config)
+	if !mods {
+		updt = av.UpdateStart()
+	}
+	av.ConfigToolbar()
+	av.ConfigSplitView()
+	av.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (av *ArtifactsView) ToolBar() *gi.ToolBar {
+	return av.ChildByName("artbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the artifacts and the run output
+func (av *ArtifactsView) SplitView() *gi.SplitView {
+	return av.ChildByName("artsplit", 1).(*gi.SplitView)
+}
+
+// TableView returns the table of the artifacts
+func (av *ArtifactsView) TableView() *giv.TableView {
+	return av.SplitView().ChildByName("artifacts", 0).(*giv.TableView)
+}
+
+// TextView returns the TextView of the output of the artifacts run
+func (av *ArtifactsView) TextView() *giv.TextView {
+	ly := av.SplitView().ChildByName("out", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// SelArtifact returns the selected artifact, and its index in the list,
+// nil if none
+func (av *ArtifactsView) SelArtifact() (*Artifact, int) {
+	idx := av.TableView().SelectedIdx
+	if idx < 0 || idx >= len(av.Artifacts) {
+		av.Gide.SetStatus("select an artifact first")
+		return nil, -1
+	}
+	return &av.Artifacts[idx], idx
+}
+
+// ConfigToolbar adds the toolbar actions
+func (av *ArtifactsView) ConfigToolbar() {
+	tb := av.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Run", Icon: "play", Tooltip: "run the selected executable, in its directory, for the host platform only (also by double-click)"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			if af, _ := avv.SelArtifact(); af != nil {
+				avv.Run(af)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop the running executable"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			avv.Gide.CmdRuns().KillByName(ArtifactRunCmdName)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Reveal", Icon: "folder-open", Tooltip: "open the directory of the selected artifact in the file manager"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			if af, _ := avv.SelArtifact(); af != nil {
+				avv.Reveal(af)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Copy Path", Icon: "copy", Tooltip: "copy the full path of the selected artifact to the clipboard"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			if af, _ := avv.SelArtifact(); af != nil {
+				oswin.TheApp.ClipBoard(avv.ParentWindow().OSWin).Write(mimedata.NewText(af.Path))
+				avv.Gide.SetStatus("copied: " + af.Path)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Remove", Icon: "minus", Tooltip: "remove the selected artifact from the list (the file is kept)"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			if af, idx := avv.SelArtifact(); af != nil {
+				avv.List.Delete(idx)
+				avv.ShowArtifacts()
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "update the sizes and times, removing the artifacts whose files were deleted"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			avv.List.Prune()
+			avv.ShowArtifacts()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear", Icon: "close", Tooltip: "clear the list of artifacts (the files are kept)"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			avv.List.Clear()
+			avv.ShowArtifacts()
+		})
+}
+
+// ConfigSplitView configures the split view of the artifacts and the run
+// output
+func (av *ArtifactsView) ConfigSplitView() {
+	split := av.SplitView()
+	split.Dim = mat32.Y
+	if len(split.Kids) > 0 {
+		return
+	}
+	tv := split.AddNewChild(giv.KiT_TableView, "artifacts").(*giv.TableView)
+	tv.SetStretchMax()
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SetInactive()
+	tv.SetSlice(&av.Artifacts)
+	tv.SliceViewSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.SliceViewDoubleClicked) {
+			avv, _ := recv.Embed(KiT_ArtifactsView).(*ArtifactsView)
+			if af, _ := avv.SelArtifact(); af != nil {
+				avv.Run(af)
+			}
+		}
+	})
+	ly := gi.AddNewLayout(split, "out", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.6, .4)
+}
+
+// ShowArtifacts shows the current artifacts of the list
+func (av *ArtifactsView) ShowArtifacts() {
+	vp := av.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	av.List.Mu.Lock()
+	av.Artifacts = append([]Artifact{}, av.List.Artifacts...)
+	av.List.Mu.Unlock()
+	tv := av.TableView()
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	tv.SetSlice(&av.Artifacts)
+	tv.UpdateEnd(updt)
+}
+
+// Reveal opens the directory of given artifact in the file manager of the
+// OS
+func (av *ArtifactsView) Reveal(af *Artifact) {
+	cmd := exec.Command(giv.OSOpenCommand(), filepath.Dir(af.Path))
+	if err := cmd.Start(); err != nil {
+		av.Gide.SetStatus("could not open the file manager: " + err.Error())
+	}
+}
+
+// Run runs given executable artifact in its directory in the background,
+// with its output shown below the artifacts
+func (av *ArtifactsView) Run(af *Artifact) {
+	if !af.Exec {
+		av.Gide.SetStatus("not an executable: " + af.Path)
+		return
+	}
+	ge := av.Gide
+	fpath := af.Path
+	if _, idx := ge.CmdRuns().ByName(ArtifactRunCmdName); idx >= 0 {
+		ge.SetStatus("an artifact is already running -- stop it first")
+		return
+	}
+	tbuf := av.TextView().Buf
+	vp := ge.VPort()
+	wupdt := vp.TopUpdateStart()
+	tbuf.New(0)
+	tbuf.SetInactive(true)
+	tbuf.AppendTextMarkup([]byte(fpath), []byte("<b>"+html.EscapeString(fpath)+"</b>"), giv.EditSignal)
+	vp.TopUpdateEnd(wupdt)
+	go func() {
+		cmd := exec.Command(fpath)
+		cmd.Dir = filepath.Dir(fpath)
+		cmd.Env = ge.ProjPrefs().CmdEnv()
+		ge.CmdRuns().AddCmd(ArtifactRunCmdName, fpath, &CmdAndArgs{Cmd: fpath}, cmd)
+		stdout, err := cmd.StdoutPipe()
+		if err == nil {
+			cmd.Stderr = cmd.Stdout
+			err = cmd.Start()
+			if err == nil {
+				obuf := OutBuf{}
+				obuf.Init(stdout, tbuf, 0, MarkupCmdOutput)
+				obuf.MonOut()
+			}
+			err = cmd.Wait()
+		}
+		ge.CmdRuns().DeleteByName(ArtifactRunCmdName)
+		if av.This() == nil || av.IsDeleted() || av.IsDestroyed() {
+			return
+		}
+		res := fmt.Sprintf("%v: done", filepath.Base(fpath))
+		if err != nil {
+			res = fmt.Sprintf("%v: %v", filepath.Base(fpath), err)
+		}
+		ge.SetStatus(res)
+	}()
+}
+
+// ArtifactsViewProps are style properties for ArtifactsView
+var ArtifactsViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AskPassRequest is a request for a credential (user name, password or ssh
+// key passphrase) from the gide-askpass helper, which git and ssh run as
+// GIT_ASKPASS / SSH_ASKPASS for the commands run by gide, as there is no
+// terminal to prompt in
+type AskPassRequest struct {
+	Prompt string `desc:"prompt given by git or ssh, e.g., Password for 'https://user@host':"`
+	Dir    string `desc:"working directory of the helper, which is that of the command, for finding the project"`
+}
+
+// AskPassReply is the reply to an AskPassRequest
+type AskPassReply struct {
+	Answer string `desc:"the credential entered"`
+	Ok     bool   `desc:"false if canceled -- the command then fails"`
+}
+
+// AskPassEnvVar is the environment variable giving the gide-askpass
+// helper the path of the socket of the AskPassServer
+const AskPassEnvVar = "GIDE_ASKPASS_SOCK"
+
+// AskPassTimeout is how long the helper waits for the credential to be entered
+var AskPassTimeout = 5 * time.Minute
+
+// AskPassServer listens on a local socket for AskPassRequests from the
+// gide-askpass helper, for the commands run by this gide, and prompts
+// for them
+type AskPassServer struct {
+	Listener   net.Listener                             `desc:"the socket listener"`
+	PromptFunc func(req *AskPassRequest) (string, bool) `desc:"function that prompts for the credential, blocking until entered"`
+	Path       string                                   `desc:"path of the socket"`
+	Helper     string                                   `desc:"full path of the gide-askpass helper"`
+	Mu         sync.Mutex                               `desc:"serializes the prompts"`
+}
+
+// TheAskPass is the running AskPassServer, nil if none: then the commands
+// run without the helper, and git does not prompt on the terminal
+var TheAskPass *AskPassServer
+
+// AskPassSockPath returns the path of the AskPassServer socket of this
+// process, in the RuntimeDir, private to the user
+func AskPassSockPath() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("gide-askpass-%d.sock", os.Getpid())), nil
+}
+
+// AskPassHelper returns the full path of the gide-askpass helper: on the
+// PATH, or next to the gide executable -- "" if not found
+func AskPassHelper() string {
+	if hp, err := exec.LookPath("gide-askpass"); err == nil {
+		hp, _ = filepath.Abs(hp)
+		return hp
+	}
+	ex, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	hp := filepath.Join(filepath.Dir(ex), "gide-askpass")
+	if _, err := os.Stat(hp); err != nil {
+		if _, err := os.Stat(hp + ".exe"); err != nil {
+			return ""
+		}
+		hp += ".exe"
+	}
+	return hp
+}
+
+// StartAskPassServer starts listening for AskPassRequests, prompting with
+// given function, and sets TheAskPass -- returns an error if the
+// gide-askpass helper is not installed
+func StartAskPassServer(promptFunc func(req *AskPassRequest) (string, bool)) (*AskPassServer, error) {
+	hp := AskPassHelper()
+	if hp == "" {
+		return nil, fmt.Errorf("gide: gide-askpass helper not found, so commands cannot prompt for credentials -- install it with go install github.com/goki/gide/cmd/gide-askpass")
+	}
+	sock, err := AskPassSockPath()
+	if err != nil {
+		return nil, err
+	}
+	as := &AskPassServer{PromptFunc: promptFunc, Path: sock, Helper: hp}
+	os.Remove(as.Path)
+	ln, err := net.Listen("unix", as.Path)
+	if err != nil {
+		return nil, err
+	}
+	os.Chmod(as.Path, 0600)
+	as.Listener = ln
+	TheAskPass = as
+	go as.Serve()
+	return as, nil
+}
+
+// Serve accepts and handles connections until the listener is closed
+func (as *AskPassServer) Serve() {
+	for {
+		conn, err := as.Listener.Accept()
+		if err != nil {
+			return
+		}
+		go as.Handle(conn)
+	}
+}
+
+// Handle reads one AskPassRequest from given connection, prompts for it,
+// and replies with the answer
+func (as *AskPassServer) Handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(InstanceTimeout))
+	req := &AskPassRequest{}
+	if err := json.NewDecoder(conn).Decode(req); err != nil {
+		log.Println(err)
+		return
+	}
+	as.Mu.Lock()
+	ans, ok := as.PromptFunc(req)
+	as.Mu.Unlock()
+	json.NewEncoder(conn).Encode(&AskPassReply{Answer: ans, Ok: ok})
+}
+
+// Stop stops listening, and removes the socket
+func (as *AskPassServer) Stop() {
+	if as == nil || as.Listener == nil {
+		return
+	}
+	as.Listener.Close()
+	as.Listener = nil
+	os.Remove(as.Path)
+	if TheAskPass == as {
+		TheAskPass = nil
+	}
+}
+
+// AskPassEnv returns the environment variables that make git and ssh ask
+// gide for credentials through the helper, and git never wait for them on
+// the terminal -- only the latter if TheAskPass is not running
+func AskPassEnv() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	as := TheAskPass
+	if as == nil {
+		return env
+	}
+	return append(env, "GIT_ASKPASS="+as.Helper, "SSH_ASKPASS="+as.Helper, "SSH_ASKPASS_REQUIRE=force", AskPassEnvVar+"="+as.Path)
+}
+
+// AskPassCmdEnv returns the environment of the process with AskPassEnv added
+func AskPassCmdEnv() []string {
+	return append(os.Environ(), AskPassEnv()...)
+}
+
+// AskPassIsSecret returns true if the credential for given prompt is to be
+// entered without showing it: all but user names
+func AskPassIsSecret(prompt string) bool {
+	return !strings.HasPrefix(strings.ToLower(strings.TrimSpace(prompt)), "username")
+}
+
+// SendAskPassRequest sends given prompt to the AskPassServer of the gide
+// at given socket path, returning the credential entered -- an error if
+// canceled, or there is no server
+func SendAskPassRequest(sock, prompt string) (string, error) {
+	conn, err := net.DialTimeout("unix", sock, InstanceTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	dir, _ := os.Getwd()
+	if err := json.NewEncoder(conn).Encode(&AskPassRequest{Prompt: prompt, Dir: dir}); err != nil {
+		return "", err
+	}
+	conn.SetReadDeadline(time.Now().Add(AskPassTimeout))
+	rep := &AskPassReply{}
+	if err := json.NewDecoder(conn).Decode(rep); err != nil {
+		return "", err
+	}
+	if !rep.Ok {
+		return "", fmt.Errorf("canceled")
+	}
+	return rep.Answer, nil
+}
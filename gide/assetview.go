@@ -0,0 +1,231 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+)
+
+// AssetViewZoomStep is the factor by which zoom in / out changes the zoom
+var AssetViewZoomStep = float32(1.25)
+
+// AssetView views a file that is not edited as text: images (including svg)
+// are rendered with zoom controls, and all files show a summary of the file
+// info (size, dimensions etc) -- a toolbar action opens the file in the
+// external application for it.
+type AssetView struct {
+	gi.Layout
+	Gide     Gide         `json:"-" xml:"-" desc:"parent gide project"`
+	Info     giv.FileInfo `desc:"info for the file being viewed"`
+	Zoom     float32      `desc:"current zoom factor for images -- 1 = actual size"`
+	ImgSize  mat32.Vec2   `desc:"actual size of the image, if an image"`
+	Image    image.Image  `json:"-" xml:"-" view:"-" desc:"the image, if a bitmap image"`
+	ImageErr error        `json:"-" xml:"-" view:"-" desc:"error opening the image, if any"`
+}
+
+var KiT_AssetView = kit.Types.AddType(&AssetView{}, AssetViewProps)
+
+// IsSVG returns true if the file is an svg image
+func (av *AssetView) IsSVG() bool {
+	return av.Info.Sup == filecat.Svg
+}
+
+// IsImage returns true if the file is an image
+func (av *AssetView) IsImage() bool {
+	return av.Info.Cat == filecat.Image
+}
+
+// Summary returns a summary of the file info
+func (av *AssetView) Summary() string {
+	fi := &av.Info
+	sum := fmt.Sprintf("<b>%v</b>   %v   %v   modified: %v", fi.Name, fi.Kind, fi.Size.String(), fi.ModTime)
+	switch {
+	case av.ImageErr != nil:
+		sum += fmt.Sprintf("   <i>could not open image: %v</i>", av.ImageErr)
+	case av.IsImage() && av.ImgSize != mat32.Vec2Zero:
+		sum += fmt.Sprintf("   %v x %v pixels   zoom: %v%%", av.ImgSize.X, av.ImgSize.Y, int(av.Zoom*100))
+	}
+	return sum
+}
+
+// Config configures the view for given file, which is opened
+func (av *AssetView) Config(ge Gide, fpath string) {
+	av.Gide = ge
+	av.Lay = gi.LayoutVert
+	av.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(gi.KiT_Label, "summary")
+	config.Add(gi.KiT_Layout, "view")
+	mods, updt := av.ConfigChildren(config)
+	if !mods {
+		updt = av.UpdateStart()
+	}
+	av.Info.InitFile(fpath)
+	av.Zoom = 1
+	av.ConfigToolbar()
+	av.OpenAsset()
+	av.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (av *AssetView) ToolBar() *gi.ToolBar {
+	return av.ChildByName("toolbar", 0).(*gi.ToolBar)
+}
+
+// SummaryLabel returns the label showing the file summary
+func (av *AssetView) SummaryLabel() *gi.Label {
+	return av.ChildByName("summary", 1).(*gi.Label)
+}
+
+// ViewLay returns the scrolling layout containing the image
+func (av *AssetView) ViewLay() *gi.Layout {
+	return av.ChildByName("view", 2).(*gi.Layout)
+}
+
+// OpenAsset opens the file and configures the image to display it, if an
+// image
+func (av *AssetView) OpenAsset() {
+	vl := av.ViewLay()
+	vl.SetStretchMaxWidth()
+	vl.SetStretchMaxHeight()
+	updt := vl.UpdateStart()
+	vl.SetFullReRender()
+	vl.DeleteChildren(ki.DestroyKids)
+	av.Image = nil
+	av.ImageErr = nil
+	av.ImgSize = mat32.Vec2Zero
+	switch {
+	case av.IsSVG():
+		sv := svg.AddNewSVG(vl, "svg")
+		sv.Norm = true
+		av.ImageErr = sv.OpenXML(av.Info.Path)
+		if av.ImageErr == nil {
+			av.ImgSize = sv.ViewBox.Size
+		}
+	case av.IsImage():
+		av.Image, av.ImageErr = gi.OpenImage(av.Info.Path)
+		if av.ImageErr == nil {
+			av.ImgSize = mat32.NewVec2FmPoint(av.Image.Bounds().Size())
+			gi.AddNewBitmap(vl, "bitmap")
+		}
+	}
+	av.SetZoom(av.Zoom)
+	vl.UpdateEnd(updt)
+}
+
+// SetZoom sets the zoom factor for images, and updates the display
+func (av *AssetView) SetZoom(zoom float32) {
+	av.Zoom = mat32.Clamp(zoom, 0.01, 100)
+	av.SummaryLabel().SetText(av.Summary())
+	if av.ImgSize == mat32.Vec2Zero {
+		return
+	}
+	vl := av.ViewLay()
+	if !vl.HasChildren() {
+		return
+	}
+	sz := av.ImgSize.MulScalar(av.Zoom)
+	if sz.X < 1 || sz.Y < 1 {
+		return
+	}
+	updt := vl.UpdateStart()
+	vl.SetFullReRender()
+	switch kid := vl.Child(0).(type) {
+	case *svg.SVG:
+		kid.SetProp("width", units.NewDot(sz.X))
+		kid.SetProp("height", units.NewDot(sz.Y))
+		kid.Resize(sz.ToPoint())
+	case *gi.Bitmap:
+		kid.SetImage(av.Image, sz.X, sz.Y)
+		kid.LayoutToImgSize()
+	}
+	vl.UpdateEnd(updt)
+}
+
+// ZoomIn zooms in on the image
+func (av *AssetView) ZoomIn() {
+	av.SetZoom(av.Zoom * AssetViewZoomStep)
+}
+
+// ZoomOut zooms out of the image
+func (av *AssetView) ZoomOut() {
+	av.SetZoom(av.Zoom / AssetViewZoomStep)
+}
+
+// ActualSize shows the image at its actual size
+func (av *AssetView) ActualSize() {
+	av.SetZoom(1)
+}
+
+// OpenExternal opens the file using the Open File command, which runs the
+// external application for it
+func (av *AssetView) OpenExternal() {
+	if av.Gide == nil {
+		return
+	}
+	av.Gide.ExecCmdNameFileName(av.Info.Path, CmdName("Open File"), true, true)
+}
+
+// ConfigToolbar adds toolbar.
+func (av *AssetView) ConfigToolbar() {
+	tb := av.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Zoom In", Icon: "zoom-in", Tooltip: "zoom in on the image"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_AssetView).(*AssetView)
+			avv.ZoomIn()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Zoom Out", Icon: "zoom-out", Tooltip: "zoom out of the image"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_AssetView).(*AssetView)
+			avv.ZoomOut()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Actual Size", Tooltip: "show the image at its actual size"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_AssetView).(*AssetView)
+			avv.ActualSize()
+		})
+	tb.AddSeparator("sep-open")
+	tb.AddAction(gi.ActOpts{Label: "Reload", Icon: "update", Tooltip: "reload the file, e.g., after it has been changed"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_AssetView).(*AssetView)
+			avv.Info.InitFile(avv.Info.Path)
+			avv.OpenAsset()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Open Externally", Icon: "file-open", Tooltip: "open the file in the external application for it, using the Open File command"},
+		av.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			avv, _ := recv.Embed(KiT_AssetView).(*AssetView)
+			avv.OpenExternal()
+		})
+}
+
+// AssetViewTabName returns the name of the main tab used for viewing given file
+func AssetViewTabName(fpath string) string {
+	return "View: " + filepath.Base(fpath)
+}
+
+// AssetViewProps are style properties for AssetView
+var AssetViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
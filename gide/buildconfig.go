@@ -0,0 +1,130 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+)
+
+// BuildConfig is a named build configuration (target) of a project: when
+// it is the active one (ProjPrefs.BuildConfig), it sets the BuildDir,
+// BuildTarg and RunExec, and the GOOS, GOARCH and {BuildFlags} of the
+// build commands
+type BuildConfig struct {
+	Name    string      `desc:"name of the configuration, as shown in the Build Config chooser"`
+	MainPkg gi.FileName `desc:"directory of the main package to build -- sets BuildDir and BuildTarg"`
+	GOOS    string      `desc:"target operating system (GOOS) -- empty for the host one"`
+	GOARCH  string      `desc:"target architecture (GOARCH) -- empty for the host one"`
+	Tags    string      `desc:"build tags, comma separated"`
+	Output  gi.FileName `desc:"path of the executable to build -- sets RunExec -- if empty, the one that go build makes in the MainPkg directory"`
+	LDFlags string      `desc:"flags passed to the linker, e.g., -s -w or -X main.version=1.0"`
+}
+
+// Label satisfies the Labeler interface
+func (bc BuildConfig) Label() string {
+	return bc.Name
+}
+
+// Flags returns the go build flags for the tags and ldflags of the
+// configuration, as {BuildFlags}
+func (bc *BuildConfig) Flags() []string {
+	var fl []string
+	if bc.Tags != "" {
+		fl = append(fl, "-tags", bc.Tags)
+	}
+	if bc.LDFlags != "" {
+		fl = append(fl, "-ldflags", bc.LDFlags)
+	}
+	return fl
+}
+
+// OutFlags returns the go build flags for the output path of the
+// configuration, as {BuildOutFlags} -- none if Output is not set
+func (bc *BuildConfig) OutFlags() []string {
+	if bc.Output == "" {
+		return nil
+	}
+	out, _ := filepath.Abs(string(bc.Output))
+	return []string{"-o", out}
+}
+
+// Env returns the GOOS and GOARCH environment variables of the
+// configuration, in KEY=value form
+func (bc *BuildConfig) Env() []string {
+	var env []string
+	if bc.GOOS != "" {
+		env = append(env, "GOOS="+bc.GOOS)
+	}
+	if bc.GOARCH != "" {
+		env = append(env, "GOARCH="+bc.GOARCH)
+	}
+	return env
+}
+
+// BuildConfigs is a list of build configurations
+type BuildConfigs []*BuildConfig
+
+// ConfigByName returns the configuration of given name, false if not found
+func (bc BuildConfigs) ConfigByName(name string) (*BuildConfig, bool) {
+	for _, c := range bc {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of the configurations
+func (bc BuildConfigs) Names() []string {
+	nms := make([]string, len(bc))
+	for i, c := range bc {
+		nms[i] = c.Name
+	}
+	return nms
+}
+
+// ActiveBuildConfig returns the active build configuration, nil if none
+func (pf *ProjPrefs) ActiveBuildConfig() *BuildConfig {
+	if pf.BuildConfig == "" {
+		return nil
+	}
+	bc, _ := pf.BuildConfigs.ConfigByName(pf.BuildConfig)
+	return bc
+}
+
+// SetBuildConfig makes the configuration of given name the active one,
+// and applies it -- returns false if there is none of that name
+func (pf *ProjPrefs) SetBuildConfig(name string) bool {
+	if _, ok := pf.BuildConfigs.ConfigByName(name); !ok {
+		return false
+	}
+	pf.BuildConfig = name
+	pf.ApplyBuildConfig()
+	return true
+}
+
+// ApplyBuildConfig sets the BuildDir, BuildTarg and RunExec from the active
+// build configuration, if any
+func (pf *ProjPrefs) ApplyBuildConfig() {
+	bc := pf.ActiveBuildConfig()
+	if bc == nil || bc.MainPkg == "" {
+		return
+	}
+	pf.BuildDir = bc.MainPkg
+	pf.BuildTarg = bc.MainPkg
+	if bc.Output != "" {
+		pf.RunExec = bc.Output
+		return
+	}
+	mp := string(bc.MainPkg)
+	if root, modpath, ok := GoModule(mp); ok {
+		mi := &GoModInfo{Root: root, ModPath: modpath}
+		pf.RunExec = gi.FileName(mi.GoExecPath(mp))
+	} else {
+		pf.RunExec = gi.FileName(filepath.Join(mp, filepath.Base(mp)))
+	}
+}
@@ -0,0 +1,481 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// BuildTarget is a target platform of the build matrix: a GOOS / GOARCH
+// and build tags combination
+type BuildTarget struct {
+	GOOS   string `desc:"target operating system (GOOS)"`
+	GOARCH string `desc:"target architecture (GOARCH)"`
+	Tags   string `desc:"build tags, comma separated"`
+	CGO    bool   `desc:"build with cgo (CGO_ENABLED=1) -- cross-compiling with cgo needs a C cross-compiler, set with CC in the project EnvVars"`
+	Off    bool   `desc:"if set, the target is not built"`
+}
+
+// Label satisfies the Labeler interface
+func (bt BuildTarget) Label() string {
+	lb := bt.GOOS + "/" + bt.GOARCH
+	if bt.Tags != "" {
+		lb += " " + bt.Tags
+	}
+	return lb
+}
+
+// Env returns the environment variables of the target, in KEY=value form
+func (bt *BuildTarget) Env() []string {
+	cgo := "CGO_ENABLED=0"
+	if bt.CGO {
+		cgo = "CGO_ENABLED=1"
+	}
+	return []string{"GOOS=" + bt.GOOS, "GOARCH=" + bt.GOARCH, cgo}
+}
+
+// CommonBuildTargets are the targets added by the Add Common action of the
+// build matrix
+var CommonBuildTargets = []BuildTarget{
+	{GOOS: "linux", GOARCH: "amd64"},
+	{GOOS: "linux", GOARCH: "arm64"},
+	{GOOS: "darwin", GOARCH: "amd64"},
+	{GOOS: "darwin", GOARCH: "arm64"},
+	{GOOS: "windows", GOARCH: "amd64"},
+}
+
+// BuildMatrix is a list of target platforms for which the main package of
+// the project is built, in parallel, collecting the executables in a
+// directory
+type BuildMatrix struct {
+	Targets  []BuildTarget `desc:"the target platforms"`
+	MainPkg  gi.FileName   `desc:"directory of the main package to build -- empty for the BuildDir of the project"`
+	OutDir   gi.FileName   `desc:"directory where the executables are collected, named for the main package and target, e.g., gide_linux_amd64 -- empty for dist in the project directory"`
+	LDFlags  string        `desc:"flags passed to the linker, e.g., -s -w"`
+	Parallel int           `desc:"number of targets built at the same time -- 0 for the number of CPUs"`
+}
+
+// MainDir returns the directory of the main package to build
+func (bm *BuildMatrix) MainDir(pf *ProjPrefs) string {
+	if bm.MainPkg != "" {
+		return string(bm.MainPkg)
+	}
+	if pf.BuildDir != "" {
+		return string(pf.BuildDir)
+	}
+	return string(pf.ProjRoot)
+}
+
+// Dir returns the directory where the executables are collected
+func (bm *BuildMatrix) Dir(pf *ProjPrefs) string {
+	if bm.OutDir != "" {
+		return string(bm.OutDir)
+	}
+	return filepath.Join(string(pf.ProjRoot), "dist")
+}
+
+// OutName returns the file name of the executable of the main package in
+// given directory for given target
+func (bm *BuildMatrix) OutName(dir string, bt *BuildTarget) string {
+	nm := filepath.Base(dir)
+	if root, modpath, ok := GoModule(dir); ok {
+		if imp, ok := GoImportPath(root, modpath, dir); ok {
+			nm = path.Base(imp)
+		}
+	}
+	nm += "_" + bt.GOOS + "_" + bt.GOARCH
+	if bt.Tags != "" {
+		nm += "_" + strings.NewReplacer(",", "-", " ", "-").Replace(bt.Tags)
+	}
+	if bt.GOOS == "windows" {
+		nm += ".exe"
+	}
+	return nm
+}
+
+// AddCommon adds the CommonBuildTargets not already in the matrix
+func (bm *BuildMatrix) AddCommon() {
+	for _, ct := range CommonBuildTargets {
+		has := false
+		for _, bt := range bm.Targets {
+			if bt.GOOS == ct.GOOS && bt.GOARCH == ct.GOARCH && bt.Tags == ct.Tags {
+				has = true
+				break
+			}
+		}
+		if !has {
+			bm.Targets = append(bm.Targets, ct)
+		}
+	}
+}
+
+// MatrixResult is the result of the build of a target of the build matrix
+type MatrixResult struct {
+	Target   string        `width:"20" desc:"the target"`
+	Status   string        `desc:"status of the build: waiting, building, ok, failed or stopped"`
+	Time     time.Duration `desc:"time taken by the build"`
+	Artifact string        `width:"40" desc:"path of the executable built"`
+	Output   string        `view:"-" desc:"output of the build"`
+}
+
+// MatrixCmdName is the prefix of the names of the matrix builds in the
+// CmdRuns of the project, followed by the target
+var MatrixCmdName = "Build Matrix"
+
+// BuildMatrixTarget builds given target of the matrix, with the
+// environment of the project, writing its executable to given path --
+// the command is added to the CmdRuns of the project while it runs
+func BuildMatrixTarget(ge Gide, bm *BuildMatrix, bt *BuildTarget, dir, out string) (string, error) {
+	args := []string{"build", "-o", out}
+	if bt.Tags != "" {
+		args = append(args, "-tags", bt.Tags)
+	}
+	if bm.LDFlags != "" {
+		args = append(args, "-ldflags", bm.LDFlags)
+	}
+	args = append(args, ".")
+	cmd := ge.ProjPrefs().ExecCmd(dir, bt.Env(), "go", args...) // last ones win
+	var obuf bytes.Buffer
+	cmd.Stdout = &obuf
+	cmd.Stderr = &obuf
+	nm := MatrixCmdName + " " + bt.Label()
+	ge.CmdRuns().AddCmd(nm, "go "+strings.Join(args, " "), &CmdAndArgs{Cmd: "go", Args: args}, cmd)
+	err := cmd.Start()
+	if err == nil {
+		err = cmd.Wait()
+	}
+	ge.CmdRuns().DeleteByName(nm)
+	return obuf.String(), err
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    MatrixView
+
+// MatrixView is the build matrix panel: it builds the main package of the
+// project for each target of the BuildMatrix of the project in parallel,
+// showing the status of each
+type MatrixView struct {
+	gi.Layout
+	Gide    Gide           `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Results []MatrixResult `desc:"the results of the builds of the targets"`
+	Running bool           `json:"-" xml:"-" desc:"true while building"`
+	Stopped bool           `json:"-" xml:"-" desc:"set by Stop, so the waiting targets are not built"`
+	Mu      sync.Mutex     `json:"-" xml:"-" view:"-" desc:"mutex for the results"`
+}
+
+var KiT_MatrixView = kit.Types.AddType(&MatrixView{}, MatrixViewProps)
+
+// Config configures the view
+func (mv *MatrixView) Config(ge Gide) {
+	mv.Gide = ge
+	mv.Lay = gi.LayoutVert
+	mv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "matrixbar")
+	config.Add(gi.KiT_SplitView, "matrixsplit")
+	mods, updt := mv.ConfigChildren(config)
+	if !mods {
+		updt = mv.UpdateStart()
+	}
+	mv.ConfigToolbar()
+	mv.ConfigSplitView()
+	if !mv.Running && len(mv.Results) == 0 {
+		mv.InitResults()
+	}
+	mv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (mv *MatrixView) ToolBar() *gi.ToolBar {
+	return mv.ChildByName("matrixbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the results and the output
+func (mv *MatrixView) SplitView() *gi.SplitView {
+	return mv.ChildByName("matrixsplit", 1).(*gi.SplitView)
+}
+
+// TableView returns the table of the results
+func (mv *MatrixView) TableView() *giv.TableView {
+	return mv.SplitView().ChildByName("results", 0).(*giv.TableView)
+}
+
+// TextView returns the TextView of the output of the selected build
+func (mv *MatrixView) TextView() *giv.TextView {
+	ly := mv.SplitView().ChildByName("out", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (mv *MatrixView) ConfigToolbar() {
+	tb := mv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Build All", Icon: "play", Tooltip: "build the main package for all the targets, in parallel, collecting the executables in the output directory"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MatrixView).(*MatrixView)
+			mvv.Gide.SaveAllCheck(true, func() {
+				mvv.Build()
+			})
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop the builds"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MatrixView).(*MatrixView)
+			mvv.Stop()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Targets...", Icon: "gear", Tooltip: "edit the targets (GOOS / GOARCH / tags), main package, output directory and ldflags of the build matrix"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MatrixView).(*MatrixView)
+			mvv.EditTargets()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Add Common", Icon: "plus", Tooltip: "add the common targets: linux, darwin and windows on amd64 and arm64"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MatrixView).(*MatrixView)
+			pf := mvv.Gide.ProjPrefs()
+			pf.Matrix.AddCommon()
+			pf.Changed = true
+			mvv.InitResults()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Out Dir", Icon: "folder-open", Tooltip: "open the output directory of the executables in the system file manager"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MatrixView).(*MatrixView)
+			pf := mvv.Gide.ProjPrefs()
+			dir := pf.Matrix.Dir(pf)
+			if _, err := os.Stat(dir); err != nil {
+				mvv.Gide.SetStatus("output directory does not exist yet: " + dir)
+				return
+			}
+			oswin.TheApp.OpenURL("file://" + dir)
+		})
+}
+
+// ConfigSplitView configures the split view of the results and the
+// output of the selected build
+func (mv *MatrixView) ConfigSplitView() {
+	split := mv.SplitView()
+	split.Dim = mat32.Y
+	if len(split.Kids) > 0 {
+		return
+	}
+	tv := split.AddNewChild(giv.KiT_TableView, "results").(*giv.TableView)
+	tv.SetStretchMax()
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SetInactive()
+	tv.SetSlice(&mv.Results)
+	tv.SliceViewSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.SliceViewDoubleClicked) {
+			mvv, _ := recv.Embed(KiT_MatrixView).(*MatrixView)
+			mvv.ShowOutput(data.(int))
+		}
+	})
+	ly := gi.AddNewLayout(split, "out", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.5, .5)
+}
+
+// InitResults resets the results to the targets of the matrix, waiting
+// to be built
+func (mv *MatrixView) InitResults() {
+	pf := mv.Gide.ProjPrefs()
+	mv.Mu.Lock()
+	mv.Results = nil
+	for _, bt := range pf.Matrix.Targets {
+		st := "waiting"
+		if bt.Off {
+			st = "off"
+		}
+		mv.Results = append(mv.Results, MatrixResult{Target: bt.Label(), Status: st})
+	}
+	mv.Mu.Unlock()
+	mv.UpdateResults()
+}
+
+// UpdateResults updates the table of the results
+func (mv *MatrixView) UpdateResults() {
+	vp := mv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	tv := mv.TableView()
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	mv.Mu.Lock()
+	tv.SetSlice(&mv.Results)
+	mv.Mu.Unlock()
+	tv.UpdateEnd(updt)
+}
+
+// SetResult sets the status of the result of given index, updating the
+// view
+func (mv *MatrixView) SetResult(idx int, fun func(mr *MatrixResult)) {
+	mv.Mu.Lock()
+	if idx < len(mv.Results) {
+		fun(&mv.Results[idx])
+	}
+	mv.Mu.Unlock()
+	mv.UpdateResults()
+}
+
+// EditTargets opens a dialog for editing the targets of the matrix
+func (mv *MatrixView) EditTargets() {
+	pf := mv.Gide.ProjPrefs()
+	giv.StructViewDialog(mv.Gide.VPort(), &pf.Matrix, giv.DlgOpts{Title: "Build Matrix", Prompt: "targets built by Build All, in parallel, with the executables collected in OutDir"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MatrixView).(*MatrixView)
+			mvv.Gide.ProjPrefs().Changed = true
+			if !mvv.Running {
+				mvv.InitResults()
+			}
+		})
+}
+
+// Stop stops the running builds, and the waiting ones
+func (mv *MatrixView) Stop() {
+	mv.Stopped = true
+	for _, bt := range mv.Gide.ProjPrefs().Matrix.Targets {
+		mv.Gide.CmdRuns().KillByName(MatrixCmdName + " " + bt.Label())
+	}
+}
+
+// Build builds all the targets of the matrix in the background, at most
+// Parallel at a time, setting the problems of the failed ones
+func (mv *MatrixView) Build() {
+	if mv.Running {
+		mv.Gide.SetStatus("the build matrix is already building")
+		return
+	}
+	ge := mv.Gide
+	pf := ge.ProjPrefs()
+	bm := pf.Matrix
+	bm.Targets = append([]BuildTarget(nil), pf.Matrix.Targets...)
+	if len(bm.Targets) == 0 {
+		ge.SetStatus("no targets in the build matrix: use Add Common or Targets... to add them")
+		return
+	}
+	dir := bm.MainDir(pf)
+	odir := bm.Dir(pf)
+	if err := os.MkdirAll(odir, 0755); err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	mv.InitResults()
+	mv.Running = true
+	mv.Stopped = false
+	par := bm.Parallel
+	if par <= 0 {
+		par = runtime.NumCPU()
+	}
+	go func() {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, par)
+		var nok, nfail int
+		var cmu sync.Mutex
+		for i := range bm.Targets {
+			bt := &bm.Targets[i]
+			if bt.Off {
+				continue
+			}
+			wg.Add(1)
+			go func(idx int, bt *BuildTarget) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				if mv.Stopped {
+					mv.SetResult(idx, func(mr *MatrixResult) { mr.Status = "stopped" })
+					return
+				}
+				mv.SetResult(idx, func(mr *MatrixResult) { mr.Status = "building" })
+				out := filepath.Join(odir, bm.OutName(dir, bt))
+				st := time.Now()
+				bout, err := BuildMatrixTarget(ge, &bm, bt, dir, out)
+				dur := time.Since(st).Round(time.Millisecond)
+				if err == nil {
+					if af, ok := NewArtifact(out, MatrixCmdName, bt.Label()); ok {
+						ge.AddArtifacts([]Artifact{af})
+					}
+				}
+				probs := ParseProblems([]byte(bout), dir, MatrixCmdName+" "+bt.Label(), ProblemError)
+				ge.SetProblems(MatrixCmdName+" "+bt.Label(), probs)
+				cmu.Lock()
+				if err == nil {
+					nok++
+				} else {
+					nfail++
+				}
+				cmu.Unlock()
+				mv.SetResult(idx, func(mr *MatrixResult) {
+					mr.Time = dur
+					mr.Output = bout
+					switch {
+					case err == nil:
+						mr.Status = "ok"
+						mr.Artifact = out
+					case mv.Stopped:
+						mr.Status = "stopped"
+					default:
+						mr.Status = "failed"
+						if mr.Output == "" {
+							mr.Output = err.Error()
+						}
+					}
+				})
+			}(i, bt)
+		}
+		wg.Wait()
+		mv.Running = false
+		ge.SetStatus(fmt.Sprintf("build matrix: %d ok, %d failed, executables in: %v", nok, nfail, odir))
+	}()
+}
+
+// ShowOutput shows the output of the build of the target of given index
+func (mv *MatrixView) ShowOutput(idx int) {
+	mv.Mu.Lock()
+	if idx < 0 || idx >= len(mv.Results) {
+		mv.Mu.Unlock()
+		return
+	}
+	mr := mv.Results[idx]
+	mv.Mu.Unlock()
+	out := mr.Output
+	if out == "" {
+		out = mr.Status
+	}
+	lns := bytes.Split([]byte(strings.TrimRight(mr.Target+": "+out, "\n")), []byte("\n"))
+	mus := make([][]byte, len(lns))
+	for i, ln := range lns {
+		mus[i] = MarkupCmdOutput(ln)
+	}
+	tbuf := mv.TextView().Buf
+	tbuf.New(0)
+	tbuf.SetInactive(true)
+	tbuf.AppendTextMarkup(bytes.Join(lns, []byte("\n")), bytes.Join(mus, []byte("\n")), giv.EditSignal)
+}
+
+// MatrixViewProps are style properties for MatrixView
+var MatrixViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
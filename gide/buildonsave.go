@@ -0,0 +1,58 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"time"
+
+	"github.com/goki/pi/filecat"
+)
+
+// BuildOnSaveCmdName is the name of the go build ./... command run after
+// saving in Go projects without BuildCmds, and the source of its problems
+var BuildOnSaveCmdName = "Build On Save"
+
+// BuildOnSaveDelay is the time after the last save before the build is
+// run, so a burst of saves (e.g., Save All) builds once
+var BuildOnSaveDelay = 750 * time.Millisecond
+
+// BuildOnSaveCmds returns the commands built after saving in given
+// project: its BuildCmds, or go build ./... for Go projects without them
+// -- commands that prompt for values are left out, as they are run in the
+// background
+func BuildOnSaveCmds(pf *ProjPrefs) []*Command {
+	var cmds []*Command
+	for _, cn := range pf.BuildCmds {
+		cm, _, ok := AvailCmds.CmdByName(cn, false)
+		if !ok {
+			continue
+		}
+		if _, hasp := cm.HasPrompts(); hasp {
+			continue
+		}
+		cmds = append(cmds, cm)
+	}
+	if len(pf.BuildCmds) == 0 && pf.MainLang == filecat.Go {
+		cmds = append(cmds, &Command{Name: BuildOnSaveCmdName, Desc: "go build ./... after saving", Lang: filecat.Go,
+			Cmds: []CmdAndArgs{{Cmd: "go", Args: []string{"build", "{BuildFlags}", "./..."}}}, Dir: "{ProjPath}"})
+	}
+	return cmds
+}
+
+// RunBuildOnSave runs given build commands, and their commands, in order,
+// waiting for each, without output to a buffer, so the problems go to the
+// Problems panel without taking the focus -- returns false and the name of
+// the command that failed, if one does
+func RunBuildOnSave(ge Gide, cmds []*Command) (bool, string) {
+	for _, cm := range cmds {
+		cm.ChDir(ge, nil)
+		for i := range cm.Cmds {
+			if !cm.RunNoBuf(ge, &cm.Cmds[i]) {
+				return false, cm.Name
+			}
+		}
+	}
+	return true, ""
+}
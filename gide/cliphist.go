@@ -0,0 +1,151 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+)
+
+// ClipHistPrefs are the preferences of the clipboard history, the ring of
+// the recent copies and cuts of the text views, shared by all the projects,
+// which can be pasted with Paste History
+type ClipHistPrefs struct {
+	Max       int  `min:"0" desc:"maximum number of copies and cuts kept -- 0 to use the TextViewClipHistMax of the GoGi detailed preferences"`
+	NoSecrets bool `desc:"do not keep the copies that look like passwords, keys or tokens (a single word mixing letters, digits and symbols, or a known token or private key format)"`
+}
+
+// Defaults sets the default preferences
+func (cp *ClipHistPrefs) Defaults() {
+	cp.NoSecrets = true
+}
+
+// Apply sets the size of the clipboard history, and removes the secrets
+// from it if they are not kept
+func (cp *ClipHistPrefs) Apply() {
+	if cp.Max > 0 {
+		giv.TextViewClipHistMax = cp.Max
+		if len(giv.TextViewClipHistory) > cp.Max {
+			giv.TextViewClipHistory = giv.TextViewClipHistory[:cp.Max]
+		}
+	}
+	ClipHistFilter()
+}
+
+// ClipHistLabelLen is the maximum length of the entries shown in the Paste
+// History popup
+var ClipHistLabelLen = 60
+
+// ClipHistSecretRe matches the usual formats of the tokens and keys: GitHub,
+// GitLab, Slack, OpenAI / Stripe, AWS and Google keys, JWTs and private keys
+var ClipHistSecretRe = regexp.MustCompile(`^(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_\w{20,}|glpat-[\w-]{20,}|xox[abpr]-[\w-]{10,}|(sk|pk|rk)[-_](live_|test_)?[\w-]{16,}|AKIA[A-Z0-9]{16}|AIza[\w-]{30,}|eyJ[\w-]+\.[\w-]+\.[\w-]+)$|-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+
+// ClipHistIsSecret returns true if given copied text looks like a
+// password, key or token: a known format, or a single word of 8 to 128
+// characters mixing at least three of lowercase and uppercase letters,
+// digits and symbols, with few repeated characters, and not looking like
+// words or identifiers (runs of lowercase letters, digits at their end)
+func ClipHistIsSecret(txt []byte) bool {
+	s := string(bytes.TrimSpace(txt))
+	if ClipHistSecretRe.MatchString(s) {
+		return true
+	}
+	if len(s) < 8 || len(s) > 128 || strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+		return false
+	}
+	var low, up, dig, sym bool
+	chars := map[rune]bool{}
+	run := 0
+	for _, r := range s {
+		chars[r] = true
+		if unicode.IsLower(r) {
+			run++
+		} else {
+			run = 0
+		}
+		if run >= 6 {
+			return false
+		}
+		switch {
+		case unicode.IsLower(r):
+			low = true
+		case unicode.IsUpper(r):
+			up = true
+		case unicode.IsDigit(r):
+			dig = true
+		case r <= unicode.MaxASCII && unicode.IsPrint(r):
+			sym = true
+		default:
+			return false // non-ascii text
+		}
+	}
+	classes := 0
+	for _, c := range []bool{low, up, dig, sym} {
+		if c {
+			classes++
+		}
+	}
+	if classes < 3 || len(chars) < len(s)/2 {
+		return false
+	}
+	// identifiers and paths copied from code are not secrets
+	if strings.ContainsAny(s, "/\\()[]{}<>\"'`,;") || strings.Contains(s, "::") {
+		return false
+	}
+	return !clipHistIdentRe.MatchString(s) || clipHistDigitLetterRe.MatchString(s)
+}
+
+// clipHistIdentRe matches the (dotted) identifiers of code
+var clipHistIdentRe = regexp.MustCompile(`^[A-Za-z_]\w*(\.[A-Za-z_]\w*)*$`)
+
+// clipHistDigitLetterRe matches a digit followed by a letter, rare in
+// identifiers, which have their digits at the end of words
+var clipHistDigitLetterRe = regexp.MustCompile(`[0-9][A-Za-z]`)
+
+// ClipHistFilter removes the entries that look like secrets from the
+// clipboard history, if they are not kept per the preferences
+func ClipHistFilter() {
+	if !Prefs.ClipHist.NoSecrets {
+		return
+	}
+	ch := giv.TextViewClipHistory[:0]
+	for _, clip := range giv.TextViewClipHistory {
+		if !ClipHistIsSecret(clip) {
+			ch = append(ch, clip)
+		}
+	}
+	for i := len(ch); i < len(giv.TextViewClipHistory); i++ {
+		giv.TextViewClipHistory[i] = nil
+	}
+	giv.TextViewClipHistory = ch
+}
+
+// ClipHistLabel returns the label of given entry of the clipboard history
+// in the Paste History popup: its first non-blank line, with white space
+// compacted, up to ClipHistLabelLen, and its number of lines
+func ClipHistLabel(clip []byte) string {
+	lns := strings.Split(strings.TrimRight(string(clip), "\n"), "\n")
+	lb := ""
+	for _, ln := range lns {
+		if lb = strings.Join(strings.Fields(ln), " "); lb != "" {
+			break
+		}
+	}
+	if r := []rune(lb); len(r) > ClipHistLabelLen {
+		lb = string(r[:ClipHistLabelLen]) + "…"
+	}
+	if lb == "" {
+		lb = "(white space)"
+	}
+	if len(lns) > 1 {
+		lb += fmt.Sprintf("  [%d lines]", len(lns))
+	}
+	return lb
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/giv"
+)
+
+// DepChain returns the commands that the command depends on, with their
+// own dependencies, in the order they are run: each after those it
+// depends on, once -- returns an error for a missing command or a cycle
+func (cm *Command) DepChain() ([]*Command, error) {
+	var chain []*Command
+	done := map[string]bool{}
+	var path []string
+	var visit func(c *Command) error
+	visit = func(c *Command) error {
+		for _, pn := range path {
+			if pn == c.Name {
+				return fmt.Errorf("command dependency cycle: %v -> %v", strings.Join(path, " -> "), c.Name)
+			}
+		}
+		if done[c.Name] {
+			return nil
+		}
+		path = append(path, c.Name)
+		for _, dn := range c.DependsOn {
+			dc, _, ok := AvailCmds.CmdByName(dn, false)
+			if !ok {
+				return fmt.Errorf("command %v depends on %v, which was not found", c.Name, dn)
+			}
+			if err := visit(dc); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		done[c.Name] = true
+		if c != cm {
+			chain = append(chain, c)
+		}
+		return nil
+	}
+	if err := visit(cm); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// UpToDate returns true if the Output of the command is newer than all of
+// its Inputs, in the command directory and its subdirectories -- false if
+// they are not set
+func (cm *Command) UpToDate(avp *ArgVarVals) bool {
+	if cm.Output == "" || cm.Inputs == "" {
+		return false
+	}
+	oinfo, err := os.Stat(avp.Bind(cm.Output))
+	if err != nil {
+		return false
+	}
+	otime := oinfo.ModTime()
+	cdir := "{ProjPath}"
+	if cm.Dir != "" {
+		cdir = cm.Dir
+	}
+	var pats []string
+	for _, pt := range strings.Split(cm.Inputs, ",") {
+		if pt = strings.TrimSpace(pt); pt != "" {
+			pats = append(pats, pt)
+		}
+	}
+	fresh := true
+	root := avp.Bind(cdir)
+	filepath.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || !fresh {
+			return nil
+		}
+		if info.IsDir() {
+			if nm := info.Name(); pth != root && (nm[0] == '.' || nm[0] == '_' || nm == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, pt := range pats {
+			if ok, _ := filepath.Match(pt, info.Name()); ok && info.ModTime().After(otime) {
+				fresh = false
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	return fresh
+}
+
+// RunDeps runs given dependencies of the command, in order, waiting for
+// each, with output to the buffer -- those that are UpToDate are skipped
+// -- returns false if one fails, so the command is not run
+func (cm *Command) RunDeps(ge Gide, buf *giv.TextBuf, deps []*Command) bool {
+	for _, dc := range deps {
+		if dc.UpToDate(ge.ArgVarVals()) {
+			cm.AppendCmdOut(ge, buf, []byte(fmt.Sprintf("%v: up to date, skipped\n", dc.Name)))
+			continue
+		}
+		cm.AppendCmdOut(ge, buf, []byte(fmt.Sprintf("%v: running, before %v\n", dc.Name, cm.Name)))
+		dc.ChDir(ge, buf)
+		for i := range dc.Cmds {
+			cma := &dc.Cmds[i]
+			ok := false
+			if buf == nil {
+				ok = dc.RunNoBuf(ge, cma)
+			} else {
+				ok = dc.RunBufWait(ge, buf, cma)
+			}
+			if !ok {
+				msg := fmt.Sprintf("%v: failed, so %v is not run", dc.Name, cm.Name)
+				cm.AppendCmdOut(ge, buf, []byte(msg+"\n"))
+				ge.SetStatus(msg)
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -83,6 +83,14 @@ func (cm *CmdAndArgs) BindArgs(avp *ArgVarVals) []string {
 	}
 	args := []string{}
 	for i := range cm.Args {
+		if vr, ok := ArgVars[cm.Args[i]]; ok && vr.Type == ArgVarList {
+			for _, it := range strings.Split((*avp)[cm.Args[i]], "\n") {
+				if it != "" {
+					args = append(args, it)
+				}
+			}
+			continue
+		}
 		av := avp.Bind(cm.Args[i])
 		if len(av) > 0 && av[0] == '*' { // only allow at *start* of command -- for *.ext exprs
 			glob, err := filepath.Glob(av)
@@ -143,6 +151,7 @@ type CmdRun struct {
 	CmdStr  string      `desc:"command string"`
 	CmdArgs *CmdAndArgs `desc:"Details of the command and args"`
 	Exec    *exec.Cmd   `desc:"exec.Cmd for the command"`
+	Start   time.Time   `desc:"when the command was started"`
 }
 
 // Kill kills the process
@@ -165,7 +174,7 @@ func (rc *CmdRuns) Add(cm *CmdRun) {
 
 // AddCmd adds a new running command, creating CmdRun via args
 func (rc *CmdRuns) AddCmd(name, cmdstr string, cmdargs *CmdAndArgs, ex *exec.Cmd) {
-	cm := &CmdRun{name, cmdstr, cmdargs, ex}
+	cm := &CmdRun{name, cmdstr, cmdargs, ex, time.Now()}
 	rc.Add(cm)
 }
 
@@ -212,14 +221,18 @@ func (rc *CmdRuns) KillByName(name string) bool {
 // Command defines different types of commands that can be run in the project.
 // The output of the commands shows up in an associated tab.
 type Command struct {
-	Name    string            `width:"20" desc:"name of this command (must be unique in list of commands)"`
-	Desc    string            `width:"40" desc:"brief description of this command"`
-	Lang    filecat.Supported `desc:"supported language / file type that this command applies to -- choose Any or e.g., AnyCode for subtypes -- filters the list of commands shown based on file language type"`
-	Cmds    []CmdAndArgs      `tableview-select:"-" desc:"sequence of commands to run for this overall command."`
-	Dir     string            `width:"20" complete:"arg" desc:"if specified, will change to this directory before executing the command -- e.g., use {FileDirPath} for current file's directory -- only use directory values here -- if not specified, directory will be project root directory."`
-	Wait    bool              `desc:"if true, we wait for the command to run before displaying output -- mainly for post-save commands and those with subsequent steps: if multiple commands are present, then it uses Wait mode regardless."`
-	Focus   bool              `desc:"if true, keyboard focus is directed to the command output tab panel after the command runs."`
-	Confirm bool              `desc:"if true, command requires Ok / Cancel confirmation dialog -- only needed for non-prompt commands"`
+	Name      string            `width:"20" desc:"name of this command (must be unique in list of commands)"`
+	Desc      string            `width:"40" desc:"brief description of this command"`
+	Lang      filecat.Supported `desc:"supported language / file type that this command applies to -- choose Any or e.g., AnyCode for subtypes -- filters the list of commands shown based on file language type"`
+	Cmds      []CmdAndArgs      `tableview-select:"-" desc:"sequence of commands to run for this overall command."`
+	Dir       string            `width:"20" complete:"arg" desc:"if specified, will change to this directory before executing the command -- e.g., use {FileDirPath} for current file's directory -- only use directory values here -- if not specified, directory will be project root directory."`
+	Wait      bool              `desc:"if true, we wait for the command to run before displaying output -- mainly for post-save commands and those with subsequent steps: if multiple commands are present, then it uses Wait mode regardless."`
+	Focus     bool              `desc:"if true, keyboard focus is directed to the command output tab panel after the command runs."`
+	Confirm   bool              `desc:"if true, command requires Ok / Cancel confirmation dialog -- only needed for non-prompt commands"`
+	DependsOn CmdNames          `desc:"commands run before this one, in order, with their own dependencies, e.g., Build Go Proj for Run Proj -- this one is not run if one of them fails"`
+	Output    string            `width:"20" complete:"arg" desc:"file made by the command, e.g., {RunExecPath} -- when this and Inputs are set, the command is skipped when run as a dependency if the file is newer than all the Inputs"`
+	Inputs    string            `width:"20" desc:"glob patterns of the files the Output is made from, comma separated, e.g., *.go,go.mod -- matched in the command Dir and all its subdirectories"`
+	Shell     bool              `desc:"if true, the command line is run by the shell of the Shell preferences, after sourcing its RcFile and with its Env, so pipes, globs and aliases work -- otherwise it is run directly"`
 }
 
 // Label satisfies the Labeler interface
@@ -325,21 +338,32 @@ func (cm *Command) Run(ge Gide, buf *giv.TextBuf) {
 	cm.PromptUser(ge, buf, pvals)
 }
 
-// RunAfterPrompts runs after any prompts have been set, if needed
+// RunAfterPrompts runs after any prompts have been set, if needed -- the
+// DependsOn commands are run first, in the background
 func (cm *Command) RunAfterPrompts(ge Gide, buf *giv.TextBuf) {
 	ge.CmdRuns().KillByName(cm.Name) // make sure nothing still running for us..
 	CmdNoUserPrompt = false
-	cdir := "{ProjPath}"
-	if cm.Dir != "" {
-		cdir = cm.Dir
-	}
-	cds := ge.ArgVarVals().Bind(cdir)
-	err := os.Chdir(cds)
-	cm.AppendCmdOut(ge, buf, []byte(fmt.Sprintf("cd %v (from: %v)\n", cds, cdir)))
-	if err != nil {
-		cm.AppendCmdOut(ge, buf, []byte(fmt.Sprintf("Could not change to directory %v -- error: %v\n", cds, err)))
+	if len(cm.DependsOn) > 0 {
+		deps, err := cm.DepChain()
+		if err != nil {
+			cm.AppendCmdOut(ge, buf, []byte(err.Error()+"\n"))
+			ge.SetStatus(err.Error())
+			return
+		}
+		go func() {
+			if cm.RunDeps(ge, buf, deps) {
+				cm.RunCmds(ge, buf)
+			}
+		}()
+		return
 	}
+	cm.RunCmds(ge, buf)
+}
 
+// RunCmds runs the commands of the command, in its directory -- waiting for
+// them if Wait is set or there is more than one
+func (cm *Command) RunCmds(ge Gide, buf *giv.TextBuf) {
+	cm.ChDir(ge, buf)
 	if CmdWaitOverride || cm.Wait || len(cm.Cmds) > 1 {
 		for i := range cm.Cmds {
 			cma := &cm.Cmds[i]
@@ -363,11 +387,51 @@ func (cm *Command) RunAfterPrompts(ge Gide, buf *giv.TextBuf) {
 	}
 }
 
+// ChDir changes to the directory of the command, noting it in the output
+func (cm *Command) ChDir(ge Gide, buf *giv.TextBuf) {
+	cdir := "{ProjPath}"
+	if cm.Dir != "" {
+		cdir = cm.Dir
+	}
+	cds := ge.ArgVarVals().Bind(cdir)
+	err := os.Chdir(cds)
+	cm.AppendCmdOut(ge, buf, []byte(fmt.Sprintf("cd %v (from: %v)\n", cds, cdir)))
+	if err != nil {
+		cm.AppendCmdOut(ge, buf, []byte(fmt.Sprintf("Could not change to directory %v -- error: %v\n", cds, err)))
+	}
+}
+
+// PrepCmd prepares to run given command of this command, with the
+// environment of the project -- in the shell of the Shell preferences if
+// Shell is set, and in the Container of the project if set, or in WSL
+// (with sh)
+func (cm *Command) PrepCmd(ge Gide, cma *CmdAndArgs) (*exec.Cmd, string) {
+	cmd, cmdstr := cma.PrepCmd(ge.ArgVarVals())
+	pf := ge.ProjPrefs()
+	if pf.ExecRemote() && !IsHostCmd(cmd.Args[0]) {
+		if cm.Shell {
+			if pf.WSL.On() {
+				cmdstr = WSLPathsIn(cmdstr)
+			}
+			return pf.ExecCmd("", nil, "sh", "-c", cmdstr), cmdstr
+		}
+		return pf.ExecCmd("", nil, cmd.Args[0], cmd.Args[1:]...), cmdstr
+	}
+	env := pf.CmdEnv()
+	if cm.Shell {
+		sp := Prefs.Shell.Cur()
+		cmd = sp.Command(cmdstr)
+		env = append(env, sp.EnvList()...)
+	}
+	cmd.Env = env
+	return cmd, cmdstr
+}
+
 // RunBufWait runs a command with output to the buffer, using CombinedOutput
 // so it waits for completion -- returns overall command success, and logs one
 // line of the command output to gide statusbar
 func (cm *Command) RunBufWait(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
-	cmd, cmdstr := cma.PrepCmd(ge.ArgVarVals())
+	cmd, cmdstr := cm.PrepCmd(ge, cma)
 	ge.CmdRuns().AddCmd(cm.Name, cmdstr, cma, cmd)
 	out, err := cmd.CombinedOutput()
 	cm.AppendCmdOut(ge, buf, out)
@@ -377,14 +441,14 @@ func (cm *Command) RunBufWait(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
 // RunBuf runs a command with output to the buffer, incrementally updating the
 // buffer with new results line-by-line as they come in
 func (cm *Command) RunBuf(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
-	cmd, cmdstr := cma.PrepCmd(ge.ArgVarVals())
+	cmd, cmdstr := cm.PrepCmd(ge, cma)
 	ge.CmdRuns().AddCmd(cm.Name, cmdstr, cma, cmd)
 	stdout, err := cmd.StdoutPipe()
 	if err == nil {
 		cmd.Stderr = cmd.Stdout
 		err = cmd.Start()
 		if err == nil {
-			obuf := giv.OutBuf{}
+			obuf := OutBuf{}
 			obuf.Init(stdout, buf, 0, MarkupCmdOutput)
 			obuf.MonOut()
 		}
@@ -397,7 +461,7 @@ func (cm *Command) RunBuf(ge Gide, buf *giv.TextBuf, cma *CmdAndArgs) bool {
 // go as a goroutine for no-wait case -- returns overall command success, and
 // logs one line of the command output to gide statusbar
 func (cm *Command) RunNoBuf(ge Gide, cma *CmdAndArgs) bool {
-	cmd, cmdstr := cma.PrepCmd(ge.ArgVarVals())
+	cmd, cmdstr := cm.PrepCmd(ge, cma)
 	ge.CmdRuns().AddCmd(cm.Name, cmdstr, cma, cmd)
 	out, err := cmd.CombinedOutput()
 	return cm.RunStatus(ge, nil, cmdstr, err, out)
@@ -435,17 +499,31 @@ var CmdOutStatusLen = 80
 // ge.StatusBar -- returns true if there are no errors, and false if there
 // were errors
 func (cm *Command) RunStatus(ge Gide, buf *giv.TextBuf, cmdstr string, err error, out []byte) bool {
+	cr, _ := ge.CmdRuns().ByName(cm.Name)
 	ge.CmdRuns().DeleteByName(cm.Name)
+	if IsProblemCmd(cm.Name) || HasErrParsers(cm.Lang) {
+		pout := out
+		if buf != nil {
+			pout = buf.Text()
+		}
+		dir, _ := os.Getwd()
+		ge.SetProblems(cm.Name, ParseCmdProblems(cm.Lang, pout, dir, cm.Name, ProblemCmdSeverity(cm.Name)))
+	}
+	cm.RunSanReports(ge, buf, out)
 	var rval bool
 	outstr := ""
 	if out != nil {
-		outstr = string(out[:CmdOutStatusLen])
+		if len(out) > CmdOutStatusLen {
+			out = out[:CmdOutStatusLen]
+		}
+		outstr = string(out)
 	}
 	finstat := ""
 	tstr := time.Now().Format("Mon Jan  2 15:04:05 MST 2006")
 	if err == nil {
 		finstat = fmt.Sprintf("%v <b>successful</b> at: %v", cmdstr, tstr)
 		rval = true
+		cm.RecordArtifacts(ge, cr)
 	} else if ee, ok := err.(*exec.ExitError); ok {
 		finstat = fmt.Sprintf("%v <b>failed</b> at: %v with error: %v", cmdstr, tstr, ee.Error())
 		rval = false
@@ -468,9 +546,63 @@ func (cm *Command) RunStatus(ge Gide, buf *giv.TextBuf, cmdstr string, err error
 		}
 	}
 	ge.SetStatus(cmdstr + " " + outstr)
+	cm.NotifyDone(ge, cr, buf != nil, cmdstr, err)
+	if IsVcsCmd(cm.Name) {
+		ge.LogAction(ActionVcs, cm.Name, cmdstr, err)
+	} else {
+		ge.LogAction(ActionCmd, cm.Name, cmdstr, err)
+	}
+	ge.UpdateVcsStatus()
 	return rval
 }
 
+// NotifyDone notifies that the command finished, if it failed, or ran for
+// at least NotifyCmdMinTime -- offering to show its output, if in a tab,
+// and problems
+func (cm *Command) NotifyDone(ge Gide, cr *CmdRun, tab bool, cmdstr string, err error) {
+	if err == nil && (cr == nil || time.Since(cr.Start) < NotifyCmdMinTime) {
+		return
+	}
+	sev, msg := ProblemInfo, cmdstr+" successful"
+	if cr != nil {
+		msg += fmt.Sprintf(" in %v", time.Since(cr.Start).Round(time.Second))
+	}
+	if err != nil {
+		sev, msg = ProblemError, cmdstr+" failed: "+err.Error()
+	}
+	var acts []NotifyAction
+	if tab {
+		acts = append(acts, NotifyAction{Label: "Output", URL: "tab:///" + cm.Name})
+	}
+	if IsProblemCmd(cm.Name) || HasErrParsers(cm.Lang) {
+		acts = append(acts, NotifyAction{Label: "Problems", URL: "gide:///Problems"})
+	}
+	ge.Notify(sev, cm.Name, msg, acts...)
+}
+
+// RunSanReports parses the data races and sanitizer errors reported in the
+// output of the command, appending a summary of them grouped by report,
+// with links to their stack frames, and setting them as problems
+func (cm *Command) RunSanReports(ge Gide, buf *giv.TextBuf, out []byte) {
+	sout := out
+	if buf != nil {
+		sout = buf.Text()
+	}
+	src := cm.Name + " races"
+	if !IsSanOutput(sout) {
+		ge.SetProblems(src, nil)
+		return
+	}
+	reps := ParseSanReports(sout)
+	root := string(ge.ProjPrefs().ProjRoot)
+	ge.SetProblems(src, SanProblems(reps, root, src))
+	if buf == nil || len(reps) == 0 {
+		return
+	}
+	lns, mus := SanReportsMarkup(reps, root)
+	buf.AppendTextMarkup(append([]byte("\n"), bytes.Join(lns, []byte("\n"))...), append([]byte("\n"), bytes.Join(mus, []byte("\n"))...), giv.EditSignal)
+}
+
 // LangMatch returns true if the given language matches the command Lang constraints
 func (cm *Command) LangMatch(lang filecat.Supported) bool {
 	return filecat.IsMatch(cm.Lang, lang)
@@ -488,6 +620,9 @@ func MarkupCmdOutput(out []byte) []byte {
 		nt := bytes.Replace(out, orig, link, -1)
 		return nt
 	}
+	if nt, ok := MarkupErrLinks(out); ok { // other toolchains
+		return nt
+	}
 	return out
 }
 
@@ -554,17 +689,21 @@ func VersCtrlCmdNames(vcnm giv.VersCtrlName, cmds []string) []string {
 	vnm := strings.ToLower(string(vcnm))
 	sz := len(cmds)
 	for i := sz - 1; i >= 0; i-- {
-		cmd := strings.ToLower(cmds[i])
-		if strings.Contains(cmd, vnm) {
-			continue
-		}
-		for _, vcs := range giv.VersCtrlSystems {
-			if vcs != vnm {
-				if strings.Contains(cmd, vcs) {
-					cmds = append(cmds[:i], cmds[i+1:]...)
+		other := false
+		for _, wd := range strings.Fields(strings.ToLower(cmds[i])) {
+			if wd == vnm {
+				other = false
+				break
+			}
+			for _, vcs := range giv.VersCtrlSystems {
+				if vcs != vnm && wd == vcs {
+					other = true
 				}
 			}
 		}
+		if other {
+			cmds = append(cmds[:i], cmds[i+1:]...)
+		}
 	}
 	return cmds
 }
@@ -648,6 +787,7 @@ func (cm *Commands) SavePrefs() error {
 	err := cm.SaveJSON(gi.FileName(pnm))
 	if err == nil {
 		MergeAvailCmds()
+		SyncPrefsSaved()
 	}
 	return err
 }
@@ -768,102 +908,164 @@ const (
 	CmdNoFocus   = false
 	CmdConfirm   = true
 	CmdNoConfirm = false
+	CmdShell     = true
+	CmdNoShell   = false
 )
 
 // StdCmds is the original compiled-in set of standard commands.
 var StdCmds = Commands{
 	{"Run Proj", "run RunExec executable set in project", filecat.Any,
-		[]CmdAndArgs{{"{RunExecPath}", nil}}, "{RunExecDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"{RunExecPath}", nil}}, "{RunExecDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Run Prompt", "run any command you enter at the prompt", filecat.Any,
-		[]CmdAndArgs{{"{PromptString1}", nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"{PromptString1}", nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 
 	// Make
 	{"Make", "run make with no args", filecat.Any,
-		[]CmdAndArgs{{"make", nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"make", nil}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Make Prompt", "run make with prompted make target", filecat.Any,
-		[]CmdAndArgs{{"make", []string{"{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"make", []string{"{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 
 	// Go
 	{"Imports Go File", "run goimports on file", filecat.Go,
-		[]CmdAndArgs{{"goimports", []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"goimports", []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Fmt Go File", "run go fmt on file", filecat.Go,
-		[]CmdAndArgs{{"gofmt", []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"gofmt", []string{"-w", "{FilePath}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Build Go Dir", "run go build to build in current dir", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"build", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"build", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Build Go Proj", "run go build for project BuildDir", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"build", "-v"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"build", "-v", "{BuildFlags}", "{BuildOutFlags}"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Install Go Proj", "run go install for project BuildDir", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"install", "-v"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"install", "-v", "{BuildFlags}"}}}, "{BuildDir}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Generate Go", "run go generate in current dir", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"generate"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"generate"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Generate Go Proj", "run go generate for all the packages of the project", filecat.Go,
+		[]CmdAndArgs{{"go", []string{"generate", "./..."}}}, "{ProjPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Test Go", "run go test in current dir", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"test", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"test", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Test Go Race", "run go test with the race detector in current dir", filecat.Go,
+		[]CmdAndArgs{{"go", []string{"test", "-race", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Vet Go", "run go vet in current dir", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"vet"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"vet"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Mod Tidy Go", "run go mod tidy in current dir", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"mod", "tidy"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"mod", "tidy"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Mod Init Go", "run go mod init in current dir with module path from prompt", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"mod", "init", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"mod", "init", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Get Go", "run go get on package you enter at prompt", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Get Go Updt", "run go get -u (updt) on package you enter at prompt", filecat.Go,
-		[]CmdAndArgs{{"go", []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"go", []string{"get", "{PromptString1}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 
 	// Git
 	{"Add Git", "git add file", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"git", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Checkout Git", "git checkout file or directory -- WARNING will overwrite local changes!", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"checkout", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdConfirm},
+		[]CmdAndArgs{{"git", []string{"checkout", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdConfirm, nil, "", "", CmdNoShell},
 	{"Status Git", "git status", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"git", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Diff Git", "git diff -- see changes since last checkin", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"diff"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"git", []string{"diff"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Log Git", "git log", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"log"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"git", []string{"log"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Commit Git", "git commit", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"commit", "-am", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm}, // promptstring1 provided during normal commit process, MUST be wait!
+		[]CmdAndArgs{{"git", []string{"commit", "-am", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // promptstring1 provided during normal commit process, MUST be wait!
+	{"Commit Staged Git", "git commit of the staged changes -- as done by the commit panel", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"commit", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // promptstring1 provided by the commit panel, MUST be wait!
+	{"Amend Git", "git commit --amend -- replace the last commit with one including the staged changes", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"commit", "--amend", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // promptstring1 provided by the commit panel, MUST be wait!
 	{"Pull Git ", "git pull", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"pull"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"git", []string{"pull"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Push Git ", "git push", filecat.Any,
-		[]CmdAndArgs{{"git", []string{"push"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"git", []string{"push"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Switch Branch Git", "git checkout branch -- switch the project to the branch entered at the prompt", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"checkout", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // wait, so branch and files can be updated after
+	{"New Branch Git", "git checkout -b -- create a new branch, named at the prompt, from the current one and switch to it", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"checkout", "-b", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Fetch Prune Git", "git fetch --prune -- get the branches of all the remotes, removing those deleted there", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"fetch", "--all", "--prune"}}}, "{ProjPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"New Tag Git", "git tag -a -- create an annotated tag of the current commit, with the name and message at the prompts", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"tag", "-a", "{PromptString1}", "-m", "{PromptString2}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Delete Tag Git", "git tag -d -- delete the tag named at the prompt (only in the local repository)", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"tag", "-d", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdConfirm, nil, "", "", CmdNoShell},
+	{"Push Tag Git", "git push origin tag -- push the tag named at the prompt to the origin remote", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"push", "origin", "refs/tags/{PromptString1}"}}}, "{ProjPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Push Tags Git", "git push --tags -- push all the tags to the origin remote", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"push", "origin", "--tags"}}}, "{ProjPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Fetch PR Git", "git fetch remote refspec -- get the changes of a pull / merge request into a local branch (remote and refspec at the prompts)", filecat.Any,
+		[]CmdAndArgs{{"git", []string{"fetch", "{PromptString1}", "{PromptString2}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 
 	// SVN
 	{"Add SVN", "svn add file", filecat.Any,
-		[]CmdAndArgs{{"svn", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"svn", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Status SVN", "svn status", filecat.Any,
-		[]CmdAndArgs{{"svn", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"svn", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Info SVN", "svn info", filecat.Any,
-		[]CmdAndArgs{{"svn", []string{"info"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"svn", []string{"info"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Log SVN", "svn log", filecat.Any,
-		[]CmdAndArgs{{"svn", []string{"log", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"svn", []string{"log", "-v"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Commit SVN Proj", "svn commit for entire project directory", filecat.Any,
-		[]CmdAndArgs{{"svn", []string{"commit", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm}, // promptstring1 provided during normal commit process
+		[]CmdAndArgs{{"svn", []string{"commit", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // promptstring1 provided during normal commit process
 	{"Commit SVN Dir", "svn commit in directory of current file", filecat.Any,
-		[]CmdAndArgs{{"svn", []string{"commit", "-m", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm}, // promptstring1 provided during normal commit process
+		[]CmdAndArgs{{"svn", []string{"commit", "-m", "{PromptString1}"}}}, "{FileDirPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // promptstring1 provided during normal commit process
 	{"Update SVN", "svn update", filecat.Any,
-		[]CmdAndArgs{{"svn", []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"svn", []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+
+	// Hg
+	{"Add Hg", "hg add file", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Status Hg", "hg status", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Diff Hg", "hg diff -- see changes since last checkin", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"diff"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Log Hg", "hg log", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"log"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Commit Hg", "hg commit for entire project directory", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"commit", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // promptstring1 provided during normal commit process, MUST be wait!
+	{"Pull Hg", "hg pull -u -- pull and update to the new changes", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"pull", "-u"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Push Hg", "hg push", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"push"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Update Hg", "hg update", filecat.Any,
+		[]CmdAndArgs{{"hg", []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+
+	// Fossil
+	{"Add Fossil", "fossil add file", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"add", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Status Fossil", "fossil status", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"status"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Diff Fossil", "fossil diff -- see changes since last checkin", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"diff"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Log Fossil", "fossil timeline", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"timeline"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Commit Fossil", "fossil commit for entire project directory (pushes too if autosync is on)", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"commit", "-m", "{PromptString1}"}}}, "{ProjPath}", CmdWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell}, // promptstring1 provided during normal commit process, MUST be wait!
+	{"Pull Fossil", "fossil pull", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"pull"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Push Fossil", "fossil push", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"push"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
+	{"Update Fossil", "fossil update", filecat.Any,
+		[]CmdAndArgs{{"fossil", []string{"update"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 
 	// LaTeX
 	{"LaTeX PDF", "run PDFLaTeX on file", filecat.TeX,
-		[]CmdAndArgs{{"pdflatex", []string{"-file-line-error", "-interaction=nonstopmode", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"pdflatex", []string{"-file-line-error", "-interaction=nonstopmode", "{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"BibTeX", "run BibTeX on file", filecat.TeX,
-		[]CmdAndArgs{{"bibtex", []string{"{FileNameNoExt}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"bibtex", []string{"{FileNameNoExt}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Biber", "run Biber on file", filecat.TeX,
-		[]CmdAndArgs{{"biber", []string{"{FileNameNoExt}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"biber", []string{"{FileNameNoExt}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"CleanTeX", "remove aux LaTeX files", filecat.TeX,
-		[]CmdAndArgs{{"rm", []string{"*.aux", "*.log", "*.blg", "*.bbl", "*.fff", "*.lof", "*.ttt", "*.toc", "*.spl"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"rm", []string{"*.aux", "*.log", "*.blg", "*.bbl", "*.fff", "*.lof", "*.ttt", "*.toc", "*.spl"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 
 	// Generic files / images / etc
 	{"Open File", "open file using OS 'open' command", filecat.Any,
-		[]CmdAndArgs{{"open", []string{"{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"open", []string{"{FilePath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Open Target File", "open project target file using OS 'open' command", filecat.Any,
-		[]CmdAndArgs{{"open", []string{"{RunExecPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"open", []string{"{RunExecPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 
 	// Misc
 	{"List Dir", "list current dir", filecat.Any,
-		[]CmdAndArgs{{"ls", []string{"-la"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"ls", []string{"-la"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 	{"Grep", "recursive grep of all files for prompted value", filecat.Any,
-		[]CmdAndArgs{{"grep", []string{"-R", "-e", "{PromptString1}", "{FileDirPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm},
+		[]CmdAndArgs{{"grep", []string{"-R", "-e", "{PromptString1}", "{FileDirPath}"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell},
 }
 
 // SetCompleter adds a completer to the textfield - each field
@@ -0,0 +1,468 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+)
+
+// CommitMsgsMax is the maximum number of commit messages kept in the
+// message history of a project (ProjPrefs.CommitMsgs)
+var CommitMsgsMax = 20
+
+// AddCommitMsg adds given message to the front of the commit message
+// history, removing any earlier copy of it
+func (pf *ProjPrefs) AddCommitMsg(msg string) {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return
+	}
+	msgs := []string{msg}
+	for _, m := range pf.CommitMsgs {
+		if m != msg && len(msgs) < CommitMsgsMax {
+			msgs = append(msgs, m)
+		}
+	}
+	pf.CommitMsgs = msgs
+	pf.Changed = true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    CommitView
+
+// CommitView is a widget for committing to the repository of the project
+// (see VcsRepo): it lists the changed files, with checkboxes to stage and
+// unstage them (or select the files to commit, for a VCS without staging),
+// shows the diff of the selected file, and has an editor for the commit
+// message, with the history of previous messages, and an option to amend
+// the last commit
+type CommitView struct {
+	gi.Layout
+	Gide    Gide            `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Repo    VcsRepo         `json:"-" xml:"-" copy:"-" desc:"repository of the project"`
+	Root    string          `desc:"root directory of the repository"`
+	Changes []VcsChange     `desc:"changed files of the repository"`
+	Sel     map[string]bool `desc:"files selected for the commit, for a VCS without staging"`
+	Cur     string          `desc:"path of the file whose diff is shown"`
+	Amend   bool            `desc:"amend the last commit instead of making a new one"`
+}
+
+var KiT_CommitView = kit.Types.AddType(&CommitView{}, CommitViewProps)
+
+// Config configures the view for given repository of the given project
+func (cv *CommitView) Config(ge Gide, repo VcsRepo) {
+	cv.Gide = ge
+	if cv.Repo == nil || cv.Repo.Root() != repo.Root() {
+		cv.Sel = map[string]bool{}
+	}
+	cv.Repo = repo
+	cv.Root = repo.Root()
+	cv.Lay = gi.LayoutVert
+	cv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "commitbar")
+	config.Add(gi.KiT_SplitView, "commitsplit")
+	config.Add(gi.KiT_Label, "msglabel")
+	config.Add(gi.KiT_Layout, "msgtext")
+	mods, updt := cv.ConfigChildren(config)
+	if !mods {
+		updt = cv.UpdateStart()
+	}
+	cv.ConfigToolbar()
+	cv.ConfigSplitView()
+	lbl := cv.ChildByName("msglabel", 2).(*gi.Label)
+	lbl.SetText("<b>Commit message:</b>")
+	cv.ConfigMsgView()
+	cv.UpdateEnd(updt)
+}
+
+// ToolBar returns the commit toolbar
+func (cv *CommitView) ToolBar() *gi.ToolBar {
+	return cv.ChildByName("commitbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the files and the diff
+func (cv *CommitView) SplitView() *gi.SplitView {
+	return cv.ChildByName("commitsplit", 1).(*gi.SplitView)
+}
+
+// FilesFrame returns the frame with the list of changed files
+func (cv *CommitView) FilesFrame() *gi.Frame {
+	return cv.SplitView().ChildByName("files", 0).(*gi.Frame)
+}
+
+// DiffView returns the TextView showing the diff of the selected file
+func (cv *CommitView) DiffView() *giv.TextView {
+	ly := cv.SplitView().ChildByName("diff", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// MsgView returns the TextView for editing the commit message
+func (cv *CommitView) MsgView() *giv.TextView {
+	ly := cv.ChildByName("msgtext", 3).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// Msg returns the commit message entered
+func (cv *CommitView) Msg() string {
+	tv := cv.MsgView()
+	if tv.Buf == nil {
+		return ""
+	}
+	return strings.TrimSpace(string(tv.Buf.Text()))
+}
+
+// SetMsg sets the commit message in the editor
+func (cv *CommitView) SetMsg(msg string) {
+	tv := cv.MsgView()
+	if tv.Buf != nil {
+		tv.Buf.SetText([]byte(msg))
+	}
+}
+
+// ConfigToolbar adds the toolbar actions
+func (cv *CommitView) ConfigToolbar() {
+	tb := cv.ToolBar()
+	if tb.HasChildren() {
+		tb.ChildByName("amend", 0).(*gi.CheckBox).SetInactiveState(!cv.IsStager())
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "update the list of changed files"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+			cvv.Refresh()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stage All", Icon: "plus", Tooltip: "stage (or select for the commit) the changes of all the files, including the untracked ones"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+			cvv.StageAll(true)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Unstage All", Icon: "minus", Tooltip: "unstage (or unselect) the changes of all the files, keeping them in the working copy"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+			cvv.StageAll(false)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Open", Icon: "file-open", Tooltip: "open the file whose diff is shown"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+			if cvv.Cur != "" {
+				cvv.Gide.ShowFile(filepath.Join(cvv.Root, filepath.FromSlash(cvv.Cur)), 0)
+			}
+		})
+	tb.AddSeparator("sep-stage")
+	hb := gi.AddNewMenuButton(tb, "history")
+	hb.SetText("History")
+	hb.Tooltip = "previous commit messages of this project, to use again"
+	hb.MakeMenuFunc = cv.HistoryMenu
+	cb := gi.AddNewCheckBox(tb, "amend")
+	cb.SetText("Amend")
+	cb.Tooltip = "amend the last commit with the staged changes and the message, instead of making a new commit -- the message of the last commit is used if none entered (git only)"
+	cb.SetChecked(cv.Amend)
+	cb.SetInactiveState(!cv.IsStager())
+	cb.ButtonSig.Connect(cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+			cvv.SetAmend(send.(*gi.CheckBox).IsChecked())
+		}
+	})
+	tb.AddAction(gi.ActOpts{Label: "Commit", Icon: "star", Tooltip: "commit the staged (or selected) changes with the message"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+			cvv.Commit()
+		})
+}
+
+// ConfigSplitView configures the split view of the files and the diff
+func (cv *CommitView) ConfigSplitView() {
+	split := cv.SplitView()
+	split.Dim = mat32.X
+	if len(split.Kids) > 0 {
+		return
+	}
+	fr := gi.AddNewFrame(split, "files", gi.LayoutVert)
+	fr.SetStretchMaxWidth()
+	fr.SetStretchMaxHeight()
+	fr.SetMinPrefHeight(units.NewValue(10, units.Ch))
+	ly := gi.AddNewLayout(split, "diff", gi.LayoutVert)
+	dtv := ConfigOutputTextView(ly)
+	dtv.SetProp("white-space", "pre")
+	dtv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.3, .7)
+}
+
+// ConfigMsgView configures the commit message editor
+func (cv *CommitView) ConfigMsgView() {
+	ly := cv.ChildByName("msgtext", 3).(*gi.Layout)
+	ly.Lay = gi.LayoutVert
+	ly.SetStretchMaxWidth()
+	ly.SetMinPrefHeight(units.NewValue(5, units.Em))
+	ly.SetProp("max-height", units.NewValue(10, units.Em))
+	if ly.HasChildren() {
+		return
+	}
+	tv := giv.AddNewTextView(ly, "msg")
+	tv.SetProp("line-nos", false)
+	tv.SetProp("font-family", gi.Prefs.MonoFont)
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+	tb := giv.NewTextBuf()
+	tb.Opts.LineNos = false
+	tv.SetBuf(tb)
+}
+
+// IsStager returns true if the repository stages changes (see VcsStager)
+func (cv *CommitView) IsStager() bool {
+	_, ok := cv.Repo.(VcsStager)
+	return ok
+}
+
+// IsSel returns true if the changes of given file are to be committed:
+// staged, or selected for a VCS without staging
+func (cv *CommitView) IsSel(ch *VcsChange) bool {
+	if cv.IsStager() {
+		return ch.IsStaged()
+	}
+	return cv.Sel[ch.Path]
+}
+
+// Refresh updates the list of changed files, and the diff of the selected one
+func (cv *CommitView) Refresh() {
+	chg, err := cv.Repo.Changes()
+	if err != nil {
+		cv.Gide.SetStatus(err.Error())
+	}
+	cv.Changes = chg
+	cv.ShowFiles()
+	cur := cv.Cur
+	if _, ok := cv.ChangeByPath(cur); !ok && len(chg) > 0 {
+		cur = chg[0].Path
+	}
+	cv.ShowDiff(cur)
+}
+
+// ChangeByPath returns the change for given file path, false if none
+func (cv *CommitView) ChangeByPath(fpath string) (*VcsChange, bool) {
+	for i := range cv.Changes {
+		if cv.Changes[i].Path == fpath {
+			return &cv.Changes[i], true
+		}
+	}
+	return nil, false
+}
+
+// ShowFiles shows the changed files, each with a checkbox that stages and
+// unstages (or selects) it, and a button that shows its diff
+func (cv *CommitView) ShowFiles() {
+	fr := cv.FilesFrame()
+	updt := fr.UpdateStart()
+	fr.SetFullReRender()
+	fr.DeleteChildren(ki.DestroyKids)
+	if len(cv.Changes) == 0 {
+		gi.AddNewLabel(fr, "none", "no changes")
+	}
+	for i := range cv.Changes {
+		gc := &cv.Changes[i]
+		row := gi.AddNewLayout(fr, fmt.Sprintf("row-%d", i), gi.LayoutHoriz)
+		fpath := gc.Path
+		cb := gi.AddNewCheckBox(row, "stage")
+		cb.Tooltip = "stage (checked) or unstage the changes of this file -- select them for the commit, for a VCS without staging"
+		cb.SetChecked(cv.IsSel(gc))
+		cb.ButtonSig.Connect(cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.ButtonToggled) {
+				cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+				cvv.Stage(fpath, send.(*gi.CheckBox).IsChecked())
+			}
+		})
+		ac := gi.AddNewAction(row, "file")
+		ac.SetText(gc.Status() + "  " + gc.Path)
+		ac.Tooltip = "show the diff of this file"
+		ac.SetProp("font-family", gi.Prefs.MonoFont)
+		ac.ActionSig.Connect(cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+			cvv.ShowDiff(fpath)
+		})
+	}
+	fr.UpdateEnd(updt)
+}
+
+// ShowDiff shows the diff of the file at given path (relative to the
+// repository root)
+func (cv *CommitView) ShowDiff(fpath string) {
+	cv.Cur = fpath
+	dtv := cv.DiffView()
+	dbuf := dtv.Buf
+	if dbuf == nil {
+		return
+	}
+	dbuf.SetHiStyle(cv.Gide.ProjPrefs().ProjHiStyle())
+	dbuf.Info.Name = "changes.diff"
+	dbuf.Info.Sup = filecat.Diff
+	gc, ok := cv.ChangeByPath(fpath)
+	if !ok {
+		dbuf.SetText(nil)
+		return
+	}
+	diff, err := cv.Repo.Diff(gc)
+	if err != nil {
+		diff = []byte(err.Error())
+	}
+	dbuf.SetText(diff)
+}
+
+// Stage stages (or unstages) the changes of given file, and refreshes --
+// (un)selects them for a VCS without staging
+func (cv *CommitView) Stage(fpath string, stage bool) {
+	sr, ok := cv.Repo.(VcsStager)
+	if !ok {
+		cv.Sel[fpath] = stage
+		return
+	}
+	var err error
+	if stage {
+		err = sr.Stage(fpath)
+	} else {
+		err = sr.Unstage(fpath)
+	}
+	if err != nil {
+		gi.PromptDialog(cv.Viewport, gi.DlgOpts{Title: "Staging Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+	cv.Cur = fpath
+	cv.Refresh()
+	cv.Gide.UpdateVcsStatus()
+}
+
+// StageAll stages (or unstages) the changes of all the files, and refreshes
+// -- (un)selects them for a VCS without staging
+func (cv *CommitView) StageAll(stage bool) {
+	sr, ok := cv.Repo.(VcsStager)
+	if !ok {
+		for i := range cv.Changes {
+			cv.Sel[cv.Changes[i].Path] = stage
+		}
+		cv.ShowFiles()
+		return
+	}
+	var err error
+	if stage {
+		err = sr.Stage("")
+	} else {
+		err = sr.Unstage("")
+	}
+	if err != nil {
+		gi.PromptDialog(cv.Viewport, gi.DlgOpts{Title: "Staging Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+	cv.Refresh()
+	cv.Gide.UpdateVcsStatus()
+}
+
+// SetAmend sets whether to amend the last commit -- the message of the last
+// commit is put in the editor if none entered yet
+func (cv *CommitView) SetAmend(amend bool) {
+	sr, ok := cv.Repo.(VcsStager)
+	cv.Amend = amend && ok
+	if !cv.Amend || cv.Msg() != "" {
+		return
+	}
+	if msg, err := sr.LastMsg(); err == nil {
+		cv.SetMsg(msg)
+	}
+}
+
+// HistoryMenu makes the menu of previous commit messages
+func (cv *CommitView) HistoryMenu(obj ki.Ki, m *gi.Menu) {
+	*m = gi.Menu{}
+	msgs := cv.Gide.ProjPrefs().CommitMsgs
+	if len(msgs) == 0 {
+		ac := m.AddAction(gi.ActOpts{Label: "No previous messages"}, nil, nil)
+		ac.SetInactive()
+		return
+	}
+	for i, msg := range msgs {
+		lbl := strings.SplitN(msg, "\n", 2)[0]
+		if len(lbl) > 60 {
+			lbl = lbl[:60] + "..."
+		}
+		m.AddAction(gi.ActOpts{Label: lbl, Data: i}, cv.This(),
+			func(recv, send ki.Ki, sig int64, data interface{}) {
+				cvv, _ := recv.Embed(KiT_CommitView).(*CommitView)
+				idx := send.(*gi.Action).Data.(int)
+				if idx < len(cvv.Gide.ProjPrefs().CommitMsgs) {
+					cvv.SetMsg(cvv.Gide.ProjPrefs().CommitMsgs[idx])
+				}
+			})
+	}
+}
+
+// Commit commits the staged (or selected) changes with the message, or
+// amends the last commit if Amend is set
+func (cv *CommitView) Commit() {
+	msg := cv.Msg()
+	if msg == "" {
+		gi.PromptDialog(cv.Viewport, gi.DlgOpts{Title: "No Commit Message", Prompt: "Please enter a commit message -- remember this is essential front-line documentation."}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	var files []string
+	for i := range cv.Changes {
+		if cv.IsSel(&cv.Changes[i]) {
+			files = append(files, cv.Changes[i].Path)
+		}
+	}
+	if len(files) == 0 && !cv.Amend {
+		gi.PromptDialog(cv.Viewport, gi.DlgOpts{Title: "Nothing Staged", Prompt: "No changes are staged for the commit -- check the files to commit in the list."}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	var err error
+	sr, stager := cv.Repo.(VcsStager)
+	switch {
+	case cv.Amend:
+		err = sr.Amend(msg)
+	case stager:
+		err = sr.Commit(msg, nil)
+	default:
+		err = cv.Repo.Commit(msg, files)
+	}
+	op := "commit"
+	if cv.Amend {
+		op = "amend"
+	}
+	cv.Gide.LogAction(ActionVcs, op, strings.SplitN(msg, "\n", 2)[0], err)
+	if err != nil {
+		gi.PromptDialog(cv.Viewport, gi.DlgOpts{Title: "Commit Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		cv.Refresh()
+		return
+	}
+	cv.Gide.ProjPrefs().AddCommitMsg(msg)
+	cv.SetMsg("")
+	cv.Sel = map[string]bool{}
+	if cv.Amend {
+		cv.Amend = false
+		if cb, ok := cv.ToolBar().ChildByName("amend", 0).(*gi.CheckBox); ok {
+			cb.SetChecked(false)
+		}
+	}
+	cv.Gide.SetStatus(fmt.Sprintf("committed: %v", strings.SplitN(msg, "\n", 2)[0]))
+	cv.Refresh()
+	cv.Gide.UpdateVcsStatus()
+}
+
+// CommitViewProps are style properties for CommitView
+var CommitViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -50,7 +50,7 @@ func (cn *Console) Init() {
 // MonitorOut monitors std output and appends it to the buffer
 // should be in a separate routine
 func (cn *Console) MonitorOut() {
-	obuf := giv.OutBuf{}
+	obuf := OutBuf{}
 	obuf.Init(cn.StdoutRead, cn.Buf, 0, MarkupStdout)
 	obuf.MonOut()
 }
@@ -58,7 +58,7 @@ func (cn *Console) MonitorOut() {
 // MonitorErr monitors std error and appends it to the buffer
 // should be in a separate routine
 func (cn *Console) MonitorErr() {
-	obuf := giv.OutBuf{}
+	obuf := OutBuf{}
 	obuf.Init(cn.StderrRead, cn.Buf, 0, MarkupStderr)
 	obuf.MonOut()
 }
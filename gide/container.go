@@ -0,0 +1,175 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ContainerHostCmds are the executables always run on the host, not in the
+// Container of the project: version control, and opening files
+var ContainerHostCmds = []string{"git", "svn", "bzr", "hg", "open", "xdg-open", "cmd"}
+
+// ContainerParams name the container of a project, a dev-container in
+// which its commands (build, test, run, and debugging with a headless dlv)
+// are run, with the project root mounted, and the paths in their output
+// and in the debugger translated
+type ContainerParams struct {
+	Image     string   `width:"24" desc:"image the commands run in, each in a new container removed when done (docker run --rm), with the project root mounted at the WorkDir -- e.g., golang:1.21"`
+	Container string   `width:"24" desc:"name of a running container the commands run in (docker exec), instead of a new one of the Image -- the project root must be mounted in it at the WorkDir, and the DebugPort published"`
+	Engine    string   `desc:"container engine: docker (the default) or podman"`
+	WorkDir   string   `width:"24" desc:"path of the project root in the container -- /work by default"`
+	RunArgs   []string `desc:"extra args of docker run for the Image, e.g., -v for a module cache, or --network host"`
+	DebugPort int      `desc:"port of the API server of the headless dlv in the container, published on localhost -- 2345 by default"`
+}
+
+// On returns true if the commands run in a container
+func (cp *ContainerParams) On() bool {
+	return cp.Image != "" || cp.Container != ""
+}
+
+// EngineCmd returns the container engine
+func (cp *ContainerParams) EngineCmd() string {
+	if cp.Engine == "" {
+		return "docker"
+	}
+	return cp.Engine
+}
+
+// Root returns the path of the project root in the container
+func (cp *ContainerParams) Root() string {
+	if cp.WorkDir == "" {
+		return "/work"
+	}
+	return cp.WorkDir
+}
+
+// Port returns the port of the API server of the debugger
+func (cp *ContainerParams) Port() int {
+	if cp.DebugPort <= 0 {
+		return 2345
+	}
+	return cp.DebugPort
+}
+
+// ContainerPath returns the path in the container of given host path, in
+// the project of given root -- paths outside of the project are unchanged
+func (cp *ContainerParams) ContainerPath(root, hpath string) string {
+	rel, err := filepath.Rel(root, hpath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return hpath
+	}
+	return filepath.ToSlash(filepath.Join(cp.Root(), rel))
+}
+
+// HostPath returns the host path of given path in the container, in the
+// project of given root -- paths outside of the WorkDir are unchanged
+func (cp *ContainerParams) HostPath(root, cpath string) string {
+	croot := cp.Root()
+	if cpath != croot && !strings.HasPrefix(cpath, croot+"/") {
+		return cpath
+	}
+	return filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(cpath, croot)))
+}
+
+// IsHostCmd returns true if given executable is one of ContainerHostCmds
+func IsHostCmd(name string) bool {
+	base := strings.TrimSuffix(filepath.Base(name), ".exe")
+	for _, hc := range ContainerHostCmds {
+		if base == hc {
+			return true
+		}
+	}
+	return false
+}
+
+// Command returns the command running given executable, with args (with
+// the host paths translated), in the container, in the path there of given
+// host dir, with given environment
+// variables set in the container, and given ports published on localhost
+// -- for the project of given root
+func (cp *ContainerParams) Command(root, dir string, env []string, ports []int, name string, args ...string) *exec.Cmd {
+	cdir := cp.ContainerPath(root, dir)
+	var cargs []string
+	if cp.Container != "" {
+		cargs = []string{"exec", "-i", "-w", cdir}
+	} else {
+		cargs = []string{"run", "--rm", "-i", "-v", root + ":" + cp.Root(), "-w", cdir}
+		for _, p := range ports {
+			ps := strconv.Itoa(p)
+			cargs = append(cargs, "-p", "127.0.0.1:"+ps+":"+ps)
+		}
+	}
+	for _, ev := range env {
+		cargs = append(cargs, "-e", ev)
+	}
+	if cp.Container != "" {
+		cargs = append(cargs, cp.Container)
+	} else {
+		cargs = append(cargs, cp.RunArgs...)
+		cargs = append(cargs, cp.Image)
+	}
+	cargs = append(cargs, cp.ContainerArg(root, name))
+	for _, a := range args {
+		cargs = append(cargs, cp.ContainerArg(root, a))
+	}
+	cmd := exec.Command(cp.EngineCmd(), cargs...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	return cmd
+}
+
+// ContainerArg returns given arg with the host paths of the project of
+// given root in it translated to the container, e.g., for -o=/proj/bin/x
+func (cp *ContainerParams) ContainerArg(root, arg string) string {
+	if !strings.Contains(arg, root) {
+		return arg
+	}
+	if idx := strings.Index(arg, "="); idx > 0 && strings.HasPrefix(arg[idx+1:], root) {
+		return arg[:idx+1] + cp.ContainerPath(root, arg[idx+1:])
+	}
+	if strings.HasPrefix(arg, root) {
+		return cp.ContainerPath(root, arg)
+	}
+	return arg
+}
+
+// ExecRemote returns true if the commands of the project run in its
+// Container, or in WSL
+func (pf *ProjPrefs) ExecRemote() bool {
+	return pf.Container.On() || pf.WSL.On()
+}
+
+// ExecCmd returns the command running given executable, with args, in
+// given dir, with the environment of the commands of the project, plus
+// given env -- in the Container of the project if set, or in WSL, with the
+// paths translated, other than the ContainerHostCmds
+func (pf *ProjPrefs) ExecCmd(dir string, env []string, name string, args ...string) *exec.Cmd {
+	return pf.ExecCmdPorts(dir, env, nil, name, args...)
+}
+
+// ExecCmdPorts is ExecCmd, publishing given ports of the container on
+// localhost, e.g., that of a debugger
+func (pf *ProjPrefs) ExecCmdPorts(dir string, env []string, ports []int, name string, args ...string) *exec.Cmd {
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	switch {
+	case IsHostCmd(name):
+	case pf.Container.On():
+		root := string(pf.ProjRoot)
+		return pf.Container.Command(root, dir, append(pf.ProjEnv(), env...), ports, name, args...)
+	case pf.WSL.On():
+		return pf.WSL.Command(dir, append(pf.ProjEnv(), env...), name, args...)
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = append(pf.CmdEnv(), env...)
+	return cmd
+}
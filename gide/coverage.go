@@ -0,0 +1,543 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// CoverBlock is a block of statements of a coverage profile, with the
+// number of times it was run
+type CoverBlock struct {
+	StLine int `desc:"starting line, 1-based"`
+	StCol  int `desc:"starting column, 1-based"`
+	EdLine int `desc:"ending line, 1-based"`
+	EdCol  int `desc:"ending column, 1-based"`
+	NStmt  int `desc:"number of statements in the block"`
+	Count  int `desc:"number of times the block was run -- 0 = not covered"`
+}
+
+// CoverFile is the coverage of one source file
+type CoverFile struct {
+	Path   string       `desc:"full path of the file"`
+	Pkg    string       `desc:"import path of the package of the file"`
+	Blocks []CoverBlock `desc:"the blocks of statements of the file"`
+}
+
+// Stmts returns the number of statements covered, and the total
+func (cf *CoverFile) Stmts() (cov, tot int) {
+	for _, bl := range cf.Blocks {
+		tot += bl.NStmt
+		if bl.Count > 0 {
+			cov += bl.NStmt
+		}
+	}
+	return
+}
+
+// Lines returns the 0-based lines of the file that are in blocks, true if
+// covered: a line is covered if any of its blocks is
+func (cf *CoverFile) Lines() map[int]bool {
+	lns := map[int]bool{}
+	for _, bl := range cf.Blocks {
+		for ln := bl.StLine - 1; ln < bl.EdLine; ln++ {
+			lns[ln] = lns[ln] || bl.Count > 0
+		}
+	}
+	return lns
+}
+
+// CoverPct returns the percent of given covered statements of the total
+func CoverPct(cov, tot int) float64 {
+	if tot == 0 {
+		return 0
+	}
+	return 100 * float64(cov) / float64(tot)
+}
+
+// CoverProfile is a coverage profile written by go test -coverprofile, by
+// file, for marking the covered and uncovered lines and showing the
+// coverage of the files and packages
+type CoverProfile struct {
+	Mode  string                `desc:"mode of the profile: set, count or atomic"`
+	Files map[string]*CoverFile `desc:"coverage of the files, by full path"`
+	Mu    sync.Mutex            `json:"-" xml:"-" view:"-" desc:"mutex protecting updates"`
+}
+
+// ParseCoverProfile parses the given coverage profile, whose file names are
+// the import paths of the packages plus the file name, mapping them to the
+// directories of the packages with go list run in given directory --
+// blocks listed more than once (with -coverpkg) are merged
+func ParseCoverProfile(src []byte, dir string, env []string) (string, map[string]*CoverFile, error) {
+	mode := ""
+	type blkey struct {
+		fnm                string
+		stl, stc, edl, edc int
+	}
+	blks := map[blkey]*CoverBlock{}
+	files := map[string][]*CoverBlock{}
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	for sc.Scan() {
+		ln := strings.TrimSpace(sc.Text())
+		if ln == "" {
+			continue
+		}
+		if strings.HasPrefix(ln, "mode:") {
+			mode = strings.TrimSpace(strings.TrimPrefix(ln, "mode:"))
+			continue
+		}
+		ci := strings.LastIndex(ln, ":")
+		if ci < 0 {
+			return "", nil, fmt.Errorf("gide: bad coverage profile line: %v", ln)
+		}
+		fnm := ln[:ci]
+		bl := CoverBlock{}
+		if _, err := fmt.Sscanf(ln[ci+1:], "%d.%d,%d.%d %d %d", &bl.StLine, &bl.StCol, &bl.EdLine, &bl.EdCol, &bl.NStmt, &bl.Count); err != nil {
+			return "", nil, fmt.Errorf("gide: bad coverage profile line: %v: %v", ln, err)
+		}
+		key := blkey{fnm, bl.StLine, bl.StCol, bl.EdLine, bl.EdCol}
+		if ob, has := blks[key]; has {
+			ob.Count += bl.Count
+			continue
+		}
+		nb := &bl
+		blks[key] = nb
+		files[fnm] = append(files[fnm], nb)
+	}
+	if mode == "" {
+		return "", nil, fmt.Errorf("gide: not a coverage profile: no mode line")
+	}
+	pkgs := map[string]bool{}
+	for fnm := range files {
+		pkgs[path.Dir(fnm)] = true
+	}
+	dirs := CoverPkgDirs(dir, env, pkgs)
+	cfs := map[string]*CoverFile{}
+	for fnm, bls := range files {
+		pkg := path.Dir(fnm)
+		fpath := ""
+		if pd, has := dirs[pkg]; has {
+			fpath = filepath.Join(pd, path.Base(fnm))
+		} else if strings.HasPrefix(fnm, "_/") { // outside of any module or GOPATH
+			fpath = filepath.FromSlash(fnm[1:])
+		} else {
+			fpath = filepath.Join(dir, filepath.FromSlash(fnm))
+		}
+		cf := &CoverFile{Path: fpath, Pkg: pkg}
+		for _, bl := range bls {
+			cf.Blocks = append(cf.Blocks, *bl)
+		}
+		sort.Slice(cf.Blocks, func(i, j int) bool {
+			bi, bj := cf.Blocks[i], cf.Blocks[j]
+			return bi.StLine < bj.StLine || (bi.StLine == bj.StLine && bi.StCol < bj.StCol)
+		})
+		cfs[fpath] = cf
+	}
+	return mode, cfs, nil
+}
+
+// CoverPkgDirs returns the directories of given packages, by import path,
+// from go list run in given directory -- packages that are not found are
+// left out
+func CoverPkgDirs(dir string, env []string, pkgs map[string]bool) map[string]string {
+	dirs := map[string]string{}
+	if len(pkgs) == 0 {
+		return dirs
+	}
+	args := []string{"list", "-e", "-f", "{{.ImportPath}}\t{{.Dir}}"}
+	for pkg := range pkgs {
+		args = append(args, pkg)
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	out, _ := cmd.Output()
+	for _, ln := range strings.Split(string(out), "\n") {
+		fs := strings.SplitN(ln, "\t", 2)
+		if len(fs) == 2 && fs[1] != "" {
+			dirs[fs[0]] = fs[1]
+		}
+	}
+	return dirs
+}
+
+// Open reads and parses the coverage profile in given file, see
+// ParseCoverProfile
+func (cp *CoverProfile) Open(fname, dir string, env []string) error {
+	src, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return err
+	}
+	mode, cfs, err := ParseCoverProfile(src, dir, env)
+	if err != nil {
+		return err
+	}
+	cp.Mu.Lock()
+	cp.Mode, cp.Files = mode, cfs
+	cp.Mu.Unlock()
+	return nil
+}
+
+// Clear removes the coverage
+func (cp *CoverProfile) Clear() {
+	cp.Mu.Lock()
+	cp.Mode, cp.Files = "", nil
+	cp.Mu.Unlock()
+}
+
+// IsEmpty returns true if there is no coverage
+func (cp *CoverProfile) IsEmpty() bool {
+	cp.Mu.Lock()
+	defer cp.Mu.Unlock()
+	return len(cp.Files) == 0
+}
+
+// File returns the coverage of the file at given full path, nil if none
+func (cp *CoverProfile) File(fpath string) *CoverFile {
+	cp.Mu.Lock()
+	defer cp.Mu.Unlock()
+	return cp.Files[fpath]
+}
+
+// ByPkg returns the files, sorted by package and path, and the sorted
+// packages
+func (cp *CoverProfile) ByPkg() (map[string][]*CoverFile, []string) {
+	cp.Mu.Lock()
+	defer cp.Mu.Unlock()
+	bypkg := map[string][]*CoverFile{}
+	for _, cf := range cp.Files {
+		bypkg[cf.Pkg] = append(bypkg[cf.Pkg], cf)
+	}
+	pkgs := make([]string, 0, len(bypkg))
+	for pkg, cfs := range bypkg {
+		pkgs = append(pkgs, pkg)
+		sort.Slice(cfs, func(i, j int) bool { return cfs[i].Path < cfs[j].Path })
+	}
+	sort.Strings(pkgs)
+	return bypkg, pkgs
+}
+
+// Stmts returns the number of statements covered over all the files, and
+// the total
+func (cp *CoverProfile) Stmts() (cov, tot int) {
+	cp.Mu.Lock()
+	defer cp.Mu.Unlock()
+	for _, cf := range cp.Files {
+		c, t := cf.Stmts()
+		cov += c
+		tot += t
+	}
+	return
+}
+
+// CoverColors are the gutter colors of the covered and uncovered lines
+var CoverColors = [2]string{"#ef9a9a", "#a5d6a7"}
+
+// CoverLinesProp is the property of a TextBuf holding the lines marked
+// with the coverage colors, as distinct from breakpoints
+var CoverLinesProp = "gide-cover-lines"
+
+// MarkCoverage colors the line number gutter of the covered and uncovered
+// lines of given buffer, as from CoverFile.Lines, replacing the previous
+// marks -- lines with breakpoints keep their color -- nil to clear
+func MarkCoverage(tb *giv.TextBuf, lns map[int]bool) {
+	if tb == nil {
+		return
+	}
+	olns, had := tb.Prop(CoverLinesProp).(map[int]bool)
+	if had {
+		for ln := range olns {
+			tb.DeleteLineColor(ln)
+		}
+		tb.DeleteProp(CoverLinesProp)
+	}
+	if len(lns) == 0 {
+		if had {
+			tb.RefreshViews()
+		}
+		return
+	}
+	mlns := map[int]bool{}
+	for ln, cov := range lns {
+		if ln >= tb.NLines || tb.HasLineColor(ln) {
+			continue
+		}
+		clr := CoverColors[0]
+		if cov {
+			clr = CoverColors[1]
+		}
+		tb.SetLineColor(ln, clr)
+		mlns[ln] = true
+	}
+	tb.SetProp(CoverLinesProp, mlns)
+	tb.RefreshViews()
+}
+
+// IsCoverLine returns true if the color of given line of the buffer is a
+// coverage mark
+func IsCoverLine(tb *giv.TextBuf, ln int) bool {
+	lns, ok := tb.Prop(CoverLinesProp).(map[int]bool)
+	return ok && lns[ln]
+}
+
+// UnmarkCoverLine removes given line from the coverage marks, when its
+// color is replaced, e.g., by a breakpoint
+func UnmarkCoverLine(tb *giv.TextBuf, ln int) {
+	if lns, ok := tb.Prop(CoverLinesProp).(map[int]bool); ok && lns[ln] {
+		delete(lns, ln)
+		tb.DeleteLineColor(ln)
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    CoverView
+
+// CoverView is a widget that runs the tests with coverage, and shows the
+// coverage of the packages and their files, with links to them
+type CoverView struct {
+	gi.Layout
+	Gide    Gide          `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Profile *CoverProfile `json:"-" xml:"-" copy:"-" desc:"the coverage profile shown"`
+	Output  string        `json:"-" xml:"-" desc:"output of the last go test run"`
+	Running bool          `json:"-" xml:"-" desc:"true while the tests are being run"`
+}
+
+var KiT_CoverView = kit.Types.AddType(&CoverView{}, CoverViewProps)
+
+// CoverCmdName is the name of the coverage runs in the CmdRuns of the
+// project, for stopping them
+var CoverCmdName = "Test Coverage"
+
+// Config configures the view to show given profile
+func (cv *CoverView) Config(ge Gide, cp *CoverProfile) {
+	cv.Gide = ge
+	cv.Profile = cp
+	cv.Lay = gi.LayoutVert
+	cv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "coverbar")
+	config.Add(gi.KiT_Layout, "covertext")
+	mods, updt := cv.ConfigChildren(config)
+	if !mods {
+		updt = cv.UpdateStart()
+	}
+	cv.ConfigToolbar()
+	ConfigOutputTextView(cv.TextViewLay())
+	cv.UpdateEnd(updt)
+}
+
+// ToolBar returns the coverage toolbar
+func (cv *CoverView) ToolBar() *gi.ToolBar {
+	return cv.ChildByName("coverbar", 0).(*gi.ToolBar)
+}
+
+// TextViewLay returns the coverage TextView layout
+func (cv *CoverView) TextViewLay() *gi.Layout {
+	return cv.ChildByName("covertext", 1).(*gi.Layout)
+}
+
+// TextView returns the coverage TextView
+func (cv *CoverView) TextView() *giv.TextView {
+	return cv.TextViewLay().ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (cv *CoverView) ConfigToolbar() {
+	tb := cv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Run Package", Icon: "play", Tooltip: "run the tests of the package of the active file with coverage"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CoverView).(*CoverView)
+			cvv.Run(false)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Run All", Icon: "fast-fwd", Tooltip: "run the tests of all the packages of the project with coverage"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CoverView).(*CoverView)
+			cvv.Run(true)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop the tests being run"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CoverView).(*CoverView)
+			cvv.Gide.CmdRuns().KillByName(CoverCmdName)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear", Icon: "close", Tooltip: "remove the coverage marks from the files"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CoverView).(*CoverView)
+			cvv.Gide.ClearCoverage()
+		})
+}
+
+// CoverProfilePath returns the path of the coverage profile written by the
+// runs of this process
+func CoverProfilePath() string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gide-cover-%d.out", os.Getpid()))
+}
+
+// Run runs go test -coverprofile in the background, for the package of the
+// active file, or all the packages of the project, and then shows the
+// coverage and marks it in the open files
+func (cv *CoverView) Run(all bool) {
+	if cv.Running {
+		cv.Gide.SetStatus("the tests are already being run")
+		return
+	}
+	ge := cv.Gide
+	dir := string(ge.ProjPrefs().ProjRoot)
+	pkg := "./..."
+	if !all {
+		pkg = "."
+		if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil && tv.Buf.Filename != "" {
+			dir = filepath.Dir(string(tv.Buf.Filename))
+		}
+	}
+	cv.Running = true
+	cv.Output = ""
+	cv.ShowCoverage()
+	ge.SetStatus(fmt.Sprintf("running tests with coverage in: %v", dir))
+	go func() {
+		err := cv.RunCover(dir, pkg)
+		cv.Running = false
+		if cv.This() == nil || cv.IsDeleted() || cv.IsDestroyed() {
+			return
+		}
+		vp := ge.VPort()
+		wupdt := vp.TopUpdateStart()
+		ge.SetProblems(CoverCmdName, ParseProblems([]byte(cv.Output), dir, CoverCmdName, ProblemError))
+		if err != nil {
+			ge.SetStatus(fmt.Sprintf("coverage: %v", err))
+		} else {
+			cov, tot := cv.Profile.Stmts()
+			ge.SetStatus(fmt.Sprintf("coverage: %.1f%% of statements", CoverPct(cov, tot)))
+		}
+		ge.UpdateCoverage()
+		vp.TopUpdateEnd(wupdt)
+	}()
+}
+
+// RunCover runs go test -coverprofile for given package in given directory,
+// and reads the profile -- the output is saved in Output
+func (cv *CoverView) RunCover(dir, pkg string) error {
+	ge := cv.Gide
+	prof := CoverProfilePath()
+	defer os.Remove(prof)
+	args := []string{"test", "-coverprofile=" + prof, pkg}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = ge.ProjPrefs().CmdEnv()
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cma := &CmdAndArgs{Cmd: "go", Args: args}
+	ge.CmdRuns().AddCmd(CoverCmdName, "go "+strings.Join(args, " "), cma, cmd)
+	err := cmd.Start()
+	if err == nil {
+		err = cmd.Wait()
+	}
+	ge.CmdRuns().DeleteByName(CoverCmdName)
+	cv.Output = out.String()
+	if _, serr := os.Stat(prof); serr != nil {
+		cv.Profile.Clear()
+		if err == nil {
+			err = serr
+		}
+		return err
+	}
+	if perr := cv.Profile.Open(prof, dir, cmd.Env); perr != nil {
+		return perr
+	}
+	if err != nil {
+		return fmt.Errorf("tests failed: %v", err) // profile still written for the passing packages
+	}
+	return nil
+}
+
+// ShowCoverage shows the total coverage, the coverage of each package and
+// its files, with file:/// links to them, and the output of the last run
+func (cv *CoverView) ShowCoverage() {
+	if cv.Profile == nil {
+		return
+	}
+	root := string(cv.Gide.ProjPrefs().ProjRoot)
+	var outlns, outmus [][]byte
+	addln := func(ln, mu string) {
+		outlns = append(outlns, []byte(ln))
+		outmus = append(outmus, []byte(mu))
+	}
+	switch {
+	case cv.Running:
+		addln("running tests with coverage...", "<i>running tests with coverage...</i>")
+	case cv.Profile.IsEmpty():
+		addln("no coverage: Run Package or Run All", "no coverage: Run Package or Run All")
+	default:
+		cov, tot := cv.Profile.Stmts()
+		lstr := fmt.Sprintf("total: %.1f%% of %d statements", CoverPct(cov, tot), tot)
+		addln(lstr, "<b>"+lstr+"</b>")
+		addln("", "")
+		bypkg, pkgs := cv.Profile.ByPkg()
+		for _, pkg := range pkgs {
+			pcov, ptot := 0, 0
+			for _, cf := range bypkg[pkg] {
+				c, t := cf.Stmts()
+				pcov += c
+				ptot += t
+			}
+			lstr := fmt.Sprintf("%v: %.1f%% (%d/%d)", pkg, CoverPct(pcov, ptot), pcov, ptot)
+			addln(lstr, "<b>"+html.EscapeString(lstr)+"</b>")
+			for _, cf := range bypkg[pkg] {
+				c, t := cf.Stmts()
+				fnm := cf.Path
+				if rel, err := filepath.Rel(root, cf.Path); err == nil && !RelOutside(rel) {
+					fnm = filepath.ToSlash(rel)
+				}
+				pct := fmt.Sprintf("%.1f%% (%d/%d)", CoverPct(c, t), c, t)
+				addln(fmt.Sprintf("\t%v: %v", fnm, pct), fmt.Sprintf(`	<a href="file:///%v#L1">%v</a>: %v`, cf.Path, html.EscapeString(fnm), pct))
+			}
+			addln("", "")
+		}
+	}
+	if cv.Output != "" {
+		addln("", "")
+		for _, ln := range strings.Split(strings.TrimRight(cv.Output, "\n"), "\n") {
+			addln(ln, html.EscapeString(ln))
+		}
+	}
+	ctv := cv.TextView()
+	cbuf := ctv.Buf
+	if cbuf == nil {
+		return
+	}
+	cbuf.New(0)
+	cbuf.SetInactive(true)
+	cbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), giv.EditSignal)
+	ctv.SetCursorShow(ctv.CursorPos)
+}
+
+// CoverViewProps are style properties for CoverView
+var CoverViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseCoverProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-cover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	env := append(os.Environ(), "GOPROXY=off", "GOFLAGS=-mod=mod")
+	src := `mode: set
+_/proj/p/a.go:3.14,5.2 2 1
+_/proj/p/a.go:7.20,9.3 1 0
+_/proj/p/a.go:9.3,11.2 1 0
+_/proj/p/a.go:3.14,5.2 2 0
+_/proj/p/a.go:7.20,9.3 1 1
+example.com/none/q/b.go:1.1,2.2 3 0
+`
+	mode, files, err := ParseCoverProfile([]byte(src), dir, env)
+	if err != nil {
+		t.Fatalf("ParseCoverProfile error: %v\n", err)
+	}
+	if mode != "set" {
+		t.Errorf("ParseCoverProfile error: mode should have been: set  was: %v\n", mode)
+	}
+	apath := filepath.FromSlash("/proj/p/a.go")
+	bpath := filepath.Join(dir, "example.com", "none", "q", "b.go")
+	want := map[string]*CoverFile{
+		apath: {Path: apath, Pkg: "_/proj/p", Blocks: []CoverBlock{
+			{StLine: 3, StCol: 14, EdLine: 5, EdCol: 2, NStmt: 2, Count: 1},
+			{StLine: 7, StCol: 20, EdLine: 9, EdCol: 3, NStmt: 1, Count: 1},
+			{StLine: 9, StCol: 3, EdLine: 11, EdCol: 2, NStmt: 1, Count: 0},
+		}},
+		bpath: {Path: bpath, Pkg: "example.com/none/q", Blocks: []CoverBlock{
+			{StLine: 1, StCol: 1, EdLine: 2, EdCol: 2, NStmt: 3, Count: 0},
+		}},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("ParseCoverProfile error: should have been: %+v  was: %+v\n", want, files)
+	}
+	cf := files[apath]
+	if cf == nil {
+		return
+	}
+	if cov, tot := cf.Stmts(); cov != 3 || tot != 4 {
+		t.Errorf("Stmts error: should have been: 3 4  was: %v %v\n", cov, tot)
+	}
+	lns := map[int]bool{2: true, 3: true, 4: true, 6: true, 7: true, 8: true, 9: false, 10: false}
+	if was := cf.Lines(); !reflect.DeepEqual(was, lns) {
+		t.Errorf("Lines error: should have been: %v  was: %v\n", lns, was)
+	}
+	if pct := CoverPct(3, 4); pct != 75 {
+		t.Errorf("CoverPct error: should have been: 75  was: %v\n", pct)
+	}
+	if pct := CoverPct(0, 0); pct != 0 {
+		t.Errorf("CoverPct error: should have been: 0  was: %v\n", pct)
+	}
+
+	for _, bad := range []string{"_/proj/p/a.go:3.14,5.2 2 1\n", "mode: set\nno colon\n", "mode: set\na.go:x.y\n"} {
+		if _, _, err := ParseCoverProfile([]byte(bad), dir, env); err == nil {
+			t.Errorf("ParseCoverProfile error: %q should be an error\n", bad)
+		}
+	}
+}
@@ -0,0 +1,508 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/lex"
+)
+
+// IsCSVFile returns true if given file is a delimited text file that can be
+// viewed as a table: .csv or .tsv
+func IsCSVFile(fpath string) bool {
+	switch strings.ToLower(filepath.Ext(fpath)) {
+	case ".csv", ".tsv":
+		return true
+	}
+	return false
+}
+
+// CSVDelim returns the delimiter of the fields of given delimited text
+// file: tab for .tsv, else comma
+func CSVDelim(fpath string) rune {
+	if strings.ToLower(filepath.Ext(fpath)) == ".tsv" {
+		return '\t'
+	}
+	return ','
+}
+
+// CSVRecord is a record (row) of a delimited text file: its fields, and the
+// lines of the file it spans (several if a quoted field has newlines)
+type CSVRecord struct {
+	Fields []string `desc:"fields of the record, unquoted"`
+	StLn   int      `desc:"first line of the record"`
+	EdLn   int      `desc:"last line of the record"`
+}
+
+// ParseCSV parses given lines of delimited text into records, with fields
+// separated by delim: fields can be quoted with ", with "" for a quote
+// within them, to contain delimiters and newlines -- blank lines are
+// skipped
+func ParseCSV(lines [][]rune, delim rune) []CSVRecord {
+	var recs []CSVRecord
+	var fld []rune
+	ri := -1 // current record, while a quoted field continues on the next line
+	inq := false
+	for ln, l := range lines {
+		if n := len(l); n > 0 && l[n-1] == '\r' {
+			l = l[:n-1]
+		}
+		fst := true // at the start of a field
+		if ri < 0 {
+			if len(l) == 0 {
+				continue
+			}
+			recs = append(recs, CSVRecord{StLn: ln})
+			ri = len(recs) - 1
+		} else {
+			fld = append(fld, '\n')
+			fst = false
+		}
+		for i := 0; i < len(l); i++ {
+			r := l[i]
+			switch {
+			case inq && r == '"' && i+1 < len(l) && l[i+1] == '"':
+				fld = append(fld, '"')
+				i++
+			case inq && r == '"':
+				inq = false
+			case inq:
+				fld = append(fld, r)
+			case r == '"' && fst:
+				inq = true
+				fst = false
+			case r == delim:
+				recs[ri].Fields = append(recs[ri].Fields, string(fld))
+				fld = fld[:0]
+				fst = true
+			default:
+				fld = append(fld, r)
+				fst = false
+			}
+		}
+		if inq {
+			continue
+		}
+		recs[ri].Fields = append(recs[ri].Fields, string(fld))
+		recs[ri].EdLn = ln
+		fld = fld[:0]
+		ri = -1
+	}
+	if ri >= 0 { // unterminated quote
+		recs[ri].Fields = append(recs[ri].Fields, string(fld))
+		recs[ri].EdLn = len(lines) - 1
+	}
+	return recs
+}
+
+// FormatCSVRecord returns the delimited text of a record of given fields,
+// separated by delim, quoting those that need it
+func FormatCSVRecord(fields []string, delim rune) string {
+	var sb strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			sb.WriteRune(delim)
+		}
+		if strings.ContainsAny(f, "\"\n\r") || strings.ContainsRune(f, delim) {
+			sb.WriteString(`"` + strings.Replace(f, `"`, `""`, -1) + `"`)
+		} else {
+			sb.WriteString(f)
+		}
+	}
+	return sb.String()
+}
+
+// CSVView shows a delimited text file (.csv, .tsv) as a table, with its
+// columns aligned, sortable by clicking their header, and hideable: cells
+// edited in the table are written back to the text of the file, rewriting
+// just the records changed, as edits of its buffer that can be undone (and
+// saved as usual).  The sorting and hiding only change the view.
+type CSVView struct {
+	gi.Layout
+	Gide    Gide          `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Buf     *giv.TextBuf  `json:"-" xml:"-" copy:"-" desc:"buffer of the file, which the edits of the cells are made to"`
+	File    string        `desc:"full path of the file"`
+	Delim   rune          `desc:"delimiter of the fields"`
+	Header  bool          `desc:"the first record is the header, which names the columns"`
+	Records []CSVRecord   `json:"-" xml:"-" desc:"records of the file, as last parsed"`
+	NCols   int           `desc:"number of columns: the most fields of any record"`
+	Hidden  map[int]bool  `desc:"columns hidden"`
+	Rows    reflect.Value `json:"-" xml:"-" view:"-" desc:"pointer to the slice shown by the table: a struct per record (after the header), with the index of the record, then a string field per column"`
+	Stale   bool          `desc:"the text was edited since it was parsed -- the table is reloaded instead of applying the next cell edit"`
+	Syncing bool          `json:"-" xml:"-" view:"-" desc:"cell edits are being made to the buffer"`
+}
+
+var KiT_CSVView = kit.Types.AddType(&CSVView{}, CSVViewProps)
+
+// Config configures the view to show given file, with given buffer of it
+func (cv *CSVView) Config(ge Gide, fpath string, tb *giv.TextBuf) {
+	cv.Gide = ge
+	cv.File = fpath
+	if cv.Buf != tb {
+		if cv.Buf != nil {
+			cv.Buf.TextBufSig.Disconnect(cv.This())
+		}
+		cv.Buf = tb
+		cv.Header = true
+		cv.Hidden = nil
+		tb.TextBufSig.Connect(cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+			switch sig {
+			case int64(giv.TextBufNew):
+				if !cvv.Syncing {
+					cvv.Reload()
+				}
+			case int64(giv.TextBufInsert), int64(giv.TextBufDelete):
+				if !cvv.Syncing && !cvv.Stale {
+					cvv.Stale = true
+					cvv.UpdateSummary()
+				}
+			}
+		})
+	}
+	cv.Delim = CSVDelim(fpath)
+	cv.Lay = gi.LayoutVert
+	cv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "csvbar")
+	config.Add(gi.KiT_Label, "summary")
+	config.Add(giv.KiT_TableView, "table")
+	mods, updt := cv.ConfigChildren(config)
+	if !mods {
+		updt = cv.UpdateStart()
+	}
+	cv.ConfigToolbar()
+	tv := cv.TableView()
+	if mods {
+		tv.SetStretchMax()
+		tv.NoAdd = true
+		tv.NoDelete = true
+		tv.ViewSig.Connect(cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+			cvv.ApplyEdits()
+		})
+		tv.SliceViewSig.Connect(cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(giv.SliceViewDoubleClicked) {
+				cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+				cvv.ShowInEditor()
+			}
+		})
+	}
+	cv.Parse()
+	cv.ShowTable()
+	cv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (cv *CSVView) ToolBar() *gi.ToolBar {
+	return cv.ChildByName("csvbar", 0).(*gi.ToolBar)
+}
+
+// SummaryLabel returns the label showing the summary of the file
+func (cv *CSVView) SummaryLabel() *gi.Label {
+	return cv.ChildByName("summary", 1).(*gi.Label)
+}
+
+// TableView returns the table of the records
+func (cv *CSVView) TableView() *giv.TableView {
+	return cv.ChildByName("table", 2).(*giv.TableView)
+}
+
+// Parse parses the text of the buffer into records
+func (cv *CSVView) Parse() {
+	cv.Buf.LinesMu.RLock()
+	cv.Records = ParseCSV(cv.Buf.Lines[:cv.Buf.NLines], cv.Delim)
+	cv.Buf.LinesMu.RUnlock()
+	cv.NCols = 0
+	for _, rec := range cv.Records {
+		if len(rec.Fields) > cv.NCols {
+			cv.NCols = len(rec.Fields)
+		}
+	}
+	cv.Stale = false
+}
+
+// ColName returns the name of column of given index: its header if any,
+// else C1, C2...
+func (cv *CSVView) ColName(col int) string {
+	if cv.Header && len(cv.Records) > 0 && col < len(cv.Records[0].Fields) {
+		if hdr := strings.TrimSpace(cv.Records[0].Fields[col]); hdr != "" {
+			return hdr
+		}
+	}
+	return fmt.Sprintf("C%d", col+1)
+}
+
+// csvFieldName returns a field name for given column name: an exported
+// identifier made of its letters and digits, not yet used
+func csvFieldName(col int, name string, used map[string]bool) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			sb.WriteRune(r)
+		}
+	}
+	nm := []rune(sb.String())
+	switch {
+	case len(nm) == 0:
+		nm = []rune(fmt.Sprintf("C%d", col+1))
+	case unicode.IsLetter(nm[0]):
+		nm[0] = unicode.ToUpper(nm[0])
+	}
+	if !unicode.IsUpper(nm[0]) {
+		nm = append([]rune("C"), nm...)
+	}
+	fnm := string(nm)
+	if used[fnm] {
+		fnm = fmt.Sprintf("%s_%d", fnm, col+1)
+	}
+	used[fnm] = true
+	return fnm
+}
+
+// ShowTable shows the records in the table, making the struct type of its
+// rows for the current columns
+func (cv *CSVView) ShowTable() {
+	used := map[string]bool{"CSVRecord": true}
+	flds := []reflect.StructField{{Name: "CSVRecord", Type: reflect.TypeOf(0), Tag: `view:"-"`}}
+	for c := 0; c < cv.NCols; c++ {
+		nm := cv.ColName(c)
+		tag := fmt.Sprintf("desc:%q", nm)
+		if cv.Hidden[c] {
+			tag += ` view:"-"`
+		}
+		flds = append(flds, reflect.StructField{Name: csvFieldName(c, nm, used), Type: reflect.TypeOf(""), Tag: reflect.StructTag(tag)})
+	}
+	st := reflect.StructOf(flds)
+	rows := reflect.New(reflect.SliceOf(st))
+	rs := rows.Elem()
+	for ri, rec := range cv.Records {
+		if ri == 0 && cv.Header {
+			continue
+		}
+		rv := reflect.New(st).Elem()
+		rv.Field(0).SetInt(int64(ri))
+		for c, f := range rec.Fields {
+			rv.Field(c + 1).SetString(f)
+		}
+		rs = reflect.Append(rs, rv)
+	}
+	rows.Elem().Set(rs)
+	cv.Rows = rows
+	tv := cv.TableView()
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	tv.SetSlice(rows.Interface())
+	tv.UpdateEnd(updt)
+	cv.UpdateSummary()
+}
+
+// UpdateSummary updates the label showing the summary of the file
+func (cv *CSVView) UpdateSummary() {
+	nrec := len(cv.Records)
+	if cv.Header && nrec > 0 {
+		nrec--
+	}
+	dlm := "comma"
+	if cv.Delim == '\t' {
+		dlm = "tab"
+	}
+	sum := fmt.Sprintf("<b>%v</b>   %v records   %v columns   %v-separated", giv.DirAndFile(cv.File), nrec, cv.NCols, dlm)
+	if nh := len(cv.Hidden); nh > 0 {
+		sum += fmt.Sprintf("   %v hidden", nh)
+	}
+	if cv.Stale {
+		sum += "   <i>the text was edited -- Reload to update the table</i>"
+	}
+	cv.SummaryLabel().SetText(sum)
+}
+
+// Reload parses the text of the buffer again, and shows it
+func (cv *CSVView) Reload() {
+	vp := cv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	cv.Parse()
+	cv.ShowTable()
+}
+
+// csvTrimFields returns given fields of a record without the empty ones
+// past the n it had, padding it to the columns of the table
+func csvTrimFields(flds []string, n int) []string {
+	nf := len(flds)
+	for nf > n && flds[nf-1] == "" {
+		nf--
+	}
+	return flds[:nf]
+}
+
+// ApplyEdits makes the edits of the cells of the table to the text of the
+// buffer, rewriting the records changed -- if the text was edited since it
+// was parsed, the table is reloaded instead
+func (cv *CSVView) ApplyEdits() {
+	if cv.Buf == nil || cv.Syncing || !cv.Rows.IsValid() {
+		return
+	}
+	if cv.Stale {
+		cv.Reload()
+		cv.Gide.SetStatus("the file was edited as text -- the table was reloaded, edit the cell again")
+		return
+	}
+	chgs := map[int][]string{}
+	rs := cv.Rows.Elem()
+	for i := 0; i < rs.Len(); i++ {
+		rv := rs.Index(i)
+		ri := int(rv.Field(0).Int())
+		rec := cv.Records[ri]
+		flds := make([]string, cv.NCols)
+		for c := range flds {
+			flds[c] = rv.Field(c + 1).String()
+		}
+		flds = csvTrimFields(flds, len(rec.Fields))
+		if !reflect.DeepEqual(flds, rec.Fields) {
+			chgs[ri] = flds
+		}
+	}
+	if len(chgs) == 0 {
+		return
+	}
+	ris := make([]int, 0, len(chgs))
+	for ri := range chgs {
+		ris = append(ris, ri)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ris))) // later lines first
+	cv.Syncing = true
+	for _, ri := range ris {
+		rec := cv.Records[ri]
+		st := lex.Pos{Ln: rec.StLn}
+		ed := lex.Pos{Ln: rec.EdLn, Ch: cv.Buf.LineLen(rec.EdLn)}
+		cv.Buf.DeleteText(st, ed, true)
+		cv.Buf.InsertText(st, []byte(FormatCSVRecord(chgs[ri], cv.Delim)), true)
+	}
+	cv.Syncing = false
+	cv.Parse()
+	cv.UpdateSummary()
+}
+
+// SelRecord returns the record of the selected row, false if none
+func (cv *CSVView) SelRecord() (CSVRecord, bool) {
+	idx := cv.TableView().SelectedIdx
+	if !cv.Rows.IsValid() || idx < 0 || idx >= cv.Rows.Elem().Len() {
+		return CSVRecord{}, false
+	}
+	ri := int(cv.Rows.Elem().Index(idx).Field(0).Int())
+	if ri >= len(cv.Records) {
+		return CSVRecord{}, false
+	}
+	return cv.Records[ri], true
+}
+
+// ShowInEditor shows the selected record in the text of the file, in an
+// editor pane
+func (cv *CSVView) ShowInEditor() {
+	ln := 1
+	if rec, ok := cv.SelRecord(); ok {
+		ln = rec.StLn + 1
+	}
+	cv.Gide.ShowFile(cv.File, ln)
+}
+
+// ToggleHeader toggles whether the first record is the header naming the
+// columns, or a record
+func (cv *CSVView) ToggleHeader() {
+	cv.Header = !cv.Header
+	cv.Reload()
+}
+
+// ToggleColumn toggles whether given column is hidden
+func (cv *CSVView) ToggleColumn(col int) {
+	if cv.Hidden == nil {
+		cv.Hidden = make(map[int]bool)
+	}
+	if cv.Hidden[col] {
+		delete(cv.Hidden, col)
+	} else {
+		cv.Hidden[col] = true
+	}
+	cv.Reload()
+}
+
+// ColumnsMenu makes the menu of the columns, to hide or show them
+func (cv *CSVView) ColumnsMenu(obj ki.Ki, m *gi.Menu) {
+	*m = gi.Menu{}
+	for c := 0; c < cv.NCols; c++ {
+		lbl := "* " + cv.ColName(c)
+		if cv.Hidden[c] {
+			lbl = "  " + cv.ColName(c)
+		}
+		m.AddAction(gi.ActOpts{Label: lbl, Data: c}, cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+			ac := send.(*gi.Action)
+			cvv.ToggleColumn(ac.Data.(int))
+		})
+	}
+	if len(cv.Hidden) > 0 {
+		m.AddSeparator("sep-all")
+		m.AddAction(gi.ActOpts{Label: "Show All"}, cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+			cvv.Hidden = nil
+			cvv.Reload()
+		})
+	}
+}
+
+// ConfigToolbar adds the toolbar actions
+func (cv *CSVView) ConfigToolbar() {
+	tb := cv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Reload", Icon: "update", Tooltip: "parse the text of the file again, e.g., after it was edited as text"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+			cvv.Reload()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Header", Icon: "file-sheet", Tooltip: "toggle whether the first record is the header naming the columns, or a record"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+			cvv.ToggleHeader()
+		})
+	cm := gi.AddNewMenuButton(tb, "columns")
+	cm.SetText("Columns")
+	cm.Tooltip = "hide or show the columns -- the text of the file keeps them all"
+	cm.MakeMenuFunc = cv.ColumnsMenu
+	tb.AddSeparator("sep-edit")
+	tb.AddAction(gi.ActOpts{Label: "Show In Editor", Icon: "file-text", Tooltip: "show the selected record in the text of the file (also by double-click)"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			cvv, _ := recv.Embed(KiT_CSVView).(*CSVView)
+			cvv.ShowInEditor()
+		})
+}
+
+// CSVViewTabName returns the name of the main tab used for viewing given
+// file as a table
+func CSVViewTabName(fpath string) string {
+	return "Table: " + filepath.Base(fpath)
+}
+
+// CSVViewProps are style properties for CSVView
+var CSVViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -7,6 +7,7 @@ package gide
 import (
 	"fmt"
 	"log"
+	"os/exec"
 	"strings"
 	"time"
 
@@ -151,9 +152,25 @@ func (dv *DebugView) Start() {
 		if dv.Dbg != nil {
 			dv.Detach()
 		}
-		rootPath := string(dv.Gide.ProjPrefs().ProjRoot)
-		pars := &dv.Gide.ProjPrefs().Debug
+		pf := dv.Gide.ProjPrefs()
+		rootPath := string(pf.ProjRoot)
+		pars := &pf.Debug
 		dv.State.Mode = pars.Mode
+		pars.Listen, pars.RemoteRoot, pars.Exec = "", "", nil
+		if cp := &pf.Container; cp.On() && pars.Mode != gidebug.Attach { // headless dlv in the container
+			port := cp.Port()
+			pars.Listen = fmt.Sprintf("0.0.0.0:%d", port)
+			pars.RemoteRoot = cp.Root()
+			pars.Exec = func(dir string, args []string) *exec.Cmd {
+				return pf.ExecCmdPorts(dir, nil, []int{port}, "dlv", args...)
+			}
+		} else if pf.WSL.On() && pars.Mode != gidebug.Attach {
+			pars.Listen = fmt.Sprintf("0.0.0.0:%d", pf.WSL.Port())
+			pars.RemoteRoot = WSLPath(rootPath)
+			pars.Exec = func(dir string, args []string) *exec.Cmd {
+				return pf.ExecCmd(dir, nil, "dlv", args...)
+			}
+		}
 		pars.StatFunc = func(stat gidebug.Status) {
 			if stat == gidebug.Ready && dv.State.Mode == gidebug.Attach {
 				dv.UpdateFmState()
@@ -523,7 +540,7 @@ func (dv *DebugView) ListGlobalVars(filter string) {
 	if err != nil {
 		return
 	}
-	dv.State.GlobalVars = vrs
+	dv.State.SetGlobalVars(vrs, dv.Gide.ProjPrefs().Debug.VarMemMax)
 	dv.ShowGlobalVars(true)
 }
 
@@ -796,7 +813,7 @@ func (dv DebugView) AllVarVw() *VarsView {
 // ConsoleText returns the console TextView
 func (dv DebugView) ConsoleText() *giv.TextView {
 	tv := dv.Tabs()
-	cv := tv.TabByName("Console").Child(0).(*giv.TextView)
+	cv := tv.TabByName("Console").ChildByType(giv.KiT_TextView, ki.Embeds, 0).(*giv.TextView)
 	return cv
 }
 
@@ -859,7 +876,7 @@ func (dv *DebugView) ConfigToolBar() {
 	if gi.Prefs.IsDarkMode() {
 		stl.CurBgColor = stl.CurBgColor.Darker(75)
 	}
-	tb.AddAction(gi.ActOpts{Label: "Restart", Icon: "update", Tooltip: "(re)start the debugger on exe:" + dv.ExePath + " -- automatically rebuilds exe if any source files have changed"}, dv.This(),
+	tb.AddAction(gi.ActOpts{Label: "Restart", Icon: "update", Tooltip: "(re)start the debugger on exe:" + dv.ExePath + " -- automatically rebuilds exe if any source files have changed", Shortcut: "Control+Shift+F5"}, dv.This(),
 		func(recv, send ki.Ki, sig int64, data interface{}) {
 			dvv := recv.Embed(KiT_DebugView).(*DebugView)
 			dvv.Start()
@@ -890,13 +907,13 @@ func (dv *DebugView) ConfigToolBar() {
 			dvv.StepOut()
 			tb.UpdateActions()
 		})
-	tb.AddAction(gi.ActOpts{Label: "Single", Icon: "step-fwd", Tooltip: "steps a single CPU instruction", UpdateFunc: dv.ActionActivate}, dv.This(),
+	tb.AddAction(gi.ActOpts{Label: "Single", Icon: "step-fwd", Tooltip: "steps a single CPU instruction", Shortcut: "Shift+F7", UpdateFunc: dv.ActionActivate}, dv.This(),
 		func(recv, send ki.Ki, sig int64, data interface{}) {
 			dvv := recv.Embed(KiT_DebugView).(*DebugView)
 			dvv.StepOut()
 			tb.UpdateActions()
 		})
-	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop execution"}, dv.This(),
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop execution", Shortcut: "Shift+F5"}, dv.This(),
 		func(recv, send ki.Ki, sig int64, data interface{}) {
 			dvv := recv.Embed(KiT_DebugView).(*DebugView)
 			dvv.Stop()
@@ -0,0 +1,589 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+)
+
+// DocStructMaxLines is the maximum number of lines of a JSON / YAML file for
+// the path of the value at the cursor to be shown in the statusbar -- larger
+// files are only parsed for the structure view
+var DocStructMaxLines = 20000
+
+// DocStructOpenNodes is the maximum number of nodes of a document structure
+// for its tree to be shown all open -- only the top levels are opened beyond
+var DocStructOpenNodes = 500
+
+// DocStructLang returns the language of the structure of the file of given
+// buffer: "json" or "yaml", "" if neither
+func DocStructLang(tb *giv.TextBuf) string {
+	switch tb.Info.Sup {
+	case filecat.Json:
+		return "json"
+	case filecat.Yaml:
+		return "yaml"
+	}
+	switch strings.ToLower(filepath.Ext(string(tb.Filename))) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	}
+	return ""
+}
+
+// DocNode is a value of the structure of a JSON / YAML document: an object /
+// mapping, an array / sequence, with the values in it as children, or a
+// scalar -- the name of the node is its label in the structure tree
+type DocNode struct {
+	ki.Node
+	Key   string         `desc:"key of the value in its object / mapping"`
+	Index int            `desc:"index of the value in its array / sequence, -1 if not in one"`
+	Kind  string         `desc:"object or array for a value with the values in it as children, empty for a scalar"`
+	Value string         `desc:"text of a scalar value"`
+	Reg   textbuf.Region `desc:"region of the text of the value, starting at its key if any"`
+	ind   int            // column of the key, or item dash, of a YAML value
+}
+
+var KiT_DocNode = kit.Types.AddType(&DocNode{}, ki.Props{"EnumType:Flag": ki.KiT_Flags})
+
+// NewDocRoot returns a new root node of a document structure of given name
+func NewDocRoot(name string) *DocNode {
+	dn := &DocNode{Index: -1, ind: -1}
+	dn.InitName(dn, name)
+	return dn
+}
+
+// AddDocNode adds a node for a value of given key, or index in an array if
+// key is empty and idx >= 0, starting at given position
+func (dn *DocNode) AddDocNode(key string, idx int, st lex.Pos) *DocNode {
+	cn := dn.AddNewChild(KiT_DocNode, "").(*DocNode)
+	cn.Key = key
+	cn.Index = idx
+	cn.Reg.Start = st
+	cn.Reg.End = st
+	return cn
+}
+
+// DocParent returns the parent node, nil for the root
+func (dn *DocNode) DocParent() *DocNode {
+	if dn.Par == nil {
+		return nil
+	}
+	pn, _ := dn.Par.Embed(KiT_DocNode).(*DocNode)
+	return pn
+}
+
+// PathElem returns the element of the path to this value in its parent:
+// .key, or ["key"] for a key that is not an identifier, or [index]
+func (dn *DocNode) PathElem() string {
+	if dn.Index >= 0 {
+		return fmt.Sprintf("[%d]", dn.Index)
+	}
+	ident := dn.Key != ""
+	for i, r := range dn.Key {
+		if !(r == '_' || r == '-' && i > 0 || unicode.IsLetter(r) || unicode.IsDigit(r) && i > 0) {
+			ident = false
+			break
+		}
+	}
+	if ident {
+		return "." + dn.Key
+	}
+	return "[" + strconv.Quote(dn.Key) + "]"
+}
+
+// Path returns the path to this value from the root of the document, e.g.,
+// spec.containers[0].image -- "" for the root
+func (dn *DocNode) Path() string {
+	var els []string
+	for n := dn; n.DocParent() != nil; n = n.DocParent() {
+		els = append(els, n.PathElem())
+	}
+	var sb strings.Builder
+	for i := len(els) - 1; i >= 0; i-- {
+		sb.WriteString(els[i])
+	}
+	return strings.TrimPrefix(sb.String(), ".")
+}
+
+// Label returns the label of the node in the structure tree: its key or
+// index, with the number of values in it, or its value if a scalar
+func (dn *DocNode) Label() string {
+	lbl := dn.Key
+	if dn.Index >= 0 {
+		lbl = fmt.Sprintf("[%d]", dn.Index)
+	} else if dn.DocParent() == nil {
+		lbl = dn.Nm
+	}
+	switch dn.Kind {
+	case "object":
+		return fmt.Sprintf("%s {%d}", lbl, dn.NumChildren())
+	case "array":
+		return fmt.Sprintf("%s [%d]", lbl, dn.NumChildren())
+	}
+	val := []rune(dn.Value)
+	if len(val) > 40 {
+		val = append(val[:40], '…')
+	}
+	return lbl + ": " + string(val)
+}
+
+// SetLabels sets the names of the nodes under this one to their labels
+func (dn *DocNode) SetLabels() {
+	dn.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		if n, ok := k.(*DocNode); ok && n.DocParent() != nil {
+			n.SetName(n.Label())
+		}
+		return ki.Continue
+	})
+}
+
+// NodeAt returns the deepest node whose region contains given position,
+// this node if none under it does
+func (dn *DocNode) NodeAt(pos lex.Pos) *DocNode {
+	for _, k := range dn.Kids {
+		cn, ok := k.(*DocNode)
+		if !ok || pos.IsLess(cn.Reg.Start) || cn.Reg.End.IsLess(pos) {
+			continue
+		}
+		return cn.NodeAt(pos)
+	}
+	return dn
+}
+
+// NumNodes returns the number of nodes under this one, including it
+func (dn *DocNode) NumNodes() int {
+	n := 0
+	dn.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		n++
+		return ki.Continue
+	})
+	return n
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    JSON
+
+// jsonScanner scans the lines of a JSON document, keeping the position
+type jsonScanner struct {
+	lines [][]rune
+	pos   lex.Pos
+}
+
+// skipSpace skips the white space, and the line ends
+func (sc *jsonScanner) skipSpace() {
+	for sc.pos.Ln < len(sc.lines) {
+		ln := sc.lines[sc.pos.Ln]
+		if sc.pos.Ch >= len(ln) {
+			sc.pos.Ln++
+			sc.pos.Ch = 0
+			continue
+		}
+		if !unicode.IsSpace(ln[sc.pos.Ch]) {
+			return
+		}
+		sc.pos.Ch++
+	}
+}
+
+// peek returns the next rune after the white space, -1 at the end
+func (sc *jsonScanner) peek() rune {
+	sc.skipSpace()
+	if sc.pos.Ln >= len(sc.lines) {
+		return -1
+	}
+	return sc.lines[sc.pos.Ln][sc.pos.Ch]
+}
+
+// errorf returns an error at the position
+func (sc *jsonScanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", sc.pos.Ln+1, fmt.Sprintf(format, args...))
+}
+
+// str scans a string, returning it unquoted
+func (sc *jsonScanner) str() (string, error) {
+	ln := sc.lines[sc.pos.Ln]
+	st := sc.pos.Ch
+	for i := st + 1; i < len(ln); i++ {
+		switch ln[i] {
+		case '\\':
+			i++
+		case '"':
+			sc.pos.Ch = i + 1
+			raw := string(ln[st : i+1])
+			if s, err := strconv.Unquote(raw); err == nil {
+				return s, nil
+			}
+			return raw[1 : len(raw)-1], nil
+		}
+	}
+	return "", sc.errorf("unterminated string")
+}
+
+// value scans a value into given node, with the values in it as children
+func (sc *jsonScanner) value(dn *DocNode) error {
+	r := sc.peek()
+	switch r {
+	case -1:
+		return sc.errorf("expected a value")
+	case '{', '[':
+		dn.Kind = "object"
+		cl := '}'
+		if r == '[' {
+			dn.Kind = "array"
+			cl = ']'
+		}
+		sc.pos.Ch++
+		if sc.peek() == cl {
+			sc.pos.Ch++
+			break
+		}
+		for {
+			var cn *DocNode
+			if r == '[' {
+				sc.peek()
+				cn = dn.AddDocNode("", dn.NumChildren(), sc.pos)
+			} else {
+				if sc.peek() != '"' {
+					return sc.errorf("expected a key string")
+				}
+				kst := sc.pos
+				key, err := sc.str()
+				if err != nil {
+					return err
+				}
+				if sc.peek() != ':' {
+					return sc.errorf("expected : after key %q", key)
+				}
+				sc.pos.Ch++
+				cn = dn.AddDocNode(key, -1, kst)
+			}
+			if err := sc.value(cn); err != nil {
+				return err
+			}
+			nx := sc.peek()
+			sc.pos.Ch++
+			if nx == cl {
+				break
+			}
+			if nx != ',' {
+				sc.pos.Ch--
+				return sc.errorf("expected , or %c", cl)
+			}
+		}
+	case '"':
+		s, err := sc.str()
+		if err != nil {
+			return err
+		}
+		dn.Value = strconv.Quote(s)
+	default:
+		ln := sc.lines[sc.pos.Ln]
+		st := sc.pos.Ch
+		for sc.pos.Ch < len(ln) && strings.ContainsRune("+-.0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ", ln[sc.pos.Ch]) {
+			sc.pos.Ch++
+		}
+		dn.Value = string(ln[st:sc.pos.Ch])
+		if dn.Value == "" {
+			return sc.errorf("unexpected %q", r)
+		}
+		if _, err := strconv.ParseFloat(dn.Value, 64); err != nil && dn.Value != "true" && dn.Value != "false" && dn.Value != "null" {
+			sc.pos.Ch = st
+			return sc.errorf("invalid value %q", dn.Value)
+		}
+	}
+	dn.Reg.End = sc.pos
+	return nil
+}
+
+// ParseJSONDoc parses the structure of the JSON document of given lines
+// into given root node -- the nodes parsed before an error are kept
+func ParseJSONDoc(lines [][]rune, root *DocNode) error {
+	sc := &jsonScanner{lines: lines}
+	if sc.peek() == -1 {
+		return nil
+	}
+	root.Reg.Start = sc.pos
+	if err := sc.value(root); err != nil {
+		return err
+	}
+	if sc.peek() != -1 {
+		return sc.errorf("unexpected text after the value")
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    YAML
+
+// yamlContent returns the content of a YAML line without its comment, and
+// the column it starts at
+func yamlContent(ln []rune) (string, int) {
+	col := 0
+	for col < len(ln) && ln[col] == ' ' {
+		col++
+	}
+	ed := len(ln)
+	var quote rune
+	for i := col; i < len(ln); i++ {
+		r := ln[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == col || ln[i-1] == ' ' || ln[i-1] == '\t'):
+			ed = i
+			i = len(ln)
+		}
+	}
+	return strings.TrimRightFunc(string(ln[col:ed]), unicode.IsSpace), col
+}
+
+// yamlKey splits given YAML content into a key and its value, returning
+// false if it is not a key: value
+func yamlKey(txt string) (key, val string, ok bool) {
+	rs := []rune(txt)
+	i := 0
+	if len(rs) > 0 && (rs[0] == '"' || rs[0] == '\'') {
+		for i = 1; i < len(rs) && rs[i] != rs[0]; i++ {
+			if rs[0] == '"' && rs[i] == '\\' {
+				i++
+			}
+		}
+		i++
+		if i > len(rs) {
+			return "", "", false
+		}
+	}
+	for ; i < len(rs); i++ {
+		if rs[i] == ':' && (i+1 == len(rs) || rs[i+1] == ' ' || rs[i+1] == '\t') {
+			key = strings.TrimSpace(string(rs[:i]))
+			if uq, err := strconv.Unquote(key); err == nil {
+				key = uq
+			} else if len(key) >= 2 && key[0] == '\'' && key[len(key)-1] == '\'' {
+				key = strings.Replace(key[1:len(key)-1], "''", "'", -1)
+			}
+			return key, strings.TrimSpace(string(rs[i+1:])), true
+		}
+		if i == 0 && (rs[0] == '[' || rs[0] == '{') {
+			return "", "", false
+		}
+	}
+	return "", "", false
+}
+
+// yamlParser parses the structure of a YAML document by the indentation of
+// its lines
+type yamlParser struct {
+	stack []*DocNode
+	block *DocNode
+}
+
+// top returns the node at the top of the stack
+func (yp *yamlParser) top() *DocNode {
+	return yp.stack[len(yp.stack)-1]
+}
+
+// content adds the node of given content, at given column of given line,
+// in given parent
+func (yp *yamlParser) content(par *DocNode, txt string, ln, col int) {
+	if txt == "-" || strings.HasPrefix(txt, "- ") || strings.HasPrefix(txt, "-\t") {
+		if par.Kind == "" {
+			par.Kind = "array"
+		}
+		cn := par.AddDocNode("", par.NumChildren(), lex.Pos{Ln: ln, Ch: col})
+		cn.ind = col
+		yp.stack = append(yp.stack, cn)
+		rest := strings.TrimLeft(txt[1:], " \t")
+		if rest != "" {
+			yp.content(cn, rest, ln, col+len([]rune(txt))-len([]rune(rest)))
+		}
+		return
+	}
+	if key, val, ok := yamlKey(txt); ok {
+		if par.Kind == "" {
+			par.Kind = "object"
+		}
+		cn := par.AddDocNode(key, -1, lex.Pos{Ln: ln, Ch: col})
+		cn.ind = col
+		yp.stack = append(yp.stack, cn)
+		yp.scalar(cn, val)
+		return
+	}
+	yp.scalar(par, txt)
+}
+
+// scalar sets the value of given node, unless empty (the values in it
+// follow), starting a block scalar for | or >
+func (yp *yamlParser) scalar(dn *DocNode, val string) {
+	if val == "" {
+		return
+	}
+	if val[0] == '|' || val[0] == '>' {
+		yp.block = dn
+	}
+	if dn.Value == "" {
+		dn.Value = val
+	} else {
+		dn.Value += " " + val
+	}
+}
+
+// ParseYAMLDoc parses the structure of the YAML document of given lines
+// into given root node, by the indentation of the lines: flow collections
+// ({...}, [...]) and block scalars (|, >) are shown as scalars
+func ParseYAMLDoc(lines [][]rune, root *DocNode) error {
+	yp := &yamlParser{stack: []*DocNode{root}}
+	started := false
+	for ln, lr := range lines {
+		txt, col := yamlContent(lr)
+		if txt == "" {
+			continue
+		}
+		if yp.block != nil {
+			if col > yp.block.ind {
+				for _, dn := range yp.stack {
+					dn.Reg.End = lex.Pos{Ln: ln, Ch: len(lr)}
+				}
+				continue
+			}
+			yp.block = nil
+		}
+		if col == 0 && (txt == "---" || txt == "..." || strings.HasPrefix(txt, "%") || strings.HasPrefix(txt, "--- ")) {
+			continue
+		}
+		if !started {
+			root.Reg.Start = lex.Pos{Ln: ln, Ch: col}
+			started = true
+		}
+		item := txt == "-" || strings.HasPrefix(txt, "- ") || strings.HasPrefix(txt, "-\t")
+		for len(yp.stack) > 1 {
+			tn := yp.top()
+			if tn.ind < col {
+				break
+			}
+			// a sequence can be at the indentation of its key
+			if tn.ind == col && item && tn.Key != "" && tn.Value == "" && tn.Kind != "object" {
+				break
+			}
+			yp.stack = yp.stack[:len(yp.stack)-1]
+		}
+		par := yp.top()
+		_, _, iskey := yamlKey(txt)
+		if par.Value != "" && !item && !iskey {
+			yp.scalar(par, txt) // continued plain scalar
+		} else {
+			yp.content(par, txt, ln, col)
+		}
+		for _, dn := range yp.stack {
+			dn.Reg.End = lex.Pos{Ln: ln, Ch: len(lr)}
+		}
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Buffer
+
+// DocStruct is the structure of the JSON / YAML document of a buffer, as
+// parsed
+type DocStruct struct {
+	Root *DocNode `desc:"root of the structure"`
+	Err  error    `desc:"error parsing the document -- the structure is parsed up to it"`
+}
+
+// BufDocStruct returns the structure of the JSON / YAML document of given
+// buffer, parsing it if not yet parsed since it was last edited -- nil if
+// not a JSON / YAML file
+func BufDocStruct(tb *giv.TextBuf) *DocStruct {
+	if ds, ok := tb.Prop("doc-struct").(*DocStruct); ok {
+		return ds
+	}
+	lang := DocStructLang(tb)
+	if lang == "" {
+		return nil
+	}
+	ds := &DocStruct{Root: NewDocRoot(filepath.Base(string(tb.Filename)))}
+	tb.LinesMu.RLock()
+	if lang == "json" {
+		ds.Err = ParseJSONDoc(tb.Lines, ds.Root)
+	} else {
+		ds.Err = ParseYAMLDoc(tb.Lines, ds.Root)
+	}
+	tb.LinesMu.RUnlock()
+	ds.Root.SetLabels()
+	tb.SetProp("doc-struct", ds)
+	return ds
+}
+
+// ClearDocStruct clears the structure of the document of given buffer, to
+// be parsed again, e.g., when it is edited
+func ClearDocStruct(tb *giv.TextBuf) {
+	tb.DeleteProp("doc-struct")
+}
+
+// DocPathAt returns the path of the value at given position in the JSON /
+// YAML document of given buffer, e.g., spec.containers[0].image -- "" if
+// not such a file, or it has more than DocStructMaxLines lines
+func DocPathAt(tb *giv.TextBuf, pos lex.Pos) string {
+	if DocStructLang(tb) == "" || tb.NumLines() > DocStructMaxLines {
+		return ""
+	}
+	ds := BufDocStruct(tb)
+	if ds == nil {
+		return ""
+	}
+	return ds.Root.NodeAt(pos).Path()
+}
+
+// FormatJSON formats the JSON document of given buffer, indenting it as set
+// in its editor prefs (tabs, or spaces), or minifies it as a single line,
+// replacing the whole text so that it can be undone
+func FormatJSON(tb *giv.TextBuf, minify bool) error {
+	src := bytes.TrimSpace(tb.LinesToBytesCopy())
+	var b bytes.Buffer
+	var err error
+	if minify {
+		err = json.Compact(&b, src)
+	} else {
+		indent := "\t"
+		if tb.Opts.SpaceIndent {
+			indent = strings.Repeat(" ", tb.Opts.TabSize)
+		}
+		err = json.Indent(&b, src, "", indent)
+	}
+	if err != nil {
+		if se, ok := err.(*json.SyntaxError); ok {
+			return fmt.Errorf("line %d: %v", bytes.Count(src[:se.Offset], []byte("\n"))+1, err)
+		}
+		return err
+	}
+	if bytes.Equal(b.Bytes(), src) {
+		return nil
+	}
+	b.WriteString("\n")
+	nln := tb.NumLines()
+	ed := lex.Pos{Ln: nln - 1, Ch: tb.LineLen(nln - 1)}
+	tb.ReplaceText(lex.PosZero, ed, lex.PosZero, b.String(), giv.EditSignal, false)
+	return nil
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// DocStructView shows the structure of a JSON / YAML document as a tree, in
+// which clicking on a value selects it in the editor
+type DocStructView struct {
+	gi.Layout
+	Gide Gide         `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Buf  *giv.TextBuf `json:"-" xml:"-" copy:"-" desc:"buffer of the document"`
+	Doc  *DocStruct   `json:"-" xml:"-" copy:"-" desc:"structure of the document shown"`
+}
+
+var KiT_DocStructView = kit.Types.AddType(&DocStructView{}, DocStructViewProps)
+
+// Config configures the view to show the structure of the document of
+// given buffer
+func (dv *DocStructView) Config(ge Gide, tb *giv.TextBuf) {
+	dv.Gide = ge
+	if dv.Buf != tb {
+		if dv.Buf != nil {
+			dv.Buf.TextBufSig.Disconnect(dv.This())
+		}
+		dv.Buf = tb
+		tb.TextBufSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DocStructView).(*DocStructView)
+			if sig == int64(giv.TextBufNew) || sig == int64(giv.TextBufDone) {
+				dvv.Refresh()
+			}
+		})
+	}
+	dv.Lay = gi.LayoutVert
+	dv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "docbar")
+	config.Add(gi.KiT_Label, "summary")
+	config.Add(gi.KiT_Frame, "doc-frame")
+	mods, updt := dv.ConfigChildren(config)
+	if !mods {
+		updt = dv.UpdateStart()
+	}
+	dv.ConfigToolbar()
+	dv.Refresh()
+	dv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (dv *DocStructView) ToolBar() *gi.ToolBar {
+	return dv.ChildByName("docbar", 0).(*gi.ToolBar)
+}
+
+// Summary returns the summary label
+func (dv *DocStructView) Summary() *gi.Label {
+	return dv.ChildByName("summary", 1).(*gi.Label)
+}
+
+// Frame returns the frame of the tree
+func (dv *DocStructView) Frame() *gi.Frame {
+	return dv.ChildByName("doc-frame", 2).(*gi.Frame)
+}
+
+// Refresh parses the document again if it was edited, and shows its
+// structure
+func (dv *DocStructView) Refresh() {
+	ds := BufDocStruct(dv.Buf)
+	if ds == nil {
+		ds = &DocStruct{Root: NewDocRoot(filepath.Base(string(dv.Buf.Filename))), Err: fmt.Errorf("not a JSON or YAML file")}
+	}
+	dv.Doc = ds
+	sfr := dv.Frame()
+	updt := sfr.UpdateStart()
+	sfr.SetFullReRender()
+	if !sfr.HasChildren() {
+		sfr.SetProp("height", units.NewEm(5)) // enables scrolling
+		sfr.SetStretchMaxWidth()
+		sfr.SetStretchMaxHeight()
+		tv := sfr.AddNewChild(KiT_DocTreeView, "treeview").(*DocTreeView)
+		tv.TreeViewSig.Connect(dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if data == nil || sig != int64(giv.TreeViewSelected) {
+				return
+			}
+			dvv, _ := recv.Embed(KiT_DocStructView).(*DocStructView)
+			tvn, _ := data.(ki.Ki).Embed(KiT_DocTreeView).(*DocTreeView)
+			if dn := tvn.DocNode(); dn != nil {
+				dvv.SelectNode(dn)
+			}
+		})
+	}
+	tv := sfr.Child(0).(*DocTreeView)
+	tv.SetRootNode(ds.Root)
+	if ds.Root.NumNodes() <= DocStructOpenNodes {
+		tv.OpenAll()
+	}
+	sfr.UpdateEnd(updt)
+	dv.UpdateSummary()
+}
+
+// UpdateSummary shows the file and the number of values in it, or the
+// error parsing it
+func (dv *DocStructView) UpdateSummary() {
+	ds := dv.Doc
+	msg := fmt.Sprintf("<b>%s</b>: %d values", html.EscapeString(filepath.Base(string(dv.Buf.Filename))), ds.Root.NumNodes()-1)
+	if ds.Err != nil {
+		msg += " -- parsed up to the error: " + html.EscapeString(ds.Err.Error())
+	}
+	dv.Summary().SetText(msg)
+}
+
+// SelectNode selects the text of the value of given node in the editor
+func (dv *DocStructView) SelectNode(dn *DocNode) {
+	if dn.DocParent() == nil {
+		return
+	}
+	dv.Gide.SaveNavPos()
+	dv.Gide.OpenFileAtRegion(dv.Buf.Filename, dn.Reg)
+}
+
+// Format formats the JSON document, or minifies it as a single line
+func (dv *DocStructView) Format(minify bool) {
+	if DocStructLang(dv.Buf) != "json" {
+		dv.Gide.SetStatus("only JSON documents can be formatted")
+		return
+	}
+	if err := FormatJSON(dv.Buf, minify); err != nil {
+		dv.Gide.SetStatus("could not format: " + html.EscapeString(err.Error()))
+		return
+	}
+	dv.Refresh()
+}
+
+// ConfigToolbar adds the toolbar actions
+func (dv *DocStructView) ConfigToolbar() {
+	tb := dv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "parse the document again, e.g., after it was edited"},
+		dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DocStructView).(*DocStructView)
+			dvv.Refresh()
+		})
+	tb.AddSeparator("sep-fmt")
+	tb.AddAction(gi.ActOpts{Label: "Format", Icon: "edit", Tooltip: "indent the JSON document, with tabs or spaces as set in the editor prefs -- can be undone"},
+		dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DocStructView).(*DocStructView)
+			dvv.Format(false)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Minify", Icon: "minus", Tooltip: "remove all the white space of the JSON document, as a single line -- can be undone"},
+		dv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dvv, _ := recv.Embed(KiT_DocStructView).(*DocStructView)
+			dvv.Format(true)
+		})
+}
+
+// DocStructViewProps are style properties for DocStructView
+var DocStructViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+// DocTreeView is a TreeView of DocNode nodes
+type DocTreeView struct {
+	giv.TreeView
+}
+
+var KiT_DocTreeView = kit.Types.AddType(&DocTreeView{}, nil)
+
+func init() {
+	kit.Types.SetProps(KiT_DocTreeView, SymTreeViewProps)
+}
+
+// DocNode returns the SrcNode as a DocNode
+func (dt *DocTreeView) DocNode() *DocNode {
+	dn, _ := dt.SrcNode.Embed(KiT_DocNode).(*DocNode)
+	return dn
+}
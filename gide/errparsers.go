@@ -0,0 +1,331 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goki/pi/filecat"
+)
+
+// ErrParser parses the errors and warnings in the output of a toolchain
+// into problems, for the commands of its languages
+type ErrParser struct {
+	Name  string                                                              `desc:"name of the toolchain"`
+	Langs []filecat.Supported                                                 `desc:"languages of the commands whose output is parsed"`
+	Parse func(out []byte, dir, source string, sev ProblemSeverity) []Problem `desc:"the parser -- nil for the path:line:col: msg format of ProblemRe"`
+}
+
+// ErrParsers are the output parsers of the non-Go toolchains, selected by
+// the Lang of the command -- the output of commands for Any language is
+// parsed by all of them
+var ErrParsers = []*ErrParser{
+	{Name: "gcc / clang", Langs: []filecat.Supported{filecat.C, filecat.ObjC}},
+	{Name: "javac", Langs: []filecat.Supported{filecat.Java}},
+	{Name: "rustc", Langs: []filecat.Supported{filecat.Rust}, Parse: ParseRustcProblems},
+	{Name: "tsc", Langs: []filecat.Supported{filecat.JavaScript}, Parse: ParseTscProblems},
+	{Name: "pytest", Langs: []filecat.Supported{filecat.Python}, Parse: ParsePytestProblems},
+	{Name: "python", Langs: []filecat.Supported{filecat.Python}, Parse: ParsePythonProblems},
+}
+
+// ErrParsersForLang returns the parsers for the output of commands of
+// given language -- all of them for Any
+func ErrParsersForLang(lang filecat.Supported) []*ErrParser {
+	if lang == filecat.Any {
+		return ErrParsers
+	}
+	var eps []*ErrParser
+	for _, ep := range ErrParsers {
+		for _, el := range ep.Langs {
+			if el == lang {
+				eps = append(eps, ep)
+				break
+			}
+		}
+	}
+	return eps
+}
+
+// HasErrParsers returns true if there are parsers for the output of
+// commands of given (specific) language, whose output is then parsed for
+// problems regardless of their name
+func HasErrParsers(lang filecat.Supported) bool {
+	return lang != filecat.Any && len(ErrParsersForLang(lang)) > 0
+}
+
+// ParseCmdProblems returns the problems in the output of a command of
+// given language, with the parsers of that language, and ProblemRe for
+// Any or Go -- without duplicates at the same line, the specific parsers
+// coming first
+func ParseCmdProblems(lang filecat.Supported, out []byte, dir, source string, sev ProblemSeverity) []Problem {
+	var probs []Problem
+	have := map[string]bool{}
+	add := func(pbs []Problem) {
+		for _, pb := range pbs {
+			key := fmt.Sprintf("%v:%d", pb.Path, pb.Line)
+			if !have[key] {
+				have[key] = true
+				probs = append(probs, pb)
+			}
+		}
+	}
+	eps := ErrParsersForLang(lang)
+	dflt := len(eps) == 0 || lang == filecat.Any
+	for _, ep := range eps {
+		if ep.Parse == nil {
+			dflt = true
+			continue
+		}
+		add(ep.Parse(out, dir, source, sev))
+	}
+	if dflt {
+		add(ParseProblems(out, dir, source, sev))
+	}
+	return probs
+}
+
+// NewProblem returns a problem at given path, relative to given directory,
+// and line and col -- false if there is no such file or the line is not
+// valid
+func NewProblem(path string, line, col int, dir, source, msg string, sev ProblemSeverity) (Problem, bool) {
+	pb := Problem{Path: path, Line: line, Col: col, Severity: sev, Source: source, Msg: strings.TrimSpace(msg)}
+	if pb.Line <= 0 {
+		return pb, false
+	}
+	if !filepath.IsAbs(pb.Path) {
+		pb.Path = filepath.Join(dir, pb.Path)
+	}
+	pb.Path = filepath.Clean(pb.Path)
+	if info, err := os.Stat(pb.Path); err != nil || info.IsDir() {
+		return pb, false
+	}
+	return pb, true
+}
+
+// errSevs are the severities of the diagnostic kinds of rustc and tsc
+var errSevs = map[string]ProblemSeverity{"error": ProblemError, "warning": ProblemWarning, "note": ProblemInfo, "help": ProblemInfo}
+
+var (
+	// rustcHeadRe matches the head of a rustc diagnostic: error[E0425]: msg
+	rustcHeadRe = regexp.MustCompile(`^(error|warning)(?:\[(\w+)\])?: (.+)$`)
+
+	// rustcLocRe matches the location of a rustc diagnostic: --> path:line:col
+	rustcLocRe = regexp.MustCompile(`^\s*--> ((?:[A-Za-z]:)?[^\s:]+):(\d+):(\d+)`)
+)
+
+// RustErrorURL is the format of the link to the docs of a rustc error code
+var RustErrorURL = "https://doc.rust-lang.org/error_codes/%s.html"
+
+// ParseRustcProblems returns the problems in rustc (and cargo) output: a
+// head with the kind, code and message, followed by the location
+func ParseRustcProblems(out []byte, dir, source string, sev ProblemSeverity) []Problem {
+	var probs []Problem
+	var head []string
+	for _, lb := range bytes.Split(out, []byte("\n")) {
+		ln := strings.TrimRight(string(lb), "\r")
+		if hm := rustcHeadRe.FindStringSubmatch(ln); hm != nil {
+			head = hm
+			continue
+		}
+		lm := rustcLocRe.FindStringSubmatch(ln)
+		if lm == nil || head == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(lm[2])
+		col, _ := strconv.Atoi(lm[3])
+		msg := head[3]
+		if head[2] != "" {
+			msg = head[2] + ": " + msg
+		}
+		if pb, ok := NewProblem(lm[1], line, col, dir, source, msg, errSevs[head[1]]); ok {
+			if head[2] != "" {
+				pb.URL = fmt.Sprintf(RustErrorURL, head[2])
+			}
+			probs = append(probs, pb)
+		}
+		head = nil // only the primary location
+	}
+	return probs
+}
+
+// tscRe matches a tsc diagnostic: path(line,col): error TS2322: msg, or
+// path:line:col - error TS2322: msg with --pretty
+var tscRe = regexp.MustCompile(`^((?:[A-Za-z]:)?[^\s:()]+\.[cm]?[jt]sx?)(?:\((\d+),(\d+)\):|:(\d+):(\d+) -)\s*(error|warning)\s+(TS\d+):\s*(.+)$`)
+
+// ParseTscProblems returns the problems in the output of the TypeScript
+// compiler
+func ParseTscProblems(out []byte, dir, source string, sev ProblemSeverity) []Problem {
+	var probs []Problem
+	for _, lb := range bytes.Split(out, []byte("\n")) {
+		m := tscRe.FindStringSubmatch(strings.TrimRight(string(lb), "\r"))
+		if m == nil {
+			continue
+		}
+		ls, cs := m[2], m[3]
+		if ls == "" {
+			ls, cs = m[4], m[5]
+		}
+		line, _ := strconv.Atoi(ls)
+		col, _ := strconv.Atoi(cs)
+		if pb, ok := NewProblem(m[1], line, col, dir, source, m[7]+": "+m[8], errSevs[m[6]]); ok {
+			probs = append(probs, pb)
+		}
+	}
+	return probs
+}
+
+// pyFrameRe matches a frame of a python traceback: File "path", line 12
+var pyFrameRe = regexp.MustCompile(`^\s*File "([^"]+)", line (\d+)`)
+
+// ParsePythonProblems returns the problems in python tracebacks: the
+// exception, at the innermost frame in a file under the directory (or
+// else the innermost one)
+func ParsePythonProblems(out []byte, dir, source string, sev ProblemSeverity) []Problem {
+	var probs []Problem
+	var frame, inDir *Problem
+	for _, lb := range bytes.Split(out, []byte("\n")) {
+		ln := strings.TrimRight(string(lb), "\r")
+		if fm := pyFrameRe.FindStringSubmatch(ln); fm != nil {
+			line, _ := strconv.Atoi(fm[2])
+			if pb, ok := NewProblem(fm[1], line, 0, dir, source, "", ProblemError); ok {
+				frame = &pb
+				if dir != "" && strings.HasPrefix(pb.Path, dir+string(filepath.Separator)) && !strings.Contains(pb.Path, "site-packages") {
+					inDir = &pb
+				}
+			}
+			continue
+		}
+		if frame == nil || ln == "" || ln[0] == ' ' || ln[0] == '\t' || strings.HasPrefix(ln, "Traceback ") {
+			continue
+		}
+		pb := frame
+		if inDir != nil {
+			pb = inDir
+		}
+		pb.Msg = strings.TrimSpace(ln)
+		probs = append(probs, *pb)
+		frame, inDir = nil, nil
+	}
+	return probs
+}
+
+var (
+	// pytestLocRe matches the location of a pytest failure: path.py:12: AssertionError
+	pytestLocRe = regexp.MustCompile(`^((?:[A-Za-z]:)?[^\s:]+\.py):(\d+): (\w+)$`)
+
+	// pytestErrRe matches an error line of a pytest failure: E   assert 1 == 2
+	pytestErrRe = regexp.MustCompile(`^E\s+(.+)$`)
+)
+
+// ParsePytestProblems returns the problems in pytest output: the
+// failures, at their location, with the first E line of their report
+func ParsePytestProblems(out []byte, dir, source string, sev ProblemSeverity) []Problem {
+	var probs []Problem
+	emsg := ""
+	for _, lb := range bytes.Split(out, []byte("\n")) {
+		ln := strings.TrimRight(string(lb), "\r")
+		if em := pytestErrRe.FindStringSubmatch(ln); em != nil {
+			if emsg == "" {
+				emsg = strings.TrimSpace(em[1])
+			}
+			continue
+		}
+		lm := pytestLocRe.FindStringSubmatch(ln)
+		if lm == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(lm[2])
+		msg := lm[3]
+		if emsg != "" {
+			msg += ": " + emsg
+		}
+		if pb, ok := NewProblem(lm[1], line, 0, dir, source, msg, ProblemError); ok {
+			probs = append(probs, pb)
+		}
+		emsg = ""
+	}
+	return probs
+}
+
+// ErrLinkRes match the file positions in the output of the non-Go
+// toolchains that are not in the first fields of the line or are not
+// ./ paths, for linking them in the command output: each has the path,
+// line and optional col as groups
+var ErrLinkRes = []*regexp.Regexp{
+	pyFrameRe,
+	regexp.MustCompile(`^((?:[A-Za-z]:)?[^\s:()]+\.[cm]?[jt]sx?)\((\d+),(\d+)\)`),
+	regexp.MustCompile(`^\s*--> ((?:[A-Za-z]:)?[^\s:]+):(\d+):(\d+)`),
+	regexp.MustCompile(`^((?:[A-Za-z]:)?[\w.\-/\\]+\.\w+):(\d+)(?::(\d+))?[: ]`),
+}
+
+// MarkupErrLinks applies a link to the first file position in given
+// command output line matched by ErrLinkRes -- false if none.  The line is
+// HTML-escaped (see OutBuf), so it is matched unescaped (see
+// UnescapeOffsets), with the link applied to the escaped text.
+func MarkupErrLinks(out []byte) ([]byte, bool) {
+	raw, offs := UnescapeOffsets(out)
+	for _, re := range ErrLinkRes {
+		m := re.FindSubmatchIndex(raw)
+		if m == nil {
+			continue
+		}
+		pst, ped := m[2], m[3]
+		pos := "L" + string(raw[m[4]:m[5]])
+		if len(m) > 6 && m[6] >= 0 {
+			pos += "C" + string(raw[m[6]:m[7]])
+		}
+		ed := m[1]
+		switch {
+		case re == pyFrameRe: // just the path, in quotes
+			ed = ped
+		case raw[ed-1] == ':' || raw[ed-1] == ' ':
+			ed--
+		}
+		pst, ped, ed = offs[pst], offs[ped], offs[ed]
+		lnk := fmt.Sprintf(`<a href="file:///%s#%s">%s</a>`, out[pst:ped], pos, out[pst:ed])
+		var b bytes.Buffer
+		b.Write(out[:pst])
+		b.WriteString(lnk)
+		b.Write(out[ed:])
+		return b.Bytes(), true
+	}
+	return out, false
+}
+
+// htmlEscapes are the escapes of the characters escaped by
+// html.EscapeString, in the order of htmlUnescaped
+var htmlEscapes = [][]byte{[]byte("&lt;"), []byte("&gt;"), []byte("&amp;"), []byte("&#39;"), []byte("&#34;")}
+
+// htmlUnescaped are the characters of htmlEscapes
+var htmlUnescaped = "<>&'\""
+
+// UnescapeOffsets returns given HTML-escaped text unescaped, with the
+// offset in the text of each of its bytes, plus the length of the text
+// at the end, for mapping positions in it back to the text
+func UnescapeOffsets(out []byte) ([]byte, []int) {
+	raw := make([]byte, 0, len(out))
+	offs := make([]int, 0, len(out)+1)
+	for i := 0; i < len(out); {
+		c, n := out[i], 1
+		if c == '&' {
+			for ei, esc := range htmlEscapes {
+				if bytes.HasPrefix(out[i:], esc) {
+					c, n = htmlUnescaped[ei], len(esc)
+					break
+				}
+			}
+		}
+		raw = append(raw, c)
+		offs = append(offs, i)
+		i += n
+	}
+	return raw, append(offs, len(out))
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+func TestMarkupErrLinks(t *testing.T) {
+	tests := []struct {
+		out  string
+		link bool
+		mu   string
+	}{
+		{`  File "/p/mod.py", line 5, in main`, true, `  File &#34;<a href="file:////p/mod.py#L5">/p/mod.py</a>&#34;, line 5, in main`},
+		{` --> src/main.rs:3:5`, true, ` --&gt; <a href="file:///src/main.rs#L3C5">src/main.rs:3:5</a>`},
+		{`   --> src/lib.rs:12:1`, true, `   --&gt; <a href="file:///src/lib.rs#L12C1">src/lib.rs:12:1</a>`},
+		{`app.ts(4,7): error TS2322: Type 'string' is not assignable`, true, `<a href="file:///app.ts#L4C7">app.ts(4,7)</a>: error TS2322: Type &#39;string&#39; is not assignable`},
+		{`a.c:3:5: error: expected ';' before '}' token`, true, `<a href="file:///a.c#L3C5">a.c:3:5</a>: error: expected &#39;;&#39; before &#39;}&#39; token`},
+		{`Main.java:7: error: <identifier> expected`, true, `<a href="file:///Main.java#L7">Main.java:7</a>: error: &lt;identifier&gt; expected`},
+		{`hello <world> & "you"`, false, `hello &lt;world&gt; &amp; &#34;you&#34;`},
+	}
+	for _, tst := range tests {
+		esc := giv.HTMLEscapeBytes([]byte(tst.out))
+		mu, link := MarkupErrLinks(esc)
+		if link != tst.link || string(mu) != tst.mu {
+			t.Errorf("MarkupErrLinks error: %q: should have been: %v %q  was: %v %q\n", tst.out, tst.link, tst.mu, link, mu)
+		}
+	}
+	// unescaped output, as appended by AppendCmdOut
+	mu, link := MarkupErrLinks([]byte(` --> src/main.rs:3:5`))
+	if want := ` --> <a href="file:///src/main.rs#L3C5">src/main.rs:3:5</a>`; !link || string(mu) != want {
+		t.Errorf("MarkupErrLinks error: unescaped: should have been: %q  was: %q\n", want, mu)
+	}
+}
+
+func TestUnescapeOffsets(t *testing.T) {
+	raw, offs := UnescapeOffsets([]byte(`a&lt;b&#34;&amp;`))
+	if string(raw) != `a<b"&` || !reflect.DeepEqual(offs, []int{0, 1, 5, 6, 11, 16}) {
+		t.Errorf("UnescapeOffsets error: was: %q %v\n", raw, offs)
+	}
+}
+
+// errParsersDir returns a temporary directory with given (empty) files
+func errParsersDir(t *testing.T, files ...string) string {
+	dir, err := ioutil.TempDir("", "gide-errparsers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range files {
+		fpath := filepath.Join(dir, filepath.FromSlash(fn))
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		if err := ioutil.WriteFile(fpath, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestErrParsers(t *testing.T) {
+	dir := errParsersDir(t, "src/main.rs", "app.ts", "mod.py", "test_x.py")
+	defer os.RemoveAll(dir)
+	lib := errParsersDir(t, "lib.py")
+	defer os.RemoveAll(lib)
+	pth := func(fn string) string { return filepath.Join(dir, filepath.FromSlash(fn)) }
+	tests := []struct {
+		name  string
+		parse func(out []byte, dir, source string, sev ProblemSeverity) []Problem
+		out   string
+		probs []Problem
+	}{
+		{"rustc", ParseRustcProblems, "error[E0425]: cannot find value `x` in this scope\n --> src/main.rs:3:5\n  |\n3 |     x\n  |     ^ not found\n   --> src/main.rs:9:1\n\nwarning: unused variable: `y`\r\n  --> src/main.rs:2:9\r\n\nerror: no such file\n --> src/none.rs:1:1\n", []Problem{
+			{Path: pth("src/main.rs"), Line: 3, Col: 5, Severity: ProblemError, Source: "Run", Msg: "E0425: cannot find value `x` in this scope", URL: fmt.Sprintf(RustErrorURL, "E0425")},
+			{Path: pth("src/main.rs"), Line: 2, Col: 9, Severity: ProblemWarning, Source: "Run", Msg: "unused variable: `y`"},
+		}},
+		{"tsc", ParseTscProblems, "app.ts(4,7): error TS2322: Type 'string' is not assignable to type 'number'.\napp.ts:10:3 - warning TS6133: 'x' is declared but its value is never read.\nnone.ts(1,1): error TS1005: ';' expected.\nFound 2 errors.\n", []Problem{
+			{Path: pth("app.ts"), Line: 4, Col: 7, Severity: ProblemError, Source: "Run", Msg: "TS2322: Type 'string' is not assignable to type 'number'."},
+			{Path: pth("app.ts"), Line: 10, Col: 3, Severity: ProblemWarning, Source: "Run", Msg: "TS6133: 'x' is declared but its value is never read."},
+		}},
+		{"python", ParsePythonProblems, "Traceback (most recent call last):\n  File \"" + pth("mod.py") + "\", line 5, in <module>\n    main()\n  File \"" + filepath.Join(lib, "lib.py") + "\", line 2, in main\n    raise ValueError(\"bad\")\nValueError: bad\n\nTraceback (most recent call last):\n  File \"mod.py\", line 7, in <module>\nKeyError: 'k'\n", []Problem{
+			{Path: pth("mod.py"), Line: 5, Severity: ProblemError, Source: "Run", Msg: "ValueError: bad"},
+			{Path: pth("mod.py"), Line: 7, Severity: ProblemError, Source: "Run", Msg: "KeyError: 'k'"},
+		}},
+		{"python outside", ParsePythonProblems, "Traceback (most recent call last):\n  File \"" + filepath.Join(lib, "lib.py") + "\", line 2, in main\nValueError: bad\n", []Problem{
+			{Path: filepath.Join(lib, "lib.py"), Line: 2, Severity: ProblemError, Source: "Run", Msg: "ValueError: bad"},
+		}},
+		{"pytest", ParsePytestProblems, "    def test_a():\n>       assert 1 == 2\nE       assert 1 == 2\nE        +  where 2 = f()\n\ntest_x.py:3: AssertionError\n_____ test_b _____\ntest_x.py:9: KeyError\nnone.py:1: AssertionError\n", []Problem{
+			{Path: pth("test_x.py"), Line: 3, Severity: ProblemError, Source: "Run", Msg: "AssertionError: assert 1 == 2"},
+			{Path: pth("test_x.py"), Line: 9, Severity: ProblemError, Source: "Run", Msg: "KeyError"},
+		}},
+	}
+	for _, tst := range tests {
+		probs := tst.parse([]byte(tst.out), dir, "Run", ProblemError)
+		if !reflect.DeepEqual(probs, tst.probs) {
+			t.Errorf("%v parser error: should have been: %+v\n  was: %+v\n", tst.name, tst.probs, probs)
+		}
+	}
+}
+
+func TestErrParsersForLang(t *testing.T) {
+	tests := []struct {
+		lang filecat.Supported
+		eps  string
+	}{
+		{filecat.Rust, "rustc"},
+		{filecat.Python, "pytest python"},
+		{filecat.C, "gcc / clang"},
+		{filecat.Go, ""},
+	}
+	for _, tst := range tests {
+		var nms []string
+		for _, ep := range ErrParsersForLang(tst.lang) {
+			nms = append(nms, ep.Name)
+		}
+		if eps := strings.Join(nms, " "); eps != tst.eps {
+			t.Errorf("ErrParsersForLang error: %v: should have been: %q  was: %q\n", tst.lang, tst.eps, eps)
+		}
+	}
+	if len(ErrParsersForLang(filecat.Any)) != len(ErrParsers) || HasErrParsers(filecat.Any) || HasErrParsers(filecat.Go) || !HasErrParsers(filecat.Rust) {
+		t.Errorf("HasErrParsers error: should be for specific languages with parsers only\n")
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/ki/ints"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+)
+
+// PDFConverters are the commands tried, in order, to convert an exported
+// HTML file to PDF -- the first one found in the PATH is used, with {html}
+// and {pdf} replaced by the files
+var PDFConverters = [][]string{
+	{"wkhtmltopdf", "--quiet", "{html}", "{pdf}"},
+	{"chromium", "--headless", "--disable-gpu", "--print-to-pdf={pdf}", "{html}"},
+	{"chromium-browser", "--headless", "--disable-gpu", "--print-to-pdf={pdf}", "{html}"},
+	{"google-chrome", "--headless", "--disable-gpu", "--print-to-pdf={pdf}", "{html}"},
+}
+
+// ExportCSS returns the CSS style sheet of given highlighting style, with a
+// class for each token, as used in the markup of the TextBuf
+func ExportCSS(hs *histyle.Style) string {
+	var sb strings.Builder
+	pre := []string{"font-family: monospace", "padding: 1em", "line-height: 1.3", "white-space: pre-wrap"}
+	if tx := ExportEntryCSS(hs.Tag(token.Text)); tx != "" {
+		pre = append(pre, tx)
+	}
+	if bg := hs.TagRaw(token.Background); !bg.Background.IsNil() {
+		pre = append(pre, "background-color: "+bg.Background.HexString())
+	}
+	fmt.Fprintf(&sb, "body { margin: 2em; }\npre { %s; }\n.lnum { opacity: 0.5; user-select: none; }\n", strings.Join(pre, "; "))
+	var toks []token.Tokens
+	for tk := range token.Names {
+		toks = append(toks, tk)
+	}
+	sort.Slice(toks, func(i, j int) bool { return toks[i] < toks[j] })
+	for _, tk := range toks {
+		if tk == token.Background || tk.StyleName() == "" {
+			continue
+		}
+		if css := ExportEntryCSS(hs.Tag(tk)); css != "" {
+			fmt.Fprintf(&sb, "%s { %s; }\n", tk.ClassName(), css)
+		}
+	}
+	return sb.String()
+}
+
+// ExportEntryCSS returns the CSS properties of given style entry, with the
+// colors in hexadecimal as browsers expect, "" if none are set
+func ExportEntryCSS(se histyle.StyleEntry) string {
+	var css []string
+	if !se.Color.IsNil() {
+		css = append(css, "color: "+se.Color.HexString())
+	}
+	if !se.Background.IsNil() {
+		css = append(css, "background-color: "+se.Background.HexString())
+	}
+	if se.Bold == histyle.Yes {
+		css = append(css, "font-weight: bold")
+	}
+	if se.Italic == histyle.Yes {
+		css = append(css, "font-style: italic")
+	}
+	if se.Underline == histyle.Yes {
+		css = append(css, "text-decoration: underline")
+	}
+	return strings.Join(css, "; ")
+}
+
+// ExportHTML returns a standalone HTML page with given region of the text of
+// given buffer (all of it if nil), with its syntax highlighting, and line
+// numbers if lineNos is set
+func ExportHTML(tb *giv.TextBuf, reg textbuf.Region, title string, lineNos bool) []byte {
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	tb.MarkupMu.RLock()
+	defer tb.MarkupMu.RUnlock()
+	if tb.NLines == 0 {
+		reg = textbuf.Region{}
+	} else if reg.IsNil() {
+		reg = textbuf.NewRegion(0, 0, tb.NLines-1, len(tb.Lines[tb.NLines-1]))
+	}
+	hs := tb.Hi.HiStyle
+	if hs == nil {
+		hs = histyle.AvailStyle(histyle.StyleDefault)
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n<pre>", html.EscapeString(title), ExportCSS(hs))
+	ednd := ints.MinInt(reg.End.Ln, tb.NLines-1)
+	lnw := len(fmt.Sprintf("%d", ednd+1))
+	for ln := reg.Start.Ln; ln <= ednd; ln++ {
+		txt := tb.Lines[ln]
+		st, ed := 0, len(txt)
+		if ln == reg.Start.Ln {
+			st = ints.MinInt(reg.Start.Ch, ed)
+		}
+		if ln == reg.End.Ln {
+			ed = ints.MaxInt(ints.MinInt(reg.End.Ch, ed), st)
+		}
+		if lineNos {
+			fmt.Fprintf(&b, "<span class=\"lnum\">%*d  </span>", lnw, ln+1)
+		}
+		var tags lex.Line
+		if ln < len(tb.HiTags) {
+			tags = ExportTags(tb.HiTags[ln], st, ed)
+		}
+		b.Write(tb.Hi.MarkupLine(txt[st:ed], tags, nil))
+		b.WriteString("\n")
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.Bytes()
+}
+
+// ExportTags returns the tags of a line clipped to the runes from st to ed,
+// relative to st
+func ExportTags(tags lex.Line, st, ed int) lex.Line {
+	var ct lex.Line
+	for _, tg := range tags {
+		if tg.Ed <= st || tg.St >= ed {
+			continue
+		}
+		tg.St = ints.MaxInt(tg.St, st) - st
+		tg.Ed = ints.MinInt(tg.Ed, ed) - st
+		ct = append(ct, tg)
+	}
+	return ct
+}
+
+// ExportPDF converts given HTML page to given PDF file, using the first of
+// the PDFConverters found
+func ExportPDF(page []byte, pdf string) error {
+	var conv []string
+	for _, pc := range PDFConverters {
+		if _, err := exec.LookPath(pc[0]); err == nil {
+			conv = pc
+			break
+		}
+	}
+	if conv == nil {
+		return fmt.Errorf("no HTML to PDF converter found in the PATH: install wkhtmltopdf or chromium")
+	}
+	tf, err := ioutil.TempFile("", "gide-export-*.html")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tf.Name())
+	_, err = tf.Write(page)
+	tf.Close()
+	if err != nil {
+		return err
+	}
+	if abs, err := filepath.Abs(pdf); err == nil {
+		pdf = abs
+	}
+	args := make([]string, len(conv)-1)
+	for i, a := range conv[1:] {
+		a = strings.Replace(a, "{html}", tf.Name(), -1)
+		args[i] = strings.Replace(a, "{pdf}", pdf, -1)
+	}
+	out, err := exec.Command(conv[0], args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %v: %s", conv[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ExportFile exports given region of the text of given buffer (all of it if
+// nil) with its syntax highlighting to given file: as a PDF if it has the
+// .pdf extension, else as a standalone HTML page
+func ExportFile(tb *giv.TextBuf, reg textbuf.Region, fname string, lineNos bool) error {
+	title := filepath.Base(string(tb.Filename))
+	page := ExportHTML(tb, reg, title, lineNos)
+	if strings.ToLower(filepath.Ext(fname)) == ".pdf" {
+		return ExportPDF(page, fname)
+	}
+	return ioutil.WriteFile(fname, page, 0644)
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/vci"
+)
+
+// FileHistView is a widget that shows the history of commits of one file,
+// for showing the diff between any two of its revisions, or between a
+// revision and the working copy, and viewing the file at a revision
+type FileHistView struct {
+	gi.Layout
+	Gide Gide     `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Repo vci.Repo `json:"-" xml:"-" copy:"-" desc:"version control repository of the file"`
+	File string   `desc:"full path of the file"`
+	Log  vci.Log  `desc:"commits of the file, most recent first"`
+	RevA string   `desc:"revision A for Diff A / B"`
+	RevB string   `desc:"revision B for Diff A / B -- empty for the working copy"`
+}
+
+var KiT_FileHistView = kit.Types.AddType(&FileHistView{}, FileHistViewProps)
+
+// Config configures the view to show the history of given file
+func (hv *FileHistView) Config(ge Gide, repo vci.Repo, fpath string, lg vci.Log) {
+	hv.Gide = ge
+	hv.Repo = repo
+	hv.File = fpath
+	hv.Log = lg
+	hv.Lay = gi.LayoutVert
+	hv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "histbar")
+	config.Add(giv.KiT_TableView, "log")
+	mods, updt := hv.ConfigChildren(config)
+	if !mods {
+		updt = hv.UpdateStart()
+	}
+	hv.ConfigToolbar()
+	tv := hv.TableView()
+	if mods {
+		tv.SliceViewSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(giv.SliceViewDoubleClicked) {
+				hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+				hvv.DiffPrev()
+			}
+		})
+	}
+	tv.SetStretchMax()
+	tv.SetInactive()
+	tv.SetSlice(&hv.Log)
+	hv.UpdateEnd(updt)
+}
+
+// ToolBar returns the history toolbar
+func (hv *FileHistView) ToolBar() *gi.ToolBar {
+	return hv.ChildByName("histbar", 0).(*gi.ToolBar)
+}
+
+// TableView returns the table of commits
+func (hv *FileHistView) TableView() *giv.TableView {
+	return hv.ChildByName("log", 1).(*giv.TableView)
+}
+
+// SelRev returns the revision of the selected commit, false if none
+func (hv *FileHistView) SelRev() (string, bool) {
+	idx := hv.TableView().SelectedIdx
+	if idx < 0 || idx >= len(hv.Log) {
+		return "", false
+	}
+	return hv.Log[idx].Rev, true
+}
+
+// ConfigToolbar adds the toolbar actions
+func (hv *FileHistView) ConfigToolbar() {
+	tb := hv.ToolBar()
+	if tb.HasChildren() {
+		tb.ChildByName("file", 0).(*gi.Label).SetText("File: " + giv.DirAndFile(hv.File))
+		return
+	}
+	tb.SetStretchMaxWidth()
+	gi.AddNewLabel(tb, "file", "File: "+giv.DirAndFile(hv.File))
+	tb.AddSeparator("sep-file")
+	tb.AddAction(gi.ActOpts{Label: "Diff Prev", Icon: "file-sheet", Tooltip: "show the changes made to the file by the selected commit (also by double-click)"},
+		hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+			hvv.DiffPrev()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Diff Working", Icon: "file-sheet", Tooltip: "show the changes between the file at the selected commit and the working copy, including unsaved edits"},
+		hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+			if rev, ok := hvv.SelRev(); ok {
+				hvv.Diff(rev, "")
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Open at Rev", Icon: "file-open", Tooltip: "view the file as it was at the selected commit (read-only)"},
+		hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+			if rev, ok := hvv.SelRev(); ok {
+				hvv.OpenAtRev(rev)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Info", Icon: "info", Tooltip: "show the full description of the selected commit"},
+		hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+			if rev, ok := hvv.SelRev(); ok {
+				hvv.CommitInfo(rev)
+			}
+		})
+	tb.AddSeparator("sep-ab")
+	tb.AddAction(gi.ActOpts{Label: "Set A", Tooltip: "use the selected commit as revision A of Diff A / B"},
+		hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+			if rev, ok := hvv.SelRev(); ok {
+				hvv.RevA = rev
+				hvv.Gide.SetStatus("file history: A revision: " + rev)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Set B", Tooltip: "use the selected commit as revision B of Diff A / B"},
+		hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+			if rev, ok := hvv.SelRev(); ok {
+				hvv.RevB = rev
+				hvv.Gide.SetStatus("file history: B revision: " + rev)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Diff A / B", Icon: "file-sheet", Tooltip: "show the changes between revisions A and B (set with Set A and Set B) -- B is the working copy if not set"},
+		hv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			hvv, _ := recv.Embed(KiT_FileHistView).(*FileHistView)
+			if hvv.RevA == "" {
+				gi.PromptDialog(hvv.Viewport, gi.DlgOpts{Title: "No A Revision", Prompt: "Select a commit and use Set A first"}, gi.AddOk, gi.NoCancel, nil, nil)
+				return
+			}
+			hvv.Diff(hvv.RevA, hvv.RevB)
+		})
+}
+
+// Diff shows the diff of the file between given revisions -- revb empty
+// for the working copy (using its open buffer if any)
+func (hv *FileHistView) Diff(reva, revb string) {
+	var fbuf *giv.TextBuf
+	if revb == "" {
+		fbuf = hv.Gide.TextBufForFile(hv.File, false)
+	}
+	if fbuf != nil && BufLineEnds(fbuf) == LineEndsCRLF { // lines without their CR
+		src, err := hv.Repo.FileContents(hv.File, reva)
+		if err != nil {
+			gi.PromptDialog(hv.Viewport, gi.DlgOpts{Title: "Diff Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+			return
+		}
+		src, _ = ToBufLineEnds(src, LineEndsCRLF)
+		if reva == "" {
+			reva = "HEAD"
+		}
+		giv.DiffViewDialog(hv.Viewport, textbuf.BytesToLineStrings(src, false), fbuf.Strings(false), hv.File, hv.File, reva, "", giv.DlgOpts{Title: "DiffVcs: " + giv.DirAndFile(hv.File)})
+		return
+	}
+	if _, err := giv.DiffViewDialogFromRevs(hv.Viewport, hv.Repo, hv.File, fbuf, reva, revb); err != nil {
+		gi.PromptDialog(hv.Viewport, gi.DlgOpts{Title: "Diff Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+}
+
+// DiffPrev shows the changes made to the file by the selected commit: the
+// diff from the commit before it in the log
+func (hv *FileHistView) DiffPrev() {
+	idx := hv.TableView().SelectedIdx
+	if idx < 0 || idx >= len(hv.Log) {
+		return
+	}
+	if idx+1 >= len(hv.Log) {
+		hv.Gide.SetStatus("file history: the first commit of the file has no previous revision")
+		return
+	}
+	hv.Diff(hv.Log[idx+1].Rev, hv.Log[idx].Rev)
+}
+
+// OpenAtRev shows the file as it was at given revision, in a read-only view
+func (hv *FileHistView) OpenAtRev(rev string) {
+	src, err := hv.Repo.FileContents(hv.File, rev)
+	if err != nil {
+		gi.PromptDialog(hv.Viewport, gi.DlgOpts{Title: "Open at Revision Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	title := fmt.Sprintf("%v @ %v", giv.DirAndFile(hv.File), rev)
+	giv.TextViewDialog(hv.Viewport, src, giv.DlgOpts{Title: title, Filename: hv.File, LineNos: true, Ok: true})
+}
+
+// CommitInfo shows the full description of the commit of given revision
+func (hv *FileHistView) CommitInfo(rev string) {
+	cinfo, err := hv.Repo.CommitDesc(rev, false)
+	if err != nil {
+		gi.PromptDialog(hv.Viewport, gi.DlgOpts{Title: "Commit Info Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	giv.TextViewDialog(hv.Viewport, cinfo, giv.DlgOpts{Title: "Commit Info: " + rev, Ok: true})
+}
+
+// FileHistViewProps are style properties for FileHistView
+var FileHistViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
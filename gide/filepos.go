@@ -0,0 +1,99 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goki/pi/lex"
+)
+
+// FilePos is a file to open at a position, e.g., as given on the command
+// line as path:line:col (see ParseFilePos)
+type FilePos struct {
+	Path string `desc:"full path of the file"`
+	Line int    `desc:"line number, starting at 1 -- 0 if none"`
+	Col  int    `desc:"column number, starting at 1 -- 0 if none"`
+}
+
+// ParseFilePos parses a file path with an optional line and column suffix:
+// path, path:line or path:line:col, with numbers starting at 1 as in
+// compiler messages and grep -n output (a trailing : is also allowed).  A
+// numeric suffix is only taken as a position if the given path does not
+// exist as such.  The path is made absolute.
+func ParseFilePos(arg string) FilePos {
+	fp := FilePos{Path: arg}
+	if _, err := os.Stat(arg); err != nil {
+		pth := strings.TrimSuffix(arg, ":")
+		var nums []int
+		for len(nums) < 2 {
+			ci := strings.LastIndex(pth, ":")
+			if ci <= 0 {
+				break
+			}
+			n, err := strconv.Atoi(pth[ci+1:])
+			if err != nil || n < 0 {
+				break
+			}
+			nums = append([]int{n}, nums...)
+			pth = pth[:ci]
+		}
+		if len(nums) > 0 {
+			fp.Path = pth
+			fp.Line = nums[0]
+			if len(nums) > 1 {
+				fp.Col = nums[1]
+			}
+		}
+	}
+	if abs, err := filepath.Abs(fp.Path); err == nil {
+		fp.Path = abs
+	}
+	return fp
+}
+
+// Pos returns the text position of the file pos, with lines and columns
+// starting at 0
+func (fp *FilePos) Pos() lex.Pos {
+	pos := lex.Pos{}
+	if fp.Line > 0 {
+		pos.Ln = fp.Line - 1
+	}
+	if fp.Col > 0 {
+		pos.Ch = fp.Col - 1
+	}
+	return pos
+}
+
+// ProjRootMarkers are the files and directories that mark the root
+// directory of a project, for finding the project of a file (see ProjRootForFile)
+var ProjRootMarkers = []string{".git", ".hg", ".svn", ".fslckout", "_FOSSIL_", "go.mod"}
+
+// ProjRootForFile returns the root directory of the project that given file
+// (full path) is in: the nearest directory up from it that has a .gide
+// project file named for it, or one of the ProjRootMarkers -- else the
+// directory of the file
+func ProjRootForFile(fpath string) string {
+	fdir := filepath.Dir(fpath)
+	if info, err := os.Stat(fpath); err == nil && info.IsDir() {
+		fdir = fpath
+	}
+	for d := fdir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, filepath.Base(d)+".gide")); err == nil {
+			return d
+		}
+		for _, m := range ProjRootMarkers {
+			if _, err := os.Stat(filepath.Join(d, m)); err == nil {
+				return d
+			}
+		}
+		if filepath.Dir(d) == d {
+			return fdir
+		}
+	}
+}
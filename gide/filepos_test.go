@@ -0,0 +1,73 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goki/pi/lex"
+)
+
+func TestParseFilePos(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-filepos")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	odir, _ := os.Getwd()
+	defer os.Chdir(odir)
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	dir, _ = os.Getwd() // without symlinks, as Abs makes paths
+	if err := ioutil.WriteFile(filepath.Join(dir, "x:12"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	pth := func(fn string) string { return filepath.Join(dir, fn) }
+	tests := []struct {
+		arg string
+		fp  FilePos
+	}{
+		{"a.go", FilePos{Path: pth("a.go")}},
+		{"a.go:12", FilePos{Path: pth("a.go"), Line: 12}},
+		{"a.go:12:3", FilePos{Path: pth("a.go"), Line: 12, Col: 3}},
+		{"a.go:12:3:", FilePos{Path: pth("a.go"), Line: 12, Col: 3}},
+		{"a.go:12:", FilePos{Path: pth("a.go"), Line: 12}},
+		{"a.go:0", FilePos{Path: pth("a.go")}},
+		{"a.go:1:2:3", FilePos{Path: pth("a.go:1"), Line: 2, Col: 3}},
+		{"a.go:x", FilePos{Path: pth("a.go:x")}},
+		{"a.go:-1", FilePos{Path: pth("a.go:-1")}},
+		{"a.go:x:3", FilePos{Path: pth("a.go:x"), Line: 3}},
+		{":12", FilePos{Path: pth(":12")}},
+		{"x:12", FilePos{Path: pth("x:12")}}, // exists as such
+		{"x:12:4", FilePos{Path: pth("x"), Line: 12, Col: 4}},
+		{filepath.Join(dir, "sub", "b.go") + ":7", FilePos{Path: pth(filepath.Join("sub", "b.go")), Line: 7}},
+		{"sub/../a.go:3", FilePos{Path: pth("a.go"), Line: 3}},
+	}
+	for _, tst := range tests {
+		if fp := ParseFilePos(tst.arg); fp != tst.fp {
+			t.Errorf("ParseFilePos error: %q: should have been: %+v  was: %+v\n", tst.arg, tst.fp, fp)
+		}
+	}
+}
+
+func TestFilePosPos(t *testing.T) {
+	tests := []struct {
+		fp  FilePos
+		pos lex.Pos
+	}{
+		{FilePos{Line: 12, Col: 3}, lex.Pos{Ln: 11, Ch: 2}},
+		{FilePos{Line: 1}, lex.Pos{}},
+		{FilePos{}, lex.Pos{}},
+	}
+	for _, tst := range tests {
+		if pos := tst.fp.Pos(); pos != tst.pos {
+			t.Errorf("FilePos Pos error: %+v: should have been: %v  was: %v\n", tst.fp, tst.pos, pos)
+		}
+	}
+}
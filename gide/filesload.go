@@ -0,0 +1,139 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+)
+
+// FilesLoad loads the file tree of a project in the background when it is
+// opened, so the window is usable immediately, even for a large project:
+// the top directory is loaded first, and then the directories that were
+// open, one at a time, each shown as soon as it is loaded
+type FilesLoad struct {
+	Loading bool       `desc:"the files are being loaded"`
+	NDirs   int        `desc:"number of open directories to load"`
+	Dirs    int        `desc:"number of open directories loaded so far"`
+	Pending []func()   `desc:"functions to run when the files are loaded, e.g., to open files in the project"`
+	Mu      sync.Mutex `desc:"mutex protecting the loading state"`
+}
+
+// InitFileTree initializes the root of given file tree for given root
+// directory, without reading it, so it can be shown while loading
+func InitFileTree(ft *giv.FileTree, root string) {
+	ft.FRoot = ft
+	if ft.NodeType == nil {
+		ft.NodeType = KiT_FileNode
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	ft.FPath = gi.FileName(root)
+	ft.SetName(filepath.Base(root))
+	ft.Info.InitFile(root)
+}
+
+// IsLoading returns true if the files are being loaded
+func (fl *FilesLoad) IsLoading() bool {
+	fl.Mu.Lock()
+	defer fl.Mu.Unlock()
+	return fl.Loading
+}
+
+// WhenLoaded runs given function when the files are loaded -- right away
+// if they are
+func (fl *FilesLoad) WhenLoaded(fun func()) {
+	fl.Mu.Lock()
+	if fl.Loading {
+		fl.Pending = append(fl.Pending, fun)
+		fl.Mu.Unlock()
+		return
+	}
+	fl.Mu.Unlock()
+	fun()
+}
+
+// Start starts loading the files of given file tree from given root
+// directory in the background, calling status with the progress, and done
+// when loaded (before the Pending functions)
+func (fl *FilesLoad) Start(ft *giv.FileTree, root string, status func(msg string), done func()) {
+	fl.Mu.Lock()
+	fl.Loading = true
+	fl.Dirs, fl.NDirs = 0, 0
+	fl.Mu.Unlock()
+	InitFileTree(ft, root)
+	go fl.Load(ft, root, status, done)
+}
+
+// Load loads the files of given file tree: the top directory, and then
+// each of the directories that were open, from the top down
+func (fl *FilesLoad) Load(ft *giv.FileTree, root string, status func(msg string), done func()) {
+	st := time.Now()
+	// the open dirs are closed while the top one is read, and then opened
+	// again, one at a time -- on a copy, as the map is that of the prefs
+	var opens []string
+	dirs := giv.DirFlagMap{}
+	for rp, df := range ft.Dirs {
+		dirs[rp] = df
+		if rp != "" && rp != "." && ft.Dirs.IsOpen(rp) {
+			opens = append(opens, rp)
+		}
+	}
+	sort.Slice(opens, func(i, j int) bool {
+		di, dj := strings.Count(opens[i], string(filepath.Separator)), strings.Count(opens[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return opens[i] < opens[j]
+	})
+	for _, rp := range opens {
+		dirs.SetOpen(rp, false)
+	}
+	ft.Dirs = dirs
+	fl.Mu.Lock()
+	fl.NDirs = len(opens)
+	fl.Mu.Unlock()
+	status(fmt.Sprintf("loading project files: %v", root))
+	ft.OpenPath(root)
+	for i, rp := range opens {
+		fl.Mu.Lock()
+		fl.Dirs = i
+		fl.Mu.Unlock()
+		status(fmt.Sprintf("loading project files: directory %d of %d: %v", i+1, len(opens), rp))
+		fn, ok := ft.FindFile(filepath.Join(root, rp))
+		if !ok || !fn.IsDir() || fn.IsOpen() {
+			continue // gone, or in a closed directory
+		}
+		if gfn, ok := fn.This().Embed(KiT_FileNode).(*FileNode); ok {
+			if gfn.LinkDirBlocked() != "" || gfn.OpenDirLazy() {
+				continue
+			}
+		}
+		ft.UpdtMu.Lock()
+		fn.OpenDir()
+		ft.UpdtMu.Unlock()
+	}
+	status(fmt.Sprintf("project files loaded in %v", time.Since(st).Round(time.Millisecond)))
+	if done != nil {
+		done()
+	}
+	fl.Mu.Lock()
+	pend := fl.Pending
+	fl.Pending = nil
+	fl.Loading = false
+	fl.Dirs = len(opens)
+	fl.Mu.Unlock()
+	for _, fun := range pend {
+		fun()
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// FileTabMimeType is the mime type used for drag-n-drop of file tabs --
+// the data is the file name
+const FileTabMimeType = "application/x-gide-filetab"
+
+// FileTabsSignals are signals that FileTabs can send, with the file name as data
+type FileTabsSignals int64
+
+const (
+	// FileTabSelected means that the tab for given file was clicked, or
+	// selected as the neighbor of a closed tab -- file should be viewed
+	FileTabSelected FileTabsSignals = iota
+
+	// FileTabClosed means that the tab for given file was closed, by middle
+	// clicking it or moving it to another pane
+	FileTabClosed
+
+	// FileTabDropped means that the tab for given file was dropped in from
+	// another pane -- file should be viewed
+	FileTabDropped
+
+	FileTabsSignalsN
+)
+
+//go:generate stringer -type=FileTabsSignals
+
+// FileTabs is a bar of tabs for the files viewed in one editor pane -- tabs
+// can be reordered and moved / copied to other panes using drag-n-drop, and
+// closed with a middle click
+type FileTabs struct {
+	gi.Layout
+	CurFile    gi.FileName `desc:"file currently being viewed"`
+	NTabs      int         `json:"-" desc:"counter used for unique naming of tabs"`
+	FileTabSig ki.Signal   `copy:"-" json:"-" xml:"-" desc:"signal for file tab actions -- see FileTabsSignals for the types -- data is the file name"`
+}
+
+var KiT_FileTabs = kit.Types.AddType(&FileTabs{}, FileTabsProps)
+
+// AddNewFileTabs adds a new file tabs bar to given parent node, with given name.
+func AddNewFileTabs(parent ki.Ki, name string) *FileTabs {
+	ft := parent.AddNewChild(KiT_FileTabs, name).(*FileTabs)
+	ft.Lay = gi.LayoutHoriz
+	return ft
+}
+
+var FileTabsProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+	"overflow":      "hidden",
+	"max-width":     -1,
+	"spacing":       units.NewPx(0),
+	"margin":        0,
+	"padding":       0,
+}
+
+// Tabs returns the current list of tabs
+func (ft *FileTabs) Tabs() []*FileTab {
+	tabs := make([]*FileTab, 0, len(ft.Kids))
+	for _, k := range ft.Kids {
+		if tb, ok := k.Embed(KiT_FileTab).(*FileTab); ok {
+			tabs = append(tabs, tb)
+		}
+	}
+	return tabs
+}
+
+// Files returns the files for the current list of tabs, in order
+func (ft *FileTabs) Files() []gi.FileName {
+	tabs := ft.Tabs()
+	fns := make([]gi.FileName, len(tabs))
+	for i, tb := range tabs {
+		fns[i] = tb.Filename
+	}
+	return fns
+}
+
+// TabByFile returns the tab for given file, and its index
+func (ft *FileTabs) TabByFile(fnm gi.FileName) (*FileTab, int, bool) {
+	for i, tb := range ft.Tabs() {
+		if tb.Filename == fnm {
+			return tb, i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// AddTab adds a tab for given file at given index (-1 = at end), returning
+// the tab -- if there is already a tab for the file it is returned as is.
+func (ft *FileTabs) AddTab(fnm gi.FileName, at int) *FileTab {
+	if tb, _, has := ft.TabByFile(fnm); has {
+		return tb
+	}
+	updt := ft.UpdateStart()
+	defer ft.UpdateEnd(updt)
+	if at < 0 || at > len(ft.Kids) {
+		at = len(ft.Kids)
+	}
+	tb := ft.InsertNewChild(KiT_FileTab, at, fmt.Sprintf("tab-%d", ft.NTabs)).(*FileTab)
+	ft.NTabs++
+	tb.Filename = fnm
+	tb.SetText(filepath.Base(string(fnm)))
+	tb.Tooltip = string(fnm)
+	tb.ButtonSig.Connect(ft.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonClicked) {
+			ftt := recv.Embed(KiT_FileTabs).(*FileTabs)
+			tbb := send.Embed(KiT_FileTab).(*FileTab)
+			ftt.FileTabSig.Emit(ftt.This(), int64(FileTabSelected), tbb.Filename)
+		}
+	})
+	ft.SetFullReRender()
+	return tb
+}
+
+// SetCurFile sets the file currently being viewed, adding a tab for it
+// after the current one if not already present, and selecting it
+func (ft *FileTabs) SetCurFile(fnm gi.FileName) {
+	if fnm == "" {
+		ft.CurFile = ""
+		ft.UpdateSelect()
+		return
+	}
+	if _, _, has := ft.TabByFile(fnm); !has {
+		at := -1
+		if _, ci, has := ft.TabByFile(ft.CurFile); has {
+			at = ci + 1
+		}
+		ft.AddTab(fnm, at)
+	}
+	ft.CurFile = fnm
+	ft.UpdateSelect()
+}
+
+// UpdateSelect updates the selected state of the tabs to show the current file
+func (ft *FileTabs) UpdateSelect() {
+	for _, tb := range ft.Tabs() {
+		sel := tb.Filename == ft.CurFile
+		if tb.IsSelected() != sel {
+			tb.SetSelectedState(sel)
+			tb.UpdateSig()
+		}
+	}
+}
+
+// MoveTab moves the tab at index from to index to
+func (ft *FileTabs) MoveTab(from, to int) {
+	if from == to || from < 0 || from >= len(ft.Kids) {
+		return
+	}
+	updt := ft.UpdateStart()
+	ft.Kids.Move(from, to)
+	ft.SetFullReRender()
+	ft.UpdateEnd(updt)
+}
+
+// DeleteTab deletes the tab for given file without sending any signals --
+// if it was the current file, the neighboring tab is made current and its
+// file returned as next, which is empty if no tabs remain.  returns false if
+// not found.
+func (ft *FileTabs) DeleteTab(fnm gi.FileName) (next gi.FileName, has bool) {
+	tb, idx, has := ft.TabByFile(fnm)
+	if !has {
+		return "", false
+	}
+	updt := ft.UpdateStart()
+	ft.DeleteChild(tb.This(), true)
+	ft.SetFullReRender()
+	ft.UpdateEnd(updt)
+	if ft.CurFile != fnm {
+		return "", true
+	}
+	ft.CurFile = ""
+	tabs := ft.Tabs()
+	if len(tabs) == 0 {
+		return "", true
+	}
+	if idx >= len(tabs) {
+		idx = len(tabs) - 1
+	}
+	next = tabs[idx].Filename
+	ft.SetCurFile(next)
+	return next, true
+}
+
+// CloseTab closes the tab for given file -- if it was the current file then
+// the neighboring tab is selected, sending FileTabSelected, and then
+// FileTabClosed is sent for the closed file
+func (ft *FileTabs) CloseTab(fnm gi.FileName) {
+	next, has := ft.DeleteTab(fnm)
+	if !has {
+		return
+	}
+	if next != "" {
+		ft.FileTabSig.Emit(ft.This(), int64(FileTabSelected), next)
+	}
+	ft.FileTabSig.Emit(ft.This(), int64(FileTabClosed), fnm)
+}
+
+// DropTab handles a file tab dropped at given index (-1 = at end) of this
+// bar, from given source tab -- returns the mod actually used
+func (ft *FileTabs) DropTab(fnm gi.FileName, at int, src ki.Ki, mod dnd.DropMods) dnd.DropMods {
+	if stb, ok := src.Embed(KiT_FileTab).(*FileTab); ok && stb.Parent() == ft.This() {
+		_, from, _ := ft.TabByFile(fnm)
+		if at < 0 || at >= len(ft.Kids) {
+			at = len(ft.Kids) - 1
+		}
+		ft.MoveTab(from, at)
+		return dnd.DropCopy // source doesn't need to do anything
+	}
+	if _, cur, has := ft.TabByFile(fnm); has {
+		if at < 0 || at >= len(ft.Kids) {
+			at = len(ft.Kids) - 1
+		}
+		ft.MoveTab(cur, at)
+	} else {
+		ft.AddTab(fnm, at)
+	}
+	ft.SetCurFile(fnm)
+	ft.FileTabSig.Emit(ft.This(), int64(FileTabDropped), fnm)
+	return mod
+}
+
+// FileTabMimeFile returns the file name from file tab mime data, false if none
+func FileTabMimeFile(md mimedata.Mimes) (gi.FileName, bool) {
+	for _, d := range md {
+		if d.Type == FileTabMimeType {
+			return gi.FileName(d.Data), true
+		}
+	}
+	return "", false
+}
+
+// FileTabsEvents connects drag-n-drop onto the bar itself, which drops at
+// the end -- uses LowPri so that drops on the tabs take precedence
+func (ft *FileTabs) FileTabsEvents() {
+	ft.ConnectEvent(oswin.DNDEvent, gi.LowPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.Event)
+		if de.Action != dnd.DropOnTarget {
+			return
+		}
+		ftt := recv.Embed(KiT_FileTabs).(*FileTabs)
+		fnm, ok := FileTabMimeFile(de.Data)
+		if !ok {
+			return
+		}
+		de.Target = ftt.This()
+		de.SetProcessed()
+		mod := ftt.DropTab(fnm, -1, de.Source, de.Mod)
+		ftt.ParentWindow().FinalizeDragNDrop(mod)
+	})
+}
+
+func (ft *FileTabs) ConnectEvents2D() {
+	ft.Layout.ConnectEvents2D()
+	ft.FileTabsEvents()
+}
+
+/////////////////////////////////////////////////////////////////////////////
+//  FileTab
+
+// FileTab is one tab in a FileTabs bar, for given file
+type FileTab struct {
+	gi.Button
+	Filename gi.FileName `desc:"file for this tab"`
+}
+
+var KiT_FileTab = kit.Types.AddType(&FileTab{}, nil)
+
+// FileTabProps are the properties for FileTab, based on gi.ButtonProps
+var FileTabProps map[string]interface{}
+
+func init() {
+	FileTabProps = make(ki.Props, len(gi.ButtonProps))
+	ki.CopyProps(&FileTabProps, gi.ButtonProps, ki.DeepCopy)
+	FileTabProps["border-radius"] = units.NewPx(0)
+	FileTabProps["margin"] = units.NewPx(0)
+	FileTabProps["padding"] = units.NewPx(2)
+	kit.Types.SetProps(KiT_FileTab, FileTabProps)
+}
+
+func (tb *FileTab) CopyFieldsFrom(frm interface{}) {
+	fr := frm.(*FileTab)
+	tb.Button.CopyFieldsFrom(&fr.Button)
+	tb.Filename = fr.Filename
+}
+
+// Tabs returns the FileTabs bar that this tab is in
+func (tb *FileTab) Tabs() *FileTabs {
+	if tb.Par == nil {
+		return nil
+	}
+	ft, _ := tb.Par.Embed(KiT_FileTabs).(*FileTabs)
+	return ft
+}
+
+// DragNDropStart starts a drag-n-drop of this tab
+func (tb *FileTab) DragNDropStart() {
+	md := mimedata.Mimes{&mimedata.Data{Type: FileTabMimeType, Data: []byte(tb.Filename)}}
+	sp := &gi.Sprite{}
+	sp.GrabRenderFrom(tb)
+	gi.ImageClearer(sp.Pixels, 50.0)
+	tb.ParentWindow().StartDragNDrop(tb.This(), md, sp)
+}
+
+// DragNDropTarget handles a drag-n-drop onto this tab -- the dropped tab is
+// placed at the position of this one
+func (tb *FileTab) DragNDropTarget(de *dnd.Event) {
+	ft := tb.Tabs()
+	fnm, ok := FileTabMimeFile(de.Data)
+	if ft == nil || !ok {
+		return
+	}
+	de.Target = tb.This()
+	de.SetProcessed()
+	_, at, _ := ft.TabByFile(tb.Filename)
+	mod := ft.DropTab(fnm, at, de.Source, de.Mod)
+	ft.ParentWindow().FinalizeDragNDrop(mod)
+}
+
+// Dragged is called on the source tab after the drop is finalized -- the tab
+// is closed if it was moved to another pane
+func (tb *FileTab) Dragged(de *dnd.Event) {
+	if de.Mod != dnd.DropMove {
+		return
+	}
+	if ft := tb.Tabs(); ft != nil {
+		ft.CloseTab(tb.Filename)
+	}
+}
+
+// FileTabEvents connects middle-click to close and drag-n-drop events
+func (tb *FileTab) FileTabEvents() {
+	tb.ConnectEvent(oswin.MouseEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		if me.Button != mouse.Middle || me.Action != mouse.Release {
+			return
+		}
+		me.SetProcessed()
+		tbb := recv.Embed(KiT_FileTab).(*FileTab)
+		if ft := tbb.Tabs(); ft != nil {
+			ft.CloseTab(tbb.Filename)
+		}
+	})
+	tb.ConnectEvent(oswin.DNDEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		de := d.(*dnd.Event)
+		tbb := recv.Embed(KiT_FileTab).(*FileTab)
+		switch de.Action {
+		case dnd.Start:
+			tbb.DragNDropStart()
+		case dnd.DropOnTarget:
+			tbb.DragNDropTarget(de)
+		case dnd.DropFmSource:
+			tbb.Dragged(de)
+		}
+	})
+}
+
+func (tb *FileTab) ConnectEvents2D() {
+	tb.Button.ConnectEvents2D()
+	tb.FileTabEvents()
+}
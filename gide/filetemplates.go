@@ -0,0 +1,295 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/pi"
+)
+
+// FileTemplatesDirName is the default directory of the file templates of a
+// project, at its root, when the project prefs do not set one
+var FileTemplatesDirName = ".gide-templates"
+
+// FileTemplateExt is the extension of the template files: a template named
+// go.tmpl applies to the new .go files, _test.go.tmpl to the _test.go files,
+// and Makefile.tmpl to the files named Makefile
+var FileTemplateExt = ".tmpl"
+
+// FileTemplateHeader is the name of the file, in a templates directory, of
+// the header (e.g., a license) inserted by the {Header} variable, commented
+// out as per the language of the new file
+var FileTemplateHeader = "HEADER"
+
+// StdFileTemplates are the templates used when neither the project nor the
+// user have one for a new file, by name as for the template files
+var StdFileTemplates = map[string]string{
+	"go":   "{Header}package {Package}\n",
+	"html": "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n<title>{FileNameNoExt}</title>\n</head>\n<body>\n\n</body>\n</html>\n",
+	"sh":   "#!/bin/sh\n{Header}",
+	"py":   "{Header}",
+	"c":    "{Header}",
+	"h":    "{Header}",
+	"cpp":  "{Header}",
+	"js":   "{Header}",
+}
+
+// FileTemplateVars are the variables replaced by their values in the file
+// templates
+var FileTemplateVars = map[string]string{
+	"{FileName}":      "name of the new file, without path",
+	"{FileNameNoExt}": "name of the new file, without path and extension",
+	"{FileDir}":       "name of the directory of the new file",
+	"{FileDirRel}":    "path of the directory of the new file, relative to the project root",
+	"{Package}":       "Go package of the new file: that of the other Go files in its directory, else its directory name",
+	"{Project}":       "name of the project",
+	"{Author}":        "user.name of git, else the user name",
+	"{Email}":         "user.email of git",
+	"{Date}":          "current date, as 2006-01-02",
+	"{Year}":          "current year",
+	"{Header}":        "header of the templates directory (e.g., a license), commented out for the language of the new file, followed by a blank line -- empty if none",
+}
+
+// FileTemplateMatch returns the length of the part of given file name that
+// the template of given name (without .tmpl) matches -- the whole name, its
+// extension, or a suffix starting with _, - or . -- 0 if it does not match
+func FileTemplateMatch(tnm, fname string) int {
+	switch {
+	case tnm == "":
+		return 0
+	case fname == tnm:
+		return len(tnm) + 1 // whole name is the most specific
+	case strings.HasSuffix(fname, "."+tnm):
+		return len(tnm)
+	case (tnm[0] == '_' || tnm[0] == '-' || tnm[0] == '.') && strings.HasSuffix(fname, tnm):
+		return len(tnm)
+	}
+	return 0
+}
+
+// FileTemplatesDir returns the templates directory of a project at given
+// root, as set in its prefs, FileTemplatesDirName if empty
+func FileTemplatesDir(root string, dir gi.FileName) string {
+	dn := string(dir)
+	if dn == "" {
+		dn = FileTemplatesDirName
+	}
+	if filepath.IsAbs(dn) {
+		return dn
+	}
+	return filepath.Join(root, dn)
+}
+
+// UserFileTemplatesDir returns the templates directory of the user, in the
+// preferences directory, used for the files for which the project has none
+func UserFileTemplatesDir() string {
+	if oswin.TheApp == nil {
+		return ""
+	}
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), "templates")
+}
+
+// FileTemplateIn returns the text of the template for given file name in
+// given directory, the most specific one matching, and whether there is one
+func FileTemplateIn(dir, fname string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	best, bestn := "", 0
+	for _, fi := range fis {
+		nm := fi.Name()
+		if fi.IsDir() || !strings.HasSuffix(nm, FileTemplateExt) {
+			continue
+		}
+		if n := FileTemplateMatch(strings.TrimSuffix(nm, FileTemplateExt), fname); n > bestn {
+			best, bestn = nm, n
+		}
+	}
+	if bestn == 0 {
+		return "", false
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, best))
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// StdFileTemplate returns the standard template for given file name, if any
+func StdFileTemplate(fname string) (string, bool) {
+	var nms []string
+	for nm := range StdFileTemplates {
+		nms = append(nms, nm)
+	}
+	sort.Strings(nms)
+	best, bestn := "", 0
+	for _, nm := range nms {
+		if n := FileTemplateMatch(nm, fname); n > bestn {
+			best, bestn = nm, n
+		}
+	}
+	if bestn == 0 {
+		return "", false
+	}
+	return StdFileTemplates[best], true
+}
+
+// FileTemplateHeaderIn returns the header of given templates directory,
+// commented out for given language, followed by a blank line -- "" if
+// none
+func FileTemplateHeaderIn(dir string, sup filecat.Supported) string {
+	if dir == "" {
+		return ""
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, FileTemplateHeader))
+	if err != nil {
+		return ""
+	}
+	hdr := strings.TrimRight(string(b), "\n\r\t ")
+	if hdr == "" {
+		return ""
+	}
+	cln, cst, ced := "//", "", ""
+	if lp, err := pi.LangSupport.Props(sup); err == nil {
+		cln, cst, ced = lp.CommentLn, lp.CommentSt, lp.CommentEd
+	}
+	lns := strings.Split(hdr, "\n")
+	switch {
+	case cln != "":
+		for i, ln := range lns {
+			lns[i] = strings.TrimRight(strings.TrimSpace(cln)+" "+ln, " ")
+		}
+	case cst != "":
+		lns = append(append([]string{cst}, lns...), ced)
+	}
+	return strings.Join(lns, "\n") + "\n\n"
+}
+
+// FileTemplateAuthor returns the user.name and user.email of git in given
+// directory, the user name if not set
+func FileTemplateAuthor(dir string) (name, email string) {
+	gitcfg := func(key string) string {
+		cmd := exec.Command("git", "config", key)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	name, email = gitcfg("user.name"), gitcfg("user.email")
+	if name == "" {
+		name = os.Getenv("USER")
+		if name == "" {
+			name = os.Getenv("USERNAME")
+		}
+	}
+	return
+}
+
+// FileTemplatePkgName returns the Go package of a new file at given path:
+// that of the other Go files in its directory, else its directory name as
+// an identifier
+func FileTemplatePkgName(fpath string) string {
+	dir := filepath.Dir(fpath)
+	if pkg := GoDirPkgName(dir, fpath); pkg != "" {
+		return pkg
+	}
+	pkg := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, filepath.Base(dir))
+	if pkg == "" || unicode.IsDigit(rune(pkg[0])) {
+		pkg = "main"
+	}
+	return pkg
+}
+
+// FileTemplateText returns the text of the new file at given path, in the
+// project at given root with given templates directory, from its template
+// with the variables replaced -- false if there is no template for it
+func FileTemplateText(fpath, root string, tdir gi.FileName) (string, bool) {
+	fname := filepath.Base(fpath)
+	pdir := FileTemplatesDir(root, tdir)
+	udir := UserFileTemplatesDir()
+	tmpl, ok := FileTemplateIn(pdir, fname)
+	if !ok {
+		tmpl, ok = FileTemplateIn(udir, fname)
+	}
+	if !ok {
+		tmpl, ok = StdFileTemplate(fname)
+	}
+	if !ok {
+		return "", false
+	}
+	hdr := ""
+	if strings.Contains(tmpl, "{Header}") {
+		sup := filecat.SupportedFromFile(fname)
+		hdr = FileTemplateHeaderIn(pdir, sup)
+		if hdr == "" {
+			hdr = FileTemplateHeaderIn(udir, sup)
+		}
+	}
+	vals := map[string]string{}
+	if strings.Contains(tmpl+hdr, "{Package}") {
+		vals["{Package}"] = FileTemplatePkgName(fpath)
+	}
+	if strings.Contains(tmpl+hdr, "{Author}") || strings.Contains(tmpl+hdr, "{Email}") {
+		vals["{Author}"], vals["{Email}"] = FileTemplateAuthor(root)
+	}
+	now := time.Now()
+	vals["{FileName}"] = fname
+	vals["{FileNameNoExt}"] = strings.TrimSuffix(fname, filepath.Ext(fname))
+	vals["{FileDir}"] = filepath.Base(filepath.Dir(fpath))
+	if rel, err := filepath.Rel(root, filepath.Dir(fpath)); err == nil {
+		vals["{FileDirRel}"] = filepath.ToSlash(rel)
+	}
+	vals["{Project}"] = filepath.Base(root)
+	vals["{Date}"] = now.Format("2006-01-02")
+	vals["{Year}"] = now.Format("2006")
+	var rep []string
+	for k, v := range vals {
+		rep = append(rep, k, v)
+	}
+	rp := strings.NewReplacer(rep...)
+	txt := strings.Replace(tmpl, "{Header}", rp.Replace(hdr), -1)
+	return rp.Replace(txt), true
+}
+
+// ApplyFileTemplate writes the text of its template into given new, empty
+// file of given project, with the line endings of the project -- does
+// nothing if there is no template for it
+func ApplyFileTemplate(ge Gide, fpath string) error {
+	if st, err := os.Stat(fpath); err != nil || st.Size() > 0 || st.IsDir() {
+		return err
+	}
+	pf := ge.ProjPrefs()
+	txt, ok := FileTemplateText(fpath, string(pf.ProjRoot), pf.Templates)
+	if !ok || txt == "" {
+		return nil
+	}
+	if pf.LineEnds == LineEndsCRLF {
+		txt = strings.Replace(txt, "\n", "\r\n", -1)
+	}
+	return ioutil.WriteFile(fpath, []byte(txt), 0644)
+}
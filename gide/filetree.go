@@ -5,7 +5,10 @@
 package gide
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -14,14 +17,24 @@ import (
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/pi/filecat"
+	"github.com/goki/vci"
 )
 
 // FileNode is Gide version of FileNode for FileTree view
 type FileNode struct {
 	giv.FileNode
+	DirVcs    vci.FileStatus `json:"-" xml:"-" copy:"-" desc:"for directories, most significant version control status of the files within it, if HasDirVcs (see VcsStatus)"`
+	HasDirVcs bool           `json:"-" xml:"-" copy:"-" desc:"for directories, DirVcs is set"`
+	NEntries  int            `json:"-" xml:"-" copy:"-" desc:"for large directories loaded incrementally (see OpenDirLazy), the number of entries in it"`
+	NLoaded   int            `json:"-" xml:"-" copy:"-" desc:"for large directories being loaded, the number of entries loaded so far"`
+	Loading   bool           `json:"-" xml:"-" copy:"-" desc:"for large directories, the entries are being loaded"`
 }
 
 var KiT_FileNode = kit.Types.AddType(&FileNode{}, nil)
@@ -32,6 +45,135 @@ func (fn *FileNode) CopyFieldsFrom(frm interface{}) {
 	// no copy here
 }
 
+// Label returns the name of the file, followed by a marker of its version
+// control status (see VcsStatusMarker), if the VcsMarkers pref is set, and
+// for large directories, the number of entries (see EntriesLabel)
+func (fn *FileNode) Label() string {
+	nm := fn.Name() + fn.EntriesLabel()
+	ge, ok := ParentGide(fn.This())
+	if !ok || !ge.ProjPrefs().Files.VcsMarkers || fn.IsIrregular() {
+		return nm
+	}
+	mk := ""
+	if fn.IsDir() {
+		if fn.HasDirVcs {
+			mk = "\u2022" // bullet
+		}
+	} else if fn.Info.Vcs != vci.Untracked || !ge.FileIgnore().Ignored(string(fn.FPath), false) {
+		mk = VcsStatusMarker(fn.Info.Vcs)
+	}
+	if mk == "" {
+		return nm
+	}
+	return nm + "  " + mk
+}
+
+// RenameFile renames (moves) the file or directory to given new path, which
+// can be just a new name, or a path relative to the directory it is in.
+// Open buffers of the files are updated to the new path, and for Go files
+// and directories, updating the Go references is offered (see GoRenameRefs).
+func (fn *FileNode) RenameFile(newpath string) error {
+	if fn.IsExternal() || newpath == "" {
+		return nil
+	}
+	orgpath := string(fn.FPath)
+	if !filepath.IsAbs(newpath) {
+		newpath = filepath.Join(filepath.Dir(orgpath), newpath)
+	}
+	if newpath == orgpath {
+		return nil
+	}
+	if _, err := os.Stat(newpath); err == nil {
+		err = fmt.Errorf("cannot rename: %v already exists", newpath)
+		log.Println(err)
+		return err
+	}
+	ge, hasGe := ParentGide(fn.This()) // get now: node may be gone after update
+	isDir := fn.IsDir()
+	if isDir && fn.FRoot.IsDirOpen(fn.FPath) {
+		fn.FRoot.SetDirOpen(gi.FileName(newpath))
+	}
+	var err error
+	if repo, _ := fn.Repo(); repo != nil && fn.Info.Vcs >= vci.Stored {
+		err = repo.Move(orgpath, newpath)
+	}
+	if _, serr := os.Stat(orgpath); serr == nil {
+		err = os.Rename(orgpath, newpath) // no vcs, or vcs move failed
+	}
+	if hasGe {
+		ge.LogAction(ActionFile, "rename", orgpath+" -> "+newpath, err)
+	}
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if ierr := fn.Info.InitFile(newpath); ierr == nil {
+		fn.FPath = gi.FileName(fn.Info.Path)
+		fn.SetName(fn.Info.Name)
+	}
+	fn.FRoot.UpdateDir() // needs full update, as it may have moved
+	if hasGe {
+		ge.RenameOpenNodes(orgpath, newpath)
+		GoRenameRefs(ge, orgpath, newpath, isDir)
+	}
+	return nil
+}
+
+// IsLinkDir returns true if this is a directory reached through a symbolic
+// link, i.e., its (resolved) path is not within the directory of its parent
+func (fn *FileNode) IsLinkDir() bool {
+	if !fn.IsDir() || fn.IsIrregular() || fn.This() == fn.FRoot.This() {
+		return false
+	}
+	par, ok := fn.Par.Embed(giv.KiT_FileNode).(*giv.FileNode)
+	if !ok || par.IsIrregular() {
+		return false
+	}
+	return filepath.Join(string(par.FPath), fn.Nm) != string(fn.FPath)
+}
+
+// LinkDirBlocked returns why this directory cannot be opened if it is
+// reached through a symbolic link that is not to be followed: links are
+// not followed if the FollowLinks file pref is off, or if the link leads
+// back to a directory that contains it (a cycle) -- "" if it can be opened
+func (fn *FileNode) LinkDirBlocked() string {
+	if !fn.IsLinkDir() {
+		return ""
+	}
+	if ge, ok := ParentGide(fn.This()); ok && !ge.ProjPrefs().Files.FollowLinks {
+		return fmt.Sprintf("%v is a link to %v, and links are not followed (see FollowLinks in the project prefs)", fn.Nm, fn.FPath)
+	}
+	cycle := false
+	fn.FuncUpParent(0, fn, func(k ki.Ki, level int, d interface{}) bool {
+		pfn, ok := k.Embed(giv.KiT_FileNode).(*giv.FileNode)
+		if !ok {
+			return ki.Break
+		}
+		if pfn.FPath == fn.FPath {
+			cycle = true
+			return ki.Break
+		}
+		return ki.Continue
+	})
+	if cycle {
+		return fmt.Sprintf("%v is a link to %v, which contains it -- not followed, as it is a cycle", fn.Nm, fn.FPath)
+	}
+	return ""
+}
+
+// OpenBufNodes returns the node itself and the nodes within it (for a
+// directory) that have an open buffer
+func (fn *FileNode) OpenBufNodes() []*giv.FileNode {
+	var fns []*giv.FileNode
+	fn.FuncDownMeFirst(0, fn, func(k ki.Ki, level int, d interface{}) bool {
+		if sfn, ok := k.Embed(giv.KiT_FileNode).(*giv.FileNode); ok && sfn.Buf != nil {
+			fns = append(fns, sfn)
+		}
+		return ki.Continue
+	})
+	return fns
+}
+
 // ParentGide returns the Gide parent of given node
 func ParentGide(kn ki.Ki) (Gide, bool) {
 	if ki.IsRoot(kn) {
@@ -106,6 +248,12 @@ func (on *OpenNodes) Add(fn *giv.FileNode) bool {
 	if !added {
 		return added
 	}
+	on.ConnectBuf(fn)
+	return added
+}
+
+// ConnectBuf connects to fn.TextBuf signal to auto-close when buffer closes
+func (on *OpenNodes) ConnectBuf(fn *giv.FileNode) {
 	if fn.Buf != nil {
 		fn.Buf.TextBufSig.Connect(fn.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 			if sig == int64(giv.TextBufClosed) {
@@ -114,7 +262,33 @@ func (on *OpenNodes) Add(fn *giv.FileNode) bool {
 			}
 		})
 	}
-	return added
+}
+
+// Rename updates the buffer of given open node for the file having been
+// renamed (or moved) to newpath, and moves it to nfn, the node for the new
+// path (if different), which takes the place of ofn in the list
+func (on *OpenNodes) Rename(ofn, nfn *giv.FileNode, newpath string) {
+	buf := ofn.Buf
+	if buf == nil {
+		return
+	}
+	buf.Filename = gi.FileName(newpath)
+	buf.SetName(newpath)
+	buf.Stat()
+	if nfn == nil || nfn == ofn {
+		ofn.FPath = gi.FileName(newpath)
+		return
+	}
+	buf.TextBufSig.Disconnect(ofn.This())
+	ofn.Buf = nil
+	nfn.Buf = buf
+	nfn.SetOpen()
+	for i, f := range *on {
+		if f == ofn {
+			(*on)[i] = nfn
+		}
+	}
+	on.ConnectBuf(nfn)
 }
 
 // AddImpl adds given node to list of open nodes -- if already on the list it is
@@ -219,11 +393,68 @@ type FileSearchResults struct {
 	Matches []textbuf.Match
 }
 
+// FindRegexp compiles the given find regexp, which is made case insensitive
+// if ignoreCase is set
+func FindRegexp(find string, ignoreCase bool) (*regexp.Regexp, error) {
+	if ignoreCase {
+		find = "(?i)" + find
+	}
+	return regexp.Compile(find)
+}
+
+// FindGlobs returns the list of file name globs in given string, separated
+// by spaces and / or commas
+func FindGlobs(globs string) []string {
+	return strings.FieldsFunc(globs, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+// FindGlobsMatch returns true if any of the given globs matches the name of
+// given file, its path relative to the root of the tree, or, if dirs is
+// true, the name of any of the directories it is in (e.g., to exclude all
+// files within vendor directories)
+func FindGlobsMatch(globs []string, fn *giv.FileNode, dirs bool) bool {
+	return FindGlobsMatchPath(globs, filepath.ToSlash(fn.MyRelPath()), dirs)
+}
+
+// FindGlobsMatchPath is FindGlobsMatch for given path relative to the root,
+// using / separators
+func FindGlobsMatchPath(globs []string, rpath string, dirs bool) bool {
+	nm := path.Base(rpath)
+	for _, gl := range globs {
+		gl = strings.TrimSuffix(filepath.ToSlash(gl), "/")
+		if m, _ := filepath.Match(gl, nm); m {
+			return true
+		}
+		if m, _ := filepath.Match(gl, rpath); m {
+			return true
+		}
+		if !dirs {
+			continue
+		}
+		els := strings.Split(rpath, "/")
+		for _, el := range els[:len(els)-1] {
+			if m, _ := filepath.Match(gl, el); m {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // FileTreeSearch returns list of all nodes starting at given node of given
 // language(s) that contain the given string (non regexp version), sorted in
 // descending order by number of occurrences -- ignoreCase transforms
-// everything into lowercase
-func FileTreeSearch(start *giv.FileNode, find string, ignoreCase, regExp bool, loc FindLoc, activeDir string, langs []filecat.Supported) []FileSearchResults {
+// everything into lowercase.  If include globs are given, only files
+// matching one of them are searched, and files matching any exclude glob
+// (or in a directory matching one) are not searched (see FindGlobsMatch).
+// For FindLocDir, dirRecursive also searches the (open) subdirectories of
+// activeDir.  FindLocOpen is handled by OpenNodesSearch.  If filter is
+// non-nil, files for which it returns false are skipped without being read
+// (see SymIndex.FindFilter).  The files are searched in parallel (see
+// SearchFiles).
+func FileTreeSearch(start *giv.FileNode, find string, ignoreCase, regExp bool, loc FindLoc, activeDir string, dirRecursive bool, langs []filecat.Supported, include, exclude []string, filter func(sfn *giv.FileNode) bool) []FileSearchResults {
 	fb := []byte(find)
 	fsz := len(find)
 	if fsz == 0 {
@@ -232,32 +463,40 @@ func FileTreeSearch(start *giv.FileNode, find string, ignoreCase, regExp bool, l
 	var re *regexp.Regexp
 	var err error
 	if regExp {
-		re, err = regexp.Compile(find)
+		re, err = FindRegexp(find, ignoreCase)
 		if err != nil {
 			log.Println(err)
 			return nil
 		}
 	}
+	files := FileTreeSearchFiles(start, loc, activeDir, dirRecursive, langs, include, exclude, filter)
 	mls := make([]FileSearchResults, 0)
+	SearchFiles(files, fb, re, ignoreCase, nil, func(res FileSearchResults) {
+		mls = append(mls, res)
+	})
+	SortFileSearchResults(mls)
+	return mls
+}
+
+// FileTreeSearchFiles returns the files starting at given node that are
+// searched by FileTreeSearch with given parameters, in tree order
+func FileTreeSearchFiles(start *giv.FileNode, loc FindLoc, activeDir string, dirRecursive bool, langs []filecat.Supported, include, exclude []string, filter func(sfn *giv.FileNode) bool) []*giv.FileNode {
+	var files []*giv.FileNode
 	start.FuncDownMeFirst(0, start, func(k ki.Ki, level int, d interface{}) bool {
 		sfn := k.Embed(giv.KiT_FileNode).(*giv.FileNode)
 		if sfn.IsDir() && !sfn.IsOpen() {
 			// fmt.Printf("dir: %v closed\n", sfn.FPath)
 			return ki.Break // don't go down into closed directories!
 		}
-		if sfn.IsDir() || sfn.IsExec() || sfn.Info.Kind == "octet-stream" || sfn.IsAutoSave() {
-			// fmt.Printf("dir: %v opened\n", sfn.Nm)
+		if sfn.IsDir() || !FindFileOk(sfn, langs, include, exclude) {
 			return ki.Continue
 		}
-		if strings.HasSuffix(sfn.Nm, ".gide") { // exclude self
-			return ki.Continue
-		}
-		if !filecat.IsMatchList(langs, sfn.Info.Sup) {
+		if filter != nil && !filter(sfn) {
 			return ki.Continue
 		}
 		if loc == FindLocDir {
 			cdir, _ := filepath.Split(string(sfn.FPath))
-			if activeDir != cdir {
+			if activeDir != cdir && !(dirRecursive && strings.HasPrefix(cdir, activeDir)) {
 				return ki.Continue
 			}
 		} else if loc == FindLocNotTop {
@@ -265,32 +504,91 @@ func FileTreeSearch(start *giv.FileNode, find string, ignoreCase, regExp bool, l
 				return ki.Continue
 			}
 		}
-		var cnt int
-		var matches []textbuf.Match
-		if sfn.IsOpen() && sfn.Buf != nil {
-			if regExp {
-				cnt, matches = sfn.Buf.SearchRegexp(re)
-			} else {
-				cnt, matches = sfn.Buf.Search(fb, ignoreCase, false)
-			}
-		} else {
-			if regExp {
-				cnt, matches = textbuf.SearchFileRegexp(string(sfn.FPath), re)
-			} else {
-				cnt, matches = textbuf.SearchFile(string(sfn.FPath), fb, ignoreCase)
-			}
+		files = append(files, sfn)
+		return ki.Continue
+	})
+	return files
+}
+
+// SortFileSearchResults sorts the results in descending order by number of
+// occurrences, and then by file path
+func SortFileSearchResults(mls []FileSearchResults) {
+	sort.Slice(mls, func(i, j int) bool {
+		if mls[i].Count != mls[j].Count {
+			return mls[i].Count > mls[j].Count
+		}
+		return mls[i].Node.FPath < mls[j].Node.FPath
+	})
+}
+
+// OpenNodesSearch is FileTreeSearch for the files in given list of files
+// that are open for editing, for FindLocOpen
+func OpenNodesSearch(nodes OpenNodes, find string, ignoreCase, regExp bool, langs []filecat.Supported, include, exclude []string) []FileSearchResults {
+	if find == "" {
+		return nil
+	}
+	var re *regexp.Regexp
+	if regExp {
+		var err error
+		re, err = FindRegexp(find, ignoreCase)
+		if err != nil {
+			log.Println(err)
+			return nil
+		}
+	}
+	mls := make([]FileSearchResults, 0)
+	for _, sfn := range nodes {
+		if sfn.Buf == nil || !FindFileOk(sfn, langs, include, exclude) {
+			continue
 		}
+		cnt, matches := FileNodeSearch(sfn, []byte(find), re, ignoreCase)
 		if cnt > 0 {
 			mls = append(mls, FileSearchResults{sfn, cnt, matches})
 		}
-		return ki.Continue
-	})
+	}
 	sort.Slice(mls, func(i, j int) bool {
 		return mls[i].Count > mls[j].Count
 	})
 	return mls
 }
 
+// FindFileOk returns true if given file should be searched: it is not
+// executable, binary, autosave or a project file, it is one of given
+// languages, and it matches the include and exclude globs
+func FindFileOk(sfn *giv.FileNode, langs []filecat.Supported, include, exclude []string) bool {
+	if sfn.IsExec() || sfn.Info.Kind == "octet-stream" || sfn.IsAutoSave() {
+		return false
+	}
+	if strings.HasSuffix(sfn.Nm, ".gide") { // exclude self
+		return false
+	}
+	if !filecat.IsMatchList(langs, sfn.Info.Sup) {
+		return false
+	}
+	if len(include) > 0 && !FindGlobsMatch(include, sfn, false) {
+		return false
+	}
+	if len(exclude) > 0 && FindGlobsMatch(exclude, sfn, true) {
+		return false
+	}
+	return true
+}
+
+// FileNodeSearch searches given file for find string, or regexp re if
+// non-nil, using its buffer if open, else the file
+func FileNodeSearch(sfn *giv.FileNode, fb []byte, re *regexp.Regexp, ignoreCase bool) (int, []textbuf.Match) {
+	if sfn.IsOpen() && sfn.Buf != nil {
+		if re != nil {
+			return sfn.Buf.SearchRegexp(re)
+		}
+		return sfn.Buf.Search(fb, ignoreCase, false)
+	}
+	if re != nil {
+		return textbuf.SearchFileRegexp(string(sfn.FPath), re)
+	}
+	return textbuf.SearchFile(string(sfn.FPath), fb, ignoreCase)
+}
+
 /////////////////////////////////////////////////////////////////////////
 // FileTreeView is the Gide version of the FileTreeView
 
@@ -328,9 +626,33 @@ func init() {
 			"label":    "Set Run Exec",
 			"updtfunc": FileTreeActiveExecFunc,
 		}},
+		{"FileHistory", ki.Props{
+			"label":    "File History",
+			"desc":     "show the history of commits of the file, for diffs between its revisions and viewing it at a revision",
+			"updtfunc": FileTreeInactiveDirFunc,
+		}},
+		{"CompareFiles", ki.Props{
+			"label":    "Compare Files",
+			"desc":     "show the differences between the two selected files side by side -- or between the selected file and one to choose",
+			"updtfunc": FileTreeActiveCompareFunc,
+		}},
+		{"ViewTable", ki.Props{
+			"label":    "View As Table",
+			"desc":     "show the delimited text file (.csv, .tsv) as a table, with sortable and hideable columns, and editable cells",
+			"updtfunc": FileTreeActiveCSVFunc,
+		}},
 		{"sep-view", ki.BlankProp{}},
 	}, cm...)
+	for i := range cm {
+		if pp, ok := cm[i].Value.(ki.Props); ok && cm[i].Name == "DeleteFiles" {
+			pp["desc"] = "move file(s) to the trash -- use Undo Delete in the File menu to restore them"
+		}
+	}
 	FileTreeViewProps["CtxtMenuActive"] = cm
+	FileTreeViewProps[".ignored"] = ki.Props{
+		"color":      "#a0a0a0",
+		"font-style": "italic",
+	}
 	kit.Types.SetProps(KiT_FileTreeView, FileTreeViewProps)
 }
 
@@ -343,6 +665,53 @@ func (ft *FileTreeView) FileNode() *FileNode {
 	return fn.(*FileNode)
 }
 
+// Ignored returns true if the file node is ignored by the .gitignore or
+// .gideignore files of the project, and whether it is shown (when ignored
+// nodes are shown, and it is not hidden)
+func (ft *FileTreeView) Ignored() (ign, show bool) {
+	fn := ft.FileNode()
+	if fn == nil || ft.This() == ft.RootView.This() {
+		return false, false
+	}
+	ge, ok := ParentGide(fn.This())
+	if !ok {
+		return false, false
+	}
+	fi := ge.FileIgnore()
+	ign = fi.Ignored(string(fn.FPath), fn.IsDir())
+	show = ign && ge.ProjPrefs().Files.ShowIgnored && !fi.Hidden(string(fn.FPath), fn.IsDir())
+	return ign, show
+}
+
+func (ft *FileTreeView) Style2D() {
+	ft.FileTreeView.Style2D()
+	restyle := false
+	if fn := ft.FileNode(); fn != nil && fn.IsDir() && fn.HasDirVcs {
+		if cls := VcsStatusClass(fn.DirVcs); cls != "" {
+			ft.AddClass(cls)
+			restyle = true
+		}
+	}
+	if ign, show := ft.Ignored(); ign && show {
+		ft.AddClass("ignored")
+		restyle = true
+	}
+	if restyle {
+		ft.StyleTreeView()
+		ft.LayState.SetFromStyle(&ft.Sty.Layout)
+	}
+}
+
+func (ft *FileTreeView) Size2D(iter int) {
+	if ign, show := ft.Ignored(); ign && !show {
+		ft.InitLayout2D() // hidden: takes no space, and is not rendered
+		ft.LayState.Alloc.Size.SetZero()
+		ft.WidgetSize.SetZero()
+		return
+	}
+	ft.FileTreeView.Size2D(iter)
+}
+
 // EditFiles calls EditFile on selected files
 func (ft *FileTreeView) EditFiles() {
 	sels := ft.SelectedViews()
@@ -370,6 +739,330 @@ func (ft *FileTreeView) SetRunExec() {
 	}
 }
 
+// ViewTable shows the (first) selected delimited text file as a table
+func (ft *FileTreeView) ViewTable() {
+	sels := ft.SelectedViews()
+	for i := len(sels) - 1; i >= 0; i-- {
+		sn := sels[i]
+		ftv := sn.Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ftv.FileNode()
+		if fn == nil || fn.IsDir() || !IsCSVFile(string(fn.FPath)) {
+			continue
+		}
+		if ge, ok := ParentGide(fn.This()); ok {
+			ge.ViewTablePath(string(fn.FPath))
+		}
+		break
+	}
+}
+
+// FileHistory shows the history of commits of the (first) selected file
+func (ft *FileTreeView) FileHistory() {
+	sels := ft.SelectedViews()
+	for i := len(sels) - 1; i >= 0; i-- {
+		sn := sels[i]
+		ftv := sn.Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ftv.FileNode()
+		if fn == nil || fn.IsDir() {
+			continue
+		}
+		if ge, ok := ParentGide(fn.This()); ok {
+			ge.FileHistoryPath(string(fn.FPath))
+		}
+		break
+	}
+}
+
+// SelectedFiles returns the paths of the selected files, not directories,
+// in the order of the selection
+func (ft *FileTreeView) SelectedFiles() []string {
+	var fpaths []string
+	for _, sn := range ft.SelectedViews() {
+		ftv := sn.Embed(KiT_FileTreeView).(*FileTreeView)
+		if fn := ftv.FileNode(); fn != nil && !fn.IsDir() {
+			fpaths = append(fpaths, string(fn.FPath))
+		}
+	}
+	return fpaths
+}
+
+// CompareFiles shows the differences between the two selected files, or
+// between the selected file and one chosen in a dialog
+func (ft *FileTreeView) CompareFiles() {
+	fpaths := ft.SelectedFiles()
+	if len(fpaths) == 0 || len(fpaths) > 2 {
+		return
+	}
+	ge, ok := ParentGide(ft.SrcNode)
+	if !ok {
+		return
+	}
+	if len(fpaths) == 2 {
+		ge.DiffFiles(gi.FileName(fpaths[0]), gi.FileName(fpaths[1]))
+		return
+	}
+	fa := fpaths[0]
+	giv.FileViewDialog(ft.Viewport, filepath.Dir(fa), "", giv.DlgOpts{Title: "Compare " + filepath.Base(fa) + " With"}, nil,
+		ft.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				dlg, _ := send.(*gi.Dialog)
+				if fb := giv.FileViewDialogValue(dlg); fb != "" {
+					ge.DiffFiles(gi.FileName(fa), gi.FileName(fb))
+				}
+			}
+		})
+}
+
+// DropDir returns the directory that files dropped onto this node go into:
+// the node itself if a directory, otherwise its parent -- nil if external
+func (ft *FileTreeView) DropDir() *FileNode {
+	fn := ft.FileNode()
+	if fn == nil || fn.IsExternal() {
+		return nil
+	}
+	if fn.IsDir() {
+		return fn
+	}
+	if pfn, ok := fn.Parent().Embed(KiT_FileNode).(*FileNode); ok {
+		return pfn
+	}
+	return nil
+}
+
+// Drop handles files dragged within the tree onto this node: after
+// confirmation, they are moved into its directory (see DropDir) by
+// renaming them, which keeps their version control history and open
+// buffers (see FileNode.RenameFile), or copied there
+func (ft *FileTreeView) Drop(md mimedata.Mimes, mod dnd.DropMods) {
+	win := ft.ParentWindow()
+	if win == nil || !win.EventMgr.DNDIsInternalSrc() {
+		ft.DropExternal(md, mod)
+		return
+	}
+	tdir := ft.DropDir()
+	if tdir == nil {
+		ft.DropCancel()
+		return
+	}
+	tpath := string(tdir.FPath)
+	var paths, names []string
+	for i := 0; i+1 < len(md); i += 3 { // 0 = ki path, 1 = file path, 2 = file data
+		fp := string(md[i+1].Data)
+		if filepath.Dir(fp) == tpath || fp == tpath || strings.HasPrefix(tpath, fp+string(filepath.Separator)) {
+			continue // already there, or into itself
+		}
+		paths = append(paths, fp)
+		names = append(names, filepath.Base(fp))
+	}
+	if len(paths) == 0 {
+		ft.DropCancel()
+		return
+	}
+	gi.ChoiceDialog(ft.ViewportSafe(), gi.DlgOpts{Title: "Move Files?",
+		Prompt: fmt.Sprintf("Move: %v into folder: %v, or copy them there?", strings.Join(names, ", "), tdir.Nm)},
+		[]string{"Move", "Copy", "Cancel"},
+		ft.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			ftv := recv.Embed(KiT_FileTreeView).(*FileTreeView)
+			switch sig {
+			case 0:
+				ftv.DragNDropFinalize(dnd.DropIgnore) // not deleted by source
+				MoveFilesToDir(tdir, paths)
+			case 1:
+				win.EventMgr.DNDDropMod = dnd.DropCopy
+				ftv.PasteMimeCopyFilesCheck(&tdir.FileNode, md)
+			default:
+				ftv.DropCancel()
+			}
+		})
+}
+
+// DropExternal copies files dropped from outside the app (e.g., from the
+// OS file manager) into the directory of this node (see DropDir)
+func (ft *FileTreeView) DropExternal(md mimedata.Mimes, mod dnd.DropMods) {
+	tdir := ft.DropDir()
+	if tdir == nil {
+		ft.DropCancel()
+		return
+	}
+	ft.PasteMimeCopyFilesCheck(&tdir.FileNode, md)
+}
+
+// MoveFilesToDir moves the files at given paths into given directory, by
+// renaming them (see FileNode.RenameFile) -- files that would overwrite an
+// existing file are not moved, and reported in the status bar
+func MoveFilesToDir(tdir *FileNode, paths []string) {
+	ge, hasGe := ParentGide(tdir.This())
+	froot := tdir.FRoot
+	tpath := string(tdir.FPath)
+	var exist []string
+	for _, fp := range paths {
+		np := filepath.Join(tpath, filepath.Base(fp))
+		if _, err := os.Stat(np); err == nil {
+			exist = append(exist, filepath.Base(fp))
+			continue
+		}
+		sfni, ok := froot.FindFile(fp)
+		if !ok {
+			continue
+		}
+		if sfn, ok := sfni.This().Embed(KiT_FileNode).(*FileNode); ok {
+			sfn.RenameFile(np)
+		}
+	}
+	if len(exist) > 0 && hasGe {
+		ge.SetStatus(fmt.Sprintf("not moved, as they already exist in %v: %v", tpath, strings.Join(exist, ", ")))
+	}
+}
+
+// FileTreeRenameChord is the key renaming the selected files of the file tree
+var FileTreeRenameChord = key.Chord("F2")
+
+// ConnectEvents2D handles the delete and new file keys before the giv
+// FileTreeView, so they use our DeleteFiles and NewFile, and the rename key
+func (ftv *FileTreeView) ConnectEvents2D() {
+	ftv.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ftvv := recv.Embed(KiT_FileTreeView).(*FileTreeView)
+		kt := d.(*key.ChordEvent)
+		if ftvv.IsInactive() || kt.IsProcessed() {
+			return
+		}
+		switch gi.KeyFun(kt.Chord()) {
+		case gi.KeyFunDelete, gi.KeyFunBackspace:
+			ftvv.DeleteFiles()
+			kt.SetProcessed()
+		case gi.KeyFunInsert:
+			giv.CallMethod(ftvv, "NewFile", ftvv.ViewportSafe())
+			kt.SetProcessed()
+		}
+		if !kt.IsProcessed() && kt.Chord() == FileTreeRenameChord {
+			ftvv.RenameFiles()
+			kt.SetProcessed()
+		}
+	})
+	ftv.FileTreeView.ConnectEvents2D()
+}
+
+// NewFile makes a new file in the selected directory, or that of the
+// selected file, with the text of its template (see FileTemplateText)
+func (ftv *FileTreeView) NewFile(filename string, addToVcs bool) {
+	sels := ftv.SelectedViews()
+	if len(sels) == 0 {
+		return
+	}
+	fn := sels[len(sels)-1].Embed(KiT_FileTreeView).(*FileTreeView).FileNode()
+	if fn == nil || fn.IsExternal() {
+		return
+	}
+	ppath := string(fn.FPath)
+	if !fn.IsDir() {
+		ppath = filepath.Dir(ppath)
+	}
+	np := filepath.Join(ppath, filename)
+	_, err := os.Stat(np)
+	exists := err == nil
+	ftv.FileTreeView.NewFile(filename, addToVcs)
+	if exists {
+		return
+	}
+	if ge, ok := ParentGide(fn.This()); ok {
+		if err := ApplyFileTemplate(ge, np); err != nil {
+			ge.SetStatus("could not write the template of the new file: " + err.Error())
+		}
+	}
+}
+
+// DeleteFiles moves the selected files and directories to the trash (see
+// FileTrash), after confirming, and warning about any with unsaved changes
+func (ftv *FileTreeView) DeleteFiles() {
+	fn := ftv.FileNode()
+	if fn == nil {
+		return
+	}
+	ge, ok := ParentGide(fn.This())
+	if !ok {
+		ftv.FileTreeView.DeleteFiles()
+		return
+	}
+	var nodes []*FileNode
+	var unsaved []string
+	sels := ftv.SelectedViews()
+	for i := len(sels) - 1; i >= 0; i-- {
+		sfn := sels[i].Embed(KiT_FileTreeView).(*FileTreeView).FileNode()
+		if sfn == nil || sfn.IsExternal() || sfn.This() == sfn.FRoot.This() {
+			continue
+		}
+		nodes = append(nodes, sfn)
+		for _, ofn := range sfn.OpenBufNodes() {
+			if ofn.Buf.IsChanged() {
+				unsaved = append(unsaved, giv.DirAndFile(string(ofn.FPath)))
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	prompt := "Move file(s) to the trash?  If any selections are directories, all their files and subdirectories are moved too.  Use Undo Delete in the File menu to restore them."
+	if len(unsaved) > 0 {
+		prompt += fmt.Sprintf("<br><br><b>Warning:</b> these files have unsaved changes, which will be lost: %v", strings.Join(unsaved, ", "))
+	}
+	gi.ChoiceDialog(ftv.ViewportSafe(), gi.DlgOpts{Title: "Move to Trash?", Prompt: prompt},
+		[]string{"Move to Trash", "Cancel"},
+		ftv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == 0 {
+				TrashFiles(ge, nodes)
+			}
+		})
+}
+
+// TrashFiles moves the files of given nodes to the trash of the project,
+// closing their buffers (discarding any changes), and updates the tree
+func TrashFiles(ge Gide, nodes []*FileNode) {
+	var paths []string
+	for _, fn := range nodes {
+		for _, ofn := range fn.OpenBufNodes() {
+			ofn.Buf.ClearChanged()
+			ofn.Buf.AutoSaveDelete()
+			ofn.CloseBuf()
+		}
+		paths = append(paths, string(fn.FPath))
+	}
+	ft := nodes[0].FRoot
+	tfs, err := ge.FileTrash().MoveToTrash(paths)
+	ge.LogAction(ActionFile, "delete", strings.Join(paths, " "), err)
+	ft.UpdateDir()
+	ge.UpdateVcsStatus()
+	if err != nil {
+		log.Println(err)
+		gi.PromptDialog(ge.VPort(), gi.DlgOpts{Title: "Could Not Move to Trash", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+	if len(tfs) > 0 {
+		ge.SetStatus(fmt.Sprintf("moved %d file(s) to the trash -- use Undo Delete to restore", len(tfs)))
+	}
+}
+
+// OpenDir opens the selected directories, loading large ones incrementally
+// (see OpenDirLazy), except for links that are not followed (see LinkDirBlocked)
+func (ftv *FileTreeView) OpenDir() {
+	sels := ftv.SelectedViews()
+	for i := len(sels) - 1; i >= 0; i-- {
+		sn := sels[i]
+		ftvv := sn.Embed(KiT_FileTreeView).(*FileTreeView)
+		fn := ftvv.FileNode()
+		if fn == nil {
+			continue
+		}
+		if msg := fn.LinkDirBlocked(); msg != "" {
+			if ge, ok := ParentGide(fn.This()); ok {
+				ge.SetStatus(msg)
+			}
+			continue
+		}
+		if !fn.OpenDirLazy() {
+			fn.OpenDir()
+		}
+	}
+}
+
 // RenameFiles calls RenameFile on any selected nodes
 func (ftv *FileTreeView) RenameFiles() {
 	fn := ftv.FileNode()
@@ -391,7 +1084,6 @@ func (ftv *FileTreeView) RenameFiles() {
 				nodes = append(nodes, fn)
 			}
 		}
-		ge.CloseOpenNodes(nodes) // close before rename because we are async after this
 		for _, fn := range nodes {
 			giv.CallMethod(fn, "RenameFile", ftv.Viewport)
 		}
@@ -409,7 +1101,7 @@ func FileTreeViewExecCmds(it interface{}, vp *gi.Viewport2D) []string {
 		if !ok {
 			return nil
 		}
-		return AvailCmds.FilterCmdNames(filecat.NoSupport, ge.VersCtrl())
+		return AvailCmds.FilterCmdNames(filecat.NoSupport, ge.FileVersCtrl(string(ge.ProjPrefs().ProjRoot)))
 	}
 	fn := ft.FileNode()
 	if fn == nil {
@@ -423,7 +1115,7 @@ func FileTreeViewExecCmds(it interface{}, vp *gi.Viewport2D) []string {
 	if fn != nil {
 		lang = fn.Info.Sup
 	}
-	cmds := AvailCmds.FilterCmdNames(lang, ge.VersCtrl())
+	cmds := AvailCmds.FilterCmdNames(lang, ge.FileVersCtrl(string(fn.FPath)))
 	return cmds
 }
 
@@ -466,6 +1158,24 @@ var FileTreeInactiveDirFunc = giv.ActionUpdateFunc(func(fni interface{}, act *gi
 	}
 })
 
+// FileTreeActiveCSVFunc is an ActionUpdateFunc that activates action if
+// node is a delimited text file (.csv, .tsv)
+var FileTreeActiveCSVFunc = giv.ActionUpdateFunc(func(fni interface{}, act *gi.Action) {
+	ft := fni.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
+	fn := ft.FileNode()
+	if fn != nil {
+		act.SetActiveState(!fn.IsDir() && IsCSVFile(string(fn.FPath)))
+	}
+})
+
+// FileTreeActiveCompareFunc is an ActionUpdateFunc that activates action if
+// one or two files are selected
+var FileTreeActiveCompareFunc = giv.ActionUpdateFunc(func(fni interface{}, act *gi.Action) {
+	ft := fni.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
+	n := len(ft.SelectedFiles())
+	act.SetActiveState(n == 1 || n == 2)
+})
+
 // FileTreeActiveDirFunc is an ActionUpdateFunc that activates action if node is a dir
 var FileTreeActiveDirFunc = giv.ActionUpdateFunc(func(fni interface{}, act *gi.Action) {
 	ft := fni.(ki.Ki).Embed(KiT_FileTreeView).(*FileTreeView)
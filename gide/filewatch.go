@@ -0,0 +1,295 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/goki/gi/giv"
+)
+
+// FileWatchDelay is how long the FileWatcher waits after the last change
+// before updating the file tree, so that a burst of changes (e.g., from git
+// checkout) results in a single update
+var FileWatchDelay = 250 * time.Millisecond
+
+// FileWatchMaxDelay is the maximum time the FileWatcher waits to update the
+// file tree while changes keep happening
+var FileWatchMaxDelay = 2 * time.Second
+
+// FileWatchMaxDirs is the maximum number of directories watched by the
+// FileWatcher -- each one uses system resources
+var FileWatchMaxDirs = 4000
+
+// FileWatchMaxFiles is the number of files changed in one update of the
+// FileWatcher beyond which they are handled as a whole, e.g., by rescanning
+// the project, instead of one by one (see FileFunc)
+var FileWatchMaxFiles = 500
+
+// FileWatchFunc is the function called by the FileWatcher with the files
+// changed since its last update, sorted
+type FileWatchFunc func(fpaths []string)
+
+// FileWatcher watches all the directories of a project (except hidden,
+// excluded and ignored ones, see QuickOpenSkip and FileIgnore) for files being created, removed or
+// renamed by external tools (git checkout, go generate), and updates the
+// file tree for them -- the changed files are also passed to FileFunc.
+// Changes are collected for FileWatchDelay (up to FileWatchMaxDelay while
+// they keep coming), and then handled in a single batched update: each
+// changed directory that is open in the tree is updated once, the files of
+// each repository are read once, the view is re-rendered once, and
+// FileFunc is called once with all the changed files.
+type FileWatcher struct {
+	Tree     *giv.FileTree     `desc:"file tree being updated"`
+	View     *FileTreeView     `desc:"view of the tree, re-rendered on updates, if set"`
+	Exclude  []string          `desc:"globs of files and directories not watched"`
+	Ignore   *FileIgnore       `desc:"ignored files and directories, not watched, if set"`
+	FileFunc FileWatchFunc     `desc:"function called with the files that have been changed, created or removed, once per update"`
+	Watcher  *fsnotify.Watcher `desc:"the watcher"`
+	Dirs     map[string]bool   `desc:"directories being watched"`
+	Pending  map[string]bool   `desc:"directories with changes pending update"`
+	Files    map[string]bool   `desc:"files with changes pending FileFunc"`
+	First    time.Time         `desc:"time of the first pending change"`
+	Timer    *time.Timer       `desc:"timer for updating after FileWatchDelay"`
+	Mu       sync.Mutex        `desc:"mutex protecting pending changes"`
+}
+
+// Start starts watching the directories of given tree
+func (fw *FileWatcher) Start(ft *giv.FileTree, ftv *FileTreeView, exclude []string, ign *FileIgnore, fileFunc FileWatchFunc) error {
+	fw.Stop()
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	fw.Mu.Lock()
+	fw.Tree = ft
+	fw.View = ftv
+	fw.Exclude = exclude
+	fw.Ignore = ign
+	fw.FileFunc = fileFunc
+	fw.Watcher = w
+	fw.Dirs = make(map[string]bool)
+	fw.Pending = make(map[string]bool)
+	fw.Files = make(map[string]bool)
+	fw.Mu.Unlock()
+	fw.AddDir(string(ft.FPath))
+	go fw.Watch(w)
+	return nil
+}
+
+// Stop stops watching
+func (fw *FileWatcher) Stop() {
+	fw.Mu.Lock()
+	w := fw.Watcher
+	fw.Watcher = nil
+	if fw.Timer != nil {
+		fw.Timer.Stop()
+		fw.Timer = nil
+	}
+	fw.Mu.Unlock()
+	if w != nil {
+		w.Close() // outside of lock: waits for pending events to be taken
+	}
+}
+
+// Skip returns true if given path should not be watched or updated --
+// ignore files are never skipped, so FileFunc sees changes to them
+func (fw *FileWatcher) Skip(fpath string, isDir bool) bool {
+	rel, err := filepath.Rel(string(fw.Tree.FPath), fpath)
+	if err != nil || RelOutside(rel) {
+		return true
+	}
+	if rel == "." || (!isDir && IsIgnoreFile(fpath)) {
+		return false
+	}
+	return QuickOpenSkip(filepath.ToSlash(rel), isDir, fw.Exclude) || fw.Ignore.Ignored(fpath, isDir)
+}
+
+// AddDir adds given directory and all of its subdirectories to those
+// watched, up to FileWatchMaxDirs
+func (fw *FileWatcher) AddDir(dir string) {
+	filepath.Walk(dir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if fw.Skip(pth, true) {
+			return filepath.SkipDir
+		}
+		fw.Mu.Lock()
+		defer fw.Mu.Unlock()
+		if fw.Watcher == nil || len(fw.Dirs) >= FileWatchMaxDirs {
+			return filepath.SkipDir
+		}
+		if fw.Dirs[pth] {
+			return nil
+		}
+		if err := fw.Watcher.Add(pth); err != nil {
+			log.Println(err)
+			return filepath.SkipDir
+		}
+		fw.Dirs[pth] = true
+		return nil
+	})
+}
+
+// Watch handles the events of given watcher, until it is closed
+func (fw *FileWatcher) Watch(w *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			fw.Event(ev)
+		case _, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// Event records given event for the next update
+func (fw *FileWatcher) Event(ev fsnotify.Event) {
+	if ev.Op == fsnotify.Chmod {
+		return
+	}
+	isDir := false
+	if ev.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			isDir = true
+		}
+	}
+	if fw.Skip(ev.Name, isDir) {
+		return
+	}
+	if isDir {
+		fw.AddDir(ev.Name)
+	}
+	fw.Mu.Lock()
+	defer fw.Mu.Unlock()
+	if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		delete(fw.Dirs, ev.Name) // no longer watched, if a dir
+	}
+	if ev.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 {
+		fw.Pending[filepath.Dir(ev.Name)] = true
+	}
+	if !isDir {
+		fw.Files[ev.Name] = true
+	}
+	now := time.Now()
+	if fw.Timer == nil {
+		fw.First = now
+		fw.Timer = time.AfterFunc(FileWatchDelay, fw.Update)
+	} else if now.Sub(fw.First) < FileWatchMaxDelay {
+		fw.Timer.Reset(FileWatchDelay)
+	}
+}
+
+// DirNode returns the node for given directory in the tree, nil if not
+// there (e.g., within a closed directory)
+func (fw *FileWatcher) DirNode(dir string) *giv.FileNode {
+	rel, err := filepath.Rel(string(fw.Tree.FPath), dir)
+	if err != nil {
+		return nil
+	}
+	fn := &fw.Tree.FileNode
+	if rel == "." {
+		return fn
+	}
+	for _, nm := range strings.Split(filepath.ToSlash(rel), "/") {
+		k := fn.ChildByName(nm, 0)
+		if k == nil {
+			return nil
+		}
+		fn = k.Embed(giv.KiT_FileNode).(*giv.FileNode)
+	}
+	if !fn.IsDir() {
+		return nil
+	}
+	return fn
+}
+
+// Update updates the tree for the pending changes, and calls FileFunc
+// with the changed files
+func (fw *FileWatcher) Update() {
+	fw.Mu.Lock()
+	pdirs := fw.Pending
+	pfiles := fw.Files
+	fw.Pending = make(map[string]bool)
+	fw.Files = make(map[string]bool)
+	fw.Timer = nil
+	ft, ftv, ffun := fw.Tree, fw.View, fw.FileFunc
+	fw.Mu.Unlock()
+	if ft == nil {
+		return
+	}
+	if len(pdirs) > 0 {
+		updt := false
+		if ftv != nil {
+			updt = ftv.UpdateStart()
+			ftv.SetFullReRender()
+		}
+		ft.UpdtMu.Lock()
+		fw.UpdateDirs(ft, pdirs)
+		ft.UpdtMu.Unlock()
+		if ftv != nil {
+			ftv.UpdateEnd(updt)
+		}
+	}
+	if ffun != nil && len(pfiles) > 0 {
+		files := make([]string, 0, len(pfiles))
+		for f := range pfiles {
+			files = append(files, f)
+		}
+		sort.Strings(files)
+		ffun(files)
+	}
+}
+
+// UpdateDirs updates the nodes of given directories that are open in the
+// tree, parents first, reading the files of their repositories once
+func (fw *FileWatcher) UpdateDirs(ft *giv.FileTree, pdirs map[string]bool) {
+	dirs := make([]string, 0, len(pdirs))
+	for dir := range pdirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		di, dj := strings.Count(dirs[i], string(filepath.Separator)), strings.Count(dirs[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return dirs[i] < dirs[j]
+	})
+	var fns []*giv.FileNode
+	repos := make(map[*giv.FileNode]bool)
+	for _, dir := range dirs {
+		fn := fw.DirNode(dir)
+		if fn == nil || !(fn.This() == ft.This() || fn.IsOpen()) {
+			continue
+		}
+		fns = append(fns, fn)
+		if _, rnode := fn.Repo(); rnode != nil && !repos[rnode] {
+			repos[rnode] = true
+			rnode.UpdateRepoFiles()
+		}
+	}
+	for _, fn := range fns {
+		if fn.This() == nil || fn.IsDeleted() { // removed by the update of a parent
+			continue
+		}
+		if fn.InitFileInfo() != nil || fn.IsIrregular() {
+			continue
+		}
+		fn.UpdateDir()
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ints"
+)
+
+// FindWorkers is the number of files searched in parallel by a find in the
+// project files -- the number of CPUs by default
+var FindWorkers = runtime.NumCPU()
+
+// FindUpdateMSec is the number of milliseconds between the updates of the
+// results shown while a find in the project files is running
+var FindUpdateMSec = 200
+
+// SearchFiles searches given files for find string, or regexp re if
+// non-nil, with FindWorkers of them searched in parallel, calling found for
+// each file with matches (one at a time, in the order they are found).
+// Stops early if cancel is closed, returning false.
+func SearchFiles(files []*giv.FileNode, fb []byte, re *regexp.Regexp, ignoreCase bool, cancel <-chan struct{}, found func(res FileSearchResults)) bool {
+	nw := ints.MinInt(ints.MaxInt(FindWorkers, 1), len(files))
+	fc := make(chan *giv.FileNode)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(nw)
+	for i := 0; i < nw; i++ {
+		go func() {
+			defer wg.Done()
+			for sfn := range fc {
+				cnt, matches := FileNodeSearch(sfn, fb, re, ignoreCase)
+				if cnt > 0 {
+					mu.Lock()
+					found(FileSearchResults{sfn, cnt, matches})
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	done := true
+files:
+	for _, sfn := range files {
+		select {
+		case <-cancel:
+			done = false
+			break files
+		case fc <- sfn:
+		}
+	}
+	close(fc)
+	wg.Wait()
+	return done
+}
+
+// FindSearch is a find in the project files running in the background,
+// whose results are shown in the FindView as they are found
+type FindSearch struct {
+	Results []FileSearchResults `desc:"results found so far"`
+	NFiles  int                 `desc:"number of files to search"`
+	Shown   int                 `desc:"number of results shown"`
+	Cancel  chan struct{}       `desc:"closed to cancel the search"`
+	Done    chan struct{}       `desc:"closed when the search is done, or cancelled"`
+	Mu      sync.Mutex          `desc:"mutex protecting the results"`
+}
+
+// IsCancelled returns true if the search was cancelled
+func (fs *FindSearch) IsCancelled() bool {
+	select {
+	case <-fs.Cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// StartSearch starts searching given files for find string, or regexp re
+// if non-nil, as a background job, cancelling any search still running,
+// and showing the results as they are found
+func (fv *FindView) StartSearch(files []*giv.FileNode, find string, ignoreCase bool, re *regexp.Regexp) {
+	fv.CancelSearch()
+	fs := &FindSearch{NFiles: len(files), Cancel: make(chan struct{}), Done: make(chan struct{})}
+	fv.Search = fs
+	fv.ShowResults(nil)
+	fv.Gide.SetStatus(fmt.Sprintf("finding %q in %d files...", find, len(files)))
+	fv.Gide.Jobs().Run("find", JobHigh, func(jb *Job) {
+		fv.RunSearch(fs, files, []byte(find), ignoreCase, re)
+	})
+}
+
+// CancelSearch cancels the search running in the background, if any --
+// returns true if there was one
+func (fv *FindView) CancelSearch() bool {
+	fs := fv.Search
+	if fs == nil {
+		return false
+	}
+	fv.Search = nil
+	select {
+	case <-fs.Done:
+		return false
+	default:
+	}
+	close(fs.Cancel)
+	return true
+}
+
+// RunSearch runs given search, updating the results shown every
+// FindUpdateMSec while it runs, and once done
+func (fv *FindView) RunSearch(fs *FindSearch, files []*giv.FileNode, fb []byte, ignoreCase bool, re *regexp.Regexp) {
+	defer close(fs.Done)
+	st := time.Now()
+	stop := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(time.Duration(FindUpdateMSec) * time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				fv.UpdateSearch(fs)
+			}
+		}
+	}()
+	done := SearchFiles(files, fb, re, ignoreCase, fs.Cancel, func(res FileSearchResults) {
+		fs.Mu.Lock()
+		fs.Results = append(fs.Results, res)
+		fs.Mu.Unlock()
+	})
+	close(stop)
+	if !done {
+		return
+	}
+	fv.UpdateSearch(fs)
+	fs.Mu.Lock()
+	nres := len(fs.Results)
+	fs.Mu.Unlock()
+	fv.Gide.SetStatus(fmt.Sprintf("found %q in %d of %d files, in %v", string(fb), nres, fs.NFiles, time.Since(st).Round(time.Millisecond)))
+}
+
+// UpdateSearch shows the results found so far by given search, unless it
+// was cancelled -- the first ones found are opened, as in ShowResults, and
+// the later ones are added keeping the cursor in the results
+func (fv *FindView) UpdateSearch(fs *FindSearch) {
+	fs.Mu.Lock()
+	if fs.IsCancelled() || len(fs.Results) == fs.Shown || fv.IsDestroyed() {
+		fs.Mu.Unlock()
+		return
+	}
+	res := make([]FileSearchResults, len(fs.Results))
+	copy(res, fs.Results)
+	first := fs.Shown == 0
+	fs.Shown = len(res)
+	fs.Mu.Unlock()
+	SortFileSearchResults(res)
+	if first {
+		fv.ShowResults(res)
+		return
+	}
+	fv.Results = res
+	fv.ShowFinds()
+}
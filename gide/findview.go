@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"html"
+	"io/ioutil"
 	"net/url"
 	"reflect"
 	"regexp"
@@ -15,8 +16,12 @@ import (
 	"time"
 
 	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/ints"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/pi/filecat"
@@ -39,6 +44,9 @@ const (
 	// FindLocNotTop finds in all open folders *except* the top-level folder
 	FindLocNotTop
 
+	// FindLocOpen only finds in the files that are open for editing
+	FindLocOpen
+
 	// FindLocN is the number of find locations (scopes)
 	FindLocN
 )
@@ -58,18 +66,52 @@ type FindParams struct {
 	Regexp     bool                `desc:"use regexp regular expression search and replace"`
 	Langs      []filecat.Supported `desc:"languages for files to search"`
 	Loc        FindLoc             `desc:"locations to search in"`
+	Include    string              `desc:"file name globs to restrict search to, separated by spaces or commas (e.g., *.go *.md) -- matched against the file name and its path relative to the project root -- all files when empty"`
+	Exclude    string              `desc:"file name globs to exclude from search, separated by spaces or commas (e.g., vendor *_test.go) -- also excludes all files within matching directories"`
+	Context    int                 `desc:"number of lines of context to show before and after each match in the results"`
+	Recursive  bool                `desc:"for the dir location, also find in the open subdirectories of the directory of the current active file"`
 	FindHist   []string            `desc:"history of finds"`
 	ReplHist   []string            `desc:"history of replaces"`
+	FindOpts   map[string]FindOpts `desc:"options used for each find in FindHist, restored when it is selected from the history"`
+	Saved      []SavedSearch       `desc:"named searches saved for re-running from the Saved Searches panel"`
+}
+
+// FindOpts are the options used for a find, saved in the find history
+type FindOpts struct {
+	IgnoreCase bool   `desc:"ignore case"`
+	Regexp     bool   `desc:"use regexp regular expression search and replace"`
+	Include    string `desc:"file name globs to restrict search to"`
+	Exclude    string `desc:"file name globs to exclude from search"`
+}
+
+// Opts returns the current options
+func (fp *FindParams) Opts() FindOpts {
+	return FindOpts{IgnoreCase: fp.IgnoreCase, Regexp: fp.Regexp, Include: fp.Include, Exclude: fp.Exclude}
+}
+
+// SetOpts sets the current options
+func (fp *FindParams) SetOpts(fo FindOpts) {
+	fp.IgnoreCase = fo.IgnoreCase
+	fp.Regexp = fo.Regexp
+	fp.Include = fo.Include
+	fp.Exclude = fo.Exclude
 }
 
 // FindView is a find / replace widget that displays results in a TextView
 // and has a toolbar for controlling find / replace process.
 type FindView struct {
 	gi.Layout
-	Gide   Gide           `json:"-" xml:"-" desc:"parent gide project"`
-	LangVV giv.ValueView  `desc:"langs value view"`
-	Time   time.Time      `desc:"time of last find"`
-	Re     *regexp.Regexp `desc:"compiled regexp"`
+	Gide       Gide                    `json:"-" xml:"-" desc:"parent gide project"`
+	LangVV     giv.ValueView           `desc:"langs value view"`
+	Time       time.Time               `desc:"time of last find"`
+	Re         *regexp.Regexp          `desc:"compiled regexp"`
+	Results    []FileSearchResults     `json:"-" xml:"-" desc:"results of the last find"`
+	Skip       map[string]map[int]bool `json:"-" xml:"-" desc:"results unchecked in the replace preview, which are skipped in Apply, by file path and index of match"`
+	Previewing bool                    `json:"-" xml:"-" desc:"true if the replace preview is being shown"`
+	Collapsed  map[string]bool         `json:"-" xml:"-" desc:"files whose results are collapsed to just their header, by file path"`
+	FindHidx   int                     `json:"-" xml:"-" desc:"index in FindHist of the find shown by moving up / down in the find field -- -1 if none"`
+	ReplHidx   int                     `json:"-" xml:"-" desc:"index in ReplHist of the replace shown by moving up / down in the replace field -- -1 if none"`
+	Search     *FindSearch             `json:"-" xml:"-" desc:"the find in the project files running in the background, if any"`
 }
 
 var KiT_FindView = kit.Types.AddType(&FindView{}, FindViewProps)
@@ -79,57 +121,295 @@ func (fv *FindView) Params() *FindParams {
 	return &fv.Gide.ProjPrefs().Find
 }
 
-// ShowResults shows the results in the buffer
+// ShowResults shows the results in the buffer, and opens the first one
 func (fv *FindView) ShowResults(res []FileSearchResults) {
+	fv.Results = res
+	fv.Skip = nil
+	fv.Previewing = false
+	fv.Collapsed = nil
+	fv.ShowFinds()
+	ftv := fv.TextView()
+	ftv.CursorStartDoc()
+	if fv.CursorFindLink(true, false) { // no wrap
+		ftv.OpenLinkAt(ftv.CursorPos)
+	}
+}
+
+// ShowFinds shows the current Results in the buffer, grouped under a header
+// for each file that collapses it on clicking, with Context lines of
+// context around each match, and a link for dismissing each match
+func (fv *FindView) ShowFinds() {
 	ftv := fv.TextView()
 	fbuf := ftv.Buf
+	cpos := ftv.CursorPos
+	nctx := ints.MaxInt(fv.Params().Context, 0)
 	outlns := make([][]byte, 0, 100)
 	outmus := make([][]byte, 0, 100) // markups
-	for _, fs := range res {
+	for _, fs := range fv.Results {
 		fp := fs.Node.Info.Path
 		fn := fs.Node.MyRelPath()
 		fbStLn := len(outlns) // find buf start ln
+		fold := "[-]"
+		if fv.Collapsed[fp] {
+			fold = "[+]"
+		}
 		lstr := fmt.Sprintf(`%v: %v`, fn, fs.Count)
-		outlns = append(outlns, []byte(lstr))
-		mstr := fmt.Sprintf(`<b>%v</b>`, lstr)
+		outlns = append(outlns, []byte(fold+" "+lstr))
+		mstr := fmt.Sprintf(`<a href="findfold:///%v">%v</a> <b>%v</b>`, fp, fold, lstr)
 		outmus = append(outmus, []byte(mstr))
-		for _, mt := range fs.Matches {
+		if fv.Collapsed[fp] {
+			outlns = append(outlns, []byte(""))
+			outmus = append(outmus, []byte(""))
+			continue
+		}
+		var lines [][]rune
+		if nctx > 0 {
+			lines = FindFileLines(fs.Node)
+		}
+		last := -1 // last line of file shown
+		for i, mt := range fs.Matches {
+			mln := mt.Reg.Start.Ln
+			if nctx > 0 {
+				st := ints.MaxInt(mln-nctx, last+1)
+				if last >= 0 && st > last+1 {
+					outlns = append(outlns, []byte("\t--"))
+					outmus = append(outmus, []byte("\t--"))
+				}
+				for cl := st; cl < mln && cl < len(lines); cl++ {
+					outlns, outmus = FindContextLine(outlns, outmus, lines, cl)
+				}
+			}
 			txt := bytes.TrimSpace(mt.Text)
 			txt = append([]byte{'\t'}, txt...)
-			ln := mt.Reg.Start.Ln + 1
+			ln := mln + 1
 			ch := mt.Reg.Start.Ch + 1
 			ech := mt.Reg.End.Ch + 1
 			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
 			nomu := bytes.Replace(txt, []byte("<mark>"), nil, -1)
 			nomu = bytes.Replace(nomu, []byte("</mark>"), nil, -1)
 			nomus := html.EscapeString(string(nomu))
-			lstr = fmt.Sprintf(`%v: %s`, fnstr, nomus) // note: has tab embedded at start of lstr
+			lstr = fmt.Sprintf(`%v: %s [x]`, fnstr, nomus) // note: has tab embedded at start of lstr
 
 			outlns = append(outlns, []byte(lstr))
-			mstr = fmt.Sprintf(`	<a href="find:///%v#R%vN%vL%vC%v-L%vC%v">%v</a>: %s`, fp, fbStLn, fs.Count, ln, ch, ln, ech, fnstr, txt)
+			mstr = fmt.Sprintf(`	<a href="find:///%v#R%vN%vL%vC%v-L%vC%v">%v</a>: %s <a href="finddel:///%v#M%d">[x]</a>`, fp, fbStLn, fs.Count, ln, ch, ln, ech, fnstr, txt, fp, i)
 			outmus = append(outmus, []byte(mstr))
+			last = ints.MaxInt(last, mln)
+			if nctx > 0 {
+				ed := mln + nctx
+				if i+1 < len(fs.Matches) {
+					ed = ints.MinInt(ed, fs.Matches[i+1].Reg.Start.Ln-1)
+				}
+				for cl := last + 1; cl <= ed && cl < len(lines); cl++ {
+					outlns, outmus = FindContextLine(outlns, outmus, lines, cl)
+					last = cl
+				}
+			}
 		}
 		outlns = append(outlns, []byte(""))
 		outmus = append(outmus, []byte(""))
 	}
 	ltxt := bytes.Join(outlns, []byte("\n"))
 	mtxt := bytes.Join(outmus, []byte("\n"))
+	fbuf.New(0)
 	fbuf.SetInactive(true)
 	fbuf.AppendTextMarkup(ltxt, mtxt, giv.EditSignal)
-	ftv.CursorStartDoc()
-	ok := ftv.CursorNextLink(false) // no wrap
-	if ok {
-		ftv.OpenLinkAt(ftv.CursorPos)
+	ftv.SetCursorShow(cpos)
+}
+
+// FindContextLine appends given line of context from file lines to the
+// results lines and markup
+func FindContextLine(outlns, outmus [][]byte, lines [][]rune, ln int) ([][]byte, [][]byte) {
+	txt := strings.TrimSpace(string(lines[ln]))
+	outlns = append(outlns, []byte(fmt.Sprintf("\t%d- %v", ln+1, txt)))
+	outmus = append(outmus, []byte(fmt.Sprintf("\t<i>%d-</i> %v", ln+1, html.EscapeString(txt))))
+	return outlns, outmus
+}
+
+// FindLinkAt returns the find:/// link to a match at given position in the
+// results, false if none -- other links (e.g., for collapsing files) are
+// not returned
+func (fv *FindView) FindLinkAt(pos lex.Pos) (*girl.TextLink, bool) {
+	tl, ok := fv.TextView().LinkAt(pos)
+	if !ok || !strings.HasPrefix(tl.URL, "find:///") {
+		return nil, false
+	}
+	return tl, true
+}
+
+// CursorFindLink moves the cursor to the next (or previous) find:/// link
+// to a match in the results, skipping other links -- wraparound wraps
+// around the results -- returns true if found
+func (fv *FindView) CursorFindLink(next, wraparound bool) bool {
+	ftv := fv.TextView()
+	stpos := ftv.CursorPos
+	for i := 0; i <= 2*ftv.NLines; i++ { // at most 2 other links per line
+		var ok bool
+		if next {
+			ok = ftv.CursorNextLink(wraparound)
+		} else {
+			ok = ftv.CursorPrevLink(wraparound)
+		}
+		if !ok {
+			return false
+		}
+		if _, ok := fv.FindLinkAt(ftv.CursorPos); ok {
+			return true
+		}
+		if ftv.CursorPos == stpos {
+			return false
+		}
+	}
+	return false
+}
+
+// LinkURL handles the links in the results other than those to matches:
+// findfold:/// toggles collapsing a file, finddel:/// dismisses a match,
+// and findsel:/// toggles skipping replacements in the replace preview
+func (fv *FindView) LinkURL(ur string) bool {
+	switch {
+	case strings.HasPrefix(ur, "findsel:///"):
+		return fv.ToggleSkipURL(ur)
+	case strings.HasPrefix(ur, "findfold:///"):
+		return fv.ToggleCollapseURL(ur)
+	case strings.HasPrefix(ur, "finddel:///"):
+		return fv.DismissURL(ur)
+	}
+	return false
+}
+
+// ResultsForURL returns the index in Results of the file for given url,
+// and the match index in its fragment (M<i>), -1 if none
+func (fv *FindView) ResultsForURL(ur string) (fidx, midx int, ok bool) {
+	up, err := url.Parse(ur)
+	if err != nil {
+		return -1, -1, false
+	}
+	fpath := up.Path[1:] // has double //
+	midx = -1
+	if up.Fragment != "" {
+		fmt.Sscanf(up.Fragment, "M%d", &midx)
+	}
+	for i := range fv.Results {
+		if fv.Results[i].Node.Info.Path == fpath {
+			return i, midx, true
+		}
+	}
+	return -1, midx, false
+}
+
+// ToggleCollapseURL toggles whether the results for the file of given
+// findfold:/// url are collapsed to just its header
+func (fv *FindView) ToggleCollapseURL(ur string) bool {
+	fidx, _, ok := fv.ResultsForURL(ur)
+	if !ok {
+		return false
+	}
+	fpath := fv.Results[fidx].Node.Info.Path
+	if fv.Collapsed == nil {
+		fv.Collapsed = make(map[string]bool)
+	}
+	fv.Collapsed[fpath] = !fv.Collapsed[fpath]
+	fv.ShowFinds()
+	return true
+}
+
+// SetCollapseAll collapses or expands the results for all files
+func (fv *FindView) SetCollapseAll(collapse bool) {
+	if fv.Previewing {
+		return
+	}
+	fv.Collapsed = make(map[string]bool)
+	if collapse {
+		for _, fs := range fv.Results {
+			fv.Collapsed[fs.Node.Info.Path] = true
+		}
 	}
+	fv.ShowFinds()
 }
 
-// SaveFindString saves the given find string to the find params history and current str
+// DismissURL removes the match for given finddel:/// url from the results,
+// so it is no longer shown or replaced
+func (fv *FindView) DismissURL(ur string) bool {
+	fidx, midx, ok := fv.ResultsForURL(ur)
+	if !ok {
+		return false
+	}
+	fs := &fv.Results[fidx]
+	if midx < 0 || midx >= len(fs.Matches) {
+		return false
+	}
+	fs.Matches = append(fs.Matches[:midx:midx], fs.Matches[midx+1:]...)
+	fs.Count--
+	if len(fs.Matches) == 0 {
+		fv.Results = append(fv.Results[:fidx:fidx], fv.Results[fidx+1:]...)
+	}
+	fv.ShowFinds()
+	return true
+}
+
+// SaveFindString saves the given find string to the find params history and
+// current str, along with the current options
 func (fv *FindView) SaveFindString(find string) {
-	fv.Params().Find = find
-	gi.StringsInsertFirstUnique(&fv.Params().FindHist, find, gi.Prefs.Params.SavedPathsMax)
+	fp := fv.Params()
+	fp.Find = find
+	gi.StringsInsertFirstUnique(&fp.FindHist, find, gi.Prefs.Params.SavedPathsMax)
+	fo := make(map[string]FindOpts, len(fp.FindHist))
+	for _, fh := range fp.FindHist { // only keep those still in history
+		if o, has := fp.FindOpts[fh]; has {
+			fo[fh] = o
+		}
+	}
+	fo[find] = fp.Opts()
+	fp.FindOpts = fo
+	fv.FindHidx = -1
 	ftc := fv.FindText()
 	if ftc != nil {
-		ftc.ItemsFromStringList(fv.Params().FindHist, true, 0)
+		ftc.ItemsFromStringList(fp.FindHist, true, 0)
+	}
+}
+
+// RestoreFindOpts restores the options saved in the history for given find
+// string, updating the toolbar
+func (fv *FindView) RestoreFindOpts(find string) {
+	fp := fv.Params()
+	fo, has := fp.FindOpts[find]
+	if !has {
+		return
+	}
+	fp.SetOpts(fo)
+	fv.IgnoreBox().SetChecked(fp.IgnoreCase)
+	fv.RegexpBox().SetChecked(fp.Regexp)
+	fv.IncludeText().SetText(fp.Include)
+	fv.ExcludeText().SetText(fp.Exclude)
+}
+
+// FindHistStep moves given number of steps back (positive = older) in the
+// find history, showing that find string and restoring its options
+func (fv *FindView) FindHistStep(steps int) {
+	fp := fv.Params()
+	if len(fp.FindHist) == 0 {
+		return
+	}
+	fv.FindHidx = ints.MinInt(ints.MaxInt(fv.FindHidx+steps, 0), len(fp.FindHist)-1)
+	find := fp.FindHist[fv.FindHidx]
+	fp.Find = find
+	if tf, ok := fv.FindText().TextField(); ok {
+		tf.SetText(find)
+	}
+	fv.RestoreFindOpts(find)
+}
+
+// ReplHistStep moves given number of steps back (positive = older) in the
+// replace history, showing that replace string
+func (fv *FindView) ReplHistStep(steps int) {
+	fp := fv.Params()
+	if len(fp.ReplHist) == 0 {
+		return
+	}
+	fv.ReplHidx = ints.MinInt(ints.MaxInt(fv.ReplHidx+steps, 0), len(fp.ReplHist)-1)
+	fp.Replace = fp.ReplHist[fv.ReplHidx]
+	if tf, ok := fv.ReplText().TextField(); ok {
+		tf.SetText(fp.Replace)
 	}
 }
 
@@ -137,6 +417,7 @@ func (fv *FindView) SaveFindString(find string) {
 func (fv *FindView) SaveReplString(repl string) {
 	fv.Params().Replace = repl
 	gi.StringsInsertFirstUnique(&fv.Params().ReplHist, repl, gi.Prefs.Params.SavedPathsMax)
+	fv.ReplHidx = -1
 	rtc := fv.ReplText()
 	if rtc != nil {
 		rtc.ItemsFromStringList(fv.Params().ReplHist, true, 0)
@@ -177,25 +458,30 @@ func (fv *FindView) ReplaceAction() bool {
 	fv.SaveReplString(fp.Replace)
 	gi.StringsInsertFirstUnique(&fp.ReplHist, fp.Replace, gi.Prefs.Params.SavedPathsMax)
 
+	if fv.Previewing {
+		fv.ExitPreview()
+	}
+
 	ftv := fv.TextView()
-	tl, ok := ftv.OpenLinkAt(ftv.CursorPos)
+	tl, ok := fv.FindLinkAt(ftv.CursorPos)
 	if !ok {
-		ok = ftv.CursorNextLink(false) // no wrap
+		ok = fv.CursorFindLink(true, false) // no wrap
 		if !ok {
 			return false
 		}
-		tl, ok = ftv.OpenLinkAt(ftv.CursorPos)
+		tl, ok = fv.FindLinkAt(ftv.CursorPos)
 		if !ok {
 			return false
 		}
 	}
+	ftv.OpenLinkAt(ftv.CursorPos)
 	ge := fv.Gide
 	tv, reg, _, _, ok := ge.ParseOpenFindURL(tl.URL, ftv)
 	if !ok {
 		return false
 	}
 	if reg.IsNil() {
-		ok = ftv.CursorNextLink(false) // no wrap
+		ok = fv.CursorFindLink(true, false) // no wrap
 		if !ok {
 			return false
 		}
@@ -214,7 +500,7 @@ func (fv *FindView) ReplaceAction() bool {
 		if fp.Regexp {
 			rg := tv.Buf.Region(reg.Start, reg.End)
 			b := rg.ToBytes()
-			rb := fv.Re.ReplaceAll(b, []byte(fp.Replace))
+			rb := fv.ReplaceBytes(b)
 			tv.Buf.ReplaceText(reg.Start, reg.End, reg.Start, string(rb), giv.EditSignal, false)
 		} else {
 			// MatchCase only if doing IgnoreCase
@@ -231,7 +517,7 @@ func (fv *FindView) ReplaceAction() bool {
 
 	tv.ClearHighlights()
 
-	ok = ftv.CursorNextLink(false) // no wrap
+	ok = fv.CursorFindLink(true, false) // no wrap
 	if ok {
 		ftv.OpenLinkAt(ftv.CursorPos) // move to next
 	}
@@ -255,7 +541,7 @@ func (fv *FindView) CompileRegexp() bool {
 		return true
 	}
 	var err error
-	fv.Re, err = regexp.Compile(fp.Find)
+	fv.Re, err = FindRegexp(fp.Find, fp.IgnoreCase)
 	if err != nil {
 		gi.PromptDialog(nil, gi.DlgOpts{Title: "Regexp is Invalid", Prompt: fmt.Sprintf("The regular expression was invalid: %v", err)}, gi.AddOk, gi.NoCancel, nil, nil)
 		return false
@@ -276,10 +562,224 @@ func (fv *FindView) ReplaceAll() {
 	}
 }
 
+// ReplaceBytes returns the replacement for given source text matched by
+// the find string, expanding ${n} submatches when using regexp
+func (fv *FindView) ReplaceBytes(src []byte) []byte {
+	fp := fv.Params()
+	if fp.Regexp && fv.Re != nil {
+		return fv.Re.ReplaceAll(src, []byte(fp.Replace))
+	}
+	return []byte(fp.Replace)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Replace Preview
+
+// ReplacePreviewAction shows a preview of all the replacements for the
+// current find results, each of which can be checked / unchecked by
+// clicking on its [x] box before doing ApplyReplace
+func (fv *FindView) ReplacePreviewAction() {
+	if !fv.CompileRegexp() {
+		return
+	}
+	fv.SaveReplString(fv.Params().Replace)
+	fv.Previewing = true
+	fv.ShowPreview()
+}
+
+// ExitPreview returns to showing the find results
+func (fv *FindView) ExitPreview() {
+	fv.Previewing = false
+	fv.ShowFinds()
+}
+
+// FindFileLines returns the current lines of given file, from its buffer if
+// open, else from the file
+func FindFileLines(fn *giv.FileNode) [][]rune {
+	if fn.IsOpen() && fn.Buf != nil {
+		return fn.Buf.Lines
+	}
+	b, err := ioutil.ReadFile(string(fn.FPath))
+	if err != nil {
+		return nil
+	}
+	bls := bytes.Split(b, []byte("\n"))
+	lns := make([][]rune, len(bls))
+	for i, bl := range bls {
+		lns[i] = []rune(string(bl))
+	}
+	return lns
+}
+
+// FindMatchSrc returns the text before, within and after given match region
+// on its line in given lines
+func FindMatchSrc(lines [][]rune, reg textbuf.Region) (pre, src, post string) {
+	if reg.Start.Ln >= len(lines) {
+		return
+	}
+	ln := lines[reg.Start.Ln]
+	st := ints.MinInt(reg.Start.Ch, len(ln))
+	ed := len(ln)
+	if reg.End.Ln == reg.Start.Ln {
+		ed = ints.MaxInt(st, ints.MinInt(reg.End.Ch, len(ln)))
+	}
+	pre = strings.TrimLeft(string(ln[:st]), " \t")
+	return pre, string(ln[st:ed]), strings.TrimRight(string(ln[ed:]), " \t\r")
+}
+
+// FindCheckBox returns the check box text for given checked state: [x] if
+// checked, [ ] if not, and [-] if partially checked
+func FindCheckBox(checked, partial bool) string {
+	switch {
+	case partial:
+		return "[-]"
+	case checked:
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// ShowPreview shows the preview of all replacements in the buffer
+func (fv *FindView) ShowPreview() {
+	ftv := fv.TextView()
+	fbuf := ftv.Buf
+	cpos := ftv.CursorPos
+	outlns := make([][]byte, 0, 100)
+	outmus := make([][]byte, 0, 100) // markups
+	for _, fs := range fv.Results {
+		fp := fs.Node.Info.Path
+		fn := fs.Node.MyRelPath()
+		sk := fv.Skip[fp]
+		lines := FindFileLines(fs.Node)
+		fbStLn := len(outlns) // find buf start ln
+		cb := FindCheckBox(len(sk) == 0, len(sk) > 0 && len(sk) < len(fs.Matches))
+		outlns = append(outlns, []byte(fmt.Sprintf(`%v %v: %v`, cb, fn, fs.Count)))
+		outmus = append(outmus, []byte(fmt.Sprintf(`<a href="findsel:///%v">%v</a> <b>%v: %v</b>`, fp, cb, fn, fs.Count)))
+		for i, mt := range fs.Matches {
+			cb = FindCheckBox(!sk[i], false)
+			ln := mt.Reg.Start.Ln + 1
+			ch := mt.Reg.Start.Ch + 1
+			ech := mt.Reg.End.Ch + 1
+			fnstr := fmt.Sprintf("%v:%d:%d", fn, ln, ch)
+			pre, src, post := FindMatchSrc(lines, mt.Reg)
+			rep := string(fv.ReplaceBytes([]byte(src)))
+			outlns = append(outlns, []byte(fmt.Sprintf("\t%v %v: %v%v%v%v", cb, fnstr, pre, src, rep, post)))
+			outmus = append(outmus, []byte(fmt.Sprintf(`	<a href="findsel:///%v#M%d">%v</a> <a href="find:///%v#R%vN%vL%vC%v-L%vC%v">%v</a>: %v<s>%v</s><mark>%v</mark>%v`, fp, i, cb, fp, fbStLn, fs.Count, ln, ch, ln, ech, fnstr, html.EscapeString(pre), html.EscapeString(src), html.EscapeString(rep), html.EscapeString(post))))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+	}
+	ltxt := bytes.Join(outlns, []byte("\n"))
+	mtxt := bytes.Join(outmus, []byte("\n"))
+	fbuf.New(0)
+	fbuf.SetInactive(true)
+	fbuf.AppendTextMarkup(ltxt, mtxt, giv.EditSignal)
+	ftv.SetCursorShow(cpos)
+}
+
+// ToggleSkipURL toggles whether the replacement(s) for given findsel:///
+// url in the preview are skipped -- the url is for a single match, or all of
+// the matches in a file if it has no fragment
+func (fv *FindView) ToggleSkipURL(ur string) bool {
+	up, err := url.Parse(ur)
+	if err != nil {
+		return false
+	}
+	fpath := up.Path[1:] // has double //
+	var fs *FileSearchResults
+	for i := range fv.Results {
+		if fv.Results[i].Node.Info.Path == fpath {
+			fs = &fv.Results[i]
+			break
+		}
+	}
+	if fs == nil {
+		return false
+	}
+	if fv.Skip == nil {
+		fv.Skip = make(map[string]map[int]bool)
+	}
+	sk := fv.Skip[fpath]
+	if sk == nil {
+		sk = make(map[int]bool)
+		fv.Skip[fpath] = sk
+	}
+	if up.Fragment == "" {
+		if len(sk) > 0 {
+			delete(fv.Skip, fpath)
+		} else {
+			for i := range fs.Matches {
+				sk[i] = true
+			}
+		}
+	} else {
+		var mi int
+		fmt.Sscanf(up.Fragment, "M%d", &mi)
+		if sk[mi] {
+			delete(sk, mi)
+		} else {
+			sk[mi] = true
+		}
+	}
+	fv.ShowPreview()
+	return true
+}
+
+// ApplyReplace does all of the replacements that are checked in the
+// preview -- the files are changed but not saved
+func (fv *FindView) ApplyReplace() {
+	if !fv.Previewing || !fv.CheckValidRegexp() {
+		return
+	}
+	wupdt := fv.TopUpdateStart()
+	defer fv.TopUpdateEnd(wupdt)
+
+	fp := fv.Params()
+	ge := fv.Gide
+	nrep := 0
+	nfile := 0
+	for _, fs := range fv.Results {
+		fpath := fs.Node.Info.Path
+		sk := fv.Skip[fpath]
+		if len(sk) == len(fs.Matches) {
+			continue
+		}
+		tb := ge.TextBufForFile(fpath, false)
+		if tb == nil {
+			continue
+		}
+		for i := len(fs.Matches) - 1; i >= 0; i-- { // in reverse so earlier are not moved
+			if sk[i] {
+				continue
+			}
+			reg := fs.Matches[i].Reg
+			reg.Time.SetTime(fv.Time)
+			reg = tb.AdjustReg(reg)
+			if reg.IsNil() {
+				continue
+			}
+			if fp.Regexp {
+				b := tb.Region(reg.Start, reg.End).ToBytes()
+				tb.ReplaceText(reg.Start, reg.End, reg.Start, string(fv.ReplaceBytes(b)), giv.EditSignal, false)
+			} else {
+				// MatchCase only if doing IgnoreCase
+				tb.ReplaceText(reg.Start, reg.End, reg.Start, fp.Replace, giv.EditSignal, fp.IgnoreCase)
+			}
+			nrep++
+		}
+		nfile++
+	}
+	fv.Results = nil
+	fv.Skip = nil
+	fv.Previewing = false
+	fv.TextView().Buf.New(0)
+	ge.SetStatus(fmt.Sprintf("Replaced %v matches in %v files -- changed files are not yet saved", nrep, nfile))
+}
+
 // NextFind shows next find result
 func (fv *FindView) NextFind() {
 	ftv := fv.TextView()
-	ok := ftv.CursorNextLink(true) // wrap
+	ok := fv.CursorFindLink(true, true) // wrap
 	if ok {
 		ftv.OpenLinkAt(ftv.CursorPos)
 	}
@@ -288,7 +788,7 @@ func (fv *FindView) NextFind() {
 // PrevFind shows previous find result
 func (fv *FindView) PrevFind() {
 	ftv := fv.TextView()
-	ok := ftv.CursorPrevLink(true) // wrap
+	ok := fv.CursorFindLink(false, true) // wrap
 	if ok {
 		ftv.OpenLinkAt(ftv.CursorPos)
 	}
@@ -315,16 +815,19 @@ func (fv *FindView) OpenFindURL(ur string, ftv *giv.TextView) bool {
 
 // HighlightFinds highlights all the find results in ftv buffer
 func (fv *FindView) HighlightFinds(tv, ftv *giv.TextView, fbStLn, fCount int, find string) {
-	lnka := []byte(`<a href="`)
-	lnkasz := len(lnka)
+	lnka := []byte(`<a href="find:`)
+	lnkasz := len(`<a href="`)
 
 	fb := ftv.Buf
 
 	if len(tv.Highlights) != fCount { // highlight
-		hi := make([]textbuf.Region, fCount)
-		for i := 0; i < fCount; i++ {
-			fln := fbStLn + 1 + i
+		hi := make([]textbuf.Region, 0, fCount)
+		// context lines are interspersed, and results stop at the next file header
+		for fln := fbStLn + 1; fln < len(fb.Markup) && len(hi) < fCount; fln++ {
 			ltxt := fb.Markup[fln]
+			if bytes.HasPrefix(ltxt, []byte(`<a href="findfold:`)) || bytes.HasPrefix(ltxt, []byte(`<a href="findsel:`)) {
+				break
+			}
 			fpi := bytes.Index(ltxt, lnka)
 			if fpi < 0 {
 				continue
@@ -340,7 +843,7 @@ func (fv *FindView) HighlightFinds(tv, ftv *giv.TextView, fbStLn, fCount int, fi
 			lidx := strings.Index(iup.Fragment, "L")
 			ireg.FromString(iup.Fragment[lidx:])
 			ireg.Time.SetTime(fv.Time)
-			hi[i] = ireg
+			hi = append(hi, ireg)
 		}
 		tv.Highlights = hi
 	}
@@ -352,6 +855,8 @@ func (fv *FindView) HighlightFinds(tv, ftv *giv.TextView, fbStLn, fCount int, fi
 // Config configures the view
 func (fv *FindView) Config(ge Gide) {
 	fv.Gide = ge
+	fv.FindHidx = -1
+	fv.ReplHidx = -1
 	fv.Lay = gi.LayoutVert
 	fv.SetProp("spacing", gi.StdDialogVSpaceUnits)
 	config := kit.TypeAndNameList{}
@@ -374,8 +879,12 @@ func (fv *FindView) Config(ge Gide) {
 	ib.SetChecked(fp.IgnoreCase)
 	rb := fv.RegexpBox()
 	rb.SetChecked(fp.Regexp)
+	fv.IncludeText().SetText(fp.Include)
+	fv.ExcludeText().SetText(fp.Exclude)
 	cf := fv.LocCombo()
 	cf.SetCurIndex(int(fp.Loc))
+	fv.RecursiveBox().SetChecked(fp.Recursive)
+	fv.ContextSpin().SetValue(float32(fp.Context))
 	tvly := fv.TextViewLay()
 	ConfigOutputTextView(tvly)
 	if mods {
@@ -385,6 +894,12 @@ func (fv *FindView) Config(ge Gide) {
 	fv.UpdateEnd(updt)
 }
 
+// Destroy cancels the search running in the background when the tab is closed
+func (fv *FindView) Destroy() {
+	fv.CancelSearch()
+	fv.Layout.Destroy()
+}
+
 // FindBar returns the find toolbar
 func (fv *FindView) FindBar() *gi.ToolBar {
 	return fv.ChildByName("findbar", 0).(*gi.ToolBar)
@@ -425,6 +940,26 @@ func (fv *FindView) CurDirBox() *gi.CheckBox {
 	return fv.ReplBar().ChildByName("cur-dir", 6).(*gi.CheckBox)
 }
 
+// RecursiveBox returns the dir recursive checkbox in toolbar
+func (fv *FindView) RecursiveBox() *gi.CheckBox {
+	return fv.ReplBar().ChildByName("recursive", 7).(*gi.CheckBox)
+}
+
+// ContextSpin returns the context lines spinbox in toolbar
+func (fv *FindView) ContextSpin() *gi.SpinBox {
+	return fv.ReplBar().ChildByName("context", 9).(*gi.SpinBox)
+}
+
+// IncludeText returns the include globs textfield in toolbar
+func (fv *FindView) IncludeText() *gi.TextField {
+	return fv.FindBar().ChildByName("include", 7).(*gi.TextField)
+}
+
+// ExcludeText returns the exclude globs textfield in toolbar
+func (fv *FindView) ExcludeText() *gi.TextField {
+	return fv.FindBar().ChildByName("exclude", 9).(*gi.TextField)
+}
+
 // FindNextAct returns the find next action in toolbar -- selected first
 func (fv *FindView) FindNextAct() *gi.Action {
 	return fv.FindBar().ChildByName("next", 3).(*gi.Action)
@@ -437,7 +972,7 @@ func (fv *FindView) TextViewLay() *gi.Layout {
 
 // TextView returns the find results TextView
 func (fv *FindView) TextView() *giv.TextView {
-	return fv.TextViewLay().Child(0).Embed(giv.KiT_TextView).(*giv.TextView)
+	return fv.TextViewLay().ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
 }
 
 // ConfigToolbar adds toolbar.
@@ -460,7 +995,7 @@ func (fv *FindView) ConfigToolbar() {
 	finds := fb.AddNewChild(gi.KiT_ComboBox, "find-str").(*gi.ComboBox)
 	finds.Editable = true
 	finds.SetStretchMaxWidth()
-	finds.Tooltip = "String to find -- hit enter or tab to update search -- click for history"
+	finds.Tooltip = "String to find -- hit enter or tab to update search -- click or use up / down arrows for history, which restores the options used for each find"
 	finds.ConfigParts()
 	finds.ItemsFromStringList(fv.Params().FindHist, true, 0)
 	ftf, _ := finds.TextField()
@@ -468,6 +1003,7 @@ func (fv *FindView) ConfigToolbar() {
 		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
 		cb := send.(*gi.ComboBox)
 		fvv.Params().Find = cb.CurVal.(string)
+		fvv.RestoreFindOpts(fvv.Params().Find)
 		fvv.FindAction()
 	})
 	ftf.TextFieldSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -476,7 +1012,11 @@ func (fv *FindView) ConfigToolbar() {
 			tf := send.(*gi.TextField)
 			fvv.Params().Find = tf.Text()
 			fvv.FindAction()
+		} else if sig == int64(gi.TextFieldInsert) || sig == int64(gi.TextFieldBackspace) || sig == int64(gi.TextFieldDelete) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.CancelSearch() // stale now
 		} else if sig == int64(gi.TextFieldCleared) {
+			fv.CancelSearch()
 			tv := fv.Gide.ActiveTextView()
 			if tv != nil {
 				tv.ClearHighlights()
@@ -521,6 +1061,36 @@ func (fv *FindView) ConfigToolbar() {
 			fvv.PrevFind()
 		})
 
+	incl := fb.AddNewChild(gi.KiT_Label, "include-lbl").(*gi.Label)
+	incl.SetText("Files:")
+	incl.Tooltip = "file name globs to restrict search to, separated by spaces or commas (e.g., *.go *.md) -- all files when empty"
+	inc := fb.AddNewChild(gi.KiT_TextField, "include").(*gi.TextField)
+	inc.Tooltip = incl.Tooltip
+	inc.TextFieldSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.Params().Include = send.(*gi.TextField).Text()
+		}
+	})
+
+	excl := fb.AddNewChild(gi.KiT_Label, "exclude-lbl").(*gi.Label)
+	excl.SetText("Exclude:")
+	excl.Tooltip = "file name globs to exclude from search, separated by spaces or commas (e.g., vendor *_test.go) -- also excludes all files within matching directories"
+	exc := fb.AddNewChild(gi.KiT_TextField, "exclude").(*gi.TextField)
+	exc.Tooltip = excl.Tooltip
+	exc.TextFieldSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.Params().Exclude = send.(*gi.TextField).Text()
+		}
+	})
+
+	fb.AddAction(gi.ActOpts{Label: "Save...", Icon: "file-save", Tooltip: "save the current find string and options as a named search, to re-run from the Saved Searches panel"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			SaveSearchPrompt(fvv.Gide)
+		})
+
 	rb.AddAction(gi.ActOpts{Label: "Replace:", Tooltip: "Replace find string with replace string for currently-selected find result"}, fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
 		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
 		fvv.CompileRegexp()
@@ -530,7 +1100,7 @@ func (fv *FindView) ConfigToolbar() {
 	repls := rb.AddNewChild(gi.KiT_ComboBox, "repl-str").(*gi.ComboBox)
 	repls.Editable = true
 	repls.SetStretchMaxWidth()
-	repls.Tooltip = "String to replace find string -- click for history -- use ${n} for regexp submatch where n = 1 for first submatch, etc"
+	repls.Tooltip = "String to replace find string -- click or use up / down arrows for history -- use ${n} for regexp submatch where n = 1 for first submatch, etc"
 	repls.ConfigParts()
 	repls.ItemsFromStringList(fv.Params().ReplHist, true, 0)
 	rtf, _ := repls.TextField()
@@ -553,9 +1123,21 @@ func (fv *FindView) ConfigToolbar() {
 			fvv.ReplaceAllAction()
 		})
 
+	rb.AddAction(gi.ActOpts{Label: "Preview", Tooltip: "show a preview of all the replacements, which can be checked / unchecked by clicking on their [x] boxes, before doing Apply"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.ReplacePreviewAction()
+		})
+
+	rb.AddAction(gi.ActOpts{Label: "Apply", Tooltip: "do all the replacements that are checked in the preview"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.ApplyReplace()
+		})
+
 	locl := rb.AddNewChild(gi.KiT_Label, "loc-lbl").(*gi.Label)
 	locl.SetText("Loc:")
-	locl.Tooltip = "location to find in: all = all open folders in browser; file = current active file; dir = directory of current active file; nottop = all except the top-level in browser"
+	locl.Tooltip = "location to find in: all = all open folders in browser; file = current active file; dir = directory of current active file (and its open subdirectories if recursive); nottop = all except the top-level in browser; open = files open for editing"
 	// locl.SetProp("vertical-align", gi.AlignMiddle)
 
 	cf := rb.AddNewChild(gi.KiT_ComboBox, "loc").(*gi.ComboBox)
@@ -569,6 +1151,45 @@ func (fv *FindView) ConfigToolbar() {
 		fvv.Params().Loc = FindLoc(eval.Value)
 	})
 
+	rc := rb.AddNewChild(gi.KiT_CheckBox, "recursive").(*gi.CheckBox)
+	rc.SetText("Recursive")
+	rc.Tooltip = "for the dir location, also find in the open subdirectories of the directory of the current active file"
+	rc.ButtonSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.Params().Recursive = send.(*gi.CheckBox).IsChecked()
+		}
+	})
+
+	ctxl := rb.AddNewChild(gi.KiT_Label, "context-lbl").(*gi.Label)
+	ctxl.SetText("Context:")
+	ctxl.Tooltip = "number of lines of context to show before and after each match in the results"
+	ctxs := rb.AddNewChild(gi.KiT_SpinBox, "context").(*gi.SpinBox)
+	ctxs.Defaults()
+	ctxs.SetMin(0)
+	ctxs.SetMax(20)
+	ctxs.Step = 1
+	ctxs.Tooltip = ctxl.Tooltip
+	ctxs.SpinBoxSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+		fvv.Params().Context = int(send.(*gi.SpinBox).Value)
+		if !fvv.Previewing {
+			fvv.ShowFinds()
+		}
+	})
+
+	rb.AddAction(gi.ActOpts{Label: "Collapse", Tooltip: "collapse the results for all files to just their headers -- click on the [-] / [+] of a file header to collapse / expand it"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.SetCollapseAll(true)
+		})
+
+	rb.AddAction(gi.ActOpts{Label: "Expand", Tooltip: "expand the results for all files"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FindView).(*FindView)
+			fvv.SetCollapseAll(false)
+		})
+
 	langl := rb.AddNewChild(gi.KiT_Label, "lang-lbl").(*gi.Label)
 	langl.SetText("Lang:")
 	langl.Tooltip = "Language(s) to restrict search / replace to"
@@ -588,6 +1209,40 @@ func (fv *FindView) ConfigToolbar() {
 
 }
 
+// FindViewKeys handles the up / down arrows in the find and replace fields,
+// which move back and forward in their history
+func (fv *FindView) FindViewKeys(kt *key.ChordEvent) {
+	kf := gi.KeyFun(kt.Chord())
+	if kf != gi.KeyFunMoveUp && kf != gi.KeyFunMoveDown {
+		return
+	}
+	win := fv.ParentWindow()
+	if win == nil {
+		return
+	}
+	steps := 1
+	if kf == gi.KeyFunMoveDown {
+		steps = -1
+	}
+	foc := win.EventMgr.CurFocus()
+	if ftf, ok := fv.FindText().TextField(); ok && foc == ftf.This() {
+		kt.SetProcessed()
+		fv.FindHistStep(steps)
+	} else if rtf, ok := fv.ReplText().TextField(); ok && foc == rtf.This() {
+		kt.SetProcessed()
+		fv.ReplHistStep(steps)
+	}
+}
+
+func (fv *FindView) ConnectEvents2D() {
+	fv.Layout.ConnectEvents2D()
+	// hipri to get arrows before the text field focus navigation
+	fv.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		fvv := recv.Embed(KiT_FindView).(*FindView)
+		fvv.FindViewKeys(d.(*key.ChordEvent))
+	})
+}
+
 // FindViewProps are style properties for FindView
 var FindViewProps = ki.Props{
 	"EnumType:Flag":    gi.KiT_NodeFlags,
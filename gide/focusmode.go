@@ -0,0 +1,131 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+)
+
+// FocusModeWidth is the maximum width, in characters, of the editor pane
+// shown in the distraction-free mode
+var FocusModeWidth = 100
+
+// focusHideProps are the style properties set to hide a widget
+var focusHideProps = []string{"height", "min-height", "max-height", "margin", "padding", "border-width"}
+
+// focusWidthProps are the style properties set to center a widget with a
+// maximum width
+var focusWidthProps = []string{"width", "max-width", "horizontal-align"}
+
+// FocusMode is the state of the distraction-free mode of a project, in
+// which just the active editor pane is shown, centered with a maximum width
+// of FocusModeWidth characters: the file tree, other panes and tabs are
+// collapsed in their split views, and the toolbar and statusbar are hidden
+// by shrinking them to nothing.  Everything changed is saved, and restored
+// when the mode is turned off.
+type FocusMode struct {
+	On     bool                `desc:"the distraction-free mode is on"`
+	Splits map[ki.Ki][]float32 `desc:"splits of the split views collapsed, to restore"`
+	Props  map[ki.Ki]ki.Props  `desc:"style properties of the widgets changed, to restore -- nil for those that were not set"`
+	Hidden []*gi.WidgetBase    `desc:"widgets hidden, to show again"`
+}
+
+// SaveProps saves given style properties of given widget, if not yet saved
+func (fm *FocusMode) SaveProps(wb *gi.WidgetBase, props []string) {
+	if fm.Props == nil {
+		fm.Props = make(map[ki.Ki]ki.Props)
+	}
+	sp, ok := fm.Props[wb.This()]
+	if !ok {
+		sp = ki.Props{}
+		fm.Props[wb.This()] = sp
+	}
+	for _, pn := range props {
+		if _, has := sp[pn]; has {
+			continue
+		}
+		pv := wb.Prop(pn)
+		sp[pn] = pv
+	}
+}
+
+// Collapse collapses the split view containing given child to just that
+// child, saving its splits
+func (fm *FocusMode) Collapse(sv *gi.SplitView, child ki.Ki) {
+	idx, ok := sv.Kids.IndexOf(child, 0)
+	if !ok {
+		return
+	}
+	if fm.Splits == nil {
+		fm.Splits = make(map[ki.Ki][]float32)
+	}
+	if _, has := fm.Splits[sv.This()]; !has {
+		fm.Splits[sv.This()] = append([]float32{}, sv.Splits...)
+	}
+	sps := make([]float32, len(sv.Kids))
+	sps[idx] = 1
+	sv.SetSplits(sps...)
+}
+
+// SwapSplits swaps the splits of the collapsed split views with the saved
+// ones, without updating the views, e.g., to save the layout of the normal
+// mode while the mode is on -- swapping again reverts it
+func (fm *FocusMode) SwapSplits() {
+	for k, sps := range fm.Splits {
+		if sv, ok := k.(*gi.SplitView); ok {
+			fm.Splits[k] = sv.Splits
+			sv.Splits = sps
+		}
+	}
+}
+
+// Hide hides given widget, by shrinking it to no size, and not rendering
+// it
+func (fm *FocusMode) Hide(wb *gi.WidgetBase) {
+	fm.SaveProps(wb, focusHideProps)
+	wb.SetFixedHeight(units.NewPx(0.01))
+	wb.SetProp("margin", 0)
+	wb.SetProp("padding", 0)
+	wb.SetProp("border-width", 0)
+	wb.SetInvisible()
+	fm.Hidden = append(fm.Hidden, wb)
+}
+
+// SetMaxWidth limits the width of given widget to FocusModeWidth
+// characters, centering it horizontally
+func (fm *FocusMode) SetMaxWidth(wb *gi.WidgetBase) {
+	fm.SaveProps(wb, focusWidthProps)
+	wb.SetProp("width", units.NewCh(float32(FocusModeWidth)))
+	wb.SetProp("max-width", units.NewCh(float32(FocusModeWidth)))
+	wb.SetProp("horizontal-align", gist.AlignCenter)
+}
+
+// Restore restores all the splits and widgets changed, turning the mode off
+func (fm *FocusMode) Restore() {
+	for k, sps := range fm.Splits {
+		if sv, ok := k.(*gi.SplitView); ok && !sv.IsDeleted() {
+			sv.SetSplits(sps...)
+		}
+	}
+	for k, sp := range fm.Props {
+		if k.IsDeleted() {
+			continue
+		}
+		for pn, pv := range sp {
+			if pv == nil {
+				k.DeleteProp(pn)
+			} else {
+				k.SetProp(pn, pv)
+			}
+		}
+	}
+	for _, wb := range fm.Hidden {
+		wb.ClearInvisible()
+	}
+	*fm = FocusMode{}
+}
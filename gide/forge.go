@@ -0,0 +1,513 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+)
+
+// ForgePrefs are the preferences for the GitHub / GitLab integration, which
+// lists the pull / merge requests of the repository of a project
+type ForgePrefs struct {
+	GitHubToken string   `desc:"personal access token for the GitHub API -- optional for public repositories (but they are rate limited without it) -- the GITHUB_TOKEN environment variable is used if empty"`
+	GitLabToken string   `desc:"personal access token for the GitLab API -- the GITLAB_TOKEN environment variable is used if empty"`
+	GitLabHosts []string `desc:"host names of self-hosted GitLab servers (gitlab.com and hosts with gitlab in their name are known)"`
+}
+
+// ForgeTimeout is the timeout for requests to the forge APIs
+var ForgeTimeout = 20 * time.Second
+
+// ForgeRepo is a repository on GitHub or GitLab, as given by a remote of the
+// git repository of a project
+type ForgeRepo struct {
+	GitLab bool   `desc:"on GitLab, else GitHub"`
+	Host   string `desc:"host name, e.g., github.com"`
+	Path   string `desc:"path of the repository on the host: owner/name (GitLab allows subgroups)"`
+	Remote string `desc:"name of the git remote for it"`
+}
+
+// ParseForgeURL returns the host and repository path of given git remote
+// url, in scp-like (git@host:owner/repo.git), ssh:// or https:// form
+func ParseForgeURL(rurl string) (host, path string, ok bool) {
+	rurl = strings.TrimSpace(rurl)
+	if !strings.Contains(rurl, "://") {
+		ci := strings.Index(rurl, ":")
+		if ci < 0 {
+			return "", "", false
+		}
+		host = rurl[:ci]
+		if ai := strings.LastIndex(host, "@"); ai >= 0 {
+			host = host[ai+1:]
+		}
+		path = rurl[ci+1:]
+	} else {
+		u, err := url.Parse(rurl)
+		if err != nil {
+			return "", "", false
+		}
+		host = u.Hostname()
+		path = u.Path
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return "", "", false
+	}
+	return host, path, true
+}
+
+// DetectForgeRepo returns the GitHub or GitLab repository of the git
+// repository that given directory is in: that of the upstream remote if
+// there is one (where pull requests of forks go), else origin, else the
+// first remote -- false if none is on a known forge
+func DetectForgeRepo(dir string) (*ForgeRepo, bool) {
+	out, err := GitOutput(dir, "remote")
+	if err != nil || out == "" {
+		return nil, false
+	}
+	rems := strings.Fields(out)
+	sort.SliceStable(rems, func(i, j int) bool {
+		rank := func(r string) int {
+			switch r {
+			case "upstream":
+				return 0
+			case "origin":
+				return 1
+			}
+			return 2
+		}
+		return rank(rems[i]) < rank(rems[j])
+	})
+	for _, rem := range rems {
+		rurl, err := GitOutput(dir, "remote", "get-url", rem)
+		if err != nil {
+			continue
+		}
+		host, path, ok := ParseForgeURL(rurl)
+		if !ok {
+			continue
+		}
+		fr := &ForgeRepo{Host: host, Path: path, Remote: rem}
+		switch {
+		case host == "github.com":
+		case strings.Contains(host, "gitlab"):
+			fr.GitLab = true
+		default:
+			known := false
+			for _, h := range Prefs.Forge.GitLabHosts {
+				if h == host {
+					known = true
+				}
+			}
+			if !known {
+				continue
+			}
+			fr.GitLab = true
+		}
+		return fr, true
+	}
+	return nil, false
+}
+
+// Name returns the name of the forge
+func (fr *ForgeRepo) Name() string {
+	if fr.GitLab {
+		return "GitLab"
+	}
+	return "GitHub"
+}
+
+// WebURL returns the url of the repository web page
+func (fr *ForgeRepo) WebURL() string {
+	return "https://" + fr.Host + "/" + fr.Path
+}
+
+// IssueURL returns the url of the web page of the issue of given number
+func (fr *ForgeRepo) IssueURL(num int) string {
+	if fr.GitLab {
+		return fmt.Sprintf("%v/-/issues/%d", fr.WebURL(), num)
+	}
+	return fmt.Sprintf("%v/issues/%d", fr.WebURL(), num)
+}
+
+// Token returns the API access token for the forge, "" if none
+func (fr *ForgeRepo) Token() string {
+	if fr.GitLab {
+		if Prefs.Forge.GitLabToken != "" {
+			return Prefs.Forge.GitLabToken
+		}
+		return os.Getenv("GITLAB_TOKEN")
+	}
+	if Prefs.Forge.GitHubToken != "" {
+		return Prefs.Forge.GitHubToken
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+// APIGet gets the JSON result of given API url into val
+func (fr *ForgeRepo) APIGet(aurl string, val interface{}) error {
+	req, err := http.NewRequest("GET", aurl, nil)
+	if err != nil {
+		return err
+	}
+	if tok := fr.Token(); tok != "" {
+		if fr.GitLab {
+			req.Header.Set("PRIVATE-TOKEN", tok)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+tok)
+		}
+	}
+	if !fr.GitLab {
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+	cl := &http.Client{Timeout: ForgeTimeout}
+	resp, err := cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := strings.TrimSpace(string(b))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return fmt.Errorf("%v API: %v: %v", fr.Name(), resp.Status, msg)
+	}
+	return json.Unmarshal(b, val)
+}
+
+// ForgePR is an open pull request (GitHub) or merge request (GitLab)
+type ForgePR struct {
+	Number int    `desc:"number of the request"`
+	Title  string `width:"60" desc:"title of the request"`
+	Author string `desc:"user name of the author"`
+	Branch string `desc:"branch with the changes (in the fork for requests from forks)"`
+	Base   string `desc:"branch that the changes are to be merged into"`
+	Ref    string `tableview:"-" desc:"ref of the changes in the repository, for fetching them"`
+	URL    string `tableview:"-" desc:"url of the web page of the request"`
+	Body   string `tableview:"-" desc:"description of the request"`
+}
+
+// LocalBranch returns the name of the local branch the request is checked out to
+func (pr *ForgePR) LocalBranch(fr *ForgeRepo) string {
+	if fr.GitLab {
+		return fmt.Sprintf("mr-%d", pr.Number)
+	}
+	return fmt.Sprintf("pr-%d", pr.Number)
+}
+
+// IssueRe matches references to issues (#123) in the text of a request
+var IssueRe = regexp.MustCompile(`(?:^|[^\w&/])#(\d+)\b`)
+
+// Issues returns the numbers of the issues referenced in the title and
+// description of the request
+func (pr *ForgePR) Issues() []int {
+	var nums []int
+	have := map[int]bool{}
+	for _, m := range IssueRe.FindAllStringSubmatch(pr.Title+"\n"+pr.Body, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n == pr.Number || have[n] {
+			continue
+		}
+		nums = append(nums, n)
+		have[n] = true
+	}
+	return nums
+}
+
+// PullRequests returns the open pull / merge requests of the repository
+func (fr *ForgeRepo) PullRequests() ([]*ForgePR, error) {
+	var prs []*ForgePR
+	if fr.GitLab {
+		var mrs []struct {
+			IID          int    `json:"iid"`
+			Title        string `json:"title"`
+			Description  string `json:"description"`
+			WebURL       string `json:"web_url"`
+			SourceBranch string `json:"source_branch"`
+			TargetBranch string `json:"target_branch"`
+			Author       struct {
+				Username string `json:"username"`
+			} `json:"author"`
+		}
+		aurl := fmt.Sprintf("https://%v/api/v4/projects/%v/merge_requests?state=opened&per_page=100", fr.Host, url.PathEscape(fr.Path))
+		if err := fr.APIGet(aurl, &mrs); err != nil {
+			return nil, err
+		}
+		for _, mr := range mrs {
+			prs = append(prs, &ForgePR{Number: mr.IID, Title: mr.Title, Author: mr.Author.Username, Branch: mr.SourceBranch, Base: mr.TargetBranch, Ref: fmt.Sprintf("refs/merge-requests/%d/head", mr.IID), URL: mr.WebURL, Body: mr.Description})
+		}
+		return prs, nil
+	}
+	var pls []struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Head struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	}
+	aurl := fmt.Sprintf("https://api.github.com/repos/%v/pulls?state=open&per_page=100", fr.Path)
+	if err := fr.APIGet(aurl, &pls); err != nil {
+		return nil, err
+	}
+	for _, pl := range pls {
+		prs = append(prs, &ForgePR{Number: pl.Number, Title: pl.Title, Author: pl.User.Login, Branch: pl.Head.Ref, Base: pl.Base.Ref, Ref: fmt.Sprintf("refs/pull/%d/head", pl.Number), URL: pl.HTMLURL, Body: pl.Body})
+	}
+	return prs, nil
+}
+
+// PRDiff fetches the changes of given request and its base branch from the
+// remote into the repository at given directory, and returns the diff of
+// the changes from where they branched off the base
+func (fr *ForgeRepo) PRDiff(dir string, pr *ForgePR) ([]byte, error) {
+	base := fmt.Sprintf("refs/remotes/%v/%v", fr.Remote, pr.Base)
+	head := fmt.Sprintf("refs/gide/%v", pr.LocalBranch(fr))
+	if _, err := GitRun(dir, "fetch", "-q", fr.Remote, "+refs/heads/"+pr.Base+":"+base, "+"+pr.Ref+":"+head); err != nil {
+		return nil, err
+	}
+	return GitRun(dir, "diff", base+"..."+head)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    ForgeView
+
+// ForgeView is a widget that lists the open pull / merge requests of the
+// GitHub or GitLab repository of the project, for checking out their
+// branches, reviewing their diffs, and opening them and their linked
+// issues in the browser
+type ForgeView struct {
+	gi.Layout
+	Gide Gide       `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Repo *ForgeRepo `json:"-" xml:"-" copy:"-" desc:"the forge repository"`
+	Dir  string     `desc:"directory of the git repository"`
+	PRs  []*ForgePR `desc:"the open requests"`
+}
+
+var KiT_ForgeView = kit.Types.AddType(&ForgeView{}, ForgeViewProps)
+
+// Config configures the view for given forge repository, of the git
+// repository in given directory
+func (fv *ForgeView) Config(ge Gide, fr *ForgeRepo, dir string) {
+	fv.Gide = ge
+	fv.Repo = fr
+	fv.Dir = dir
+	fv.Lay = gi.LayoutVert
+	fv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "forgebar")
+	config.Add(gi.KiT_SplitView, "forgesplit")
+	mods, updt := fv.ConfigChildren(config)
+	if !mods {
+		updt = fv.UpdateStart()
+	}
+	fv.ConfigToolbar()
+	split := fv.SplitView()
+	split.Dim = mat32.Y
+	if len(split.Kids) == 0 {
+		tv := giv.AddNewTableView(split, "prs")
+		tv.SetStretchMax()
+		tv.SetInactive()
+		tv.SliceViewSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(giv.SliceViewDoubleClicked) {
+				fvv, _ := recv.Embed(KiT_ForgeView).(*ForgeView)
+				fvv.Diff()
+			}
+		})
+		ly := gi.AddNewLayout(split, "diff", gi.LayoutVert)
+		dtv := ConfigOutputTextView(ly)
+		dtv.SetProp("white-space", "pre")
+		dtv.SetBuf(giv.NewTextBuf())
+		split.SetSplits(.35, .65)
+	}
+	fv.TableView().SetSlice(&fv.PRs)
+	fv.UpdateEnd(updt)
+}
+
+// ToolBar returns the forge toolbar
+func (fv *ForgeView) ToolBar() *gi.ToolBar {
+	return fv.ChildByName("forgebar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the requests and the diff
+func (fv *ForgeView) SplitView() *gi.SplitView {
+	return fv.ChildByName("forgesplit", 1).(*gi.SplitView)
+}
+
+// TableView returns the table of requests
+func (fv *ForgeView) TableView() *giv.TableView {
+	return fv.SplitView().ChildByName("prs", 0).(*giv.TableView)
+}
+
+// DiffView returns the TextView showing the diff of a request
+func (fv *ForgeView) DiffView() *giv.TextView {
+	ly := fv.SplitView().ChildByName("diff", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// SelPR returns the selected request, false if none
+func (fv *ForgeView) SelPR() (*ForgePR, bool) {
+	idx := fv.TableView().SelectedIdx
+	if idx < 0 || idx >= len(fv.PRs) {
+		return nil, false
+	}
+	return fv.PRs[idx], true
+}
+
+// ConfigToolbar adds the toolbar actions
+func (fv *ForgeView) ConfigToolbar() {
+	tb := fv.ToolBar()
+	if tb.HasChildren() {
+		tb.ChildByName("repo", 0).(*gi.Label).SetText(fv.Repo.Name() + ": " + fv.Repo.Path)
+		return
+	}
+	tb.SetStretchMaxWidth()
+	gi.AddNewLabel(tb, "repo", fv.Repo.Name()+": "+fv.Repo.Path)
+	tb.AddSeparator("sep-repo")
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "get the list of open requests again"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_ForgeView).(*ForgeView)
+			fvv.Refresh()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Diff", Icon: "file-sheet", Tooltip: "show the changes of the selected request, from where they branched off its base (also by double-click)"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_ForgeView).(*ForgeView)
+			fvv.Diff()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Checkout", Icon: "file-download", Tooltip: "fetch the changes of the selected request into a local branch (pr-N or mr-N) and switch to it"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_ForgeView).(*ForgeView)
+			fvv.Checkout()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Open", Icon: "file-open", Tooltip: "open the web page of the selected request in the browser"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_ForgeView).(*ForgeView)
+			if pr, ok := fvv.SelPR(); ok {
+				oswin.TheApp.OpenURL(pr.URL)
+			}
+		})
+	ib := gi.AddNewMenuButton(tb, "issues")
+	ib.SetText("Issues")
+	ib.Tooltip = "open the issues referenced in the selected request (#N) in the browser"
+	ib.MakeMenuFunc = fv.IssuesMenu
+}
+
+// Refresh gets the list of open requests in the background, and shows it
+func (fv *ForgeView) Refresh() {
+	fv.Gide.SetStatus(fmt.Sprintf("getting open requests from %v...", fv.Repo.Name()))
+	go func() {
+		prs, err := fv.Repo.PullRequests()
+		wupdt := fv.TopUpdateStart()
+		defer fv.TopUpdateEnd(wupdt)
+		if err != nil {
+			fv.Gide.SetStatus(err.Error())
+			gi.PromptDialog(fv.Viewport, gi.DlgOpts{Title: "Getting Requests Failed", Prompt: err.Error() + " -- set an access token in Preferences / Forge if needed"}, gi.AddOk, gi.NoCancel, nil, nil)
+			return
+		}
+		fv.PRs = prs
+		fv.TableView().SetSlice(&fv.PRs)
+		fv.Gide.SetStatus(fmt.Sprintf("%d open requests on %v", len(prs), fv.Repo.Name()))
+	}()
+}
+
+// Diff shows the changes of the selected request
+func (fv *ForgeView) Diff() {
+	pr, ok := fv.SelPR()
+	if !ok {
+		return
+	}
+	dbuf := fv.DiffView().Buf
+	dbuf.SetHiStyle(fv.Gide.ProjPrefs().ProjHiStyle())
+	dbuf.Info.Name = "changes.diff"
+	dbuf.Info.Sup = filecat.Diff
+	diff, err := fv.Repo.PRDiff(fv.Dir, pr)
+	if err != nil {
+		diff = []byte(err.Error())
+	}
+	hdr := fmt.Sprintf("#%d %v (%v: %v into %v)\n\n%v\n\n", pr.Number, pr.Title, pr.Author, pr.Branch, pr.Base, strings.TrimSpace(pr.Body))
+	dbuf.SetText(append([]byte(hdr), diff...))
+}
+
+// Checkout fetches the changes of the selected request into a local branch
+// and switches to it, using the Fetch PR Git and Switch Branch Git commands
+func (fv *ForgeView) Checkout() {
+	pr, ok := fv.SelPR()
+	if !ok {
+		return
+	}
+	lbr := pr.LocalBranch(fv.Repo)
+	fv.Gide.SaveAllCheck(true, func() { // true = cancel option
+		avp := fv.Gide.ArgVarVals()
+		(*avp)["{PromptString1}"] = fv.Repo.Remote
+		(*avp)["{PromptString2}"] = "+" + pr.Ref + ":refs/heads/" + lbr
+		CmdNoUserPrompt = true // don't re-prompt!
+		fv.Gide.ExecCmdNameFileName(fv.Dir, "Fetch PR Git", true, true)
+		(*avp)["{PromptString1}"] = lbr
+		CmdNoUserPrompt = true
+		fv.Gide.ExecCmdNameFileName(fv.Dir, "Switch Branch Git", true, false)
+		fv.Gide.UpdateVcsStatus()
+		if br, _ := GitCurBranch(fv.Dir); br != lbr {
+			gi.PromptDialog(fv.Viewport, gi.DlgOpts{Title: "Checkout Failed", Prompt: fmt.Sprintf("Could not check out request #%d to branch: %v -- see the Fetch PR Git and Switch Branch Git tabs for the output of git", pr.Number, lbr)}, gi.AddOk, gi.NoCancel, nil, nil)
+		}
+	})
+}
+
+// IssuesMenu makes the menu of the issues referenced in the selected request
+func (fv *ForgeView) IssuesMenu(obj ki.Ki, m *gi.Menu) {
+	*m = gi.Menu{}
+	pr, ok := fv.SelPR()
+	var nums []int
+	if ok {
+		nums = pr.Issues()
+	}
+	if len(nums) == 0 {
+		ac := m.AddAction(gi.ActOpts{Label: "No referenced issues"}, nil, nil)
+		ac.SetInactive()
+		return
+	}
+	for _, n := range nums {
+		m.AddAction(gi.ActOpts{Label: fmt.Sprintf("#%d", n), Data: fv.Repo.IssueURL(n)}, fv.This(),
+			func(recv, send ki.Ki, sig int64, data interface{}) {
+				oswin.TheApp.OpenURL(send.(*gi.Action).Data.(string))
+			})
+	}
+}
+
+// ForgeViewProps are style properties for ForgeView
+var ForgeViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
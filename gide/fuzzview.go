@@ -0,0 +1,531 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// FuzzStats are the counters of a run of go test -fuzz, from its periodic
+// fuzz: status lines
+type FuzzStats struct {
+	Elapsed string `desc:"time elapsed"`
+	Execs   int64  `desc:"number of inputs tried"`
+	Rate    int64  `desc:"inputs tried per second"`
+	New     int    `desc:"new interesting inputs found in this run"`
+	Corpus  int    `desc:"total size of the corpus"`
+	Crashes int    `desc:"failing inputs found in this run"`
+	Phase   string `desc:"what the fuzzer is doing, e.g., gathering baseline coverage or minimizing"`
+}
+
+var (
+	// FuzzStatRe matches the status line of the fuzzer:
+	// fuzz: elapsed: 3s, execs: 343392 (114432/sec), new interesting: 2 (total: 4)
+	FuzzStatRe = regexp.MustCompile(`^fuzz: elapsed: (\S+), execs: (\d+) \((\d+)/sec\), new interesting: (\d+) \(total: (\d+)\)`)
+
+	// FuzzPhaseRe matches the other status lines of the fuzzer:
+	// fuzz: elapsed: 0s, gathering baseline coverage: 1/2 completed
+	FuzzPhaseRe = regexp.MustCompile(`^fuzz: elapsed: (\S+), (.+)$`)
+
+	// FuzzFailRe matches the report of a failing input written to the
+	// seed corpus in testdata
+	FuzzFailRe = regexp.MustCompile(`Failing input written to (\S+)`)
+)
+
+// Update updates the stats from given line of output, returning true if
+// it is a status (or failure) line
+func (fs *FuzzStats) Update(ln string) bool {
+	if m := FuzzStatRe.FindStringSubmatch(ln); m != nil {
+		fs.Elapsed = m[1]
+		fs.Execs, _ = strconv.ParseInt(m[2], 10, 64)
+		fs.Rate, _ = strconv.ParseInt(m[3], 10, 64)
+		fs.New, _ = strconv.Atoi(m[4])
+		fs.Corpus, _ = strconv.Atoi(m[5])
+		fs.Phase = "fuzzing"
+		return true
+	}
+	if m := FuzzPhaseRe.FindStringSubmatch(ln); m != nil {
+		fs.Elapsed = m[1]
+		fs.Phase = m[2]
+		return true
+	}
+	if FuzzFailRe.MatchString(ln) {
+		fs.Crashes++
+		return true
+	}
+	return false
+}
+
+// String returns the stats as shown in the toolbar
+func (fs *FuzzStats) String() string {
+	if fs.Phase == "" {
+		return ""
+	}
+	if fs.Execs == 0 {
+		return fmt.Sprintf("%v: %v -- crashes: %d", fs.Elapsed, fs.Phase, fs.Crashes)
+	}
+	return fmt.Sprintf("%v: execs: %d (%d/sec) -- corpus: %d (%d new) -- crashes: %d", fs.Elapsed, fs.Execs, fs.Rate, fs.Corpus, fs.New, fs.Crashes)
+}
+
+// FuzzInput is an input of the seed corpus of a fuzz target in testdata,
+// as written there by the fuzzer when it fails
+type FuzzInput struct {
+	Func     string `desc:"fuzz target"`
+	Name     string `desc:"name of the input file, which is also the name of its subtest"`
+	Values   string `width:"50" desc:"the values of the input"`
+	Modified string `desc:"when the input was written"`
+	Path     string `view:"-" desc:"full path of the input file"`
+}
+
+// FuzzCorpusDir returns the directory of the seed corpus of given fuzz
+// target of the package in given directory
+func FuzzCorpusDir(dir, fn string) string {
+	return filepath.Join(dir, "testdata", "fuzz", fn)
+}
+
+// FuzzInputValues returns the values of given input file, in the go test
+// fuzz v1 encoding, on one line, truncated to max chars
+func FuzzInputValues(b []byte, max int) string {
+	lns := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lns) > 0 && strings.HasPrefix(lns[0], "go test fuzz") {
+		lns = lns[1:]
+	}
+	vals := strings.Join(lns, ", ")
+	if len(vals) > max {
+		vals = vals[:max] + "..."
+	}
+	return vals
+}
+
+// FuzzFuncs returns the fuzz targets of the _test.go files in given
+// directory
+func FuzzFuncs(dir string) []*TestNode {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var fns []*TestNode
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), "_test.go") {
+			continue
+		}
+		for _, tn := range TestScanFile(filepath.Join(dir, fi.Name())) {
+			if tn.Kind == "Fuzz" {
+				fns = append(fns, tn)
+			}
+		}
+	}
+	return fns
+}
+
+// FuzzInputs returns the inputs of the seed corpus of given fuzz targets,
+// in given directory, the most recent first
+func FuzzInputs(dir string, fns []*TestNode) []FuzzInput {
+	var fins []FuzzInput
+	for _, fn := range fns {
+		cdir := FuzzCorpusDir(dir, fn.Nm)
+		fis, err := ioutil.ReadDir(cdir)
+		if err != nil {
+			continue
+		}
+		for _, fi := range fis {
+			if fi.IsDir() {
+				continue
+			}
+			fpath := filepath.Join(cdir, fi.Name())
+			b, _ := ioutil.ReadFile(fpath)
+			fins = append(fins, FuzzInput{Func: fn.Nm, Name: fi.Name(), Values: FuzzInputValues(b, 80),
+				Modified: fi.ModTime().Format("2006-01-02 15:04:05"), Path: fpath})
+		}
+	}
+	sort.SliceStable(fins, func(i, j int) bool {
+		return fins[i].Modified > fins[j].Modified
+	})
+	return fins
+}
+
+// FuzzView runs go test -fuzz on a fuzz target of a package, with its live
+// counters, and lists the inputs of the seed corpus in testdata, i.e., the
+// failing inputs found, for running or debugging the target on one
+type FuzzView struct {
+	gi.Layout
+	Gide     Gide        `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Dir      string      `desc:"directory of the package"`
+	Func     string      `desc:"the fuzz target"`
+	FuzzTime string      `desc:"how long to fuzz, as with -fuzztime, e.g., 30s or 1000x -- empty to fuzz until a failure or stopped"`
+	Funcs    []*TestNode `json:"-" xml:"-" desc:"the fuzz targets of the package"`
+	Inputs   []FuzzInput `desc:"the inputs of the seed corpus of the fuzz targets"`
+	Stats    FuzzStats   `desc:"counters of the current or last run"`
+	Running  bool        `json:"-" xml:"-" desc:"true while fuzzing"`
+}
+
+var KiT_FuzzView = kit.Types.AddType(&FuzzView{}, FuzzViewProps)
+
+// FuzzCmdName is the name of the commands run by the FuzzView in the
+// CmdRuns of the project, for stopping them
+var FuzzCmdName = "Fuzz"
+
+// Config configures the view for the fuzz targets of the package in given
+// directory, with given target selected if non-empty
+func (fv *FuzzView) Config(ge Gide, dir, fn string) {
+	fv.Gide = ge
+	fv.Lay = gi.LayoutVert
+	fv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "fuzzbar")
+	config.Add(gi.KiT_SplitView, "fuzzsplit")
+	mods, updt := fv.ConfigChildren(config)
+	if !mods {
+		updt = fv.UpdateStart()
+	}
+	fv.ConfigToolbar()
+	fv.ConfigSplitView()
+	if dir != "" && dir != fv.Dir && !fv.Running {
+		fv.Dir = dir
+		fv.Func = ""
+	}
+	fv.Refresh()
+	if fn != "" && !fv.Running {
+		fv.SetFunc(fn)
+	}
+	fv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (fv *FuzzView) ToolBar() *gi.ToolBar {
+	return fv.ChildByName("fuzzbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the inputs and the output
+func (fv *FuzzView) SplitView() *gi.SplitView {
+	return fv.ChildByName("fuzzsplit", 1).(*gi.SplitView)
+}
+
+// TableView returns the table of the inputs of the seed corpus
+func (fv *FuzzView) TableView() *giv.TableView {
+	return fv.SplitView().ChildByName("inputs", 0).(*giv.TableView)
+}
+
+// TextView returns the TextView of the output of the runs
+func (fv *FuzzView) TextView() *giv.TextView {
+	ly := fv.SplitView().ChildByName("out", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// SelInput returns the selected input, nil if none
+func (fv *FuzzView) SelInput() *FuzzInput {
+	idx := fv.TableView().SelectedIdx
+	if idx < 0 || idx >= len(fv.Inputs) {
+		fv.Gide.SetStatus("select an input first")
+		return nil
+	}
+	return &fv.Inputs[idx]
+}
+
+// FuncNode returns the node of the fuzz target of given name, nil if none
+func (fv *FuzzView) FuncNode(fn string) *TestNode {
+	for _, tn := range fv.Funcs {
+		if tn.Nm == fn {
+			return tn
+		}
+	}
+	return nil
+}
+
+// ConfigToolbar adds the toolbar actions
+func (fv *FuzzView) ConfigToolbar() {
+	tb := fv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Name: "target", Label: "Target...", Icon: "function", Tooltip: "choose the fuzz target of the package"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			fvv.ChooseFunc()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Fuzz", Icon: "play", Tooltip: "run go test -fuzz on the fuzz target, until a failing input is found, the fuzz time is up, or it is stopped"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			fvv.Fuzz()
+		})
+	gi.AddNewLabel(tb, "time-lbl", "Time:")
+	tf := gi.AddNewTextField(tb, "time")
+	tf.Tooltip = "how long to fuzz, as with -fuzztime, e.g., 30s or 1000x -- empty to fuzz until a failure or stopped"
+	tf.SetMinPrefWidth(units.NewCh(8))
+	tf.TextFieldSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			fvv.FuzzTime = strings.TrimSpace(send.(*gi.TextField).Text())
+		}
+	})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop fuzzing"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			fvv.Gide.CmdRuns().KillByName(FuzzCmdName)
+		})
+	tb.AddSeparator("sep-input")
+	tb.AddAction(gi.ActOpts{Label: "Run Input", Icon: "play", Tooltip: "run the fuzz target on the selected input only (also by double-click)"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			if fi := fvv.SelInput(); fi != nil {
+				fvv.RunInput(fi)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Debug Input", Icon: "gear", Tooltip: "debug the fuzz target on the selected input only"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			if fi := fvv.SelInput(); fi != nil {
+				fvv.DebugInput(fi)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Open Input", Icon: "file-text", Tooltip: "open the file of the selected input"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			if fi := fvv.SelInput(); fi != nil {
+				fvv.Gide.ShowFile(fi.Path, 1)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "re-scan the fuzz targets and their inputs in testdata"},
+		fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			fvv.Refresh()
+		})
+	tb.AddSeparator("sep-stats")
+	gi.AddNewLabel(tb, "stats", "")
+}
+
+// ConfigSplitView configures the split view of the inputs and the output
+func (fv *FuzzView) ConfigSplitView() {
+	split := fv.SplitView()
+	split.Dim = mat32.Y
+	if len(split.Kids) > 0 {
+		return
+	}
+	tv := split.AddNewChild(giv.KiT_TableView, "inputs").(*giv.TableView)
+	tv.SetStretchMax()
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SetInactive()
+	tv.SetSlice(&fv.Inputs)
+	tv.SliceViewSig.Connect(fv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.SliceViewDoubleClicked) {
+			fvv, _ := recv.Embed(KiT_FuzzView).(*FuzzView)
+			if fi := fvv.SelInput(); fi != nil {
+				fvv.RunInput(fi)
+			}
+		}
+	})
+	ly := gi.AddNewLayout(split, "out", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.3, .7)
+}
+
+// Refresh re-scans the fuzz targets of the package and the inputs of their
+// seed corpus
+func (fv *FuzzView) Refresh() {
+	if fv.Dir == "" {
+		return
+	}
+	fv.Funcs = FuzzFuncs(fv.Dir)
+	if fv.Func == "" && len(fv.Funcs) > 0 {
+		fv.Func = fv.Funcs[0].Nm
+	}
+	fv.SetFunc(fv.Func)
+	fv.SetInputs(FuzzInputs(fv.Dir, fv.Funcs))
+}
+
+// SetFunc sets the fuzz target, shown in the toolbar
+func (fv *FuzzView) SetFunc(fn string) {
+	fv.Func = fn
+	lbl := "Target..."
+	if fn != "" {
+		lbl = fn
+	}
+	if ac, ok := fv.ToolBar().ChildByName("target", 0).(*gi.Action); ok {
+		ac.SetText(lbl)
+	}
+}
+
+// ChooseFunc pops up a menu of the fuzz targets of the package
+func (fv *FuzzView) ChooseFunc() {
+	fv.Refresh()
+	if len(fv.Funcs) == 0 {
+		fv.Gide.SetStatus("no fuzz targets in: " + fv.Dir)
+		return
+	}
+	nms := make([]string, len(fv.Funcs))
+	for i, tn := range fv.Funcs {
+		nms[i] = tn.Nm
+	}
+	gi.StringsChooserPopup(nms, fv.Func, fv.ToolBar(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		fv.SetFunc(ac.Text)
+	})
+}
+
+// SetInputs sets the inputs shown
+func (fv *FuzzView) SetInputs(fins []FuzzInput) {
+	vp := fv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	fv.Inputs = fins
+	tv := fv.TableView()
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	tv.SetSlice(&fv.Inputs)
+	tv.UpdateEnd(updt)
+}
+
+// SetStats shows the counters in the toolbar
+func (fv *FuzzView) SetStats() {
+	vp := fv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	if lb, ok := fv.ToolBar().ChildByName("stats", 0).(*gi.Label); ok {
+		lb.SetText(fv.Stats.String())
+	}
+}
+
+// ClearOutput clears the output, starting it with given line in bold
+func (fv *FuzzView) ClearOutput(head string) {
+	vp := fv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	tbuf := fv.TextView().Buf
+	tbuf.New(0)
+	tbuf.SetInactive(true)
+	tbuf.AppendTextMarkup([]byte(head), []byte("<b>"+html.EscapeString(head)+"</b>"), giv.EditSignal)
+}
+
+// AppendOutput appends given line of output, with links to the files
+func (fv *FuzzView) AppendOutput(ln []byte) {
+	vp := fv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	tbuf := fv.TextView().Buf
+	tbuf.AppendTextLineMarkup(ln, MarkupCmdOutput(ln), giv.EditSignal)
+	if tv := fv.TextView(); tv.IsVisible() {
+		tv.CursorEndDoc()
+	}
+}
+
+// Fuzz runs go test -fuzz on the fuzz target in the background, with its
+// output and counters shown as it goes, and then refreshes the inputs
+func (fv *FuzzView) Fuzz() {
+	if fv.Func == "" {
+		fv.ChooseFunc()
+		return
+	}
+	re := "^" + fv.Func + "$"
+	args := []string{"test", "-run", re, "-fuzz", re}
+	if fv.FuzzTime != "" {
+		args = append(args, "-fuzztime", fv.FuzzTime)
+	}
+	fv.Gide.SaveAllCheck(true, func() { // true = cancel option
+		fv.Run(append(args, "."), true)
+	})
+}
+
+// RunInput runs the fuzz target on given input of its seed corpus, which
+// is run as a subtest of it
+func (fv *FuzzView) RunInput(fi *FuzzInput) {
+	ta := &TestAt{Func: fi.Func, Kind: "Fuzz", Subs: []string{fi.Name}}
+	fv.Run([]string{"test", "-v", "-run", ta.RunRegexp(), "."}, false)
+}
+
+// DebugInput runs the debugger on the fuzz target on given input
+func (fv *FuzzView) DebugInput(fi *FuzzInput) {
+	tn := fv.FuncNode(fi.Func)
+	if tn == nil {
+		fv.Gide.SetStatus("fuzz target not found: " + fi.Func)
+		return
+	}
+	ta := &TestAt{Func: fi.Func, Kind: "Fuzz", Subs: []string{fi.Name}}
+	fv.Gide.DebugTestArgs(tn.FPath, ta.DebugArgs())
+}
+
+// Run runs go test with given args in the package directory in the
+// background, streaming the output and updating the counters if fuzz
+func (fv *FuzzView) Run(args []string, fuzz bool) {
+	if fv.Running {
+		fv.Gide.SetStatus("already fuzzing -- stop it first")
+		return
+	}
+	ge := fv.Gide
+	fv.Running = fuzz
+	cmdstr := "go " + strings.Join(args, " ")
+	fv.ClearOutput(cmdstr)
+	fv.Stats = FuzzStats{}
+	if fuzz {
+		fv.Stats.Phase = "starting"
+	}
+	fv.SetStats()
+	go func() {
+		cmd := ge.ProjPrefs().ExecCmd(fv.Dir, nil, "go", args...)
+		ge.CmdRuns().AddCmd(FuzzCmdName, cmdstr, &CmdAndArgs{Cmd: "go", Args: args}, cmd)
+		stdout, err := cmd.StdoutPipe()
+		if err == nil {
+			cmd.Stderr = cmd.Stdout
+			err = cmd.Start()
+		}
+		if err == nil {
+			sc := bufio.NewScanner(stdout)
+			sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+			for sc.Scan() {
+				if fv.This() == nil || fv.IsDeleted() || fv.IsDestroyed() {
+					continue
+				}
+				fv.AppendOutput(bytes.TrimRight(sc.Bytes(), "\r"))
+				if fuzz && fv.Stats.Update(sc.Text()) {
+					fv.SetStats()
+				}
+			}
+			err = cmd.Wait()
+		}
+		ge.CmdRuns().DeleteByName(FuzzCmdName)
+		fv.Running = false
+		if fv.This() == nil || fv.IsDeleted() || fv.IsDestroyed() {
+			return
+		}
+		res := "done"
+		if err != nil {
+			res = err.Error()
+		}
+		fv.AppendOutput([]byte(res))
+		if fuzz {
+			fv.Stats.Phase = res
+			fv.SetStats()
+			fv.SetInputs(FuzzInputs(fv.Dir, fv.Funcs))
+		}
+		ge.SetStatus(cmdstr + ": " + res)
+	}()
+}
+
+// FuzzViewProps are style properties for FuzzView
+var FuzzViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
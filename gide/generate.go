@@ -0,0 +1,102 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/pi/filecat"
+)
+
+// GenerateRe matches a //go:generate directive line
+var GenerateRe = regexp.MustCompile(`^//go:generate\s`)
+
+// GenerateColor is the gutter color of the lines with a //go:generate
+// directive -- double-click on it to run the directive
+var GenerateColor = "#b39ddb"
+
+// GenerateLinesProp is the property of a TextBuf holding the lines marked
+// as //go:generate directives, as distinct from breakpoints
+var GenerateLinesProp = "gide-generate-lines"
+
+// IsGenerateDirective returns true if given line is a //go:generate
+// directive
+func IsGenerateDirective(ln []byte) bool {
+	return GenerateRe.Match(ln)
+}
+
+// GenerateRunRegexp returns the go generate -run regexp selecting only the
+// directive of given line -- braces are escaped as classes, so they are
+// left as is by the command arg vars
+func GenerateRunRegexp(ln string) string {
+	re := regexp.QuoteMeta(strings.TrimRight(ln, " \t\r"))
+	re = strings.NewReplacer(`\{`, `[{]`, `\}`, `[}]`).Replace(re)
+	return "^" + re + "$"
+}
+
+// MarkGenerate colors the line number gutter of the //go:generate
+// directives of given Go buffer, replacing the previous marks -- lines
+// with other colors, e.g., breakpoints, are left as is -- returns the
+// number of directives
+func MarkGenerate(tb *giv.TextBuf) int {
+	if tb == nil {
+		return 0
+	}
+	olns, had := tb.Prop(GenerateLinesProp).(map[int]bool)
+	if had {
+		for ln := range olns {
+			tb.DeleteLineColor(ln)
+		}
+		tb.DeleteProp(GenerateLinesProp)
+	}
+	mlns := map[int]bool{}
+	if tb.Info.Sup == filecat.Go {
+		for ln := 0; ln < tb.NumLines(); ln++ {
+			if !IsGenerateDirective(tb.BytesLine(ln)) || tb.HasLineColor(ln) {
+				continue
+			}
+			tb.SetLineColor(ln, GenerateColor)
+			mlns[ln] = true
+		}
+	}
+	if len(mlns) > 0 {
+		tb.SetProp(GenerateLinesProp, mlns)
+	}
+	if had || len(mlns) > 0 {
+		tb.RefreshViews()
+	}
+	return len(mlns)
+}
+
+// UpdateGenerateMarks updates the //go:generate marks of given buffer for
+// given edit, only if it spans lines or is on a directive line
+func UpdateGenerateMarks(tb *giv.TextBuf, tbe *textbuf.Edit) {
+	if tb == nil || tbe == nil || tb.Info.Sup != filecat.Go {
+		return
+	}
+	ln := tbe.Reg.Start.Ln
+	if tbe.Reg.End.Ln != ln || IsGenerateLine(tb, ln) || (ln < tb.NumLines() && IsGenerateDirective(tb.BytesLine(ln))) {
+		MarkGenerate(tb)
+	}
+}
+
+// IsGenerateLine returns true if the color of given line of the buffer
+// marks a //go:generate directive
+func IsGenerateLine(tb *giv.TextBuf, ln int) bool {
+	lns, ok := tb.Prop(GenerateLinesProp).(map[int]bool)
+	return ok && lns[ln]
+}
+
+// UnmarkGenerateLine removes given line from the //go:generate marks, when
+// its color is replaced, e.g., by a breakpoint
+func UnmarkGenerateLine(tb *giv.TextBuf, ln int) {
+	if lns, ok := tb.Prop(GenerateLinesProp).(map[int]bool); ok && lns[ln] {
+		delete(lns, ln)
+		tb.DeleteLineColor(ln)
+	}
+}
@@ -30,6 +30,18 @@ type Gide interface {
 	// FileTree returns the gide.Files file tree
 	FileTree() *giv.FileTree
 
+	// FileIgnore returns the files and directories ignored by .gitignore
+	// and .gideignore files in the project
+	FileIgnore() *FileIgnore
+
+	// ProjSpellDict returns the dictionary of the words accepted by the
+	// spell checking in the project
+	ProjSpellDict() *SpellDict
+
+	// FileTrash returns the trash that deleted project files are moved to,
+	// for undoing deletions
+	FileTrash() *FileTrash
+
 	// LastSaveTime returns the time stamp when a file was last saved within project --
 	// can be used for dirty flag state relative to other time stamps.
 	LastSaveTime() time.Time
@@ -38,10 +50,23 @@ type Gide interface {
 	// version or whatever is set in project preferences
 	VersCtrl() giv.VersCtrlName
 
+	// FileVersCtrl returns the version control system of the repository that
+	// given file or directory is in, detected by walking up from it (so
+	// nested repositories use their own), else the project one (VersCtrl)
+	FileVersCtrl(fpath string) giv.VersCtrlName
+
 	// CmdRuns returns the CmdRuns manager of running commands, used extensively
 	// in commands.go
 	CmdRuns() *CmdRuns
 
+	// Jobs returns the JobManager running the background jobs of the
+	// project, e.g., indexing and searches
+	Jobs() *JobManager
+
+	// UpdateVcsStatus updates the version control status of the files in the
+	// file tree in the background, e.g., after running a command
+	UpdateVcsStatus()
+
 	// ArgVarVals returns the ArgVarVals argument variable values
 	ArgVarVals() *ArgVarVals
 
@@ -73,6 +98,14 @@ type Gide interface {
 	// activated, returns text view and index
 	NextViewFileNode(fn *giv.FileNode) (*TextView, int)
 
+	// ViewFileNode sets the given text view at given index to view file in
+	// given node (opens buffer if not already opened)
+	ViewFileNode(tv *TextView, vidx int, fn *giv.FileNode)
+
+	// TextViewIndex finds index of given textview among all the editor panes,
+	// -1 if not one of them
+	TextViewIndex(av *TextView) int
+
 	// ActiveTextView returns the currently-active TextView
 	ActiveTextView() *TextView
 
@@ -101,6 +134,10 @@ type Gide interface {
 	// OpenFileAtRegion opens the specified file, highlights the region and sets the cursor
 	OpenFileAtRegion(filename gi.FileName, reg textbuf.Region) (tv *TextView, ok bool)
 
+	// SaveNavPos saves the cursor position in the active text view to the
+	// navigation history, prior to jumping to another location
+	SaveNavPos()
+
 	// SaveAllCheck checks if any files have not been saved, and prompt to save them.
 	// returns true if there were unsaved files, false otherwise.
 	// cancelOpt presents an option to cancel current command,
@@ -113,9 +150,13 @@ type Gide interface {
 	SaveAllOpenNodes()
 
 	// CloseOpenNodes closes any nodes with open views (including those in directories under nodes).
-	// called prior to rename.
 	CloseOpenNodes(nodes []*FileNode)
 
+	// RenameOpenNodes updates the open nodes for files at or within orgpath
+	// having been renamed (moved) to newpath, so their buffers and views
+	// refer to the new path
+	RenameOpenNodes(orgpath, newpath string)
+
 	// LookupFun is the completion system Lookup function that makes a custom
 	// textview dialog that has option to edit resulting file.
 	LookupFun(data interface{}, text string, posLn, posCh int) (ld complete.Lookup)
@@ -126,6 +167,92 @@ type Gide interface {
 	// Symbols calls a function to parse file or package
 	Symbols()
 
+	// Todos shows the TODO comments in the project files
+	Todos()
+
+	// ScanTodos re-scans the project files for TODO comments and shows them
+	ScanTodos()
+
+	// Notify adds a notification of an event of the project, shown in the
+	// Notifications panel, with given actions offered as links
+	Notify(sev ProblemSeverity, source, msg string, acts ...NotifyAction)
+
+	// LogAction logs an operation of given kind (ActionSave, ActionCmd..),
+	// name and arguments, failed if err is not nil, in the action log
+	LogAction(kind, op, args string, err error)
+
+	// SetProblems sets the problems found by given source (a command, linter
+	// or language server), replacing its previous ones, and updates the
+	// Problems panel and the marks in the open files
+	SetProblems(source string, probs []Problem)
+
+	// AddArtifacts adds given artifacts made by a build to the list shown
+	// in the Artifacts panel
+	AddArtifacts(afs []Artifact)
+
+	// ClearProblems removes all the problems
+	ClearProblems()
+
+	// NextProblem goes to the next problem after the cursor
+	NextProblem()
+
+	// PrevProblem goes to the previous problem before the cursor
+	PrevProblem()
+
+	// UpdateCoverage updates the Coverage panel and the coverage marks in
+	// the open files, after the tests are run with coverage
+	UpdateCoverage()
+
+	// ClearCoverage removes the coverage and its marks in the files
+	ClearCoverage()
+
+	// RunGenerate runs the //go:generate directive at given 0-based line of
+	// given file, with the output shown as for the other commands
+	RunGenerate(fpath string, ln int)
+
+	// RunTestAt runs the test, benchmark or subtest enclosing given 0-based
+	// line of given _test.go file, with the output shown as for the other
+	// commands
+	RunTestAt(fpath string, ln int)
+
+	// DebugTestAt runs the debugger on the test, benchmark or subtest
+	// enclosing given 0-based line of given _test.go file
+	DebugTestAt(fpath string, ln int)
+
+	// DebugTestArgs runs the debugger on the tests of the package of given
+	// _test.go file, with given args of the test binary, e.g., -test.run
+	DebugTestArgs(tstPath string, args []string)
+
+	// Fuzz shows the Fuzz panel, for the fuzz target at the cursor if any
+	Fuzz()
+
+	// SendToTerminal writes given text to the active terminal, as if typed,
+	// ending with a newline -- opening the Terminal if none
+	SendToTerminal(txt string) error
+
+	// EvalInRepl evaluates given code in the REPL console of given language,
+	// opening it if not open
+	EvalInRepl(lang filecat.Supported, code string) error
+
+	// RecycleCmdTab returns the tab of the output of the command of given
+	// name, and its buffer, making them if not found -- true if the buffer
+	// is new -- if sel, selects the tab, and if clearBuf, clears the buffer
+	RecycleCmdTab(cmdNm string, sel bool, clearBuf bool) (*giv.TextBuf, *giv.TextView, bool)
+
+	// FileHistoryPath shows the history of commits of given file
+	FileHistoryPath(fpath string)
+
+	// DiffFiles shows the differences between two given files, in the
+	// side-by-side DiffView
+	DiffFiles(fnmA, fnmB gi.FileName)
+
+	// ViewTablePath shows given delimited text file (.csv, .tsv) as a table,
+	// whose cell edits are made to its text
+	ViewTablePath(fpath string)
+
+	// SavedSearches shows the panel of saved searches
+	SavedSearches()
+
 	// Debug runs debugger on default exe
 	Debug()
 
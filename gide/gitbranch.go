@@ -0,0 +1,84 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// GitOutput runs git with given args in given directory, returning its
+// trimmed output -- the error includes the output of git if it failed
+func GitOutput(dir string, args ...string) (string, error) {
+	out, err := GitRun(dir, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// GitRun runs git with given args in given directory, returning its output
+// as is -- the error includes the output of git if it failed
+func GitRun(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = AskPassCmdEnv()
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git %v: %v: %v", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// GitCurBranch returns the current branch of the git repository that given
+// directory is in -- for a detached HEAD, the short commit id in parens
+func GitCurBranch(dir string) (string, error) {
+	br, err := GitOutput(dir, "symbolic-ref", "--short", "-q", "HEAD")
+	if err == nil && br != "" {
+		return br, nil
+	}
+	id, err := GitOutput(dir, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return "(" + id + ")", nil
+}
+
+// GitBranches returns the sorted local branches of the git repository that
+// given directory is in, and the branches of its remotes that have no
+// local branch of the same name, without the remote name (checking one of
+// those out creates a local branch tracking it)
+func GitBranches(dir string) (local, remote []string, err error) {
+	out, err := GitOutput(dir, "for-each-ref", "--format=%(refname)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, nil, err
+	}
+	have := map[string]bool{}
+	for _, ref := range strings.Split(out, "\n") {
+		if br := strings.TrimPrefix(ref, "refs/heads/"); br != ref {
+			local = append(local, br)
+			have[br] = true
+		}
+	}
+	rhave := map[string]bool{}
+	for _, ref := range strings.Split(out, "\n") {
+		rb := strings.TrimPrefix(ref, "refs/remotes/")
+		if rb == ref {
+			continue
+		}
+		si := strings.Index(rb, "/")
+		if si < 0 {
+			continue
+		}
+		br := rb[si+1:]
+		if br == "HEAD" || have[br] || rhave[br] {
+			continue
+		}
+		remote = append(remote, br)
+		rhave[br] = true
+	}
+	sort.Strings(local)
+	sort.Strings(remote)
+	return local, remote, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GitTags returns the tags of the git repository that given directory is
+// in, highest version first
+func GitTags(dir string) ([]string, error) {
+	out, err := GitOutput(dir, "tag", "--list", "--sort=-v:refname")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// GitTagsDesc returns the list of tags of the git repository that given
+// directory is in, highest version first, each with the first line of its
+// message (or commit subject)
+func GitTagsDesc(dir string) (string, error) {
+	return GitOutput(dir, "tag", "--list", "-n1", "--sort=-v:refname")
+}
+
+// SemVer is a semantic version, as in a release tag: v1.2.3
+type SemVer struct {
+	Prefix string `desc:"text before the version numbers, e.g., v"`
+	Major  int    `desc:"major version, for incompatible changes"`
+	Minor  int    `desc:"minor version, for added functionality"`
+	Patch  int    `desc:"patch version, for fixes"`
+	Pre    string `desc:"pre-release suffix, e.g., -rc1 -- empty for a release"`
+}
+
+// SemVerRe matches a semantic version tag, with an optional prefix (such
+// as v or a module subdirectory path/v) and pre-release or build suffix
+var SemVerRe = regexp.MustCompile(`^(.*?)(\d+)\.(\d+)\.(\d+)([-+].*)?$`)
+
+// ParseSemVer parses given tag as a semantic version, false if it is not one
+func ParseSemVer(tag string) (SemVer, bool) {
+	m := SemVerRe.FindStringSubmatch(tag)
+	if m == nil {
+		return SemVer{}, false
+	}
+	sv := SemVer{Prefix: m[1], Pre: m[5]}
+	sv.Major, _ = strconv.Atoi(m[2])
+	sv.Minor, _ = strconv.Atoi(m[3])
+	sv.Patch, _ = strconv.Atoi(m[4])
+	return sv, true
+}
+
+// String returns the version as a tag
+func (sv SemVer) String() string {
+	return fmt.Sprintf("%v%d.%d.%d%v", sv.Prefix, sv.Major, sv.Minor, sv.Patch, sv.Pre)
+}
+
+// Less returns true if the version is before the other one (a pre-release
+// is before the release of the same version)
+func (sv SemVer) Less(o SemVer) bool {
+	switch {
+	case sv.Major != o.Major:
+		return sv.Major < o.Major
+	case sv.Minor != o.Minor:
+		return sv.Minor < o.Minor
+	case sv.Patch != o.Patch:
+		return sv.Patch < o.Patch
+	}
+	return sv.Pre != "" && o.Pre == ""
+}
+
+// Next returns the next patch, minor and major release versions
+func (sv SemVer) Next() (patch, minor, major SemVer) {
+	patch = SemVer{Prefix: sv.Prefix, Major: sv.Major, Minor: sv.Minor, Patch: sv.Patch + 1}
+	if sv.Pre != "" { // release of the pre-release version
+		patch.Patch = sv.Patch
+	}
+	minor = SemVer{Prefix: sv.Prefix, Major: sv.Major, Minor: sv.Minor + 1}
+	major = SemVer{Prefix: sv.Prefix, Major: sv.Major + 1}
+	return
+}
+
+// LatestSemVer returns the highest semantic version of given tags -- only
+// those with the most common prefix among them are considered, so that
+// tags of submodules (path/v1.0.0) do not mix with the main ones -- false
+// if none
+func LatestSemVer(tags []string) (SemVer, bool) {
+	var svs []SemVer
+	npre := map[string]int{}
+	for _, tag := range tags {
+		if sv, ok := ParseSemVer(tag); ok {
+			svs = append(svs, sv)
+			npre[sv.Prefix]++
+		}
+	}
+	if len(svs) == 0 {
+		return SemVer{}, false
+	}
+	prefix := svs[0].Prefix
+	for p, n := range npre {
+		if n > npre[prefix] || (n == npre[prefix] && p < prefix) {
+			prefix = p
+		}
+	}
+	var last SemVer
+	have := false
+	for _, sv := range svs {
+		if sv.Prefix == prefix && (!have || last.Less(sv)) {
+			last = sv
+			have = true
+		}
+	}
+	return last, true
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"testing"
+)
+
+func TestParseSemVer(t *testing.T) {
+	tests := []struct {
+		tag string
+		ok  bool
+		sv  SemVer
+	}{
+		{"v1.2.3", true, SemVer{Prefix: "v", Major: 1, Minor: 2, Patch: 3}},
+		{"0.10.0", true, SemVer{Major: 0, Minor: 10}},
+		{"v2.0.0-rc1", true, SemVer{Prefix: "v", Major: 2, Pre: "-rc1"}},
+		{"sub/mod/v1.4.0", true, SemVer{Prefix: "sub/mod/v", Major: 1, Minor: 4}},
+		{"v1.2", false, SemVer{}},
+		{"latest", false, SemVer{}},
+	}
+	for _, tst := range tests {
+		sv, ok := ParseSemVer(tst.tag)
+		if ok != tst.ok || sv != tst.sv {
+			t.Errorf("ParseSemVer error: %v: should have been: %v %+v  was: %v %+v\n", tst.tag, tst.ok, tst.sv, ok, sv)
+		}
+		if ok && sv.String() != tst.tag {
+			t.Errorf("SemVer String error: should have been: %v  was: %v\n", tst.tag, sv.String())
+		}
+	}
+}
+
+func TestSemVerLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		less bool
+	}{
+		{"v1.2.3", "v1.2.4", true},
+		{"v1.2.4", "v1.2.3", false},
+		{"v1.2.9", "v1.10.0", true},
+		{"v1.9.9", "v2.0.0", true},
+		{"v2.0.0-rc1", "v2.0.0", true},
+		{"v2.0.0", "v2.0.0-rc1", false},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.4-rc1", "v1.2.3", false},
+	}
+	for _, tst := range tests {
+		a, _ := ParseSemVer(tst.a)
+		b, _ := ParseSemVer(tst.b)
+		if less := a.Less(b); less != tst.less {
+			t.Errorf("SemVer Less error: %v < %v: should have been: %v  was: %v\n", tst.a, tst.b, tst.less, less)
+		}
+	}
+}
+
+func TestSemVerNext(t *testing.T) {
+	tests := []struct {
+		tag                 string
+		patch, minor, major string
+	}{
+		{"v1.2.3", "v1.2.4", "v1.3.0", "v2.0.0"},
+		{"v2.0.0-rc1", "v2.0.0", "v2.1.0", "v3.0.0"},
+		{"0.1.9", "0.1.10", "0.2.0", "1.0.0"},
+	}
+	for _, tst := range tests {
+		sv, _ := ParseSemVer(tst.tag)
+		patch, minor, major := sv.Next()
+		if patch.String() != tst.patch || minor.String() != tst.minor || major.String() != tst.major {
+			t.Errorf("SemVer Next error: %v: should have been: %v %v %v  was: %v %v %v\n", tst.tag, tst.patch, tst.minor, tst.major, patch, minor, major)
+		}
+	}
+}
+
+func TestLatestSemVer(t *testing.T) {
+	tests := []struct {
+		tags   []string
+		ok     bool
+		latest string
+	}{
+		{[]string{"v1.2.3", "v1.10.0", "v1.9.0"}, true, "v1.10.0"},
+		{[]string{"v2.0.0-rc2", "v1.5.0", "v2.0.0-rc1"}, true, "v2.0.0-rc2"},
+		{[]string{"v2.0.0-rc1", "v2.0.0"}, true, "v2.0.0"},
+		{[]string{"v1.0.0", "v1.1.0", "sub/v3.0.0"}, true, "v1.1.0"},
+		{[]string{"nightly", "v1.0.0"}, true, "v1.0.0"},
+		{[]string{"nightly"}, false, ""},
+		{nil, false, ""},
+	}
+	for _, tst := range tests {
+		sv, ok := LatestSemVer(tst.tags)
+		if ok != tst.ok || (ok && sv.String() != tst.latest) {
+			t.Errorf("LatestSemVer error: %v: should have been: %v %v  was: %v %v\n", tst.tags, tst.ok, tst.latest, ok, sv)
+		}
+	}
+}
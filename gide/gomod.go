@@ -0,0 +1,107 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// GoModInfo is the layout of a Go module, as detected when a project is
+// opened (see DetectGoModule)
+type GoModInfo struct {
+	Root     string   `desc:"directory of the go.mod file"`
+	ModPath  string   `desc:"module path declared in the go.mod file"`
+	MainPkgs []string `desc:"directories of the main packages in the module (not including nested modules), with the default one first"`
+}
+
+// DetectGoModule returns the layout of the Go module that given project
+// root directory is in (or is the root of) -- ok is false if there is none
+func DetectGoModule(projRoot string) (*GoModInfo, bool) {
+	root, modpath, ok := GoModule(projRoot)
+	if !ok {
+		return nil, false
+	}
+	mi := &GoModInfo{Root: root, ModPath: modpath}
+	mi.MainPkgs = GoMainPkgs(projRoot)
+	mi.SortMainPkgs()
+	return mi, true
+}
+
+// GoMainPkgs returns the directories within given directory (and it) that
+// have a main package, skipping the directories that are not part of a
+// module (see GoSkipDir) and nested modules
+func GoMainPkgs(dir string) []string {
+	var pkgs []string
+	filepath.Walk(dir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if pth != dir {
+			if GoSkipDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(pth, "go.mod")); err == nil {
+				return filepath.SkipDir // nested module
+			}
+		}
+		if GoDirPkgName(pth, "") == "main" {
+			pkgs = append(pkgs, pth)
+		}
+		return nil
+	})
+	return pkgs
+}
+
+// SortMainPkgs sorts the MainPkgs so the default one is first: the module
+// root if it is a main package, then cmd/<last element of module path>,
+// and then the rest in order of depth, then name
+func (mi *GoModInfo) SortMainPkgs() {
+	rank := func(dir string) int {
+		switch {
+		case dir == mi.Root:
+			return 0
+		case dir == filepath.Join(mi.Root, "cmd", path.Base(mi.ModPath)):
+			return 1
+		}
+		return 2
+	}
+	sort.SliceStable(mi.MainPkgs, func(i, j int) bool {
+		pi, pj := mi.MainPkgs[i], mi.MainPkgs[j]
+		if ri, rj := rank(pi), rank(pj); ri != rj {
+			return ri < rj
+		}
+		if di, dj := strings.Count(pi, string(filepath.Separator)), strings.Count(pj, string(filepath.Separator)); di != dj {
+			return di < dj
+		}
+		return pi < pj
+	})
+}
+
+// MainPkg returns the directory of the default main package, "" if none
+func (mi *GoModInfo) MainPkg() string {
+	if len(mi.MainPkgs) == 0 {
+		return ""
+	}
+	return mi.MainPkgs[0]
+}
+
+// GoExecPath returns the path of the executable that go build makes for
+// the main package in given directory of the module: named for the last
+// element of its import path, in that directory
+func (mi *GoModInfo) GoExecPath(dir string) string {
+	nm := path.Base(mi.ModPath)
+	if imp, ok := GoImportPath(mi.Root, mi.ModPath, dir); ok {
+		nm = path.Base(imp)
+	}
+	if runtime.GOOS == "windows" {
+		nm += ".exe"
+	}
+	return filepath.Join(dir, nm)
+}
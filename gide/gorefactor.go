@@ -0,0 +1,323 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+)
+
+// GoModule returns the directory and module path of the go.mod file for
+// given directory, searching up from it -- ok is false if none
+func GoModule(dir string) (root, modpath string, ok bool) {
+	for d := dir; ; d = filepath.Dir(d) {
+		if b, err := ioutil.ReadFile(filepath.Join(d, "go.mod")); err == nil {
+			modpath = GoModPath(b)
+			return d, modpath, modpath != ""
+		}
+		if filepath.Dir(d) == d {
+			return "", "", false
+		}
+	}
+}
+
+// GoModPath returns the module path in given go.mod file contents
+func GoModPath(src []byte) string {
+	for _, ln := range strings.Split(string(src), "\n") {
+		flds := strings.Fields(ln)
+		if len(flds) >= 2 && flds[0] == "module" {
+			return strings.Trim(flds[1], "\"`")
+		}
+	}
+	return ""
+}
+
+// GoImportPath returns the import path of the package in given directory,
+// within the module at given root with given module path
+func GoImportPath(root, modpath, dir string) (string, bool) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || RelOutside(rel) {
+		return "", false
+	}
+	if rel == "." {
+		return modpath, true
+	}
+	return modpath + "/" + filepath.ToSlash(rel), true
+}
+
+// GoSkipDir returns true if given directory (name) within a module is not
+// part of the module's packages for refactoring: hidden, vendor, testdata
+func GoSkipDir(nm string) bool {
+	return strings.HasPrefix(nm, ".") || strings.HasPrefix(nm, "_") || nm == "vendor" || nm == "testdata"
+}
+
+// GoFilePkgName returns the package name in the package clause of given
+// Go file, "" if it cannot be parsed
+func GoFilePkgName(fpath string) string {
+	f, err := parser.ParseFile(token.NewFileSet(), fpath, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return f.Name.Name
+}
+
+// GoDirPkgName returns the package name of the (non-test) Go files in
+// given directory, other than the skip file, "" if none
+func GoDirPkgName(dir, skip string) string {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, fi := range fis {
+		nm := fi.Name()
+		fp := filepath.Join(dir, nm)
+		if fi.IsDir() || fp == skip || filepath.Ext(nm) != ".go" || strings.HasSuffix(nm, "_test.go") {
+			continue
+		}
+		if pkg := GoFilePkgName(fp); pkg != "" {
+			return pkg
+		}
+	}
+	return ""
+}
+
+// GoHasFiles returns true if there are any Go files in given directory or
+// its subdirectories
+func GoHasFiles(dir string) bool {
+	has := false
+	filepath.Walk(dir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if has {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && filepath.Ext(pth) == ".go" {
+			has = true
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return has
+}
+
+// GoSetPkgName sets the package clause of given Go file to given package
+// name, keeping the _test suffix of external test packages -- returns true
+// if changed
+func GoSetPkgName(fpath, pkg string) (bool, error) {
+	src, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return false, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fpath, src, parser.PackageClauseOnly)
+	if err != nil {
+		return false, err
+	}
+	cur := f.Name.Name
+	if strings.HasSuffix(cur, "_test") && !strings.HasSuffix(pkg, "_test") {
+		pkg += "_test"
+	}
+	if cur == pkg {
+		return false, nil
+	}
+	st := fset.Position(f.Name.Pos()).Offset
+	ed := fset.Position(f.Name.End()).Offset
+	out := append(append(append([]byte{}, src[:st]...), pkg...), src[ed:]...)
+	return true, WriteSameMode(fpath, out)
+}
+
+// WriteSameMode writes given contents to an existing file, keeping its mode
+func WriteSameMode(fpath string, b []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(fpath); err == nil {
+		mode = info.Mode()
+	}
+	return ioutil.WriteFile(fpath, b, mode)
+}
+
+// GoFileRenameImports replaces imports of package oldimp, and of packages
+// within it, with newimp in given Go file -- returns true if changed
+func GoFileRenameImports(fpath, oldimp, newimp string) (bool, error) {
+	src, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return false, err
+	}
+	out, err := GoSrcRenameImports(fpath, src, oldimp, newimp)
+	if out == nil || err != nil {
+		return false, err
+	}
+	return true, WriteSameMode(fpath, out)
+}
+
+// GoSrcRenameImports returns given source of given Go file with the imports
+// of package oldimp, and of packages within it, replaced with newimp -- nil
+// if it has none
+func GoSrcRenameImports(fpath string, src []byte, oldimp, newimp string) ([]byte, error) {
+	if !bytes.Contains(src, []byte("\""+oldimp)) {
+		return nil, nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fpath, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+	var out []byte
+	last := 0
+	for _, is := range f.Imports {
+		val, err := strconv.Unquote(is.Path.Value)
+		if err != nil || (val != oldimp && !strings.HasPrefix(val, oldimp+"/")) {
+			continue
+		}
+		st := fset.Position(is.Path.Pos()).Offset
+		ed := fset.Position(is.Path.End()).Offset
+		out = append(out, src[last:st]...)
+		out = append(out, strconv.Quote(newimp+val[len(oldimp):])...)
+		last = ed
+	}
+	if out == nil {
+		return nil, nil
+	}
+	return append(out, src[last:]...), nil
+}
+
+// GoRenameImports replaces imports of package oldimp, and of packages
+// within it, with newimp in all the Go files of the module at given root
+// (not including nested modules), other than those for which skip (if
+// non-nil) returns true, e.g., files with unsaved changes in their buffers
+// -- returns the files changed, and the skipped files that import them
+func GoRenameImports(root, oldimp, newimp string, skip func(fpath string) bool) (files, skipped []string, err error) {
+	var errs []string
+	filepath.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if pth == root {
+				return nil
+			}
+			if GoSkipDir(info.Name()) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(pth, "go.mod")); err == nil {
+				return filepath.SkipDir // nested module
+			}
+			return nil
+		}
+		if filepath.Ext(pth) != ".go" {
+			return nil
+		}
+		if skip != nil && skip(pth) {
+			if src, err := ioutil.ReadFile(pth); err == nil {
+				if out, _ := GoSrcRenameImports(pth, src, oldimp, newimp); out != nil {
+					skipped = append(skipped, pth)
+				}
+			}
+			return nil
+		}
+		chg, err := GoFileRenameImports(pth, oldimp, newimp)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		if chg {
+			files = append(files, pth)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	if len(errs) > 0 {
+		return files, skipped, fmt.Errorf("%v", strings.Join(errs, "\n"))
+	}
+	return files, skipped, nil
+}
+
+// GoRenameRefs offers to update the Go references for a file or directory
+// having been renamed (moved) from orgpath to newpath: for a Go file moved
+// to another directory, its package clause is set to the package of that
+// directory, and for a directory of Go packages, their import paths are
+// updated in all the Go files of the module.  Updated files open in ge
+// are reverted to show the changes -- files with unsaved changes are not
+// updated, and are listed in the status bar.
+func GoRenameRefs(ge Gide, orgpath, newpath string, isDir bool) {
+	root, modpath, ok := GoModule(filepath.Dir(newpath))
+	if !ok {
+		return
+	}
+	unsaved := func(fpath string) bool {
+		fn := ge.FileNodeForFile(fpath, false)
+		return fn != nil && fn.Buf != nil && fn.Buf.IsChanged()
+	}
+	var prompt string
+	var update func() ([]string, []string, error)
+	if isDir {
+		oimp, ook := GoImportPath(root, modpath, orgpath)
+		nimp, nok := GoImportPath(root, modpath, newpath)
+		if !ook || !nok || !GoHasFiles(newpath) {
+			return
+		}
+		prompt = fmt.Sprintf("Update the import paths of package <code>%v</code> (and packages within it) to <code>%v</code>, in all the Go files of module <code>%v</code>?", oimp, nimp, modpath)
+		update = func() ([]string, []string, error) {
+			return GoRenameImports(root, oimp, nimp, unsaved)
+		}
+	} else {
+		if filepath.Ext(newpath) != ".go" || filepath.Dir(orgpath) == filepath.Dir(newpath) {
+			return
+		}
+		pkg := GoDirPkgName(filepath.Dir(newpath), newpath)
+		cur := GoFilePkgName(newpath)
+		if pkg == "" || cur == "" || cur == pkg || cur == pkg+"_test" {
+			return
+		}
+		prompt = fmt.Sprintf("Set the package clause of <code>%v</code> to package <code>%v</code> of the directory it was moved to?  Note: references to its declarations from other packages are not updated.", filepath.Base(newpath), pkg)
+		update = func() ([]string, []string, error) {
+			if unsaved(newpath) {
+				return nil, []string{newpath}, nil
+			}
+			chg, err := GoSetPkgName(newpath, pkg)
+			if chg {
+				return []string{newpath}, nil, err
+			}
+			return nil, nil, err
+		}
+	}
+	gi.PromptDialog(ge.VPort(), gi.DlgOpts{Title: "Update Go References", Prompt: prompt}, gi.AddOk, gi.AddCancel,
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			files, skipped, err := update()
+			ge.LogAction(ActionRefactor, "update Go references", fmt.Sprintf("%v -> %v: %d files", orgpath, newpath, len(files)), err)
+			if err != nil {
+				log.Println(err)
+			}
+			for _, f := range files {
+				if fn := ge.FileNodeForFile(f, false); fn != nil && fn.Buf != nil && !fn.Buf.IsChanged() {
+					RevertBuf(fn.Buf)
+				}
+			}
+			if len(skipped) > 0 {
+				for i, f := range skipped {
+					if rel, err := filepath.Rel(root, f); err == nil {
+						skipped[i] = filepath.ToSlash(rel)
+					}
+				}
+				ge.SetStatus(fmt.Sprintf("updated Go references in %d files -- not in files with unsaved changes: %v", len(files), strings.Join(skipped, ", ")))
+				return
+			}
+			ge.SetStatus(fmt.Sprintf("updated Go references in %d files", len(files)))
+		})
+}
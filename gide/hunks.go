@@ -0,0 +1,185 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/pi/lex"
+)
+
+// Hunk is a contiguous change between an original version of a file (in
+// the repository) and its current text, in lines -- ends are exclusive
+type Hunk struct {
+	OrigSt int `desc:"first line of the original text"`
+	OrigEd int `desc:"end line of the original text"`
+	CurSt  int `desc:"first line of the current text"`
+	CurEd  int `desc:"end line of the current text"`
+}
+
+// LineHunk returns the hunk of changes from orig to cur lines that contains
+// given line of cur -- for lines deleted from orig, the line at or before
+// where they were -- false if that line is not changed
+func LineHunk(orig, cur []string, ln int) (Hunk, bool) {
+	for _, df := range textbuf.DiffLines(orig, cur) {
+		if df.Tag == 'e' {
+			continue
+		}
+		if (ln >= df.J1 && ln < df.J2) || (df.J1 == df.J2 && (ln == df.J1 || ln == df.J1-1)) {
+			return Hunk{OrigSt: df.I1, OrigEd: df.I2, CurSt: df.J1, CurEd: df.J2}, true
+		}
+	}
+	return Hunk{}, false
+}
+
+// ApplyHunk returns the orig lines with given hunk of changes to cur applied
+func ApplyHunk(orig, cur []string, hk Hunk) []string {
+	nw := make([]string, 0, len(orig)+hk.CurEd-hk.CurSt)
+	nw = append(nw, orig[:hk.OrigSt]...)
+	nw = append(nw, cur[hk.CurSt:hk.CurEd]...)
+	return append(nw, orig[hk.OrigEd:]...)
+}
+
+// HunkRepo returns the repository of the file of the view, and the path
+// of the file relative to its root
+func (tv *TextView) HunkRepo() (VcsRepo, string, error) {
+	if tv.Buf == nil || tv.Buf.Filename == "" {
+		return nil, "", fmt.Errorf("no file")
+	}
+	fname := string(tv.Buf.Filename)
+	repo, err := NewVcsRepo(filepath.Dir(fname))
+	if err != nil {
+		return nil, "", err
+	}
+	rel, err := filepath.Rel(repo.Root(), fname)
+	if err != nil {
+		return nil, "", err
+	}
+	return repo, filepath.ToSlash(rel), nil
+}
+
+// OrigHunk returns the lines of the file in the repository at given
+// revision (the last commit if empty, : for the git index), with the line
+// endings of the buffer (see ToBufLineEnds), and the hunk of changes from
+// them containing given line of the view -- crlf is true if the CR of the
+// lines were removed, to be added back when writing them to the repository
+func (tv *TextView) OrigHunk(repo VcsRepo, fpath, rev string, ln int) (orig []string, hk Hunk, crlf bool, err error) {
+	src, err := repo.FileContents(fpath, rev)
+	if err != nil {
+		return nil, Hunk{}, false, err
+	}
+	src, crlf = ToBufLineEnds(src, BufLineEnds(tv.Buf))
+	orig = strings.Split(string(src), "\n")
+	hk, ok := LineHunk(orig, tv.Buf.Strings(false), ln)
+	if !ok {
+		return nil, Hunk{}, false, fmt.Errorf("line %d is not changed", ln+1)
+	}
+	return orig, hk, crlf, nil
+}
+
+// HunkError shows the error of a hunk action in the status bar, if in a
+// gide, else in a dialog
+func (tv *TextView) HunkError(act string, err error) {
+	msg := fmt.Sprintf("%v: %v", act, err)
+	if ge, ok := ParentGide(tv); ok {
+		ge.SetStatus(msg)
+		return
+	}
+	gi.PromptDialog(tv.Viewport, gi.DlgOpts{Title: act + " Failed", Prompt: msg}, gi.AddOk, gi.NoCancel, nil, nil)
+}
+
+// StageHunk stages the hunk of changes (relative to the index) that
+// contains given line, as it is in the view, leaving the other changes of
+// the file unstaged (git only)
+func (tv *TextView) StageHunk(ln int) {
+	repo, fpath, err := tv.HunkRepo()
+	if err != nil {
+		tv.HunkError("Stage Hunk", err)
+		return
+	}
+	sr, ok := repo.(VcsStager)
+	if !ok {
+		tv.HunkError("Stage Hunk", fmt.Errorf("%v does not stage changes", repo.Vcs()))
+		return
+	}
+	orig, hk, crlf, err := tv.OrigHunk(repo, fpath, ":", ln)
+	if err != nil {
+		tv.HunkError("Stage Hunk", err)
+		return
+	}
+	nw := ApplyHunk(orig, tv.Buf.Strings(false), hk)
+	if err := sr.SetIndexContents(fpath, FromBufLineEnds([]byte(strings.Join(nw, "\n")), crlf)); err != nil {
+		tv.HunkError("Stage Hunk", err)
+		return
+	}
+	if ge, ok := ParentGide(tv); ok {
+		ge.SetStatus(fmt.Sprintf("staged lines %d-%d of %v", hk.CurSt+1, hk.CurEd, fpath))
+		ge.UpdateVcsStatus()
+	}
+}
+
+// RevertHunk replaces the hunk of changes that contains given line with
+// the text of the last commit -- it can be undone like any other edit
+func (tv *TextView) RevertHunk(ln int) {
+	repo, fpath, err := tv.HunkRepo()
+	if err != nil {
+		tv.HunkError("Revert Hunk", err)
+		return
+	}
+	orig, hk, _, err := tv.OrigHunk(repo, fpath, "", ln)
+	if err != nil {
+		tv.HunkError("Revert Hunk", err)
+		return
+	}
+	otxt := strings.Join(orig[hk.OrigSt:hk.OrigEd], "\n")
+	nln := tv.Buf.NumLines()
+	st := lex.Pos{Ln: hk.CurSt}
+	ed := lex.Pos{Ln: hk.CurEd}
+	switch {
+	case hk.CurEd < nln:
+		if hk.OrigEd > hk.OrigSt {
+			otxt += "\n"
+		}
+	case hk.CurSt > 0: // through the end: replace from the end of the line before
+		st = lex.Pos{Ln: hk.CurSt - 1, Ch: len(tv.Buf.Line(hk.CurSt - 1))}
+		ed = lex.Pos{Ln: nln - 1, Ch: len(tv.Buf.Line(nln - 1))}
+		if hk.OrigEd > hk.OrigSt {
+			otxt = "\n" + otxt
+		}
+	default:
+		ed = lex.Pos{Ln: nln - 1, Ch: len(tv.Buf.Line(nln - 1))}
+	}
+	tv.Buf.ReplaceText(st, ed, st, otxt, true, false)
+	tv.SetCursorShow(lex.Pos{Ln: hk.CurSt})
+}
+
+// CopyOrigHunk copies the text of the last commit for the hunk of changes
+// that contains given line to the clipboard
+func (tv *TextView) CopyOrigHunk(ln int) {
+	repo, fpath, err := tv.HunkRepo()
+	if err != nil {
+		tv.HunkError("Copy Original", err)
+		return
+	}
+	orig, hk, _, err := tv.OrigHunk(repo, fpath, "", ln)
+	if err != nil {
+		tv.HunkError("Copy Original", err)
+		return
+	}
+	otxt := strings.Join(orig[hk.OrigSt:hk.OrigEd], "\n")
+	if hk.OrigEd > hk.OrigSt {
+		otxt += "\n"
+	}
+	oswin.TheApp.ClipBoard(tv.ParentWindow().OSWin).Write(mimedata.NewText(otxt))
+	if ge, ok := ParentGide(tv); ok {
+		ge.SetStatus(fmt.Sprintf("copied %d original lines", hk.OrigEd-hk.OrigSt))
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineHunk(t *testing.T) {
+	orig := []string{"a", "b", "c", "d", "e", "f", "g"}
+	tests := []struct {
+		name string
+		cur  string
+		ln   int
+		ok   bool
+		hk   Hunk
+		nw   string
+	}{
+		{"unchanged", "a b c d e f g", 2, false, Hunk{}, ""},
+		{"changed", "a B c d e f g", 1, true, Hunk{1, 2, 1, 2}, "a B c d e f g"},
+		{"other line", "a B c d e f g", 3, false, Hunk{}, ""},
+		{"inserted", "a b x y c d e f g", 3, true, Hunk{2, 2, 2, 4}, "a b x y c d e f g"},
+		{"deleted at", "a b e f g", 2, true, Hunk{2, 4, 2, 2}, "a b e f g"},
+		{"deleted before", "a b e f g", 1, true, Hunk{2, 4, 2, 2}, "a b e f g"},
+		{"one of two", "A b c d e F g", 5, true, Hunk{5, 6, 5, 6}, "a b c d e F g"},
+		{"first of two", "A b c d e F g", 0, true, Hunk{0, 1, 0, 1}, "A b c d e f g"},
+		{"appended", "a b c d e f g h", 7, true, Hunk{7, 7, 7, 8}, "a b c d e f g h"},
+	}
+	for _, tst := range tests {
+		cur := strings.Fields(tst.cur)
+		hk, ok := LineHunk(orig, cur, tst.ln)
+		if ok != tst.ok || hk != tst.hk {
+			t.Errorf("LineHunk error: %v: should have been: %v %+v  was: %v %+v\n", tst.name, tst.ok, tst.hk, ok, hk)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		nw := ApplyHunk(orig, cur, hk)
+		if want := strings.Fields(tst.nw); !reflect.DeepEqual(nw, want) {
+			t.Errorf("ApplyHunk error: %v: should have been: %v  was: %v\n", tst.name, want, nw)
+		}
+	}
+}
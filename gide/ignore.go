@@ -0,0 +1,360 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// IgnoreFileName is the name of the gide-specific ignore file at the root
+// of a project, using the same syntax as .gitignore, for files and
+// directories to ignore in addition to those in .gitignore
+var IgnoreFileName = ".gideignore"
+
+// GitIgnoreFileName is the name of git ignore files, which can be in any
+// directory of a project
+var GitIgnoreFileName = ".gitignore"
+
+// IgnoreRule is one pattern from an ignore file
+type IgnoreRule struct {
+	Pattern  string `desc:"glob pattern, with / separators, and ** matching any number of directories"`
+	Base     string `desc:"directory of the ignore file the rule is from, relative to the project root, / separated ('' = root)"`
+	Negate   bool   `desc:"pattern started with ! -- un-ignores matching files"`
+	DirOnly  bool   `desc:"pattern ended with / -- only matches directories"`
+	Anchored bool   `desc:"pattern has a / other than at the end -- matched against the path relative to Base, otherwise against the file name only"`
+}
+
+// ParseIgnore returns the rules in given ignore file contents, for an
+// ignore file in given base directory
+func ParseIgnore(src []byte, base string) []IgnoreRule {
+	var rules []IgnoreRule
+	for _, ln := range strings.Split(string(src), "\n") {
+		ln = strings.TrimRight(ln, " \t\r")
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		ir := IgnoreRule{Base: base}
+		if strings.HasPrefix(ln, "!") {
+			ir.Negate = true
+			ln = ln[1:]
+		} else if strings.HasPrefix(ln, `\`) {
+			ln = ln[1:] // escaped # or !
+		}
+		if strings.HasSuffix(ln, "/") {
+			ir.DirOnly = true
+			ln = strings.TrimRight(ln, "/")
+		}
+		if strings.Contains(ln, "/") {
+			ir.Anchored = true
+			ln = strings.TrimPrefix(ln, "/")
+		}
+		if ln == "" {
+			continue
+		}
+		ir.Pattern = ln
+		rules = append(rules, ir)
+	}
+	return rules
+}
+
+// IgnoreGlobMatch returns true if given / separated glob pattern matches
+// given / separated path, where ** matches any number of path elements
+func IgnoreGlobMatch(pat, pth string) bool {
+	return ignoreSegsMatch(strings.Split(pat, "/"), strings.Split(pth, "/"))
+}
+
+func ignoreSegsMatch(ps, ns []string) bool {
+	for len(ps) > 0 {
+		if ps[0] == "**" {
+			for i := 0; i <= len(ns); i++ {
+				if ignoreSegsMatch(ps[1:], ns[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(ns) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(ps[0], ns[0]); !ok {
+			return false
+		}
+		ps = ps[1:]
+		ns = ns[1:]
+	}
+	return len(ns) == 0
+}
+
+// Match returns true if the rule matches given path, relative to the
+// project root and / separated
+func (ir *IgnoreRule) Match(rpath string, isDir bool) bool {
+	if ir.DirOnly && !isDir {
+		return false
+	}
+	if ir.Base != "" {
+		if !strings.HasPrefix(rpath, ir.Base+"/") {
+			return false
+		}
+		rpath = rpath[len(ir.Base)+1:]
+	}
+	if !ir.Anchored {
+		return IgnoreGlobMatch(ir.Pattern, path.Base(rpath))
+	}
+	return IgnoreGlobMatch(ir.Pattern, rpath)
+}
+
+// RelOutside returns true if given relative path, as returned by
+// filepath.Rel, is outside of the directory it is relative to: .. or
+// within it -- unlike a file or directory whose name starts with ..
+func RelOutside(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	return rel == ".." || strings.HasPrefix(rel, "../")
+}
+
+// IgnoreCacheKey is the key of the cached results of FileIgnore.Ignored: a
+// path relative to the root, and whether it is a directory, as the rules
+// can differ for directories
+type IgnoreCacheKey struct {
+	Rel   string
+	IsDir bool
+}
+
+// FileIgnore determines which files and directories of a project are
+// ignored according to the .gitignore files in the project (read as
+// needed), .git/info/exclude, and the IgnoreFileName file at the root.
+// Files within an ignored directory are always ignored, as is the
+// project-local trash (TrashDirName).  Hidden files (see SetFilePrefs) are
+// also ignored, and in addition are never shown in the tree view.
+type FileIgnore struct {
+	Root        string                  `desc:"root directory of the project"`
+	Top         []IgnoreRule            `desc:"rules from .git/info/exclude, the root .gitignore and IgnoreFileName, in order of increasing priority"`
+	Dirs        map[string][]IgnoreRule `desc:"rules from the .gitignore files of subdirectories, by directory relative to Root -- nil entries for directories without one"`
+	Hide        []IgnoreRule            `desc:"rules for files and directories that are hidden, from FilePrefs.HidePatterns"`
+	HideDots    bool                    `desc:"files and directories with names starting with . are hidden"`
+	FollowLinks bool                    `desc:"symbolic links to directories are followed by Walk"`
+	Cache       map[IgnoreCacheKey]bool `desc:"cached results of Ignored, by path relative to Root and whether it is a directory"`
+	Mu          sync.Mutex              `desc:"mutex protecting the maps"`
+}
+
+// Open reads the top-level ignore files for given project root, and clears
+// all cached rules and results -- call again whenever an ignore file changes
+func (fi *FileIgnore) Open(root string) {
+	fi.Mu.Lock()
+	defer fi.Mu.Unlock()
+	fi.Root = root
+	fi.Top = nil
+	for _, fn := range []string{filepath.Join(".git", "info", "exclude"), GitIgnoreFileName, IgnoreFileName} {
+		if b, err := ioutil.ReadFile(filepath.Join(root, fn)); err == nil {
+			fi.Top = append(fi.Top, ParseIgnore(b, "")...)
+		}
+	}
+	fi.Dirs = make(map[string][]IgnoreRule)
+	fi.Cache = make(map[IgnoreCacheKey]bool)
+}
+
+// SetFilePrefs sets the hidden files and directories, and whether symbolic
+// links are followed, from given file prefs, and clears the cached results
+func (fi *FileIgnore) SetFilePrefs(fp *FilePrefs) {
+	fi.Mu.Lock()
+	defer fi.Mu.Unlock()
+	fi.Hide = ParseIgnore([]byte(strings.Join(fp.HidePatterns, "\n")), "")
+	fi.HideDots = !fp.ShowHidden
+	fi.FollowLinks = fp.FollowLinks
+	fi.Cache = make(map[IgnoreCacheKey]bool)
+}
+
+// IsIgnoreFile returns true if given file is a .gitignore or IgnoreFileName
+// file, and so requires FileIgnore to be reopened when changed
+func IsIgnoreFile(fpath string) bool {
+	nm := filepath.Base(fpath)
+	return nm == GitIgnoreFileName || nm == IgnoreFileName
+}
+
+// dirRules returns the rules from the .gitignore in given subdirectory,
+// reading it if not yet read -- must be called under lock
+func (fi *FileIgnore) dirRules(rdir string) []IgnoreRule {
+	if rules, has := fi.Dirs[rdir]; has {
+		return rules
+	}
+	var rules []IgnoreRule
+	if b, err := ioutil.ReadFile(filepath.Join(fi.Root, filepath.FromSlash(rdir), GitIgnoreFileName)); err == nil {
+		rules = ParseIgnore(b, rdir)
+	}
+	fi.Dirs[rdir] = rules
+	return rules
+}
+
+// hidden returns true if given path is hidden, not considering its parent
+// directories
+func (fi *FileIgnore) hidden(rpath string, isDir bool) bool {
+	if fi.HideDots && strings.HasPrefix(path.Base(rpath), ".") {
+		return true
+	}
+	hid := false
+	for i := range fi.Hide {
+		if fi.Hide[i].Match(rpath, isDir) {
+			hid = !fi.Hide[i].Negate
+		}
+	}
+	return hid
+}
+
+// match returns true if given path is ignored by the rules that apply to
+// it, or hidden, not considering its parent directories -- must be called
+// under lock
+func (fi *FileIgnore) match(rpath string, isDir bool) bool {
+	if fi.hidden(rpath, isDir) {
+		return true
+	}
+	ign := false
+	check := func(rules []IgnoreRule) {
+		for i := range rules {
+			if rules[i].Match(rpath, isDir) {
+				ign = !rules[i].Negate
+			}
+		}
+	}
+	check(fi.Top)
+	dirs := strings.Split(rpath, "/")
+	for i := 1; i < len(dirs); i++ {
+		check(fi.dirRules(strings.Join(dirs[:i], "/")))
+	}
+	return ign
+}
+
+// Hidden returns true if given file or directory (full path) is hidden,
+// either itself or by being within a hidden directory
+func (fi *FileIgnore) Hidden(fpath string, isDir bool) bool {
+	if fi == nil {
+		return false
+	}
+	fi.Mu.Lock()
+	defer fi.Mu.Unlock()
+	if fi.Root == "" || (!fi.HideDots && len(fi.Hide) == 0) {
+		return false
+	}
+	rel, err := filepath.Rel(fi.Root, fpath)
+	if err != nil || rel == "." || RelOutside(rel) {
+		return false
+	}
+	segs := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 1; i <= len(segs); i++ {
+		if fi.hidden(strings.Join(segs[:i], "/"), isDir || i < len(segs)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Walk walks the files and directories within given root like
+// filepath.Walk, also following symbolic links to directories if
+// FollowLinks is set (see WalkFiles) -- safe to call on a nil FileIgnore
+func (fi *FileIgnore) Walk(root string, walkFn filepath.WalkFunc) error {
+	return WalkFiles(root, fi != nil && fi.FollowLinks, walkFn)
+}
+
+// WalkFiles walks the files and directories within given root like
+// filepath.Walk, and if follow is true, also walks the directories that
+// symbolic links point to, as if they were within the directory of the
+// link.  Directories already walked are not walked again, so cycles of
+// links are not followed.
+func WalkFiles(root string, follow bool, walkFn filepath.WalkFunc) error {
+	if !follow {
+		return filepath.Walk(root, walkFn)
+	}
+	real, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return filepath.Walk(root, walkFn)
+	}
+	return walkLinked(real, root, map[string]bool{}, walkFn, true)
+}
+
+// walkLinked walks the directory at given real path for WalkFiles,
+// reporting the paths within it as within dpath -- the directory itself is
+// only reported if top
+func walkLinked(real, dpath string, visited map[string]bool, walkFn filepath.WalkFunc, top bool) error {
+	return filepath.Walk(real, func(pth string, info os.FileInfo, err error) error {
+		dp := dpath + pth[len(real):]
+		if err != nil {
+			return walkFn(dp, info, err)
+		}
+		if info.IsDir() {
+			visited[pth] = true
+			if pth == real && !top {
+				return nil
+			}
+			return walkFn(dp, info, nil)
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			return walkFn(dp, info, nil)
+		}
+		tinfo, serr := os.Stat(pth)
+		if serr != nil {
+			return walkFn(dp, info, nil) // broken link
+		}
+		if !tinfo.IsDir() {
+			return walkFn(dp, tinfo, nil)
+		}
+		tpath, eerr := filepath.EvalSymlinks(pth)
+		if eerr != nil || visited[tpath] {
+			return nil
+		}
+		if ferr := walkFn(dp, tinfo, nil); ferr != nil {
+			if ferr == filepath.SkipDir {
+				return nil
+			}
+			return ferr
+		}
+		return walkLinked(tpath, dp, visited, walkFn, false)
+	})
+}
+
+// Ignored returns true if given file or directory (full path) is ignored,
+// either itself or by being within an ignored directory -- safe to call on
+// a nil FileIgnore, which ignores nothing
+func (fi *FileIgnore) Ignored(fpath string, isDir bool) bool {
+	if fi == nil {
+		return false
+	}
+	fi.Mu.Lock()
+	defer fi.Mu.Unlock()
+	if fi.Root == "" {
+		return false
+	}
+	rel, err := filepath.Rel(fi.Root, fpath)
+	if err != nil || rel == "." || RelOutside(rel) {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == TrashDirName || strings.HasPrefix(rel, TrashDirName+"/") {
+		return true
+	}
+	if ign, has := fi.Cache[IgnoreCacheKey{rel, isDir}]; has {
+		return ign
+	}
+	segs := strings.Split(rel, "/")
+	ign := false
+	for i := 1; i <= len(segs); i++ {
+		sub := strings.Join(segs[:i], "/")
+		if cign, has := fi.Cache[IgnoreCacheKey{sub, true}]; has && i < len(segs) {
+			ign = cign
+		} else {
+			ign = fi.match(sub, isDir || i < len(segs))
+			if i < len(segs) {
+				fi.Cache[IgnoreCacheKey{sub, true}] = ign
+			}
+		}
+		if ign {
+			break // everything within an ignored directory is ignored
+		}
+	}
+	fi.Cache[IgnoreCacheKey{rel, isDir}] = ign
+	return ign
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseIgnore(t *testing.T) {
+	src := "# comment\n\n*.o\n!keep.o\nbuild/\n/top.txt\ndocs/*.html\n\\#hash\ntrail \r\n/\n"
+	rules := ParseIgnore([]byte(src), "sub")
+	want := []IgnoreRule{
+		{Pattern: "*.o", Base: "sub"},
+		{Pattern: "keep.o", Base: "sub", Negate: true},
+		{Pattern: "build", Base: "sub", DirOnly: true},
+		{Pattern: "top.txt", Base: "sub", Anchored: true},
+		{Pattern: "docs/*.html", Base: "sub", Anchored: true},
+		{Pattern: "#hash", Base: "sub"},
+		{Pattern: "trail", Base: "sub"},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseIgnore error: should have been: %+v  was: %+v\n", want, rules)
+	}
+}
+
+func TestIgnoreRuleMatch(t *testing.T) {
+	tests := []struct {
+		rule  string
+		base  string
+		rpath string
+		isDir bool
+		match bool
+	}{
+		{"*.o", "", "a.o", false, true},
+		{"*.o", "", "dir/a.o", false, true},
+		{"*.o", "", "a.go", false, false},
+		{"build/", "", "build", true, true},
+		{"build/", "", "build", false, false},
+		{"build/", "", "x/build", true, true},
+		{"/top.txt", "", "top.txt", false, true},
+		{"/top.txt", "", "x/top.txt", false, false},
+		{"docs/*.html", "", "docs/a.html", false, true},
+		{"docs/*.html", "", "docs/x/a.html", false, false},
+		{"**/gen/*.go", "", "a/b/gen/x.go", false, true},
+		{"**/gen/*.go", "", "gen/x.go", false, true},
+		{"a/**/z", "", "a/z", false, true},
+		{"a/**/z", "", "a/b/c/z", false, true},
+		{"*.o", "sub", "sub/a.o", false, true},
+		{"*.o", "sub", "a.o", false, false},
+		{"/x.txt", "sub", "sub/x.txt", false, true},
+		{"/x.txt", "sub", "sub/y/x.txt", false, false},
+	}
+	for _, tst := range tests {
+		rules := ParseIgnore([]byte(tst.rule), tst.base)
+		if len(rules) != 1 {
+			t.Errorf("ParseIgnore error: %q should have one rule, was: %+v\n", tst.rule, rules)
+			continue
+		}
+		if m := rules[0].Match(tst.rpath, tst.isDir); m != tst.match {
+			t.Errorf("Match error: %q in %q for %q (dir %v): should have been: %v  was: %v\n", tst.rule, tst.base, tst.rpath, tst.isDir, tst.match, m)
+		}
+	}
+}
+
+func TestRelOutside(t *testing.T) {
+	tests := []struct {
+		rel string
+		out bool
+	}{
+		{"..", true},
+		{"../a", true},
+		{filepath.Join("..", "a", "b"), true},
+		{"..a", false},
+		{"..a/b", false},
+		{"a/..b", false},
+		{".", false},
+		{"a", false},
+	}
+	for _, tst := range tests {
+		if out := RelOutside(tst.rel); out != tst.out {
+			t.Errorf("RelOutside error: %q should have been: %v  was: %v\n", tst.rel, tst.out, out)
+		}
+	}
+}
+
+func TestFileIgnored(t *testing.T) {
+	root, err := ioutil.TempDir("", "gide-ignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		GitIgnoreFileName:                       "*.log\nbuild/\n!keep.log\nout\n",
+		filepath.Join("sub", GitIgnoreFileName): "*.tmp\n/local.txt\n",
+		IgnoreFileName:                          "secret.txt\n",
+	}
+	for fn, src := range files {
+		os.MkdirAll(filepath.Join(root, filepath.Dir(fn)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(root, fn), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fi := &FileIgnore{}
+	fi.Open(root)
+	fi.SetFilePrefs(&FilePrefs{ShowHidden: true, HidePatterns: []string{"*.pb.go"}})
+	tests := []struct {
+		rpath string
+		isDir bool
+		ign   bool
+	}{
+		{"a.log", false, true},
+		{"keep.log", false, false},
+		{"a.go", false, false},
+		{"build", true, true},
+		{"build", false, false}, // same path as a file: not cached as the dir
+		{"build/x.go", false, true},
+		{"out", false, true},
+		{"out/x.go", false, true},
+		{"sub/a.tmp", false, true},
+		{"a.tmp", false, false},
+		{"sub/local.txt", false, true},
+		{"sub/x/local.txt", false, false},
+		{"secret.txt", false, true},
+		{"x/y.pb.go", false, true},
+		{".hidden", false, false}, // ShowHidden
+		{TrashDirName + "/x.go", false, true},
+		{"..x", false, false},
+	}
+	for pass := 0; pass < 2; pass++ { // second pass is from the cache
+		for _, tst := range tests {
+			fpath := filepath.Join(root, filepath.FromSlash(tst.rpath))
+			if ign := fi.Ignored(fpath, tst.isDir); ign != tst.ign {
+				t.Errorf("Ignored error: %q (dir %v, pass %d): should have been: %v  was: %v\n", tst.rpath, tst.isDir, pass, tst.ign, ign)
+			}
+		}
+	}
+	if fi.Ignored(filepath.Join(root, "..", "a.log"), false) {
+		t.Errorf("Ignored error: a file outside of the root should not be ignored\n")
+	}
+	fi.SetFilePrefs(&FilePrefs{})
+	if !fi.Ignored(filepath.Join(root, ".hidden"), false) || !fi.Hidden(filepath.Join(root, ".hidden", "x"), false) {
+		t.Errorf("Ignored error: hidden files should be ignored when not shown\n")
+	}
+	var nfi *FileIgnore
+	if nfi.Ignored(filepath.Join(root, "a.log"), false) {
+		t.Errorf("Ignored error: a nil FileIgnore should ignore nothing\n")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// OpenRequest is a request to open a project and / or files, as given on
+// the command line, which is sent to an already-running gide when
+// SingleInstance is set (see InstanceServer)
+type OpenRequest struct {
+	Proj  string    `desc:"project file (.gide) to open, full path"`
+	Path  string    `desc:"project directory to open, full path"`
+	Files []FilePos `desc:"files to open, at their positions, each in the project it is in"`
+}
+
+// IsEmpty returns true if nothing is requested
+func (req *OpenRequest) IsEmpty() bool {
+	return req.Proj == "" && req.Path == "" && len(req.Files) == 0
+}
+
+// ParseOpenArgs returns the OpenRequest for given command line args
+// (other than flags): a project file (.gide extension) or directory, and /
+// or any number of files, each optionally at path:line:col (see
+// ParseFilePos), or gide:// URLs (see ParseOpenURL) -- only the first
+// project or directory is used
+func ParseOpenArgs(args []string) *OpenRequest {
+	req := &OpenRequest{}
+	for _, arg := range args {
+		if IsOpenURL(arg) {
+			ureq, err := ParseOpenURL(arg)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			if req.Proj == "" && req.Path == "" {
+				req.Proj, req.Path = ureq.Proj, ureq.Path
+			}
+			req.Files = append(req.Files, ureq.Files...)
+			continue
+		}
+		if strings.ToLower(filepath.Ext(arg)) == ".gide" {
+			if req.Proj == "" && req.Path == "" {
+				req.Proj, _ = filepath.Abs(arg)
+			}
+			continue
+		}
+		fp := ParseFilePos(arg)
+		if info, err := os.Stat(fp.Path); err == nil && info.IsDir() {
+			if req.Proj == "" && req.Path == "" {
+				req.Path = fp.Path
+			}
+			continue
+		}
+		req.Files = append(req.Files, fp)
+	}
+	return req
+}
+
+// RuntimeDir returns the directory, private to the user, of the sockets
+// of gide (see InstanceSockPath, AskPassSockPath): $XDG_RUNTIME_DIR if set,
+// which is private by its spec, otherwise a directory named for the user
+// in the temp directory, created with no access for the other users --
+// an error if it exists with access for them, as they could then take the
+// sockets
+func RuntimeDir() (string, error) {
+	if rd := os.Getenv("XDG_RUNTIME_DIR"); rd != "" {
+		return rd, nil
+	}
+	usr := fmt.Sprintf("%d", os.Getuid())
+	if os.Getuid() < 0 { // windows
+		usr = os.Getenv("USERNAME")
+	}
+	dir := filepath.Join(os.TempDir(), "gide-"+usr)
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !fi.IsDir() || (runtime.GOOS != "windows" && fi.Mode().Perm()&0077 != 0) {
+		return "", fmt.Errorf("gide: %v is not a directory private to the user -- remove it", dir)
+	}
+	return dir, nil
+}
+
+// InstanceSockPath returns the path of the local socket that the running
+// gide listens on for OpenRequests, in the RuntimeDir
+func InstanceSockPath() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gide.sock"), nil
+}
+
+// InstanceTimeout is how long to wait for a running gide to accept an
+// OpenRequest, before starting a new one
+var InstanceTimeout = 2 * time.Second
+
+// SendOpenRequest sends given request to the running gide, if there is one
+// -- returns an error if there is none, or it did not accept the request
+func SendOpenRequest(req *OpenRequest) error {
+	sock, err := InstanceSockPath()
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("unix", sock, InstanceTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(InstanceTimeout))
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if reply = strings.TrimSpace(reply); reply != "ok" {
+		return fmt.Errorf("gide: open request failed: %v", reply)
+	}
+	return nil
+}
+
+// InstanceServer listens on the InstanceSockPath socket for OpenRequests
+// from other invocations of gide (or gide-open), so they open their files
+// in this one, in the matching project window
+type InstanceServer struct {
+	Listener net.Listener           `desc:"the socket listener"`
+	OpenFunc func(req *OpenRequest) `desc:"function that performs the requests -- called on the goroutine of the connection, so it must hand them over to the event loop of a window"`
+	Path     string                 `desc:"path of the socket"`
+}
+
+// StartInstanceServer starts listening for OpenRequests, calling given
+// function for each (see OpenFunc) -- returns an error if another gide is already listening
+func StartInstanceServer(openFunc func(req *OpenRequest)) (*InstanceServer, error) {
+	sock, err := InstanceSockPath()
+	if err != nil {
+		return nil, err
+	}
+	is := &InstanceServer{OpenFunc: openFunc, Path: sock}
+	if _, err := os.Stat(is.Path); err == nil {
+		if conn, err := net.DialTimeout("unix", is.Path, InstanceTimeout); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("gide: another instance is listening on: %v", is.Path)
+		}
+		os.Remove(is.Path) // stale, from a gide that did not exit cleanly
+	}
+	ln, err := net.Listen("unix", is.Path)
+	if err != nil {
+		return nil, err
+	}
+	os.Chmod(is.Path, 0600)
+	is.Listener = ln
+	go is.Serve()
+	return is, nil
+}
+
+// Serve accepts and handles connections until the listener is closed
+func (is *InstanceServer) Serve() {
+	for {
+		conn, err := is.Listener.Accept()
+		if err != nil {
+			return
+		}
+		go is.Handle(conn)
+	}
+}
+
+// Handle reads one OpenRequest from given connection, replies ok, and then
+// performs it
+func (is *InstanceServer) Handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(InstanceTimeout))
+	req := &OpenRequest{}
+	if err := json.NewDecoder(conn).Decode(req); err != nil {
+		if err == io.EOF { // just checking if we are running
+			return
+		}
+		log.Println(err)
+		fmt.Fprintf(conn, "%v\n", err)
+		return
+	}
+	fmt.Fprintf(conn, "ok\n")
+	is.OpenFunc(req)
+}
+
+// Stop stops listening, and removes the socket
+func (is *InstanceServer) Stop() {
+	if is == nil || is.Listener == nil {
+		return
+	}
+	is.Listener.Close()
+	is.Listener = nil
+	os.Remove(is.Path)
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/ki/ints"
+)
+
+// JobWorkers is the maximum number of background jobs of a project run at
+// the same time -- half the number of CPUs by default, at least 2
+var JobWorkers = ints.MaxInt(runtime.NumCPU()/2, 2)
+
+// JobPriority is the priority of a background Job: the queued jobs of higher
+// priority are run first
+type JobPriority int
+
+const (
+	// JobLow is for jobs nobody is waiting for, e.g., indexing
+	JobLow JobPriority = iota
+
+	// JobNormal is for jobs updating what is shown, e.g., the version
+	// control status
+	JobNormal
+
+	// JobHigh is for jobs the user is waiting for, e.g., a search
+	JobHigh
+)
+
+// Job is a task run in the background by a JobManager
+type Job struct {
+	Name     string        `desc:"name of the job -- a job queued with the same name as one not yet started replaces it"`
+	Priority JobPriority   `desc:"priority of the job"`
+	Fun      func(jb *Job) `desc:"function running the job -- it should return soon after the job is cancelled, see IsCancelled"`
+	Seq      int           `desc:"sequence number of the job, queued jobs of the same priority are run in order"`
+	Start    time.Time     `desc:"time the job was started running"`
+	Cancel   chan struct{} `desc:"closed to cancel the job"`
+	Done     chan struct{} `desc:"closed when the job is done, or was cancelled before running"`
+}
+
+// IsCancelled returns true if the job was cancelled
+func (jb *Job) IsCancelled() bool {
+	select {
+	case <-jb.Cancel:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait waits until the job is done
+func (jb *Job) Wait() {
+	<-jb.Done
+}
+
+// JobManager runs the background jobs of a project (indexing, linting,
+// version control status, searches) on at most JobWorkers goroutines, the
+// queued ones by priority, so that they do not all run at once and pile up
+// when requested repeatedly: a job queued with the name of one not yet
+// started replaces it.  ChangeFunc is called when jobs start or end, e.g.,
+// to show the background activity.
+type JobManager struct {
+	Queue      []*Job     `desc:"jobs waiting to run"`
+	Running    []*Job     `desc:"jobs running"`
+	Workers    int        `desc:"number of worker goroutines"`
+	Seq        int        `desc:"sequence number of the last job queued"`
+	ChangeFunc func()     `desc:"function called when jobs are queued, started or done, if set -- not from the main goroutine in general"`
+	Mu         sync.Mutex `desc:"mutex protecting the jobs"`
+}
+
+// Run queues given function to run in the background as a job of given
+// name and priority, replacing any job of the same name not yet started,
+// and returns the job
+func (jm *JobManager) Run(name string, pri JobPriority, fun func(jb *Job)) *Job {
+	jb := &Job{Name: name, Priority: pri, Fun: fun, Cancel: make(chan struct{}), Done: make(chan struct{})}
+	jm.Mu.Lock()
+	for i, qj := range jm.Queue {
+		if qj.Name == name {
+			jm.Queue = append(jm.Queue[:i], jm.Queue[i+1:]...)
+			close(qj.Cancel)
+			close(qj.Done)
+			break
+		}
+	}
+	jm.Seq++
+	jb.Seq = jm.Seq
+	jm.Queue = append(jm.Queue, jb)
+	start := jm.Workers < JobWorkers
+	if start {
+		jm.Workers++
+	}
+	jm.Mu.Unlock()
+	if start {
+		go jm.Work()
+	}
+	jm.Changed()
+	return jb
+}
+
+// Work runs the queued jobs, highest priority first, until there are none
+func (jm *JobManager) Work() {
+	for {
+		jm.Mu.Lock()
+		if len(jm.Queue) == 0 {
+			jm.Workers--
+			jm.Mu.Unlock()
+			return
+		}
+		sort.SliceStable(jm.Queue, func(i, j int) bool {
+			return jm.Queue[i].Priority > jm.Queue[j].Priority
+		})
+		jb := jm.Queue[0]
+		jm.Queue = jm.Queue[1:]
+		jb.Start = time.Now()
+		jm.Running = append(jm.Running, jb)
+		jm.Mu.Unlock()
+		jm.Changed()
+		jb.Fun(jb)
+		jm.Mu.Lock()
+		for i, rj := range jm.Running {
+			if rj == jb {
+				jm.Running = append(jm.Running[:i], jm.Running[i+1:]...)
+				break
+			}
+		}
+		jm.Mu.Unlock()
+		close(jb.Done)
+		jm.Changed()
+	}
+}
+
+// Changed calls the ChangeFunc, if set
+func (jm *JobManager) Changed() {
+	jm.Mu.Lock()
+	fun := jm.ChangeFunc
+	jm.Mu.Unlock()
+	if fun != nil {
+		fun()
+	}
+}
+
+// Cancel cancels the jobs of given name, queued or running, returning the
+// number cancelled -- running jobs end when their function returns
+func (jm *JobManager) Cancel(name string) int {
+	return jm.cancel(func(jb *Job) bool { return jb.Name == name })
+}
+
+// CancelAll cancels all the jobs, e.g., when the project is closed
+func (jm *JobManager) CancelAll() int {
+	return jm.cancel(func(jb *Job) bool { return true })
+}
+
+// cancel cancels the jobs for which fun returns true
+func (jm *JobManager) cancel(fun func(jb *Job) bool) int {
+	n := 0
+	jm.Mu.Lock()
+	var queue []*Job
+	for _, qj := range jm.Queue {
+		if !fun(qj) {
+			queue = append(queue, qj)
+			continue
+		}
+		close(qj.Cancel)
+		close(qj.Done)
+		n++
+	}
+	jm.Queue = queue
+	for _, rj := range jm.Running {
+		if fun(rj) && !rj.IsCancelled() {
+			close(rj.Cancel)
+			n++
+		}
+	}
+	jm.Mu.Unlock()
+	if n > 0 {
+		jm.Changed()
+	}
+	return n
+}
+
+// Counts returns the number of jobs running and queued
+func (jm *JobManager) Counts() (running, queued int) {
+	jm.Mu.Lock()
+	defer jm.Mu.Unlock()
+	return len(jm.Running), len(jm.Queue)
+}
+
+// Status returns a description of the jobs running and queued, "" if none
+func (jm *JobManager) Status() string {
+	jm.Mu.Lock()
+	defer jm.Mu.Unlock()
+	if len(jm.Running) == 0 && len(jm.Queue) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, rj := range jm.Running {
+		fmt.Fprintf(&sb, "%s: running for %v\n", rj.Name, time.Since(rj.Start).Round(time.Second))
+	}
+	for _, qj := range jm.Queue {
+		fmt.Fprintf(&sb, "%s: waiting\n", qj.Name)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io/ioutil"
 	"log"
 	"path/filepath"
@@ -32,28 +33,43 @@ import (
 type KeyFuns int32
 
 const (
-	KeyFunNil        KeyFuns = iota
-	KeyFunNeeds2             // special internal signal returned by KeyFun indicating need for second key
-	KeyFunNextPanel          // move to next panel to the right
-	KeyFunPrevPanel          // move to prev panel to the left
-	KeyFunFileOpen           // open a new file in active textview
-	KeyFunBufSelect          // select an open buffer to edit in active textview
-	KeyFunBufClone           // open active file in other view
-	KeyFunBufSave            // save active textview buffer to its file
-	KeyFunBufSaveAs          // save as active textview buffer to its file
-	KeyFunBufClose           // close active textview buffer
-	KeyFunExecCmd            // execute a command on active textview buffer
-	KeyFunRectCopy           // copy rectangle
-	KeyFunRectCut            // cut rectangle
-	KeyFunRectPaste          // paste rectangle
-	KeyFunRegCopy            // copy selection to named register
-	KeyFunRegPaste           // paste selection from named register
-	KeyFunCommentOut         // comment out region
-	KeyFunIndent             // indent region
-	KeyFunJump               // jump to line (same as gi.KeyFunJump)
-	KeyFunSetSplit           // set named splitter config
-	KeyFunBuildProj          // build overall project
-	KeyFunRunProj            // run overall project
+	KeyFunNil           KeyFuns = iota
+	KeyFunNeeds2                // special internal signal returned by KeyFun indicating need for second key
+	KeyFunNextPanel             // move to next panel to the right
+	KeyFunPrevPanel             // move to prev panel to the left
+	KeyFunFileOpen              // open a new file in active textview
+	KeyFunBufSelect             // select an open buffer to edit in active textview
+	KeyFunBufClone              // open active file in other view
+	KeyFunBufSave               // save active textview buffer to its file
+	KeyFunBufSaveAs             // save as active textview buffer to its file
+	KeyFunBufClose              // close active textview buffer
+	KeyFunExecCmd               // execute a command on active textview buffer
+	KeyFunRectCopy              // copy rectangle
+	KeyFunRectCut               // cut rectangle
+	KeyFunRectPaste             // paste rectangle
+	KeyFunRegCopy               // copy selection to named register
+	KeyFunRegPaste              // paste selection from named register
+	KeyFunCommentOut            // comment out region
+	KeyFunIndent                // indent region
+	KeyFunJump                  // jump to line (same as gi.KeyFunJump)
+	KeyFunSetSplit              // set named splitter config
+	KeyFunBuildProj             // build overall project
+	KeyFunRunProj               // run overall project
+	KeyFunMacroRec              // start / stop recording a keyboard macro
+	KeyFunMacroPlay             // play back the last recorded keyboard macro
+	KeyFunNextPane              // move to next editor pane
+	KeyFunPrevPane              // move to prev editor pane
+	KeyFunNavBack               // move back to previous location in navigation history
+	KeyFunNavForward            // move forward to next location in navigation history
+	KeyFunLastEdit              // move to location of last edit
+	KeyFunQuickOpen             // fuzzy find and open any file in project
+	KeyFunFindInFiles           // find / replace in all project files
+	KeyFunNextProblem           // go to next problem (error, warning) in Problems panel
+	KeyFunPrevProblem           // go to previous problem in Problems panel
+	KeyFunFocusMode             // toggle the distraction-free mode, showing just the active editor pane
+	KeyFunCmdPalette            // find and run any action of the menus by (part of) its name
+	KeyFunContextMenu           // open the context menu of the widget with the keyboard focus
+	KeyFunDescribeFocus         // announce the widget with the keyboard focus, and its panel
 	KeyFunsN
 )
 
@@ -273,6 +289,58 @@ func (km *KeySeqMap) Update(kmName KeyMapName) {
 	}
 }
 
+// Conflicts returns the conflicts of the keymap, one per line: single keys
+// starting a two-key sequence (which are never used), functions without a
+// key, and keys that shadow those of the text editing functions of the
+// active gi.KeyMap (the gide keys win)
+func (km *KeySeqMap) Conflicts() []string {
+	kms := km.ToSlice()
+	sort.Slice(kms, func(i, j int) bool {
+		if kms[i].Fun != kms[j].Fun {
+			return kms[i].Fun < kms[j].Fun
+		}
+		return kms[i].Keys.String() < kms[j].Keys.String()
+	})
+	needs2 := map[key.Chord]bool{}
+	for _, ki := range kms {
+		if ki.Keys.Key2 != "" {
+			needs2[ki.Keys.Key1] = true
+		}
+	}
+	var cfs []string
+	has := map[KeyFuns]bool{}
+	for _, ki := range kms {
+		has[ki.Fun] = true
+		if ki.Keys.Key2 == "" && needs2[ki.Keys.Key1] {
+			cfs = append(cfs, fmt.Sprintf("%v: %v starts a two-key sequence, so it is never used", ki.Fun, ki.Keys.Key1))
+		}
+		if strings.HasPrefix(string(ki.Keys.Key1), "- Not Set - ") {
+			cfs = append(cfs, fmt.Sprintf("%v: no key is set", ki.Fun))
+		}
+	}
+	for kf := KeyFunNextPanel; kf < KeyFunsN; kf++ {
+		if !has[kf] {
+			cfs = append(cfs, fmt.Sprintf("%v: no key is set", kf))
+		}
+	}
+	if gi.ActiveKeyMap == nil {
+		return cfs
+	}
+	pfx := map[key.Chord]bool{}
+	for _, ki := range kms {
+		gf, ok := (*gi.ActiveKeyMap)[ki.Keys.Key1]
+		switch {
+		case !ok:
+		case ki.Keys.Key2 == "":
+			cfs = append(cfs, fmt.Sprintf("%v: %v shadows the text editing function %v of the %v keymap", ki.Fun, ki.Keys.Key1, gf, gi.ActiveKeyMapName))
+		case !pfx[ki.Keys.Key1]:
+			pfx[ki.Keys.Key1] = true
+			cfs = append(cfs, fmt.Sprintf("two-key sequence prefix %v shadows the text editing function %v of the %v keymap", ki.Keys.Key1, gf, gi.ActiveKeyMapName))
+		}
+	}
+	return cfs
+}
+
 /////////////////////////////////////////////////////////////////////////////////
 // KeyMaps -- list of KeyMap's
 
@@ -358,7 +426,9 @@ func (km *KeyMaps) OpenPrefs() error {
 	pdir := oswin.TheApp.AppPrefsDir()
 	pnm := filepath.Join(pdir, PrefsKeyMapsFileName)
 	AvailKeyMapsChanged = false
-	return km.OpenJSON(gi.FileName(pnm))
+	err := km.OpenJSON(gi.FileName(pnm))
+	km.AddMissingStd()
+	return err
 }
 
 // SavePrefs saves KeyMaps to App standard prefs directory, using PrefsKeyMapsFileName
@@ -366,7 +436,9 @@ func (km *KeyMaps) SavePrefs() error {
 	pdir := oswin.TheApp.AppPrefsDir()
 	pnm := filepath.Join(pdir, PrefsKeyMapsFileName)
 	AvailKeyMapsChanged = false
-	return km.SaveJSON(gi.FileName(pnm))
+	err := km.SaveJSON(gi.FileName(pnm))
+	SyncPrefsSaved()
+	return err
 }
 
 // CopyFrom copies keymaps from given other map
@@ -379,6 +451,112 @@ func (km *KeyMaps) CopyFrom(cp KeyMaps) {
 	json.Unmarshal(b, km)
 }
 
+// ExportMap saves the keymap of given name to a JSON-formatted file, to
+// share it or open it with ImportMap
+func (km *KeyMaps) ExportMap(name KeyMapName, filename gi.FileName) error {
+	for _, it := range *km {
+		if it.Name == string(name) {
+			b, err := json.MarshalIndent(it, "", "  ")
+			if err != nil {
+				log.Println(err) // unlikely
+				return err
+			}
+			return ioutil.WriteFile(string(filename), b, 0644)
+		}
+	}
+	return fmt.Errorf("key map named: %v not found", name)
+}
+
+// ImportMap opens the keymap(s) of a JSON-formatted file, saved by
+// ExportMap or SaveJSON, replacing those of the same name, and adding the
+// others
+func (km *KeyMaps) ImportMap(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	var ims KeyMaps
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		err = json.Unmarshal(b, &ims)
+	} else {
+		ims = make(KeyMaps, 1)
+		err = json.Unmarshal(b, &ims[0])
+	}
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	for _, it := range ims {
+		if it.Name == "" {
+			it.Name = strings.TrimSuffix(filepath.Base(string(filename)), filepath.Ext(string(filename)))
+		}
+		if it.Map == nil {
+			it.Map = KeySeqMap{}
+		}
+		idx := -1
+		for i, ot := range *km {
+			if ot.Name == it.Name {
+				idx = i
+				break
+			}
+		}
+		if idx >= 0 {
+			(*km)[idx] = it
+		} else {
+			*km = append(*km, it)
+		}
+	}
+	AvailKeyMapsChanged = true
+	return nil
+}
+
+// AddMissingStd adds the StdKeyMaps presets not in this list, e.g., those
+// added since the list was saved in the prefs
+func (km *KeyMaps) AddMissingStd() {
+	for _, st := range StdKeyMaps {
+		has := false
+		for _, it := range *km {
+			if it.Name == st.Name {
+				has = true
+				break
+			}
+		}
+		if has {
+			continue
+		}
+		var cp KeyMaps
+		cp.CopyFrom(KeyMaps{st})
+		*km = append(*km, cp...)
+	}
+}
+
+// Conflicts returns the conflicts of all the keymaps, by keymap name
+func (km *KeyMaps) Conflicts() string {
+	var sb strings.Builder
+	for _, it := range *km {
+		cfs := it.Map.Conflicts()
+		if len(cfs) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("<b>%v</b>:<br>\n", it.Name))
+		for _, cf := range cfs {
+			sb.WriteString("&nbsp;&nbsp;" + html.EscapeString(cf) + "<br>\n")
+		}
+	}
+	return sb.String()
+}
+
+// CheckConflicts shows the conflicts of all the keymaps: keys never used,
+// functions without keys, and text editing keys shadowed by the gide keys
+func (km *KeyMaps) CheckConflicts() {
+	cfs := km.Conflicts()
+	if cfs == "" {
+		cfs = "No conflicts found"
+	}
+	gi.PromptDialog(nil, gi.DlgOpts{Title: "Key Map Conflicts", Prompt: cfs}, gi.AddOk, gi.NoCancel, nil, nil)
+}
+
 // RevertToStd reverts this map to using the StdKeyMaps that are compiled into
 // the program and have all the lastest key functions bound to standard
 // values.
@@ -431,6 +609,29 @@ var KeyMapsProps = ki.Props{
 					}},
 				},
 			}},
+			{"ImportMap", ki.Props{
+				"label": "Import Map...",
+				"desc":  "open the keymap(s) of a file, e.g., one exported by Export Map, replacing the keymaps of the same name",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"ExportMap", ki.Props{
+				"label": "Export Map...",
+				"desc":  "save one keymap to a file, to share it or import it elsewhere",
+				"Args": ki.PropSlice{
+					{"Key Map Name", ki.Props{}},
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"CheckConflicts", ki.Props{
+				"label": "Check Conflicts",
+				"desc":  "show the conflicts of the keymaps: single keys that start a two-key sequence (and so are never used), functions without a key, and keys that shadow the text editing keys",
+			}},
 			{"RevertToStd", ki.Props{
 				"desc":    "This reverts the keymaps to using the StdKeyMaps that are compiled into the program and have all the lastest key functions defined.  If you have edited your maps, and are finding things not working, it is a good idea to save your current maps and try this, or at least do ViewStdMaps to see the current standards.  <b>Your current map edits will be lost if you proceed!</b>  Continue?",
 				"confirm": true,
@@ -468,6 +669,32 @@ var KeyMapsProps = ki.Props{
 				}},
 			},
 		}},
+		{"ImportMap", ki.Props{
+			"label": "Import Map",
+			"icon":  "file-upload",
+			"desc":  "open the keymap(s) of a file, e.g., one exported by Export Map, replacing the keymaps of the same name",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"ExportMap", ki.Props{
+			"label": "Export Map",
+			"icon":  "file-download",
+			"desc":  "save one keymap to a file, to share it or import it elsewhere",
+			"Args": ki.PropSlice{
+				{"Key Map Name", ki.Props{}},
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"CheckConflicts", ki.Props{
+			"label": "Check Conflicts",
+			"icon":  "search",
+			"desc":  "show the conflicts of the keymaps: single keys that start a two-key sequence (and so are never used), functions without a key, and keys that shadow the text editing keys",
+		}},
 		{"sep-std", ki.BlankProp{}},
 		{"ViewStd", ki.Props{
 			"desc":    "Shows the standard maps that are compiled into the program and have all the lastest key functions bound to standard key chords.  Useful for comparing against custom maps.",
@@ -532,6 +759,22 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "q"}:         KeyFunMacroRec,
+		KeySeq{"Control+M", "e"}:         KeyFunMacroPlay,
+		KeySeq{"Control+M", "l"}:         KeyFunNextPane,
+		KeySeq{"Control+M", "h"}:         KeyFunPrevPane,
+		KeySeq{"Control+M", ","}:         KeyFunNavBack,
+		KeySeq{"Control+M", "."}:         KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:         KeyFunLastEdit,
+		KeySeq{"Control+M", "a"}:         KeyFunQuickOpen,
+		KeySeq{"Control+M", "d"}:         KeyFunFindInFiles,
+		KeySeq{"F8", ""}:                 KeyFunNextProblem,
+		KeySeq{"Shift+F8", ""}:           KeyFunPrevProblem,
+		KeySeq{"Control+M", "z"}:         KeyFunFocusMode,
+		KeySeq{"F1", ""}:                 KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:          KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:           KeyFunDescribeFocus,
+		KeySeq{"Meta+P", ""}:             KeyFunQuickOpen,
 	}},
 	{"MacEmacs", "Mac with emacs-style navigation -- emacs wins in conflicts", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -573,6 +816,22 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+X", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+X", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+X", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+X", "q"}:         KeyFunMacroRec,
+		KeySeq{"Control+X", "e"}:         KeyFunMacroPlay,
+		KeySeq{"Control+X", "l"}:         KeyFunNextPane,
+		KeySeq{"Control+X", "h"}:         KeyFunPrevPane,
+		KeySeq{"Control+X", ","}:         KeyFunNavBack,
+		KeySeq{"Control+X", "."}:         KeyFunNavForward,
+		KeySeq{"Control+X", "u"}:         KeyFunLastEdit,
+		KeySeq{"Control+X", "a"}:         KeyFunQuickOpen,
+		KeySeq{"Control+X", "d"}:         KeyFunFindInFiles,
+		KeySeq{"F8", ""}:                 KeyFunNextProblem,
+		KeySeq{"Shift+F8", ""}:           KeyFunPrevProblem,
+		KeySeq{"Control+X", "z"}:         KeyFunFocusMode,
+		KeySeq{"F1", ""}:                 KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:          KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:           KeyFunDescribeFocus,
+		KeySeq{"Meta+P", ""}:             KeyFunQuickOpen,
 	}},
 	{"LinuxEmacs", "Linux with emacs-style navigation -- emacs wins in conflicts", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -614,6 +873,21 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+X", "q"}:         KeyFunMacroRec,
+		KeySeq{"Control+X", "e"}:         KeyFunMacroPlay,
+		KeySeq{"Control+X", "l"}:         KeyFunNextPane,
+		KeySeq{"Control+X", "h"}:         KeyFunPrevPane,
+		KeySeq{"Control+X", ","}:         KeyFunNavBack,
+		KeySeq{"Control+X", "."}:         KeyFunNavForward,
+		KeySeq{"Control+X", "u"}:         KeyFunLastEdit,
+		KeySeq{"Control+X", "a"}:         KeyFunQuickOpen,
+		KeySeq{"Control+X", "d"}:         KeyFunFindInFiles,
+		KeySeq{"F8", ""}:                 KeyFunNextProblem,
+		KeySeq{"Shift+F8", ""}:           KeyFunPrevProblem,
+		KeySeq{"Control+X", "z"}:         KeyFunFocusMode,
+		KeySeq{"F1", ""}:                 KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:          KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:           KeyFunDescribeFocus,
 	}},
 	{"LinuxStd", "Standard Linux KeySeqMap", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -657,6 +931,22 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "q"}:         KeyFunMacroRec,
+		KeySeq{"Control+M", "e"}:         KeyFunMacroPlay,
+		KeySeq{"Control+M", "l"}:         KeyFunNextPane,
+		KeySeq{"Control+M", "h"}:         KeyFunPrevPane,
+		KeySeq{"Control+M", ","}:         KeyFunNavBack,
+		KeySeq{"Control+M", "."}:         KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:         KeyFunLastEdit,
+		KeySeq{"Control+M", "a"}:         KeyFunQuickOpen,
+		KeySeq{"Control+M", "d"}:         KeyFunFindInFiles,
+		KeySeq{"F8", ""}:                 KeyFunNextProblem,
+		KeySeq{"Shift+F8", ""}:           KeyFunPrevProblem,
+		KeySeq{"Control+M", "z"}:         KeyFunFocusMode,
+		KeySeq{"F1", ""}:                 KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:          KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:           KeyFunDescribeFocus,
+		KeySeq{"Control+P", ""}:          KeyFunQuickOpen,
 	}},
 	{"WindowsStd", "Standard Windows KeySeqMap", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -700,6 +990,22 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "q"}:         KeyFunMacroRec,
+		KeySeq{"Control+M", "e"}:         KeyFunMacroPlay,
+		KeySeq{"Control+M", "l"}:         KeyFunNextPane,
+		KeySeq{"Control+M", "h"}:         KeyFunPrevPane,
+		KeySeq{"Control+M", ","}:         KeyFunNavBack,
+		KeySeq{"Control+M", "."}:         KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:         KeyFunLastEdit,
+		KeySeq{"Control+M", "a"}:         KeyFunQuickOpen,
+		KeySeq{"Control+M", "d"}:         KeyFunFindInFiles,
+		KeySeq{"F8", ""}:                 KeyFunNextProblem,
+		KeySeq{"Shift+F8", ""}:           KeyFunPrevProblem,
+		KeySeq{"Control+M", "z"}:         KeyFunFocusMode,
+		KeySeq{"F1", ""}:                 KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:          KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:           KeyFunDescribeFocus,
+		KeySeq{"Control+P", ""}:          KeyFunQuickOpen,
 	}},
 	{"ChromeStd", "Standard chrome-browser and linux-under-chrome bindings", KeySeqMap{
 		KeySeq{"Control+Tab", ""}:        KeyFunNextPanel,
@@ -743,5 +1049,133 @@ var StdKeyMaps = KeyMaps{
 		KeySeq{"Control+M", "Control+M"}: KeyFunBuildProj,
 		KeySeq{"Control+M", "r"}:         KeyFunRunProj,
 		KeySeq{"Control+M", "Control+R"}: KeyFunRunProj,
+		KeySeq{"Control+M", "q"}:         KeyFunMacroRec,
+		KeySeq{"Control+M", "e"}:         KeyFunMacroPlay,
+		KeySeq{"Control+M", "l"}:         KeyFunNextPane,
+		KeySeq{"Control+M", "h"}:         KeyFunPrevPane,
+		KeySeq{"Control+M", ","}:         KeyFunNavBack,
+		KeySeq{"Control+M", "."}:         KeyFunNavForward,
+		KeySeq{"Control+M", "u"}:         KeyFunLastEdit,
+		KeySeq{"Control+M", "a"}:         KeyFunQuickOpen,
+		KeySeq{"Control+M", "d"}:         KeyFunFindInFiles,
+		KeySeq{"F8", ""}:                 KeyFunNextProblem,
+		KeySeq{"Shift+F8", ""}:           KeyFunPrevProblem,
+		KeySeq{"Control+M", "z"}:         KeyFunFocusMode,
+		KeySeq{"F1", ""}:                 KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:          KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:           KeyFunDescribeFocus,
+		KeySeq{"Control+P", ""}:          KeyFunQuickOpen,
+	}},
+	{"VSCode", "VSCode-like bindings (Linux / Windows) -- Control+K starts the two-key sequences", KeySeqMap{
+		KeySeq{"Control+Tab", ""}:                 KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:           KeyFunPrevPanel,
+		KeySeq{"Control+O", ""}:                   KeyFunFileOpen,
+		KeySeq{"Control+K", "Control+P"}:          KeyFunBufSelect,
+		KeySeq{"Control+\\", ""}:                  KeyFunBufClone,
+		KeySeq{"Control+S", ""}:                   KeyFunBufSave,
+		KeySeq{"Shift+Control+S", ""}:             KeyFunBufSaveAs,
+		KeySeq{"Control+W", ""}:                   KeyFunBufClose,
+		KeySeq{"Control+F4", ""}:                  KeyFunBufClose,
+		KeySeq{"Control+K", "x"}:                  KeyFunExecCmd,
+		KeySeq{"Control+K", "Alt+C"}:              KeyFunRectCopy,
+		KeySeq{"Control+K", "Alt+X"}:              KeyFunRectCut,
+		KeySeq{"Control+K", "Alt+V"}:              KeyFunRectPaste,
+		KeySeq{"Control+K", "c"}:                  KeyFunRegCopy,
+		KeySeq{"Control+K", "v"}:                  KeyFunRegPaste,
+		KeySeq{"Control+/", ""}:                   KeyFunCommentOut,
+		KeySeq{"Control+K", "Control+F"}:          KeyFunIndent,
+		KeySeq{"Control+G", ""}:                   KeyFunJump,
+		KeySeq{"Control+K", "l"}:                  KeyFunSetSplit,
+		KeySeq{"Shift+Control+B", ""}:             KeyFunBuildProj,
+		KeySeq{"Control+F5", ""}:                  KeyFunRunProj,
+		KeySeq{"Control+K", "q"}:                  KeyFunMacroRec,
+		KeySeq{"Control+K", "e"}:                  KeyFunMacroPlay,
+		KeySeq{"Control+K", "Control+RightArrow"}: KeyFunNextPane,
+		KeySeq{"Control+K", "Control+LeftArrow"}:  KeyFunPrevPane,
+		KeySeq{"Alt+LeftArrow", ""}:               KeyFunNavBack,
+		KeySeq{"Alt+RightArrow", ""}:              KeyFunNavForward,
+		KeySeq{"Control+K", "Control+Q"}:          KeyFunLastEdit,
+		KeySeq{"Control+P", ""}:                   KeyFunQuickOpen,
+		KeySeq{"Shift+Control+F", ""}:             KeyFunFindInFiles,
+		KeySeq{"F8", ""}:                          KeyFunNextProblem,
+		KeySeq{"Shift+F8", ""}:                    KeyFunPrevProblem,
+		KeySeq{"Control+K", "z"}:                  KeyFunFocusMode,
+		KeySeq{"F1", ""}:                          KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:                   KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:                    KeyFunDescribeFocus,
+	}},
+	{"Sublime", "Sublime Text-like bindings (Linux / Windows) -- Control+K starts the two-key sequences", KeySeqMap{
+		KeySeq{"Control+Tab", ""}:                 KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:           KeyFunPrevPanel,
+		KeySeq{"Control+O", ""}:                   KeyFunFileOpen,
+		KeySeq{"Control+K", "Control+B"}:          KeyFunBufSelect,
+		KeySeq{"Control+K", "n"}:                  KeyFunBufClone,
+		KeySeq{"Control+S", ""}:                   KeyFunBufSave,
+		KeySeq{"Shift+Control+S", ""}:             KeyFunBufSaveAs,
+		KeySeq{"Control+W", ""}:                   KeyFunBufClose,
+		KeySeq{"Control+K", "x"}:                  KeyFunExecCmd,
+		KeySeq{"Control+K", "Alt+C"}:              KeyFunRectCopy,
+		KeySeq{"Control+K", "Alt+X"}:              KeyFunRectCut,
+		KeySeq{"Control+K", "Alt+V"}:              KeyFunRectPaste,
+		KeySeq{"Control+K", "c"}:                  KeyFunRegCopy,
+		KeySeq{"Control+K", "v"}:                  KeyFunRegPaste,
+		KeySeq{"Control+/", ""}:                   KeyFunCommentOut,
+		KeySeq{"Control+K", "i"}:                  KeyFunIndent,
+		KeySeq{"Control+G", ""}:                   KeyFunJump,
+		KeySeq{"Control+K", "l"}:                  KeyFunSetSplit,
+		KeySeq{"Control+B", ""}:                   KeyFunBuildProj,
+		KeySeq{"Shift+Control+B", ""}:             KeyFunRunProj,
+		KeySeq{"Control+Q", ""}:                   KeyFunMacroRec,
+		KeySeq{"Shift+Control+Q", ""}:             KeyFunMacroPlay,
+		KeySeq{"Control+K", "Control+RightArrow"}: KeyFunNextPane,
+		KeySeq{"Control+K", "Control+LeftArrow"}:  KeyFunPrevPane,
+		KeySeq{"Alt+-", ""}:                       KeyFunNavBack,
+		KeySeq{"Shift+Alt+_", ""}:                 KeyFunNavForward,
+		KeySeq{"Control+K", "u"}:                  KeyFunLastEdit,
+		KeySeq{"Control+P", ""}:                   KeyFunQuickOpen,
+		KeySeq{"Shift+Control+F", ""}:             KeyFunFindInFiles,
+		KeySeq{"F4", ""}:                          KeyFunNextProblem,
+		KeySeq{"Shift+F4", ""}:                    KeyFunPrevProblem,
+		KeySeq{"Control+K", "z"}:                  KeyFunFocusMode,
+		KeySeq{"F1", ""}:                          KeyFunCmdPalette,
+		KeySeq{"Shift+F10", ""}:                   KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:                    KeyFunDescribeFocus,
+	}},
+	{"JetBrains", "JetBrains IDE-like bindings (Linux / Windows) -- Control+M starts the two-key sequences", KeySeqMap{
+		KeySeq{"Control+Tab", ""}:                   KeyFunNextPanel,
+		KeySeq{"Shift+Control+Tab", ""}:             KeyFunPrevPanel,
+		KeySeq{"Control+M", "f"}:                    KeyFunFileOpen,
+		KeySeq{"Control+E", ""}:                     KeyFunBufSelect,
+		KeySeq{"Control+M", "n"}:                    KeyFunBufClone,
+		KeySeq{"Control+S", ""}:                     KeyFunBufSave,
+		KeySeq{"Control+M", "w"}:                    KeyFunBufSaveAs,
+		KeySeq{"Control+F4", ""}:                    KeyFunBufClose,
+		KeySeq{"Shift+Control+A", ""}:               KeyFunExecCmd,
+		KeySeq{"Control+M", "Alt+C"}:                KeyFunRectCopy,
+		KeySeq{"Control+M", "Alt+X"}:                KeyFunRectCut,
+		KeySeq{"Control+M", "Alt+V"}:                KeyFunRectPaste,
+		KeySeq{"Control+M", "x"}:                    KeyFunRegCopy,
+		KeySeq{"Control+M", "g"}:                    KeyFunRegPaste,
+		KeySeq{"Control+/", ""}:                     KeyFunCommentOut,
+		KeySeq{"Control+Alt+L", ""}:                 KeyFunIndent,
+		KeySeq{"Control+G", ""}:                     KeyFunJump,
+		KeySeq{"Control+M", "v"}:                    KeyFunSetSplit,
+		KeySeq{"Control+F9", ""}:                    KeyFunBuildProj,
+		KeySeq{"Shift+F10", ""}:                     KeyFunRunProj,
+		KeySeq{"Control+M", "q"}:                    KeyFunMacroRec,
+		KeySeq{"Control+M", "e"}:                    KeyFunMacroPlay,
+		KeySeq{"Alt+RightArrow", ""}:                KeyFunNextPane,
+		KeySeq{"Alt+LeftArrow", ""}:                 KeyFunPrevPane,
+		KeySeq{"Control+Alt+LeftArrow", ""}:         KeyFunNavBack,
+		KeySeq{"Control+Alt+RightArrow", ""}:        KeyFunNavForward,
+		KeySeq{"Shift+Control+DeleteBackspace", ""}: KeyFunLastEdit,
+		KeySeq{"Shift+Control+N", ""}:               KeyFunQuickOpen,
+		KeySeq{"Shift+Control+F", ""}:               KeyFunFindInFiles,
+		KeySeq{"F2", ""}:                            KeyFunNextProblem,
+		KeySeq{"Shift+F2", ""}:                      KeyFunPrevProblem,
+		KeySeq{"Control+M", "z"}:                    KeyFunFocusMode,
+		KeySeq{"F1", ""}:                            KeyFunCmdPalette,
+		KeySeq{"Control+M", "c"}:                    KeyFunContextMenu,
+		KeySeq{"Shift+F1", ""}:                      KeyFunDescribeFocus,
 	}},
 }
@@ -35,12 +35,27 @@ func _() {
 	_ = x[KeyFunSetSplit-19]
 	_ = x[KeyFunBuildProj-20]
 	_ = x[KeyFunRunProj-21]
-	_ = x[KeyFunsN-22]
+	_ = x[KeyFunMacroRec-22]
+	_ = x[KeyFunMacroPlay-23]
+	_ = x[KeyFunNextPane-24]
+	_ = x[KeyFunPrevPane-25]
+	_ = x[KeyFunNavBack-26]
+	_ = x[KeyFunNavForward-27]
+	_ = x[KeyFunLastEdit-28]
+	_ = x[KeyFunQuickOpen-29]
+	_ = x[KeyFunFindInFiles-30]
+	_ = x[KeyFunNextProblem-31]
+	_ = x[KeyFunPrevProblem-32]
+	_ = x[KeyFunFocusMode-33]
+	_ = x[KeyFunCmdPalette-34]
+	_ = x[KeyFunContextMenu-35]
+	_ = x[KeyFunDescribeFocus-36]
+	_ = x[KeyFunsN-37]
 }
 
-const _KeyFuns_name = "KeyFunNilKeyFunNeeds2KeyFunNextPanelKeyFunPrevPanelKeyFunFileOpenKeyFunBufSelectKeyFunBufCloneKeyFunBufSaveKeyFunBufSaveAsKeyFunBufCloseKeyFunExecCmdKeyFunRectCopyKeyFunRectCutKeyFunRectPasteKeyFunRegCopyKeyFunRegPasteKeyFunCommentOutKeyFunIndentKeyFunJumpKeyFunSetSplitKeyFunBuildProjKeyFunRunProjKeyFunsN"
+const _KeyFuns_name = "KeyFunNilKeyFunNeeds2KeyFunNextPanelKeyFunPrevPanelKeyFunFileOpenKeyFunBufSelectKeyFunBufCloneKeyFunBufSaveKeyFunBufSaveAsKeyFunBufCloseKeyFunExecCmdKeyFunRectCopyKeyFunRectCutKeyFunRectPasteKeyFunRegCopyKeyFunRegPasteKeyFunCommentOutKeyFunIndentKeyFunJumpKeyFunSetSplitKeyFunBuildProjKeyFunRunProjKeyFunMacroRecKeyFunMacroPlayKeyFunNextPaneKeyFunPrevPaneKeyFunNavBackKeyFunNavForwardKeyFunLastEditKeyFunQuickOpenKeyFunFindInFilesKeyFunNextProblemKeyFunPrevProblemKeyFunFocusModeKeyFunCmdPaletteKeyFunContextMenuKeyFunDescribeFocusKeyFunsN"
 
-var _KeyFuns_index = [...]uint16{0, 9, 21, 36, 51, 65, 80, 94, 107, 122, 136, 149, 163, 176, 191, 204, 218, 234, 246, 256, 270, 285, 298, 306}
+var _KeyFuns_index = [...]uint16{0, 9, 21, 36, 51, 65, 80, 94, 107, 122, 136, 149, 163, 176, 191, 204, 218, 234, 246, 256, 270, 285, 298, 312, 327, 341, 355, 368, 384, 398, 413, 430, 447, 464, 479, 495, 512, 531, 539}
 
 func (i KeyFuns) String() string {
 	if i < 0 || i >= KeyFuns(len(_KeyFuns_index)-1) {
@@ -21,7 +21,9 @@ import (
 // LangOpts defines options associated with a given language / file format
 // only languages in filecat.Supported list are supported..
 type LangOpts struct {
-	PostSaveCmds CmdNames `desc:"command(s) to run after a file of this type is saved"`
+	PostSaveCmds    CmdNames `desc:"command(s) to run after a file of this type is saved"`
+	RainbowBrackets bool     `desc:"color nested (), [], {} brackets according to their depth"`
+	StickyScroll    bool     `desc:"pin the first lines of the functions, types and blocks enclosing the top of the view above it while scrolling -- only for languages parsed by pi"`
 }
 
 // Langs is a map of language options
@@ -97,7 +99,9 @@ func (lt *Langs) SavePrefs() error {
 	pdir := oswin.TheApp.AppPrefsDir()
 	pnm := filepath.Join(pdir, PrefsLangsFileName)
 	AvailLangsChanged = false
-	return lt.SaveJSON(gi.FileName(pnm))
+	err := lt.SaveJSON(gi.FileName(pnm))
+	SyncPrefsSaved()
+	return err
 }
 
 // CopyFrom copies languages from given other map
@@ -215,5 +219,7 @@ var LangsProps = ki.Props{
 
 // StdLangs is the original compiled-in set of standard language options.
 var StdLangs = Langs{
-	filecat.Go: {CmdNames{"Imports Go File"}},
+	filecat.Go:   {CmdNames{"Imports Go File"}, false, true},
+	filecat.Json: {nil, true, false},
+	filecat.Lisp: {nil, true, false},
 }
@@ -0,0 +1,167 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/vci"
+)
+
+// DirLazyMin is the number of entries in a directory at or above which its
+// files are added to the file tree incrementally, in batches, in the
+// background when it is opened, instead of all at once -- 0 = never
+var DirLazyMin = 1000
+
+// DirLazyBatch is the number of entries added to the file tree at a time
+// for a directory that is loaded incrementally
+var DirLazyBatch = 250
+
+// DirEntryCount returns the number of entries in given directory, without
+// getting any info about them -- -1 if it cannot be read
+func DirEntryCount(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return -1
+	}
+	defer f.Close()
+	nms, err := f.Readdirnames(-1)
+	if err != nil {
+		return -1
+	}
+	return len(nms)
+}
+
+// EntriesLabel returns the label suffix showing the number of entries of a
+// large directory, and how many have been loaded so far when loading
+func (fn *FileNode) EntriesLabel() string {
+	if !fn.IsDir() || fn.NEntries == 0 {
+		return ""
+	}
+	if fn.Loading {
+		return fmt.Sprintf("  (loading %d of %d)", fn.NLoaded, fn.NEntries)
+	}
+	return fmt.Sprintf("  (%d entries)", fn.NEntries)
+}
+
+// OpenDirLazy opens this directory node, adding its files to the tree in
+// batches of DirLazyBatch in the background if it has at least DirLazyMin
+// entries -- returns false if it does not (or is already loading), in which
+// case it should be opened with OpenDir as usual.  Closing the directory
+// while loading stops the loading.
+func (fn *FileNode) OpenDirLazy() bool {
+	if !fn.IsDir() || fn.IsIrregular() || fn.This() == fn.FRoot.This() || DirLazyMin <= 0 {
+		return false
+	}
+	if fn.Loading {
+		return true
+	}
+	n := DirEntryCount(string(fn.FPath))
+	if n < DirLazyMin {
+		fn.NEntries = 0
+		return false
+	}
+	fn.NEntries = n
+	fn.NLoaded = 0
+	fn.Loading = true
+	fn.SetOpen()
+	fn.FRoot.SetDirOpen(fn.FPath)
+	fn.UpdateSig()
+	go fn.LoadDirBatches()
+	return true
+}
+
+// LoadDirBatches adds the files of this directory to the tree in batches of
+// DirLazyBatch, keeping the existing nodes (and their open buffers) of files
+// that are still there -- the info about each batch of files is obtained
+// before locking the tree to add them
+func (fn *FileNode) LoadDirBatches() {
+	defer func() {
+		fn.Loading = false
+		fn.UpdateSig()
+	}()
+	fn.DetectVcsRepo(true)
+	path := string(fn.FPath)
+	config := fn.ConfigOfFiles(path)
+	fn.NEntries = len(config)
+	repo, rnode := fn.Repo()
+	infos := make([]giv.FileInfo, DirLazyBatch)
+	keep := make(map[string]bool, len(config))
+	for st := 0; st < len(config); st += DirLazyBatch {
+		if !fn.IsOpen() {
+			return // closed: stop -- the rest is loaded when opened again
+		}
+		ed := st + DirLazyBatch
+		if ed > len(config) {
+			ed = len(config)
+		}
+		for i := st; i < ed; i++ {
+			fp := filepath.Join(path, config[i].Name)
+			if eff, err := filepath.EvalSymlinks(fp); err == nil {
+				fp = eff
+			}
+			infos[i-st] = giv.FileInfo{}
+			infos[i-st].InitFile(fp)
+		}
+		fn.FRoot.UpdtMu.Lock()
+		updt := fn.UpdateStart()
+		for i := st; i < ed; i++ {
+			fn.AddLazyChild(i, config[i], &infos[i-st], repo, rnode)
+			keep[config[i].Name] = true
+		}
+		fn.NLoaded = ed
+		fn.UpdateEnd(updt)
+		fn.FRoot.UpdtMu.Unlock()
+	}
+	fn.FRoot.UpdtMu.Lock()
+	updt := fn.UpdateStart()
+	for i := fn.NumChildren() - 1; i >= 0; i-- {
+		if k := fn.Child(i); !keep[k.Name()] {
+			fn.DeleteChildAtIndex(i, ki.DestroyKids)
+		}
+	}
+	fn.UpdateEnd(updt)
+	fn.FRoot.UpdtMu.Unlock()
+}
+
+// AddLazyChild puts the node for given file at given index of the children,
+// reusing any existing node for it, and sets its info
+func (fn *FileNode) AddLazyChild(idx int, tn kit.TypeAndName, info *giv.FileInfo, repo vci.Repo, rnode *giv.FileNode) {
+	var sf *giv.FileNode
+	if ci, ok := fn.Children().IndexByName(tn.Name, idx); ok {
+		if ci != idx {
+			fn.Children().Move(ci, idx)
+		}
+		sf = fn.Child(idx).Embed(giv.KiT_FileNode).(*giv.FileNode)
+	} else {
+		if idx > fn.NumChildren() {
+			idx = fn.NumChildren()
+		}
+		sf = fn.InsertNewChild(tn.Type, idx, tn.Name).Embed(giv.KiT_FileNode).(*giv.FileNode)
+	}
+	sf.FRoot = fn.FRoot
+	sf.FPath = gi.FileName(info.Path)
+	if info.Path == "" {
+		sf.FPath = gi.FileName(filepath.Join(string(fn.FPath), tn.Name))
+	}
+	sf.Info = *info
+	switch {
+	case sf.IsDir():
+		sf.Info.Vcs = vci.Stored
+		if fn.FRoot.IsDirOpen(sf.FPath) {
+			sf.ReadDir(string(sf.FPath))
+		}
+	case repo != nil:
+		sf.Info.Vcs = rnode.RepoFiles.Status(repo, string(sf.FPath))
+	default:
+		sf.Info.Vcs = vci.Stored
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import "sync"
+
+// LazyTabs records the tabs whose contents are configured on first use,
+// e.g., the command, symbols and terminal tabs restored from the session,
+// which are only made when their tab is first selected, so they do not slow
+// down the opening of a project -- the debugger view and the command
+// buffers are otherwise only made when first used, and the spelling model
+// is loaded in the background (see LoadSpell)
+type LazyTabs struct {
+	Cfgs map[string]func()      `desc:"functions configuring the tabs, by tab label"`
+	Data map[string]interface{} `desc:"optional data for the tabs not yet configured, e.g., their session state"`
+	Mu   sync.Mutex             `desc:"mutex protecting the maps"`
+}
+
+// Add records given function as configuring the tab of given label on
+// first use, with optional data, e.g., its session state
+func (lt *LazyTabs) Add(label string, cfg func(), data interface{}) {
+	lt.Mu.Lock()
+	defer lt.Mu.Unlock()
+	if lt.Cfgs == nil {
+		lt.Cfgs = make(map[string]func())
+		lt.Data = make(map[string]interface{})
+	}
+	lt.Cfgs[label] = cfg
+	if data != nil {
+		lt.Data[label] = data
+	} else {
+		delete(lt.Data, label)
+	}
+}
+
+// Remove removes the tab of given label, e.g., when it is configured by
+// other means, or deleted -- returns true if it was not yet configured
+func (lt *LazyTabs) Remove(label string) bool {
+	lt.Mu.Lock()
+	defer lt.Mu.Unlock()
+	_, has := lt.Cfgs[label]
+	delete(lt.Cfgs, label)
+	delete(lt.Data, label)
+	return has
+}
+
+// TabData returns the data of the tab of given label, if not yet configured
+func (lt *LazyTabs) TabData(label string) (interface{}, bool) {
+	lt.Mu.Lock()
+	defer lt.Mu.Unlock()
+	data, has := lt.Data[label]
+	return data, has
+}
+
+// Config configures the tab of given label, if not yet configured --
+// returns true if it was
+func (lt *LazyTabs) Config(label string) bool {
+	lt.Mu.Lock()
+	cfg, has := lt.Cfgs[label]
+	delete(lt.Cfgs, label)
+	delete(lt.Data, label)
+	lt.Mu.Unlock()
+	if !has {
+		return false
+	}
+	cfg()
+	return true
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/lex"
+)
+
+// LineEnds are the line endings of a file: LF (\n, as on Unix and macOS),
+// CRLF (\r\n, as on Windows), or a mix of both
+type LineEnds int32
+
+const (
+	// LineEndsLF ends lines with a line feed (\n)
+	LineEndsLF LineEnds = iota
+
+	// LineEndsCRLF ends lines with a carriage return and a line feed (\r\n)
+	LineEndsCRLF
+
+	// LineEndsMixed is for files with both kinds of line endings, which
+	// are kept as they are unless converted
+	LineEndsMixed
+
+	// LineEndsN is the number of line endings
+	LineEndsN
+)
+
+//go:generate stringer -type=LineEnds
+
+var KiT_LineEnds = kit.Enums.AddEnumAltLower(LineEndsN, kit.NotBitFlag, nil, "LineEnds")
+
+func (ev LineEnds) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *LineEnds) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// LineEndsLabels are the labels of the line endings, as shown in the
+// statusbar
+var LineEndsLabels = [LineEndsN]string{"LF", "CRLF", "Mixed"}
+
+// Label returns the label of the line endings
+func (le LineEnds) Label() string {
+	if le < 0 || le >= LineEndsN {
+		return le.String()
+	}
+	return LineEndsLabels[le]
+}
+
+// BufLineEnds returns the line endings of given buffer, as detected by
+// DetectLineEnds, or converted by ConvertLineEnds
+func BufLineEnds(tb *giv.TextBuf) LineEnds {
+	le, _ := tb.Prop("line-ends").(LineEnds)
+	return le
+}
+
+// DetectLineEnds detects the line endings of given buffer, just opened or
+// reverted: lines ending in a CR are from a CRLF file (Mixed if not all of
+// them are), whose CR are then removed, so that edits do not mix the line
+// endings, and added back on save (see SaveLineEnds) -- the lines of a
+// Mixed file are kept as they are.  A buffer with no line endings (at most
+// one line) gets def, the default of the project for new files.  Does
+// nothing for a buffer already detected, with no CR.
+func DetectLineEnds(tb *giv.TextBuf, def LineEnds) {
+	tb.LinesMu.RLock()
+	ncr := 0
+	mixed := false
+	for i, ln := range tb.Lines {
+		if n := len(ln); n > 0 && ln[n-1] == '\r' {
+			ncr++
+		} else if i < tb.NLines-1 { // last line can have no ending
+			mixed = true
+		}
+	}
+	nln := tb.NLines
+	tb.LinesMu.RUnlock()
+	if ncr == 0 {
+		if _, has := tb.Prop("line-ends").(LineEnds); !has {
+			le := LineEndsLF
+			if nln <= 1 {
+				le = def
+			}
+			tb.SetProp("line-ends", le)
+		}
+		return
+	}
+	if tb.IsChanged() { // being edited, as Mixed
+		return
+	}
+	if mixed {
+		tb.SetProp("line-ends", LineEndsMixed)
+		return
+	}
+	tb.SetProp("line-ends", LineEndsCRLF)
+	tb.SetText(bytes.Replace(tb.LinesToBytesCopy(), []byte("\r\n"), []byte("\n"), -1))
+}
+
+// SaveLineEnds converts the text of given buffer, just updated from its
+// lines for saving (on TextBufDone), to its line endings: adding back the
+// CR removed from the lines of a CRLF file
+func SaveLineEnds(tb *giv.TextBuf) {
+	if BufLineEnds(tb) == LineEndsCRLF {
+		tb.Txt = bytes.Replace(tb.Txt, []byte("\n"), []byte("\r\n"), -1)
+	}
+}
+
+// ToBufLineEnds converts the text of a file, as in the repository, to the
+// form of the lines of a buffer with given line endings: the CR of its CRLF
+// endings are removed for a CRLF buffer, as done by DetectLineEnds, so that
+// it can be diffed against the buffer, and copied into it -- returns true
+// if it did so, for converting back with FromBufLineEnds
+func ToBufLineEnds(src []byte, le LineEnds) ([]byte, bool) {
+	if le != LineEndsCRLF || !bytes.Contains(src, []byte("\r\n")) {
+		return src, false
+	}
+	return bytes.Replace(src, []byte("\r\n"), []byte("\n"), -1), true
+}
+
+// FromBufLineEnds converts back text converted by ToBufLineEnds, given
+// whether its CR were removed
+func FromBufLineEnds(txt []byte, crlf bool) []byte {
+	if !crlf {
+		return txt
+	}
+	return bytes.Replace(txt, []byte("\n"), []byte("\r\n"), -1)
+}
+
+// ConvertLineEnds converts the line endings of given buffer to le (LF or
+// CRLF), written on the next save: the CR of the lines having one (in a
+// Mixed file) are deleted, as edits that can be undone, and the buffer is
+// marked changed
+func ConvertLineEnds(tb *giv.TextBuf, le LineEnds) {
+	if le == LineEndsMixed {
+		return
+	}
+	var crs []lex.Pos
+	tb.LinesMu.RLock()
+	for i, ln := range tb.Lines {
+		if n := len(ln); n > 0 && ln[n-1] == '\r' {
+			crs = append(crs, lex.Pos{Ln: i, Ch: n - 1})
+		}
+	}
+	tb.LinesMu.RUnlock()
+	if len(crs) == 0 && le == BufLineEnds(tb) {
+		return
+	}
+	tb.SetProp("line-ends", le)
+	for i := len(crs) - 1; i >= 0; i-- {
+		st := crs[i]
+		tb.DeleteText(st, lex.Pos{Ln: st.Ln, Ch: st.Ch + 1}, true)
+	}
+	tb.SetChanged()
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"testing"
+
+	"github.com/goki/gi/giv"
+)
+
+func TestDetectLineEnds(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		def  LineEnds
+		le   LineEnds
+		txt  string
+		save string
+	}{
+		{"lf", "a\nb\nc\n", LineEndsCRLF, LineEndsLF, "a\nb\nc\n", "a\nb\nc\n"},
+		{"crlf", "a\r\nb\r\nc\r\n", LineEndsLF, LineEndsCRLF, "a\nb\nc\n", "a\r\nb\r\nc\r\n"},
+		{"crlf no last", "a\r\nb\r\nc", LineEndsLF, LineEndsCRLF, "a\nb\nc\n", "a\r\nb\r\nc\r\n"},
+		{"mixed", "a\r\nb\nc\r\n", LineEndsLF, LineEndsMixed, "a\r\nb\nc\r\n", "a\r\nb\nc\r\n"},
+		{"one line", "abc", LineEndsCRLF, LineEndsCRLF, "abc\n", "abc\r\n"},
+		{"empty", "", LineEndsLF, LineEndsLF, "\n", "\n"},
+	}
+	for _, tst := range tests {
+		tb := &giv.TextBuf{}
+		tb.InitName(tb, tst.name)
+		tb.Hi.Style = "emacs" // skips the defaults, which need the app
+		tb.SetText([]byte(tst.src))
+		DetectLineEnds(tb, tst.def)
+		if le := BufLineEnds(tb); le != tst.le {
+			t.Errorf("DetectLineEnds error: %v: should have been: %v  was: %v\n", tst.name, tst.le, le)
+		}
+		if txt := string(tb.LinesToBytesCopy()); txt != tst.txt {
+			t.Errorf("DetectLineEnds error: %v: text should have been: %q  was: %q\n", tst.name, tst.txt, txt)
+		}
+		tb.Txt = tb.LinesToBytesCopy() // as on save, with a final line ending
+		SaveLineEnds(tb)
+		if save := string(tb.Txt); save != tst.save {
+			t.Errorf("SaveLineEnds error: %v: should have been: %q  was: %q\n", tst.name, tst.save, save)
+		}
+		DetectLineEnds(tb, LineEndsLF) // again, as after a save: no change
+		if le := BufLineEnds(tb); le != tst.le {
+			t.Errorf("DetectLineEnds error: %v: again should have been: %v  was: %v\n", tst.name, tst.le, le)
+		}
+	}
+}
+
+func TestBufLineEndsConvert(t *testing.T) {
+	tests := []struct {
+		src  string
+		le   LineEnds
+		txt  string
+		crlf bool
+	}{
+		{"a\r\nb\r\n", LineEndsCRLF, "a\nb\n", true},
+		{"a\r\nb\r\n", LineEndsLF, "a\r\nb\r\n", false},
+		{"a\r\nb\r\n", LineEndsMixed, "a\r\nb\r\n", false},
+		{"a\nb\n", LineEndsCRLF, "a\nb\n", false},
+		{"", LineEndsCRLF, "", false},
+	}
+	for _, tst := range tests {
+		txt, crlf := ToBufLineEnds([]byte(tst.src), tst.le)
+		if string(txt) != tst.txt || crlf != tst.crlf {
+			t.Errorf("ToBufLineEnds error: %q %v: should have been: %q %v  was: %q %v\n", tst.src, tst.le, tst.txt, tst.crlf, txt, crlf)
+		}
+		if back := string(FromBufLineEnds(txt, crlf)); back != tst.src {
+			t.Errorf("FromBufLineEnds error: %q %v: should have been: %q  was: %q\n", tst.src, tst.le, tst.src, back)
+		}
+	}
+}
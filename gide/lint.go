@@ -0,0 +1,162 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// LintParams are the parameters for running golangci-lint in a project
+type LintParams struct {
+	Path     string      `desc:"path of the golangci-lint executable -- empty to find it on the PATH"`
+	Config   gi.FileName `ext:".yml,.yaml,.toml,.json" desc:"golangci-lint config file -- empty to use the .golangci.yml (etc) found from the project directories, as golangci-lint does"`
+	Args     []string    `desc:"additional arguments passed to golangci-lint run, e.g., --fast or --enable-all"`
+	V2       bool        `desc:"set for golangci-lint v2, which sets the JSON output with --output.json.path instead of --out-format"`
+	OnSave   bool        `desc:"if set, golangci-lint is run on the package of each Go file saved, updating its findings in the Problems panel"`
+	Disabled bool        `view:"-" json:"-" xml:"-" desc:"set when golangci-lint is not found, so it is not run again on save"`
+}
+
+// LintCmdName is the name of golangci-lint runs in the CmdRuns of the
+// project, and the source of its problems, followed by the package
+// directory
+var LintCmdName = "golangci-lint"
+
+// LintDocsURL is the format of the link to the docs of a linter, given
+// its name
+var LintDocsURL = "https://golangci-lint.run/usage/linters/#%s"
+
+// LintIssue is an issue in the JSON output of golangci-lint
+type LintIssue struct {
+	FromLinter string
+	Text       string
+	Severity   string
+	Pos        struct {
+		Filename string
+		Line     int
+		Column   int
+	}
+}
+
+// LintOutput is the JSON output of golangci-lint
+type LintOutput struct {
+	Issues []LintIssue
+}
+
+// Exec returns the path of the golangci-lint executable
+func (lp *LintParams) Exec() string {
+	if lp.Path != "" {
+		return lp.Path
+	}
+	return "golangci-lint"
+}
+
+// Installed returns true if the golangci-lint executable is found
+func (lp *LintParams) Installed() bool {
+	_, err := exec.LookPath(lp.Exec())
+	return err == nil
+}
+
+// RunArgs returns the args of golangci-lint for running on given packages
+func (lp *LintParams) RunArgs(pkgs ...string) []string {
+	args := []string{"run"}
+	if lp.V2 {
+		args = append(args, "--output.json.path=stdout", "--show-stats=false")
+	} else {
+		args = append(args, "--out-format=json")
+	}
+	if lp.Config != "" {
+		args = append(args, "--config="+string(lp.Config))
+	}
+	args = append(args, lp.Args...)
+	return append(args, pkgs...)
+}
+
+// ParseLintOutput parses the JSON output of golangci-lint run in given
+// directory, returning its issues as problems, with their linter as the
+// source, by package directory
+func ParseLintOutput(out []byte, dir string) (map[string][]Problem, error) {
+	js := bytes.TrimSpace(out)
+	if st := bytes.IndexByte(js, '{'); st > 0 { // skip any warnings before the JSON
+		js = js[st:]
+	}
+	lo := LintOutput{}
+	if err := json.NewDecoder(bytes.NewReader(js)).Decode(&lo); err != nil {
+		return nil, fmt.Errorf("could not parse the golangci-lint output: %v", err)
+	}
+	bydir := map[string][]Problem{}
+	for _, is := range lo.Issues {
+		fpath := filepath.FromSlash(is.Pos.Filename)
+		if !filepath.IsAbs(fpath) {
+			fpath = filepath.Join(dir, fpath)
+		}
+		sev := ProblemWarning
+		if strings.ToLower(is.Severity) == "error" {
+			sev = ProblemError
+		}
+		pb := Problem{Path: fpath, Line: is.Pos.Line, Col: is.Pos.Column, Severity: sev, Source: LintCmdName + " " + is.FromLinter, Msg: is.Text}
+		if is.FromLinter != "" {
+			pb.URL = fmt.Sprintf(LintDocsURL, is.FromLinter)
+		}
+		pd := filepath.Dir(fpath)
+		bydir[pd] = append(bydir[pd], pb)
+	}
+	for _, probs := range bydir {
+		sort.Slice(probs, func(i, j int) bool { return probs[i].Less(&probs[j]) })
+	}
+	return bydir, nil
+}
+
+// RunLint runs golangci-lint for given packages (e.g., ./... or .) in
+// given directory, returning its findings by package directory -- the
+// command is added to the CmdRuns of the project while it runs, for
+// stopping it
+func RunLint(ge Gide, lp *LintParams, dir string, pkgs ...string) (map[string][]Problem, error) {
+	ex := lp.Exec()
+	if !lp.Installed() {
+		return nil, fmt.Errorf("%v not found: install it from https://golangci-lint.run, or set its Path in the Lint project preferences", ex)
+	}
+	args := lp.RunArgs(pkgs...)
+	cmd := exec.Command(ex, args...)
+	cmd.Dir = dir
+	cmd.Env = ge.ProjPrefs().CmdEnv()
+	var out, errout bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &errout
+	cma := &CmdAndArgs{Cmd: ex, Args: args}
+	ge.CmdRuns().AddCmd(LintCmdName, ex+" "+strings.Join(args, " "), cma, cmd)
+	err := cmd.Start()
+	if err == nil {
+		err = cmd.Wait() // exit code 1 = issues found
+	}
+	ge.CmdRuns().DeleteByName(LintCmdName)
+	bydir, perr := ParseLintOutput(out.Bytes(), dir)
+	if perr != nil {
+		if msg := strings.TrimSpace(errout.String()); msg != "" {
+			return nil, fmt.Errorf("%v", msg)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, perr
+	}
+	return bydir, nil
+}
+
+// LintSource returns the source of the problems found by golangci-lint in
+// given package directory, relative to the project root
+func LintSource(root, dir string) string {
+	if rel, err := filepath.Rel(root, dir); err == nil && !RelOutside(rel) {
+		dir = filepath.ToSlash(rel)
+	}
+	return LintCmdName + " " + dir
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseLintOutput(t *testing.T) {
+	dir := filepath.FromSlash("/proj")
+	out := `level=warning msg="[runner] The linter 'golint' is deprecated"
+{"Issues":[
+{"FromLinter":"errcheck","Text":"Error return value is not checked","Severity":"","Pos":{"Filename":"a/b.go","Line":12,"Column":3}},
+{"FromLinter":"govet","Text":"unreachable code","Severity":"error","Pos":{"Filename":"a/b.go","Line":4,"Column":0}},
+{"FromLinter":"","Text":"typecheck","Severity":"error","Pos":{"Filename":"/other/c.go","Line":1,"Column":1}}
+],"Report":{}}
+`
+	bydir, err := ParseLintOutput([]byte(out), dir)
+	if err != nil {
+		t.Fatalf("ParseLintOutput error: %v\n", err)
+	}
+	bpath := filepath.Join(dir, "a", "b.go")
+	want := map[string][]Problem{
+		filepath.Dir(bpath): {
+			{Path: bpath, Line: 4, Col: 0, Severity: ProblemError, Source: LintCmdName + " govet", Msg: "unreachable code", URL: fmt.Sprintf(LintDocsURL, "govet")},
+			{Path: bpath, Line: 12, Col: 3, Severity: ProblemWarning, Source: LintCmdName + " errcheck", Msg: "Error return value is not checked", URL: fmt.Sprintf(LintDocsURL, "errcheck")},
+		},
+		filepath.FromSlash("/other"): {
+			{Path: filepath.FromSlash("/other/c.go"), Line: 1, Col: 1, Severity: ProblemError, Source: LintCmdName + " ", Msg: "typecheck"},
+		},
+	}
+	if !reflect.DeepEqual(bydir, want) {
+		t.Errorf("ParseLintOutput error: should have been: %+v  was: %+v\n", want, bydir)
+	}
+
+	bydir, err = ParseLintOutput([]byte(`{"Issues":null}`), dir)
+	if err != nil || len(bydir) != 0 {
+		t.Errorf("ParseLintOutput error: no issues should have no problems, was: %v %v\n", bydir, err)
+	}
+	if _, err = ParseLintOutput([]byte("level=error msg=\"no go files\"\n"), dir); err == nil {
+		t.Errorf("ParseLintOutput error: output with no JSON should be an error\n")
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// LiveRunCmdName is the name of the tab of the live run, and of the
+// executable run by it in the CmdRuns of the project
+var LiveRunCmdName = "Live Run"
+
+// LiveRunDelay is the time after the last change before rebuilding, so a
+// burst of changes (e.g., a checkout) rebuilds once
+var LiveRunDelay = 500 * time.Millisecond
+
+// LiveRunStopWait is how long a restarted executable is given to exit
+// after being killed
+var LiveRunStopWait = 5 * time.Second
+
+// IsLiveRunFile returns true if a change to given file triggers a rebuild
+// of the live run: the Go files of the project, other than tests, and
+// go.mod and go.sum
+func IsLiveRunFile(fpath string) bool {
+	base := filepath.Base(fpath)
+	if base == "go.mod" || base == "go.sum" {
+		return true
+	}
+	return filepath.Ext(base) == ".go" && !strings.HasSuffix(base, "_test.go") && !strings.HasPrefix(base, ".")
+}
+
+// LiveRunCmds returns the commands building the RunExec of given project
+// for the live run: its BuildCmds, or Build Go Proj for Go projects without
+// them -- commands that prompt for values are left out
+func LiveRunCmds(pf *ProjPrefs) []*Command {
+	var cmds []*Command
+	for _, cn := range pf.BuildCmds {
+		cm, _, ok := AvailCmds.CmdByName(cn, false)
+		if !ok {
+			continue
+		}
+		if _, hasp := cm.HasPrompts(); hasp {
+			continue
+		}
+		cmds = append(cmds, cm)
+	}
+	if len(pf.BuildCmds) == 0 && pf.MainLang == filecat.Go {
+		if cm, _, ok := AvailCmds.CmdByName("Build Go Proj", false); ok {
+			cmds = append(cmds, cm)
+		}
+	}
+	return cmds
+}
+
+// LiveRun is the live-reload run mode: it runs the RunExec of the project,
+// and rebuilds and restarts it whenever the Go files of the project
+// change, like air or reflex, with the restart events and the output of
+// the executable in one tab -- reloading can be paused
+type LiveRun struct {
+	Gide     Gide          `json:"-" xml:"-" view:"-" desc:"the project"`
+	Buf      *giv.TextBuf  `json:"-" xml:"-" view:"-" desc:"buffer of the tab with the events and output"`
+	Paused   bool          `desc:"if set, changes do not rebuild until resumed"`
+	Pending  []string      `desc:"files changed since the last rebuild, or while paused"`
+	Restarts int           `desc:"number of restarts"`
+	Cmd      *exec.Cmd     `json:"-" xml:"-" view:"-" desc:"the running executable"`
+	Done     chan struct{} `json:"-" xml:"-" view:"-" desc:"closed when the running executable exits"`
+	Timer    *time.Timer   `json:"-" xml:"-" view:"-" desc:"timer of the rebuild after LiveRunDelay"`
+	Busy     bool          `desc:"true while rebuilding"`
+	Again    bool          `desc:"set when files change while rebuilding, so it rebuilds again when done"`
+	Mu       sync.Mutex    `json:"-" xml:"-" view:"-" desc:"mutex protecting the state"`
+}
+
+// IsOn returns true if the live run has been started and not stopped
+func (lr *LiveRun) IsOn() bool {
+	lr.Mu.Lock()
+	defer lr.Mu.Unlock()
+	return lr.Gide != nil
+}
+
+// Start starts the live run of the project, with the events and output in
+// given buffer: it builds and runs the RunExec
+func (lr *LiveRun) Start(ge Gide, buf *giv.TextBuf) {
+	lr.Mu.Lock()
+	lr.Gide = ge
+	lr.Buf = buf
+	lr.Paused = false
+	lr.Pending = nil
+	lr.Restarts = 0
+	lr.Mu.Unlock()
+	lr.Event("live run started: rebuilding and restarting on changes to the Go files of the project")
+	go lr.Rebuild()
+}
+
+// Stop stops the live run, and the running executable
+func (lr *LiveRun) Stop() {
+	lr.Mu.Lock()
+	if lr.Gide == nil {
+		lr.Mu.Unlock()
+		return
+	}
+	if lr.Timer != nil {
+		lr.Timer.Stop()
+		lr.Timer = nil
+	}
+	ge := lr.Gide
+	lr.Mu.Unlock()
+	lr.Kill()
+	ge.CmdRuns().DeleteByName(LiveRunCmdName)
+	lr.Event("live run stopped")
+	lr.Mu.Lock()
+	lr.Gide = nil
+	lr.Mu.Unlock()
+}
+
+// SetPaused pauses or resumes reloading -- on resume, it rebuilds if files
+// were changed while paused
+func (lr *LiveRun) SetPaused(pause bool) {
+	lr.Mu.Lock()
+	if lr.Gide == nil || lr.Paused == pause {
+		lr.Mu.Unlock()
+		return
+	}
+	lr.Paused = pause
+	npend := len(lr.Pending)
+	lr.Mu.Unlock()
+	if pause {
+		lr.Event("reloading paused")
+		return
+	}
+	lr.Event(fmt.Sprintf("reloading resumed -- %d files changed while paused", npend))
+	if npend > 0 {
+		lr.Schedule()
+	}
+}
+
+// Changed notes that given file was changed, scheduling a rebuild if it is
+// one of IsLiveRunFile and reloading is not paused
+func (lr *LiveRun) Changed(fpath string) {
+	if !IsLiveRunFile(fpath) {
+		return
+	}
+	lr.Mu.Lock()
+	if lr.Gide == nil {
+		lr.Mu.Unlock()
+		return
+	}
+	for _, pf := range lr.Pending {
+		if pf == fpath {
+			lr.Mu.Unlock()
+			return
+		}
+	}
+	lr.Pending = append(lr.Pending, fpath)
+	paused := lr.Paused
+	lr.Mu.Unlock()
+	if !paused {
+		lr.Schedule()
+	}
+}
+
+// Schedule schedules a rebuild after LiveRunDelay, restarting the delay
+// if already scheduled
+func (lr *LiveRun) Schedule() {
+	lr.Mu.Lock()
+	defer lr.Mu.Unlock()
+	if lr.Timer != nil {
+		lr.Timer.Stop()
+	}
+	lr.Timer = time.AfterFunc(LiveRunDelay, func() {
+		lr.Mu.Lock()
+		lr.Timer = nil
+		if lr.Busy {
+			lr.Again = true
+			lr.Mu.Unlock()
+			return
+		}
+		lr.Mu.Unlock()
+		lr.Rebuild()
+	})
+}
+
+// Rebuild builds the RunExec and restarts it if the build succeeds --
+// the running executable is kept if it fails
+func (lr *LiveRun) Rebuild() {
+	for {
+		lr.Mu.Lock()
+		ge := lr.Gide
+		if ge == nil || lr.Busy {
+			lr.Mu.Unlock()
+			return
+		}
+		lr.Busy = true
+		lr.Again = false
+		pend := lr.Pending
+		lr.Pending = nil
+		lr.Mu.Unlock()
+		if len(pend) > 0 {
+			root := string(ge.ProjPrefs().ProjRoot)
+			rels := make([]string, len(pend))
+			for i, pf := range pend {
+				rels[i] = pf
+				if rel, err := filepath.Rel(root, pf); err == nil {
+					rels[i] = rel
+				}
+			}
+			lr.Event("changed: " + strings.Join(rels, ", ") + " -- rebuilding")
+		}
+		ok := lr.Build()
+		if ok {
+			lr.Restart()
+		}
+		lr.Mu.Lock()
+		lr.Busy = false
+		again := lr.Again && !lr.Paused
+		lr.Mu.Unlock()
+		if !again {
+			return
+		}
+	}
+}
+
+// Build runs the build commands, with their output in the tab -- returns
+// false if one fails
+func (lr *LiveRun) Build() bool {
+	ge := lr.Gide
+	cmds := LiveRunCmds(ge.ProjPrefs())
+	for _, cm := range cmds {
+		cm.ChDir(ge, lr.Buf)
+		for i := range cm.Cmds {
+			if !cm.RunBufWait(ge, lr.Buf, &cm.Cmds[i]) {
+				lr.Event("build failed: " + cm.Name + " -- keeping the running executable")
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Kill kills the running executable, if any, waiting for it to exit
+func (lr *LiveRun) Kill() {
+	lr.Mu.Lock()
+	cmd, done := lr.Cmd, lr.Done
+	lr.Cmd = nil
+	lr.Mu.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	cmd.Process.Kill()
+	select {
+	case <-done:
+	case <-time.After(LiveRunStopWait):
+	}
+}
+
+// Restart kills the running executable, if any, and starts the RunExec,
+// with its output in the tab
+func (lr *LiveRun) Restart() {
+	ge := lr.Gide
+	pf := ge.ProjPrefs()
+	if !pf.RunExecIsExec() {
+		lr.Event(fmt.Sprintf("RunExec is not an executable: %v -- set it in the project preferences", pf.RunExec))
+		return
+	}
+	lr.Kill()
+	exe, _ := filepath.Abs(string(pf.RunExec))
+	cmd := pf.ExecCmd(filepath.Dir(exe), nil, exe)
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		cmd.Stderr = cmd.Stdout
+		err = cmd.Start()
+	}
+	if err != nil {
+		lr.Event("could not start: " + err.Error())
+		return
+	}
+	done := make(chan struct{})
+	lr.Mu.Lock()
+	lr.Restarts++
+	nr := lr.Restarts
+	lr.Cmd = cmd
+	lr.Done = done
+	lr.Mu.Unlock()
+	ge.CmdRuns().DeleteByName(LiveRunCmdName)
+	ge.CmdRuns().AddCmd(LiveRunCmdName, exe, &CmdAndArgs{Cmd: exe}, cmd)
+	if nr == 1 {
+		lr.Event("started: " + exe)
+	} else {
+		lr.Event(fmt.Sprintf("restarted (#%d): %v", nr-1, exe))
+	}
+	go func() {
+		obuf := OutBuf{}
+		obuf.Init(stdout, lr.Buf, 0, MarkupCmdOutput)
+		obuf.MonOut()
+		werr := cmd.Wait()
+		close(done)
+		lr.Mu.Lock()
+		cur := lr.Cmd == cmd
+		if cur {
+			lr.Cmd = nil
+		}
+		lr.Mu.Unlock()
+		if !cur {
+			return // killed for a restart or stop
+		}
+		ge.CmdRuns().DeleteByName(LiveRunCmdName)
+		msg := "exited"
+		if werr != nil {
+			msg = "exited: " + werr.Error()
+		}
+		lr.Event(msg + " -- restarted on the next change")
+	}()
+}
+
+// Event appends given restart event, in bold with the time, to the tab
+func (lr *LiveRun) Event(msg string) {
+	lr.Mu.Lock()
+	ge, buf := lr.Gide, lr.Buf
+	lr.Mu.Unlock()
+	if ge == nil || buf == nil {
+		return
+	}
+	ln := fmt.Sprintf("[%v] %v", time.Now().Format("15:04:05"), msg)
+	wupdt := ge.VPort().TopUpdateStart()
+	defer ge.VPort().TopUpdateEnd(wupdt)
+	buf.AppendTextLineMarkup([]byte(ln), []byte("<b>"+html.EscapeString(ln)+"</b>"), giv.EditSignal)
+	buf.AutoScrollViews()
+	ge.SetStatus("live run: " + msg)
+}
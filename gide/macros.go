@@ -0,0 +1,246 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// MacroKey is one recorded key event in a keyboard macro -- the full rune,
+// code and modifiers are saved so that the event can be regenerated exactly
+type MacroKey struct {
+	Chord key.Chord `desc:"key chord, for display"`
+	Rune  rune      `desc:"unicode rune generated by key, -1 if none"`
+	Code  key.Codes `desc:"physical key code"`
+	Mods  int32     `desc:"modifier key bit flags"`
+}
+
+// NewMacroKey returns a MacroKey recording given key chord event
+func NewMacroKey(kt *key.ChordEvent) MacroKey {
+	return MacroKey{Chord: kt.Chord(), Rune: kt.Rune, Code: kt.Code, Mods: kt.Modifiers}
+}
+
+// Event returns a new key chord event for this key, suitable for sending to
+// a window
+func (mk *MacroKey) Event() *key.ChordEvent {
+	kt := &key.ChordEvent{}
+	kt.Rune = mk.Rune
+	kt.Code = mk.Code
+	kt.Modifiers = mk.Mods
+	kt.Action = key.Press
+	kt.Init()
+	return kt
+}
+
+// Macro is a named sequence of recorded key events that can be played back
+type Macro struct {
+	Name string     `desc:"name of macro"`
+	Desc string     `desc:"brief description"`
+	Keys []MacroKey `desc:"sequence of keys to play back"`
+}
+
+// Label satisfies the Labeler interface
+func (mc Macro) Label() string {
+	return mc.Name
+}
+
+// KeysString returns the key chords of the macro as a space-separated string
+func (mc *Macro) KeysString() string {
+	ks := make([]string, len(mc.Keys))
+	for i, k := range mc.Keys {
+		ks[i] = string(k.Chord)
+	}
+	return strings.Join(ks, " ")
+}
+
+// Macros is a list of named keyboard macros
+type Macros []*Macro
+
+var KiT_Macros = kit.Types.AddType(&Macros{}, MacrosProps)
+
+// MacroName has an associated ValueView for selecting from the list of
+// available named macros
+type MacroName string
+
+// AvailMacros are available named keyboard macros.  can be loaded / saved /
+// edited with preferences.
+var AvailMacros Macros
+
+// AvailMacroNames are the names of the current AvailMacros -- used for some choosers
+var AvailMacroNames []string
+
+// MacroByName returns a named macro and index by name -- returns false and emits a
+// message to stdout if not found
+func (lt *Macros) MacroByName(name MacroName) (*Macro, int, bool) {
+	if name == "" {
+		return nil, -1, false
+	}
+	for i, mc := range *lt {
+		if mc.Name == string(name) {
+			return mc, i, true
+		}
+	}
+	fmt.Printf("gide.MacroByName: macro named: %v not found\n", name)
+	return nil, -1, false
+}
+
+// Add adds a new macro with a copy of given keys, returns macro and index --
+// an existing macro of the same name is replaced
+func (lt *Macros) Add(name, desc string, keys []MacroKey) (*Macro, int) {
+	mc := &Macro{Name: name, Desc: desc, Keys: make([]MacroKey, len(keys))}
+	copy(mc.Keys, keys)
+	for i, emc := range *lt {
+		if emc.Name == name {
+			(*lt)[i] = mc
+			return mc, i
+		}
+	}
+	*lt = append(*lt, mc)
+	return mc, len(*lt) - 1
+}
+
+// Names returns a slice of current names
+func (lt *Macros) Names() []string {
+	nms := make([]string, len(*lt))
+	for i, mc := range *lt {
+		nms[i] = mc.Name
+	}
+	return nms
+}
+
+// PrefsMacrosFileName is the name of the preferences file in App prefs
+// directory for saving / loading the default AvailMacros
+var PrefsMacrosFileName = "macros_prefs.json"
+
+// OpenJSON opens named macros from a JSON-formatted file.
+func (lt *Macros) OpenJSON(filename gi.FileName) error {
+	b, err := ioutil.ReadFile(string(filename))
+	if err != nil {
+		return err
+	}
+	*lt = make(Macros, 0, 10) // reset
+	return json.Unmarshal(b, lt)
+}
+
+// SaveJSON saves named macros to a JSON-formatted file.
+func (lt *Macros) SaveJSON(filename gi.FileName) error {
+	b, err := json.MarshalIndent(lt, "", "  ")
+	if err != nil {
+		log.Println(err) // unlikely
+		return err
+	}
+	err = ioutil.WriteFile(string(filename), b, 0644)
+	if err != nil {
+		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		log.Println(err)
+	}
+	return err
+}
+
+// OpenPrefs opens Macros from App standard prefs directory, using PrefsMacrosFileName
+func (lt *Macros) OpenPrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsMacrosFileName)
+	AvailMacrosChanged = false
+	err := lt.OpenJSON(gi.FileName(pnm))
+	if err == nil {
+		AvailMacroNames = lt.Names()
+	}
+	return err
+}
+
+// SavePrefs saves Macros to App standard prefs directory, using PrefsMacrosFileName
+func (lt *Macros) SavePrefs() error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, PrefsMacrosFileName)
+	AvailMacrosChanged = false
+	AvailMacroNames = lt.Names()
+	return lt.SaveJSON(gi.FileName(pnm))
+}
+
+// AvailMacrosChanged is used to update toolbars via following menu, toolbar
+// props update methods -- not accurate if editing any other list but works for
+// now..
+var AvailMacrosChanged = false
+
+// MacrosProps define the ToolBar and MenuBar for TableView of Macros
+var MacrosProps = ki.Props{
+	"MainMenu": ki.PropSlice{
+		{"AppMenu", ki.BlankProp{}},
+		{"File", ki.PropSlice{
+			{"OpenPrefs", ki.Props{}},
+			{"SavePrefs", ki.Props{
+				"shortcut": "Command+S",
+				"updtfunc": giv.ActionUpdateFunc(func(mci interface{}, act *gi.Action) {
+					act.SetActiveState(AvailMacrosChanged && mci.(*Macros) == &AvailMacros)
+				}),
+			}},
+			{"sep-file", ki.BlankProp{}},
+			{"OpenJSON", ki.Props{
+				"label":    "Open from file",
+				"desc":     "You can save and open named keyboard macros to / from files to share, experiment, transfer, etc",
+				"shortcut": "Command+O",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+			{"SaveJSON", ki.Props{
+				"label": "Save to file",
+				"desc":  "You can save and open named keyboard macros to / from files to share, experiment, transfer, etc",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json",
+					}},
+				},
+			}},
+		}},
+		{"Edit", "Copy Cut Paste Dupe"},
+		{"Window", "Windows"},
+	},
+	"ToolBar": ki.PropSlice{
+		{"SavePrefs", ki.Props{
+			"desc": "saves Macros to App standard prefs directory, in file macros_prefs.json, which will be loaded automatically at startup)",
+			"icon": "file-save",
+			"updtfunc": giv.ActionUpdateFunc(func(mci interface{}, act *gi.Action) {
+				act.SetActiveState(AvailMacrosChanged && mci.(*Macros) == &AvailMacros)
+			}),
+		}},
+		{"sep-file", ki.BlankProp{}},
+		{"OpenJSON", ki.Props{
+			"label": "Open from file",
+			"icon":  "file-open",
+			"desc":  "You can save and open named keyboard macros to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+		{"SaveJSON", ki.Props{
+			"label": "Save to file",
+			"icon":  "file-save",
+			"desc":  "You can save and open named keyboard macros to / from files to share, experiment, transfer, etc",
+			"Args": ki.PropSlice{
+				{"File Name", ki.Props{
+					"ext": ".json",
+				}},
+			},
+		}},
+	},
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/ki/ints"
+	"github.com/goki/ki/ki"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/token"
+)
+
+// IncrMarkupMinLines is the minimum number of lines in a buffer for
+// incremental re-highlighting to be used -- smaller buffers just use the
+// standard delayed re-markup of the entire buffer, which is fast enough
+var IncrMarkupMinLines = 1000
+
+// IncrMarkupChunk is the initial number of lines past an edit that are
+// re-lexed at a time -- this is doubled until the new tags converge with
+// the existing ones
+var IncrMarkupChunk = 50
+
+// ConfigMarkup connects given buffer to the gide-specific markup
+// functionality: incremental re-highlighting of only the edited region
+// (see IncrMarkup), and rainbow coloring of brackets by depth, if set for
+// the language (see RainbowMarkup).
+func ConfigMarkup(tb *giv.TextBuf) {
+	tb.TextBufSig.Connect(tb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		tbb := recv.Embed(giv.KiT_TextBuf).(*giv.TextBuf)
+		switch sig {
+		case int64(giv.TextBufInsert), int64(giv.TextBufDelete):
+			tbe, ok := data.(*textbuf.Edit)
+			if !ok || tbe == nil {
+				return
+			}
+			updt := IncrMarkup(tbb, tbe)
+			if RainbowBrackets(tbb.Info.Sup) {
+				st, ed := tbe.Reg.Start.Ln, tbe.Reg.End.Ln
+				if tbe.Delete {
+					ed = st
+				}
+				if updt || EditHasBrackets(tbe) { // depth changes downstream
+					ed = -1
+				}
+				RainbowMarkup(tbb, st, ed)
+				updt = true
+			}
+			if updt { // edit as data marks it as ours
+				tbb.TextBufSig.Emit(tbb.This(), int64(giv.TextBufMarkUpdt), tbe)
+			}
+		case int64(giv.TextBufMarkUpdt):
+			if _, ours := data.(*textbuf.Edit); ours {
+				return
+			}
+			if RainbowBrackets(tbb.Info.Sup) {
+				RainbowMarkup(tbb, 0, -1)
+				tbb.TextBufSig.Emit(tbb.This(), int64(giv.TextBufMarkUpdt), &textbuf.Edit{})
+			}
+		}
+	})
+	if RainbowBrackets(tb.Info.Sup) {
+		RainbowMarkup(tb, 0, -1)
+	}
+}
+
+// IncrMarkupPiMaxLines is the maximum number of lines past an edit that are
+// re-lexed for a buffer using GoPi, if their tags do not converge before --
+// the delayed full re-markup, still needed for parsing, then updates the rest
+var IncrMarkupPiMaxLines = 2000
+
+// IncrMarkup does incremental re-highlighting for given edit, replacing the
+// delayed full re-markup -- returns true if lines beyond the edit itself were
+// updated, so views need to be updated.  For a buffer using GoPi, the lines
+// following the edit are re-lexed one by one, each from the lexer state at
+// the end of the previous one, until their tags converge with the existing
+// ones, but the delayed full re-markup is kept, as GoPi needs it for the
+// parse (symbols, completion) and not just for the tags.
+func IncrMarkup(tb *giv.TextBuf, tbe *textbuf.Edit) bool {
+	if !tb.Hi.HasHi() || tb.NLines < IncrMarkupMinLines {
+		return false
+	}
+	usePi := tb.Hi.UsingPi()
+	if !usePi {
+		tb.StopDelayedReMarkup()
+	}
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	tb.MarkupMu.Lock()
+	defer tb.MarkupMu.Unlock()
+	if !tb.IsMarkingUp() {
+		tb.MarkupEdits = nil // only needed for a full markup in progress
+	}
+	nln := ints.MinInt(len(tb.Lines), len(tb.HiTags))
+	st := ints.MinInt(tbe.Reg.Start.Ln, nln-1)
+	ed := st
+	if !tbe.Delete {
+		ed = ints.MinInt(tbe.Reg.End.Ln, nln-1)
+	}
+	if st < 0 {
+		return false
+	}
+	if usePi {
+		return IncrMarkupPiLines(tb, st, ed, nln)
+	}
+	for st > 0 && MarkupLineContinues(tb.Lines[st-1], tb.HiTags[st-1]) {
+		st--
+	}
+	chunk := IncrMarkupChunk
+	for {
+		end := ints.MinInt(ed+1+chunk, nln)
+		txt := bytes.Join(tb.LineBytes[st:end], []byte("\n"))
+		txt = append(txt, '\n')
+		mtags, err := tb.Hi.ChromaTagsAll(txt)
+		if err != nil {
+			return false
+		}
+		last := -1
+		for ln := ed + 1; ln < end && ln-st < len(mtags); ln++ {
+			if MarkupLinesEqual(mtags[ln-st], tb.HiTags[ln]) {
+				last = ln // converged
+				break
+			}
+		}
+		if last < 0 && end < nln {
+			chunk *= 2
+			continue
+		}
+		if last < 0 {
+			last = end
+		}
+		for ln := st; ln < last && ln-st < len(mtags); ln++ {
+			tb.HiTags[ln] = mtags[ln-st]
+			tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], tb.AdjustedTags(ln))
+		}
+		return last > ed+1
+	}
+}
+
+// IncrMarkupPiLines re-lexes the lines of given buffer using GoPi from st,
+// through ed, the end of the edit, and on until the tags of a line are the
+// same as before, or IncrMarkupPiMaxLines past ed -- returns true if lines
+// beyond the edit were updated.  Must be called under LinesMu and MarkupMu.
+func IncrMarkupPiLines(tb *giv.TextBuf, st, ed, nln int) bool {
+	mx := ints.MinInt(ed+1+IncrMarkupPiMaxLines, nln)
+	last := ed
+	for ln := st; ln < mx; ln++ {
+		mt, err := tb.Hi.MarkupTagsLine(ln, tb.Lines[ln])
+		if err != nil {
+			break
+		}
+		if ln > ed && MarkupLinesEqual(mt, tb.HiTags[ln]) {
+			break // converged
+		}
+		tb.HiTags[ln] = mt
+		tb.Markup[ln] = tb.Hi.MarkupLine(tb.Lines[ln], mt, tb.AdjustedTags(ln))
+		last = ln
+	}
+	return last > ed
+}
+
+// MarkupLineContinues returns true if the given line ends within a comment
+// or string token, which could continue on to the next line
+func MarkupLineContinues(txt []rune, tags lex.Line) bool {
+	sz := len(tags)
+	if sz == 0 {
+		return false
+	}
+	lt := tags[sz-1]
+	if lt.Ed < len(txt) {
+		return false
+	}
+	return lt.Tok.Tok.InCat(token.Comment) || lt.Tok.Tok.InSubCat(token.LitStr)
+}
+
+// MarkupLinesEqual returns true if the two lines have the same tokens in the
+// same positions
+func MarkupLinesEqual(a, b lex.Line) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Tok.Tok != b[i].Tok.Tok || a[i].St != b[i].St || a[i].Ed != b[i].Ed {
+			return false
+		}
+	}
+	return true
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//   Rainbow Brackets
+
+// RainbowColors are the colors used for successive depths of nested brackets
+// when RainbowBrackets is set for a language -- cycles through the list
+var RainbowColors = []string{"#d19a66", "#c678dd", "#56b6c2", "#e5c07b", "#61afef", "#98c379"}
+
+// RainbowBrackets returns true if rainbow bracket coloring is set for given
+// language in AvailLangs
+func RainbowBrackets(sup filecat.Supported) bool {
+	if lopt, has := AvailLangs[sup]; has {
+		return lopt.RainbowBrackets
+	}
+	return false
+}
+
+// EditHasBrackets returns true if the text of given edit contains any
+// bracket characters, in which case the depths of all following brackets
+// may have changed
+func EditHasBrackets(tbe *textbuf.Edit) bool {
+	for _, ln := range tbe.Text {
+		for _, r := range ln {
+			if BracketDepthDelta(r) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// BracketDepthDelta returns +1 for an open bracket, -1 for a close bracket,
+// and 0 otherwise
+func BracketDepthDelta(r rune) int {
+	switch r {
+	case '(', '[', '{':
+		return 1
+	case ')', ']', '}':
+		return -1
+	}
+	return 0
+}
+
+// RainbowTagSkip returns true if given tag is one where brackets are not
+// counted: comments and literals (strings etc)
+func RainbowTagSkip(tg *lex.Lex) bool {
+	return tg.Tok.Tok.InCat(token.Comment) || tg.Tok.Tok.InCat(token.Literal)
+}
+
+// RainbowBracketPos returns the positions of all brackets on given line that
+// are not in comments or literals, along with the depth for each, starting
+// at given depth -- each close bracket has the same depth as its open.
+// Returns the depth at the end of the line.
+func RainbowBracketPos(txt []rune, tags lex.Line, depth int) (pos, depths []int, edepth int) {
+	ti := 0
+	for i, r := range txt {
+		dd := BracketDepthDelta(r)
+		if dd == 0 {
+			continue
+		}
+		for ti < len(tags) && tags[ti].Ed <= i {
+			ti++
+		}
+		if ti < len(tags) && tags[ti].St <= i && RainbowTagSkip(&tags[ti]) {
+			continue
+		}
+		if dd > 0 {
+			pos = append(pos, i)
+			depths = append(depths, depth)
+			depth++
+		} else {
+			if depth > 0 {
+				depth--
+			}
+			pos = append(pos, i)
+			depths = append(depths, depth)
+		}
+	}
+	return pos, depths, depth
+}
+
+// RainbowMarkupLine adds color spans for brackets to given markup for line,
+// starting at given bracket depth.  Returns the new markup and the depth at
+// the end of the line.
+func RainbowMarkupLine(mu []byte, txt []rune, tags lex.Line, depth int) ([]byte, int) {
+	pos, depths, edepth := RainbowBracketPos(txt, tags, depth)
+	if len(pos) == 0 {
+		return mu, edepth
+	}
+	nmu := make([]byte, 0, len(mu)+len(pos)*32)
+	ri := 0 // rune index in txt
+	pi := 0 // index in pos
+	sz := len(mu)
+	for i := 0; i < sz; {
+		c := mu[i]
+		switch {
+		case c == '<': // markup tag -- copy as is
+			e := bytes.IndexByte(mu[i:], '>')
+			if e < 0 {
+				e = sz - i - 1
+			}
+			nmu = append(nmu, mu[i:i+e+1]...)
+			i += e + 1
+		case c == '&': // escaped entity = one rune
+			e := bytes.IndexByte(mu[i:], ';')
+			if e < 0 {
+				e = 0
+			}
+			nmu = append(nmu, mu[i:i+e+1]...)
+			i += e + 1
+			ri++
+		default:
+			_, rsz := utf8.DecodeRune(mu[i:])
+			if pi < len(pos) && pos[pi] == ri {
+				clr := RainbowColors[depths[pi]%len(RainbowColors)]
+				nmu = append(nmu, fmt.Sprintf(`<span style="color: %s">`, clr)...)
+				nmu = append(nmu, mu[i:i+rsz]...)
+				nmu = append(nmu, "</span>"...)
+				pi++
+			} else {
+				nmu = append(nmu, mu[i:i+rsz]...)
+			}
+			i += rsz
+			ri++
+		}
+	}
+	return nmu, edepth
+}
+
+// RainbowMarkup regenerates markup for lines st through ed (inclusive, -1 =
+// to end) of given buffer with brackets colored according to their nesting
+// depth, which is computed from the start of the buffer.
+func RainbowMarkup(tb *giv.TextBuf, st, ed int) {
+	if !tb.Hi.HasHi() {
+		return
+	}
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	tb.MarkupMu.Lock()
+	defer tb.MarkupMu.Unlock()
+	nln := ints.MinInt(len(tb.Lines), ints.MinInt(len(tb.HiTags), len(tb.Markup)))
+	if ed < 0 || ed >= nln {
+		ed = nln - 1
+	}
+	depth := 0
+	for ln := 0; ln < st && ln < nln; ln++ {
+		_, _, depth = RainbowBracketPos(tb.Lines[ln], tb.HiTags[ln], depth)
+	}
+	for ln := st; ln <= ed; ln++ {
+		mu := tb.Hi.MarkupLine(tb.Lines[ln], tb.HiTags[ln], tb.AdjustedTags(ln))
+		tb.Markup[ln], depth = RainbowMarkupLine(mu, tb.Lines[ln], tb.HiTags[ln], depth)
+	}
+}
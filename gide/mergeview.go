@@ -0,0 +1,401 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/lex"
+)
+
+// MergeConflict is a conflict in a merged file, between the conflict
+// markers written by git: <<<<<<< (local), ||||||| (base, with the diff3
+// conflict style), ======= (remote), and >>>>>>>
+type MergeConflict struct {
+	St   int `desc:"line of the <<<<<<< marker"`
+	Base int `desc:"line of the ||||||| marker, -1 if none"`
+	Mid  int `desc:"line of the ======= marker"`
+	Ed   int `desc:"line of the >>>>>>> marker"`
+}
+
+// MergeConflicts returns the conflicts in given lines of a merged file
+func MergeConflicts(lns []string) []MergeConflict {
+	var mcs []MergeConflict
+	mc := MergeConflict{St: -1, Base: -1, Mid: -1}
+	for i, ln := range lns {
+		switch {
+		case strings.HasPrefix(ln, "<<<<<<<"):
+			mc = MergeConflict{St: i, Base: -1, Mid: -1}
+		case mc.St < 0:
+		case strings.HasPrefix(ln, "|||||||") && mc.Mid < 0:
+			mc.Base = i
+		case ln == "=======" && mc.Mid < 0:
+			mc.Mid = i
+		case strings.HasPrefix(ln, ">>>>>>>") && mc.Mid >= 0:
+			mc.Ed = i
+			mcs = append(mcs, mc)
+			mc = MergeConflict{St: -1, Base: -1, Mid: -1}
+		}
+	}
+	return mcs
+}
+
+// Local returns the local lines of the conflict, in given lines
+func (mc *MergeConflict) Local(lns []string) []string {
+	if mc.Base >= 0 {
+		return lns[mc.St+1 : mc.Base]
+	}
+	return lns[mc.St+1 : mc.Mid]
+}
+
+// BaseLines returns the base lines of the conflict, in given lines -- nil
+// without the diff3 conflict style
+func (mc *MergeConflict) BaseLines(lns []string) []string {
+	if mc.Base < 0 {
+		return nil
+	}
+	return lns[mc.Base+1 : mc.Mid]
+}
+
+// Remote returns the remote lines of the conflict, in given lines
+func (mc *MergeConflict) Remote(lns []string) []string {
+	return lns[mc.Mid+1 : mc.Ed]
+}
+
+// MergeView is the view of a 3-way merge, as a git mergetool: the diffs of
+// the local and remote versions, and the merged file, in which the
+// conflicts are resolved by taking a version, or editing
+type MergeView struct {
+	gi.Layout
+	Base   string          `desc:"the common ancestor"`
+	Local  string          `desc:"the local version (ours)"`
+	Remote string          `desc:"the remote version (theirs)"`
+	Merged string          `desc:"the merged file, with the conflict markers"`
+	Done   func(ok bool)   `json:"-" xml:"-" view:"-" desc:"function called when done: ok if the merge is resolved, and the merged file saved"`
+	Confs  []MergeConflict `json:"-" xml:"-" desc:"the conflicts of the merged file"`
+}
+
+var KiT_MergeView = kit.Types.AddType(&MergeView{}, MergeViewProps)
+
+// Config configures the view for given files: merging them with git
+// merge-file into the merged file if it has no conflict markers
+func (mv *MergeView) Config(base, local, remote, merged string) error {
+	mv.Base, mv.Local, mv.Remote, mv.Merged = base, local, remote, merged
+	mb, err := ioutil.ReadFile(merged)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(MergeConflicts(textbuf.BytesToLineStrings(mb, false))) == 0 {
+		cmd := exec.Command("git", "merge-file", "-p", "--diff3", "-L", "local", "-L", "base", "-L", "remote", local, base, remote)
+		out, cerr := cmd.Output()
+		if _, ok := cerr.(*exec.ExitError); cerr != nil && !ok { // exit status is the number of conflicts
+			return cerr
+		}
+		if err = ioutil.WriteFile(merged, out, 0644); err != nil {
+			return err
+		}
+	}
+	lb, err := ioutil.ReadFile(local)
+	if err != nil {
+		return err
+	}
+	rb, err := ioutil.ReadFile(remote)
+	if err != nil {
+		return err
+	}
+	mv.Lay = gi.LayoutVert
+	mv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "mergebar")
+	config.Add(gi.KiT_SplitView, "mergesplit")
+	mods, updt := mv.ConfigChildren(config)
+	if !mods {
+		updt = mv.UpdateStart()
+	}
+	mv.ConfigToolbar()
+	split := mv.SplitView()
+	split.Dim = mat32.Y
+	split.SetStretchMaxWidth()
+	split.SetStretchMaxHeight()
+	if len(split.Kids) == 0 {
+		dv := split.AddNewChild(giv.KiT_DiffView, "diff").(*giv.DiffView)
+		dv.SetStretchMax()
+		ly := gi.AddNewLayout(split, "merged", gi.LayoutVert)
+		ly.SetStretchMax()
+		ly.SetMinPrefWidth(units.NewValue(20, units.Ch))
+		ly.SetMinPrefHeight(units.NewValue(10, units.Ch))
+		tv := ly.AddNewChild(giv.KiT_TextView, "merged").(*giv.TextView)
+		tv.SetProp("font-family", gi.Prefs.MonoFont)
+		tv.SetStretchMax()
+		tv.SetBuf(giv.NewTextBuf())
+		split.SetSplits(.5, .5)
+	}
+	dv := mv.DiffView()
+	dv.FileA = local + " (local)"
+	dv.FileB = remote + " (remote)"
+	dv.DiffStrings(textbuf.BytesToLineStrings(lb, false), textbuf.BytesToLineStrings(rb, false))
+	buf := mv.TextView().Buf
+	buf.Opts.LineNos = true
+	if err = buf.Open(gi.FileName(merged)); err != nil {
+		mv.UpdateEnd(updt)
+		return err
+	}
+	mv.UpdateEnd(updt)
+	mv.UpdateConfs()
+	if len(mv.Confs) > 0 {
+		mv.ShowConflict(0)
+	}
+	return nil
+}
+
+// ToolBar returns the toolbar
+func (mv *MergeView) ToolBar() *gi.ToolBar {
+	return mv.ChildByName("mergebar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the diffs and the merged file
+func (mv *MergeView) SplitView() *gi.SplitView {
+	return mv.ChildByName("mergesplit", 1).(*gi.SplitView)
+}
+
+// DiffView returns the view of the diffs of the local and remote versions
+func (mv *MergeView) DiffView() *giv.DiffView {
+	return mv.SplitView().ChildByName("diff", 0).(*giv.DiffView)
+}
+
+// TextView returns the TextView of the merged file
+func (mv *MergeView) TextView() *giv.TextView {
+	ly := mv.SplitView().ChildByName("merged", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// UpdateConfs updates the conflicts, and the status, from the merged file
+func (mv *MergeView) UpdateConfs() {
+	mv.Confs = MergeConflicts(mv.TextView().Buf.Strings(false))
+	msg := "no conflicts left -- Done to save and finish the merge"
+	if n := len(mv.Confs); n > 0 {
+		msg = fmt.Sprintf("%d conflicts left", n)
+	}
+	lbl := mv.ToolBar().ChildByName("status", 0).(*gi.Label)
+	lbl.SetText(msg)
+}
+
+// ConflictAtCursor returns the index of the conflict at the cursor, or
+// the next one after it, -1 if none
+func (mv *MergeView) ConflictAtCursor() int {
+	ln := mv.TextView().CursorPos.Ln
+	for i, mc := range mv.Confs {
+		if ln <= mc.Ed {
+			return i
+		}
+	}
+	return -1
+}
+
+// ShowConflict moves the cursor to the conflict of given index
+func (mv *MergeView) ShowConflict(idx int) {
+	tv := mv.TextView()
+	tv.SetCursorShow(lex.Pos{Ln: mv.Confs[idx].St})
+	tv.GrabFocus()
+}
+
+// NextConflict moves the cursor to the next conflict, wrapping around
+func (mv *MergeView) NextConflict() {
+	if len(mv.Confs) == 0 {
+		return
+	}
+	ln := mv.TextView().CursorPos.Ln
+	for i, mc := range mv.Confs {
+		if mc.St > ln {
+			mv.ShowConflict(i)
+			return
+		}
+	}
+	mv.ShowConflict(0)
+}
+
+// PrevConflict moves the cursor to the previous conflict, wrapping around
+func (mv *MergeView) PrevConflict() {
+	n := len(mv.Confs)
+	if n == 0 {
+		return
+	}
+	ln := mv.TextView().CursorPos.Ln
+	for i := n - 1; i >= 0; i-- {
+		if mv.Confs[i].St < ln {
+			mv.ShowConflict(i)
+			return
+		}
+	}
+	mv.ShowConflict(n - 1)
+}
+
+// Take resolves the conflict at the cursor with the lines returned by given
+// function, e.g., its local lines, and moves to the next one
+func (mv *MergeView) Take(lines func(mc *MergeConflict, lns []string) []string) {
+	mv.UpdateConfs()
+	idx := mv.ConflictAtCursor()
+	if idx < 0 {
+		return
+	}
+	mc := &mv.Confs[idx]
+	buf := mv.TextView().Buf
+	lns := buf.Strings(false)
+	txt := strings.Join(lines(mc, lns), "\n")
+	st := lex.Pos{Ln: mc.St}
+	ed := lex.Pos{Ln: mc.Ed + 1}
+	if ed.Ln >= buf.NumLines() {
+		ed = lex.Pos{Ln: mc.Ed, Ch: len([]rune(lns[mc.Ed]))}
+	} else if txt != "" {
+		txt += "\n"
+	}
+	buf.ReplaceText(st, ed, st, txt, giv.EditSignal, false)
+	mv.TextView().SetCursorShow(st)
+	mv.UpdateConfs()
+	if idx = mv.ConflictAtCursor(); idx >= 0 {
+		mv.ShowConflict(idx)
+	} else if len(mv.Confs) > 0 {
+		mv.NextConflict()
+	}
+}
+
+// TakeLocal resolves the conflict at the cursor with the local version
+func (mv *MergeView) TakeLocal() {
+	mv.Take(func(mc *MergeConflict, lns []string) []string { return mc.Local(lns) })
+}
+
+// TakeRemote resolves the conflict at the cursor with the remote version
+func (mv *MergeView) TakeRemote() {
+	mv.Take(func(mc *MergeConflict, lns []string) []string { return mc.Remote(lns) })
+}
+
+// TakeBoth resolves the conflict at the cursor with the local version,
+// followed by the remote one
+func (mv *MergeView) TakeBoth() {
+	mv.Take(func(mc *MergeConflict, lns []string) []string {
+		return append(append([]string{}, mc.Local(lns)...), mc.Remote(lns)...)
+	})
+}
+
+// TakeBase resolves the conflict at the cursor with the base version
+func (mv *MergeView) TakeBase() {
+	mv.Take(func(mc *MergeConflict, lns []string) []string { return mc.BaseLines(lns) })
+}
+
+// Save saves the merged file
+func (mv *MergeView) Save() error {
+	err := mv.TextView().Buf.SaveFile(gi.FileName(mv.Merged))
+	if err != nil {
+		gi.PromptDialog(mv.Viewport, gi.DlgOpts{Title: "Could Not Save", Prompt: fmt.Sprintf("Could not save the merged file %v: %v", mv.Merged, err)}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+	return err
+}
+
+// Finish saves the merged file, and ends the merge as resolved -- after
+// confirming if conflicts are left
+func (mv *MergeView) Finish() {
+	mv.UpdateConfs()
+	if len(mv.Confs) == 0 {
+		if mv.Save() == nil && mv.Done != nil {
+			mv.Done(true)
+		}
+		return
+	}
+	gi.ChoiceDialog(mv.Viewport, gi.DlgOpts{Title: "Conflicts Left", Prompt: fmt.Sprintf("%d conflicts are left in the merged file -- save it with their markers, as resolved?", len(mv.Confs))},
+		[]string{"Save As Resolved", "Cancel"}, mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != 0 {
+				return
+			}
+			if mv.Save() == nil && mv.Done != nil {
+				mv.Done(true)
+			}
+		})
+}
+
+// Abort ends the merge as unresolved, without saving
+func (mv *MergeView) Abort() {
+	if mv.Done != nil {
+		mv.Done(false)
+	}
+}
+
+// ConfigToolbar adds the toolbar actions
+func (mv *MergeView) ConfigToolbar() {
+	tb := mv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Prev", Icon: "wedge-up", Tooltip: "move to the previous conflict"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.PrevConflict()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Next", Icon: "wedge-down", Tooltip: "move to the next conflict"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.NextConflict()
+		})
+	tb.AddSeparator("sep-take")
+	tb.AddAction(gi.ActOpts{Label: "Take Local", Icon: "wedge-left", Tooltip: "resolve the conflict at the cursor with the local version (ours)"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.TakeLocal()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Take Remote", Icon: "wedge-right", Tooltip: "resolve the conflict at the cursor with the remote version (theirs)"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.TakeRemote()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Take Both", Icon: "plus", Tooltip: "resolve the conflict at the cursor with the local version, followed by the remote one"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.TakeBoth()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Take Base", Icon: "update", Tooltip: "resolve the conflict at the cursor with the base version (the common ancestor)"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.TakeBase()
+		})
+	tb.AddSeparator("sep-save")
+	tb.AddAction(gi.ActOpts{Label: "Save", Icon: "file-save", Tooltip: "save the merged file, without finishing the merge"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.Save()
+			mvv.UpdateConfs()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Done", Icon: "checkmark", Tooltip: "save the merged file, and finish the merge as resolved"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.Finish()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Abort", Icon: "close", Tooltip: "finish the merge as unresolved, without saving"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_MergeView).(*MergeView)
+			mvv.Abort()
+		})
+	tb.AddSeparator("sep-status")
+	gi.AddNewLabel(tb, "status", "")
+}
+
+// MergeViewProps are style properties for MergeView
+var MergeViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
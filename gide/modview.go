@@ -0,0 +1,586 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// ModReq is a requirement of a Go module, from its go.mod file, with the
+// latest version available, as listed by go list -m -u
+type ModReq struct {
+	Path     string `width:"40" desc:"module path"`
+	Version  string `desc:"version required in go.mod"`
+	Latest   string `desc:"latest version available, if newer than the required one"`
+	Indirect bool   `desc:"true if the requirement is marked // indirect"`
+	Replace  string `desc:"replacement module (and version), or directory, from a replace directive"`
+	InSum    bool   `desc:"true if go.sum has the checksum of the version"`
+}
+
+// ModFile is the part of the output of go mod edit -json that lists the
+// requirements and replacements
+type ModFile struct {
+	Module struct {
+		Path string
+	}
+	Go      string
+	Require []struct {
+		Path     string
+		Version  string
+		Indirect bool
+	}
+	Replace []struct {
+		Old struct{ Path, Version string }
+		New struct{ Path, Version string }
+	}
+}
+
+// ModListInfo is a module in the output of go list -m -json
+type ModListInfo struct {
+	Path    string
+	Version string
+	Update  *struct {
+		Version string
+	}
+}
+
+// GoModCmd runs a go command in given module directory, returning its
+// combined output
+func GoModCmd(dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("go %v: %v: %v", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// ParseModFile parses the output of go mod edit -json into the requirements
+// of the module, sorted with the direct ones first, then by path, and the
+// module path
+func ParseModFile(js []byte) ([]ModReq, string, error) {
+	mf := ModFile{}
+	if err := json.Unmarshal(js, &mf); err != nil {
+		return nil, "", fmt.Errorf("could not parse go mod edit -json output: %v", err)
+	}
+	reps := map[string]string{}
+	for _, rp := range mf.Replace {
+		nw := rp.New.Path
+		if rp.New.Version != "" {
+			nw += " " + rp.New.Version
+		}
+		reps[rp.Old.Path] = nw
+		if rp.Old.Version != "" {
+			reps[rp.Old.Path+"@"+rp.Old.Version] = nw
+		}
+	}
+	reqs := make([]ModReq, len(mf.Require))
+	for i, rq := range mf.Require {
+		mr := ModReq{Path: rq.Path, Version: rq.Version, Indirect: rq.Indirect}
+		if nw, has := reps[rq.Path+"@"+rq.Version]; has {
+			mr.Replace = nw
+		} else if nw, has := reps[rq.Path]; has {
+			mr.Replace = nw
+		}
+		reqs[i] = mr
+	}
+	sort.SliceStable(reqs, func(i, j int) bool {
+		if reqs[i].Indirect != reqs[j].Indirect {
+			return !reqs[i].Indirect
+		}
+		return reqs[i].Path < reqs[j].Path
+	})
+	return reqs, mf.Module.Path, nil
+}
+
+// GoSumHas returns the module@version entries of the contents of a go.sum
+// file that have the checksum of the module, not just its go.mod
+func GoSumHas(sum []byte) map[string]bool {
+	has := map[string]bool{}
+	for _, ln := range strings.Split(string(sum), "\n") {
+		fs := strings.Fields(ln)
+		if len(fs) < 3 || strings.HasSuffix(fs[1], "/go.mod") {
+			continue
+		}
+		has[fs[0]+"@"+fs[1]] = true
+	}
+	return has
+}
+
+// ParseModUpdates parses the output of go list -m -u -json, returning the
+// newer versions by module path
+func ParseModUpdates(js []byte) map[string]string {
+	upds := map[string]string{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	for {
+		mi := ModListInfo{}
+		if err := dec.Decode(&mi); err != nil {
+			break
+		}
+		if mi.Update != nil {
+			upds[mi.Path] = mi.Update.Version
+		}
+	}
+	return upds
+}
+
+// ParseModGraph parses the output of go mod graph, returning the modules
+// required by each module, as path@version (the main module has no version)
+func ParseModGraph(out []byte) map[string][]string {
+	gr := map[string][]string{}
+	for _, ln := range strings.Split(string(out), "\n") {
+		fs := strings.Fields(ln)
+		if len(fs) != 2 {
+			continue
+		}
+		gr[fs[0]] = append(gr[fs[0]], fs[1])
+	}
+	return gr
+}
+
+// ModGraphPath returns the shortest path in given module graph from the
+// main module to a version of the module of given path, as path@version --
+// nil if it is not required
+func ModGraphPath(gr map[string][]string, main, target string) []string {
+	prev := map[string]string{main: ""}
+	queue := []string{main}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur != main && strings.SplitN(cur, "@", 2)[0] == target {
+			var pth []string
+			for n := cur; n != ""; n = prev[n] {
+				pth = append([]string{n}, pth...)
+			}
+			return pth
+		}
+		for _, nx := range gr[cur] {
+			if _, seen := prev[nx]; !seen {
+				prev[nx] = cur
+				queue = append(queue, nx)
+			}
+		}
+	}
+	return nil
+}
+
+// ModVersionsBefore returns the versions of the module of given path
+// older than given one, newest first, as listed by go list -m -versions
+func ModVersionsBefore(dir string, env []string, path, version string) ([]string, error) {
+	out, err := GoModCmd(dir, env, "list", "-m", "-versions", path)
+	if err != nil {
+		return nil, err
+	}
+	fs := strings.Fields(string(out)) // path, then versions oldest first
+	end := len(fs)
+	for i := 1; i < len(fs); i++ {
+		if fs[i] == version {
+			end = i
+			break
+		}
+	}
+	var vers []string
+	for i := end - 1; i > 0; i-- {
+		vers = append(vers, fs[i])
+	}
+	return vers, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    ModView
+
+// ModView is the go.mod dependency manager: it lists the requirements of
+// the module of the project, with their latest versions, and upgrades,
+// downgrades, tidies, and shows why a module is needed
+type ModView struct {
+	gi.Layout
+	Gide    Gide     `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Dir     string   `desc:"directory of the go.mod file"`
+	ModPath string   `desc:"path of the module"`
+	Reqs    []ModReq `desc:"the requirements of the module"`
+	Running bool     `json:"-" xml:"-" desc:"true while a go command is running"`
+}
+
+var KiT_ModView = kit.Types.AddType(&ModView{}, ModViewProps)
+
+// ModCmdName is the name of the go commands run by the ModView in the
+// CmdRuns of the project, for stopping them
+var ModCmdName = "Go Mod"
+
+// Config configures the view for the module of the project
+func (mv *ModView) Config(ge Gide) {
+	mv.Gide = ge
+	mv.Lay = gi.LayoutVert
+	mv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "modbar")
+	config.Add(gi.KiT_SplitView, "modsplit")
+	mods, updt := mv.ConfigChildren(config)
+	if !mods {
+		updt = mv.UpdateStart()
+	}
+	mv.ConfigToolbar()
+	mv.ConfigSplitView()
+	mv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (mv *ModView) ToolBar() *gi.ToolBar {
+	return mv.ChildByName("modbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the requirements and the output
+func (mv *ModView) SplitView() *gi.SplitView {
+	return mv.ChildByName("modsplit", 1).(*gi.SplitView)
+}
+
+// TableView returns the table of the requirements
+func (mv *ModView) TableView() *giv.TableView {
+	return mv.SplitView().ChildByName("reqs", 0).(*giv.TableView)
+}
+
+// TextView returns the TextView of the output of the commands
+func (mv *ModView) TextView() *giv.TextView {
+	ly := mv.SplitView().ChildByName("out", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// SelReq returns the selected requirement, nil if none
+func (mv *ModView) SelReq() *ModReq {
+	idx := mv.TableView().SelectedIdx
+	if idx < 0 || idx >= len(mv.Reqs) {
+		mv.Gide.SetStatus("select a requirement first")
+		return nil
+	}
+	return &mv.Reqs[idx]
+}
+
+// ConfigToolbar adds the toolbar actions
+func (mv *ModView) ConfigToolbar() {
+	tb := mv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "re-read go.mod and go.sum, and check for the latest versions with go list -m -u"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			mvv.Refresh(true)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Upgrade", Icon: "wedge-up", Tooltip: "upgrade the selected module to its latest version, with go get module@latest"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			if mr := mvv.SelReq(); mr != nil {
+				mvv.Get(mr.Path, "latest")
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Downgrade...", Icon: "wedge-down", Tooltip: "downgrade the selected module to an older version, chosen from those listed by go list -m -versions"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			mvv.ChooseDowngrade()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Upgrade All", Icon: "wedge-up", Tooltip: "upgrade all the direct requirements to their latest minor or patch versions, with go get -u ./..."},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			mvv.RunGo("get", "-u", "./...")
+		})
+	tb.AddAction(gi.ActOpts{Label: "Tidy", Icon: "gear", Tooltip: "add the missing and remove the unused requirements, with go mod tidy"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			mvv.RunGo("mod", "tidy")
+		})
+	tb.AddAction(gi.ActOpts{Label: "Why", Icon: "info", Tooltip: "show why the selected module is needed: the path to it in the module graph, and the imports that need it (go mod why -m)"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			if mr := mvv.SelReq(); mr != nil {
+				mvv.Why(mr.Path)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "go.mod", Icon: "file-text", Tooltip: "open the go.mod file"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			if mvv.Dir != "" {
+				mvv.Gide.ShowFile(filepath.Join(mvv.Dir, "go.mod"), 1)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop the running go command"},
+		mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			mvv.Gide.CmdRuns().KillByName(ModCmdName)
+		})
+}
+
+// ConfigSplitView configures the split view of the requirements and the
+// output of the commands
+func (mv *ModView) ConfigSplitView() {
+	split := mv.SplitView()
+	split.Dim = mat32.Y
+	if len(split.Kids) > 0 {
+		return
+	}
+	tv := split.AddNewChild(giv.KiT_TableView, "reqs").(*giv.TableView)
+	tv.SetStretchMax()
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SetInactive()
+	tv.SetSlice(&mv.Reqs)
+	tv.SliceViewSig.Connect(mv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.SliceViewDoubleClicked) {
+			mvv, _ := recv.Embed(KiT_ModView).(*ModView)
+			if mr := mvv.SelReq(); mr != nil {
+				mvv.Why(mr.Path)
+			}
+		}
+	})
+	ly := gi.AddNewLayout(split, "out", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.6, .4)
+}
+
+// Refresh re-reads the requirements from go.mod and go.sum of the module
+// of the project, and checks for their latest versions in the background
+// if update is set (which needs the network)
+func (mv *ModView) Refresh(update bool) {
+	ge := mv.Gide
+	root, _, ok := GoModule(string(ge.ProjPrefs().ProjRoot))
+	if !ok {
+		mv.ShowOutput("no go.mod in the project directory or above it")
+		return
+	}
+	mv.Dir = root
+	env := ge.ProjPrefs().CmdEnv()
+	js, err := GoModCmd(root, env, "mod", "edit", "-json")
+	if err != nil {
+		mv.ShowOutput(err.Error())
+		return
+	}
+	reqs, mpath, err := ParseModFile(js)
+	if err != nil {
+		mv.ShowOutput(err.Error())
+		return
+	}
+	sum, _ := ioutil.ReadFile(filepath.Join(root, "go.sum"))
+	has := GoSumHas(sum)
+	for i := range reqs {
+		reqs[i].InSum = has[reqs[i].Path+"@"+reqs[i].Version]
+	}
+	mv.ModPath = mpath
+	mv.SetReqs(reqs)
+	if !update || len(reqs) == 0 || mv.Running {
+		return
+	}
+	mv.Running = true
+	ge.SetStatus("checking for the latest versions of the requirements...")
+	go func() {
+		args := []string{"list", "-m", "-u", "-json"}
+		for _, mr := range reqs {
+			args = append(args, mr.Path)
+		}
+		out, err := GoModCmd(root, env, args...)
+		mv.Running = false
+		if mv.This() == nil || mv.IsDeleted() || mv.IsDestroyed() {
+			return
+		}
+		if err != nil {
+			mv.ShowOutput(err.Error())
+			return
+		}
+		upds := ParseModUpdates(out)
+		for i := range reqs {
+			reqs[i].Latest = upds[reqs[i].Path]
+		}
+		mv.SetReqs(reqs)
+		msg := fmt.Sprintf("%d of %d requirements have newer versions", len(upds), len(reqs))
+		ge.SetStatus(msg)
+		if len(upds) > 0 {
+			ge.Notify(ProblemInfo, "modules", msg, NotifyAction{Label: "Go Mod", URL: "tab:///Go Mod"})
+		}
+	}()
+}
+
+// SetReqs sets the requirements shown
+func (mv *ModView) SetReqs(reqs []ModReq) {
+	vp := mv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	mv.Reqs = reqs
+	tv := mv.TableView()
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	tv.SetSlice(&mv.Reqs)
+	tv.UpdateEnd(updt)
+}
+
+// ShowOutput shows given output of a command, or error, below the
+// requirements
+func (mv *ModView) ShowOutput(out string) {
+	lns := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	mus := make([]string, len(lns))
+	for i, ln := range lns {
+		mus[i] = html.EscapeString(ln)
+	}
+	mv.ShowMarkup(lns, mus)
+}
+
+// ShowMarkup shows given lines, with markup, below the requirements
+func (mv *ModView) ShowMarkup(lns, mus []string) {
+	vp := mv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	tbuf := mv.TextView().Buf
+	tbuf.New(0)
+	tbuf.SetInactive(true)
+	tbuf.AppendTextMarkup([]byte(strings.Join(lns, "\n")), []byte(strings.Join(mus, "\n")), giv.EditSignal)
+}
+
+// RunGo runs the go command with given args in the module directory in the
+// background, showing its output, and then refreshes the requirements
+func (mv *ModView) RunGo(args ...string) {
+	if mv.Running {
+		mv.Gide.SetStatus("a go command is already running")
+		return
+	}
+	if mv.Dir == "" {
+		mv.Refresh(false)
+		if mv.Dir == "" {
+			return
+		}
+	}
+	ge := mv.Gide
+	mv.Running = true
+	cmdstr := "go " + strings.Join(args, " ")
+	mv.ShowOutput(cmdstr + "\n")
+	go func() {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = mv.Dir
+		cmd.Env = ge.ProjPrefs().CmdEnv()
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		ge.CmdRuns().AddCmd(ModCmdName, cmdstr, &CmdAndArgs{Cmd: "go", Args: args}, cmd)
+		err := cmd.Start()
+		if err == nil {
+			err = cmd.Wait()
+		}
+		ge.CmdRuns().DeleteByName(ModCmdName)
+		mv.Running = false
+		if mv.This() == nil || mv.IsDeleted() || mv.IsDestroyed() {
+			return
+		}
+		res := cmdstr + "\n" + out.String()
+		if err != nil {
+			res += "\n" + err.Error()
+		} else {
+			res += "\ndone"
+		}
+		mv.ShowOutput(res)
+		mv.Refresh(false)
+		ge.SetStatus(cmdstr + ": done")
+	}()
+}
+
+// Get runs go get for given module at given version, or query, e.g.,
+// latest
+func (mv *ModView) Get(path, version string) {
+	mv.RunGo("get", path+"@"+version)
+}
+
+// ChooseDowngrade pops up a menu of the versions of the selected module
+// older than the required one, for downgrading it to the one chosen
+func (mv *ModView) ChooseDowngrade() {
+	mr := mv.SelReq()
+	if mr == nil {
+		return
+	}
+	path := mr.Path
+	vers, err := ModVersionsBefore(mv.Dir, mv.Gide.ProjPrefs().CmdEnv(), path, mr.Version)
+	if err != nil {
+		mv.ShowOutput(err.Error())
+		return
+	}
+	if len(vers) == 0 {
+		mv.Gide.SetStatus(fmt.Sprintf("no version of %v older than %v", path, mr.Version))
+		return
+	}
+	if len(vers) > 30 {
+		vers = vers[:30]
+	}
+	tv := mv.TableView()
+	gi.StringsChooserPopup(vers, "", tv, func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		mv.Get(path, ac.Text)
+	})
+}
+
+// Why shows why the module of given path is needed: the shortest path to
+// it in the module graph (go mod graph), and the shortest import path to a
+// package of it (go mod why -m)
+func (mv *ModView) Why(path string) {
+	if mv.Dir == "" {
+		return
+	}
+	env := mv.Gide.ProjPrefs().CmdEnv()
+	lns := []string{"why " + path}
+	mus := []string{"<b>why " + html.EscapeString(path) + "</b>"}
+	gout, err := GoModCmd(mv.Dir, env, "mod", "graph")
+	if err != nil {
+		mv.ShowOutput(err.Error())
+		return
+	}
+	lns = append(lns, "", "module graph path:")
+	mus = append(mus, "", "<b>module graph path:</b>")
+	if mp := ModGraphPath(ParseModGraph(gout), mv.ModPath, path); mp != nil {
+		for i, m := range mp {
+			ln := strings.Repeat("  ", i) + m
+			lns = append(lns, ln)
+			mus = append(mus, html.EscapeString(ln))
+		}
+	} else {
+		lns = append(lns, "  not in the module graph")
+		mus = append(mus, "  not in the module graph")
+	}
+	wout, err := GoModCmd(mv.Dir, env, "mod", "why", "-m", path)
+	lns = append(lns, "", "imports (go mod why -m):")
+	mus = append(mus, "", "<b>imports (go mod why -m):</b>")
+	if err != nil {
+		lns = append(lns, err.Error())
+		mus = append(mus, html.EscapeString(err.Error()))
+	} else {
+		for _, ln := range strings.Split(strings.TrimSpace(string(wout)), "\n") {
+			if strings.HasPrefix(ln, "# ") {
+				continue
+			}
+			lns = append(lns, ln)
+			mus = append(mus, html.EscapeString(ln))
+		}
+	}
+	mv.ShowMarkup(lns, mus)
+}
+
+// ModViewProps are style properties for ModView
+var ModViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/pi/lex"
+)
+
+// NavHistoryMax is the maximum number of locations saved in the navigation history
+var NavHistoryMax = 200
+
+// NavPos is a cursor location within a file, for the navigation history
+type NavPos struct {
+	Filename gi.FileName `desc:"file name"`
+	Pos      lex.Pos     `desc:"cursor position within file"`
+}
+
+// NavHistory is a jump list of cursor locations across files, which are
+// saved prior to jumping elsewhere (e.g., following links, find results,
+// symbols, or opening another file), so that navigation can be retraced back
+// and forward.  It also records the location of the last edit.
+type NavHistory struct {
+	Locs     []NavPos `desc:"saved locations, oldest first"`
+	Idx      int      `desc:"index of current location within Locs while moving back and forward -- equal to len(Locs) when not within the history"`
+	LastEdit NavPos   `desc:"location of the last edit"`
+	Moving   bool     `desc:"true while moving back and forward, so that the resulting jumps are not saved"`
+}
+
+// Save saves given location prior to a jump -- any locations forward of the
+// current one are discarded, and it is not saved if on the same line as the
+// last one
+func (nh *NavHistory) Save(np NavPos) {
+	if nh.Moving || np.Filename == "" {
+		return
+	}
+	if nh.Idx < len(nh.Locs) {
+		nh.Locs = nh.Locs[:nh.Idx+1]
+	}
+	sz := len(nh.Locs)
+	if sz > 0 && nh.Locs[sz-1].Filename == np.Filename && nh.Locs[sz-1].Pos.Ln == np.Pos.Ln {
+		nh.Locs[sz-1] = np
+	} else {
+		nh.Locs = append(nh.Locs, np)
+		if len(nh.Locs) > NavHistoryMax {
+			nh.Locs = nh.Locs[len(nh.Locs)-NavHistoryMax:]
+		}
+	}
+	nh.Idx = len(nh.Locs)
+}
+
+// Back returns the previous location, given the current one, which is saved
+// if not already within the history, so Forward can return to it -- false if
+// at the start
+func (nh *NavHistory) Back(cur NavPos) (NavPos, bool) {
+	if nh.Idx >= len(nh.Locs) {
+		nh.Save(cur)
+		nh.Idx = len(nh.Locs) - 1
+	}
+	if nh.Idx <= 0 {
+		return NavPos{}, false
+	}
+	nh.Idx--
+	return nh.Locs[nh.Idx], true
+}
+
+// Forward returns the next location, false if at the end
+func (nh *NavHistory) Forward() (NavPos, bool) {
+	if nh.Idx >= len(nh.Locs)-1 {
+		return NavPos{}, false
+	}
+	nh.Idx++
+	return nh.Locs[nh.Idx], true
+}
+
+// SetLastEdit records the location of the last edit
+func (nh *NavHistory) SetLastEdit(np NavPos) {
+	nh.LastEdit = np
+}
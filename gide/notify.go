@@ -0,0 +1,224 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// NotifyMax is the maximum number of notifications kept per project
+var NotifyMax = 200
+
+// NotifyCmdMinTime is how long a command must run for its successful end to
+// be notified -- failures are always notified
+var NotifyCmdMinTime = 5 * time.Second
+
+// NotifyAction is an action offered by a notification, as a link: file:///
+// opens a file, tab:/// selects a tab, gide:/// calls a method of the
+// project (e.g., gide:///Problems), reload:/// reverts a file from disk,
+// and other URLs are opened in the browser
+type NotifyAction struct {
+	Label string `desc:"label of the link"`
+	URL   string `desc:"URL of the link"`
+}
+
+// Notification is a non-modal event of a project -- a command finished,
+// lint results, a file changed on disk, updates available -- kept in the
+// Notifications panel
+type Notification struct {
+	Time     time.Time       `desc:"when it happened"`
+	Severity ProblemSeverity `desc:"severity: error, warning or info"`
+	Source   string          `desc:"where it is from: command, lint, file, modules.."`
+	Msg      string          `desc:"the message, as plain text"`
+	Actions  []NotifyAction  `desc:"actions offered, as links"`
+}
+
+// NotifyList is the list of the notifications of a project, most recent
+// last, safe for concurrent use
+type NotifyList struct {
+	Notifs []Notification `desc:"the notifications"`
+	Unread int            `desc:"number of notifications not seen yet"`
+	Mu     sync.Mutex     `json:"-" xml:"-" view:"-" desc:"mutex protecting the list"`
+}
+
+// Add adds given notification, dropping the oldest ones beyond NotifyMax
+func (nl *NotifyList) Add(nt Notification) {
+	nl.Mu.Lock()
+	defer nl.Mu.Unlock()
+	if nt.Time.IsZero() {
+		nt.Time = time.Now()
+	}
+	nl.Notifs = append(nl.Notifs, nt)
+	if n := len(nl.Notifs); n > NotifyMax {
+		nl.Notifs = append(nl.Notifs[:0:0], nl.Notifs[n-NotifyMax:]...)
+	}
+	nl.Unread++
+}
+
+// All returns a copy of the notifications, most recent first
+func (nl *NotifyList) All() []Notification {
+	nl.Mu.Lock()
+	defer nl.Mu.Unlock()
+	nts := make([]Notification, len(nl.Notifs))
+	for i, nt := range nl.Notifs {
+		nts[len(nts)-1-i] = nt
+	}
+	return nts
+}
+
+// Clear removes all the notifications
+func (nl *NotifyList) Clear() {
+	nl.Mu.Lock()
+	defer nl.Mu.Unlock()
+	nl.Notifs = nil
+	nl.Unread = 0
+}
+
+// MarkRead marks all the notifications as seen
+func (nl *NotifyList) MarkRead() {
+	nl.Mu.Lock()
+	defer nl.Mu.Unlock()
+	nl.Unread = 0
+}
+
+// UnreadCounts returns the number of unread notifications, and the highest
+// severity among them
+func (nl *NotifyList) UnreadCounts() (int, ProblemSeverity) {
+	nl.Mu.Lock()
+	defer nl.Mu.Unlock()
+	sev := ProblemInfo
+	for i := len(nl.Notifs) - nl.Unread; i < len(nl.Notifs); i++ {
+		if i >= 0 && nl.Notifs[i].Severity < sev {
+			sev = nl.Notifs[i].Severity
+		}
+	}
+	return nl.Unread, sev
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    NotifyView
+
+// NotifyView shows the notifications of a project, most recent first, with
+// their severity, time, source and action links
+type NotifyView struct {
+	gi.Layout
+	Gide Gide        `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	List *NotifyList `json:"-" xml:"-" copy:"-" desc:"the list of notifications shown"`
+}
+
+var KiT_NotifyView = kit.Types.AddType(&NotifyView{}, NotifyViewProps)
+
+// Config configures the view to show given list
+func (nv *NotifyView) Config(ge Gide, nl *NotifyList) {
+	nv.Gide = ge
+	nv.List = nl
+	nv.Lay = gi.LayoutVert
+	nv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "notifybar")
+	config.Add(gi.KiT_Layout, "notifytext")
+	mods, updt := nv.ConfigChildren(config)
+	if !mods {
+		updt = nv.UpdateStart()
+	}
+	nv.ConfigToolbar()
+	ConfigOutputTextView(nv.TextViewLay())
+	nv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (nv *NotifyView) ToolBar() *gi.ToolBar {
+	return nv.ChildByName("notifybar", 0).(*gi.ToolBar)
+}
+
+// TextViewLay returns the layout of the TextView
+func (nv *NotifyView) TextViewLay() *gi.Layout {
+	return nv.ChildByName("notifytext", 1).(*gi.Layout)
+}
+
+// TextView returns the TextView of the notifications
+func (nv *NotifyView) TextView() *giv.TextView {
+	return nv.TextViewLay().ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (nv *NotifyView) ConfigToolbar() {
+	tb := nv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Clear", Icon: "close", Tooltip: "remove all the notifications"},
+		nv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			nvv, _ := recv.Embed(KiT_NotifyView).(*NotifyView)
+			nvv.List.Clear()
+			nvv.ShowNotifs()
+			nvv.Gide.SetStatus("")
+		})
+}
+
+// ShowNotifs shows the notifications, most recent first, and marks them
+// as read
+func (nv *NotifyView) ShowNotifs() {
+	if nv.List == nil {
+		return
+	}
+	nts := nv.List.All()
+	nv.List.MarkRead()
+	outlns := make([][]byte, 0, len(nts))
+	outmus := make([][]byte, 0, len(nts))
+	for _, nt := range nts {
+		icon := ProblemIcons[nt.Severity]
+		tm := nt.Time.Format("15:04:05")
+		if !SameDay(nt.Time, time.Now()) {
+			tm = nt.Time.Format("Jan 2 15:04:05")
+		}
+		ln := fmt.Sprintf("%v %v [%v] %v", icon, tm, nt.Source, nt.Msg)
+		mu := fmt.Sprintf(`<span style="color: %v">%v</span> <span style="color: grey">%v</span> [%v] %v`, ProblemColors[nt.Severity], icon, tm, html.EscapeString(nt.Source), html.EscapeString(nt.Msg))
+		for _, act := range nt.Actions {
+			ln += "  " + act.Label
+			mu += fmt.Sprintf(`  <a href="%v">%v</a>`, html.EscapeString(act.URL), html.EscapeString(act.Label))
+		}
+		outlns = append(outlns, []byte(ln))
+		outmus = append(outmus, []byte(mu))
+	}
+	if len(nts) == 0 {
+		outlns = append(outlns, []byte("no notifications"))
+		outmus = append(outmus, []byte("no notifications"))
+	}
+	ntv := nv.TextView()
+	nbuf := ntv.Buf
+	if nbuf == nil {
+		return
+	}
+	nbuf.New(0)
+	nbuf.SetInactive(true)
+	nbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), giv.EditSignal)
+}
+
+// SameDay returns true if given times are on the same day
+func SameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// NotifyViewProps are style properties for NotifyView
+var NotifyViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/giv"
+)
+
+// OutBufFlushMSec is the default number of milliseconds between the
+// appends of the output buffered by an OutBuf to its TextBuf
+var OutBufFlushMSec = 50
+
+// OutBufFlushLines is the number of lines of output buffered by an OutBuf
+// at which they are appended to its TextBuf, without waiting
+var OutBufFlushLines = 1000
+
+// OutBuf records the output from an io.Reader, e.g., of a command, to a
+// TextBuf in batches: the lines are buffered, and appended to the TextBuf
+// every FlushMSec, or as soon as OutBufFlushLines are buffered, with a
+// single update of its views per batch, so that chatty commands do not slow
+// down the views with an update per line.  Lines of any length are read,
+// and markup is applied to each by MarkupFun.
+type OutBuf struct {
+	Out       io.Reader            `desc:"the output that we are reading from"`
+	Buf       *giv.TextBuf         `desc:"the TextBuf that we output to"`
+	FlushMSec int                  `desc:"milliseconds between the appends of the buffered output"`
+	MarkupFun giv.OutBufMarkupFunc `desc:"optional markup function that adds html tags to given line of output -- it must ONLY add tags, keeping the visible bytes of the input"`
+	Lns       [][]byte             `desc:"buffered output raw lines -- not yet sent to Buf"`
+	Mus       [][]byte             `desc:"buffered output markup lines -- not yet sent to Buf"`
+	Mu        sync.Mutex           `desc:"mutex protecting the buffered lines, and the appends to Buf"`
+}
+
+// Init sets the reader, buffer, milliseconds between appends (0 for
+// OutBufFlushMSec), and markup function
+func (ob *OutBuf) Init(out io.Reader, buf *giv.TextBuf, flushMSec int, markup giv.OutBufMarkupFunc) {
+	ob.Out = out
+	ob.Buf = buf
+	ob.MarkupFun = markup
+	ob.FlushMSec = flushMSec
+	if ob.FlushMSec <= 0 {
+		ob.FlushMSec = OutBufFlushMSec
+	}
+}
+
+// MonOut reads the output until it ends, buffering its lines, which are
+// appended to the TextBuf in batches
+func (ob *OutBuf) MonOut() {
+	done := make(chan struct{})
+	go func() {
+		tick := time.NewTicker(time.Duration(ob.FlushMSec) * time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+				ob.Flush()
+			}
+		}
+	}()
+	rd := bufio.NewReaderSize(ob.Out, 64*1024)
+	for {
+		ln, err := rd.ReadBytes('\n')
+		if len(ln) > 0 {
+			ob.AddLine(ln)
+		}
+		if err != nil {
+			break
+		}
+	}
+	close(done)
+	ob.Flush()
+}
+
+// AddLine buffers given line of output (its line end is removed),
+// appending the buffered lines if there are OutBufFlushLines of them
+func (ob *OutBuf) AddLine(ln []byte) {
+	ln = bytes.TrimSuffix(ln, []byte("\n"))
+	ln = bytes.TrimSuffix(ln, []byte("\r"))
+	lc := make([]byte, len(ln)) // ReadBytes reuses its buffer
+	copy(lc, ln)
+	mu := giv.HTMLEscapeBytes(lc)
+	if ob.MarkupFun != nil {
+		mu = ob.MarkupFun(mu)
+	}
+	ob.Mu.Lock()
+	ob.Lns = append(ob.Lns, lc)
+	ob.Mus = append(ob.Mus, mu)
+	full := len(ob.Lns) >= OutBufFlushLines
+	ob.Mu.Unlock()
+	if full {
+		ob.Flush()
+	}
+}
+
+// Flush appends the buffered lines to the TextBuf, in one edit
+func (ob *OutBuf) Flush() {
+	ob.Mu.Lock()
+	defer ob.Mu.Unlock()
+	if len(ob.Lns) == 0 {
+		return
+	}
+	lfb := []byte("\n")
+	tlns := append(bytes.Join(ob.Lns, lfb), lfb...)
+	mlns := append(bytes.Join(ob.Mus, lfb), lfb...)
+	ob.Lns = nil
+	ob.Mus = nil
+	ob.Buf.Undos.Off = true
+	ob.Buf.AppendTextMarkup(tlns, mlns, giv.EditSignal)
+	ob.Buf.AutoScrollViews()
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// PluginLuaExt is the extension of the plugins run by the Lua runtime
+// embedded in gide, in gide itself -- the other plugins are run as
+// processes (see Plugin)
+var PluginLuaExt = ".lua"
+
+// PluginMethods are the methods of the API of the plugins, handled by
+// Plugins.Call -- the functions of the gide module of the lua plugins
+var PluginMethods = []string{"project", "activeFile", "bufferText", "setBufferText", "insertText", "selection", "replaceSelection", "openFile", "runCommand", "addCommand", "showPanel", "status"}
+
+// StartLuaPlugin starts the lua plugin of given path, running it in a new
+// state of the embedded Lua runtime, with the gide module: a function per
+// method of PluginMethods, taking a table of the params and returning a
+// table of the result, and on(event, function) for handling the events,
+// called with a table of their params -- print shows its args in the tab
+// of the plugin
+func (ps *Plugins) StartLuaPlugin(path string) error {
+	pl := &Plugin{Name: PluginName(path), Path: path, Handlers: map[string]*lua.LFunction{}}
+	L := lua.NewState()
+	pl.L = L
+	gm := L.NewTable()
+	for _, meth := range PluginMethods {
+		meth := meth
+		L.SetField(gm, meth, L.NewFunction(func(L *lua.LState) int {
+			res, err := ps.Call(pl, meth, luaPluginParams(L.OptTable(1, L.NewTable())))
+			if err != nil {
+				L.RaiseError("%v: %v", meth, err)
+				return 0
+			}
+			if pars, ok := res.(*PluginParams); ok && pars != nil {
+				L.Push(luaPluginTable(L, pars))
+				return 1
+			}
+			return 0
+		}))
+	}
+	L.SetField(gm, "on", L.NewFunction(func(L *lua.LState) int {
+		pl.Handlers[L.CheckString(1)] = L.CheckFunction(2)
+		return 0
+	}))
+	L.SetGlobal("gide", gm)
+	L.SetGlobal("print", L.NewFunction(func(L *lua.LState) int {
+		args := make([]string, L.GetTop())
+		for i := range args {
+			args[i] = L.ToStringMeta(L.Get(i + 1)).String()
+		}
+		ps.Output(pl, strings.Join(args, " "))
+		return 0
+	}))
+	ps.Mu.Lock()
+	ps.Plugins = append(ps.Plugins, pl)
+	ps.Mu.Unlock()
+	err := pl.RunLua(func(L *lua.LState) error {
+		return L.DoFile(path)
+	})
+	if err != nil {
+		ps.StopPlugin(pl)
+		return err
+	}
+	root := string(ps.Gide.ProjPrefs().ProjRoot)
+	ps.SendEvent(pl, &PluginMsg{Event: "start", Params: pluginParams(&PluginParams{File: root})})
+	return nil
+}
+
+// RunLua runs given function on the Lua state of the lua plugin, returning
+// its error -- if the plugin is already running a function, e.g., one
+// opening a file, which sends the open event, it is run once that one
+// returns, and RunLua returns nil
+func (pl *Plugin) RunLua(fn func(L *lua.LState) error) error {
+	pl.Mu.Lock()
+	if pl.L == nil {
+		pl.Mu.Unlock()
+		return fmt.Errorf("plugin %v is not running", pl.Name)
+	}
+	pl.LuaQueue = append(pl.LuaQueue, fn)
+	if pl.LuaBusy {
+		pl.Mu.Unlock()
+		return nil
+	}
+	pl.LuaBusy = true
+	L := pl.L
+	var err error
+	for first := true; len(pl.LuaQueue) > 0 && pl.L != nil; first = false {
+		qfn := pl.LuaQueue[0]
+		pl.LuaQueue = pl.LuaQueue[1:]
+		pl.Mu.Unlock()
+		qerr := qfn(L)
+		if first {
+			err = qerr
+		}
+		pl.Mu.Lock()
+	}
+	pl.LuaBusy = false
+	pl.LuaQueue = nil
+	if pl.L == nil { // stopped while running
+		L.Close()
+	}
+	pl.Mu.Unlock()
+	return err
+}
+
+// LuaEvent calls the function of the lua plugin handling the event of
+// given message, if any, with a table of its params
+func (pl *Plugin) LuaEvent(msg *PluginMsg) error {
+	return pl.RunLua(func(L *lua.LState) error {
+		fn, has := pl.Handlers[msg.Event]
+		if !has {
+			return nil
+		}
+		pars := &PluginParams{}
+		if len(msg.Params) > 0 {
+			json.Unmarshal(msg.Params, pars)
+		}
+		return L.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}, luaPluginTable(L, pars))
+	})
+}
+
+// luaPluginParams returns the params of given lua table
+func luaPluginParams(tbl *lua.LTable) *PluginParams {
+	pars := &PluginParams{}
+	pars.File = lua.LVAsString(tbl.RawGetString("file"))
+	pars.Text = lua.LVAsString(tbl.RawGetString("text"))
+	pars.Name = lua.LVAsString(tbl.RawGetString("name"))
+	pars.Desc = lua.LVAsString(tbl.RawGetString("desc"))
+	pars.Start = luaPluginPos(tbl.RawGetString("start"))
+	pars.End = luaPluginPos(tbl.RawGetString("end"))
+	return pars
+}
+
+// luaPluginPos returns the position of given lua value, a table with line
+// and col fields -- lines and columns start at 0, as in the JSON API
+func luaPluginPos(lv lua.LValue) PluginPos {
+	tbl, ok := lv.(*lua.LTable)
+	if !ok {
+		return PluginPos{}
+	}
+	return PluginPos{Line: int(lua.LVAsNumber(tbl.RawGetString("line"))), Col: int(lua.LVAsNumber(tbl.RawGetString("col")))}
+}
+
+// luaPluginTable returns a lua table of given params, with the fields of
+// luaPluginParams
+func luaPluginTable(L *lua.LState, pars *PluginParams) *lua.LTable {
+	tbl := L.NewTable()
+	if pars.File != "" {
+		tbl.RawSetString("file", lua.LString(pars.File))
+	}
+	if pars.Text != "" {
+		tbl.RawSetString("text", lua.LString(pars.Text))
+	}
+	if pars.Name != "" {
+		tbl.RawSetString("name", lua.LString(pars.Name))
+	}
+	if pars.Desc != "" {
+		tbl.RawSetString("desc", lua.LString(pars.Desc))
+	}
+	for _, p := range []struct {
+		nm  string
+		pos PluginPos
+	}{{"start", pars.Start}, {"end", pars.End}} {
+		pt := L.NewTable()
+		pt.RawSetString("line", lua.LNumber(p.pos.Line))
+		pt.RawSetString("col", lua.LNumber(p.pos.Col))
+		tbl.RawSetString(p.nm, pt)
+	}
+	return tbl
+}
@@ -0,0 +1,615 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/pi/lex"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// PluginsDirName is the name of the directory of the plugins, in the
+// preferences directory
+var PluginsDirName = "plugins"
+
+// PluginInterps are the interpreters of the plugin scripts run as
+// processes, by extension -- other plugins must be executables, or lua
+// scripts, run by the embedded Lua runtime (see PluginLuaExt)
+var PluginInterps = map[string][]string{
+	".py": {"python3"},
+	".js": {"node"},
+	".go": {"yaegi", "run"},
+	".sh": {"sh"},
+}
+
+// PluginsDir returns the directory of the plugins
+func PluginsDir() string {
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), PluginsDirName)
+}
+
+// PluginMsg is a message between gide and a plugin, on a JSON line: a
+// request of the plugin (ID, Method, Params), the reply of gide to it (ID,
+// Result or Error), or an event sent to the plugin (Event, Params)
+type PluginMsg struct {
+	ID     int             `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Event  string          `json:"event,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// PluginPos is a position in a buffer, for plugins
+type PluginPos struct {
+	Line int `json:"line"`
+	Col  int `json:"col"`
+}
+
+// PluginParams are the params of the requests of the plugins, and of the
+// events sent to them, each using the relevant ones
+type PluginParams struct {
+	File  string    `json:"file,omitempty"`
+	Text  string    `json:"text,omitempty"`
+	Name  string    `json:"name,omitempty"`
+	Desc  string    `json:"desc,omitempty"`
+	Start PluginPos `json:"start"`
+	End   PluginPos `json:"end"`
+}
+
+// PluginCmd is a command added by a plugin: choosing it in Plugin Command
+// sends the command event, with its name, to the plugin
+type PluginCmd struct {
+	Plugin string `desc:"name of the plugin"`
+	Name   string `desc:"name of the command"`
+	Desc   string `desc:"description of the command"`
+}
+
+// Label satisfies the Labeler interface
+func (pc PluginCmd) Label() string {
+	return pc.Plugin + ": " + pc.Name
+}
+
+// Plugin is a running plugin of the plugins directory: a lua script, run
+// by the embedded Lua runtime, calling the API of gide directly (see
+// StartLuaPlugin), or an executable, or a script run by one of the
+// PluginInterps, reading the events and replies of gide as JSON lines (see
+// PluginMsg) from its standard input, and writing its requests to its
+// standard output -- its standard error is shown in its tab
+type Plugin struct {
+	Name     string                      `desc:"name of the plugin: its file name, without extension"`
+	Path     string                      `desc:"path of the plugin"`
+	Cmd      *exec.Cmd                   `json:"-" xml:"-" desc:"the running plugin, for a plugin run as a process"`
+	Stdin    io.WriteCloser              `json:"-" xml:"-" desc:"the input of the plugin, for a plugin run as a process"`
+	L        *lua.LState                 `json:"-" xml:"-" desc:"the state of the Lua runtime running the plugin, for a lua plugin"`
+	Handlers map[string]*lua.LFunction   `json:"-" xml:"-" desc:"the functions of a lua plugin handling the events, by event"`
+	LuaBusy  bool                        `json:"-" xml:"-" desc:"true while a function is run on the Lua state (see RunLua)"`
+	LuaQueue []func(L *lua.LState) error `json:"-" xml:"-" desc:"functions to run on the Lua state once the running one returns"`
+	Mu       sync.Mutex                  `json:"-" xml:"-" desc:"mutex protecting the writes to the input, or the Lua state"`
+}
+
+// Send sends given message to the plugin: calls its handler of the event,
+// for a lua plugin
+func (pl *Plugin) Send(msg *PluginMsg) error {
+	if pl.Cmd == nil {
+		return pl.LuaEvent(msg)
+	}
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	pl.Mu.Lock()
+	defer pl.Mu.Unlock()
+	if pl.Stdin == nil {
+		return fmt.Errorf("plugin %v is not running", pl.Name)
+	}
+	_, err = pl.Stdin.Write(append(b, '\n'))
+	return err
+}
+
+// Plugins are the plugins running for a project, started when it is
+// opened, with the API for them: the buffers, selection, commands and
+// panels of the project, and the open, save and command events
+type Plugins struct {
+	Gide    Gide        `json:"-" xml:"-" desc:"the project"`
+	Plugins []*Plugin   `desc:"the running plugins"`
+	Cmds    []PluginCmd `desc:"the commands added by the plugins"`
+	Mu      sync.Mutex  `json:"-" xml:"-" desc:"mutex protecting the plugins and commands"`
+}
+
+// PluginFiles returns the plugins in the plugins directory, sorted, other
+// than those of the PluginsOff preferences
+func PluginFiles() []string {
+	dir := PluginsDir()
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var pls []string
+	for _, fi := range fis {
+		nm := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(nm, ".") || PluginDisabled(PluginName(nm)) {
+			continue
+		}
+		ext := filepath.Ext(nm)
+		if _, has := PluginInterps[ext]; has || ext == PluginLuaExt || fi.Mode()&0111 != 0 {
+			pls = append(pls, filepath.Join(dir, nm))
+		}
+	}
+	sort.Strings(pls)
+	return pls
+}
+
+// PluginName returns the name of the plugin of given file
+func PluginName(fname string) string {
+	fname = filepath.Base(fname)
+	return strings.TrimSuffix(fname, filepath.Ext(fname))
+}
+
+// PluginDisabled returns true if the plugin of given name is in the
+// PluginsOff preferences
+func PluginDisabled(name string) bool {
+	for _, dp := range Prefs.PluginsOff {
+		if dp == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PluginTabName returns the name of the tab of plugin of given name
+func PluginTabName(name string) string {
+	return "Plugin " + name
+}
+
+// Start starts the plugins of the plugins directory for given project
+func (ps *Plugins) Start(ge Gide) {
+	ps.Stop()
+	ps.Mu.Lock()
+	ps.Gide = ge
+	ps.Mu.Unlock()
+	for _, pf := range PluginFiles() {
+		if err := ps.StartPlugin(pf); err != nil {
+			ge.SetStatus(fmt.Sprintf("could not start plugin %v: %v", PluginName(pf), err))
+		}
+	}
+}
+
+// StartPlugin starts the plugin of given path, in the project root, and
+// sends it the start event, with the root as the file
+func (ps *Plugins) StartPlugin(path string) error {
+	if filepath.Ext(path) == PluginLuaExt {
+		return ps.StartLuaPlugin(path)
+	}
+	ge := ps.Gide
+	args := []string{path}
+	if interp, has := PluginInterps[filepath.Ext(path)]; has {
+		args = append(append([]string{}, interp...), path)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = string(ge.ProjPrefs().ProjRoot)
+	cmd.Env = append(ge.ProjPrefs().CmdEnv(), "GIDE_PLUGIN=1")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	pl := &Plugin{Name: PluginName(path), Path: path, Cmd: cmd, Stdin: stdin}
+	ps.Mu.Lock()
+	ps.Plugins = append(ps.Plugins, pl)
+	ps.Mu.Unlock()
+	go ps.Serve(pl, stdout)
+	go ps.Log(pl, stderr)
+	pl.Send(&PluginMsg{Event: "start", Params: pluginParams(&PluginParams{File: cmd.Dir})})
+	return nil
+}
+
+// Stop stops the plugins
+func (ps *Plugins) Stop() {
+	ps.Mu.Lock()
+	pls := ps.Plugins
+	ps.Plugins = nil
+	ps.Cmds = nil
+	ps.Mu.Unlock()
+	for _, pl := range pls {
+		pl.Stop()
+	}
+}
+
+// StopPlugin stops given plugin, and removes it from the running plugins
+func (ps *Plugins) StopPlugin(pl *Plugin) {
+	ps.Mu.Lock()
+	for i, p := range ps.Plugins {
+		if p == pl {
+			ps.Plugins = append(ps.Plugins[:i], ps.Plugins[i+1:]...)
+			break
+		}
+	}
+	ps.Mu.Unlock()
+	pl.Stop()
+}
+
+// Stop stops the plugin: kills its process, or closes its Lua state
+func (pl *Plugin) Stop() {
+	pl.Mu.Lock()
+	defer pl.Mu.Unlock()
+	if pl.Stdin != nil {
+		pl.Stdin.Close()
+		pl.Stdin = nil
+	}
+	if pl.Cmd != nil && pl.Cmd.Process != nil {
+		pl.Cmd.Process.Kill()
+	}
+	if pl.L != nil {
+		if !pl.LuaBusy { // else closed by RunLua when done
+			pl.L.Close()
+		}
+		pl.L = nil
+	}
+}
+
+// SendEvent sends given event message to given plugin, showing the error
+// in its tab if it fails, e.g., an error of the handler of a lua plugin
+func (ps *Plugins) SendEvent(pl *Plugin, msg *PluginMsg) {
+	if err := pl.Send(msg); err != nil {
+		ps.Output(pl, fmt.Sprintf("%v event: %v", msg.Event, err))
+	}
+}
+
+// Event sends the event of given name to the plugins, with given file
+// and name params if not empty: open and save, with the file (the command
+// event is sent by RunCommand, to the plugin of the command)
+func (ps *Plugins) Event(event, file, name string) {
+	ps.Mu.Lock()
+	pls := append([]*Plugin{}, ps.Plugins...)
+	ps.Mu.Unlock()
+	msg := &PluginMsg{Event: event, Params: pluginParams(&PluginParams{File: file, Name: name})}
+	for _, pl := range pls {
+		ps.SendEvent(pl, msg)
+	}
+}
+
+// Commands returns the commands added by the plugins
+func (ps *Plugins) Commands() []PluginCmd {
+	ps.Mu.Lock()
+	defer ps.Mu.Unlock()
+	return append([]PluginCmd{}, ps.Cmds...)
+}
+
+// RunCommand sends the command event for given command to its plugin
+func (ps *Plugins) RunCommand(pc *PluginCmd) {
+	ps.Mu.Lock()
+	var plc *Plugin
+	for _, pl := range ps.Plugins {
+		if pl.Name == pc.Plugin {
+			plc = pl
+		}
+	}
+	ps.Mu.Unlock()
+	if plc == nil {
+		return
+	}
+	ps.SendEvent(plc, &PluginMsg{Event: "command", Params: pluginParams(&PluginParams{Name: pc.Name})})
+}
+
+// Serve serves the requests of given plugin, from its given output, until
+// it exits
+func (ps *Plugins) Serve(pl *Plugin, out io.Reader) {
+	sc := bufio.NewScanner(out)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for sc.Scan() {
+		msg := &PluginMsg{}
+		if err := json.Unmarshal(sc.Bytes(), msg); err != nil {
+			ps.Output(pl, fmt.Sprintf("invalid request: %v: %s", err, sc.Bytes()))
+			continue
+		}
+		if msg.Method == "" {
+			continue
+		}
+		pars := &PluginParams{}
+		if len(msg.Params) > 0 {
+			json.Unmarshal(msg.Params, pars)
+		}
+		res, err := ps.Call(pl, msg.Method, pars)
+		rep := &PluginMsg{ID: msg.ID, Result: res}
+		if err != nil {
+			rep.Error = err.Error()
+		}
+		pl.Send(rep)
+	}
+	err := pl.Cmd.Wait()
+	ps.Mu.Lock()
+	running := false // not stopped
+	for i, p := range ps.Plugins {
+		if p == pl {
+			ps.Plugins = append(ps.Plugins[:i], ps.Plugins[i+1:]...)
+			running = true
+			break
+		}
+	}
+	ps.Mu.Unlock()
+	if err != nil && running {
+		ps.Output(pl, fmt.Sprintf("exited: %v", err))
+	}
+}
+
+// Log shows the standard error of given plugin in its tab
+func (ps *Plugins) Log(pl *Plugin, stderr io.Reader) {
+	sc := bufio.NewScanner(stderr)
+	for sc.Scan() {
+		ps.Output(pl, sc.Text())
+	}
+}
+
+// Output appends given line to the tab of given plugin
+func (ps *Plugins) Output(pl *Plugin, ln string) {
+	ge := ps.Gide
+	wupdt := ge.VPort().TopUpdateStart()
+	defer ge.VPort().TopUpdateEnd(wupdt)
+	buf, _, _ := ge.RecycleCmdTab(PluginTabName(pl.Name), false, false)
+	if buf != nil {
+		buf.AppendTextLineMarkup([]byte(ln), MarkupCmdOutput([]byte(ln)), giv.EditSignal)
+	}
+}
+
+// pluginParams returns given params as JSON
+func pluginParams(pars *PluginParams) json.RawMessage {
+	b, _ := json.Marshal(pars)
+	return b
+}
+
+// textView returns the text view of given file for the plugins, the active
+// one if empty -- opening it if not open
+func (ps *Plugins) textView(file string) (*TextView, error) {
+	ge := ps.Gide
+	if file == "" {
+		tv := ge.ActiveTextView()
+		if tv == nil || tv.Buf == nil {
+			return nil, fmt.Errorf("no active file")
+		}
+		return tv, nil
+	}
+	tv, err := ge.ShowFile(file, 0)
+	if err != nil {
+		return nil, err
+	}
+	return tv, nil
+}
+
+// Call handles the request of given method, with given params, of given
+// plugin, returning its result:
+//   - project: the root of the project
+//   - activeFile: the file of the active view, and the position of its cursor
+//   - bufferText: the text of given file, the active one if empty
+//   - setBufferText: sets the text of given file
+//   - insertText: inserts given text in given file at given start
+//   - selection: the selection in the active view, with its file and region
+//   - replaceSelection: replaces the selection with given text
+//   - openFile: opens given file at given start line
+//   - runCommand: runs the gide command of given name on the active file
+//   - addCommand: adds a command of given name and desc, sent back as the
+//     command event when chosen in Plugin Command
+//   - showPanel: shows given text in the tab of the plugin, with links
+//   - status: shows given text in the status bar
+func (ps *Plugins) Call(pl *Plugin, method string, pars *PluginParams) (interface{}, error) {
+	ge := ps.Gide
+	switch method {
+	case "project":
+		root := string(ge.ProjPrefs().ProjRoot)
+		return &PluginParams{File: root, Name: filepath.Base(root)}, nil
+	case "activeFile":
+		tv := ge.ActiveTextView()
+		if tv == nil || tv.Buf == nil {
+			return nil, fmt.Errorf("no active file")
+		}
+		pos := PluginPos{Line: tv.CursorPos.Ln, Col: tv.CursorPos.Ch}
+		return &PluginParams{File: string(tv.Buf.Filename), Start: pos, End: pos}, nil
+	case "bufferText":
+		if pars.File != "" {
+			if tb := ge.TextBufForFile(pars.File, false); tb != nil {
+				return &PluginParams{File: pars.File, Text: string(tb.Text())}, nil
+			}
+		}
+		tv, err := ps.textView(pars.File)
+		if err != nil {
+			return nil, err
+		}
+		return &PluginParams{File: string(tv.Buf.Filename), Text: string(tv.Buf.Text())}, nil
+	case "setBufferText", "insertText":
+		tv, err := ps.textView(pars.File)
+		if err != nil {
+			return nil, err
+		}
+		wupdt := ge.VPort().TopUpdateStart()
+		defer ge.VPort().TopUpdateEnd(wupdt)
+		if method == "setBufferText" {
+			tb := tv.Buf
+			ed := tb.EndPos()
+			tb.ReplaceText(lex.PosZero, ed, lex.PosZero, pars.Text, giv.EditSignal, false)
+		} else {
+			st := lex.Pos{Ln: pars.Start.Line, Ch: pars.Start.Col}
+			tv.Buf.InsertText(st, []byte(pars.Text), giv.EditSignal)
+		}
+		return nil, nil
+	case "selection", "replaceSelection":
+		tv := ge.ActiveTextView()
+		if tv == nil || tv.Buf == nil {
+			return nil, fmt.Errorf("no active file")
+		}
+		reg := tv.SelectReg
+		res := &PluginParams{File: string(tv.Buf.Filename), Start: PluginPos{reg.Start.Ln, reg.Start.Ch}, End: PluginPos{reg.End.Ln, reg.End.Ch}}
+		if sel := tv.Selection(); sel != nil {
+			res.Text = string(sel.ToBytes())
+		}
+		if method == "replaceSelection" {
+			if !tv.HasSelection() {
+				reg.Start, reg.End = tv.CursorPos, tv.CursorPos
+			}
+			wupdt := ge.VPort().TopUpdateStart()
+			defer ge.VPort().TopUpdateEnd(wupdt)
+			tv.Buf.ReplaceText(reg.Start, reg.End, reg.Start, pars.Text, giv.EditSignal, false)
+			tv.SelectReset()
+		}
+		return res, nil
+	case "openFile":
+		wupdt := ge.VPort().TopUpdateStart()
+		defer ge.VPort().TopUpdateEnd(wupdt)
+		_, err := ge.ShowFile(pars.File, pars.Start.Line)
+		return nil, err
+	case "runCommand":
+		if _, _, ok := AvailCmds.CmdByName(CmdName(pars.Name), false); !ok {
+			return nil, fmt.Errorf("no command named: %v", pars.Name)
+		}
+		fn := ""
+		if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+			fn = string(tv.Buf.Filename)
+		}
+		ge.ExecCmdNameFileName(fn, CmdName(pars.Name), true, true)
+		return nil, nil
+	case "addCommand":
+		if pars.Name == "" {
+			return nil, fmt.Errorf("no command name")
+		}
+		ps.Mu.Lock()
+		defer ps.Mu.Unlock()
+		for i := range ps.Cmds {
+			if ps.Cmds[i].Plugin == pl.Name && ps.Cmds[i].Name == pars.Name {
+				ps.Cmds[i].Desc = pars.Desc
+				return nil, nil
+			}
+		}
+		ps.Cmds = append(ps.Cmds, PluginCmd{Plugin: pl.Name, Name: pars.Name, Desc: pars.Desc})
+		return nil, nil
+	case "showPanel":
+		wupdt := ge.VPort().TopUpdateStart()
+		defer ge.VPort().TopUpdateEnd(wupdt)
+		buf, _, _ := ge.RecycleCmdTab(PluginTabName(pl.Name), true, true)
+		if buf == nil {
+			return nil, fmt.Errorf("no tabs")
+		}
+		for _, ln := range strings.Split(pars.Text, "\n") {
+			buf.AppendTextLineMarkup([]byte(ln), MarkupCmdOutput([]byte(ln)), giv.EditSignal)
+		}
+		return nil, nil
+	case "status":
+		ge.SetStatus(pars.Text)
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown method: %v", method)
+}
+
+// NewPlugin creates a new plugin of given name and extension in the
+// plugins directory, from a template of the protocol, returning its path
+func NewPlugin(name, ext string) (string, error) {
+	dir := PluginsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	fn := filepath.Join(dir, name+ext)
+	if _, err := os.Stat(fn); err == nil {
+		return fn, nil
+	}
+	tmpl, has := PluginTemplates[ext]
+	if !has {
+		return "", fmt.Errorf("no plugin template for: %v", ext)
+	}
+	return fn, ioutil.WriteFile(fn, []byte(tmpl), 0755)
+}
+
+// PluginTemplates are the templates of new plugins, by extension
+var PluginTemplates = map[string]string{
+	".lua": `-- gide plugin: run by the Lua runtime of gide, with the gide module,
+-- whose functions take and return tables of params (file, text, name,
+-- desc, start and end, with line and col) -- see gide.Plugins.Call
+gide.on("start", function(ev)
+  gide.addCommand{name = "Upper Case", desc = "upper-cases the selection"}
+end)
+
+gide.on("command", function(ev)
+  if ev.name == "Upper Case" then
+    local sel = gide.selection()
+    gide.replaceSelection{text = string.upper(sel.text or "")}
+  end
+end)
+
+gide.on("save", function(ev)
+  print("saved: " .. ev.file)
+end)
+`,
+	".py": `# gide plugin: reads events and replies as JSON lines from stdin, and
+# writes requests to stdout -- see gide.Plugins.Call for the methods
+import json, sys
+
+next_id = 0
+pending = {}  # functions called with the results of the requests, by id
+
+def request(method, then=None, **params):
+    global next_id
+    next_id += 1
+    pending[next_id] = then
+    print(json.dumps({"id": next_id, "method": method, "params": params}), flush=True)
+
+def upper(sel):
+    request("replaceSelection", text=sel.get("text", "").upper())
+
+for line in sys.stdin:
+    msg = json.loads(line)
+    ev = msg.get("event")
+    pars = msg.get("params", {})
+    if ev == "start":
+        request("addCommand", name="Upper Case", desc="upper-cases the selection")
+    elif ev == "command" and pars.get("name") == "Upper Case":
+        request("selection", then=upper)
+    elif ev == "save":
+        print("saved: " + pars.get("file", ""), file=sys.stderr, flush=True)
+    elif "id" in msg:
+        then = pending.pop(msg["id"], None)
+        if msg.get("error"):
+            print(msg["error"], file=sys.stderr, flush=True)
+        elif then:
+            then(msg.get("result") or {})
+`,
+	".js": `// gide plugin: reads events and replies as JSON lines from stdin, and
+// writes requests to stdout -- see gide.Plugins.Call for the methods
+const readline = require('readline');
+let nextId = 0;
+function request(method, params) {
+  console.log(JSON.stringify({id: ++nextId, method: method, params: params || {}}));
+}
+readline.createInterface({input: process.stdin}).on('line', (line) => {
+  const msg = JSON.parse(line);
+  const pars = msg.params || {};
+  if (msg.event === 'start') {
+    request('addCommand', {name: 'Hello', desc: 'shows hello in the status bar'});
+  } else if (msg.event === 'command' && pars.name === 'Hello') {
+    request('status', {text: 'hello from a plugin'});
+  } else if (msg.event === 'save') {
+    console.error('saved: ' + pars.file);
+  }
+});
+`,
+}
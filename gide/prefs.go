@@ -10,6 +10,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/goki/gi/gi"
@@ -24,19 +25,35 @@ import (
 
 // FilePrefs contains file view preferences
 type FilePrefs struct {
-	DirsOnTop bool `desc:"if true, then all directories are placed at the top of the tree view -- otherwise everything is alpha sorted"`
+	DirsOnTop    bool     `desc:"if true, then all directories are placed at the top of the tree view -- otherwise everything is alpha sorted"`
+	ShowIgnored  bool     `desc:"if true, files and directories ignored by .gitignore or .gideignore files are shown grayed out in the tree view -- otherwise they are hidden (they are always excluded from find, quick open and the symbol index)"`
+	VcsMarkers   bool     `desc:"if true, the version control status of files is marked after their names in the tree view (M = modified, A = added, D = deleted, ? = untracked, ! = conflicted), and directories containing changed files are marked with a bullet, in addition to the status colors"`
+	ShowHidden   bool     `desc:"if true, hidden files and directories (with names starting with .) are shown in the tree view, and searched by find -- otherwise they are hidden, as for HidePatterns"`
+	FollowLinks  bool     `desc:"if true, symbolic links to directories are followed, both in the tree view and in scanning the project for quick open, the symbol index and TODOs -- links that lead back to a directory already being shown or scanned (cycles) are never followed"`
+	HidePatterns []string `desc:"patterns for files and directories that are always hidden in the tree view, and excluded from find, quick open and the symbol index, using the same syntax as .gitignore files (e.g., *.pb.go, /data/, node_modules/) -- unlike ignored files, they are never shown"`
 }
 
 // Preferences are the overall user preferences for Gide.
 type Preferences struct {
-	Files        FilePrefs         `desc:"file view preferences"`
-	EnvVars      map[string]string `desc:"environment variables to set for this app -- if run from the command line, standard shell environment variables are inherited, but on some OS's (Mac), they are not set when run as a gui app"`
-	KeyMap       KeyMapName        `desc:"key map for gide-specific keyboard sequences"`
-	SaveKeyMaps  bool              `desc:"if set, the current available set of key maps is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom key maps, but it may be safer to keep it <i>OFF</i> if you are <i>not</i> using custom key maps, so that you'll always have the latest compiled-in standard key maps with all the current key functions bound to standard key chords"`
-	SaveLangOpts bool              `desc:"if set, the current customized set of language options (see Edit Lang Opts) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
-	SaveCmds     bool              `desc:"if set, the current customized set of command parameters (see Edit Cmds) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
-	GoMod        bool              `desc:"if true, use Go modules, otherwise use GOPATH -- this sets your effective GO111MODULE environment variable accordingly, dynamically -- this cannot be set on a per-project basis as it affects overall environment state (must do Apply to change)"`
-	Changed      bool              `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
+	Files          FilePrefs         `desc:"file view preferences"`
+	Forge          ForgePrefs        `desc:"GitHub / GitLab preferences, for the Pull Requests panel"`
+	SSHHosts       SSHHosts          `desc:"hosts for the SSH terminals, in addition to the Hosts of ~/.ssh/config -- authentication is that of ssh: keys and the ssh agent"`
+	Repls          Repls             `desc:"interpreters of the REPL consoles, in addition to the standard ones (python, node, and yaegi for Go), which are overridden by those of the same name"`
+	PluginsOff     []string          `desc:"names of the plugins of the plugins directory (in the preferences directory) not started for the projects"`
+	Shell          ShellPrefs        `desc:"shell run by the terminals, and by the commands with Shell set, for each OS: which shell, login or not, and an rc file and environment variables for it"`
+	EnvVars        map[string]string `desc:"environment variables to set for this app -- if run from the command line, standard shell environment variables are inherited, but on some OS's (Mac), they are not set when run as a gui app"`
+	KeyMap         KeyMapName        `desc:"key map for gide-specific keyboard sequences"`
+	SaveKeyMaps    bool              `desc:"if set, the current available set of key maps is saved to your preferences directory, and automatically loaded at startup -- this should be set if you are using custom key maps, but it may be safer to keep it <i>OFF</i> if you are <i>not</i> using custom key maps, so that you'll always have the latest compiled-in standard key maps with all the current key functions bound to standard key chords"`
+	SaveLangOpts   bool              `desc:"if set, the current customized set of language options (see Edit Lang Opts) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
+	SaveCmds       bool              `desc:"if set, the current customized set of command parameters (see Edit Cmds) is saved / loaded along with other preferences -- if not set, then you always are using the default compiled-in standard set (which will be updated)"`
+	StartupRecents bool              `desc:"if set, the recent projects are shown at startup when no project is given, to select one to open"`
+	UIScale        float32           `min:"0.25" max:"4" step:"0.05" desc:"scale of the whole user interface of gide (fonts, icons and spacing of all its windows), multiplying the Logical DPI scale of the GoGi preferences -- e.g., larger for presentations, or for a high-DPI screen -- 0 or 1 for the normal size (the font of each editor pane can also be zoomed with Ctrl+= and Ctrl+-)"`
+	ClipHist       ClipHistPrefs     `desc:"clipboard history of the copies and cuts, pasted with Paste History: its size, and whether the secrets are kept"`
+	Access         AccessPrefs       `desc:"accessibility: high-contrast colors, a visible focus ring, and announcing the focus in the statusbar and with a speech command"`
+	SingleInstance bool              `desc:"if set, running gide again (or gide-open) with files or a project to open opens them in the gide that is already running, in the window of the project they are in, instead of starting another gide"`
+	Sync           SyncPrefs         `json:"-" desc:"syncing of the settings (preferences, custom commands, keymaps, language options, color themes, splits and macros) with a directory or git repository shared by several machines -- saved separately, in sync_prefs.json, as it is specific to each machine"`
+	GoMod          bool              `desc:"if true, use Go modules, otherwise use GOPATH -- this sets your effective GO111MODULE environment variable accordingly, dynamically -- this cannot be set on a per-project basis as it affects overall environment state (must do Apply to change)"`
+	Changed        bool              `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
 }
 
 var KiT_Preferences = kit.Types.AddType(&Preferences{}, PreferencesProps)
@@ -67,9 +84,12 @@ func InitPrefs() {
 	SetActiveKeyMapName(DefaultKeyMap)
 	Prefs.Defaults()
 	Prefs.Open()
+	SyncPrefsAtStart()
 	OpenPaths()
+	OpenRecentProjs()
 	OpenIcons()
 	TheConsole.Init()
+	StartupMark("prefs")
 	gi.CustomAppMenuFunc = func(m *gi.Menu, win *gi.Window) {
 		m.InsertActionAfter("GoGi Preferences...", gi.ActOpts{Label: "Gide Preferences..."},
 			win, func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -81,12 +101,19 @@ func InitPrefs() {
 // Defaults are the defaults for FilePrefs
 func (pf *FilePrefs) Defaults() {
 	pf.DirsOnTop = true
+	pf.VcsMarkers = true
+	pf.ShowHidden = true
+	pf.FollowLinks = true
 }
 
 // Defaults are the defaults for Preferences
 func (pf *Preferences) Defaults() {
 	pf.Files.Defaults()
+	pf.ClipHist.Defaults()
+	pf.Access.Defaults()
 	pf.KeyMap = DefaultKeyMap
+	pf.StartupRecents = true
+	pf.SingleInstance = true
 	pf.EnvVars = make(map[string]string)
 }
 
@@ -101,6 +128,11 @@ func (pf *Preferences) Apply() {
 	MergeAvailCmds()
 	AvailLangs.Validate()
 	pf.ApplyEnvVars()
+	if oswin.TheApp != nil {
+		pf.Access.Apply() // before the scale: resets the GoGi zoom
+	}
+	pf.ApplyUIScale()
+	pf.ClipHist.Apply()
 	if pf.GoMod {
 		os.Setenv("GO111MODULE", "on")
 	} else {
@@ -108,6 +140,23 @@ func (pf *Preferences) Apply() {
 	}
 }
 
+// ApplyUIScale applies the UIScale to all the windows, as the GoGi zoom
+// factor, if it was changed
+func (pf *Preferences) ApplyUIScale() {
+	sc := pf.UIScale
+	if sc <= 0 {
+		sc = 1
+	}
+	if sc == gi.ZoomFactor || oswin.TheApp == nil {
+		return
+	}
+	gi.ZoomFactor = sc
+	gi.Prefs.ApplyDPI()
+	for _, w := range gi.AllWindows {
+		w.FullReRender()
+	}
+}
+
 // ApplyEnvVars applies environment variables set in EnvVars
 func (pf *Preferences) ApplyEnvVars() {
 	for k, v := range pf.EnvVars {
@@ -135,6 +184,8 @@ func (pf *Preferences) Open() error {
 	}
 	AvailSplits.OpenPrefs()
 	AvailRegisters.OpenPrefs()
+	AvailMacros.OpenPrefs()
+	pf.Sync.OpenPrefs()
 	pf.Apply()
 	pf.Changed = false
 	return err
@@ -164,7 +215,10 @@ func (pf *Preferences) Save() error {
 	}
 	AvailSplits.SavePrefs()
 	AvailRegisters.SavePrefs()
+	AvailMacros.SavePrefs()
+	pf.Sync.SavePrefs()
 	pf.Changed = false
+	SyncPrefsSaved()
 	return err
 }
 
@@ -197,7 +251,7 @@ func (pf *Preferences) EditCmds() {
 	pf.Changed = true
 	if len(CustomCmds) == 0 {
 		CustomCmds = append(CustomCmds, &Command{"Example Cmd", "list current dir", filecat.Any,
-			[]CmdAndArgs{{"ls", []string{"-la"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm})
+			[]CmdAndArgs{{"ls", []string{"-la"}}}, "{FileDirPath}", CmdNoWait, CmdNoFocus, CmdNoConfirm, nil, "", "", CmdNoShell})
 
 	}
 	CmdsView(&CustomCmds)
@@ -213,6 +267,11 @@ func (pf *Preferences) EditRegisters() {
 	RegistersView(&AvailRegisters)
 }
 
+// EditMacros opens the MacrosView editor to customize saved keyboard macros
+func (pf *Preferences) EditMacros() {
+	MacrosView(&AvailMacros)
+}
+
 // PreferencesProps define the ToolBar and MenuBar for StructView, e.g., giv.PrefsView
 var PreferencesProps = ki.Props{
 	"MainMenu": ki.PropSlice{
@@ -273,6 +332,29 @@ var PreferencesProps = ki.Props{
 			"icon": "file-binary",
 			"desc": "opens the RegistersView editor of saved named text registers.  Current values are saved and loaded with preferences automatically.",
 		}},
+		{"EditMacros", ki.Props{
+			"icon": "keyboard",
+			"desc": "opens the MacrosView editor of saved named keyboard macros.  Current values are saved and loaded with preferences automatically.",
+		}},
+		{"RegisterURLScheme", ki.Props{
+			"label":       "Register URL Scheme",
+			"icon":        "file-open",
+			"desc":        "registers gide as the handler of the gide:// URLs for your OS, e.g., gide://open?file=/path/to/file.go&line=12, so links in browsers, chat, and coverage or CI reports open the files in gide, at their lines",
+			"show-return": true,
+		}},
+		{"sep-sync", ki.BlankProp{}},
+		{"SyncNow", ki.Props{
+			"icon":        "update",
+			"desc":        "syncs the settings with the Sync directory: pulls the ones changed there, and pushes the ones changed here -- those changed in both since the last sync are conflicts, not synced until resolved with Sync Resolve",
+			"show-return": true,
+		}},
+		{"SyncResolve", ki.Props{
+			"icon": "update",
+			"desc": "resolves the conflicts of the settings sync (changed both here and in the Sync directory): takes the settings of the Sync directory if Use Remote, and otherwise pushes the ones here",
+			"Args": ki.PropSlice{
+				{"Use Remote", ki.Props{}},
+			},
+		}},
 	},
 }
 
@@ -281,25 +363,47 @@ var PreferencesProps = ki.Props{
 
 // ProjPrefs are the preferences for saving for a project -- this IS the project file
 type ProjPrefs struct {
-	Files        FilePrefs         `desc:"file view preferences"`
-	Editor       gi.EditorPrefs    `view:"inline" desc:"editor preferences"`
-	SplitName    SplitName         `desc:"current named-split config in use for configuring the splitters"`
-	MainLang     filecat.Supported `desc:"the language associated with the most frequently-encountered file extension in the file tree -- can be manually set here as well"`
-	VersCtrl     giv.VersCtrlName  `desc:"the type of version control system used in this project (git, svn, etc) -- filters commands available"`
-	ProjFilename gi.FileName       `ext:".gide" desc:"current project filename for saving / loading specific Gide configuration information in a .gide file (optional)"`
-	ProjRoot     gi.FileName       `desc:"root directory for the project -- all projects must be organized within a top-level root directory, with all the files therein constituting the scope of the project -- by default it is the path for ProjFilename"`
-	BuildCmds    CmdNames          `desc:"command(s) to run for main Build button"`
-	BuildDir     gi.FileName       `desc:"build directory for main Build button -- set this to the directory where you want to build the main target for this project -- avail as {BuildDir} in commands"`
-	BuildTarg    gi.FileName       `desc:"build target for main Build button, if relevant for your  BuildCmds"`
-	RunExec      gi.FileName       `desc:"executable to run for this project via main Run button -- called by standard Run Proj command"`
-	RunCmds      CmdNames          `desc:"command(s) to run for main Run button (typically Run Proj)"`
-	Debug        gidebug.Params    `desc:"custom debugger parameters for this project"`
-	Find         FindParams        `view:"-" desc:"saved find params"`
-	Symbols      SymbolsParams     `view:"-" desc:"saved structure params"`
-	Dirs         giv.DirFlagMap    `view:"-" desc:"directory properties"`
-	Register     RegisterName      `view:"-" desc:"last register used"`
-	Splits       []float32         `view:"-" desc:"current splitter splits"`
-	Changed      bool              `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
+	Files        FilePrefs                      `desc:"file view preferences"`
+	Editor       gi.EditorPrefs                 `view:"inline" desc:"editor preferences"`
+	SplitName    SplitName                      `desc:"current named-split config in use for configuring the splitters"`
+	MainLang     filecat.Supported              `desc:"the language associated with the most frequently-encountered file extension in the file tree -- can be manually set here as well"`
+	VersCtrl     giv.VersCtrlName               `desc:"the type of version control system used in this project (git, svn, etc) -- filters commands available for files that are not in a repository detected by walking up from them (nested repositories use their own)"`
+	ProjFilename gi.FileName                    `ext:".gide" desc:"current project filename for saving / loading specific Gide configuration information in a .gide file (optional)"`
+	ProjRoot     gi.FileName                    `desc:"root directory for the project -- all projects must be organized within a top-level root directory, with all the files therein constituting the scope of the project -- by default it is the path for ProjFilename"`
+	BuildCmds    CmdNames                       `desc:"command(s) to run for main Build button"`
+	BuildDir     gi.FileName                    `desc:"build directory for main Build button -- set this to the directory where you want to build the main target for this project -- avail as {BuildDir} in commands"`
+	BuildTarg    gi.FileName                    `desc:"build target for main Build button, if relevant for your  BuildCmds"`
+	RunExec      gi.FileName                    `desc:"executable to run for this project via main Run button -- called by standard Run Proj command"`
+	RunCmds      CmdNames                       `desc:"command(s) to run for main Run button (typically Run Proj)"`
+	BuildConfigs BuildConfigs                   `desc:"build configurations (main package, GOOS / GOARCH, tags, output, ldflags) that can be selected with the Build Config toolbar chooser"`
+	BuildConfig  string                         `desc:"name of the active build configuration, which sets the BuildDir, BuildTarg and RunExec, and the flags and environment of the Go build commands -- empty for none"`
+	Matrix       BuildMatrix                    `desc:"targets (GOOS / GOARCH / tags) for which the main package is built in parallel by the Build Matrix, collecting the executables in its OutDir"`
+	RunMode      RunMode                        `desc:"mode in which the project is built by the Go build commands, and then run: normally, or with the race detector or the memory or address sanitizer -- ignored for platforms that do not support it"`
+	HiStyle      gi.HiStyleName                 `desc:"highlighting style (color theme) of the editors in this project, overriding the one in the GoGi preferences -- empty to use that"`
+	FontSize     float32                        `desc:"font size (in points) of the editors in this project -- 0 to use the default size"`
+	LineEnds     LineEnds                       `desc:"line endings (LF or CRLF) of the new files of this project -- the other files keep their own, shown in the statusbar, unless converted"`
+	Templates    gi.FileName                    `desc:"directory of the templates of the new files of this project, relative to its root -- .gide-templates if empty -- the templates of the user (in the preferences directory) are used for the files it has none for"`
+	SpellDict    gi.FileName                    `desc:"dictionary file of the words accepted by the spell checking in this project (product names, identifiers), one per line, relative to the project root -- .gide-words.txt if empty"`
+	PostSaveCmds map[filecat.Supported]CmdNames `desc:"command(s) to run after saving files of a given language in this project (e.g., a different formatter), overriding the PostSaveCmds of Edit Lang Opts for that language"`
+	EnvVars      map[string]string              `desc:"environment variables set for the commands run in this project, in addition to (or overriding) those of the Gide preferences"`
+	WebPort      int                            `desc:"port on the local machine for the web preview server, which serves the project files for viewing html pages in a browser -- 0 = choose a free port automatically"`
+	Debug        gidebug.Params                 `desc:"custom debugger parameters for this project"`
+	BuildOnSave  bool                           `desc:"if set, the BuildCmds (or go build ./... if there are none, for Go projects) are run in the background after files are saved, showing pass / fail in the statusbar, with the errors in the Problems panel"`
+	Lint         LintParams                     `desc:"golangci-lint parameters for this project: its path and config, and whether to run it on save"`
+	Serial       SerialParams                   `desc:"serial port of the Serial Console of this project: device, baud rate and framing, and a file to log its output to"`
+	Container    ContainerParams                `desc:"dev-container of this project, in which its commands (build, test, run, and debugging) are run, with the project root mounted: a docker image, or a running container"`
+	WSL          WSLParams                      `desc:"on Windows, whether to run the commands of this project (build, test, run, and debugging) in the Windows Subsystem for Linux, and in which distro"`
+	Find         FindParams                     `view:"-" desc:"saved find params"`
+	Symbols      SymbolsParams                  `view:"-" desc:"saved structure params"`
+	Dirs         giv.DirFlagMap                 `view:"-" desc:"directory properties"`
+	Register     RegisterName                   `view:"-" desc:"last register used"`
+	Splits       []float32                      `view:"-" desc:"current splitter splits"`
+	Panes        []*PaneLayout                  `view:"-" desc:"current layout of editor panes within each of the text view panels"`
+	Session      Session                        `view:"-" desc:"open files, cursor and scroll positions, tabs and terminals, restored when the project is opened"`
+	ReplHist     map[string][]string            `view:"-" desc:"history of the inputs of the REPL consoles, by REPL name, the oldest first"`
+	Scratch      string                         `view:"-" desc:"text of the Go scratchpad"`
+	CommitMsgs   []string                       `view:"-" desc:"recent commit messages, most recent first, for the message history of the commit panel"`
+	Changed      bool                           `view:"-" changeflag:"+" json:"-" xml:"-" desc:"flag that is set by StructView by virtue of changeflag tag, whenever an edit is made.  Used to drive save menus etc."`
 }
 
 var KiT_ProjPrefs = kit.Types.AddType(&ProjPrefs{}, ProjPrefsProps)
@@ -342,6 +446,54 @@ func (pf *ProjPrefs) SaveJSON(filename gi.FileName) error {
 	return err
 }
 
+// ProjHiStyle returns the highlighting style for the project: HiStyle if
+// set, otherwise the global one
+func (pf *ProjPrefs) ProjHiStyle() gi.HiStyleName {
+	if pf.HiStyle != "" {
+		return pf.HiStyle
+	}
+	return gi.Prefs.Colors.HiStyle
+}
+
+// LangPostSaveCmds returns the commands to run after saving files of given
+// language in the project: from PostSaveCmds if listed there, otherwise
+// from AvailLangs
+func (pf *ProjPrefs) LangPostSaveCmds(sup filecat.Supported) CmdNames {
+	if cmds, has := pf.PostSaveCmds[sup]; has {
+		return cmds
+	}
+	if lopt, has := AvailLangs[sup]; has {
+		return lopt.PostSaveCmds
+	}
+	return nil
+}
+
+// CmdEnv returns the environment for commands run in the project, with
+// the ProjEnv, and the AskPassEnv for prompting for credentials added to
+// that of the process
+func (pf *ProjPrefs) CmdEnv() []string {
+	return append(append(os.Environ(), AskPassEnv()...), pf.ProjEnv()...)
+}
+
+// ProjEnv returns the environment variables of the project: EnvVars, the
+// GOOS / GOARCH of the active build configuration, and those of the active
+// run mode -- those also set in its Container
+func (pf *ProjPrefs) ProjEnv() []string {
+	keys := make([]string, 0, len(pf.EnvVars))
+	for k := range pf.EnvVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var env []string
+	for _, k := range keys {
+		env = append(env, k+"="+pf.EnvVars[k])
+	}
+	if bc := pf.ActiveBuildConfig(); bc != nil {
+		env = append(env, bc.Env()...)
+	}
+	return append(env, pf.ActiveRunMode().Env()...)
+}
+
 // RunExecIsExec returns true if the RunExec is actually executable
 func (pf *ProjPrefs) RunExecIsExec() bool {
 	fi, err := giv.NewFileInfo(string(pf.RunExec))
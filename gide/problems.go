@@ -0,0 +1,568 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/token"
+)
+
+// ProblemSeverity is the severity of a Problem
+type ProblemSeverity int32
+
+const (
+	// ProblemError is an error, e.g., from the compiler or a failed test
+	ProblemError ProblemSeverity = iota
+
+	// ProblemWarning is a warning, e.g., a vet or lint finding
+	ProblemWarning
+
+	// ProblemInfo is an informational note or hint
+	ProblemInfo
+
+	// ProblemSeverityN is the number of severities
+	ProblemSeverityN
+)
+
+//go:generate stringer -type=ProblemSeverity
+
+var KiT_ProblemSeverity = kit.Enums.AddEnumAltLower(ProblemSeverityN, kit.NotBitFlag, nil, "Problem")
+
+func (ev ProblemSeverity) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *ProblemSeverity) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// ProblemIcons are the symbols shown for each severity in the Problems panel
+var ProblemIcons = [ProblemSeverityN]string{"✖", "⚠", "ℹ"}
+
+// ProblemColors are the colors of the ProblemIcons
+var ProblemColors = [ProblemSeverityN]string{"#e53935", "#fb8c00", "#1e88e5"}
+
+// ProblemTags are the custom tags marking the text of problems in the
+// editor, for each severity -- they are styled by the highlighting style
+// (errors are typically red, warnings underlined), with a dotted underline
+// for styles that do not define them -- 0 for no marking
+var ProblemTags = [ProblemSeverityN]token.Tokens{token.TextStyleError, token.TextStyleUnderline, 0}
+
+func init() {
+	for _, tag := range ProblemTags {
+		if tag != 0 {
+			histyle.Props[tag] = ki.Props{"text-decoration": 1 << uint32(gist.DecoDottedUnderline)}
+		}
+	}
+}
+
+// Problem is one diagnostic at a position in a file: a compiler error, a
+// vet or lint finding, or one from a language server
+type Problem struct {
+	Path     string          `desc:"full path of the file"`
+	Line     int             `desc:"line number, starting at 1"`
+	Col      int             `desc:"column number (in bytes), starting at 1 -- 0 if none"`
+	Severity ProblemSeverity `desc:"severity of the problem"`
+	Source   string          `desc:"what reported the problem: the name of the command, or linter or language server"`
+	Msg      string          `desc:"the message"`
+	URL      string          `desc:"link to the docs of the rule or linter that reported the problem, if any"`
+}
+
+// Pos returns the position of the problem
+func (pb *Problem) Pos() FilePos {
+	return FilePos{Path: pb.Path, Line: pb.Line, Col: pb.Col}
+}
+
+// Loc returns the line:col of the problem, or just the line if no column
+func (pb *Problem) Loc() string {
+	if pb.Col > 0 {
+		return fmt.Sprintf("%d:%d", pb.Line, pb.Col)
+	}
+	return strconv.Itoa(pb.Line)
+}
+
+// String returns the problem as in compiler messages: path:line:col: msg
+func (pb *Problem) String() string {
+	return fmt.Sprintf("%v:%v: %v", pb.Path, pb.Loc(), pb.Msg)
+}
+
+// Less returns true if the problem comes before the other one: by file,
+// position and severity
+func (pb *Problem) Less(o *Problem) bool {
+	switch {
+	case pb.Path != o.Path:
+		return pb.Path < o.Path
+	case pb.Line != o.Line:
+		return pb.Line < o.Line
+	case pb.Col != o.Col:
+		return pb.Col < o.Col
+	}
+	return pb.Severity < o.Severity
+}
+
+// ProblemRe matches a problem in command output: path:line:col: msg, with
+// an optional column, as output by the Go tools, gcc, clang and most
+// linters
+var ProblemRe = regexp.MustCompile(`^\s*((?:[A-Za-z]:)?[^\s:]+):(\d+)(?::(\d+))?:\s*(.+)$`)
+
+// ProblemCmds are the words in the names of the commands whose output is
+// parsed for problems -- the previous problems of a command are replaced
+// each time it is run
+var ProblemCmds = []string{"Build", "Install", "Test", "Vet", "Lint", "Make", "Generate"}
+
+// ProblemWarnCmds are the words in the names of the commands in
+// ProblemCmds whose problems are warnings unless they say otherwise
+var ProblemWarnCmds = []string{"Vet", "Lint"}
+
+// cmdHasWord returns true if command name has one of given words
+func cmdHasWord(cmdNm string, wds []string) bool {
+	for _, fw := range strings.Fields(cmdNm) {
+		for _, wd := range wds {
+			if fw == wd {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsProblemCmd returns true if the output of given command is parsed for
+// problems (see ProblemCmds)
+func IsProblemCmd(cmdNm string) bool {
+	return cmdHasWord(cmdNm, ProblemCmds)
+}
+
+// ProblemCmdSeverity returns the default severity of the problems of given
+// command (see ProblemWarnCmds)
+func ProblemCmdSeverity(cmdNm string) ProblemSeverity {
+	if cmdHasWord(cmdNm, ProblemWarnCmds) {
+		return ProblemWarning
+	}
+	return ProblemError
+}
+
+// ProblemMsgSeverity returns the severity given at the start of a problem
+// message (as in error: or warning:), or given default
+func ProblemMsgSeverity(msg string, sev ProblemSeverity) ProblemSeverity {
+	lm := strings.ToLower(msg)
+	switch {
+	case strings.HasPrefix(lm, "error:") || strings.HasPrefix(lm, "fatal error:"):
+		return ProblemError
+	case strings.HasPrefix(lm, "warning:"):
+		return ProblemWarning
+	case strings.HasPrefix(lm, "note:") || strings.HasPrefix(lm, "info:") || strings.HasPrefix(lm, "hint:"):
+		return ProblemInfo
+	}
+	return sev
+}
+
+// ParseProblems returns the problems in given command output, with file
+// paths relative to given directory, reported by given source with given
+// default severity -- only those in existing files are returned, without
+// duplicates
+func ParseProblems(out []byte, dir, source string, sev ProblemSeverity) []Problem {
+	var probs []Problem
+	have := map[Problem]bool{}
+	for _, ln := range bytes.Split(out, []byte("\n")) {
+		m := ProblemRe.FindSubmatch(bytes.TrimRight(ln, "\r"))
+		if m == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(string(m[2]))
+		col := 0
+		if len(m[3]) > 0 {
+			col, _ = strconv.Atoi(string(m[3]))
+		}
+		pb, ok := NewProblem(string(m[1]), line, col, dir, source, string(m[4]), sev)
+		if !ok {
+			continue
+		}
+		pb.Severity = ProblemMsgSeverity(pb.Msg, sev)
+		if have[pb] {
+			continue
+		}
+		have[pb] = true
+		probs = append(probs, pb)
+	}
+	return probs
+}
+
+// ProblemList is the list of problems in a project, by source: each set
+// of problems of a source (e.g., a command) replaces its previous ones
+type ProblemList struct {
+	Sources map[string][]Problem `desc:"problems by source"`
+	Mu      sync.Mutex           `desc:"protects the list, which is updated as commands finish"`
+}
+
+// Set sets the problems of given source, replacing its previous ones --
+// returns true if there were or are any
+func (pl *ProblemList) Set(source string, probs []Problem) bool {
+	pl.Mu.Lock()
+	defer pl.Mu.Unlock()
+	had := len(pl.Sources[source]) > 0
+	if len(probs) == 0 {
+		delete(pl.Sources, source)
+		return had
+	}
+	if pl.Sources == nil {
+		pl.Sources = map[string][]Problem{}
+	}
+	pl.Sources[source] = probs
+	return true
+}
+
+// SetPrefix sets the problems of given sources, replacing those of all the
+// sources starting with given prefix, e.g., of a linter run on all the
+// packages -- returns true if there were or are any
+func (pl *ProblemList) SetPrefix(prefix string, bysrc map[string][]Problem) bool {
+	pl.Mu.Lock()
+	defer pl.Mu.Unlock()
+	had := false
+	for src := range pl.Sources {
+		if strings.HasPrefix(src, prefix) {
+			delete(pl.Sources, src)
+			had = true
+		}
+	}
+	for src, probs := range bysrc {
+		if len(probs) == 0 {
+			continue
+		}
+		if pl.Sources == nil {
+			pl.Sources = map[string][]Problem{}
+		}
+		pl.Sources[src] = probs
+		had = true
+	}
+	return had
+}
+
+// Clear removes all the problems
+func (pl *ProblemList) Clear() {
+	pl.Mu.Lock()
+	pl.Sources = nil
+	pl.Mu.Unlock()
+}
+
+// All returns all the problems, sorted by file and position
+func (pl *ProblemList) All() []Problem {
+	pl.Mu.Lock()
+	var probs []Problem
+	for _, sp := range pl.Sources {
+		probs = append(probs, sp...)
+	}
+	pl.Mu.Unlock()
+	sort.Slice(probs, func(i, j int) bool {
+		return probs[i].Less(&probs[j])
+	})
+	return probs
+}
+
+// File returns the problems in given file
+func (pl *ProblemList) File(fpath string) []Problem {
+	pl.Mu.Lock()
+	defer pl.Mu.Unlock()
+	var probs []Problem
+	for _, sp := range pl.Sources {
+		for _, pb := range sp {
+			if pb.Path == fpath {
+				probs = append(probs, pb)
+			}
+		}
+	}
+	return probs
+}
+
+// Counts returns the number of problems of each severity
+func (pl *ProblemList) Counts() [ProblemSeverityN]int {
+	pl.Mu.Lock()
+	defer pl.Mu.Unlock()
+	var n [ProblemSeverityN]int
+	for _, sp := range pl.Sources {
+		for _, pb := range sp {
+			n[pb.Severity]++
+		}
+	}
+	return n
+}
+
+// SourceCounts returns the number of problems of each severity found by
+// given sources
+func (pl *ProblemList) SourceCounts(sources ...string) [ProblemSeverityN]int {
+	pl.Mu.Lock()
+	defer pl.Mu.Unlock()
+	var n [ProblemSeverityN]int
+	for _, src := range sources {
+		for _, pb := range pl.Sources[src] {
+			n[pb.Severity]++
+		}
+	}
+	return n
+}
+
+// Summary returns the number of errors and warnings as a string
+func (pl *ProblemList) Summary() string {
+	n := pl.Counts()
+	return fmt.Sprintf("%d errors, %d warnings", n[ProblemError], n[ProblemWarning])
+}
+
+// Next returns the problem after given position, or before it if prev,
+// wrapping around at the end -- false if there are no problems
+func (pl *ProblemList) Next(fp FilePos, prev bool) (Problem, bool) {
+	probs := pl.All()
+	if len(probs) == 0 {
+		return Problem{}, false
+	}
+	cur := &Problem{Path: fp.Path, Line: fp.Line, Col: fp.Col, Severity: ProblemSeverityN}
+	if prev {
+		cur.Severity = -1
+		for i := len(probs) - 1; i >= 0; i-- {
+			if probs[i].Less(cur) {
+				return probs[i], true
+			}
+		}
+		return probs[len(probs)-1], true
+	}
+	for _, pb := range probs {
+		if cur.Less(&pb) {
+			return pb, true
+		}
+	}
+	return probs[0], true
+}
+
+// MarkProblems marks the text of given problems in given buffer with the
+// ProblemTags, replacing any previous marks -- the marks move with edits
+func MarkProblems(tb *giv.TextBuf, probs []Problem) {
+	if tb == nil {
+		return
+	}
+	tb.MarkupMu.Lock()
+	chg := false
+	for ln := range tb.Tags {
+		if len(tb.Tags[ln]) == 0 {
+			continue
+		}
+		tags := tb.AdjustedTags(ln)
+		nt := len(tags)
+		for _, tag := range ProblemTags {
+			if tag != 0 {
+				tags.DeleteToken(tag)
+			}
+		}
+		if len(tags) != nt {
+			tb.Tags[ln] = tags
+			chg = true
+		}
+	}
+	tb.MarkupMu.Unlock()
+	for _, pb := range probs {
+		tag := ProblemTags[pb.Severity]
+		ln := pb.Line - 1
+		if tag == 0 || !tb.IsValidLine(ln) {
+			continue
+		}
+		st, ed := ProblemSpan(tb.Line(ln), pb.Col)
+		if st >= ed {
+			continue
+		}
+		tb.AddTag(ln, st, ed, tag)
+		chg = true
+	}
+	if chg {
+		tb.TextBufSig.Emit(tb.This(), int64(giv.TextBufMarkUpdt), nil)
+	}
+}
+
+// ProblemSpan returns the span of runes of given line to mark for a
+// problem at given column (in bytes, starting at 1): the word at the
+// column, or the rest of the line if not at a word -- the whole line,
+// without leading space, if no column
+func ProblemSpan(txt []rune, col int) (st, ed int) {
+	if col <= 0 {
+		for st < len(txt) && unicode.IsSpace(txt[st]) {
+			st++
+		}
+		return st, len(txt)
+	}
+	nb := 0
+	for st < len(txt) && nb < col-1 {
+		nb += utf8.RuneLen(txt[st])
+		st++
+	}
+	isWord := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	ed = st
+	for ed < len(txt) && isWord(txt[ed]) {
+		ed++
+	}
+	if ed == st {
+		ed = len(txt)
+	}
+	return st, ed
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    ProblemsView
+
+// ProblemsView is a widget that displays the problems found by commands,
+// linters and language servers, grouped by file, with links to them
+type ProblemsView struct {
+	gi.Layout
+	Gide       Gide         `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	List       *ProblemList `json:"-" xml:"-" copy:"-" desc:"the list of problems shown"`
+	ErrorsOnly bool         `desc:"only show the errors, not the warnings and notes"`
+}
+
+var KiT_ProblemsView = kit.Types.AddType(&ProblemsView{}, ProblemsViewProps)
+
+// Config configures the view to show given list
+func (pv *ProblemsView) Config(ge Gide, pl *ProblemList) {
+	pv.Gide = ge
+	pv.List = pl
+	pv.Lay = gi.LayoutVert
+	pv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "probbar")
+	config.Add(gi.KiT_Layout, "probtext")
+	mods, updt := pv.ConfigChildren(config)
+	if !mods {
+		updt = pv.UpdateStart()
+	}
+	pv.ConfigToolbar()
+	ConfigOutputTextView(pv.TextViewLay())
+	pv.UpdateEnd(updt)
+}
+
+// ToolBar returns the problems toolbar
+func (pv *ProblemsView) ToolBar() *gi.ToolBar {
+	return pv.ChildByName("probbar", 0).(*gi.ToolBar)
+}
+
+// TextViewLay returns the problems TextView layout
+func (pv *ProblemsView) TextViewLay() *gi.Layout {
+	return pv.ChildByName("probtext", 1).(*gi.Layout)
+}
+
+// TextView returns the problems TextView
+func (pv *ProblemsView) TextView() *giv.TextView {
+	return pv.TextViewLay().ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (pv *ProblemsView) ConfigToolbar() {
+	tb := pv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Next", Icon: "wedge-down", Tooltip: "go to the next problem after the cursor", Shortcut: key.Chord(ChordForFun(KeyFunNextProblem).String())},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+			pvv.Gide.NextProblem()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Prev", Icon: "wedge-up", Tooltip: "go to the previous problem before the cursor", Shortcut: key.Chord(ChordForFun(KeyFunPrevProblem).String())},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+			pvv.Gide.PrevProblem()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear", Icon: "close", Tooltip: "remove all the problems, until the commands are run again"},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+			pvv.Gide.ClearProblems()
+		})
+	cb := gi.AddNewCheckBox(tb, "errors-only")
+	cb.SetText("Errors Only")
+	cb.Tooltip = "only show the errors, not the warnings and notes"
+	cb.SetChecked(pv.ErrorsOnly)
+	cb.ButtonSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			pvv, _ := recv.Embed(KiT_ProblemsView).(*ProblemsView)
+			pvv.ErrorsOnly = send.(*gi.CheckBox).IsChecked()
+			pvv.ShowProblems()
+		}
+	})
+}
+
+// ShowProblems shows the problems of the list in the text view, grouped by
+// file, with their severity and file:/// links to them
+func (pv *ProblemsView) ShowProblems() {
+	if pv.List == nil {
+		return
+	}
+	var probs []Problem
+	for _, pb := range pv.List.All() {
+		if !pv.ErrorsOnly || pb.Severity == ProblemError {
+			probs = append(probs, pb)
+		}
+	}
+	root := string(pv.Gide.ProjPrefs().ProjRoot)
+	outlns := make([][]byte, 0, len(probs)+20)
+	outmus := make([][]byte, 0, len(probs)+20)
+	for i := 0; i < len(probs); {
+		fpath := probs[i].Path
+		n := 1
+		for i+n < len(probs) && probs[i+n].Path == fpath {
+			n++
+		}
+		fnm := fpath
+		if rel, err := filepath.Rel(root, fpath); err == nil && !RelOutside(rel) {
+			fnm = filepath.ToSlash(rel)
+		}
+		lstr := fmt.Sprintf("%v: %d", fnm, n)
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte("<b>"+html.EscapeString(lstr)+"</b>"))
+		for _, pb := range probs[i : i+n] {
+			icon := ProblemIcons[pb.Severity]
+			desc := fmt.Sprintf("%v [%v]", pb.Msg, pb.Source)
+			outlns = append(outlns, []byte(fmt.Sprintf("\t%v %v: %v", icon, pb.Loc(), desc)))
+			mu := fmt.Sprintf(`	<span style="color: %v">%v</span> <a href="file:///%v#L%dC%d">%v</a>: %v`, ProblemColors[pb.Severity], icon, fpath, pb.Line, pb.Col, pb.Loc(), html.EscapeString(desc))
+			if pb.URL != "" {
+				mu += fmt.Sprintf(` <a href="%v">docs</a>`, pb.URL)
+			}
+			outmus = append(outmus, []byte(mu))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+		i += n
+	}
+	if len(probs) == 0 {
+		outlns = append(outlns, []byte("no problems"))
+		outmus = append(outmus, []byte("no problems"))
+	}
+	ptv := pv.TextView()
+	pbuf := ptv.Buf
+	if pbuf == nil {
+		return
+	}
+	cpos := ptv.CursorPos
+	pbuf.New(0)
+	pbuf.SetInactive(true)
+	pbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), giv.EditSignal)
+	ptv.SetCursorShow(cpos)
+}
+
+// ProblemsViewProps are style properties for ProblemsView
+var ProblemsViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
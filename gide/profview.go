@@ -0,0 +1,725 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// ProfFrame is a frame of a stack trace of a profile
+type ProfFrame struct {
+	Func string  `desc:"function of the frame"`
+	Pos  FilePos `desc:"position in the source, if known"`
+}
+
+// ProfTrace is a stack trace of a profile, with the value of its samples
+type ProfTrace struct {
+	Value  float64     `desc:"value of the samples: nanoseconds, bytes or a count"`
+	Frames []ProfFrame `desc:"the frames of the stack, innermost first"`
+}
+
+// ProfFunc is the total of a function over the traces of a profile
+type ProfFunc struct {
+	Func string  `desc:"the function"`
+	Pos  FilePos `desc:"position of its first sampled line"`
+	Flat float64 `desc:"value of the samples in the function itself"`
+	Cum  float64 `desc:"value of the samples in the function and those it calls"`
+}
+
+// Profile is a cpu or memory profile, as stack traces, parsed from the
+// output of go tool pprof -traces -lines
+type Profile struct {
+	File   string      `desc:"path of the profile file"`
+	Type   string      `desc:"type of the samples, e.g., cpu or alloc_space"`
+	Unit   string      `desc:"unit of the values: ns for durations, B for sizes, empty for counts"`
+	Total  float64     `desc:"total value of all the traces"`
+	Header []string    `desc:"header lines of the pprof output: file, type, time, duration"`
+	Traces []ProfTrace `desc:"the stack traces"`
+}
+
+// profUnits are the units of the values in the pprof output, in the base
+// unit
+var profUnits = map[string]struct {
+	Base  string
+	Scale float64
+}{
+	"ns": {"ns", 1}, "us": {"ns", 1e3}, "µs": {"ns", 1e3}, "ms": {"ns", 1e6}, "s": {"ns", 1e9}, "mins": {"ns", 60e9}, "hrs": {"ns", 3600e9},
+	"B": {"B", 1}, "kB": {"B", 1024}, "MB": {"B", 1024 * 1024}, "GB": {"B", 1024 * 1024 * 1024}, "TB": {"B", 1024 * 1024 * 1024 * 1024},
+	"k": {"", 1e3}, "M": {"", 1e6}, "G": {"", 1e9},
+}
+
+// profValueRe matches a value in the pprof output: a number and a unit
+var profValueRe = regexp.MustCompile(`^([0-9.]+(?:e[+-]?\d+)?)([a-zA-Zµ]*)$`)
+
+// ParseProfValue parses a value of the pprof output, e.g., 10ms or 1.16MB,
+// returning it in the base unit: ns, B or a count -- false if not a value
+func ParseProfValue(s string) (float64, string, bool) {
+	m := profValueRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, "", false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, "", false
+	}
+	if m[2] == "" {
+		return v, "", true
+	}
+	u, ok := profUnits[m[2]]
+	if !ok {
+		return 0, "", false
+	}
+	return v * u.Scale, u.Base, true
+}
+
+// FormatProfValue formats given value in given base unit
+func FormatProfValue(v float64, unit string) string {
+	switch unit {
+	case "ns":
+		switch {
+		case v >= 1e9:
+			return fmt.Sprintf("%.2fs", v/1e9)
+		case v >= 1e6:
+			return fmt.Sprintf("%.0fms", v/1e6)
+		case v >= 1e3:
+			return fmt.Sprintf("%.0fµs", v/1e3)
+		}
+		return fmt.Sprintf("%.0fns", v)
+	case "B":
+		switch {
+		case v >= 1024*1024*1024:
+			return fmt.Sprintf("%.2fGB", v/(1024*1024*1024))
+		case v >= 1024*1024:
+			return fmt.Sprintf("%.2fMB", v/(1024*1024))
+		case v >= 1024:
+			return fmt.Sprintf("%.1fkB", v/1024)
+		}
+		return fmt.Sprintf("%.0fB", v)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// profFrameRe matches a frame in the pprof traces: func path:line
+var profFrameRe = regexp.MustCompile(`^(\S+)(?:\s+((?:[A-Za-z]:)?[^\s:]+):(\d+))?`)
+
+// ParsePprofTraces parses the output of go tool pprof -traces -lines
+func ParsePprofTraces(out []byte) (*Profile, error) {
+	pr := &Profile{}
+	var tr *ProfTrace
+	sawSep := false
+	for _, lb := range bytes.Split(out, []byte("\n")) {
+		ln := strings.TrimRight(string(lb), "\r")
+		if strings.HasPrefix(ln, "-----------+") {
+			if tr != nil && len(tr.Frames) > 0 {
+				pr.Traces = append(pr.Traces, *tr)
+				pr.Total += tr.Value
+			}
+			tr = nil
+			sawSep = true
+			continue
+		}
+		if !sawSep {
+			if ln != "" {
+				pr.Header = append(pr.Header, ln)
+			}
+			if strings.HasPrefix(ln, "Type: ") {
+				pr.Type = strings.TrimSpace(strings.TrimPrefix(ln, "Type: "))
+			}
+			continue
+		}
+		trim := strings.TrimSpace(ln)
+		if trim == "" {
+			continue
+		}
+		val := ""
+		if len(ln) > 11 && ln[10] == ' ' && strings.TrimSpace(ln[:10]) != "" {
+			val = strings.TrimSpace(ln[:10])
+			trim = strings.TrimSpace(ln[10:])
+		}
+		if val != "" {
+			if strings.HasSuffix(val, ":") { // label line, e.g., bytes:  1MB
+				continue
+			}
+			v, unit, ok := ParseProfValue(val)
+			if !ok {
+				continue
+			}
+			if unit != "" {
+				pr.Unit = unit
+			}
+			tr = &ProfTrace{Value: v}
+		}
+		if tr == nil {
+			continue
+		}
+		fm := profFrameRe.FindStringSubmatch(trim)
+		if fm == nil {
+			continue
+		}
+		fr := ProfFrame{Func: fm[1]}
+		if fm[2] != "" && filepath.IsAbs(fm[2]) {
+			fr.Pos.Path = fm[2]
+			fr.Pos.Line, _ = strconv.Atoi(fm[3])
+		}
+		tr.Frames = append(tr.Frames, fr)
+	}
+	if tr != nil && len(tr.Frames) > 0 {
+		pr.Traces = append(pr.Traces, *tr)
+		pr.Total += tr.Value
+	}
+	if !sawSep {
+		return nil, fmt.Errorf("not pprof -traces output")
+	}
+	return pr, nil
+}
+
+// Top returns the functions of the profile, by decreasing flat value, at
+// most n (0 for all)
+func (pr *Profile) Top(n int) []ProfFunc {
+	fns := map[string]*ProfFunc{}
+	for _, tr := range pr.Traces {
+		seen := map[string]bool{}
+		for fi, fr := range tr.Frames {
+			pf, has := fns[fr.Func]
+			if !has {
+				pf = &ProfFunc{Func: fr.Func, Pos: fr.Pos}
+				fns[fr.Func] = pf
+			}
+			if fi == 0 {
+				pf.Flat += tr.Value
+			}
+			if !seen[fr.Func] { // recursion is counted once
+				pf.Cum += tr.Value
+				seen[fr.Func] = true
+			}
+		}
+	}
+	top := make([]ProfFunc, 0, len(fns))
+	for _, pf := range fns {
+		top = append(top, *pf)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Flat != top[j].Flat {
+			return top[i].Flat > top[j].Flat
+		}
+		if top[i].Cum != top[j].Cum {
+			return top[i].Cum > top[j].Cum
+		}
+		return top[i].Func < top[j].Func
+	})
+	if n > 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Pct returns given value as a percent of the total
+func (pr *Profile) Pct(v float64) float64 {
+	if pr.Total == 0 {
+		return 0
+	}
+	return 100 * v / pr.Total
+}
+
+// ProfMinPct is the minimum percent of the total of the nodes of the call
+// tree -- smaller ones are left out
+var ProfMinPct = 0.5
+
+// ProfTopN is the number of functions shown in the top table
+var ProfTopN = 100
+
+// ProfNode is a node in the call tree of a profile, as in a flame graph: a
+// function called from the path of functions from the root, with the
+// functions it calls as its children
+type ProfNode struct {
+	ki.Node
+	Func  string  `desc:"the function"`
+	Pos   FilePos `desc:"position of the first sampled line of the function in this path"`
+	Value float64 `desc:"value of the samples of the function in this path (and those it calls)"`
+	Self  float64 `desc:"value of the samples in the function itself in this path"`
+	Pct   float64 `desc:"value as a percent of the total of the profile"`
+	Unit  string  `desc:"unit of the values"`
+}
+
+var KiT_ProfNode = kit.Types.AddType(&ProfNode{}, ki.Props{"EnumType:Flag": ki.KiT_Flags})
+
+// Label returns the function with its percent of the total and value
+func (pn *ProfNode) Label() string {
+	if pn.Func == "" {
+		return fmt.Sprintf("total %v", FormatProfValue(pn.Value, pn.Unit))
+	}
+	return fmt.Sprintf("%5.1f%% %v %v", pn.Pct, FormatProfValue(pn.Value, pn.Unit), pn.Func)
+}
+
+// BuildTree makes the call tree of the profile under given root node, from
+// the outermost frames, merging the paths by function -- nodes under
+// ProfMinPct are left out
+func (pr *Profile) BuildTree(root *ProfNode) {
+	type tnode struct {
+		pn   *ProfNode
+		kids map[string]*tnode
+	}
+	top := &tnode{pn: &ProfNode{}, kids: map[string]*tnode{}}
+	for _, tr := range pr.Traces {
+		cur := top
+		cur.pn.Value += tr.Value
+		for fi := len(tr.Frames) - 1; fi >= 0; fi-- {
+			fr := tr.Frames[fi]
+			kn, has := cur.kids[fr.Func]
+			if !has {
+				kn = &tnode{pn: &ProfNode{Func: fr.Func, Pos: fr.Pos}, kids: map[string]*tnode{}}
+				cur.kids[fr.Func] = kn
+			}
+			kn.pn.Value += tr.Value
+			if fi == 0 {
+				kn.pn.Self += tr.Value
+			}
+			cur = kn
+		}
+	}
+	updt := root.UpdateStart()
+	root.DeleteChildren(ki.DestroyKids)
+	root.Func = ""
+	root.Value, root.Unit, root.Pct = top.pn.Value, pr.Unit, 100
+	var add func(par *ProfNode, tn *tnode)
+	add = func(par *ProfNode, tn *tnode) {
+		kids := make([]*tnode, 0, len(tn.kids))
+		for _, kn := range tn.kids {
+			if pr.Pct(kn.pn.Value) >= ProfMinPct {
+				kids = append(kids, kn)
+			}
+		}
+		sort.Slice(kids, func(i, j int) bool { return kids[i].pn.Value > kids[j].pn.Value })
+		for i, kn := range kids {
+			pn := par.AddNewChild(KiT_ProfNode, fmt.Sprintf("n%d", i)).(*ProfNode)
+			pn.Func, pn.Pos, pn.Value, pn.Self = kn.pn.Func, kn.pn.Pos, kn.pn.Value, kn.pn.Self
+			pn.Pct, pn.Unit = pr.Pct(pn.Value), pr.Unit
+			add(pn, kn)
+		}
+	}
+	add(root, top)
+	root.UpdateEnd(updt)
+}
+
+// ReadPprof reads the profile in given file with go tool pprof, with given
+// sample index (e.g., alloc_space) if not empty
+func ReadPprof(fname, sampleIdx string, env []string) (*Profile, error) {
+	args := []string{"tool", "pprof", "-traces", "-lines"}
+	if sampleIdx != "" {
+		args = append(args, "-sample_index="+sampleIdx)
+	}
+	cmd := exec.Command("go", append(args, fname)...)
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("go tool pprof: %v: %v", err, strings.TrimSpace(string(out)))
+	}
+	pr, err := ParsePprofTraces(out)
+	if err != nil {
+		return nil, err
+	}
+	pr.File = fname
+	return pr, nil
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    ProfView
+
+// ProfView is the profiler panel: it runs the tests with cpu or memory
+// profiling, or gets the profile of a running program, and shows the top
+// functions and the call tree of the profile, with links to the source
+type ProfView struct {
+	gi.Layout
+	Gide    Gide      `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Prof    *Profile  `json:"-" xml:"-" copy:"-" desc:"the profile shown"`
+	Tree    *ProfNode `json:"-" xml:"-" copy:"-" desc:"root of the call tree of the profile"`
+	Bench   string    `desc:"regexp of the benchmarks to run, with -bench, instead of the tests -- empty to run the tests"`
+	Running bool      `json:"-" xml:"-" desc:"true while profiling"`
+}
+
+var KiT_ProfView = kit.Types.AddType(&ProfView{}, ProfViewProps)
+
+// ProfCmdName is the name of the profiling runs in the CmdRuns of the
+// project, for stopping them
+var ProfCmdName = "Profile"
+
+// ProfWebCmdName is the name of the go tool pprof -http runs in the
+// CmdRuns of the project
+var ProfWebCmdName = "Profile Web"
+
+// Config configures the view
+func (pv *ProfView) Config(ge Gide) {
+	pv.Gide = ge
+	pv.Lay = gi.LayoutVert
+	pv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "profbar")
+	config.Add(gi.KiT_SplitView, "profsplit")
+	mods, updt := pv.ConfigChildren(config)
+	if !mods {
+		updt = pv.UpdateStart()
+	}
+	pv.ConfigToolbar()
+	pv.ConfigSplitView()
+	pv.UpdateEnd(updt)
+}
+
+// ToolBar returns the profile toolbar
+func (pv *ProfView) ToolBar() *gi.ToolBar {
+	return pv.ChildByName("profbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the top table and the call tree
+func (pv *ProfView) SplitView() *gi.SplitView {
+	return pv.ChildByName("profsplit", 1).(*gi.SplitView)
+}
+
+// TextView returns the TextView showing the top functions
+func (pv *ProfView) TextView() *giv.TextView {
+	ly := pv.SplitView().ChildByName("top", 0).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// TreeView returns the tree view of the call tree
+func (pv *ProfView) TreeView() *ProfTreeView {
+	return pv.SplitView().ChildByName("tree", 1).Child(0).(*ProfTreeView)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (pv *ProfView) ConfigToolbar() {
+	tb := pv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "CPU", Icon: "play", Tooltip: "run the tests (or benchmarks) of the package of the active file with cpu profiling"},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+			pvv.ProfileTests(false)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Mem", Icon: "play", Tooltip: "run the tests (or benchmarks) of the package of the active file with memory profiling, showing the bytes allocated"},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+			pvv.ProfileTests(true)
+		})
+	gi.AddNewLabel(tb, "bench-lbl", "Bench:")
+	bf := gi.AddNewTextField(tb, "bench")
+	bf.Tooltip = "regexp of the benchmarks to run, e.g., . for all -- empty to run the tests"
+	bf.SetMinPrefWidth(units.NewCh(12))
+	bf.TextFieldSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.TextFieldDone) || sig == int64(gi.TextFieldDeFocused) {
+			pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+			pvv.Bench = send.(*gi.TextField).Text()
+		}
+	})
+	tb.AddAction(gi.ActOpts{Label: "URL...", Icon: "file-open", Tooltip: "get the profile of a running program serving net/http/pprof, e.g., http://localhost:6060/debug/pprof/profile?seconds=10 for cpu, or .../debug/pprof/heap"},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+			gi.StringPromptDialog(pvv.Viewport, "http://localhost:6060/debug/pprof/profile?seconds=10", "", gi.DlgOpts{Title: "Profile Running Program", Prompt: "URL of the profile of a program that imports net/http/pprof:"},
+				pvv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					if sig == int64(gi.DialogAccepted) {
+						pvvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+						pvvv.ProfileURL(gi.StringPromptDialogValue(send.(*gi.Dialog)))
+					}
+				})
+		})
+	tb.AddAction(gi.ActOpts{Label: "Open...", Icon: "file-open", Tooltip: "open a saved profile file"},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+			giv.FileViewDialog(pvv.Viewport, string(pvv.Gide.ProjPrefs().ProjRoot), ".prof,.pprof,.out,.pb.gz", giv.DlgOpts{Title: "Open Profile"}, nil,
+				pvv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					if sig == int64(gi.DialogAccepted) {
+						pvvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+						dlg, _ := send.(*gi.Dialog)
+						pvvv.OpenProfile(gi.FileName(giv.FileViewDialogValue(dlg)), "")
+					}
+				})
+		})
+	tb.AddAction(gi.ActOpts{Label: "Web", Icon: "file-code", Tooltip: "open the profile in the pprof web UI (go tool pprof -http), with its graph, flame graph and source views"},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+			pvv.OpenWeb()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop profiling"},
+		pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+			pvv.Gide.CmdRuns().KillByName(ProfCmdName)
+		})
+}
+
+// ConfigSplitView configures the split view of the top table and the
+// call tree
+func (pv *ProfView) ConfigSplitView() {
+	split := pv.SplitView()
+	split.Dim = mat32.X
+	if len(split.Kids) > 0 {
+		return
+	}
+	ly := gi.AddNewLayout(split, "top", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	fr := gi.AddNewFrame(split, "tree", gi.LayoutVert)
+	fr.SetProp("height", units.NewEm(5)) // enables scrolling
+	fr.SetStretchMaxWidth()
+	fr.SetStretchMaxHeight()
+	pv.Tree = &ProfNode{}
+	pv.Tree.InitName(pv.Tree, "profile")
+	ptv := fr.AddNewChild(KiT_ProfTreeView, "treeview").(*ProfTreeView)
+	ptv.SetRootNode(pv.Tree)
+	ptv.TreeViewSig.Connect(pv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if data == nil || sig != int64(giv.TreeViewSelected) {
+			return
+		}
+		pvv, _ := recv.Embed(KiT_ProfView).(*ProfView)
+		ptn, _ := data.(ki.Ki).Embed(KiT_ProfTreeView).(*ProfTreeView)
+		if pn := ptn.ProfNode(); pn != nil && pn.Pos.Path != "" {
+			pvv.Gide.ShowFile(pn.Pos.Path, pn.Pos.Line)
+		}
+	})
+	split.SetSplits(.5, .5)
+}
+
+// ProfFilePath returns the path of the profile file of given kind written
+// by this process
+func ProfFilePath(kind string) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gide-%v-%d.prof", kind, os.Getpid()))
+}
+
+// ProfileTests runs the tests, or the benchmarks if Bench is set, of the
+// package of the active file in the background with cpu (or memory, if
+// mem) profiling, and then shows the profile
+func (pv *ProfView) ProfileTests(mem bool) {
+	if pv.Running {
+		pv.Gide.SetStatus("already profiling")
+		return
+	}
+	ge := pv.Gide
+	dir := string(ge.ProjPrefs().ProjRoot)
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil && tv.Buf.Filename != "" {
+		dir = filepath.Dir(string(tv.Buf.Filename))
+	}
+	kind, flag, sidx := "cpu", "-cpuprofile", ""
+	if mem {
+		kind, flag, sidx = "mem", "-memprofile", "alloc_space"
+	}
+	prof := ProfFilePath(kind)
+	args := []string{"test", flag + "=" + prof, "-o", filepath.Join(os.TempDir(), fmt.Sprintf("gide-prof-%d.test", os.Getpid()))}
+	if pv.Bench != "" {
+		args = append(args, "-run", "^$", "-bench", pv.Bench, "-benchmem")
+	}
+	args = append(args, ".")
+	pv.Run(dir, "go", args, prof, sidx)
+}
+
+// ProfileURL gets the profile of a running program from given net/http/pprof
+// URL in the background, and then shows it
+func (pv *ProfView) ProfileURL(url string) {
+	if pv.Running || url == "" {
+		return
+	}
+	prof := ProfFilePath("url")
+	pv.Run(string(pv.Gide.ProjPrefs().ProjRoot), "go", []string{"tool", "pprof", "-proto", "-output=" + prof, url}, prof, "")
+}
+
+// Run runs given command in the background, which writes the profile to
+// given file, and then reads and shows it
+func (pv *ProfView) Run(dir, ex string, args []string, prof, sidx string) {
+	ge := pv.Gide
+	pv.Running = true
+	os.Remove(prof)
+	ge.SetStatus(fmt.Sprintf("profiling in: %v", dir))
+	go func() {
+		cmd := exec.Command(ex, args...)
+		cmd.Dir = dir
+		cmd.Env = ge.ProjPrefs().CmdEnv()
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		cma := &CmdAndArgs{Cmd: ex, Args: args}
+		ge.CmdRuns().AddCmd(ProfCmdName, ex+" "+strings.Join(args, " "), cma, cmd)
+		err := cmd.Start()
+		if err == nil {
+			err = cmd.Wait()
+		}
+		ge.CmdRuns().DeleteByName(ProfCmdName)
+		pv.Running = false
+		if pv.This() == nil || pv.IsDeleted() || pv.IsDestroyed() {
+			return
+		}
+		if _, serr := os.Stat(prof); serr != nil {
+			msg := strings.TrimSpace(out.String())
+			if err != nil {
+				msg = err.Error() + ": " + msg
+			}
+			pv.ShowError("profiling failed: " + msg)
+			return
+		}
+		pv.OpenProfile(gi.FileName(prof), sidx)
+	}()
+}
+
+// OpenProfile reads the profile in given file and shows it -- sidx is the
+// sample index, e.g., alloc_space for memory profiles, empty for the
+// default one
+func (pv *ProfView) OpenProfile(fname gi.FileName, sidx string) {
+	pr, err := ReadPprof(string(fname), sidx, pv.Gide.ProjPrefs().CmdEnv())
+	if err != nil {
+		pv.ShowError(err.Error())
+		return
+	}
+	pv.Prof = pr
+	vp := pv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	pr.BuildTree(pv.Tree)
+	fr := pv.SplitView().ChildByName("tree", 1).(*gi.Frame)
+	updt := fr.UpdateStart()
+	fr.SetFullReRender()
+	pv.TreeView().ReSync()
+	pv.TreeView().OpenAll()
+	fr.UpdateEnd(updt)
+	pv.ShowTop()
+	pv.Gide.SetStatus(fmt.Sprintf("profile: %v total %v in %d traces", pr.Type, FormatProfValue(pr.Total, pr.Unit), len(pr.Traces)))
+}
+
+// ShowError shows given error in place of the top functions
+func (pv *ProfView) ShowError(msg string) {
+	vp := pv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	tbuf := pv.TextView().Buf
+	tbuf.New(0)
+	tbuf.AppendTextMarkup([]byte(msg), []byte(html.EscapeString(msg)), giv.EditSignal)
+	pv.Gide.SetStatus(html.EscapeString(strings.SplitN(msg, "\n", 2)[0]))
+}
+
+// ShowTop shows the header of the profile and the table of its top
+// functions, with flat and cumulative values and links to their source
+func (pv *ProfView) ShowTop() {
+	pr := pv.Prof
+	if pr == nil {
+		return
+	}
+	var outlns, outmus [][]byte
+	add := func(ln, mu string) {
+		outlns = append(outlns, []byte(ln))
+		outmus = append(outmus, []byte(mu))
+	}
+	for _, hl := range pr.Header {
+		add(hl, html.EscapeString(hl))
+	}
+	add("", "")
+	hdr := fmt.Sprintf("%10s %6s %10s %6s  %v", "flat", "flat%", "cum", "cum%", "function")
+	add(hdr, "<b>"+html.EscapeString(hdr)+"</b>")
+	for _, pf := range pr.Top(ProfTopN) {
+		cols := fmt.Sprintf("%10s %5.1f%% %10s %5.1f%%  ", FormatProfValue(pf.Flat, pr.Unit), pr.Pct(pf.Flat), FormatProfValue(pf.Cum, pr.Unit), pr.Pct(pf.Cum))
+		mu := html.EscapeString(pf.Func)
+		if pf.Pos.Path != "" {
+			mu = fmt.Sprintf(`<a href="file:///%v#L%d">%v</a>`, pf.Pos.Path, pf.Pos.Line, mu)
+		}
+		add(cols+pf.Func, cols+mu)
+	}
+	tbuf := pv.TextView().Buf
+	tbuf.New(0)
+	tbuf.SetInactive(true)
+	tbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), giv.EditSignal)
+}
+
+// OpenWeb opens the current profile in the pprof web UI, with go tool
+// pprof -http, which opens it in the browser
+func (pv *ProfView) OpenWeb() {
+	if pv.Prof == nil {
+		pv.Gide.SetStatus("no profile to open: profile first")
+		return
+	}
+	ge := pv.Gide
+	ge.CmdRuns().KillByName(ProfWebCmdName)
+	args := []string{"tool", "pprof", "-http=localhost:0", pv.Prof.File}
+	cmd := exec.Command("go", args...)
+	cmd.Env = ge.ProjPrefs().CmdEnv()
+	if err := cmd.Start(); err != nil {
+		pv.ShowError(err.Error())
+		return
+	}
+	ge.CmdRuns().AddCmd(ProfWebCmdName, "go "+strings.Join(args, " "), &CmdAndArgs{Cmd: "go", Args: args}, cmd)
+	go cmd.Wait()
+}
+
+// ProfViewProps are style properties for ProfView
+var ProfViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// ProfTreeView
+
+// ProfTreeView is a TreeView of ProfNode nodes
+type ProfTreeView struct {
+	giv.TreeView
+}
+
+var KiT_ProfTreeView = kit.Types.AddType(&ProfTreeView{}, nil)
+
+func init() {
+	kit.Types.SetProps(KiT_ProfTreeView, TestTreeViewProps)
+}
+
+// ProfNode returns the SrcNode as a ProfNode
+func (pt *ProfTreeView) ProfNode() *ProfNode {
+	pn := pt.SrcNode.Embed(KiT_ProfNode)
+	if pn == nil {
+		return nil
+	}
+	return pn.(*ProfNode)
+}
+
+// ProfHotColors are the colors of the nodes of the call tree with at
+// least 50%, 20% and 5% of the total
+var ProfHotColors = []string{"#e53935", "#fb8c00", "#fdd835"}
+
+func (pt *ProfTreeView) Style2D() {
+	pn := pt.ProfNode()
+	pt.Class = ""
+	if pn != nil {
+		pt.Icon = gi.IconName("function")
+		clr := ""
+		switch {
+		case pn.Func == "":
+		case pn.Pct >= 50:
+			clr = ProfHotColors[0]
+		case pn.Pct >= 20:
+			clr = ProfHotColors[1]
+		case pn.Pct >= 5:
+			clr = ProfHotColors[2]
+		}
+		if clr != "" {
+			pt.SetProp("color", clr)
+		} else {
+			pt.DeleteProp("color")
+		}
+	}
+	pt.StyleTreeView()
+	pt.LayState.SetFromStyle(&pt.Sty.Layout) // also does reset
+}
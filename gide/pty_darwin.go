@@ -0,0 +1,53 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin
+// +build darwin
+
+package gide
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// OpenPty opens a new pseudo-terminal, returning its master (pty) and
+// slave (tty) ends
+func OpenPty() (pty, tty *os.File, err error) {
+	pty, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = ptyIoctl(pty.Fd(), syscall.TIOCPTYGRANT, 0); err != nil {
+		pty.Close()
+		return nil, nil, err
+	}
+	if err = ptyIoctl(pty.Fd(), syscall.TIOCPTYUNLK, 0); err != nil {
+		pty.Close()
+		return nil, nil, err
+	}
+	nm := make([]byte, 128)
+	if err = ptyIoctl(pty.Fd(), syscall.TIOCPTYGNAME, uintptr(unsafe.Pointer(&nm[0]))); err != nil {
+		pty.Close()
+		return nil, nil, err
+	}
+	if i := bytes.IndexByte(nm, 0); i >= 0 {
+		nm = nm[:i]
+	}
+	tty, err = os.OpenFile(string(nm), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		pty.Close()
+		return nil, nil, err
+	}
+	return pty, tty, nil
+}
+
+// TermProcCwd returns the current working directory of given process, ""
+// as it is not available on this platform -- shells reporting it with
+// OSC 7 are tracked by the TermScreen
+func TermProcCwd(pid int) string {
+	return ""
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package gide
+
+import (
+	"io"
+	"os"
+	"os/exec"
+)
+
+// HasPty is true if terminals run in a pseudo-terminal on this platform --
+// otherwise they are connected by pipes, without line editing or resizing
+const HasPty = false
+
+// pipePty is the pipes standing in for the pseudo-terminal of a command
+type pipePty struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// StartPty starts given command connected by pipes, as pseudo-terminals
+// are not available on this platform, returning the pipes
+func StartPty(cmd *exec.Cmd, rows, cols int) (io.ReadWriteCloser, error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		pr.Close()
+		pw.Close()
+		return nil, err
+	}
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	err = cmd.Start()
+	pw.Close()
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	return &pipePty{pr, in}, nil
+}
+
+// SetPtySize does nothing, as the pipes of the command have no size
+func SetPtySize(pty io.ReadWriteCloser, rows, cols int) error {
+	return nil
+}
+
+// TermProcCwd returns the current working directory of given process, ""
+// as it is not available on this platform -- shells reporting it with
+// OSC 7 are tracked by the TermScreen
+func TermProcCwd(pid int) string {
+	return ""
+}
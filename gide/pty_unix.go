@@ -0,0 +1,61 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package gide
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// HasPty is true if terminals run in a pseudo-terminal on this platform --
+// otherwise they are connected by pipes, without line editing or resizing
+const HasPty = true
+
+// ptyIoctl does an ioctl on given file descriptor
+func ptyIoctl(fd, req, arg uintptr) error {
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg)
+	if e != 0 {
+		return e
+	}
+	return nil
+}
+
+// StartPty starts given command in a new pseudo-terminal of given size, as
+// the leader of a new session with the terminal as its controlling
+// terminal, returning the master end, to read its output and write its input
+func StartPty(cmd *exec.Cmd, rows, cols int) (io.ReadWriteCloser, error) {
+	pty, tty, err := OpenPty()
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+	SetPtySize(pty, rows, cols)
+	cmd.Stdin = tty
+	cmd.Stdout = tty
+	cmd.Stderr = tty
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+	if err := cmd.Start(); err != nil {
+		pty.Close()
+		return nil, err
+	}
+	return pty, nil
+}
+
+// SetPtySize sets the size of given pseudo-terminal, in rows and columns,
+// which sends SIGWINCH to the programs running in it
+func SetPtySize(pty io.ReadWriteCloser, rows, cols int) error {
+	f, ok := pty.(*os.File)
+	if !ok {
+		return nil
+	}
+	ws := struct{ Row, Col, X, Y uint16 }{uint16(rows), uint16(cols), 0, 0}
+	return ptyIoctl(f.Fd(), syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&ws)))
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+)
+
+// QuickOpenMax is the maximum number of matching files shown in QuickOpen
+var QuickOpenMax = 100
+
+// QuickOpenMaxFiles is the maximum number of files in a project that are
+// read for QuickOpen
+var QuickOpenMaxFiles = 100000
+
+// QuickOpenRecentBonus is the score added to recently-viewed files, which
+// is scaled down by how long ago they were viewed
+var QuickOpenRecentBonus = 20
+
+// QuickOpen has the files in a project for fuzzy finding and opening them by
+// (part of) their path, without navigating the file tree.  Matches are
+// ranked by how well they match, how recently the file was viewed, and
+// how shallow it is in the directory tree.
+type QuickOpen struct {
+	Root    string   `desc:"root directory of the project"`
+	Files   []string `desc:"all the files in the project, relative to Root, using / separators"`
+	Recent  []string `desc:"recently-viewed files, relative to Root, most recent first"`
+	Matches []string `desc:"files matching the current pattern, best first"`
+}
+
+// errQuickOpenFull stops the walk of ReadFiles once QuickOpenMaxFiles files
+// are read
+var errQuickOpenFull = errors.New("quick open: too many files")
+
+// QuickOpenSkip returns true if given file or directory should not be
+// included in QuickOpen: the version control directories (e.g., .git, see
+// VersCtrlMarkers), autosave files, project files, and anything matching
+// given exclude globs (see FindGlobsMatchPath) -- the other hidden files
+// and directories are skipped by FileIgnore, unless the ShowHidden pref is
+// set
+func QuickOpenSkip(rpath string, isDir bool, exclude []string) bool {
+	_, nm := filepath.Split(rpath)
+	for _, vm := range VersCtrlMarkers {
+		if nm == vm.File {
+			return true
+		}
+	}
+	if !isDir && (strings.HasPrefix(nm, "#") || strings.HasSuffix(nm, ".gide")) {
+		return true
+	}
+	return len(exclude) > 0 && FindGlobsMatchPath(exclude, rpath, false)
+}
+
+// ReadFiles reads all of the files within given root directory, skipping
+// those for which QuickOpenSkip is true, and those ignored by ign (if non-nil)
+func (qo *QuickOpen) ReadFiles(root string, exclude []string, ign *FileIgnore) {
+	qo.Root = root
+	qo.Files = nil
+	ign.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || pth == root {
+			return nil
+		}
+		rel, rerr := filepath.Rel(root, pth)
+		if rerr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if QuickOpenSkip(rel, info.IsDir(), exclude) || ign.Ignored(pth, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		qo.Files = append(qo.Files, rel)
+		if len(qo.Files) >= QuickOpenMaxFiles {
+			return errQuickOpenFull
+		}
+		return nil
+	})
+}
+
+// AddRecent adds given file (full path) to the end of the Recent list, if
+// it is within Root
+func (qo *QuickOpen) AddRecent(fpath string) {
+	rel, err := filepath.Rel(qo.Root, fpath)
+	if err != nil || RelOutside(rel) {
+		return
+	}
+	qo.Recent = append(qo.Recent, filepath.ToSlash(rel))
+}
+
+// FuzzyMatch returns a score for how well given pattern matches given path,
+// with the pattern characters occurring in order but not necessarily
+// together (ignoring case), and false if it does not match.  Higher scores
+// are better: runs of consecutive characters, and characters at the start
+// of path elements or words, count more, as do those within the file name.
+// The best score over all starting points of the first character is used.
+func FuzzyMatch(pat, path string) (int, bool) {
+	pr := lowerRunes(pat)
+	if len(pr) == 0 {
+		return 0, true
+	}
+	sr := []rune(path)
+	lr := lowerRunes(path)
+	nmst := 0
+	for i, r := range sr {
+		if r == '/' {
+			nmst = i + 1
+		}
+	}
+	best := -1
+	for st := range lr {
+		if lr[st] != pr[0] {
+			continue
+		}
+		if sc, ok := fuzzyMatchFrom(pr, sr, lr, st, nmst); ok && sc > best {
+			best = sc
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	return best, true
+}
+
+// lowerRunes returns the runes of given string, each lowercased, so that
+// they are at the same indexes as those of the string (unlike the runes of
+// strings.ToLower, whose count can differ)
+func lowerRunes(s string) []rune {
+	rs := []rune(s)
+	for i, r := range rs {
+		rs[i] = unicode.ToLower(r)
+	}
+	return rs
+}
+
+// fuzzyMatchFrom returns the FuzzyMatch score for matching pattern runes
+// pr greedily starting at index st in path runes sr (lowercase lr), where
+// the file name starts at nmst
+func fuzzyMatchFrom(pr, sr, lr []rune, st, nmst int) (int, bool) {
+	score := 0
+	pi := 0
+	prev := -2
+	for i := st; i < len(lr) && pi < len(pr); i++ {
+		if lr[i] != pr[pi] {
+			continue
+		}
+		sc := 1
+		if i == prev+1 {
+			sc += 5
+		}
+		if i == 0 || strings.ContainsRune("/_-. ", sr[i-1]) || (unicode.IsUpper(sr[i]) && unicode.IsLower(sr[i-1])) {
+			sc += 3
+		}
+		if i >= nmst {
+			sc += 2
+		}
+		score += sc
+		prev = i
+		pi++
+	}
+	return score, pi == len(pr)
+}
+
+// Match sets Matches to the files matching given pattern (spaces are
+// ignored), in rank order, up to QuickOpenMax
+func (qo *QuickOpen) Match(pat string) {
+	pat = strings.Replace(pat, " ", "", -1)
+	recent := make(map[string]int, len(qo.Recent))
+	for i, rf := range qo.Recent {
+		if _, has := recent[rf]; !has {
+			recent[rf] = i
+		}
+	}
+	type match struct {
+		File  string
+		Score int
+	}
+	var ms []match
+	for _, f := range qo.Files {
+		sc, ok := FuzzyMatch(pat, f)
+		if !ok {
+			continue
+		}
+		if ri, has := recent[f]; has {
+			sc += QuickOpenRecentBonus * (len(qo.Recent) - ri) / len(qo.Recent)
+		}
+		sc -= strings.Count(f, "/")
+		ms = append(ms, match{f, sc})
+	}
+	sort.SliceStable(ms, func(i, j int) bool {
+		return ms[i].Score > ms[j].Score
+	})
+	if len(ms) > QuickOpenMax {
+		ms = ms[:QuickOpenMax]
+	}
+	qo.Matches = make([]string, len(ms))
+	for i, m := range ms {
+		qo.Matches[i] = m.File
+	}
+}
+
+// QuickOpenDialog opens a dialog for fuzzy finding a file in given
+// QuickOpen: the list of matches is updated as the pattern is typed, and
+// return opens the selected file, or the best match if none is selected.
+// Use QuickOpenDialogValue to get the file chosen.
+func QuickOpenDialog(avp *gi.Viewport2D, qo *QuickOpen, opts giv.DlgOpts, recv ki.Ki, dlgFunc ki.RecvFunc) *gi.Dialog {
+	dlg := gi.NewStdDialog(opts.ToGiOpts(), gi.AddOk, gi.AddCancel)
+	dlg.Modal = true
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	tf := frame.InsertNewChild(gi.KiT_TextField, prIdx+1, "pattern").(*gi.TextField)
+	tf.Placeholder = "type any part of the file path.."
+	tf.SetStretchMaxWidth()
+	tf.SetMinPrefWidth(units.NewCh(60))
+
+	qo.Match("")
+	sv := frame.InsertNewChild(giv.KiT_SliceView, prIdx+2, "matches").(*giv.SliceView)
+	sv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	sv.SetInactiveState(true)
+	sv.SetProp("index", false)
+	sv.SetStretchMaxWidth()
+	sv.SetMinPrefHeight(units.NewEm(20))
+	sv.SetSlice(&qo.Matches)
+
+	tf.TextFieldSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		switch sig {
+		case int64(gi.TextFieldInsert), int64(gi.TextFieldBackspace), int64(gi.TextFieldDelete), int64(gi.TextFieldCleared):
+			qo.Match(string(tf.EditTxt)) // not Text(), which ends the edit
+			sv.SelectedIdx = -1
+			sv.ResetSelectedIdxs()
+			sv.SetSlice(&qo.Matches)
+		case int64(gi.TextFieldDone):
+			ddlg := recv.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			ddlg.Accept()
+		}
+	})
+	sv.SliceViewSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.SliceViewDoubleClicked) {
+			ddlg := recv.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			ddlg.Accept()
+		}
+	})
+
+	if recv != nil && dlgFunc != nil {
+		dlg.DialogSig.Connect(recv, dlgFunc)
+	}
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, func() {
+		tf.GrabFocus()
+	})
+	return dlg
+}
+
+// QuickOpenDialogValue returns the full path of the file chosen in given
+// QuickOpenDialog for given QuickOpen -- empty if there are no matches
+func QuickOpenDialogValue(dlg *gi.Dialog, qo *QuickOpen) string {
+	m := QuickOpenDialogMatch(dlg, qo)
+	if m == "" {
+		return ""
+	}
+	return filepath.Join(qo.Root, filepath.FromSlash(m))
+}
+
+// QuickOpenDialogMatch returns the match chosen in given QuickOpenDialog
+// for given QuickOpen, as is -- the selected one, else the best one --
+// empty if there are no matches
+func QuickOpenDialogMatch(dlg *gi.Dialog, qo *QuickOpen) string {
+	if len(qo.Matches) == 0 {
+		return ""
+	}
+	idx := 0
+	if sv, ok := dlg.Frame().ChildByName("matches", 0).(*giv.SliceView); ok && sv.SelectedIdx >= 0 && sv.SelectedIdx < len(qo.Matches) {
+		idx = sv.SelectedIdx
+	}
+	return qo.Matches[idx]
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pat   string
+		path  string
+		match bool
+	}{
+		{"", "a/b.go", true},
+		{"bgo", "a/b.go", true},
+		{"BGO", "a/b.go", true},
+		{"gide", "gidev/gideview.go", true},
+		{"ogb", "a/b.go", false},
+		{"abcd", "a/b.go", false},
+		{"x", "İstanbul/x.go", true},
+		{"i̇x", "İstanbul/x.go", false}, // İ lowercases to one rune here, not two
+		{"istx", "İstanbul/x.go", true},
+		{"ß", "Straße/a.go", true},
+		{"ssa", "Straße/a.go", false},
+		{"é/a", "café/a.go", true},
+	}
+	for _, tst := range tests {
+		if _, ok := FuzzyMatch(tst.pat, tst.path); ok != tst.match {
+			t.Errorf("FuzzyMatch error: %q in %q: should have been: %v  was: %v\n", tst.pat, tst.path, tst.match, ok)
+		}
+	}
+}
+
+func TestFuzzyMatchRank(t *testing.T) {
+	tests := []struct {
+		pat    string
+		better string
+		worse  string
+	}{
+		{"view", "gide/view.go", "gide/v_i_e_w.go"}, // consecutive
+		{"tv", "gide/textview.go", "gide/otxv.go"},  // word starts
+		{"tv", "gide/TextView.go", "gide/xtxv.go"},  // camel case
+		{"term", "x/term.go", "term/x.go"},          // within the file name
+		{"abc", "x/aXbc.go", "x/aXbXc.go"},          // best over starts
+		{"main", "cmd/gide/main.go", "mxaxixn/mxaxixn.go"},
+	}
+	for _, tst := range tests {
+		bs, bok := FuzzyMatch(tst.pat, tst.better)
+		ws, wok := FuzzyMatch(tst.pat, tst.worse)
+		if !bok || !wok || bs <= ws {
+			t.Errorf("FuzzyMatch error: %q should match %q (%d) better than %q (%d)\n", tst.pat, tst.better, bs, tst.worse, ws)
+		}
+	}
+}
+
+func TestQuickOpenMatch(t *testing.T) {
+	qo := &QuickOpen{Files: []string{"a/b/c/view.go", "view.go", "other.go", "a/view.go"}}
+	qo.Match("view")
+	want := []string{"view.go", "a/view.go", "a/b/c/view.go"}
+	if !reflect.DeepEqual(qo.Matches, want) {
+		t.Errorf("Match error: should have been: %v  was: %v\n", want, qo.Matches)
+	}
+	qo.Recent = []string{"a/b/c/view.go"}
+	qo.Match("v i e w")
+	if len(qo.Matches) == 0 || qo.Matches[0] != "a/b/c/view.go" {
+		t.Errorf("Match error: recent file should be first, was: %v\n", qo.Matches)
+	}
+}
+
+func TestQuickOpenReadFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "gide-quickopen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	for _, fn := range []string{"a.go", "sub/b.go", ".git/config", ".hidden/h.go", "#a.go#", "p.gide", "gen/x.pb.go", "vendor/v.go"} {
+		fpath := filepath.Join(root, filepath.FromSlash(fn))
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		if err := ioutil.WriteFile(fpath, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ign := &FileIgnore{}
+	ign.Open(root)
+	ign.SetFilePrefs(&FilePrefs{ShowHidden: true})
+	qo := &QuickOpen{}
+	qo.ReadFiles(root, []string{"vendor", "*.pb.go"}, ign)
+	sort.Strings(qo.Files)
+	want := []string{".hidden/h.go", "a.go", "sub/b.go"}
+	if !reflect.DeepEqual(qo.Files, want) {
+		t.Errorf("ReadFiles error: should have been: %v  was: %v\n", want, qo.Files)
+	}
+	ign.SetFilePrefs(&FilePrefs{})
+	qo.ReadFiles(root, nil, ign)
+	sort.Strings(qo.Files)
+	want = []string{"a.go", "gen/x.pb.go", "sub/b.go", "vendor/v.go"}
+	if !reflect.DeepEqual(qo.Files, want) {
+		t.Errorf("ReadFiles error: should have been: %v  was: %v\n", want, qo.Files)
+	}
+	omax := QuickOpenMaxFiles
+	QuickOpenMaxFiles = 2
+	defer func() { QuickOpenMaxFiles = omax }()
+	qo.ReadFiles(root, nil, ign)
+	if len(qo.Files) != 2 {
+		t.Errorf("ReadFiles error: should have stopped at 2 files, was: %v\n", qo.Files)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+)
+
+// RecentProj is a recently-opened project
+type RecentProj struct {
+	Path   string    `width:"60" inactive:"+" desc:"project file (.gide) or directory of the project"`
+	Opened time.Time `inactive:"+" desc:"when the project was last opened"`
+	Pinned bool      `desc:"pinned projects are listed first, and are kept when the list is reset or full"`
+}
+
+// RecentProjs is a list of recently-opened projects, with the pinned ones
+// first, and then the most recently opened
+type RecentProjs []*RecentProj
+
+// RecentProjects is the list of recently-opened projects, shown in the
+// Open Recent menu (via SavedPaths) and the Recent Projects dialog
+var RecentProjects RecentProjs
+
+// RecentProjsFileName is the name of the recent projects file in GoGi prefs directory
+var RecentProjsFileName = "gide_recent_projs.json"
+
+// Sort sorts the list with the pinned projects first, and then by most
+// recently opened
+func (rp *RecentProjs) Sort() {
+	sort.SliceStable(*rp, func(i, j int) bool {
+		pi, pj := (*rp)[i], (*rp)[j]
+		if pi.Pinned != pj.Pinned {
+			return pi.Pinned
+		}
+		return pi.Opened.After(pj.Opened)
+	})
+}
+
+// Find returns the index of the project with given path, -1 if not found
+func (rp *RecentProjs) Find(path string) int {
+	for i, p := range *rp {
+		if p.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add records given project as just opened, and removes the least recently
+// opened unpinned projects beyond max
+func (rp *RecentProjs) Add(path string, max int) {
+	if idx := rp.Find(path); idx >= 0 {
+		(*rp)[idx].Opened = time.Now()
+	} else {
+		*rp = append(*rp, &RecentProj{Path: path, Opened: time.Now()})
+	}
+	rp.Sort()
+	for i := len(*rp) - 1; i >= 0 && len(*rp) > max; i-- {
+		if !(*rp)[i].Pinned {
+			*rp = append((*rp)[:i], (*rp)[i+1:]...)
+		}
+	}
+}
+
+// Pin sets whether the project with given path is pinned -- returns false
+// if it is not on the list
+func (rp *RecentProjs) Pin(path string, pin bool) bool {
+	idx := rp.Find(path)
+	if idx < 0 {
+		return false
+	}
+	(*rp)[idx].Pinned = pin
+	rp.Sort()
+	return true
+}
+
+// IsPinned returns true if the project with given path is pinned
+func (rp *RecentProjs) IsPinned(path string) bool {
+	idx := rp.Find(path)
+	return idx >= 0 && (*rp)[idx].Pinned
+}
+
+// Reset removes all the unpinned projects
+func (rp *RecentProjs) Reset() {
+	var pinned RecentProjs
+	for _, p := range *rp {
+		if p.Pinned {
+			pinned = append(pinned, p)
+		}
+	}
+	*rp = pinned
+}
+
+// Paths returns the paths of the projects, in order
+func (rp *RecentProjs) Paths() []string {
+	paths := make([]string, len(*rp))
+	for i, p := range *rp {
+		paths[i] = p.Path
+	}
+	return paths
+}
+
+// OpenJSON opens the list from a JSON-formatted file
+func (rp *RecentProjs) OpenJSON(filename string) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	*rp = nil
+	return json.Unmarshal(b, rp)
+}
+
+// SaveJSON saves the list to a JSON-formatted file
+func (rp *RecentProjs) SaveJSON(filename string) error {
+	b, err := json.MarshalIndent(rp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// UpdateSavedPaths sets the SavedPaths of the Open Recent menu from
+// RecentProjects
+func UpdateSavedPaths() {
+	SavedPaths = gi.FilePaths(RecentProjects.Paths())
+	gi.StringsAddExtras((*[]string)(&SavedPaths), SavedPathsExtras)
+}
+
+// SaveRecentProjs saves RecentProjects to prefs dir, and updates SavedPaths
+func SaveRecentProjs() {
+	pdir := oswin.TheApp.AppPrefsDir()
+	RecentProjects.SaveJSON(filepath.Join(pdir, RecentProjsFileName))
+	UpdateSavedPaths()
+	SavePaths()
+}
+
+// OpenRecentProjs loads RecentProjects from prefs dir -- if not yet saved,
+// it starts with the SavedPaths (from OpenPaths)
+func OpenRecentProjs() {
+	pdir := oswin.TheApp.AppPrefsDir()
+	pnm := filepath.Join(pdir, RecentProjsFileName)
+	if _, err := os.Stat(pnm); os.IsNotExist(err) {
+		RecentProjects = nil
+		paths := make([]string, len(SavedPaths))
+		copy(paths, SavedPaths)
+		gi.StringsRemoveExtras(&paths, SavedPathsExtras)
+		for _, p := range paths {
+			RecentProjects = append(RecentProjects, &RecentProj{Path: p})
+		}
+	} else {
+		RecentProjects.OpenJSON(pnm)
+		RecentProjects.Sort()
+	}
+	UpdateSavedPaths()
+}
+
+// AddRecentProj records given project (.gide file or directory) as just
+// opened, and saves the list
+func AddRecentProj(path string) {
+	RecentProjects.Add(path, gi.Prefs.Params.SavedPathsMax)
+	SaveRecentProjs()
+}
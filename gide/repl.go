@@ -0,0 +1,83 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/pi/filecat"
+)
+
+// Repl is an interpreter run by the REPL consoles, reading the code to
+// evaluate from its standard input
+type Repl struct {
+	Name string            `width:"12" desc:"name of the REPL, also that of its tab"`
+	Lang filecat.Supported `desc:"language of the REPL: the code of the files of this language is evaluated in it, and highlighted in its input"`
+	Cmd  string            `width:"12" desc:"the interpreter"`
+	Args []string          `desc:"args of the interpreter, making it read its input interactively, without prompts (the console has its own), from pipes"`
+}
+
+// Label satisfies the Labeler interface
+func (rp Repl) Label() string {
+	return rp.Name
+}
+
+// Repls is a list of REPLs
+type Repls []Repl
+
+// StdRepls are the standard REPLs: python, node, and yaegi for Go
+var StdRepls = Repls{
+	{"Python", filecat.Python, "python3", []string{"-q", "-u", "-i", "-c", "import sys; sys.ps1 = sys.ps2 = ''"}},
+	{"Node", filecat.JavaScript, "node", []string{"-e", "require('repl').start({prompt: '', terminal: false})"}},
+	{"Go", filecat.Go, "yaegi", nil},
+}
+
+// AvailRepls returns the REPLs of the REPL consoles: the StdRepls, and those
+// of the Repls preferences, which override the standard ones of the same
+// name
+func AvailRepls() Repls {
+	rps := append(Repls{}, StdRepls...)
+	for _, prp := range Prefs.Repls {
+		found := false
+		for i := range rps {
+			if rps[i].Name == prp.Name {
+				rps[i] = prp
+				found = true
+				break
+			}
+		}
+		if !found {
+			rps = append(rps, prp)
+		}
+	}
+	return rps
+}
+
+// Names returns the names of the REPLs
+func (rs Repls) Names() []string {
+	nms := make([]string, len(rs))
+	for i, rp := range rs {
+		nms[i] = rp.Name
+	}
+	return nms
+}
+
+// ByName returns the REPL of given name, false if none
+func (rs Repls) ByName(name string) (*Repl, bool) {
+	for i := range rs {
+		if rs[i].Name == name {
+			return &rs[i], true
+		}
+	}
+	return nil, false
+}
+
+// ByLang returns the first REPL of given language, false if none
+func (rs Repls) ByLang(lang filecat.Supported) (*Repl, bool) {
+	for i := range rs {
+		if rs[i].Lang == lang {
+			return &rs[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,435 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+)
+
+// ReplHistMax is the max number of inputs kept in the history of each REPL
+var ReplHistMax = 200
+
+// ReplTabName returns the name of the tab of the REPL of given name
+func ReplTabName(name string) string {
+	return "REPL " + name
+}
+
+// ReplView is a REPL console: an interpreter (see Repl) running in the
+// project root, with its output, and a multi-line input evaluated with
+// Control+Enter, with the history of the inputs of the project
+type ReplView struct {
+	gi.Layout
+	Gide    Gide           `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Repl    Repl           `desc:"the interpreter"`
+	Cmd     *exec.Cmd      `json:"-" xml:"-" view:"-" desc:"the running interpreter"`
+	Stdin   io.WriteCloser `json:"-" xml:"-" view:"-" desc:"the input of the running interpreter"`
+	HistIdx int            `desc:"index in the history of the input shown, len of the history for a new input"`
+	Mu      sync.Mutex     `json:"-" xml:"-" view:"-" desc:"mutex protecting the interpreter"`
+}
+
+var KiT_ReplView = kit.Types.AddType(&ReplView{}, ReplViewProps)
+
+// Config configures the view for given REPL, starting it if not running
+func (rv *ReplView) Config(ge Gide, rp *Repl) {
+	rv.Gide = ge
+	rv.Repl = *rp
+	rv.Lay = gi.LayoutVert
+	rv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "replbar")
+	config.Add(gi.KiT_SplitView, "replsplit")
+	mods, updt := rv.ConfigChildren(config)
+	if !mods {
+		updt = rv.UpdateStart()
+	}
+	rv.ConfigToolbar()
+	rv.ConfigSplitView()
+	rv.HistIdx = len(rv.History())
+	rv.UpdateEnd(updt)
+	if !rv.IsRunning() {
+		rv.Start()
+	}
+}
+
+// ToolBar returns the toolbar
+func (rv *ReplView) ToolBar() *gi.ToolBar {
+	return rv.ChildByName("replbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the output and the input
+func (rv *ReplView) SplitView() *gi.SplitView {
+	return rv.ChildByName("replsplit", 1).(*gi.SplitView)
+}
+
+// OutView returns the TextView of the output
+func (rv *ReplView) OutView() *giv.TextView {
+	ly := rv.SplitView().ChildByName("out", 0).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// InView returns the text of the input
+func (rv *ReplView) InView() *ReplText {
+	return rv.SplitView().ChildByName("in", 1).Embed(KiT_ReplText).(*ReplText)
+}
+
+// History returns the history of the inputs of the REPL in the project,
+// the oldest first
+func (rv *ReplView) History() []string {
+	return rv.Gide.ProjPrefs().ReplHist[rv.Repl.Name]
+}
+
+// AddHistory adds given input to the history, keeping the last ReplHistMax
+func (rv *ReplView) AddHistory(code string) {
+	pf := rv.Gide.ProjPrefs()
+	if pf.ReplHist == nil {
+		pf.ReplHist = make(map[string][]string)
+	}
+	hist := pf.ReplHist[rv.Repl.Name]
+	if n := len(hist); n == 0 || hist[n-1] != code {
+		hist = append(hist, code)
+	}
+	if len(hist) > ReplHistMax {
+		hist = hist[len(hist)-ReplHistMax:]
+	}
+	pf.ReplHist[rv.Repl.Name] = hist
+	rv.HistIdx = len(hist)
+}
+
+// IsRunning returns true if the interpreter is running
+func (rv *ReplView) IsRunning() bool {
+	rv.Mu.Lock()
+	defer rv.Mu.Unlock()
+	return rv.Cmd != nil
+}
+
+// Start (re)starts the interpreter in the project root
+func (rv *ReplView) Start() {
+	rv.Kill()
+	ge := rv.Gide
+	name := ReplTabName(rv.Repl.Name)
+	cmd := exec.Command(rv.Repl.Cmd, rv.Repl.Args...)
+	cmd.Dir = string(ge.ProjPrefs().ProjRoot)
+	cmd.Env = ge.ProjPrefs().CmdEnv()
+	stdin, err := cmd.StdinPipe()
+	var stdout io.ReadCloser
+	if err == nil {
+		stdout, err = cmd.StdoutPipe()
+	}
+	if err == nil {
+		cmd.Stderr = cmd.Stdout
+		err = cmd.Start()
+	}
+	if err != nil {
+		rv.Event(fmt.Sprintf("could not start %v: %v", rv.Repl.Cmd, err))
+		return
+	}
+	rv.Mu.Lock()
+	rv.Cmd = cmd
+	rv.Stdin = stdin
+	rv.Mu.Unlock()
+	ge.CmdRuns().DeleteByName(name)
+	ge.CmdRuns().AddCmd(name, rv.Repl.Cmd, &CmdAndArgs{Cmd: rv.Repl.Cmd, Args: rv.Repl.Args}, cmd)
+	rv.Event("started: " + rv.Repl.Cmd)
+	go func() {
+		obuf := OutBuf{}
+		obuf.Init(stdout, rv.OutView().Buf, 0, MarkupCmdOutput)
+		obuf.MonOut()
+		werr := cmd.Wait()
+		rv.Mu.Lock()
+		cur := rv.Cmd == cmd
+		if cur {
+			rv.Cmd = nil
+			rv.Stdin = nil
+		}
+		rv.Mu.Unlock()
+		if !cur {
+			return // killed for a restart or closed
+		}
+		ge.CmdRuns().DeleteByName(name)
+		msg := "exited"
+		if werr != nil {
+			msg = "exited: " + werr.Error()
+		}
+		rv.Event(msg + " -- evaluate to restart")
+	}()
+}
+
+// Kill kills the interpreter, if running
+func (rv *ReplView) Kill() {
+	rv.Mu.Lock()
+	cmd, stdin := rv.Cmd, rv.Stdin
+	rv.Cmd = nil
+	rv.Stdin = nil
+	rv.Mu.Unlock()
+	if stdin != nil {
+		stdin.Close()
+	}
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
+
+// Interrupt interrupts the code running in the interpreter -- not
+// supported on windows, where it restarts it
+func (rv *ReplView) Interrupt() {
+	rv.Mu.Lock()
+	cmd := rv.Cmd
+	rv.Mu.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	if runtime.GOOS == "windows" {
+		rv.Start()
+		return
+	}
+	cmd.Process.Signal(os.Interrupt)
+}
+
+// Eval evaluates given code in the interpreter, starting it if not
+// running, echoing it in the output, and adding it to the history -- a
+// blank line ends the code of more than one line, to close its blocks
+func (rv *ReplView) Eval(code string) error {
+	code = strings.TrimRight(code, " \t\r\n")
+	if code == "" {
+		return nil
+	}
+	if !rv.IsRunning() {
+		rv.Start()
+	}
+	rv.Mu.Lock()
+	stdin := rv.Stdin
+	rv.Mu.Unlock()
+	if stdin == nil {
+		return fmt.Errorf("%v is not running", rv.Repl.Name)
+	}
+	rv.AddHistory(code)
+	rv.Echo(code)
+	in := code + "\n"
+	if strings.Contains(code, "\n") {
+		in += "\n"
+	}
+	_, err := stdin.Write([]byte(in))
+	return err
+}
+
+// EvalInput evaluates the input, clearing it
+func (rv *ReplView) EvalInput() {
+	it := rv.InView()
+	code := string(it.Buf.Text())
+	if err := rv.Eval(code); err != nil {
+		rv.Gide.SetStatus(err.Error())
+		return
+	}
+	it.SetInput("")
+}
+
+// HistoryStep shows the input of the history before (-1) or after (+1)
+// the current one in the input -- after the last, the input is cleared
+func (rv *ReplView) HistoryStep(dir int) {
+	hist := rv.History()
+	idx := rv.HistIdx + dir
+	if idx < 0 || idx > len(hist) {
+		return
+	}
+	rv.HistIdx = idx
+	if idx == len(hist) {
+		rv.InView().SetInput("")
+	} else {
+		rv.InView().SetInput(hist[idx])
+	}
+}
+
+// ChooseHistory pops up a menu to select an input of the history, shown
+// in the input for editing
+func (rv *ReplView) ChooseHistory() {
+	hist := rv.History()
+	if len(hist) == 0 {
+		rv.Gide.SetStatus("no history for " + rv.Repl.Name)
+		return
+	}
+	nms := make([]string, len(hist))
+	for i := range hist {
+		ln := strings.Replace(hist[len(hist)-1-i], "\n", " ⏎ ", -1)
+		if len(ln) > 80 {
+			ln = ln[:80] + "..."
+		}
+		nms[i] = fmt.Sprintf("%d: %v", len(hist)-i, ln)
+	}
+	gi.StringsChooserPopup(nms, "", rv.ToolBar(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		var idx int
+		fmt.Sscanf(ac.Text, "%d:", &idx)
+		if idx < 1 || idx > len(hist) {
+			return
+		}
+		rv.HistIdx = idx - 1
+		rv.InView().SetInput(hist[idx-1])
+		rv.InView().GrabFocus()
+	})
+}
+
+// Echo shows given input in the output, in bold
+func (rv *ReplView) Echo(code string) {
+	ge := rv.Gide
+	wupdt := ge.VPort().TopUpdateStart()
+	defer ge.VPort().TopUpdateEnd(wupdt)
+	buf := rv.OutView().Buf
+	for i, ln := range strings.Split(code, "\n") {
+		pfx := "> "
+		if i > 0 {
+			pfx = ". "
+		}
+		ln = pfx + ln
+		buf.AppendTextLineMarkup([]byte(ln), []byte("<b>"+html.EscapeString(ln)+"</b>"), giv.EditSignal)
+	}
+	buf.AutoScrollViews()
+}
+
+// Event appends given event of the interpreter, in italics, to the output
+func (rv *ReplView) Event(msg string) {
+	ge := rv.Gide
+	wupdt := ge.VPort().TopUpdateStart()
+	defer ge.VPort().TopUpdateEnd(wupdt)
+	ln := "[" + rv.Repl.Name + " " + msg + "]"
+	buf := rv.OutView().Buf
+	buf.AppendTextLineMarkup([]byte(ln), []byte("<i>"+html.EscapeString(ln)+"</i>"), giv.EditSignal)
+	buf.AutoScrollViews()
+	ge.SetStatus(rv.Repl.Name + ": " + msg)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (rv *ReplView) ConfigToolbar() {
+	tb := rv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Eval", Icon: "play", Tooltip: "evaluate the input (also Control+Enter)"},
+		rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			rvv, _ := recv.Embed(KiT_ReplView).(*ReplView)
+			rvv.EvalInput()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Interrupt", Icon: "stop", Tooltip: "interrupt the code running in the interpreter"},
+		rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			rvv, _ := recv.Embed(KiT_ReplView).(*ReplView)
+			rvv.Interrupt()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Restart", Icon: "update", Tooltip: "restart the interpreter, losing its state"},
+		rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			rvv, _ := recv.Embed(KiT_ReplView).(*ReplView)
+			rvv.Start()
+		})
+	tb.AddAction(gi.ActOpts{Label: "History...", Icon: "file-text", Tooltip: "choose an input of the history, for editing it (also Control+Up / Control+Down in the input)"},
+		rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			rvv, _ := recv.Embed(KiT_ReplView).(*ReplView)
+			rvv.ChooseHistory()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear", Icon: "close", Tooltip: "clear the output -- the state of the interpreter is kept"},
+		rv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			rvv, _ := recv.Embed(KiT_ReplView).(*ReplView)
+			rvv.OutView().Buf.New(0)
+		})
+}
+
+// ConfigSplitView configures the split view of the output and the input
+func (rv *ReplView) ConfigSplitView() {
+	split := rv.SplitView()
+	split.Dim = mat32.Y
+	split.SetStretchMaxWidth()
+	split.SetStretchMaxHeight()
+	if len(split.Kids) > 0 {
+		return
+	}
+	ly := gi.AddNewLayout(split, "out", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	it := split.AddNewChild(KiT_ReplText, "in").(*ReplText)
+	it.View = rv
+	it.SetProp("line-nos", false)
+	it.SetProp("font-family", gi.Prefs.MonoFont)
+	it.SetStretchMaxWidth()
+	it.SetStretchMaxHeight()
+	buf := giv.NewTextBuf()
+	buf.Opts.LineNos = false
+	if rv.Repl.Lang != filecat.NoSupport {
+		buf.Info.Sup = rv.Repl.Lang
+		buf.New(1) // highlighting of the language
+	}
+	it.SetBuf(buf)
+	split.SetSplits(.75, .25)
+}
+
+// Destroy kills the interpreter when the tab is closed
+func (rv *ReplView) Destroy() {
+	rv.Kill()
+	rv.Layout.Destroy()
+}
+
+// ReplViewProps are style properties for ReplView
+var ReplViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  ReplText
+
+// ReplText is the input of a REPL console: Control+Enter evaluates it, and
+// Control+Up and Control+Down step through the history
+type ReplText struct {
+	giv.TextView
+	View *ReplView `json:"-" xml:"-" view:"-" desc:"the REPL console"`
+}
+
+var KiT_ReplText = kit.Types.AddType(&ReplText{}, giv.TextViewProps)
+
+// SetInput sets the input to given code, with the cursor at its end
+func (rt *ReplText) SetInput(code string) {
+	rt.Buf.SetText([]byte(code))
+	rt.SetCursorShow(rt.Buf.EndPos())
+}
+
+// ConnectEvents2D takes the REPL keys at high priority, before the text
+// view
+func (rt *ReplText) ConnectEvents2D() {
+	rt.TextViewEvents()
+	rt.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		rtt := recv.Embed(KiT_ReplText).(*ReplText)
+		kt := d.(*key.ChordEvent)
+		if rtt.View == nil || !kt.HasAnyModifier(key.Control, key.Meta) {
+			return
+		}
+		switch kt.Code {
+		case key.CodeReturnEnter:
+			kt.SetProcessed()
+			rtt.View.EvalInput()
+		case key.CodeUpArrow:
+			kt.SetProcessed()
+			rtt.View.HistoryStep(-1)
+		case key.CodeDownArrow:
+			kt.SetProcessed()
+			rtt.View.HistoryStep(1)
+		}
+	})
+}
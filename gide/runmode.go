@@ -0,0 +1,317 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/goki/ki/kit"
+)
+
+// RunMode is the mode in which the project is built and run: normally, or
+// instrumented with the race detector or a sanitizer
+type RunMode int32
+
+const (
+	// RunModeNormal builds without instrumentation
+	RunModeNormal RunMode = iota
+
+	// RunModeRace builds with the race detector (-race)
+	RunModeRace
+
+	// RunModeMSan builds with the memory sanitizer (-msan), for cgo code
+	// -- requires clang
+	RunModeMSan
+
+	// RunModeASan builds with the address sanitizer (-asan), for cgo code
+	RunModeASan
+
+	// RunModeN is the number of run modes
+	RunModeN
+)
+
+//go:generate stringer -type=RunMode
+
+var KiT_RunMode = kit.Enums.AddEnumAltLower(RunModeN, kit.NotBitFlag, nil, "RunMode")
+
+func (ev RunMode) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *RunMode) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// RunModeNames are the names of the run modes, as shown in the Run Mode
+// chooser
+var RunModeNames = [RunModeN]string{"Normal", "Race", "MSan", "ASan"}
+
+// RunModeFlags are the go build flags of the run modes
+var RunModeFlags = [RunModeN]string{"", "-race", "-msan", "-asan"}
+
+// RunModePlatforms are the GOOS/GOARCH platforms supported by each run
+// mode, as listed by go help build -- nil for all
+var RunModePlatforms = [RunModeN][]string{
+	nil,
+	{"linux/amd64", "linux/ppc64le", "linux/arm64", "linux/s390x", "linux/loong64", "freebsd/amd64", "netbsd/amd64", "darwin/amd64", "darwin/arm64", "windows/amd64"},
+	{"linux/amd64", "linux/arm64", "linux/loong64", "freebsd/amd64"},
+	{"linux/arm64", "linux/amd64", "linux/loong64", "linux/riscv64", "linux/ppc64le"},
+}
+
+// RunModeByName returns the run mode of given name, false if none
+func RunModeByName(name string) (RunMode, bool) {
+	for i, nm := range RunModeNames {
+		if strings.EqualFold(nm, name) {
+			return RunMode(i), true
+		}
+	}
+	return RunModeNormal, false
+}
+
+// Supported returns true if the run mode is supported for given GOOS and
+// GOARCH -- empty for the host ones
+func (rm RunMode) Supported(goos, goarch string) bool {
+	plats := RunModePlatforms[rm]
+	if plats == nil {
+		return true
+	}
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	for _, pl := range plats {
+		if pl == goos+"/"+goarch {
+			return true
+		}
+	}
+	return false
+}
+
+// Env returns the environment variables needed by the run mode, in
+// KEY=value form: the sanitizers need cgo, and msan clang
+func (rm RunMode) Env() []string {
+	switch rm {
+	case RunModeMSan:
+		env := []string{"CGO_ENABLED=1"}
+		if os.Getenv("CC") == "" {
+			env = append(env, "CC=clang", "CXX=clang++")
+		}
+		return env
+	case RunModeASan:
+		return []string{"CGO_ENABLED=1"}
+	}
+	return nil
+}
+
+// ActiveRunMode returns the run mode of the project, normal if it is not
+// supported for the GOOS and GOARCH of the active build configuration
+func (pf *ProjPrefs) ActiveRunMode() RunMode {
+	goos, goarch := "", ""
+	if bc := pf.ActiveBuildConfig(); bc != nil {
+		goos, goarch = bc.GOOS, bc.GOARCH
+	}
+	if !pf.RunMode.Supported(goos, goarch) {
+		return RunModeNormal
+	}
+	return pf.RunMode
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Race and sanitizer reports
+
+// SanFrame is a frame of a stack trace in a race or sanitizer report
+type SanFrame struct {
+	Func string  `desc:"function of the frame"`
+	Pos  FilePos `desc:"position in the source"`
+}
+
+// SanSection is a section of a race or sanitizer report: an access, or
+// where a goroutine was created or memory allocated or freed, with its
+// stack trace
+type SanSection struct {
+	Title  string     `desc:"title of the section, e.g., Write at 0xc000018098 by goroutine 7"`
+	Frames []SanFrame `desc:"the frames of its stack trace, innermost first"`
+}
+
+// SanReport is a data race reported by the race detector, or an error
+// reported by a sanitizer, in the output of a command
+type SanReport struct {
+	Kind     string       `desc:"kind of report, e.g., DATA RACE or AddressSanitizer: heap-use-after-free"`
+	Sections []SanSection `desc:"the sections of the report"`
+}
+
+var (
+	// sanRaceStartRe matches the start of a race detector report
+	sanRaceStartRe = regexp.MustCompile(`^WARNING: DATA RACE`)
+
+	// sanStartRe matches the start of a sanitizer report
+	sanStartRe = regexp.MustCompile(`^==\d+==\s*(?:ERROR|WARNING): (\w+Sanitizer:\s*\S+)`)
+
+	// sanEndRe matches the end of a race or sanitizer report
+	sanEndRe = regexp.MustCompile(`^(?:==================$|SUMMARY: |==\d+==\s*ABORTING)`)
+
+	// sanFrameRe matches a frame of a sanitizer stack trace: #0 0x... in func path:line:col
+	sanFrameRe = regexp.MustCompile(`^\s*#\d+\s+0x[0-9a-fA-F]+\s+in\s+(\S+)\s+((?:[A-Za-z]:)?[^\s:()]+):(\d+)(?::(\d+))?`)
+
+	// sanRacePathRe matches the source line of a frame of a race stack trace
+	sanRacePathRe = regexp.MustCompile(`^\s+((?:[A-Za-z]:)?[^\s:()]+\.\w+):(\d+)(?:\s+\+0x[0-9a-fA-F]+)?\s*$`)
+
+	// sanRaceFuncRe matches the function line of a frame of a race stack trace
+	sanRaceFuncRe = regexp.MustCompile(`^\s+(\S+)\(.*\)\s*$`)
+)
+
+// IsSanOutput returns true if given command output may have race or
+// sanitizer reports, for parsing them
+func IsSanOutput(out []byte) bool {
+	return bytes.Contains(out, []byte("WARNING: DATA RACE")) || bytes.Contains(out, []byte("Sanitizer"))
+}
+
+// ParseSanReports parses the data races and sanitizer errors reported in
+// given command output
+func ParseSanReports(out []byte) []*SanReport {
+	var reps []*SanReport
+	var rep *SanReport
+	fn := ""
+	for _, lb := range bytes.Split(out, []byte("\n")) {
+		ln := strings.TrimRight(string(lb), "\r")
+		if sanRaceStartRe.MatchString(ln) {
+			rep = &SanReport{Kind: "DATA RACE"}
+			reps = append(reps, rep)
+			continue
+		}
+		if sm := sanStartRe.FindStringSubmatch(ln); sm != nil {
+			rep = &SanReport{Kind: sm[1]}
+			reps = append(reps, rep)
+			continue
+		}
+		if rep == nil {
+			continue
+		}
+		if sanEndRe.MatchString(ln) {
+			rep = nil
+			continue
+		}
+		trim := strings.TrimSpace(ln)
+		if trim == "" {
+			continue
+		}
+		if ln[0] != ' ' && ln[0] != '\t' {
+			rep.Sections = append(rep.Sections, SanSection{Title: strings.TrimSuffix(trim, ":")})
+			fn = ""
+			continue
+		}
+		if len(rep.Sections) == 0 {
+			rep.Sections = append(rep.Sections, SanSection{Title: rep.Kind})
+		}
+		sec := &rep.Sections[len(rep.Sections)-1]
+		if fm := sanFrameRe.FindStringSubmatch(ln); fm != nil {
+			fr := SanFrame{Func: fm[1], Pos: FilePos{Path: fm[2]}}
+			fr.Pos.Line, _ = strconv.Atoi(fm[3])
+			fr.Pos.Col, _ = strconv.Atoi(fm[4])
+			sec.Frames = append(sec.Frames, fr)
+			continue
+		}
+		if pm := sanRacePathRe.FindStringSubmatch(ln); pm != nil {
+			fr := SanFrame{Func: fn, Pos: FilePos{Path: pm[1]}}
+			fr.Pos.Line, _ = strconv.Atoi(pm[2])
+			sec.Frames = append(sec.Frames, fr)
+			fn = ""
+			continue
+		}
+		if fm := sanRaceFuncRe.FindStringSubmatch(ln); fm != nil {
+			fn = fm[1]
+		}
+	}
+	return reps
+}
+
+// TopFrame returns the first frame of the report in a file under given
+// root directory, or else its first frame -- false if it has no frames
+func (sr *SanReport) TopFrame(root string) (SanFrame, bool) {
+	var first *SanFrame
+	for si := range sr.Sections {
+		for fi := range sr.Sections[si].Frames {
+			fr := &sr.Sections[si].Frames[fi]
+			if first == nil {
+				first = fr
+			}
+			if root != "" && strings.HasPrefix(fr.Pos.Path, root+string(filepath.Separator)) {
+				return *fr, true
+			}
+		}
+	}
+	if first == nil {
+		return SanFrame{}, false
+	}
+	return *first, true
+}
+
+// Summary returns a one line summary of the report: its kind and the
+// titles of its sections
+func (sr *SanReport) Summary() string {
+	ttls := make([]string, 0, len(sr.Sections))
+	for _, sec := range sr.Sections {
+		if sec.Title != sr.Kind {
+			ttls = append(ttls, sec.Title)
+		}
+	}
+	if len(ttls) == 0 {
+		return sr.Kind
+	}
+	return sr.Kind + ": " + strings.Join(ttls, "; ")
+}
+
+// SanProblems returns a problem for each report, at its top frame in the
+// project, for marking them in the files and the Problems panel
+func SanProblems(reps []*SanReport, root, source string) []Problem {
+	var probs []Problem
+	for _, sr := range reps {
+		fr, ok := sr.TopFrame(root)
+		if !ok {
+			continue
+		}
+		probs = append(probs, Problem{Path: fr.Pos.Path, Line: fr.Pos.Line, Col: fr.Pos.Col, Severity: ProblemError, Source: source, Msg: sr.Summary()})
+	}
+	return probs
+}
+
+// SanReportsMarkup returns the lines, and their markup, of the summary of
+// given reports, grouped by report and section, with file:/// links to
+// the frames -- frames outside of the project are left out, except the
+// first of each section
+func SanReportsMarkup(reps []*SanReport, root string) (lns, mus [][]byte) {
+	add := func(ln, mu string) {
+		lns = append(lns, []byte(ln))
+		mus = append(mus, []byte(mu))
+	}
+	hdr := fmt.Sprintf("%d race / sanitizer reports:", len(reps))
+	add(hdr, "<b>"+hdr+"</b>")
+	for ri, sr := range reps {
+		ttl := fmt.Sprintf("%d: %v", ri+1, sr.Kind)
+		add(ttl, `<span style="color: red"><b>`+html.EscapeString(ttl)+"</b></span>")
+		for _, sec := range sr.Sections {
+			if sec.Title != sr.Kind {
+				add("\t"+sec.Title, "\t<b>"+html.EscapeString(sec.Title)+"</b>")
+			}
+			for fi, fr := range sec.Frames {
+				if fi > 0 && root != "" && !strings.HasPrefix(fr.Pos.Path, root+string(filepath.Separator)) {
+					continue
+				}
+				loc := fmt.Sprintf("%v:%d", filepath.Base(fr.Pos.Path), fr.Pos.Line)
+				col := fr.Pos.Col
+				if col == 0 {
+					col = 1
+				}
+				add(fmt.Sprintf("\t\t%v %v", loc, fr.Func), fmt.Sprintf(`		<a href="file:///%v#L%dC%d">%v</a> %v`, fr.Pos.Path, fr.Pos.Line, col, html.EscapeString(loc), html.EscapeString(fr.Func)))
+			}
+		}
+	}
+	return
+}
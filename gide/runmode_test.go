@@ -0,0 +1,112 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"testing"
+)
+
+var sanRaceOut = `==================
+WARNING: DATA RACE
+Write at 0x00c0000a0018 by goroutine 7:
+  main.inc()
+      /proj/main.go:10 +0x3a
+  main.main.func1()
+      /proj/main.go:16 +0x2e
+
+Previous read at 0x00c0000a0018 by goroutine 6:
+  runtime.mapaccess1()
+      /usr/local/go/src/runtime/map.go:412 +0x0
+  main.inc()
+      /proj/main.go:9 +0x2a
+
+Goroutine 7 (running) created at:
+  main.main()
+      /proj/main.go:15 +0x7e
+==================
+Found 1 data race(s)
+exit status 66
+`
+
+var sanAsanOut = "=================================================================\r\n" +
+	"==12345==ERROR: AddressSanitizer: heap-use-after-free on address 0x602000000010 at pc 0x4f5e2b bp 0x7ffd sp 0x7ffd\r\n" +
+	`READ of size 4 at 0x602000000010 thread T0
+    #0 0x4f5e2a in main /proj/uaf.c:7:10
+    #1 0x7f0a12 in __libc_start_main (/lib/x86_64-linux-gnu/libc.so.6+0x21b96)
+
+0x602000000010 is located 0 bytes inside of 4-byte region [0x602000000010,0x602000000014)
+freed by thread T0 here:
+    #0 0x4c6f10 in free (/usr/lib/libasan.so.4+0xdeca8)
+    #1 0x4f5df1 in main /proj/uaf.c:6:3
+
+SUMMARY: AddressSanitizer: heap-use-after-free /proj/uaf.c:7:10 in main
+==12345==ABORTING
+`
+
+func TestParseSanReports(t *testing.T) {
+	fr := func(fn, path string, line, col int) SanFrame {
+		return SanFrame{Func: fn, Pos: FilePos{Path: path, Line: line, Col: col}}
+	}
+	tests := []struct {
+		name string
+		out  string
+		reps []*SanReport
+	}{
+		{"race", sanRaceOut, []*SanReport{{Kind: "DATA RACE", Sections: []SanSection{
+			{Title: "Write at 0x00c0000a0018 by goroutine 7", Frames: []SanFrame{fr("main.inc", "/proj/main.go", 10, 0), fr("main.main.func1", "/proj/main.go", 16, 0)}},
+			{Title: "Previous read at 0x00c0000a0018 by goroutine 6", Frames: []SanFrame{fr("runtime.mapaccess1", "/usr/local/go/src/runtime/map.go", 412, 0), fr("main.inc", "/proj/main.go", 9, 0)}},
+			{Title: "Goroutine 7 (running) created at", Frames: []SanFrame{fr("main.main", "/proj/main.go", 15, 0)}},
+		}}}},
+		{"asan", sanAsanOut, []*SanReport{{Kind: "AddressSanitizer: heap-use-after-free", Sections: []SanSection{
+			{Title: "READ of size 4 at 0x602000000010 thread T0", Frames: []SanFrame{fr("main", "/proj/uaf.c", 7, 10)}},
+			{Title: "0x602000000010 is located 0 bytes inside of 4-byte region [0x602000000010,0x602000000014)"},
+			{Title: "freed by thread T0 here", Frames: []SanFrame{fr("main", "/proj/uaf.c", 6, 3)}},
+		}}}},
+		{"two", "ok\n" + sanRaceOut + "between\n" + sanRaceOut, nil},
+		{"none", "PASS\nok  \texample.com/p\t0.1s\n", nil},
+	}
+	for _, tst := range tests {
+		reps := ParseSanReports([]byte(tst.out))
+		if tst.name == "two" {
+			if len(reps) != 2 || !reflect.DeepEqual(reps[0], reps[1]) || len(reps[0].Sections) != 3 {
+				t.Errorf("ParseSanReports error: two: should have two same reports, was: %+v\n", reps)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(reps, tst.reps) {
+			t.Errorf("ParseSanReports error: %v: should have been: %+v\n  was: %+v\n", tst.name, tst.reps, reps)
+		}
+		if IsSanOutput([]byte(tst.out)) != (len(tst.reps) > 0) {
+			t.Errorf("IsSanOutput error: %v: should have been: %v\n", tst.name, len(tst.reps) > 0)
+		}
+	}
+}
+
+func TestSanProblems(t *testing.T) {
+	reps := ParseSanReports([]byte(sanRaceOut + sanAsanOut))
+	probs := SanProblems(reps, "/proj", "Test Race")
+	want := []Problem{
+		{Path: "/proj/main.go", Line: 10, Severity: ProblemError, Source: "Test Race", Msg: "DATA RACE: Write at 0x00c0000a0018 by goroutine 7; Previous read at 0x00c0000a0018 by goroutine 6; Goroutine 7 (running) created at"},
+		{Path: "/proj/uaf.c", Line: 7, Col: 10, Severity: ProblemError, Source: "Test Race", Msg: "AddressSanitizer: heap-use-after-free: READ of size 4 at 0x602000000010 thread T0; 0x602000000010 is located 0 bytes inside of 4-byte region [0x602000000010,0x602000000014); freed by thread T0 here"},
+	}
+	if !reflect.DeepEqual(probs, want) {
+		t.Errorf("SanProblems error: should have been: %+v\n  was: %+v\n", want, probs)
+	}
+	// the first frame in the project, not the first one
+	sr := &SanReport{Kind: "DATA RACE", Sections: []SanSection{{Title: "Read", Frames: []SanFrame{
+		{Func: "runtime.x", Pos: FilePos{Path: "/go/src/runtime/x.go", Line: 1}},
+		{Func: "main.f", Pos: FilePos{Path: "/proj/f.go", Line: 2}},
+	}}}}
+	if fr, ok := sr.TopFrame("/proj"); !ok || fr.Func != "main.f" {
+		t.Errorf("TopFrame error: should have been main.f, was: %+v\n", fr)
+	}
+	if fr, ok := sr.TopFrame("/other"); !ok || fr.Func != "runtime.x" {
+		t.Errorf("TopFrame error: should have been runtime.x, was: %+v\n", fr)
+	}
+	if _, ok := (&SanReport{Kind: "DATA RACE"}).TopFrame("/proj"); ok {
+		t.Errorf("TopFrame error: a report with no frames should have no top frame\n")
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/filecat"
+)
+
+// SavedSearch is a named find configuration that can be re-run with one
+// click, e.g., for recurring audits like finding TODO(myname) comments
+type SavedSearch struct {
+	Name       string              `width:"20" desc:"name of the search"`
+	Find       string              `width:"30" desc:"find string"`
+	IgnoreCase bool                `desc:"ignore case"`
+	Regexp     bool                `desc:"use regexp regular expression search"`
+	Loc        FindLoc             `desc:"locations to search in"`
+	Langs      []filecat.Supported `desc:"languages for files to search"`
+	Include    string              `desc:"file name globs to restrict search to, separated by spaces or commas"`
+	Exclude    string              `desc:"file name globs to exclude from search, separated by spaces or commas"`
+}
+
+// NewSavedSearch returns a SavedSearch with given name for the current
+// find string and options in given params
+func NewSavedSearch(name string, fp *FindParams) SavedSearch {
+	return SavedSearch{Name: name, Find: fp.Find, IgnoreCase: fp.IgnoreCase, Regexp: fp.Regexp, Loc: fp.Loc, Langs: fp.Langs, Include: fp.Include, Exclude: fp.Exclude}
+}
+
+// SetParams sets the find string and options in given params from the search
+func (ss *SavedSearch) SetParams(fp *FindParams) {
+	fp.Find = ss.Find
+	fp.IgnoreCase = ss.IgnoreCase
+	fp.Regexp = ss.Regexp
+	fp.Loc = ss.Loc
+	fp.Langs = ss.Langs
+	fp.Include = ss.Include
+	fp.Exclude = ss.Exclude
+}
+
+// Run runs the search in given project, showing the results in the Find tab
+func (ss *SavedSearch) Run(ge Gide) {
+	fp := &ge.ProjPrefs().Find
+	ss.SetParams(fp)
+	ge.Find(ss.Find, "", ss.IgnoreCase, ss.Regexp, ss.Loc, ss.Langs)
+}
+
+// SaveSearchPrompt prompts for a name, and saves the current find string
+// and options in given project under that name, replacing any existing
+// search of the same name, then shows the SavedSearches panel
+func SaveSearchPrompt(ge Gide) {
+	fp := &ge.ProjPrefs().Find
+	if fp.Find == "" {
+		ge.SetStatus("no current find to save")
+		return
+	}
+	gi.StringPromptDialog(ge.VPort(), fp.Find, "Name of search..",
+		gi.DlgOpts{Title: "Save Search", Prompt: "Name for saving the current find string and options, to re-run them from the Saved Searches panel"},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			dlg := send.(*gi.Dialog)
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			nm := gi.StringPromptDialogValue(dlg)
+			if nm == "" {
+				return
+			}
+			pf := ge.ProjPrefs()
+			ss := NewSavedSearch(nm, &pf.Find)
+			replaced := false
+			for i := range pf.Find.Saved {
+				if pf.Find.Saved[i].Name == nm {
+					pf.Find.Saved[i] = ss
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				pf.Find.Saved = append(pf.Find.Saved, ss)
+			}
+			pf.Changed = true
+			ge.SavedSearches()
+		})
+}
+
+// SavedSearchView is a panel listing the saved searches of a project:
+// double-clicking on one (or Run) re-runs it, and the searches can be
+// edited in place
+type SavedSearchView struct {
+	gi.Layout
+	Gide Gide `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+}
+
+var KiT_SavedSearchView = kit.Types.AddType(&SavedSearchView{}, SavedSearchViewProps)
+
+// Config configures the view
+func (sv *SavedSearchView) Config(ge Gide) {
+	sv.Gide = ge
+	sv.Lay = gi.LayoutVert
+	sv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "toolbar")
+	config.Add(giv.KiT_TableView, "saved")
+	mods, updt := sv.ConfigChildren(config)
+	tv := sv.TableView()
+	if mods {
+		tv.SliceViewSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(giv.SliceViewDoubleClicked) {
+				svv, _ := recv.Embed(KiT_SavedSearchView).(*SavedSearchView)
+				svv.Run(data.(int))
+			}
+		})
+		tv.ViewSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SavedSearchView).(*SavedSearchView)
+			svv.Gide.ProjPrefs().Changed = true
+		})
+	} else {
+		updt = sv.UpdateStart()
+	}
+	sv.ConfigToolbar()
+	tv.SetStretchMax()
+	tv.SetSlice(&ge.ProjPrefs().Find.Saved)
+	sv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (sv *SavedSearchView) ToolBar() *gi.ToolBar {
+	return sv.ChildByName("toolbar", 0).(*gi.ToolBar)
+}
+
+// TableView returns the tableview of saved searches
+func (sv *SavedSearchView) TableView() *giv.TableView {
+	return sv.ChildByName("saved", 1).(*giv.TableView)
+}
+
+// Run runs the saved search at given index
+func (sv *SavedSearchView) Run(idx int) {
+	saved := sv.Gide.ProjPrefs().Find.Saved
+	if idx < 0 || idx >= len(saved) {
+		return
+	}
+	saved[idx].Run(sv.Gide)
+}
+
+// DeleteSearch deletes the saved search at given index
+func (sv *SavedSearchView) DeleteSearch(idx int) {
+	pf := sv.Gide.ProjPrefs()
+	if idx < 0 || idx >= len(pf.Find.Saved) {
+		return
+	}
+	pf.Find.Saved = append(pf.Find.Saved[:idx], pf.Find.Saved[idx+1:]...)
+	pf.Changed = true
+	sv.Config(sv.Gide)
+}
+
+// ConfigToolbar adds toolbar.
+func (sv *SavedSearchView) ConfigToolbar() {
+	tb := sv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Run", Icon: "search", Tooltip: "run the selected search (or double-click on it)"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SavedSearchView).(*SavedSearchView)
+			svv.Run(svv.TableView().SelectedIdx)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Save Current...", Icon: "file-save", Tooltip: "save the current find string and options as a new named search"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SavedSearchView).(*SavedSearchView)
+			SaveSearchPrompt(svv.Gide)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Delete", Icon: "minus", Tooltip: "delete the selected search"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SavedSearchView).(*SavedSearchView)
+			svv.DeleteSearch(svv.TableView().SelectedIdx)
+		})
+}
+
+// SavedSearchViewProps are style properties for SavedSearchView
+var SavedSearchViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
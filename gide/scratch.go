@@ -0,0 +1,441 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+// ScratchTabName is the name of the tab of the Go scratchpad
+var ScratchTabName = "Go Scratch"
+
+// ScratchTimeout is the time a run of the scratchpad is given before it is
+// killed
+var ScratchTimeout = 30 * time.Second
+
+// ScratchResultMax is the maximum number of characters of a value shown at
+// the end of a line of the scratchpad
+var ScratchResultMax = 120
+
+// ScratchResultMark starts the values shown at the end of the lines of the
+// scratchpad -- the end of a line after it is removed before each run
+var ScratchResultMark = " // => "
+
+// ScratchNoShow are the starts of the expressions whose values are not
+// shown in the scratchpad, e.g., printing
+var ScratchNoShow = []string{"fmt.Print", "fmt.Fprint", "log.", "print(", "println(", "panic("}
+
+// ScratchDefault is the text of a new scratchpad
+var ScratchDefault = `// Go scratchpad: the statements are run in order, as in main, showing the
+// value of each expression and assignment at the end of its line -- the
+// imports, funcs and types starting a line are declared at the top level
+import "strings"
+
+s := strings.ToUpper("hello, world")
+strings.Fields(s)
+len(s)
+`
+
+// scratchHelper is the function showing the values of the scratchpad,
+// writing them to the results file, by line
+var scratchHelper = `
+//line gide-scratch.go:1
+func __show(ln int) func(...interface{}) {
+	return func(vs ...interface{}) {
+		var b []byte
+		for i, v := range vs {
+			if i > 0 {
+				b = append(b, ", "...)
+			}
+			if s, ok := v.(string); ok {
+				b = append(b, __fmt.Sprintf("%q", s)...)
+			} else {
+				b = append(b, __fmt.Sprintf("%v", v)...)
+			}
+		}
+		for i := range b {
+			if b[i] == '\n' || b[i] == '\r' {
+				b[i] = ' '
+			}
+		}
+		f, err := __os.OpenFile({results}, __os.O_APPEND|__os.O_CREATE|__os.O_WRONLY, 0644)
+		if err != nil {
+			return
+		}
+		__fmt.Fprintf(f, "%d\t%s\n", ln, b)
+		f.Close()
+	}
+}
+`
+
+// scratchErrRe matches the errors of the scratchpad code, with its line
+var scratchErrRe = regexp.MustCompile(`(?m)^(?:.*[/\\])?scratch\.go:(\d+)(?::\d+)?: (.*)$`)
+
+// scratchNoValueRe matches the errors of showing expressions of no value,
+// of go build and yaegi
+var scratchNoValueRe = regexp.MustCompile(`used as value|as type \(\.\.\.interface ?\{\}\)`)
+
+// StripScratchResults returns given line of the scratchpad without the
+// values shown at its end
+func StripScratchResults(ln string) string {
+	if i := strings.Index(ln, ScratchResultMark); i >= 0 {
+		return ln[:i]
+	}
+	return ln
+}
+
+// StripScratchCode returns given scratchpad code without the values shown
+// at the end of its lines
+func StripScratchCode(code string) string {
+	lns := strings.Split(code, "\n")
+	for i, ln := range lns {
+		lns[i] = StripScratchResults(ln)
+	}
+	return strings.Join(lns, "\n")
+}
+
+// IsScratchTopLevel returns true if given line of the scratchpad starts a
+// top-level declaration: an import, func or type
+func IsScratchTopLevel(ln string) bool {
+	for _, kw := range []string{"import", "func", "type"} {
+		if strings.HasPrefix(ln, kw+" ") || strings.HasPrefix(ln, kw+"\t") || strings.HasPrefix(ln, kw+"(") && kw == "import" {
+			return true
+		}
+	}
+	return false
+}
+
+// ScratchProgram returns the program running given scratchpad code, which
+// is a whole program if it starts with a package clause: otherwise its
+// top-level declarations (IsScratchTopLevel, up to the } or ) closing them
+// at the start of a line) are followed by a main func with the other lines,
+// each expression and assignment showing its value, but those of the lines
+// of skip, in the results file.  Line directives keep the lines of the code
+// in the errors.  Also returns the import paths.
+func ScratchProgram(code, results string, skip map[int]bool) (string, []string) {
+	fset := token.NewFileSet()
+	if f, err := parser.ParseFile(fset, "scratch.go", code, parser.PackageClauseOnly); err == nil && f.Name != nil {
+		return code, scratchImports(code)
+	}
+	var top, body strings.Builder
+	tnext, bnext := -1, -1
+	closer := ""
+	for i, ln := range strings.Split(code, "\n") {
+		sb, next := &body, &bnext
+		if closer != "" || IsScratchTopLevel(ln) {
+			sb, next = &top, &tnext
+			if closer != "" {
+				if strings.HasPrefix(ln, closer) {
+					closer = ""
+				}
+			} else if tl := strings.TrimSpace(ln); strings.HasSuffix(tl, "{") {
+				closer = "}"
+			} else if strings.HasSuffix(tl, "(") {
+				closer = ")"
+			}
+		}
+		if *next != i {
+			fmt.Fprintf(sb, "//line scratch.go:%d\n", i+1)
+		}
+		sb.WriteString(ln + "\n")
+		*next = i + 1
+	}
+	prog := "package main\n\nimport __fmt \"fmt\"\nimport __os \"os\"\n\n" + top.String() + "\n//line gide-scratch.go:1\nfunc main() {\n" + body.String() + "//line gide-scratch.go:1\n}\n" + strings.Replace(scratchHelper, "{results}", strconv.Quote(results), 1)
+	return ScratchShowValues(prog, skip), scratchImports(prog)
+}
+
+// scratchImports returns the import paths of given program, none if it
+// does not parse
+func scratchImports(prog string) []string {
+	f, err := parser.ParseFile(token.NewFileSet(), "scratch.go", prog, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+	var ims []string
+	for _, im := range f.Imports {
+		if p, err := strconv.Unquote(im.Path.Value); err == nil {
+			ims = append(ims, p)
+		}
+	}
+	return ims
+}
+
+// ScratchShowValues returns given scratchpad program with the values of the
+// expressions and assignments of its main func shown, but those of the
+// lines of skip -- unchanged if it does not parse
+func ScratchShowValues(prog string, skip map[int]bool) string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "scratch.go", prog, 0)
+	if err != nil {
+		return prog
+	}
+	type insert struct {
+		off int
+		txt string
+	}
+	var ins []insert
+	off := func(p token.Pos) int { return fset.File(p).Offset(p) }
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Recv != nil || fd.Name.Name != "main" || fd.Body == nil {
+			continue
+		}
+		for _, st := range fd.Body.List {
+			ps := fset.Position(st.Pos())
+			if ps.Filename != "scratch.go" || skip[ps.Line] {
+				continue
+			}
+			show := fmt.Sprintf("__show(%d)(", ps.Line)
+			var names []string
+			switch st := st.(type) {
+			case *ast.ExprStmt:
+				src := prog[off(st.Pos()):off(st.End())]
+				noshow := false
+				for _, ns := range ScratchNoShow {
+					if strings.HasPrefix(src, ns) {
+						noshow = true
+					}
+				}
+				if !noshow {
+					ins = append(ins, insert{off(st.Pos()), show}, insert{off(st.End()), ")"})
+				}
+			case *ast.AssignStmt:
+				names = scratchNames(st.Lhs)
+			case *ast.IncDecStmt:
+				names = scratchNames([]ast.Expr{st.X})
+			case *ast.DeclStmt:
+				if gd, ok := st.Decl.(*ast.GenDecl); ok && gd.Tok == token.VAR {
+					var vars []ast.Expr
+					for _, sp := range gd.Specs {
+						for _, nm := range sp.(*ast.ValueSpec).Names {
+							vars = append(vars, nm)
+						}
+					}
+					names = scratchNames(vars)
+				}
+			}
+			if len(names) > 0 {
+				ins = append(ins, insert{off(st.End()), "; " + show + strings.Join(names, ", ") + ")"})
+			}
+		}
+	}
+	sort.SliceStable(ins, func(i, j int) bool { return ins[i].off > ins[j].off })
+	for _, in := range ins {
+		prog = prog[:in.off] + in.txt + prog[in.off:]
+	}
+	return prog
+}
+
+// scratchNames returns the names of the identifiers of given expressions,
+// but _
+func scratchNames(exs []ast.Expr) []string {
+	var nms []string
+	for _, ex := range exs {
+		if id, ok := ex.(*ast.Ident); ok && id.Name != "_" {
+			nms = append(nms, id.Name)
+		}
+	}
+	return nms
+}
+
+// ScratchResults are the results of a run of the scratchpad
+type ScratchResults struct {
+	Values map[int][]string `desc:"values shown by the lines of the code, by line (0-based)"`
+	Errors map[int][]string `desc:"errors of the lines of the code, by line (0-based)"`
+	Output string           `desc:"output of the build, and of the program"`
+	Interp string           `desc:"how the code was run: yaegi (in process), or go build"`
+	Err    error            `desc:"error running the code (other than of the code itself), or its exit status"`
+}
+
+// ScratchInProcess returns true if code of given imports can be run in
+// process by the yaegi interpreter embedded in gide, which only has the
+// symbols of the standard library
+func ScratchInProcess(imports []string) bool {
+	for _, im := range imports {
+		if _, has := stdlib.Symbols[im+"/"+path.Base(im)]; !has {
+			return false
+		}
+	}
+	return true
+}
+
+// ScratchEval runs given scratchpad code: in process by the embedded yaegi
+// interpreter if it only imports the standard library (ScratchInProcess),
+// else from a temporary build in given directory (e.g., the project root,
+// for its module), with given environment -- stops after ScratchTimeout,
+// or if cancel is closed
+func ScratchEval(code, dir string, env []string, cancel <-chan struct{}) *ScratchResults {
+	res := &ScratchResults{Values: make(map[int][]string), Errors: make(map[int][]string)}
+	tdir, err := ioutil.TempDir("", "gide-scratch")
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer os.RemoveAll(tdir)
+	ctx, cfn := context.WithTimeout(context.Background(), ScratchTimeout)
+	defer cfn()
+	go func() {
+		select {
+		case <-cancel:
+			cfn()
+		case <-ctx.Done():
+		}
+	}()
+	fname := filepath.Join(tdir, "scratch.go")
+	results := filepath.Join(tdir, "results")
+	skip := make(map[int]bool)
+	for try := 0; ; try++ {
+		prog, ims := ScratchProgram(code, results, skip)
+		if err := ioutil.WriteFile(fname, []byte(prog), 0644); err != nil {
+			res.Err = err
+			return res
+		}
+		os.Remove(results)
+		var out []byte
+		if ScratchInProcess(ims) {
+			res.Interp = "yaegi"
+			out, err = scratchInterp(ctx, prog)
+		} else {
+			res.Interp = "go build"
+			exe := filepath.Join(tdir, "scratch")
+			out, err = scratchCmd(ctx, dir, env, "go", "build", "-gcflags=-e", "-o", exe, fname)
+			if err == nil {
+				var rout []byte
+				rout, err = scratchCmd(ctx, dir, env, exe)
+				out = append(out, rout...)
+			}
+		}
+		// expressions of no value can only be known by the errors they give
+		noval := false
+		for _, m := range scratchErrRe.FindAllStringSubmatch(string(out), -1) {
+			ln, _ := strconv.Atoi(m[1])
+			if scratchNoValueRe.MatchString(m[2]) && !skip[ln] {
+				skip[ln] = true
+				noval = true
+			}
+		}
+		if noval && try < 5 && ctx.Err() == nil {
+			continue
+		}
+		res.Output = strings.TrimPrefix(string(out), "# command-line-arguments\n")
+		for _, m := range scratchErrRe.FindAllStringSubmatch(res.Output, -1) {
+			ln, _ := strconv.Atoi(m[1])
+			res.Errors[ln-1] = append(res.Errors[ln-1], m[2])
+		}
+		break
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		res.Err = fmt.Errorf("stopped after %v", ScratchTimeout)
+	} else if ctx.Err() != nil {
+		res.Err = fmt.Errorf("stopped")
+	} else if err != nil {
+		res.Err = err
+	}
+	if b, err := ioutil.ReadFile(results); err == nil {
+		for _, rl := range strings.Split(string(b), "\n") {
+			fs := strings.SplitN(rl, "\t", 2)
+			ln, err := strconv.Atoi(fs[0])
+			if len(fs) != 2 || err != nil {
+				continue
+			}
+			val := []rune(fs[1])
+			if len(val) > ScratchResultMax {
+				val = append(val[:ScratchResultMax], '…')
+			}
+			res.Values[ln-1] = append(res.Values[ln-1], string(val))
+		}
+	}
+	return res
+}
+
+// scratchInterp runs given scratchpad program in process, in a new yaegi
+// interpreter, returning its output, followed by its error if any: the
+// standard output and error of the program (os.Stdout etc, and fmt, log)
+// are those of the interpreter, and os.Exit returns an error instead of
+// exiting.  On cancel, the interpreter is stopped at the next statement it
+// runs, without waiting for calls to the standard library to return.
+func scratchInterp(ctx context.Context, prog string) ([]byte, error) {
+	rd, wr, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&out, rd)
+		rd.Close()
+		close(done)
+	}()
+	in, err := os.Open(os.DevNull)
+	if err != nil {
+		wr.Close()
+		<-done
+		return nil, err
+	}
+	defer in.Close()
+	it := interp.New(interp.Options{Stdin: in, Stdout: wr, Stderr: wr})
+	it.Use(stdlib.Symbols)
+	_, err = it.EvalWithContext(ctx, prog)
+	wr.Close()
+	<-done
+	if err != nil && ctx.Err() == nil {
+		fmt.Fprintf(&out, "%v\n", err)
+	}
+	return out.Bytes(), err
+}
+
+// scratchCmd runs given command in given directory and environment,
+// returning its combined output
+func scratchCmd(ctx context.Context, dir string, env []string, cmd string, args ...string) ([]byte, error) {
+	cm := exec.CommandContext(ctx, cmd, args...)
+	cm.Dir = dir
+	cm.Env = env
+	return cm.CombinedOutput()
+}
+
+// LineResults returns the results shown at the end of given line (0-based)
+// of the code, "" if none: its values, or errors
+func (sr *ScratchResults) LineResults(ln int) string {
+	var rs []string
+	for _, er := range sr.Errors[ln] {
+		rs = append(rs, "error: "+er)
+	}
+	rs = append(rs, sr.Values[ln]...)
+	return strings.Join(rs, "; ")
+}
+
+// Lines returns the lines (0-based) of the code with results, in order
+func (sr *ScratchResults) Lines() []int {
+	var lns []int
+	for ln := range sr.Values {
+		lns = append(lns, ln)
+	}
+	for ln := range sr.Errors {
+		if _, has := sr.Values[ln]; !has {
+			lns = append(lns, ln)
+		}
+	}
+	sort.Ints(lns)
+	return lns
+}
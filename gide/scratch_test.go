@@ -0,0 +1,151 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStripScratchCode(t *testing.T) {
+	code := "x := 1" + ScratchResultMark + "1\ny := \"a // b\"\nx" + ScratchResultMark + "1; 2\n"
+	if sc := StripScratchCode(code); sc != "x := 1\ny := \"a // b\"\nx\n" {
+		t.Errorf("StripScratchCode error: was: %q\n", sc)
+	}
+}
+
+func TestIsScratchTopLevel(t *testing.T) {
+	tests := []struct {
+		ln  string
+		top bool
+	}{
+		{`import "strings"`, true},
+		{`import (`, true},
+		{"func f() int {", true},
+		{"type T struct {", true},
+		{"funcs := 1", false},
+		{"  func() {}()", false},
+		{"typ := 2", false},
+	}
+	for _, tst := range tests {
+		if top := IsScratchTopLevel(tst.ln); top != tst.top {
+			t.Errorf("IsScratchTopLevel error: %q: should have been: %v  was: %v\n", tst.ln, tst.top, top)
+		}
+	}
+}
+
+func TestScratchProgram(t *testing.T) {
+	code := "import \"strings\"\n\ns := strings.ToUpper(\"a\")\nfunc f(x int) int {\n\treturn x\n}\nf(2)\nfmt.Println(s)\nvar a, _ = 1, 2\na++\n"
+	prog, ims := ScratchProgram(code, "/tmp/res", map[int]bool{7: true})
+	if want := []string{"fmt", "os", "strings"}; !reflect.DeepEqual(ims, want) {
+		t.Errorf("ScratchProgram error: imports should have been: %v  was: %v\n", want, ims)
+	}
+	for _, want := range []string{
+		"//line scratch.go:1\nimport \"strings\"\n",
+		"//line scratch.go:4\nfunc f(x int) int {\n\treturn x\n}\n",
+		"func main() {\n//line scratch.go:2\n\ns := strings.ToUpper(\"a\"); __show(3)(s)\n",
+		"//line scratch.go:7\nf(2)\nfmt.Println(s)\n",
+		"var a, _ = 1, 2; __show(9)(a)\na++; __show(10)(a)\n",
+		`__os.OpenFile("/tmp/res", `,
+	} {
+		if !strings.Contains(prog, want) {
+			t.Errorf("ScratchProgram error: program should have: %q  was:\n%v\n", want, prog)
+		}
+	}
+	whole := "package main\n\nfunc main() {}\n"
+	if prog, _ := ScratchProgram(whole, "/tmp/res", nil); prog != whole {
+		t.Errorf("ScratchProgram error: a whole program should be run as it is, was:\n%v\n", prog)
+	}
+}
+
+func TestScratchInProcess(t *testing.T) {
+	tests := []struct {
+		ims []string
+		in  bool
+	}{
+		{nil, true},
+		{[]string{"fmt", "os", "strings", "encoding/json", "net/http"}, true},
+		{[]string{"fmt", "github.com/goki/gi/gi"}, false},
+		{[]string{"example.com/m/p"}, false},
+		{[]string{"nosuchstd"}, false},
+	}
+	for _, tst := range tests {
+		if in := ScratchInProcess(tst.ims); in != tst.in {
+			t.Errorf("ScratchInProcess error: %v: should have been: %v  was: %v\n", tst.ims, tst.in, in)
+		}
+	}
+}
+
+func TestScratchEval(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		vals   map[int][]string
+		errs   int
+		output string
+	}{
+		{"values", "import \"strings\"\ns := strings.ToUpper(\"hi\")\nlen(s)\nfunc f() {}\nf()\nvar n int\nn++\n", map[int][]string{1: {`"HI"`}, 2: {"2"}, 5: {"0"}, 6: {"1"}}, 0, ""},
+		{"output", "import \"fmt\"\nimport \"os\"\nfmt.Println(\"to fmt\")\nos.Stdout.WriteString(\"to os\\n\")\n", map[int][]string{3: {"6, <nil>"}}, 0, "to fmt\nto os\n"},
+		{"error", "x := undefinedy\n", map[int][]string{}, 1, "undefinedy"},
+		{"exit", "import \"os\"\nos.Exit(3)\n", map[int][]string{}, 1, "os.Exit(3)"},
+	}
+	for _, tst := range tests {
+		res := ScratchEval(tst.code, "", nil, nil)
+		if res.Interp != "yaegi" {
+			t.Errorf("ScratchEval error: %v: should have been run in process, was: %v\n", tst.name, res.Interp)
+		}
+		if !reflect.DeepEqual(res.Values, tst.vals) {
+			t.Errorf("ScratchEval error: %v: values should have been: %v  was: %v (output %q)\n", tst.name, tst.vals, res.Values, res.Output)
+		}
+		if len(res.Errors) != tst.errs {
+			t.Errorf("ScratchEval error: %v: should have %d errors, was: %v\n", tst.name, tst.errs, res.Errors)
+		}
+		if !strings.Contains(res.Output, tst.output) {
+			t.Errorf("ScratchEval error: %v: output should have: %q  was: %q\n", tst.name, tst.output, res.Output)
+		}
+	}
+}
+
+func TestScratchEvalCancel(t *testing.T) {
+	cancel := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() { close(cancel) })
+	st := time.Now()
+	res := ScratchEval("for {\n}\n", "", nil, cancel)
+	if res.Err == nil || time.Since(st) > 10*time.Second {
+		t.Errorf("ScratchEval error: should have been stopped, was: %v after %v\n", res.Err, time.Since(st))
+	}
+}
+
+func TestScratchEvalBuild(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gide-scratch-mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"go.mod": "module example.com/m\n",
+		"p/p.go": "package p\n\nfunc Twice(x int) int { return 2 * x }\n",
+	}
+	for fn, src := range files {
+		fpath := filepath.Join(dir, filepath.FromSlash(fn))
+		os.MkdirAll(filepath.Dir(fpath), 0755)
+		if err := ioutil.WriteFile(fpath, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	res := ScratchEval("import \"example.com/m/p\"\np.Twice(21)\nfunc f() {}\nf()\n", dir, env, nil)
+	if res.Interp != "go build" || res.Err != nil {
+		t.Fatalf("ScratchEval error: should have been built, was: %v %v\n%v\n", res.Interp, res.Err, res.Output)
+	}
+	if want := map[int][]string{1: {"42"}}; !reflect.DeepEqual(res.Values, want) {
+		t.Errorf("ScratchEval error: values should have been: %v  was: %v\n", want, res.Values)
+	}
+}
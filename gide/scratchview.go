@@ -0,0 +1,278 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+)
+
+// ScratchView is the Go scratchpad: Go snippets run with Control+Enter (see
+// ScratchEval), with the value of each expression and assignment shown at
+// the end of its line, and the output below -- its text is kept in the
+// project, no file is created
+type ScratchView struct {
+	gi.Layout
+	Gide Gide `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Job  *Job `json:"-" xml:"-" view:"-" desc:"the job running the code, if running"`
+}
+
+var KiT_ScratchView = kit.Types.AddType(&ScratchView{}, ScratchViewProps)
+
+// Config configures the view
+func (sv *ScratchView) Config(ge Gide) {
+	sv.Gide = ge
+	sv.Lay = gi.LayoutVert
+	sv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "scratchbar")
+	config.Add(gi.KiT_SplitView, "scratchsplit")
+	mods, updt := sv.ConfigChildren(config)
+	if !mods {
+		updt = sv.UpdateStart()
+	}
+	sv.ConfigToolbar()
+	sv.ConfigSplitView()
+	sv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (sv *ScratchView) ToolBar() *gi.ToolBar {
+	return sv.ChildByName("scratchbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the code and the output
+func (sv *ScratchView) SplitView() *gi.SplitView {
+	return sv.ChildByName("scratchsplit", 1).(*gi.SplitView)
+}
+
+// CodeView returns the text of the code
+func (sv *ScratchView) CodeView() *ScratchText {
+	return sv.SplitView().ChildByName("code", 0).Embed(KiT_ScratchText).(*ScratchText)
+}
+
+// OutView returns the TextView of the output
+func (sv *ScratchView) OutView() *giv.TextView {
+	ly := sv.SplitView().ChildByName("out", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// Code returns the code, without the values shown
+func (sv *ScratchView) Code() string {
+	return StripScratchCode(string(sv.CodeView().Buf.Text()))
+}
+
+// SaveCode keeps the text of the code in the project
+func (sv *ScratchView) SaveCode() {
+	sv.Gide.ProjPrefs().Scratch = string(sv.CodeView().Buf.Text())
+}
+
+// IsRunning returns true if the code is running
+func (sv *ScratchView) IsRunning() bool {
+	if sv.Job == nil {
+		return false
+	}
+	select {
+	case <-sv.Job.Done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Run runs the code as a background job, showing its values and output
+// when done -- the values shown before are cleared
+func (sv *ScratchView) Run() {
+	if sv.IsRunning() {
+		sv.Gide.SetStatus("the scratchpad is running -- stop it first")
+		return
+	}
+	sv.ClearValues()
+	sv.SaveCode()
+	code := sv.Code()
+	pf := sv.Gide.ProjPrefs()
+	dir, env := string(pf.ProjRoot), pf.CmdEnv()
+	sv.Event("running...")
+	st := time.Now()
+	sv.Job = sv.Gide.Jobs().Run(ScratchTabName, JobHigh, func(jb *Job) {
+		res := ScratchEval(code, dir, env, jb.Cancel)
+		sv.ShowResults(code, res, time.Since(st))
+	})
+}
+
+// Stop stops the code running, if any
+func (sv *ScratchView) Stop() {
+	if sv.IsRunning() {
+		sv.Gide.Jobs().Cancel(ScratchTabName)
+	}
+}
+
+// ShowResults shows given results of a run of given code: its values and
+// errors at the end of the lines, unless the code was edited since, and
+// its output
+func (sv *ScratchView) ShowResults(code string, res *ScratchResults, dur time.Duration) {
+	if sv.IsDestroyed() {
+		return
+	}
+	ge := sv.Gide
+	wupdt := ge.VPort().TopUpdateStart()
+	defer ge.VPort().TopUpdateEnd(wupdt)
+	buf := sv.OutView().Buf
+	buf.SetText([]byte(res.Output))
+	msg := fmt.Sprintf("%v: done in %v", res.Interp, dur.Round(time.Millisecond))
+	if res.Err != nil {
+		msg = fmt.Sprintf("%v: %v, in %v", res.Interp, res.Err, dur.Round(time.Millisecond))
+	}
+	if sv.Code() != code {
+		msg += " -- the code was edited: values not shown"
+	} else {
+		cb := sv.CodeView().Buf
+		for _, ln := range res.Lines() {
+			if ln < cb.NumLines() {
+				cb.InsertText(lex.Pos{Ln: ln, Ch: cb.LineLen(ln)}, []byte(ScratchResultMark+res.LineResults(ln)), giv.EditSignal)
+			}
+		}
+	}
+	sv.Event(msg)
+}
+
+// ClearValues clears the values shown at the end of the lines of the code
+func (sv *ScratchView) ClearValues() {
+	cb := sv.CodeView().Buf
+	for ln := cb.NumLines() - 1; ln >= 0; ln-- {
+		txt := string(cb.Line(ln))
+		if i := strings.Index(txt, ScratchResultMark); i >= 0 {
+			cb.DeleteText(lex.Pos{Ln: ln, Ch: utf8.RuneCountInString(txt[:i])}, lex.Pos{Ln: ln, Ch: cb.LineLen(ln)}, giv.EditSignal)
+		}
+	}
+}
+
+// Event appends given event of a run, in italics, to the output
+func (sv *ScratchView) Event(msg string) {
+	ln := "[" + msg + "]"
+	buf := sv.OutView().Buf
+	buf.AppendTextLineMarkup([]byte(ln), []byte("<i>"+html.EscapeString(ln)+"</i>"), giv.EditSignal)
+	buf.AutoScrollViews()
+	sv.Gide.SetStatus(ScratchTabName + ": " + msg)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (sv *ScratchView) ConfigToolbar() {
+	tb := sv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Run", Icon: "play", Tooltip: "run the code, showing the value of each expression and assignment at the end of its line (also Control+Enter) -- in process by the embedded yaegi interpreter if only the standard library is imported, else built with go in the project, so that its packages can be imported"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+			svv.Run()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop the code running"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+			svv.Stop()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear Values", Icon: "close", Tooltip: "clear the values shown at the end of the lines"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+			svv.ClearValues()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear Output", Icon: "close", Tooltip: "clear the output"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+			svv.OutView().Buf.New(0)
+		})
+}
+
+// ConfigSplitView configures the split view of the code and the output
+func (sv *ScratchView) ConfigSplitView() {
+	split := sv.SplitView()
+	split.Dim = mat32.Y
+	split.SetStretchMaxWidth()
+	split.SetStretchMaxHeight()
+	if len(split.Kids) > 0 {
+		return
+	}
+	ct := split.AddNewChild(KiT_ScratchText, "code").(*ScratchText)
+	ct.View = sv
+	ct.SetProp("font-family", gi.Prefs.MonoFont)
+	ct.SetStretchMaxWidth()
+	ct.SetStretchMaxHeight()
+	buf := giv.NewTextBuf()
+	buf.Opts.LineNos = true
+	buf.Info.Sup = filecat.Go
+	buf.New(1) // highlighting of Go
+	code := sv.Gide.ProjPrefs().Scratch
+	if code == "" {
+		code = ScratchDefault
+	}
+	buf.SetText([]byte(code))
+	buf.TextBufSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.TextBufInsert) || sig == int64(giv.TextBufDelete) {
+			svv, _ := recv.Embed(KiT_ScratchView).(*ScratchView)
+			svv.SaveCode()
+		}
+	})
+	ct.SetBuf(buf)
+	ly := gi.AddNewLayout(split, "out", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.7, .3)
+}
+
+// Destroy stops the code running when the tab is closed
+func (sv *ScratchView) Destroy() {
+	sv.Stop()
+	sv.Layout.Destroy()
+}
+
+// ScratchViewProps are style properties for ScratchView
+var ScratchViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  ScratchText
+
+// ScratchText is the code of the Go scratchpad: Control+Enter runs it
+type ScratchText struct {
+	giv.TextView
+	View *ScratchView `json:"-" xml:"-" view:"-" desc:"the scratchpad"`
+}
+
+var KiT_ScratchText = kit.Types.AddType(&ScratchText{}, giv.TextViewProps)
+
+// ConnectEvents2D takes Control+Enter at high priority, before the text
+// view
+func (st *ScratchText) ConnectEvents2D() {
+	st.TextViewEvents()
+	st.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		stt := recv.Embed(KiT_ScratchText).(*ScratchText)
+		kt := d.(*key.ChordEvent)
+		if stt.View == nil || !kt.HasAnyModifier(key.Control, key.Meta) || kt.Code != key.CodeReturnEnter {
+			return
+		}
+		kt.SetProcessed()
+		stt.View.Run()
+	})
+}
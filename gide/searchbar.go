@@ -0,0 +1,355 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/pi/lex"
+)
+
+// SearchBar is an incremental search bar for the current file in a
+// TextView, or the output of a command or debug console, shown just above
+// it: all matches are highlighted as the search
+// string is typed, and the cursor moves to the first one after where the
+// search started.  Return and the arrow keys move to the next / previous
+// match, and escape closes it.  If opened with a multi-line selection, the
+// search is limited to within that selection.
+type SearchBar struct {
+	gi.ToolBar
+	View     *giv.TextView   `json:"-" xml:"-" view:"-" desc:"the text view being searched"`
+	Find     string          `desc:"current search string"`
+	Regexp   bool            `desc:"use regexp regular expression search"`
+	UseCase  bool            `desc:"pay attention to case"`
+	InSel    bool            `desc:"only search within SelReg"`
+	SelReg   textbuf.Region  `desc:"selected region that was active when the bar was opened, searched within if InSel"`
+	Matches  []textbuf.Match `json:"-" xml:"-" desc:"current matches"`
+	Pos      int             `desc:"index of current match in Matches, -1 if none"`
+	StartPos lex.Pos         `desc:"cursor position when the search started -- the first match after it is selected while typing"`
+	Err      error           `json:"-" xml:"-" desc:"error for an invalid regexp"`
+}
+
+var KiT_SearchBar = kit.Types.AddType(&SearchBar{}, SearchBarProps)
+
+// SearchBarProps are style properties for SearchBar
+var SearchBarProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"padding":          units.NewPx(2),
+	"margin":           units.NewPx(0),
+	"spacing":          units.NewPx(4),
+	"background-color": &gi.Prefs.Colors.Control,
+}
+
+// SearchBarName returns the name of the SearchBar for given TextView
+func SearchBarName(tv *giv.TextView) string {
+	return "search-" + tv.Nm
+}
+
+// SearchBarFor returns the SearchBar open for given TextView, nil if none
+func SearchBarFor(tv *giv.TextView) *SearchBar {
+	par := tv.Parent()
+	if par == nil {
+		return nil
+	}
+	sb, _ := par.ChildByName(SearchBarName(tv), 0).(*SearchBar)
+	return sb
+}
+
+// OpenSearchBar opens the SearchBar just above given TextView (which can
+// be an inactive output view), or focuses it if already open, starting
+// with given search string if non-empty
+func OpenSearchBar(tv *giv.TextView, find string) *SearchBar {
+	sb := SearchBarFor(tv)
+	if sb == nil {
+		par := tv.Parent()
+		ly := par.Embed(gi.KiT_Layout).(*gi.Layout)
+		updt := ly.UpdateStart()
+		ly.SetFullReRender()
+		idx, _ := par.Children().IndexOf(tv.This(), 0)
+		sb = par.InsertNewChild(KiT_SearchBar, idx, SearchBarName(tv)).(*SearchBar)
+		sb.View = tv
+		sb.Config()
+		ly.UpdateEnd(updt)
+	}
+	sb.StartPos = tv.CursorPos
+	if tv.HasSelection() && !tv.SelectReg.IsSameLine() {
+		sb.InSel = true
+		sb.SelReg = tv.SelectReg
+		sb.StartPos = tv.SelectReg.Start
+		find = "" // selection is the scope, not the search string
+	}
+	sb.InSelBox().SetChecked(sb.InSel)
+	sb.InSelBox().SetInactiveState(sb.SelReg.IsNil())
+	if find != "" {
+		sb.FindText().SetText(find)
+		sb.SetFind(find)
+	} else {
+		sb.SetFind(sb.Find) // update highlights for any changes
+	}
+	sb.FindText().GrabFocus()
+	return sb
+}
+
+// CloseSearchBar closes the SearchBar for given TextView, if open, clearing
+// its highlights
+func CloseSearchBar(tv *giv.TextView) {
+	sb := SearchBarFor(tv)
+	if sb == nil {
+		return
+	}
+	tv.ClearHighlights()
+	par := sb.Parent()
+	ly := par.Embed(gi.KiT_Layout).(*gi.Layout)
+	updt := ly.UpdateStart()
+	ly.SetFullReRender()
+	par.DeleteChild(sb.This(), ki.DestroyKids)
+	ly.UpdateEnd(updt)
+	tv.GrabFocus()
+}
+
+// Config configures the bar
+func (sb *SearchBar) Config() {
+	sb.Lay = gi.LayoutHoriz
+	sb.SetStretchMaxWidth()
+
+	ft := sb.AddNewChild(gi.KiT_TextField, "find").(*gi.TextField)
+	ft.Placeholder = "search in file"
+	ft.Tooltip = "search string -- matches are highlighted as you type -- return or down / up arrows go to next / previous match, escape closes"
+	ft.SetStretchMaxWidth()
+	ft.SetMinPrefWidth(units.NewCh(40))
+	ft.TextFieldSig.Connect(sb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+		tf := send.(*gi.TextField)
+		switch sig {
+		case int64(gi.TextFieldInsert), int64(gi.TextFieldBackspace), int64(gi.TextFieldDelete), int64(gi.TextFieldCleared):
+			sbb.SetFind(string(tf.EditTxt)) // not Text(), which ends the edit
+		case int64(gi.TextFieldDone):
+			sbb.Next()
+			tf.GrabFocus()
+		}
+	})
+
+	rx := sb.AddNewChild(gi.KiT_CheckBox, "regexp").(*gi.CheckBox)
+	rx.SetText("Regexp")
+	rx.Tooltip = "use regular expression for search -- see https://github.com/google/re2/wiki/Syntax"
+	rx.SetChecked(sb.Regexp)
+	rx.ButtonSig.Connect(sb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+			sbb.Regexp = send.(*gi.CheckBox).IsChecked()
+			sbb.SetFind(sbb.Find)
+		}
+	})
+
+	uc := sb.AddNewChild(gi.KiT_CheckBox, "use-case").(*gi.CheckBox)
+	uc.SetText("Case")
+	uc.Tooltip = "match case"
+	uc.SetChecked(sb.UseCase)
+	uc.ButtonSig.Connect(sb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+			sbb.UseCase = send.(*gi.CheckBox).IsChecked()
+			sbb.SetFind(sbb.Find)
+		}
+	})
+
+	is := sb.AddNewChild(gi.KiT_CheckBox, "in-sel").(*gi.CheckBox)
+	is.SetText("In Selection")
+	is.Tooltip = "only search within the multi-line selection that was active when the search bar was opened"
+	is.ButtonSig.Connect(sb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+			sbb.InSel = send.(*gi.CheckBox).IsChecked()
+			sbb.SetFind(sbb.Find)
+		}
+	})
+
+	cl := sb.AddNewChild(gi.KiT_Label, "count").(*gi.Label)
+	cl.SetMinPrefWidth(units.NewCh(16))
+
+	sb.AddAction(gi.ActOpts{Name: "prev", Icon: "wedge-up", Tooltip: "go to previous match"},
+		sb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+			sbb.Prev()
+		})
+	sb.AddAction(gi.ActOpts{Name: "next", Icon: "wedge-down", Tooltip: "go to next match"},
+		sb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+			sbb.Next()
+		})
+	sb.AddAction(gi.ActOpts{Name: "close", Icon: "close", Tooltip: "close the search bar (escape)"},
+		sb.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+			CloseSearchBar(sbb.View)
+		})
+}
+
+// FindText returns the search string textfield
+func (sb *SearchBar) FindText() *gi.TextField {
+	return sb.ChildByName("find", 0).(*gi.TextField)
+}
+
+// InSelBox returns the in selection checkbox
+func (sb *SearchBar) InSelBox() *gi.CheckBox {
+	return sb.ChildByName("in-sel", 3).(*gi.CheckBox)
+}
+
+// CountLabel returns the label showing the match count
+func (sb *SearchBar) CountLabel() *gi.Label {
+	return sb.ChildByName("count", 4).(*gi.Label)
+}
+
+// SetFind sets the search string, highlighting all the matches, and
+// selects the first one at or after StartPos
+func (sb *SearchBar) SetFind(find string) {
+	sb.Find = find
+	sb.FindMatches()
+	sb.SelectMatch(sb.MatchAfter(sb.StartPos, true))
+}
+
+// FindMatches finds all the matches of the search string in the View
+// (within SelReg if InSel), and highlights them
+func (sb *SearchBar) FindMatches() {
+	tv := sb.View
+	sb.Matches = nil
+	sb.Err = nil
+	sb.Pos = -1
+	if sb.Find != "" && tv.Buf != nil {
+		if sb.Regexp {
+			re, err := FindRegexp(sb.Find, !sb.UseCase)
+			if err != nil {
+				sb.Err = err
+			} else {
+				_, sb.Matches = tv.Buf.SearchRegexp(re)
+			}
+		} else {
+			_, sb.Matches = tv.Buf.Search([]byte(sb.Find), !sb.UseCase, false)
+		}
+		if sb.InSel && !sb.SelReg.IsNil() {
+			sb.SelReg = tv.Buf.AdjustReg(sb.SelReg) // track edits
+			sb.Matches = sb.MatchesInReg(sb.Matches, sb.SelReg)
+		}
+	}
+	nh := len(sb.Matches)
+	if nh > giv.TextViewMaxFindHighlights {
+		nh = giv.TextViewMaxFindHighlights
+	}
+	hi := make([]textbuf.Region, nh)
+	for i := range hi {
+		hi[i] = sb.Matches[i].Reg
+	}
+	tv.Highlights = hi
+	tv.RenderAllLines()
+}
+
+// MatchesInReg returns the matches that are entirely within given region
+func (sb *SearchBar) MatchesInReg(ms []textbuf.Match, reg textbuf.Region) []textbuf.Match {
+	var in []textbuf.Match
+	for _, m := range ms {
+		if m.Reg.Start.IsLess(reg.Start) || reg.End.IsLess(m.Reg.End) {
+			continue
+		}
+		in = append(in, m)
+	}
+	return in
+}
+
+// MatchAfter returns the index of the first match starting after given
+// position (or at it, if orAt), wrapping around to the first -- -1 if none
+func (sb *SearchBar) MatchAfter(pos lex.Pos, orAt bool) int {
+	if len(sb.Matches) == 0 {
+		return -1
+	}
+	for i, m := range sb.Matches {
+		if pos.IsLess(m.Reg.Start) || (orAt && m.Reg.Start == pos) {
+			return i
+		}
+	}
+	return 0
+}
+
+// MatchBefore returns the index of the last match starting before given
+// position, wrapping around to the last -- -1 if none
+func (sb *SearchBar) MatchBefore(pos lex.Pos) int {
+	for i := len(sb.Matches) - 1; i >= 0; i-- {
+		if sb.Matches[i].Reg.Start.IsLess(pos) {
+			return i
+		}
+	}
+	return len(sb.Matches) - 1
+}
+
+// Next moves to the next match after the cursor
+func (sb *SearchBar) Next() {
+	sb.FindMatches() // buffer may have changed
+	sb.SelectMatch(sb.MatchAfter(sb.View.CursorPos, false))
+}
+
+// Prev moves to the previous match before the cursor
+func (sb *SearchBar) Prev() {
+	sb.FindMatches()
+	sb.SelectMatch(sb.MatchBefore(sb.View.CursorPos))
+}
+
+// SelectMatch selects given match, moving the cursor to it, and updates the count
+func (sb *SearchBar) SelectMatch(midx int) {
+	tv := sb.View
+	sb.Pos = midx
+	if midx >= 0 && midx < len(sb.Matches) {
+		reg := sb.Matches[midx].Reg
+		tv.SelectReg = reg
+		tv.SetCursor(reg.Start)
+		tv.SavePosHistory(tv.CursorPos)
+		tv.ScrollCursorToCenterIfHidden()
+		tv.RenderSelectLines()
+	}
+	cl := sb.CountLabel()
+	switch {
+	case sb.Err != nil:
+		cl.SetText("<i>invalid regexp</i>")
+	case sb.Find == "":
+		cl.SetText("")
+	case len(sb.Matches) == 0:
+		cl.SetText("no matches")
+	default:
+		cl.SetText(fmt.Sprintf("%v of %v", sb.Pos+1, len(sb.Matches)))
+	}
+}
+
+// SearchBarKeys handles the arrow and escape keys in the search field
+func (sb *SearchBar) SearchBarKeys(kt *key.ChordEvent) {
+	win := sb.ParentWindow()
+	if win == nil || win.EventMgr.CurFocus() != sb.FindText().This() {
+		return
+	}
+	switch gi.KeyFun(kt.Chord()) {
+	case gi.KeyFunMoveDown:
+		kt.SetProcessed()
+		sb.Next()
+	case gi.KeyFunMoveUp:
+		kt.SetProcessed()
+		sb.Prev()
+	case gi.KeyFunAbort:
+		kt.SetProcessed()
+		CloseSearchBar(sb.View)
+	}
+}
+
+func (sb *SearchBar) ConnectEvents2D() {
+	sb.ToolBar.ConnectEvents2D()
+	// hipri to get keys before the text field
+	sb.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		sbb := recv.Embed(KiT_SearchBar).(*SearchBar)
+		sbb.SearchBarKeys(d.(*key.ChordEvent))
+	})
+}
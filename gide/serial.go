@@ -0,0 +1,93 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/kit"
+)
+
+// SerialParity is the parity of a serial port
+type SerialParity int32
+
+const (
+	// SerialParityNone is no parity bit
+	SerialParityNone SerialParity = iota
+
+	// SerialParityOdd is odd parity
+	SerialParityOdd
+
+	// SerialParityEven is even parity
+	SerialParityEven
+
+	// SerialParityN is the number of parities
+	SerialParityN
+)
+
+//go:generate stringer -type=SerialParity
+
+var KiT_SerialParity = kit.Enums.AddEnumAltLower(SerialParityN, kit.NotBitFlag, nil, "SerialParity")
+
+func (ev SerialParity) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *SerialParity) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// SerialParams are the parameters of the serial port of the Serial Console
+// of a project, e.g., the USB serial of a microcontroller
+type SerialParams struct {
+	Device   string       `width:"24" desc:"serial device, e.g., /dev/ttyUSB0 or /dev/ttyACM0 on Linux, /dev/cu.usbmodem1101 on macOS"`
+	Baud     int          `desc:"baud rate, e.g., 9600 or 115200 (the default)"`
+	DataBits int          `min:"5" max:"8" desc:"data bits: 5 to 8 -- 8 is the default"`
+	Parity   SerialParity `desc:"parity"`
+	StopBits int          `min:"1" max:"2" desc:"stop bits: 1 or 2 -- 1 is the default"`
+	HWFlow   bool         `desc:"use hardware (RTS / CTS) flow control"`
+	CRLF     bool         `desc:"end the lines sent with CR LF, instead of LF"`
+	LogFile  gi.FileName  `desc:"if set, the output of the port is appended to this file"`
+}
+
+// Defaults sets the unset params to their defaults: 115200 8N1
+func (sp *SerialParams) Defaults() {
+	if sp.Baud <= 0 {
+		sp.Baud = 115200
+	}
+	if sp.DataBits < 5 || sp.DataBits > 8 {
+		sp.DataBits = 8
+	}
+	if sp.StopBits < 1 || sp.StopBits > 2 {
+		sp.StopBits = 1
+	}
+}
+
+// String returns the params in the usual notation, e.g., /dev/ttyUSB0
+// 115200 8N1
+func (sp *SerialParams) String() string {
+	par := "N"
+	switch sp.Parity {
+	case SerialParityOdd:
+		par = "O"
+	case SerialParityEven:
+		par = "E"
+	}
+	return fmt.Sprintf("%v %d %d%v%d", sp.Device, sp.Baud, sp.DataBits, par, sp.StopBits)
+}
+
+// SerialDevicePatterns are the glob patterns of the serial devices offered
+// by the Serial Console -- the USB serial adapters and boards of Linux and
+// macOS
+var SerialDevicePatterns = []string{"/dev/ttyUSB*", "/dev/ttyACM*", "/dev/ttyAMA*", "/dev/cu.*"}
+
+// SerialDevices returns the serial devices matching SerialDevicePatterns
+func SerialDevices() []string {
+	var devs []string
+	for _, pat := range SerialDevicePatterns {
+		fns, _ := filepath.Glob(pat)
+		devs = append(devs, fns...)
+	}
+	sort.Strings(devs)
+	return devs
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build darwin
+// +build darwin
+
+package gide
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// serialBauds are the speeds of the baud rates of the serial ports
+var serialBauds = map[int]uint64{
+	1200: syscall.B1200, 2400: syscall.B2400, 4800: syscall.B4800, 9600: syscall.B9600,
+	19200: syscall.B19200, 38400: syscall.B38400, 57600: syscall.B57600, 115200: syscall.B115200,
+	230400: syscall.B230400,
+}
+
+// serialCRTSCTS is the termios flag of the hardware flow control, missing
+// from syscall
+const serialCRTSCTS = 0x30000
+
+// serialSizes are the flags of the data bits of the serial ports
+var serialSizes = map[int]uint64{5: syscall.CS5, 6: syscall.CS6, 7: syscall.CS7, 8: syscall.CS8}
+
+// setSerialAttrs sets the serial port of given file descriptor to raw mode,
+// with given params
+func setSerialAttrs(fd uintptr, sp *SerialParams) error {
+	speed, ok := serialBauds[sp.Baud]
+	if !ok {
+		return fmt.Errorf("unsupported baud rate: %d", sp.Baud)
+	}
+	var tio syscall.Termios
+	if err := ptyIoctl(fd, syscall.TIOCGETA, uintptr(unsafe.Pointer(&tio))); err != nil {
+		return err
+	}
+	tio.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON | syscall.IXOFF
+	tio.Oflag &^= syscall.OPOST
+	tio.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	tio.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.PARODD | syscall.CSTOPB | serialCRTSCTS
+	tio.Cflag |= serialSizes[sp.DataBits] | syscall.CLOCAL | syscall.CREAD
+	switch sp.Parity {
+	case SerialParityOdd:
+		tio.Cflag |= syscall.PARENB | syscall.PARODD
+	case SerialParityEven:
+		tio.Cflag |= syscall.PARENB
+	}
+	if sp.StopBits == 2 {
+		tio.Cflag |= syscall.CSTOPB
+	}
+	if sp.HWFlow {
+		tio.Cflag |= serialCRTSCTS
+	}
+	tio.Ispeed = speed
+	tio.Ospeed = speed
+	tio.Cc[syscall.VMIN] = 1
+	tio.Cc[syscall.VTIME] = 0
+	return ptyIoctl(fd, syscall.TIOCSETA, uintptr(unsafe.Pointer(&tio)))
+}
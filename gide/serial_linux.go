@@ -0,0 +1,68 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package gide
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// serialBauds are the speeds of the baud rates of the serial ports
+var serialBauds = map[int]uint32{
+	1200: syscall.B1200, 2400: syscall.B2400, 4800: syscall.B4800, 9600: syscall.B9600,
+	19200: syscall.B19200, 38400: syscall.B38400, 57600: syscall.B57600, 115200: syscall.B115200,
+	230400: syscall.B230400, 460800: syscall.B460800, 500000: syscall.B500000, 576000: syscall.B576000,
+	921600: syscall.B921600, 1000000: syscall.B1000000, 1500000: syscall.B1500000, 2000000: syscall.B2000000,
+	3000000: syscall.B3000000, 4000000: syscall.B4000000,
+}
+
+// serialCBAUD and serialCRTSCTS are the termios flags of the speed and of
+// the hardware flow control, missing from syscall
+const (
+	serialCBAUD   = 0x100f
+	serialCRTSCTS = 0x80000000
+)
+
+// serialSizes are the flags of the data bits of the serial ports
+var serialSizes = map[int]uint32{5: syscall.CS5, 6: syscall.CS6, 7: syscall.CS7, 8: syscall.CS8}
+
+// setSerialAttrs sets the serial port of given file descriptor to raw mode,
+// with given params
+func setSerialAttrs(fd uintptr, sp *SerialParams) error {
+	speed, ok := serialBauds[sp.Baud]
+	if !ok {
+		return fmt.Errorf("unsupported baud rate: %d", sp.Baud)
+	}
+	var tio syscall.Termios
+	if err := ptyIoctl(fd, syscall.TCGETS, uintptr(unsafe.Pointer(&tio))); err != nil {
+		return err
+	}
+	tio.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON | syscall.IXOFF
+	tio.Oflag &^= syscall.OPOST
+	tio.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	tio.Cflag &^= serialCBAUD | syscall.CSIZE | syscall.PARENB | syscall.PARODD | syscall.CSTOPB | serialCRTSCTS
+	tio.Cflag |= speed | serialSizes[sp.DataBits] | syscall.CLOCAL | syscall.CREAD
+	switch sp.Parity {
+	case SerialParityOdd:
+		tio.Cflag |= syscall.PARENB | syscall.PARODD
+	case SerialParityEven:
+		tio.Cflag |= syscall.PARENB
+	}
+	if sp.StopBits == 2 {
+		tio.Cflag |= syscall.CSTOPB
+	}
+	if sp.HWFlow {
+		tio.Cflag |= serialCRTSCTS
+	}
+	tio.Ispeed = speed
+	tio.Ospeed = speed
+	tio.Cc[syscall.VMIN] = 1
+	tio.Cc[syscall.VTIME] = 0
+	return ptyIoctl(fd, syscall.TCSETS, uintptr(unsafe.Pointer(&tio)))
+}
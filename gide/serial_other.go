@@ -0,0 +1,20 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package gide
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// OpenSerial opens the serial port of given params -- not supported on
+// this platform
+func OpenSerial(sp *SerialParams) (io.ReadWriteCloser, error) {
+	return nil, fmt.Errorf("serial ports are not supported on %v", runtime.GOOS)
+}
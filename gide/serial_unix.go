@@ -0,0 +1,28 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin
+// +build linux darwin
+
+package gide
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// OpenSerial opens the serial port of given params, in raw mode -- it is
+// opened without waiting for the carrier, and ignores the modem lines
+func OpenSerial(sp *SerialParams) (io.ReadWriteCloser, error) {
+	f, err := os.OpenFile(sp.Device, os.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = setSerialAttrs(f.Fd(), sp); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// SerialCmdName is the name of the tab of the Serial Console
+var SerialCmdName = "Serial Console"
+
+// SerialView is the Serial Console: the output of the serial port of the
+// project (see SerialParams), e.g., of a microcontroller flashed with
+// TinyGo, with the links and search of the command output, optionally
+// logged to a file, and a line for sending text to the port
+type SerialView struct {
+	gi.Layout
+	Gide Gide               `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Port io.ReadWriteCloser `json:"-" xml:"-" view:"-" desc:"the open port, nil if closed"`
+	Log  *os.File           `json:"-" xml:"-" view:"-" desc:"the log file of the output, if any"`
+	Mu   sync.Mutex         `json:"-" xml:"-" view:"-" desc:"mutex protecting the port"`
+}
+
+var KiT_SerialView = kit.Types.AddType(&SerialView{}, SerialViewProps)
+
+// Config configures the view
+func (sv *SerialView) Config(ge Gide) {
+	sv.Gide = ge
+	sv.Lay = gi.LayoutVert
+	sv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "serialbar")
+	config.Add(gi.KiT_Layout, "out")
+	config.Add(gi.KiT_ToolBar, "sendbar")
+	mods, updt := sv.ConfigChildren(config)
+	if !mods {
+		updt = sv.UpdateStart()
+	}
+	sv.ConfigToolbar()
+	ly := sv.ChildByName("out", 1).(*gi.Layout)
+	otv := ConfigOutputTextView(ly)
+	if otv.Buf == nil {
+		otv.SetBuf(giv.NewTextBuf())
+	}
+	sv.ConfigSendBar()
+	sv.SetStatus()
+	sv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (sv *SerialView) ToolBar() *gi.ToolBar {
+	return sv.ChildByName("serialbar", 0).(*gi.ToolBar)
+}
+
+// TextView returns the TextView of the output
+func (sv *SerialView) TextView() *giv.TextView {
+	ly := sv.ChildByName("out", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// SendField returns the text field of the text to send
+func (sv *SerialView) SendField() *gi.TextField {
+	return sv.ChildByName("sendbar", 2).ChildByName("send", 1).(*gi.TextField)
+}
+
+// Params returns the serial params of the project
+func (sv *SerialView) Params() *SerialParams {
+	return &sv.Gide.ProjPrefs().Serial
+}
+
+// IsOpen returns true if the port is open
+func (sv *SerialView) IsOpen() bool {
+	sv.Mu.Lock()
+	defer sv.Mu.Unlock()
+	return sv.Port != nil
+}
+
+// Connect opens the port, choosing the device first if not set
+func (sv *SerialView) Connect() {
+	sp := sv.Params()
+	if sp.Device == "" {
+		sv.ChooseDevice(true)
+		return
+	}
+	sv.Disconnect()
+	sp.Defaults()
+	port, err := OpenSerial(sp)
+	if err != nil {
+		sv.Event(fmt.Sprintf("could not open %v: %v", sp.Device, err))
+		return
+	}
+	var log *os.File
+	if sp.LogFile != "" {
+		log, err = os.OpenFile(string(sp.LogFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			sv.Event(fmt.Sprintf("could not open the log file %v: %v", sp.LogFile, err))
+		}
+	}
+	sv.Mu.Lock()
+	sv.Port = port
+	sv.Log = log
+	sv.Mu.Unlock()
+	msg := "connected: " + sp.String()
+	if log != nil {
+		msg += " -- logging to " + string(sp.LogFile)
+	}
+	sv.Event(msg)
+	sv.SetStatus()
+	go sv.Read(port, log)
+}
+
+// Read shows the output of given port, and logs it to given file if
+// non-nil, until the port is closed
+func (sv *SerialView) Read(port io.ReadWriteCloser, log *os.File) {
+	var out io.Reader = port
+	if log != nil {
+		out = io.TeeReader(port, log)
+	}
+	obuf := OutBuf{}
+	obuf.Init(out, sv.TextView().Buf, 0, MarkupCmdOutput)
+	obuf.MonOut()
+	sv.Mu.Lock()
+	cur := sv.Port == port
+	if cur {
+		sv.Port = nil
+		sv.Log = nil
+	}
+	sv.Mu.Unlock()
+	if !cur {
+		return // closed by Disconnect
+	}
+	port.Close()
+	if log != nil {
+		log.Close()
+	}
+	sv.Event("disconnected: the port was closed, e.g., the device was unplugged")
+	sv.SetStatus()
+}
+
+// Disconnect closes the port, and the log file
+func (sv *SerialView) Disconnect() {
+	if !sv.ClosePort() {
+		return
+	}
+	sv.Event("disconnected")
+	sv.SetStatus()
+}
+
+// ClosePort closes the port, and the log file, returning false if it was
+// not open
+func (sv *SerialView) ClosePort() bool {
+	sv.Mu.Lock()
+	port, log := sv.Port, sv.Log
+	sv.Port = nil
+	sv.Log = nil
+	sv.Mu.Unlock()
+	if port == nil {
+		return false
+	}
+	port.Close()
+	if log != nil {
+		log.Close()
+	}
+	return true
+}
+
+// Send sends given line to the port, ending with LF or CR LF
+func (sv *SerialView) Send(ln string) error {
+	sv.Mu.Lock()
+	port := sv.Port
+	sv.Mu.Unlock()
+	if port == nil {
+		return fmt.Errorf("the serial port is not open")
+	}
+	if sv.Params().CRLF {
+		ln += "\r\n"
+	} else {
+		ln += "\n"
+	}
+	_, err := port.Write([]byte(ln))
+	return err
+}
+
+// ChooseDevice pops up a menu to select the device among SerialDevices,
+// connecting to it if connect
+func (sv *SerialView) ChooseDevice(connect bool) {
+	devs := SerialDevices()
+	if len(devs) == 0 {
+		gi.PromptDialog(sv.Viewport, gi.DlgOpts{Title: "No Serial Devices", Prompt: "No serial devices were found -- plug in the device, or set its Device in the Settings"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	gi.StringsChooserPopup(devs, sv.Params().Device, sv.ToolBar(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		sv.Params().Device = ac.Text
+		sv.Gide.ProjPrefs().Changed = true
+		if connect || sv.IsOpen() {
+			sv.Connect()
+		} else {
+			sv.SetStatus()
+		}
+	})
+}
+
+// EditParams opens a dialog for editing the serial params, reconnecting
+// with them if connected
+func (sv *SerialView) EditParams() {
+	sp := sv.Params()
+	sp.Defaults()
+	giv.StructViewDialog(sv.Gide.VPort(), sp, giv.DlgOpts{Title: "Serial Port", Prompt: "serial port of the Serial Console of the project"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SerialView).(*SerialView)
+			svv.Gide.ProjPrefs().Changed = true
+			if svv.IsOpen() {
+				svv.Connect()
+			} else {
+				svv.SetStatus()
+			}
+		})
+}
+
+// Clear clears the output
+func (sv *SerialView) Clear() {
+	sv.TextView().Buf.New(0)
+}
+
+// Event appends given connection event, in bold with the time, to the
+// output
+func (sv *SerialView) Event(msg string) {
+	ge := sv.Gide
+	ln := fmt.Sprintf("[%v] %v", time.Now().Format("15:04:05"), msg)
+	wupdt := ge.VPort().TopUpdateStart()
+	defer ge.VPort().TopUpdateEnd(wupdt)
+	buf := sv.TextView().Buf
+	buf.AppendTextLineMarkup([]byte(ln), []byte("<b>"+html.EscapeString(ln)+"</b>"), giv.EditSignal)
+	buf.AutoScrollViews()
+	ge.SetStatus("serial console: " + msg)
+}
+
+// SetStatus shows the params, and whether connected, in the toolbar
+func (sv *SerialView) SetStatus() {
+	sp := sv.Params()
+	st := "not connected"
+	if sv.IsOpen() {
+		st = "connected: " + sp.String()
+	} else if sp.Device != "" {
+		st += ": " + sp.Device
+	}
+	if lb, ok := sv.ToolBar().ChildByName("status", 0).(*gi.Label); ok {
+		wupdt := sv.Gide.VPort().TopUpdateStart()
+		lb.SetText(st)
+		sv.Gide.VPort().TopUpdateEnd(wupdt)
+	}
+}
+
+// ConfigToolbar adds the toolbar actions
+func (sv *SerialView) ConfigToolbar() {
+	tb := sv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Connect", Icon: "play", Tooltip: "open the serial port, choosing the device first if not set -- reconnects if connected"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SerialView).(*SerialView)
+			svv.Connect()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Disconnect", Icon: "stop", Tooltip: "close the serial port, e.g., to flash the device"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SerialView).(*SerialView)
+			svv.Disconnect()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Device...", Icon: "file-open", Tooltip: "choose the serial device, among the USB serial devices plugged in"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SerialView).(*SerialView)
+			svv.ChooseDevice(false)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Settings...", Icon: "gear", Tooltip: "edit the device, baud rate, data bits, parity, stop bits, flow control, line ending and log file"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SerialView).(*SerialView)
+			svv.EditParams()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear", Icon: "close", Tooltip: "clear the output -- the log file is kept"},
+		sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SerialView).(*SerialView)
+			svv.Clear()
+		})
+	tb.AddSeparator("sep-status")
+	gi.AddNewLabel(tb, "status", "")
+}
+
+// ConfigSendBar configures the line for sending text to the port
+func (sv *SerialView) ConfigSendBar() {
+	sb := sv.ChildByName("sendbar", 2).(*gi.ToolBar)
+	if sb.HasChildren() {
+		return
+	}
+	sb.SetStretchMaxWidth()
+	gi.AddNewLabel(sb, "send-lbl", "Send:")
+	tf := gi.AddNewTextField(sb, "send")
+	tf.Tooltip = "text sent to the port, with the line ending, on Enter"
+	tf.SetStretchMaxWidth()
+	tf.TextFieldSig.Connect(sv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.TextFieldDone) {
+			return
+		}
+		svv, _ := recv.Embed(KiT_SerialView).(*SerialView)
+		tff := send.(*gi.TextField)
+		if err := svv.Send(tff.Text()); err != nil {
+			svv.Gide.SetStatus("serial console: " + err.Error())
+			return
+		}
+		tff.SetText("")
+		tff.GrabFocus()
+	})
+}
+
+// Destroy closes the port when the tab is closed
+func (sv *SerialView) Destroy() {
+	sv.ClosePort()
+	sv.Layout.Destroy()
+}
+
+// SerialViewProps are style properties for SerialView
+var SerialViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+)
+
+// SessionView is the saved state of one editor pane
+type SessionView struct {
+	File    string  `desc:"file being viewed (see SessionPath), empty if none"`
+	Line    int     `desc:"line of the cursor (0-based)"`
+	Col     int     `desc:"column of the cursor (0-based, in runes)"`
+	TopLine int     `desc:"first visible line, i.e., the scroll position"`
+	Zoom    float32 `desc:"zoom factor of the font of the pane, 0 if not zoomed"`
+}
+
+// Session is the state of the work in a project, saved in the project file
+// and restored when the project is opened again: the files being viewed in
+// each of the editor panes, the other open files, the command output
+// tabs, and the terminals.  The layout of the panes and the find history
+// are saved with the rest of the ProjPrefs.
+type Session struct {
+	Views      []SessionView `desc:"state of each of the editor panes, in order of GideView.TextViews"`
+	ActiveView int           `desc:"index of the active editor pane"`
+	Open       []string      `desc:"all the open files, most recently used first (see SessionPath)"`
+	Tabs       []string      `desc:"labels of the tabs that were open in the tabs panel"`
+	ActiveTab  string        `desc:"label of the selected tab"`
+	Terms      []SessionTerm `desc:"terminal tabs, restarted in the working directories of their panes"`
+}
+
+// SessionTerm is the saved state of a terminal tab: its name, the split of
+// its panes, and the working directory of the shell of each pane (see
+// SessionPath)
+type SessionTerm struct {
+	Name string   `desc:"name of the terminal, and of its tab"`
+	Host string   `desc:"if set, the SSH host of the terminal, with the Dirs on the host"`
+	Vert bool     `desc:"panes are stacked vertically, instead of side by side"`
+	Dirs []string `desc:"working directory of each pane"`
+}
+
+// SessionPath returns the path to save in a Session for given file: relative
+// to given project root if within it, so the project can be moved
+func SessionPath(root, fpath string) string {
+	rel, err := filepath.Rel(root, fpath)
+	if err != nil || RelOutside(rel) {
+		return fpath
+	}
+	return filepath.ToSlash(rel)
+}
+
+// SessionFullPath returns the full path of a file saved with SessionPath
+func SessionFullPath(root, spath string) string {
+	if spath == "" || filepath.IsAbs(spath) {
+		return spath
+	}
+	return filepath.Join(root, filepath.FromSlash(spath))
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// ShellPrefs are the preferences of the shell run by the terminals, and by
+// the commands with Shell set, for each OS -- so the same preferences can
+// be used on all of them
+type ShellPrefs struct {
+	Linux   ShellOSPrefs `desc:"shell on Linux and the other unix systems"`
+	MacOS   ShellOSPrefs `desc:"shell on macOS"`
+	Windows ShellOSPrefs `desc:"shell on Windows"`
+}
+
+// Cur returns the shell preferences of the OS running gide
+func (sp *ShellPrefs) Cur() *ShellOSPrefs {
+	switch runtime.GOOS {
+	case "darwin":
+		return &sp.MacOS
+	case "windows":
+		return &sp.Windows
+	default:
+		return &sp.Linux
+	}
+}
+
+// ShellOSPrefs are the preferences of the shell on one OS
+type ShellOSPrefs struct {
+	Shell  string            `desc:"shell to run, e.g., bash, zsh, fish, pwsh or cmd, or its full path -- if empty, $SHELL or /bin/sh, or %COMSPEC% or cmd.exe on Windows"`
+	Login  bool              `desc:"run it as a login shell, reading your profile (e.g., ~/.bash_profile) -- useful on macOS, where gui apps do not get the environment of your login"`
+	RcFile gi.FileName       `desc:"file sourced by the shell when started, e.g., ~/.gide_rc, for aliases and settings specific to gide -- in the terminals, and before the commands with Shell set"`
+	Env    map[string]string `desc:"extra environment variables of the shell"`
+}
+
+// ShellKind is the kind of a shell, for the args it takes
+type ShellKind int
+
+const (
+	// ShellPosix is sh and its descendants: bash, zsh, dash, ksh
+	ShellPosix ShellKind = iota
+
+	// ShellFish is the fish shell
+	ShellFish
+
+	// ShellPwsh is PowerShell
+	ShellPwsh
+
+	// ShellCmd is the Windows cmd
+	ShellCmd
+)
+
+// DefaultShell returns the shell of the user: $SHELL, or /bin/sh -- on
+// windows, %COMSPEC% or cmd.exe
+func DefaultShell() string {
+	if runtime.GOOS == "windows" {
+		if sh := os.Getenv("COMSPEC"); sh != "" {
+			return sh
+		}
+		return "cmd.exe"
+	}
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	return "/bin/sh"
+}
+
+// ShellPath returns the shell to run: the Shell, or DefaultShell
+func (sp *ShellOSPrefs) ShellPath() string {
+	if sh := strings.TrimSpace(sp.Shell); sh != "" {
+		return sh
+	}
+	return DefaultShell()
+}
+
+// Kind returns the kind of the shell, from its name
+func (sp *ShellOSPrefs) Kind() ShellKind {
+	nm := strings.ToLower(strings.TrimSuffix(filepath.Base(sp.ShellPath()), ".exe"))
+	switch nm {
+	case "fish":
+		return ShellFish
+	case "pwsh", "powershell":
+		return ShellPwsh
+	case "cmd":
+		return ShellCmd
+	default:
+		return ShellPosix
+	}
+}
+
+// LoginArgs returns the args making the shell a login shell, if Login
+func (sp *ShellOSPrefs) LoginArgs() []string {
+	if !sp.Login {
+		return nil
+	}
+	switch sp.Kind() {
+	case ShellPwsh:
+		return []string{"-Login"}
+	case ShellCmd:
+		return nil
+	default:
+		return []string{"-l"}
+	}
+}
+
+// SourceCmd returns the command sourcing the RcFile in the shell, "" if
+// none
+func (sp *ShellOSPrefs) SourceCmd() string {
+	rc := string(sp.RcFile)
+	if rc == "" {
+		return ""
+	}
+	if strings.HasPrefix(rc, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			rc = filepath.Join(home, rc[1:])
+		}
+	}
+	switch sp.Kind() {
+	case ShellCmd:
+		return `call "` + rc + `"`
+	case ShellFish:
+		return "source " + ShellQuote(rc)
+	default:
+		return ". " + ShellQuote(rc)
+	}
+}
+
+// ShellQuote quotes given string for the posix shells, fish and PowerShell
+func ShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
+
+// EnvList returns the Env as a list of name=value, sorted by name
+func (sp *ShellOSPrefs) EnvList() []string {
+	keys := make([]string, 0, len(sp.Env))
+	for k := range sp.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, len(keys))
+	for i, k := range keys {
+		env[i] = k + "=" + sp.Env[k]
+	}
+	return env
+}
+
+// Command returns the command running given command line in the shell,
+// after sourcing the RcFile
+func (sp *ShellOSPrefs) Command(cmdline string) *exec.Cmd {
+	if src := sp.SourceCmd(); src != "" {
+		if sp.Kind() == ShellCmd {
+			cmdline = src + " & " + cmdline
+		} else {
+			cmdline = src + "; " + cmdline
+		}
+	}
+	args := sp.LoginArgs()
+	switch sp.Kind() {
+	case ShellCmd:
+		args = append(args, "/C", cmdline)
+	case ShellPwsh:
+		args = append(args, "-Command", cmdline)
+	default:
+		args = append(args, "-c", cmdline)
+	}
+	return exec.Command(sp.ShellPath(), args...)
+}
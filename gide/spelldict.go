@@ -0,0 +1,149 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/pi/spell"
+)
+
+// SpellDictFileName is the default name of the dictionary file of a
+// project, at its root, when the project prefs do not set one
+var SpellDictFileName = ".gide-words.txt"
+
+// SpellDict is the dictionary of the words accepted by the spell checking in
+// a project (product names, identifiers..), merged with the user dictionary:
+// a file of the project, one word per line, with # comments, meant to be
+// committed so that the team shares it.  Its words are ignored by the spell
+// checker, which is shared by all the projects open.
+type SpellDict struct {
+	Path    string    `desc:"path of the dictionary file -- it may not exist yet"`
+	ModTime time.Time `desc:"modification time of the file when last read"`
+	Words   []string  `desc:"words of the dictionary, lowercase, sorted"`
+}
+
+// SpellDictPath returns the path of the dictionary file of a project at
+// given root, set by given file name relative to it, SpellDictFileName if
+// empty
+func SpellDictPath(root string, fname gi.FileName) string {
+	fn := string(fname)
+	if fn == "" {
+		fn = SpellDictFileName
+	}
+	if filepath.IsAbs(fn) {
+		return fn
+	}
+	return filepath.Join(root, fn)
+}
+
+// ParseSpellDict returns the words of given dictionary file contents,
+// lowercase, sorted and without duplicates
+func ParseSpellDict(src []byte) []string {
+	has := map[string]bool{}
+	var words []string
+	for _, ln := range strings.Split(string(src), "\n") {
+		ln = strings.TrimSpace(ln)
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		wd := strings.ToLower(ln)
+		if !has[wd] {
+			has[wd] = true
+			words = append(words, wd)
+		}
+	}
+	sort.Strings(words)
+	return words
+}
+
+// Open reads the dictionary file at given path, replacing the words of the
+// dictionary read before, if any -- a missing file is an empty dictionary
+func (sd *SpellDict) Open(path string) error {
+	if sd.Path != path {
+		sd.SetWords(nil)
+		sd.Path = path
+		sd.ModTime = time.Time{}
+	}
+	return sd.Load()
+}
+
+// Load reads the dictionary file, if it was modified since last read
+func (sd *SpellDict) Load() error {
+	if sd.Path == "" {
+		return nil
+	}
+	st, err := os.Stat(sd.Path)
+	if err != nil {
+		sd.SetWords(nil)
+		sd.ModTime = time.Time{}
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if st.ModTime().Equal(sd.ModTime) {
+		return nil
+	}
+	src, err := ioutil.ReadFile(sd.Path)
+	if err != nil {
+		return err
+	}
+	sd.ModTime = st.ModTime()
+	sd.SetWords(ParseSpellDict(src))
+	return nil
+}
+
+// SetWords sets the words of the dictionary, which are ignored by the spell
+// checker, and no longer ignores the previous words that are not among them
+func (sd *SpellDict) SetWords(words []string) {
+	nw := make(map[string]bool, len(words))
+	for _, wd := range words {
+		nw[wd] = true
+		spell.IgnoreWord(wd)
+	}
+	for _, wd := range sd.Words {
+		if !nw[wd] {
+			delete(spell.Ignore, wd)
+		}
+	}
+	sd.Words = words
+}
+
+// Has returns true if given word is in the dictionary, regardless of case
+func (sd *SpellDict) Has(word string) bool {
+	wd := strings.ToLower(word)
+	i := sort.SearchStrings(sd.Words, wd)
+	return i < len(sd.Words) && sd.Words[i] == wd
+}
+
+// Add adds given word to the dictionary, appending it to the file (created
+// if needed), so that the spell checker ignores it
+func (sd *SpellDict) Add(word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" || sd.Has(word) {
+		return nil
+	}
+	sd.Load() // don't lose words added by others since
+	src, err := ioutil.ReadFile(sd.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		src = append(src, '\n')
+	}
+	src = append(src, []byte(word+"\n")...)
+	if err := ioutil.WriteFile(sd.Path, src, 0644); err != nil {
+		return err
+	}
+	sd.ModTime = time.Time{}
+	return sd.Load()
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"sync"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/pi/filecat"
+)
+
+// The spelling model takes a while to load, and giv loads it when the first
+// buffer of a supported file is opened, so the buffers opened before it is
+// loaded are configured with a spell checker standing in for theirs, and
+// only get theirs once LoadSpell has loaded it in the background.
+
+// spellStandIn stands in for the spell checker of the buffers configured
+// before the spelling model is loaded, so that giv does not load it
+var spellStandIn = &gi.Spell{}
+
+var (
+	spellOnce     sync.Once
+	spellMu       sync.Mutex
+	spellLoaded   bool
+	spellDeferred []*giv.TextBuf
+)
+
+// SpellLoaded returns true if the spelling model has been loaded by LoadSpell
+func SpellLoaded() bool {
+	spellMu.Lock()
+	defer spellMu.Unlock()
+	return spellLoaded
+}
+
+// DeferSpell defers the spell checking of given buffer, about to be opened
+// or configured, until the spelling model is loaded by LoadSpell, so that
+// giv does not load it then -- ConfigSupported must be called once the
+// buffer is opened.  Does nothing once the model is loaded.
+func DeferSpell(tb *giv.TextBuf) {
+	spellMu.Lock()
+	defer spellMu.Unlock()
+	if spellLoaded || tb.Spell != nil {
+		return
+	}
+	tb.Spell = spellStandIn
+	for _, dt := range spellDeferred {
+		if dt == tb {
+			return
+		}
+	}
+	spellDeferred = append(spellDeferred, tb)
+}
+
+// ConfigSupported configures given buffer for its supported file type, as
+// giv.TextBuf.ConfigSupported does, but without spell checking until the
+// spelling model is loaded by LoadSpell (see DeferSpell)
+func ConfigSupported(tb *giv.TextBuf) bool {
+	DeferSpell(tb)
+	sup := tb.ConfigSupported()
+	endDeferSpell(tb)
+	return sup
+}
+
+// RevertBuf reverts given buffer to the file on disk, as
+// giv.TextBuf.Revert does, but without loading the spelling model if it is
+// not yet loaded (see DeferSpell)
+func RevertBuf(tb *giv.TextBuf) bool {
+	DeferSpell(tb)
+	ok := tb.Revert()
+	endDeferSpell(tb)
+	return ok
+}
+
+// endDeferSpell removes the spell checker standing in for the one of given
+// buffer, if any, so that it is not used
+func endDeferSpell(tb *giv.TextBuf) {
+	spellMu.Lock()
+	defer spellMu.Unlock()
+	if tb.Spell == spellStandIn {
+		tb.Spell = nil
+	}
+}
+
+// LoadSpell loads the spelling model, only the first time it is called, and
+// then gives the buffers whose spell checking was deferred their spell
+// checker, calling given function to do so on the event loop if non-nil
+// (LoadSpell is meant to run in the background after startup)
+func LoadSpell(post func(fn func())) {
+	spellOnce.Do(func() { gi.InitSpell() })
+	spellMu.Lock()
+	spellLoaded = true
+	tbs := spellDeferred
+	spellDeferred = nil
+	spellMu.Unlock()
+	if len(tbs) == 0 {
+		return
+	}
+	set := func() {
+		for _, tb := range tbs {
+			if tb.This() == nil || tb.IsDestroyed() || tb.Spell != nil || tb.Info.Sup == filecat.NoSupport {
+				continue
+			}
+			tb.SetSpell()
+		}
+	}
+	if post != nil {
+		post(set)
+	} else {
+		set()
+	}
+}
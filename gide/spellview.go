@@ -10,8 +10,10 @@ import (
 
 	"github.com/goki/gi/gist"
 	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/pi/filecat"
 	"github.com/goki/pi/lex"
 	"github.com/goki/pi/spell"
+	"github.com/goki/pi/token"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/giv"
@@ -69,7 +71,7 @@ func (sv *SpellView) Config(ge Gide, atv *TextView) {
 	}
 	sv.ConfigToolbar()
 	sv.UpdateEnd(updt)
-	gi.InitSpell()
+	LoadSpell(nil)
 	sv.CheckNext()
 }
 
@@ -113,6 +115,11 @@ func (sv *SpellView) LearnAct() *gi.Action {
 	return sv.UnknownBar().ChildByName("learn", 3).(*gi.Action)
 }
 
+// ProjDictAct returns the add to project dictionary action from toolbar
+func (sv *SpellView) ProjDictAct() *gi.Action {
+	return sv.UnknownBar().ChildByName("proj-dict", 3).(*gi.Action)
+}
+
 // UnknownText returns the unknown word textfield from toolbar
 func (sv *SpellView) UnknownText() *gi.TextField {
 	return sv.UnknownBar().ChildByName("unknown-str", 1).(*gi.TextField)
@@ -191,6 +198,12 @@ func (sv *SpellView) ConfigToolbar() {
 			svv.LearnAction()
 		})
 
+	unknbar.AddAction(gi.ActOpts{Name: "proj-dict", Label: "Add To Project", Tooltip: "add the unknown word to the dictionary of the project, shared by the team, so that it is accepted in all its files"}, sv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			svv, _ := recv.Embed(KiT_SpellView).(*SpellView)
+			svv.ProjDictAction()
+		})
+
 	// change toolbar
 	changestr := chgbar.AddNewChild(gi.KiT_TextField, "change-str").(*gi.TextField)
 	changestr.SetStretchMaxWidth()
@@ -252,7 +265,7 @@ func (sv *SpellView) CheckNext() {
 				break
 			}
 			sv.CurIdx = 0
-			sv.Errs = tv.Buf.SpellCheckLineErrs(sv.CurLn)
+			sv.Errs = SpellCheckLineErrs(tv.Buf, sv.CurLn)
 		}
 	}
 	if done {
@@ -296,6 +309,44 @@ func (sv *SpellView) CheckNext() {
 	}
 }
 
+// SpellCheckLineErrs returns the spelling errors for given line of the buffer,
+// scoped according to the type of file: for code files, only comments and
+// string literals are checked (using the syntax highlighting tags), so that
+// identifiers are not flagged, while for Markdown, LaTeX and other
+// documents / text the full text is checked.
+func SpellCheckLineErrs(tb *giv.TextBuf, ln int) lex.Line {
+	if tb.Info.Cat != filecat.Code {
+		return tb.SpellCheckLineErrs(ln)
+	}
+	if !tb.IsValidLine(ln) {
+		return nil
+	}
+	tb.LinesMu.RLock()
+	defer tb.LinesMu.RUnlock()
+	tb.MarkupMu.RLock()
+	defer tb.MarkupMu.RUnlock()
+	if ln >= len(tb.HiTags) {
+		return nil
+	}
+	src := tb.Lines[ln]
+	var ser lex.Line
+	for _, t := range tb.HiTags[ln] {
+		if !(t.Tok.Tok.InCat(token.Comment) || t.Tok.Tok.InSubCat(token.LitStr)) {
+			continue
+		}
+		if t.St < 0 || t.Ed > len(src) || t.St >= t.Ed {
+			continue
+		}
+		errs := spell.CheckLexLine(src[t.St:t.Ed], nil)
+		for _, e := range errs {
+			e.St += t.St
+			e.Ed += t.St
+			ser = append(ser, e)
+		}
+	}
+	return ser
+}
+
 // ChangeAction replaces the known word with the selected suggested word
 // and call CheckNextAction
 func (sv *SpellView) ChangeAction() {
@@ -379,6 +430,17 @@ func (sv *SpellView) LearnAction() {
 	sv.CheckNext()
 }
 
+// ProjDictAction adds the current unknown word to the dictionary of the
+// project and calls CheckNext
+func (sv *SpellView) ProjDictAction() {
+	if err := sv.Gide.ProjSpellDict().Add(sv.UnkWord); err != nil {
+		sv.Gide.SetStatus("could not add to the project dictionary: " + err.Error())
+		return
+	}
+	sv.LastAction = sv.ProjDictAct()
+	sv.CheckNext()
+}
+
 // AcceptSuggestion replaces the misspelled word with the word in the ChangeText field
 func (sv *SpellView) AcceptSuggestion(s string) {
 	ct := sv.ChangeText()
@@ -17,6 +17,7 @@ import (
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
 	"github.com/goki/ki/sliceclone"
+	"github.com/goki/mat32"
 )
 
 // Split is a named splitter configuration
@@ -88,6 +89,15 @@ func (lt *Splits) Names() []string {
 	return nms
 }
 
+// PaneLayout records the arrangement of editor panes within one of the main
+// text view panels, which can be split recursively into a grid of panes --
+// a layout without any Panes is a single text view
+type PaneLayout struct {
+	Dim    mat32.Dims    `desc:"dimension along which the sub-panes are arranged"`
+	Splits []float32     `desc:"splitter proportions for the sub-panes"`
+	Panes  []*PaneLayout `desc:"sub-panes -- if empty, this is a single text view"`
+}
+
 // PrefsSplitsFileName is the name of the preferences file in App prefs
 // directory for saving / loading the default AvailSplits
 var PrefsSplitsFileName = "splits_prefs.json"
@@ -0,0 +1,148 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// SSHCmd is the ssh client run by the SSH terminals -- authentication is
+// that of ssh: keys, the ssh agent, and ~/.ssh/config
+var SSHCmd = "ssh"
+
+// SSHHost is a host for the SSH terminals
+type SSHHost struct {
+	Name         string      `width:"16" desc:"name of the host, shown in the list of hosts and in the tab name"`
+	Host         string      `width:"24" desc:"host name or address -- if empty, the Name, e.g., a Host of ~/.ssh/config"`
+	User         string      `desc:"user to log in as -- if empty, that of ~/.ssh/config, or your user name"`
+	Port         int         `desc:"port -- if 0, that of ~/.ssh/config, or 22"`
+	KeyFile      gi.FileName `desc:"private key file -- if empty, those of ~/.ssh/config and of the ssh agent"`
+	ForwardAgent bool        `desc:"forward the ssh agent, so the keys it holds can be used on the host, e.g., for git"`
+	Dir          string      `width:"24" desc:"directory to start in on the host, e.g., the checkout of the project there -- if empty, the home directory"`
+}
+
+// Label satisfies the Labeler interface
+func (sh SSHHost) Label() string {
+	return sh.Name
+}
+
+// Args returns the args of SSHCmd for a session to the host, in given
+// remote dir, or else the Dir of the host
+func (sh *SSHHost) Args(dir string) []string {
+	args := []string{"-t"}
+	if sh.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(sh.Port))
+	}
+	if sh.KeyFile != "" {
+		args = append(args, "-i", string(sh.KeyFile))
+	}
+	if sh.ForwardAgent {
+		args = append(args, "-A")
+	}
+	host := sh.Host
+	if host == "" {
+		host = sh.Name
+	}
+	if sh.User != "" {
+		host = sh.User + "@" + host
+	}
+	args = append(args, host)
+	if dir == "" {
+		dir = sh.Dir
+	}
+	if dir != "" {
+		args = append(args, "cd "+ShellQuote(dir)+` && exec "${SHELL:-/bin/sh}" -l`)
+	}
+	return args
+}
+
+// SSHHosts is a list of hosts for the SSH terminals
+type SSHHosts []SSHHost
+
+// SSHHostByName returns the host of given name: that of the SSHHosts
+// preferences, or else a Host of ~/.ssh/config, which ssh resolves
+func SSHHostByName(name string) *SSHHost {
+	for i := range Prefs.SSHHosts {
+		if Prefs.SSHHosts[i].Name == name {
+			return &Prefs.SSHHosts[i]
+		}
+	}
+	return &SSHHost{Name: name}
+}
+
+// SSHHostNames returns the names of the hosts for the SSH terminals: those
+// of the SSHHosts preferences, then the Hosts of ~/.ssh/config
+func SSHHostNames() []string {
+	var nms []string
+	has := map[string]bool{}
+	for _, sh := range Prefs.SSHHosts {
+		if sh.Name != "" && !has[sh.Name] {
+			has[sh.Name] = true
+			nms = append(nms, sh.Name)
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nms
+	}
+	for _, nm := range SSHConfigHosts(filepath.Join(home, ".ssh", "config"), 0) {
+		if !has[nm] {
+			has[nm] = true
+			nms = append(nms, nm)
+		}
+	}
+	return nms
+}
+
+// SSHConfigHosts returns the hosts of given ssh config file, and of the
+// files it includes -- Host patterns with wildcards are skipped
+func SSHConfigHosts(fname string, depth int) []string {
+	f, err := os.Open(fname)
+	if err != nil || depth > 8 {
+		return nil
+	}
+	defer f.Close()
+	var nms []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		ln := strings.TrimSpace(sc.Text())
+		if ln == "" || ln[0] == '#' {
+			continue
+		}
+		flds := strings.Fields(strings.Replace(ln, "=", " ", 1))
+		if len(flds) < 2 {
+			continue
+		}
+		switch strings.ToLower(flds[0]) {
+		case "host":
+			for _, nm := range flds[1:] {
+				if !strings.ContainsAny(nm, "*?!") {
+					nms = append(nms, nm)
+				}
+			}
+		case "include":
+			for _, inc := range flds[1:] {
+				if strings.HasPrefix(inc, "~") {
+					if home, err := os.UserHomeDir(); err == nil {
+						inc = filepath.Join(home, inc[1:])
+					}
+				} else if !filepath.IsAbs(inc) {
+					inc = filepath.Join(filepath.Dir(fname), inc)
+				}
+				incs, _ := filepath.Glob(inc)
+				for _, ifn := range incs {
+					nms = append(nms, SSHConfigHosts(ifn, depth+1)...)
+				}
+			}
+		}
+	}
+	return nms
+}
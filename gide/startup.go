@@ -0,0 +1,67 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StartTime is the time at which gide started, approximately
+var StartTime = time.Now()
+
+// StartupTimesEnv is the environment variable which, if set, makes gide
+// print the times of the phases of the startup once a project is loaded,
+// for profiling the startup
+var StartupTimesEnv = "GIDE_STARTUP_TIMES"
+
+// StartupPhase is a phase of the startup and the time since StartTime at
+// which it was done
+type StartupPhase struct {
+	Phase string
+	Time  time.Duration
+}
+
+var (
+	startupPhases   []StartupPhase
+	startupPhasesMu sync.Mutex
+)
+
+// StartupMark records that given phase of the startup is done, only for
+// the first time it is -- returns false if it was already recorded
+func StartupMark(phase string) bool {
+	startupPhasesMu.Lock()
+	defer startupPhasesMu.Unlock()
+	for _, sp := range startupPhases {
+		if sp.Phase == phase {
+			return false
+		}
+	}
+	startupPhases = append(startupPhases, StartupPhase{Phase: phase, Time: time.Since(StartTime)})
+	return true
+}
+
+// StartupReport returns the times of the phases of the startup so far
+func StartupReport() string {
+	startupPhasesMu.Lock()
+	defer startupPhasesMu.Unlock()
+	var b strings.Builder
+	b.WriteString("gide startup:")
+	for _, sp := range startupPhases {
+		b.WriteString(fmt.Sprintf(" %s: %v", sp.Phase, sp.Time.Round(time.Millisecond)))
+	}
+	return b.String()
+}
+
+// StartupDone records the last phase of the startup, printing the report
+// if StartupTimesEnv is set, the first time
+func StartupDone(phase string) {
+	if StartupMark(phase) && os.Getenv(StartupTimesEnv) != "" {
+		fmt.Println(StartupReport())
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"image"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+	"github.com/goki/pi/parse"
+)
+
+// StickyScrollMax is the maximum number of enclosing lines shown in a
+// StickyView -- the innermost ones are shown if there are more
+var StickyScrollMax = 5
+
+// StickyScrollNodes are the prefixes of the names of parse tree nodes whose
+// first line is shown in a StickyView when scrolled inside of them:
+// function, method and type declarations, loops and other block statements
+var StickyScrollNodes = []string{"Func", "Meth", "Type", "For", "Switch", "Select", "If", "Else", "Case", "Default"}
+
+// StickyScroll returns true if sticky scroll of enclosing declarations is
+// set for given language in AvailLangs
+func StickyScroll(sup filecat.Supported) bool {
+	if lopt, has := AvailLangs[sup]; has {
+		return lopt.StickyScroll
+	}
+	return false
+}
+
+// StickyScrollNode returns true if given parse tree node name is one of the
+// StickyScrollNodes
+func StickyScrollNode(nm string) bool {
+	for _, pf := range StickyScrollNodes {
+		if strings.HasPrefix(nm, pf) {
+			return true
+		}
+	}
+	return false
+}
+
+// StickyScrollLines returns the first lines of the StickyScrollNodes in the
+// parse tree of given buffer that start above and enclose given line,
+// outermost first.  Only buffers parsed by pi, for languages with
+// StickyScroll set, have such lines.
+func StickyScrollLines(tb *giv.TextBuf, ln int) []int {
+	if tb == nil || !tb.Hi.UsingPi() || !StickyScroll(tb.Info.Sup) {
+		return nil
+	}
+	fs := tb.PiState.Done()
+	var lns []int
+	ast := &fs.Ast
+	for {
+		var in *parse.Ast
+		for _, k := range ast.Kids {
+			ka, ok := k.(*parse.Ast)
+			if ok && ka.SrcReg.St.Ln <= ln && ln <= ka.SrcReg.Ed.Ln {
+				in = ka
+				break
+			}
+		}
+		if in == nil {
+			break
+		}
+		st := in.SrcReg.St.Ln
+		if st < ln && StickyScrollNode(in.Nm) {
+			if n := len(lns); n == 0 || lns[n-1] != st {
+				lns = append(lns, st)
+			}
+		}
+		ast = in
+	}
+	if len(lns) > StickyScrollMax {
+		lns = lns[len(lns)-StickyScrollMax:]
+	}
+	return lns
+}
+
+// StickyView shows the first (signature) lines of the declarations and
+// blocks enclosing the top visible line of a TextView, so that this context
+// is always visible while scrolling within a long body.  It sits just above
+// the TextView, and clicking on a line moves the cursor there.
+type StickyView struct {
+	gi.WidgetBase
+	View  *TextView `json:"-" xml:"-" view:"-" desc:"the text view whose enclosing lines are shown"`
+	Lines []int     `json:"-" xml:"-" desc:"line numbers currently shown"`
+}
+
+var KiT_StickyView = kit.Types.AddType(&StickyView{}, StickyViewProps)
+
+// AddNewStickyView adds a new sticky view to given parent node, with given name.
+func AddNewStickyView(parent ki.Ki, name string) *StickyView {
+	sv := parent.AddNewChild(KiT_StickyView, name).(*StickyView)
+	sv.SetStretchMaxWidth()
+	return sv
+}
+
+var StickyViewProps = ki.Props{
+	"EnumType:Flag": gi.KiT_NodeFlags,
+	"margin":        0,
+	"padding":       0,
+}
+
+// UpdateLines updates the lines shown for the current top visible line of
+// the View -- the layout enclosing both is re-rendered if the number of
+// lines changed.
+func (sv *StickyView) UpdateLines() {
+	tv := sv.View
+	if tv == nil || tv.Buf == nil || tv.NLines == 0 {
+		sv.SetLines(nil)
+		return
+	}
+	sv.SetLines(StickyScrollLines(tv.Buf, tv.FirstVisibleLine(0)))
+}
+
+// SetLines sets the lines to show, updating the display if they differ
+func (sv *StickyView) SetLines(lns []int) {
+	if len(lns) == len(sv.Lines) {
+		same := true
+		for i, ln := range lns {
+			if sv.Lines[i] != ln {
+				same = false
+				break
+			}
+		}
+		if !same {
+			sv.Lines = lns
+			sv.UpdateSig()
+		}
+		return
+	}
+	sv.Lines = lns
+	if ly := sv.ViewLayout(); ly != nil {
+		updt := ly.UpdateStart()
+		ly.SetFullReRender()
+		ly.UpdateEnd(updt)
+	}
+}
+
+// ViewLayout returns the closest parent layout that also contains the View
+func (sv *StickyView) ViewLayout() *gi.Layout {
+	var ly *gi.Layout
+	sv.FuncUpParent(0, sv.This(), func(k ki.Ki, level int, d interface{}) bool {
+		if sv.View.ParentLevel(k) < 0 {
+			return true
+		}
+		ly, _ = k.Embed(gi.KiT_Layout).(*gi.Layout)
+		return false
+	})
+	return ly
+}
+
+func (sv *StickyView) Size2D(iter int) {
+	sv.InitLayout2D()
+	h := float32(0)
+	if sv.View != nil {
+		h = float32(len(sv.Lines)) * sv.View.LineHeight
+	}
+	sv.Size2DFromWH(0, h)
+}
+
+func (sv *StickyView) Render2D() {
+	if sv.FullReRenderIfNeeded() {
+		return
+	}
+	if sv.PushBounds() {
+		sv.This().(gi.Node2D).ConnectEvents2D()
+		sv.RenderLines()
+		sv.PopBounds()
+	} else {
+		sv.DisconnectAllEvents(gi.RegPri)
+	}
+}
+
+// RenderLines renders the current lines using the rendered lines of the View
+func (sv *StickyView) RenderLines() {
+	tv := sv.View
+	if tv == nil || len(sv.Lines) == 0 {
+		return
+	}
+	rs := sv.Render()
+	pc := &rs.Paint
+	pos := mat32.NewVec2FmPoint(sv.VpBBox.Min)
+	epos := mat32.NewVec2FmPoint(sv.VpBBox.Max)
+	pc.FillBoxColor(rs, pos, epos.Sub(pos), tv.Sty.Font.BgColor.Color.Highlight(10))
+	pos = sv.LayState.Alloc.Pos
+	pos.X += tv.Sty.BoxSpace() + tv.LineNoOff
+	for i, ln := range sv.Lines {
+		if ln >= len(tv.Renders) {
+			continue
+		}
+		lp := pos
+		lp.Y += float32(i) * tv.LineHeight
+		lbb := sv.VpBBox
+		lbb.Min.Y = int(lp.Y)
+		lbb.Max.Y = int(lp.Y + tv.LineHeight) // only first line of wrapped ones
+		rs.PushBounds(lbb.Intersect(sv.VpBBox))
+		tv.Renders[ln].Render(rs, lp)
+		rs.PopBounds()
+	}
+}
+
+// LineAtPos returns the index within Lines at given window position, -1 if none
+func (sv *StickyView) LineAtPos(pos image.Point) int {
+	if sv.View == nil || sv.View.LineHeight == 0 {
+		return -1
+	}
+	i := int(float32(pos.Y-sv.WinBBox.Min.Y) / sv.View.LineHeight)
+	if i < 0 || i >= len(sv.Lines) {
+		return -1
+	}
+	return i
+}
+
+// StickyViewEvents connects clicking on a line to moving the View cursor there
+func (sv *StickyView) StickyViewEvents() {
+	sv.ConnectEvent(oswin.MouseEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		me := d.(*mouse.Event)
+		if me.Button != mouse.Left || me.Action != mouse.Release {
+			return
+		}
+		svv := recv.Embed(KiT_StickyView).(*StickyView)
+		i := svv.LineAtPos(me.Pos())
+		if i < 0 {
+			return
+		}
+		me.SetProcessed()
+		svv.View.SetCursorShow(lex.Pos{Ln: svv.Lines[i]})
+		svv.View.GrabFocus()
+	})
+}
+
+func (sv *StickyView) ConnectEvents2D() {
+	sv.StickyViewEvents()
+}
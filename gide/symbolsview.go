@@ -196,6 +196,7 @@ func (sv *SymbolsView) SelectSymbol(ssym syms.Symbol) {
 			log.Printf("GideView SelectSymbol: OpenFileAtRegion returned false: %v\n", ssym.Filename)
 		}
 	} else {
+		ge.SaveNavPos()
 		tv.UpdateStart()
 		tv.Highlights = tv.Highlights[:0]
 		tr := textbuf.NewRegion(ssym.SelectReg.St.Ln, ssym.SelectReg.St.Ch, ssym.SelectReg.Ed.Ln, ssym.SelectReg.Ed.Ch)
@@ -0,0 +1,119 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SymCacheDirName is the name of the directory within the user cache
+// directory where the index of the contents of each file is cached
+var SymCacheDirName = filepath.Join("gide", "symcache")
+
+// SymCacheMaxAge is the time after which the entries of the cache that
+// have not been used are removed
+var SymCacheMaxAge = 30 * 24 * time.Hour
+
+// SymCacheEntry is the index of the contents of a file in the cache, keyed
+// by the hash of the contents, so that a file is only parsed again if it
+// was changed, e.g., when the index of a project is rebuilt, or a project
+// is moved or cloned elsewhere: its symbols and words (see SymIndexFile)
+type SymCacheEntry struct {
+	Syms  []IndexSym `desc:"symbols declared in the file"`
+	Words string     `desc:"the unique words in the file"`
+}
+
+var symCachePrune sync.Once
+
+// SymCacheDir returns the directory of the cache, "" if there is no user
+// cache directory
+func SymCacheDir() string {
+	cdir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cdir, SymCacheDirName)
+}
+
+// SymCacheHash returns the key of the cache for given contents of a file,
+// and whether its symbols are indexed
+func SymCacheHash(src []byte, syms bool) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "syms:%v\n", syms)
+	h.Write(src)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// symCacheFile returns the file of the cache entry for given hash
+func symCacheFile(cdir, hash string) string {
+	return filepath.Join(cdir, hash[:2], hash+".json")
+}
+
+// OpenSymCache returns the cache entry for given hash, if any, marking it
+// as used
+func OpenSymCache(hash string) (*SymCacheEntry, bool) {
+	cdir := SymCacheDir()
+	if cdir == "" {
+		return nil, false
+	}
+	fnm := symCacheFile(cdir, hash)
+	b, err := ioutil.ReadFile(fnm)
+	if err != nil {
+		return nil, false
+	}
+	ce := &SymCacheEntry{}
+	if err := json.Unmarshal(b, ce); err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(fnm, now, now)
+	return ce, true
+}
+
+// SaveSymCache saves the cache entry for given hash
+func SaveSymCache(hash string, ce *SymCacheEntry) error {
+	cdir := SymCacheDir()
+	if cdir == "" {
+		return nil
+	}
+	b, err := json.Marshal(ce)
+	if err != nil {
+		return err
+	}
+	fnm := symCacheFile(cdir, hash)
+	if err := os.MkdirAll(filepath.Dir(fnm), 0775); err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", fnm, os.Getpid()) // entries can be saved by other gides too
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fnm)
+}
+
+// PruneSymCache removes the entries of the cache not used for SymCacheMaxAge
+func PruneSymCache() {
+	cdir := SymCacheDir()
+	if cdir == "" {
+		return
+	}
+	old := time.Now().Add(-SymCacheMaxAge)
+	filepath.Walk(cdir, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if info.ModTime().Before(old) {
+			os.Remove(pth)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,535 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+)
+
+// SymIndexMaxFiles is the maximum number of files in a project that are
+// indexed in the SymIndex
+var SymIndexMaxFiles = 200000
+
+// SymIndexMaxFileSize is the maximum size of a file that is indexed -- larger
+// files are always searched
+var SymIndexMaxFileSize = int64(2 << 20)
+
+// SymIndexMax is the maximum number of matching symbols shown in
+// SymIndexDialog
+var SymIndexMax = 200
+
+// SymIndexDirName is the name of the directory within the app prefs
+// directory where project SymIndex files are saved
+var SymIndexDirName = "symindex"
+
+// IndexSym is a symbol declared in a file, in the SymIndex
+type IndexSym struct {
+	Name  string `desc:"name of the symbol"`
+	Kind  string `desc:"kind of symbol: func, method, type, const, var"`
+	Owner string `desc:"receiver type for methods"`
+	Line  int    `desc:"line number of the declaration, 0-based"`
+}
+
+// Label returns the label for the symbol shown in SymIndexDialog
+func (sy *IndexSym) Label() string {
+	if sy.Owner != "" {
+		return sy.Owner + "." + sy.Name
+	}
+	return sy.Name
+}
+
+// SymIndexFile is the index of one file in the SymIndex
+type SymIndexFile struct {
+	ModTime time.Time  `desc:"modification time of the file when indexed"`
+	Size    int64      `desc:"size of the file when indexed"`
+	Syms    []IndexSym `desc:"symbols declared in the file (currently only for Go files)"`
+	Words   string     `desc:"all the unique words (runs of letters, digits and _) in the file, lowercase, newline separated"`
+}
+
+// SymIndex is an index of the symbols declared in, and the words used in,
+// all the files of a project, for instant workspace symbol search and for
+// skipping files that cannot contain a find string.  It is built in the
+// background, saved in the app prefs directory, and updated incrementally
+// (only files whose modification time or size have changed are re-read,
+// and only those whose contents are not in the SymCacheEntry cache are
+// parsed) when reopened and when files are saved.
+type SymIndex struct {
+	Root     string                   `desc:"root directory of the project"`
+	Files    map[string]*SymIndexFile `desc:"index for each file, by path relative to Root, using / separators"`
+	Ready    bool                     `json:"-" desc:"true once the index has been loaded or built, and can be used"`
+	Building bool                     `json:"-" desc:"true while the index is being built"`
+	Changed  bool                     `json:"-" desc:"true if changed since last saved"`
+	Mu       sync.RWMutex             `json:"-" view:"-" desc:"mutex protecting the index"`
+}
+
+// IsReady returns true if the index can be used
+func (si *SymIndex) IsReady() bool {
+	si.Mu.RLock()
+	defer si.Mu.RUnlock()
+	return si.Ready
+}
+
+// SymIndexWordChar returns true if given byte is part of an indexed word
+func SymIndexWordChar(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// SymIndexWords returns the unique lowercase words in given text, sorted and
+// newline separated
+func SymIndexWords(src []byte) string {
+	words := make(map[string]struct{})
+	st := -1
+	for i := 0; i <= len(src); i++ {
+		if i < len(src) && SymIndexWordChar(src[i]) {
+			if st < 0 {
+				st = i
+			}
+			continue
+		}
+		if st >= 0 {
+			words[string(bytes.ToLower(src[st:i]))] = struct{}{}
+			st = -1
+		}
+	}
+	wl := make([]string, 0, len(words))
+	for w := range words {
+		wl = append(wl, w)
+	}
+	sort.Strings(wl)
+	return strings.Join(wl, "\n")
+}
+
+// GoIndexSyms returns the top-level declarations in given Go source
+func GoIndexSyms(fname string, src []byte) []IndexSym {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fname, src, parser.SkipObjectResolution)
+	if f == nil {
+		return nil
+	}
+	_ = err // partial results for files with errors
+	var syms []IndexSym
+	add := func(nm *ast.Ident, kind, owner string) {
+		if nm == nil || nm.Name == "_" {
+			return
+		}
+		syms = append(syms, IndexSym{Name: nm.Name, Kind: kind, Owner: owner, Line: fset.Position(nm.Pos()).Line - 1})
+	}
+	for _, d := range f.Decls {
+		switch dd := d.(type) {
+		case *ast.FuncDecl:
+			if dd.Recv == nil || len(dd.Recv.List) == 0 {
+				add(dd.Name, "func", "")
+				continue
+			}
+			rt := dd.Recv.List[0].Type
+			for {
+				switch t := rt.(type) {
+				case *ast.StarExpr:
+					rt = t.X
+					continue
+				case *ast.IndexExpr:
+					rt = t.X
+					continue
+				case *ast.IndexListExpr:
+					rt = t.X
+					continue
+				}
+				break
+			}
+			own := ""
+			if id, ok := rt.(*ast.Ident); ok {
+				own = id.Name
+			}
+			add(dd.Name, "method", own)
+		case *ast.GenDecl:
+			for _, sp := range dd.Specs {
+				switch s := sp.(type) {
+				case *ast.TypeSpec:
+					add(s.Name, "type", "")
+				case *ast.ValueSpec:
+					kind := "var"
+					if dd.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, nm := range s.Names {
+						add(nm, kind, "")
+					}
+				}
+			}
+		}
+	}
+	return syms
+}
+
+// IndexFile returns the index for given file, nil if it should not be
+// indexed (too big, or binary) -- from the cache of the index of file
+// contents if there (see SymCacheEntry), else parsing it, and saving it there
+func IndexFile(fpath string, info os.FileInfo) *SymIndexFile {
+	if info.Size() > SymIndexMaxFileSize {
+		return nil
+	}
+	src, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil
+	}
+	hd := src
+	if len(hd) > 8000 {
+		hd = hd[:8000]
+	}
+	if bytes.IndexByte(hd, 0) >= 0 {
+		return nil
+	}
+	sf := &SymIndexFile{ModTime: info.ModTime(), Size: info.Size()}
+	gosyms := strings.HasSuffix(fpath, ".go")
+	hash := SymCacheHash(src, gosyms)
+	if ce, ok := OpenSymCache(hash); ok {
+		sf.Syms = ce.Syms
+		sf.Words = ce.Words
+		return sf
+	}
+	sf.Words = SymIndexWords(src)
+	if gosyms {
+		sf.Syms = GoIndexSyms(fpath, src)
+	}
+	SaveSymCache(hash, &SymCacheEntry{Syms: sf.Syms, Words: sf.Words})
+	return sf
+}
+
+// SymIndexFileName returns the file name for saving the index of given
+// project root
+func SymIndexFileName(root string) string {
+	h := fnv.New64a()
+	h.Write([]byte(root))
+	return filepath.Join(oswin.TheApp.AppPrefsDir(), SymIndexDirName, fmt.Sprintf("%s-%x.json", filepath.Base(root), h.Sum64()))
+}
+
+// Open opens the saved index for given root, if any -- Update must be
+// called to bring it up to date
+func (si *SymIndex) Open(root string) error {
+	b, err := ioutil.ReadFile(SymIndexFileName(root))
+	if err != nil {
+		return err
+	}
+	nsi := &SymIndex{}
+	if err := json.Unmarshal(b, nsi); err != nil {
+		return err
+	}
+	if nsi.Root != root {
+		return fmt.Errorf("SymIndex: saved index is for: %v not: %v", nsi.Root, root)
+	}
+	si.Mu.Lock()
+	si.Root = root
+	si.Files = nsi.Files
+	si.Ready = true
+	si.Mu.Unlock()
+	return nil
+}
+
+// Save saves the index to SymIndexFileName, if changed
+func (si *SymIndex) Save() error {
+	si.Mu.RLock()
+	if !si.Changed || si.Root == "" {
+		si.Mu.RUnlock()
+		return nil
+	}
+	b, err := json.Marshal(si)
+	root := si.Root
+	si.Mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	fnm := SymIndexFileName(root)
+	os.MkdirAll(filepath.Dir(fnm), 0775)
+	err = ioutil.WriteFile(fnm, b, 0644)
+	if err == nil {
+		si.Mu.Lock()
+		si.Changed = false
+		si.Mu.Unlock()
+	}
+	return err
+}
+
+// Update brings the index for given root up to date, re-indexing files that
+// are new or changed and removing those that no longer exist, then saves
+// it.  Files and directories for which QuickOpenSkip is true given exclude
+// globs, or that are ignored by ign (if non-nil), are not indexed.  Opens the
+// saved index first, if not yet loaded.
+func (si *SymIndex) Update(root string, exclude []string, ign *FileIgnore) {
+	si.Mu.Lock()
+	if si.Building {
+		si.Mu.Unlock()
+		return
+	}
+	si.Building = true
+	loaded := si.Root == root && si.Files != nil
+	si.Mu.Unlock()
+	symCachePrune.Do(PruneSymCache)
+	if !loaded {
+		si.Mu.Lock()
+		si.Root = root
+		si.Files = nil
+		si.Ready = false
+		si.Mu.Unlock()
+		si.Open(root)
+	}
+	si.Mu.RLock()
+	old := si.Files
+	si.Mu.RUnlock()
+	files := make(map[string]*SymIndexFile, len(old))
+	nchg := 0
+	ign.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || pth == root {
+			return nil
+		}
+		rel, rerr := filepath.Rel(root, pth)
+		if rerr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if QuickOpenSkip(rel, info.IsDir(), exclude) || ign.Ignored(pth, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+		if of, has := old[rel]; has && of.Size == info.Size() && of.ModTime.Equal(info.ModTime()) {
+			files[rel] = of
+		} else if sf := IndexFile(pth, info); sf != nil {
+			files[rel] = sf
+			nchg++
+		}
+		if len(files) >= SymIndexMaxFiles {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	si.Mu.Lock()
+	if nchg > 0 || len(files) != len(old) {
+		si.Changed = true
+	}
+	si.Files = files
+	si.Ready = true
+	si.Building = false
+	si.Mu.Unlock()
+	si.Save()
+}
+
+// UpdateFile re-indexes given file (full path), e.g., after it is saved
+func (si *SymIndex) UpdateFile(fpath string) {
+	si.Mu.RLock()
+	root, ready := si.Root, si.Ready
+	si.Mu.RUnlock()
+	if !ready {
+		return
+	}
+	rel, err := filepath.Rel(root, fpath)
+	if err != nil || RelOutside(rel) {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+	var sf *SymIndexFile
+	if info, err := os.Stat(fpath); err == nil && info.Mode().IsRegular() {
+		sf = IndexFile(fpath, info)
+	}
+	si.Mu.Lock()
+	if sf == nil {
+		delete(si.Files, rel)
+	} else {
+		si.Files[rel] = sf
+	}
+	si.Changed = true
+	si.Mu.Unlock()
+}
+
+// MayContain returns false only if the indexed file at given path (relative
+// to Root), unchanged since given modification time, cannot contain given
+// lowercase string of word chars (see FindFilter)
+func (si *SymIndex) MayContain(rel string, mod time.Time, lfind string) bool {
+	sf, has := si.Files[rel]
+	if !has || !sf.ModTime.Equal(mod) {
+		return true
+	}
+	return strings.Contains(sf.Words, lfind)
+}
+
+// FindFilter returns a function for FileTreeSearch that returns false for
+// files that the index shows cannot contain given find string (in any
+// case), or nil if the index cannot be used: it is not ready, or the find
+// string has characters other than letters, digits and _, and so could
+// span words.  Files that have unsaved changes, or that have changed or
+// are not in the index, are always searched.
+func (si *SymIndex) FindFilter(find string) func(sfn *giv.FileNode) bool {
+	if !si.IsReady() || find == "" {
+		return nil
+	}
+	for i := 0; i < len(find); i++ {
+		if !SymIndexWordChar(find[i]) {
+			return nil
+		}
+	}
+	lfind := strings.ToLower(find)
+	return func(sfn *giv.FileNode) bool {
+		if sfn.Buf != nil && sfn.Buf.IsChanged() {
+			return true
+		}
+		rel, err := filepath.Rel(si.Root, string(sfn.FPath))
+		if err != nil {
+			return true
+		}
+		si.Mu.RLock()
+		defer si.Mu.RUnlock()
+		return si.MayContain(filepath.ToSlash(rel), time.Time(sfn.Info.ModTime), lfind)
+	}
+}
+
+// SymIndexMatch is a symbol matching a pattern in SymIndex.Match
+type SymIndexMatch struct {
+	File string   `desc:"file declaring the symbol, relative to Root"`
+	Sym  IndexSym `desc:"the symbol"`
+}
+
+// Match returns the symbols fuzzy matching given pattern (see FuzzyMatch),
+// best first, up to SymIndexMax
+func (si *SymIndex) Match(pat string) []SymIndexMatch {
+	pat = strings.Replace(pat, " ", "", -1)
+	type match struct {
+		SymIndexMatch
+		Score int
+	}
+	var ms []match
+	si.Mu.RLock()
+	for fn, sf := range si.Files {
+		for _, sy := range sf.Syms {
+			sc, ok := FuzzyMatch(pat, sy.Label())
+			if !ok {
+				continue
+			}
+			if strings.EqualFold(pat, sy.Name) {
+				sc += 20
+			}
+			ms = append(ms, match{SymIndexMatch{fn, sy}, sc})
+		}
+	}
+	si.Mu.RUnlock()
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].Score != ms[j].Score {
+			return ms[i].Score > ms[j].Score
+		}
+		if ms[i].File != ms[j].File {
+			return ms[i].File < ms[j].File
+		}
+		return ms[i].Sym.Line < ms[j].Sym.Line
+	})
+	if len(ms) > SymIndexMax {
+		ms = ms[:SymIndexMax]
+	}
+	res := make([]SymIndexMatch, len(ms))
+	for i, m := range ms {
+		res[i] = m.SymIndexMatch
+	}
+	return res
+}
+
+// SymIndexDialog opens a dialog for finding a symbol declared anywhere in
+// the project in given SymIndex: the list of matches is updated as the
+// pattern is typed, and return goes to the selected symbol, or the best
+// match if none is selected.  Use SymIndexDialogValue to get the symbol
+// chosen.
+func SymIndexDialog(avp *gi.Viewport2D, si *SymIndex, opts giv.DlgOpts, recv ki.Ki, dlgFunc ki.RecvFunc) *gi.Dialog {
+	dlg := gi.NewStdDialog(opts.ToGiOpts(), gi.AddOk, gi.AddCancel)
+	dlg.Modal = true
+
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+	tf := frame.InsertNewChild(gi.KiT_TextField, prIdx+1, "pattern").(*gi.TextField)
+	tf.Placeholder = "type any part of the symbol name.."
+	tf.SetStretchMaxWidth()
+	tf.SetMinPrefWidth(units.NewCh(60))
+
+	var matches []SymIndexMatch
+	var labels []string
+	update := func(pat string) {
+		matches = si.Match(pat)
+		labels = make([]string, len(matches))
+		for i, m := range matches {
+			labels[i] = fmt.Sprintf("%v\t%v\t%v:%v", m.Sym.Label(), m.Sym.Kind, m.File, m.Sym.Line+1)
+		}
+	}
+	update("")
+	sv := frame.InsertNewChild(giv.KiT_SliceView, prIdx+2, "matches").(*giv.SliceView)
+	sv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	sv.SetInactiveState(true)
+	sv.SetProp("index", false)
+	sv.SetStretchMaxWidth()
+	sv.SetMinPrefHeight(units.NewEm(20))
+	sv.SetSlice(&labels)
+	dlg.SetProp("matches", &matches)
+
+	tf.TextFieldSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		switch sig {
+		case int64(gi.TextFieldInsert), int64(gi.TextFieldBackspace), int64(gi.TextFieldDelete), int64(gi.TextFieldCleared):
+			update(string(tf.EditTxt)) // not Text(), which ends the edit
+			sv.SelectedIdx = -1
+			sv.ResetSelectedIdxs()
+			sv.SetSlice(&labels)
+		case int64(gi.TextFieldDone):
+			ddlg := recv.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			ddlg.Accept()
+		}
+	})
+	sv.SliceViewSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.SliceViewDoubleClicked) {
+			ddlg := recv.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			ddlg.Accept()
+		}
+	})
+
+	if recv != nil && dlgFunc != nil {
+		dlg.DialogSig.Connect(recv, dlgFunc)
+	}
+	dlg.UpdateEndNoSig(true)
+	dlg.Open(0, 0, avp, func() {
+		tf.GrabFocus()
+	})
+	return dlg
+}
+
+// SymIndexDialogValue returns the full path of the file and the line of the
+// symbol chosen in given SymIndexDialog -- false if there are no matches
+func SymIndexDialogValue(dlg *gi.Dialog, si *SymIndex) (string, int, bool) {
+	mp, ok := dlg.Prop("matches").(*[]SymIndexMatch)
+	if !ok || len(*mp) == 0 {
+		return "", 0, false
+	}
+	matches := *mp
+	idx := 0
+	if sv, ok := dlg.Frame().ChildByName("matches", 0).(*giv.SliceView); ok && sv.SelectedIdx >= 0 && sv.SelectedIdx < len(matches) {
+		idx = sv.SelectedIdx
+	}
+	m := matches[idx]
+	return filepath.Join(si.Root, filepath.FromSlash(m.File)), m.Sym.Line, true
+}
@@ -0,0 +1,398 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/histyle"
+	"github.com/goki/gi/oswin"
+)
+
+// SyncPrefs are the preferences for syncing the settings across machines
+type SyncPrefs struct {
+	Dir  string `desc:"directory the settings are synced with, e.g., a clone of a git repository (pulled and pushed, if it has an upstream) or a folder of Dropbox -- empty for no syncing"`
+	Auto bool   `desc:"pull the settings at startup, and push them when they are saved -- otherwise only with Sync Now"`
+}
+
+// On returns true if the settings are synced
+func (sp *SyncPrefs) On() bool {
+	return sp.Dir != ""
+}
+
+// IsGit returns true if the sync directory is in a git repository
+func (sp *SyncPrefs) IsGit() bool {
+	out, err := exec.Command("git", "-C", sp.Dir, "rev-parse", "--is-inside-work-tree").Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// SyncPrefsFileName is the name of the file in the preferences directory
+// of the SyncPrefs, which are specific to each machine, so not synced
+var SyncPrefsFileName = "sync_prefs.json"
+
+// OpenPrefs opens the SyncPrefs from the preferences directory
+func (sp *SyncPrefs) OpenPrefs() error {
+	b, err := ioutil.ReadFile(filepath.Join(oswin.TheApp.AppPrefsDir(), SyncPrefsFileName))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, sp)
+}
+
+// SavePrefs saves the SyncPrefs to the preferences directory
+func (sp *SyncPrefs) SavePrefs() error {
+	b, err := json.MarshalIndent(sp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(oswin.TheApp.AppPrefsDir(), SyncPrefsFileName), b, 0644)
+}
+
+// SyncFiles are the files of the preferences directory that are synced:
+// the preferences, custom commands, keymaps, language options, the
+// highlighting styles (color themes), splits and macros
+var SyncFiles = []string{PrefsFileName, PrefsCmdsFileName, PrefsKeyMapsFileName, PrefsLangsFileName, histyle.PrefsStylesFileName, PrefsSplitsFileName, PrefsMacrosFileName}
+
+// SyncStateFileName is the name of the file in the preferences directory
+// recording the synced state of the SyncFiles
+var SyncStateFileName = "sync_state.json"
+
+// SyncState is the state of the last sync: the hashes of the SyncFiles
+// when they were last the same locally and in the sync directory, and the
+// files changed in both since then (not synced until resolved)
+type SyncState struct {
+	Hashes    map[string]string
+	Conflicts []string
+}
+
+// syncMu makes the syncs sequential
+var syncMu sync.Mutex
+
+// syncTimer is the timer of the push after the settings are saved
+var syncTimer *time.Timer
+
+// syncTimerMu protects syncTimer
+var syncTimerMu sync.Mutex
+
+// syncHash returns the hash of the file of given path, empty if it does not exist
+func syncHash(fpath string) string {
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return ""
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// syncCopy copies file from to file to
+func syncCopy(from, to string) error {
+	b, err := ioutil.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(to, b, 0644)
+}
+
+// openSyncState opens the state of the last sync, in preferences directory pdir
+func openSyncState(pdir string) *SyncState {
+	st := &SyncState{}
+	b, err := ioutil.ReadFile(filepath.Join(pdir, SyncStateFileName))
+	if err == nil {
+		json.Unmarshal(b, st)
+	}
+	if st.Hashes == nil {
+		st.Hashes = map[string]string{}
+	}
+	return st
+}
+
+// save saves the state of the last sync, in preferences directory pdir
+func (st *SyncState) save(pdir string) error {
+	sort.Strings(st.Conflicts)
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(pdir, SyncStateFileName), b, 0644)
+}
+
+// isConflict returns true if given file is in conflict
+func (st *SyncState) isConflict(fn string) bool {
+	for _, cf := range st.Conflicts {
+		if cf == fn {
+			return true
+		}
+	}
+	return false
+}
+
+// syncGit runs git with given args in the sync directory
+func (sp *SyncPrefs) syncGit(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", sp.Dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		err = fmt.Errorf("git %v: %v: %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+	return string(out), err
+}
+
+// hasUpstream returns true if the branch of the git repository of the
+// sync directory has an upstream, to pull from and push to
+func (sp *SyncPrefs) hasUpstream() bool {
+	_, err := sp.syncGit("rev-parse", "--abbrev-ref", "@{u}")
+	return err == nil
+}
+
+// Pull updates the settings in the preferences directory from the sync
+// directory (pulling its git repository first): files changed only there
+// are copied, those changed in both since the last sync are conflicts, left
+// as they are -- returns the updated files, and the conflicts
+func (sp *SyncPrefs) Pull() (upd, confs []string, err error) {
+	return sp.pull(oswin.TheApp.AppPrefsDir())
+}
+
+// pull pulls the settings of preferences directory pdir
+func (sp *SyncPrefs) pull(pdir string) (upd, confs []string, err error) {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	if !sp.On() {
+		return nil, nil, nil
+	}
+	if err = os.MkdirAll(sp.Dir, 0755); err != nil {
+		return nil, nil, err
+	}
+	if sp.IsGit() && sp.hasUpstream() {
+		if _, err = sp.syncGit("pull", "--ff-only"); err != nil {
+			return nil, nil, err
+		}
+	}
+	st := openSyncState(pdir)
+	for _, fn := range SyncFiles {
+		lp, rp := filepath.Join(pdir, fn), filepath.Join(sp.Dir, fn)
+		lh, rh, bh := syncHash(lp), syncHash(rp), st.Hashes[fn]
+		switch {
+		case lh == rh:
+			st.Hashes[fn] = lh
+		case rh == "" || rh == bh: // changed only locally: pushed
+		case lh == bh || lh == "": // changed only remotely
+			if err = syncCopy(rp, lp); err != nil {
+				return upd, st.Conflicts, err
+			}
+			st.Hashes[fn] = rh
+			upd = append(upd, fn)
+		default:
+			if !st.isConflict(fn) {
+				st.Conflicts = append(st.Conflicts, fn)
+			}
+		}
+		if lh == rh && st.isConflict(fn) {
+			st.resolved(fn)
+		}
+	}
+	return upd, st.Conflicts, st.save(pdir)
+}
+
+// resolved removes given file from the conflicts
+func (st *SyncState) resolved(fn string) {
+	for i, cf := range st.Conflicts {
+		if cf == fn {
+			st.Conflicts = append(st.Conflicts[:i], st.Conflicts[i+1:]...)
+			return
+		}
+	}
+}
+
+// Push copies the settings changed in the preferences directory since the
+// last sync to the sync directory (committing and pushing them to its git
+// repository), except for the conflicts -- returns the pushed files
+func (sp *SyncPrefs) Push() (pushed []string, err error) {
+	return sp.push(oswin.TheApp.AppPrefsDir())
+}
+
+// push pushes the settings of preferences directory pdir
+func (sp *SyncPrefs) push(pdir string) (pushed []string, err error) {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	if !sp.On() {
+		return nil, nil
+	}
+	if err = os.MkdirAll(sp.Dir, 0755); err != nil {
+		return nil, err
+	}
+	st := openSyncState(pdir)
+	for _, fn := range SyncFiles {
+		if st.isConflict(fn) {
+			continue
+		}
+		lp, rp := filepath.Join(pdir, fn), filepath.Join(sp.Dir, fn)
+		lh, rh := syncHash(lp), syncHash(rp)
+		if lh == "" || lh == rh {
+			continue
+		}
+		if bh := st.Hashes[fn]; rh != "" && rh != bh { // changed remotely: pulled first
+			if lh != bh {
+				st.Conflicts = append(st.Conflicts, fn)
+			}
+			continue
+		}
+		if err = syncCopy(lp, rp); err != nil {
+			return pushed, err
+		}
+		st.Hashes[fn] = lh
+		pushed = append(pushed, fn)
+	}
+	if err = st.save(pdir); err != nil {
+		return pushed, err
+	}
+	if len(pushed) == 0 || !sp.IsGit() {
+		return pushed, nil
+	}
+	if _, err = sp.syncGit(append([]string{"add", "--"}, pushed...)...); err != nil {
+		return pushed, err
+	}
+	host, _ := os.Hostname()
+	if _, err = sp.syncGit("commit", "-m", "gide settings from "+host); err != nil {
+		return pushed, err
+	}
+	if sp.hasUpstream() {
+		_, err = sp.syncGit("push")
+	}
+	return pushed, err
+}
+
+// Resolve resolves the sync conflicts, by taking the settings of the sync
+// directory if remote, and otherwise pushing the local ones
+func (sp *SyncPrefs) Resolve(remote bool) error {
+	pdir := oswin.TheApp.AppPrefsDir()
+	if err := sp.resolve(pdir, remote); err != nil {
+		return err
+	}
+	if remote {
+		Prefs.Reopen()
+		return nil
+	}
+	_, err := sp.push(pdir)
+	return err
+}
+
+// resolve resolves the sync conflicts of preferences directory pdir, by
+// copying the settings of the sync directory if remote, and otherwise
+// making the local ones count as the only change, for pushing them
+func (sp *SyncPrefs) resolve(pdir string, remote bool) error {
+	syncMu.Lock()
+	defer syncMu.Unlock()
+	st := openSyncState(pdir)
+	var err error
+	for _, fn := range st.Conflicts {
+		lp, rp := filepath.Join(pdir, fn), filepath.Join(sp.Dir, fn)
+		if remote {
+			err = syncCopy(rp, lp)
+			st.Hashes[fn] = syncHash(rp)
+		} else {
+			st.Hashes[fn] = syncHash(rp) // local then counts as the only change
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		st.Conflicts = nil
+	}
+	serr := st.save(pdir)
+	if err != nil {
+		return err
+	}
+	return serr
+}
+
+// SyncPrefsSaved pushes the settings after they are saved, if Auto sync is
+// on -- after a delay, so the saves of several settings are pushed together
+func SyncPrefsSaved() {
+	if !Prefs.Sync.On() || !Prefs.Sync.Auto {
+		return
+	}
+	syncTimerMu.Lock()
+	defer syncTimerMu.Unlock()
+	if syncTimer != nil {
+		syncTimer.Stop()
+	}
+	syncTimer = time.AfterFunc(2*time.Second, func() {
+		if _, err := Prefs.Sync.Push(); err != nil {
+			log.Printf("gide settings sync: %v\n", err)
+		}
+	})
+}
+
+// SyncPrefsAtStart pulls the settings at startup, if Auto sync is on,
+// reopening them if updated
+func SyncPrefsAtStart() {
+	if !Prefs.Sync.On() || !Prefs.Sync.Auto {
+		return
+	}
+	upd, confs, err := Prefs.Sync.Pull()
+	if err != nil {
+		log.Printf("gide settings sync: %v\n", err)
+	}
+	if len(confs) > 0 {
+		log.Printf("gide settings sync: changed both here and in %v (use Sync Resolve in the preferences): %v\n", Prefs.Sync.Dir, strings.Join(confs, ", "))
+	}
+	if len(upd) > 0 {
+		Prefs.Reopen()
+	}
+}
+
+// Reopen reopens the preferences and the other settings, e.g., after
+// they were updated by a sync
+func (pf *Preferences) Reopen() {
+	pf.Open()
+	if histyle.AvailStyles != nil {
+		histyle.CustomStyles.OpenPrefs()
+		histyle.MergeAvailStyles()
+	}
+}
+
+// SyncNow pulls the settings from the sync directory, and pushes the ones
+// changed here, reporting the updates and the conflicts
+func (pf *Preferences) SyncNow() string {
+	if !pf.Sync.On() {
+		return "no sync directory set: see Sync in the preferences"
+	}
+	upd, confs, err := pf.Sync.Pull()
+	if err != nil {
+		return fmt.Sprintf("Sync failed: %v", err)
+	}
+	if len(upd) > 0 {
+		pf.Reopen()
+	}
+	pushed, err := pf.Sync.Push()
+	if err != nil {
+		return fmt.Sprintf("Sync push failed: %v", err)
+	}
+	msg := fmt.Sprintf("Synced with %v -- updated: %v, pushed: %v", pf.Sync.Dir, strings.Join(upd, ", "), strings.Join(pushed, ", "))
+	if len(confs) > 0 {
+		msg += fmt.Sprintf(" -- changed both here and there, not synced (use Sync Resolve): %v", strings.Join(confs, ", "))
+	}
+	return msg
+}
+
+// SyncResolve resolves the conflicts of the settings sync: the settings of
+// the sync directory are taken if Use Remote, otherwise the local ones are
+// pushed
+func (pf *Preferences) SyncResolve(useRemote bool) error {
+	return pf.Sync.Resolve(useRemote)
+}
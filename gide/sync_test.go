@@ -0,0 +1,110 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyncPrefs(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "gide-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	pdir := filepath.Join(tmp, "prefs")
+	os.MkdirAll(pdir, 0755)
+	sp := &SyncPrefs{Dir: filepath.Join(tmp, "sync")}
+	pf, cf := PrefsFileName, PrefsCmdsFileName
+	// each step writes the local and remote files given (fn=text), then
+	// runs op: pull, push, local (resolve with the local files, and push)
+	// or remote (resolve with the remote files) -- files are the files
+	// updated by pull or pushed by push, then the conflicts and contents of
+	// both directories (as fn=local/remote) are checked
+	tests := []struct {
+		name   string
+		local  string
+		remote string
+		op     string
+		files  string
+		confs  string
+		state  string
+	}{
+		{"first push", pf + "=1", "", "push", pf, "", pf + "=1/1"},
+		{"nothing to pull", "", "", "pull", "", "", pf + "=1/1"},
+		{"changed remotely", "", pf + "=2", "pull", pf, "", pf + "=2/2"},
+		{"changed locally", pf + "=3", "", "push", pf, "", pf + "=3/3"},
+		{"changed locally not pulled", pf + "=4", "", "pull", "", "", pf + "=4/3"},
+		{"pushed later", "", "", "push", pf, "", pf + "=4/4"},
+		{"new remotely", "", cf + "=a", "pull", cf, "", pf + "=4/4 " + cf + "=a/a"},
+		{"changed in both", pf + "=L", pf + "=R", "pull", "", pf, pf + "=L/R " + cf + "=a/a"},
+		{"conflict not pushed", cf + "=b", "", "push", cf, pf, pf + "=L/R " + cf + "=b/b"},
+		{"conflict not pulled", "", "", "pull", "", pf, pf + "=L/R " + cf + "=b/b"},
+		{"resolve local", "", "", "local", pf, "", pf + "=L/L " + cf + "=b/b"},
+		{"changed in both again", pf + "=L2", pf + "=R2", "push", "", pf, pf + "=L2/R2 " + cf + "=b/b"},
+		{"resolve remote", "", "", "remote", "", "", pf + "=R2/R2 " + cf + "=b/b"},
+		{"in sync after resolve", "", "", "pull", "", "", pf + "=R2/R2 " + cf + "=b/b"},
+		{"same change in both", pf + "=S", pf + "=S", "pull", "", "", pf + "=S/S " + cf + "=b/b"},
+		{"conflict", pf + "=X", pf + "=Y", "pull", "", pf, pf + "=X/Y " + cf + "=b/b"},
+		{"conflict made the same", pf + "=Y", "", "pull", "", "", pf + "=Y/Y " + cf + "=b/b"},
+		{"in sync after the same", "", pf + "=Z", "pull", pf, "", pf + "=Z/Z " + cf + "=b/b"},
+	}
+	write := func(dir, files string) {
+		for _, fs := range strings.Fields(files) {
+			kv := strings.SplitN(fs, "=", 2)
+			if err := ioutil.WriteFile(filepath.Join(dir, kv[0]), []byte(kv[1]), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	read := func(fn string) string {
+		lb, _ := ioutil.ReadFile(filepath.Join(pdir, fn))
+		rb, _ := ioutil.ReadFile(filepath.Join(sp.Dir, fn))
+		return string(lb) + "/" + string(rb)
+	}
+	os.MkdirAll(sp.Dir, 0755)
+	for _, tst := range tests {
+		write(pdir, tst.local)
+		write(sp.Dir, tst.remote)
+		var files, confs []string
+		var err error
+		switch tst.op {
+		case "pull":
+			files, confs, err = sp.pull(pdir)
+		case "push":
+			files, err = sp.push(pdir)
+		case "local":
+			if err = sp.resolve(pdir, false); err == nil {
+				files, err = sp.push(pdir)
+			}
+		case "remote":
+			err = sp.resolve(pdir, true)
+		}
+		if err != nil {
+			t.Errorf("SyncPrefs error: %v: %v\n", tst.name, err)
+			continue
+		}
+		if tst.op != "pull" {
+			confs = openSyncState(pdir).Conflicts
+		}
+		if wf := strings.Fields(tst.files); !reflect.DeepEqual(files, wf) && !(len(files) == 0 && len(wf) == 0) {
+			t.Errorf("SyncPrefs error: %v: %v files should have been: %v  was: %v\n", tst.name, tst.op, wf, files)
+		}
+		if wc := strings.Fields(tst.confs); !reflect.DeepEqual(confs, wc) && !(len(confs) == 0 && len(wc) == 0) {
+			t.Errorf("SyncPrefs error: %v: conflicts should have been: %v  was: %v\n", tst.name, wc, confs)
+		}
+		for _, fs := range strings.Fields(tst.state) {
+			kv := strings.SplitN(fs, "=", 2)
+			if lr := read(kv[0]); lr != kv[1] {
+				t.Errorf("SyncPrefs error: %v: %v local/remote should have been: %v  was: %v\n", tst.name, kv[0], kv[1], lr)
+			}
+		}
+	}
+}
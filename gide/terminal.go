@@ -0,0 +1,265 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+// TermShell returns the shell run by the terminals: that of the Shell
+// preferences of the OS
+func TermShell() string {
+	return Prefs.Shell.Cur().ShellPath()
+}
+
+// TermEnv returns the environment of the terminals of given project: that
+// of its commands, with the Env of the Shell preferences, TERM, and GIDE_FILE, GIDE_DIR and GIDE_PROJ set to
+// the active file, its directory, and the project root
+func TermEnv(ge Gide) []string {
+	pf := ge.ProjPrefs()
+	env := append(pf.CmdEnv(), Prefs.Shell.Cur().EnvList()...)
+	env = append(env, "TERM=xterm-256color", "COLORTERM=truecolor")
+	env = append(env, "GIDE_PROJ="+string(pf.ProjRoot))
+	fpath := ""
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+		fpath = string(tv.Buf.Filename)
+	}
+	dir := ""
+	if fpath != "" {
+		dir = filepath.Dir(fpath)
+	}
+	return append(env, "GIDE_FILE="+fpath, "GIDE_DIR="+dir)
+}
+
+// Term is a terminal session: a shell running in a pseudo-terminal, and
+// the screen its output is written to
+type Term struct {
+	Name    string             `desc:"name of the terminal, also that of its tab and of the shell in the CmdRuns of the project"`
+	Shell   string             `desc:"the shell command"`
+	Host    string             `desc:"if set, the terminal is an SSH session to the host of this name (see SSHHostByName), instead of a local shell"`
+	Dir     string             `desc:"directory the shell was started in -- on the host, for SSH sessions"`
+	Screen  *TermScreen        `json:"-" xml:"-" desc:"the screen"`
+	Pty     io.ReadWriteCloser `json:"-" xml:"-" view:"-" desc:"the master end of the pseudo-terminal"`
+	Cmd     *exec.Cmd          `json:"-" xml:"-" view:"-" desc:"the running shell"`
+	Running bool               `desc:"true while the shell is running"`
+	Changed func()             `json:"-" xml:"-" view:"-" desc:"called, from the reading goroutine, when the screen changed"`
+	Mu      sync.Mutex         `json:"-" xml:"-" view:"-" desc:"mutex protecting the session"`
+}
+
+// Start starts the shell of the terminal in given dir, with given
+// environment, on a screen of given size -- closing the running one if any
+// -- the shell is that of the Shell preferences, as a login shell if set
+// there, and sourcing its RcFile -- for SSH sessions, it is SSHCmd, with
+// dir on the host, started in the project root
+func (tm *Term) Start(ge Gide, dir string, env []string, rows, cols int) error {
+	tm.Kill()
+	sp := Prefs.Shell.Cur()
+	var cmd *exec.Cmd
+	src := ""
+	if tm.Host != "" {
+		tm.Shell = SSHCmd + " " + tm.Host
+		cmd = exec.Command(SSHCmd, SSHHostByName(tm.Host).Args(dir)...)
+		cmd.Dir = string(ge.ProjPrefs().ProjRoot)
+	} else {
+		tm.Shell = sp.ShellPath()
+		cmd = exec.Command(tm.Shell, sp.LoginArgs()...)
+		cmd.Dir = dir
+		src = sp.SourceCmd()
+	}
+	cmd.Env = env
+	if tm.Screen == nil {
+		tm.Screen = NewTermScreen(rows, cols)
+	} else {
+		tm.Screen.Mu.Lock()
+		tm.Screen.Resize(rows, cols)
+		tm.Screen.Mu.Unlock()
+	}
+	pty, err := StartPty(cmd, rows, cols)
+	if err != nil {
+		return err
+	}
+	tm.Mu.Lock()
+	tm.Dir = dir
+	tm.Pty = pty
+	tm.Cmd = cmd
+	tm.Running = true
+	tm.Mu.Unlock()
+	ge.CmdRuns().DeleteByName(tm.Name)
+	ge.CmdRuns().AddCmd(tm.Name, tm.Shell, &CmdAndArgs{Cmd: tm.Shell}, cmd)
+	go tm.Read(ge, cmd, pty)
+	if src != "" {
+		pty.Write([]byte(src + "\r"))
+	}
+	return nil
+}
+
+// Read reads the output of the shell to the screen until it exits
+func (tm *Term) Read(ge Gide, cmd *exec.Cmd, pty io.ReadWriteCloser) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := pty.Read(buf)
+		if n > 0 {
+			sc := tm.Screen
+			sc.Mu.Lock()
+			sc.Write(buf[:n])
+			reply := sc.Reply
+			sc.Reply = nil
+			sc.Mu.Unlock()
+			if len(reply) > 0 {
+				pty.Write(reply)
+			}
+			tm.Notify()
+		}
+		if err != nil {
+			break
+		}
+	}
+	werr := cmd.Wait()
+	tm.Mu.Lock()
+	cur := tm.Cmd == cmd
+	if cur {
+		tm.Running = false
+		tm.Cmd = nil
+		tm.Pty = nil
+	}
+	tm.Mu.Unlock()
+	pty.Close()
+	if !cur {
+		return
+	}
+	ge.CmdRuns().DeleteByName(tm.Name)
+	msg := "[process exited]"
+	if werr != nil {
+		msg = fmt.Sprintf("[process exited: %v]", werr)
+	}
+	sc := tm.Screen
+	sc.Mu.Lock()
+	sc.Write([]byte("\r\n\x1b[1m" + msg + " -- press Enter to restart\x1b[0m\r\n"))
+	sc.Mu.Unlock()
+	tm.Notify()
+}
+
+// Cwd returns the current working directory of the shell: as reported by
+// the OS, or by the shell with OSC 7, or else the dir it was started in --
+// for SSH sessions, that on the host
+func (tm *Term) Cwd() string {
+	tm.Mu.Lock()
+	cmd, dir := tm.Cmd, tm.Dir
+	tm.Mu.Unlock()
+	if tm.Host == "" && cmd != nil && cmd.Process != nil {
+		if cwd := TermProcCwd(cmd.Process.Pid); cwd != "" {
+			return cwd
+		}
+	}
+	if sc := tm.Screen; sc != nil {
+		sc.Mu.Lock()
+		cwd := sc.Cwd
+		sc.Mu.Unlock()
+		if cwd != "" {
+			return cwd
+		}
+	}
+	return dir
+}
+
+// SetName renames the terminal, and its shell in the CmdRuns of the project
+func (tm *Term) SetName(ge Gide, name string) {
+	tm.Mu.Lock()
+	old, cmd := tm.Name, tm.Cmd
+	tm.Name = name
+	tm.Mu.Unlock()
+	if cmd == nil {
+		return
+	}
+	ge.CmdRuns().DeleteByName(old)
+	ge.CmdRuns().AddCmd(name, tm.Shell, &CmdAndArgs{Cmd: tm.Shell}, cmd)
+}
+
+// Notify calls the Changed func, if set
+func (tm *Term) Notify() {
+	tm.Mu.Lock()
+	ch := tm.Changed
+	tm.Mu.Unlock()
+	if ch != nil {
+		ch()
+	}
+}
+
+// IsRunning returns true if the shell is running
+func (tm *Term) IsRunning() bool {
+	tm.Mu.Lock()
+	defer tm.Mu.Unlock()
+	return tm.Running
+}
+
+// Send writes given input to the shell, as if typed
+func (tm *Term) Send(b []byte) error {
+	tm.Mu.Lock()
+	pty := tm.Pty
+	tm.Mu.Unlock()
+	if pty == nil {
+		return fmt.Errorf("terminal %v: shell is not running", tm.Name)
+	}
+	_, err := pty.Write(b)
+	return err
+}
+
+// Paste sends given text to the shell as pasted, bracketed if the
+// programs of the terminal asked for it
+func (tm *Term) Paste(txt []byte) error {
+	if tm.Screen != nil {
+		tm.Screen.Mu.Lock()
+		br := tm.Screen.BracketPaste
+		tm.Screen.Mu.Unlock()
+		if br {
+			txt = append(append([]byte("\x1b[200~"), txt...), "\x1b[201~"...)
+		}
+	}
+	return tm.Send(txt)
+}
+
+// Resize sets the size of the screen and of the pseudo-terminal, if
+// changed
+func (tm *Term) Resize(rows, cols int) {
+	sc := tm.Screen
+	if sc == nil || rows < 1 || cols < 1 {
+		return
+	}
+	sc.Mu.Lock()
+	same := sc.Rows == rows && sc.Cols == cols
+	if !same {
+		sc.Resize(rows, cols)
+	}
+	sc.Mu.Unlock()
+	if same {
+		return
+	}
+	tm.Mu.Lock()
+	pty := tm.Pty
+	tm.Mu.Unlock()
+	if pty != nil {
+		SetPtySize(pty, rows, cols)
+	}
+}
+
+// Kill kills the shell, if running
+func (tm *Term) Kill() {
+	tm.Mu.Lock()
+	cmd, pty := tm.Cmd, tm.Pty
+	tm.Cmd = nil
+	tm.Pty = nil
+	tm.Running = false
+	tm.Mu.Unlock()
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+	if pty != nil {
+		pty.Close()
+	}
+}
@@ -0,0 +1,801 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// TermScrollback is the max number of lines scrolled off the top of a
+// terminal kept in its history
+var TermScrollback = 1000
+
+// TermColors are the 16 standard ANSI colors of terminals: the 8 normal
+// ones, then the 8 bright ones
+var TermColors = []string{
+	"#000000", "#cd3131", "#0dbc79", "#e5e510", "#2472c8", "#bc3fbc", "#11a8cd", "#e5e5e5",
+	"#666666", "#f14c4c", "#23d18b", "#f5f543", "#3b8eea", "#d670d6", "#29b8db", "#ffffff",
+}
+
+// TermAttr is the display attributes of a cell of a terminal screen --
+// colors are "" for the default ones
+type TermAttr struct {
+	Fg        string
+	Bg        string
+	Bold      bool
+	Italic    bool
+	Underline bool
+	Reverse   bool
+}
+
+// TermCell is one character cell of a terminal screen
+type TermCell struct {
+	Ch   rune
+	Attr TermAttr
+}
+
+// TermLine is a line of cells of a terminal screen
+type TermLine []TermCell
+
+// Len returns the length of the line without its trailing blanks, other
+// than those with a background color
+func (tl TermLine) Len() int {
+	n := len(tl)
+	for n > 0 && (tl[n-1].Ch == 0 || tl[n-1].Ch == ' ') && tl[n-1].Attr.Bg == "" && !tl[n-1].Attr.Reverse {
+		n--
+	}
+	return n
+}
+
+// String returns the text of the line, up to its Len
+func (tl TermLine) String() string {
+	n := tl.Len()
+	rs := make([]rune, n)
+	for i, c := range tl[:n] {
+		rs[i] = c.Ch
+		if rs[i] == 0 {
+			rs[i] = ' '
+		}
+	}
+	return string(rs)
+}
+
+// Markup returns the line up to its Len as html, with spans for the colors
+// and styles
+func (tl TermLine) Markup() string {
+	n := tl.Len()
+	var sb strings.Builder
+	var cur TermAttr
+	open := false
+	for i := 0; i < n; i++ {
+		c := tl[i]
+		if i == 0 || c.Attr != cur {
+			if open {
+				sb.WriteString("</span>")
+				open = false
+			}
+			cur = c.Attr
+			if sty := cur.Style(); sty != "" {
+				sb.WriteString(`<span style="` + sty + `">`)
+				open = true
+			}
+		}
+		ch := c.Ch
+		if ch == 0 {
+			ch = ' '
+		}
+		sb.WriteString(html.EscapeString(string(ch)))
+	}
+	if open {
+		sb.WriteString("</span>")
+	}
+	return sb.String()
+}
+
+// Style returns the css style of the attributes, "" if default
+func (ta TermAttr) Style() string {
+	fg, bg := ta.Fg, ta.Bg
+	if ta.Reverse {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = TermColors[0]
+		}
+		if bg == "" {
+			bg = TermColors[7]
+		}
+	}
+	var sty []string
+	if fg != "" {
+		sty = append(sty, "color: "+fg)
+	}
+	if bg != "" {
+		sty = append(sty, "background-color: "+bg)
+	}
+	if ta.Bold {
+		sty = append(sty, "font-weight: bold")
+	}
+	if ta.Italic {
+		sty = append(sty, "font-style: italic")
+	}
+	if ta.Underline {
+		sty = append(sty, "text-decoration: underline")
+	}
+	return strings.Join(sty, "; ")
+}
+
+// termParse is the state of the parsing of escape sequences
+type termParse int
+
+const (
+	termGround termParse = iota
+	termEsc
+	termCSI
+	termOSC
+	termOSCEsc
+	termCharset
+)
+
+// TermScreen is the screen of a terminal emulator, in the VT100 / xterm
+// subset used by shells and the common full-screen programs: the output
+// of the programs is written to it, and it keeps the cells of its rows,
+// the cursor, and the lines scrolled off its top
+type TermScreen struct {
+	Rows         int        `desc:"number of rows"`
+	Cols         int        `desc:"number of columns"`
+	Lines        []TermLine `desc:"the rows of the screen"`
+	History      []TermLine `desc:"lines scrolled off the top of the main screen, oldest first"`
+	Row          int        `desc:"row of the cursor"`
+	Col          int        `desc:"column of the cursor"`
+	Attr         TermAttr   `desc:"current attributes of the written chars"`
+	Top          int        `desc:"top row of the scrolling region"`
+	Bot          int        `desc:"bottom row of the scrolling region"`
+	AltScreen    bool       `desc:"true if showing the alternate screen of full-screen programs"`
+	AppCursor    bool       `desc:"application cursor keys mode"`
+	BracketPaste bool       `desc:"bracketed paste mode"`
+	HideCursor   bool       `desc:"cursor hidden"`
+	Title        string     `desc:"title set by the programs"`
+	Cwd          string     `desc:"working directory reported by the shell, with OSC 7"`
+	Bell         bool       `desc:"set when the bell rang, cleared by the view"`
+	Reply        []byte     `desc:"replies to send back to the programs, e.g., cursor reports"`
+	Mu           sync.Mutex `json:"-" xml:"-" view:"-" desc:"mutex protecting the screen"`
+
+	mainLines []TermLine
+	mainRow   int
+	mainCol   int
+	savedRow  int
+	savedCol  int
+	savedAttr TermAttr
+	wrapNext  bool
+	state     termParse
+	params    []byte
+	osc       []byte
+	pend      []byte
+}
+
+// NewTermScreen returns a new blank screen of given size
+func NewTermScreen(rows, cols int) *TermScreen {
+	ts := &TermScreen{}
+	ts.Resize(rows, cols)
+	return ts
+}
+
+// Resize sets the size of the screen, keeping the rows at the bottom
+func (ts *TermScreen) Resize(rows, cols int) {
+	if rows < 1 {
+		rows = 1
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	if rows == ts.Rows && cols == ts.Cols {
+		return
+	}
+	for len(ts.Lines) > rows && len(ts.Lines)-1 > ts.Row {
+		ts.Lines = ts.Lines[:len(ts.Lines)-1] // drop the rows below the cursor first
+	}
+	if len(ts.Lines) > rows {
+		ts.Row -= len(ts.Lines) - rows
+	}
+	ts.Lines = ts.resizeLines(ts.Lines, rows, cols, true)
+	if ts.mainLines != nil {
+		ts.mainLines = ts.resizeLines(ts.mainLines, rows, cols, false)
+	}
+	ts.Rows, ts.Cols = rows, cols
+	ts.Top, ts.Bot = 0, rows-1
+	ts.Row = clampInt(ts.Row, 0, rows-1)
+	ts.Col = clampInt(ts.Col, 0, cols-1)
+	ts.wrapNext = false
+}
+
+// resizeLines returns given lines resized to rows and cols: when shrinking,
+// the top rows go to the history if hist
+func (ts *TermScreen) resizeLines(lns []TermLine, rows, cols int, hist bool) []TermLine {
+	if len(lns) > rows {
+		drop := len(lns) - rows
+		if hist && !ts.AltScreen {
+			ts.History = append(ts.History, lns[:drop]...)
+			ts.trimHistory()
+		}
+		lns = lns[drop:]
+	}
+	for len(lns) < rows {
+		lns = append(lns, nil)
+	}
+	for i, ln := range lns {
+		switch {
+		case len(ln) > cols:
+			lns[i] = ln[:cols]
+		case len(ln) < cols:
+			lns[i] = append(ln, make(TermLine, cols-len(ln))...)
+		}
+	}
+	return lns
+}
+
+// trimHistory drops the oldest history beyond TermScrollback
+func (ts *TermScreen) trimHistory() {
+	if n := len(ts.History) - TermScrollback; n > 0 {
+		ts.History = append([]TermLine(nil), ts.History[n:]...)
+	}
+}
+
+// Reset clears the screen and the history
+func (ts *TermScreen) Reset() {
+	rows, cols := ts.Rows, ts.Cols
+	ts.Lines, ts.History, ts.mainLines = nil, nil, nil
+	ts.Rows, ts.Cols, ts.Row, ts.Col = 0, 0, 0, 0
+	ts.Attr, ts.savedAttr = TermAttr{}, TermAttr{}
+	ts.AltScreen, ts.AppCursor, ts.BracketPaste, ts.HideCursor = false, false, false, false
+	ts.state = termGround
+	ts.Resize(rows, cols)
+}
+
+func clampInt(v, mn, mx int) int {
+	if v < mn {
+		return mn
+	}
+	if v > mx {
+		return mx
+	}
+	return v
+}
+
+// Write writes the output of the programs to the screen, interpreting its
+// control chars and escape sequences
+func (ts *TermScreen) Write(b []byte) (int, error) {
+	n := len(b)
+	if len(ts.pend) > 0 {
+		b = append(ts.pend, b...)
+		ts.pend = nil
+	}
+	for len(b) > 0 {
+		c := b[0]
+		if c < utf8.RuneSelf || ts.state != termGround {
+			ts.putByte(c)
+			b = b[1:]
+			continue
+		}
+		if !utf8.FullRune(b) {
+			ts.pend = append([]byte(nil), b...)
+			break
+		}
+		r, sz := utf8.DecodeRune(b)
+		ts.putChar(r)
+		b = b[sz:]
+	}
+	return n, nil
+}
+
+// putByte processes one byte of control, escape sequence or ascii char
+func (ts *TermScreen) putByte(c byte) {
+	switch ts.state {
+	case termEsc:
+		ts.escape(c)
+		return
+	case termCSI:
+		switch {
+		case c >= 0x40 && c <= 0x7e:
+			ts.state = termGround
+			ts.csi(c)
+		case c == 0x18 || c == 0x1a:
+			ts.state = termGround
+		default:
+			ts.params = append(ts.params, c)
+		}
+		return
+	case termOSC:
+		switch c {
+		case 0x07:
+			ts.state = termGround
+			ts.oscDone()
+		case 0x1b:
+			ts.state = termOSCEsc
+		default:
+			ts.osc = append(ts.osc, c)
+		}
+		return
+	case termOSCEsc:
+		ts.state = termGround
+		ts.oscDone()
+		if c != '\\' {
+			ts.putByte(0x1b)
+			ts.putByte(c)
+		}
+		return
+	case termCharset:
+		ts.state = termGround
+		return
+	}
+	switch c {
+	case 0x1b:
+		ts.state = termEsc
+	case '\r':
+		ts.Col = 0
+		ts.wrapNext = false
+	case '\n', 0x0b, 0x0c:
+		ts.lineFeed()
+	case '\b':
+		if ts.Col > 0 {
+			ts.Col--
+		}
+		ts.wrapNext = false
+	case '\t':
+		ts.Col = clampInt((ts.Col/8+1)*8, 0, ts.Cols-1)
+		ts.wrapNext = false
+	case 0x07:
+		ts.Bell = true
+	default:
+		if c >= ' ' && c != 0x7f {
+			ts.putChar(rune(c))
+		}
+	}
+}
+
+// putChar writes a printable char at the cursor, wrapping at the end of
+// the line
+func (ts *TermScreen) putChar(r rune) {
+	if ts.wrapNext {
+		ts.Col = 0
+		ts.lineFeed()
+	}
+	ts.Lines[ts.Row][ts.Col] = TermCell{Ch: r, Attr: ts.Attr}
+	if ts.Col == ts.Cols-1 {
+		ts.wrapNext = true
+	} else {
+		ts.Col++
+	}
+}
+
+// lineFeed moves the cursor down, scrolling at the bottom of the region
+func (ts *TermScreen) lineFeed() {
+	ts.wrapNext = false
+	if ts.Row == ts.Bot {
+		ts.scrollUp(ts.Top, ts.Bot, 1)
+	} else if ts.Row < ts.Rows-1 {
+		ts.Row++
+	}
+}
+
+// reverseLineFeed moves the cursor up, scrolling at the top of the region
+func (ts *TermScreen) reverseLineFeed() {
+	ts.wrapNext = false
+	if ts.Row == ts.Top {
+		ts.scrollDown(ts.Top, ts.Bot, 1)
+	} else if ts.Row > 0 {
+		ts.Row--
+	}
+}
+
+// blankLine returns a new blank line, with the background of the attributes
+func (ts *TermScreen) blankLine() TermLine {
+	ln := make(TermLine, ts.Cols)
+	if ts.Attr.Bg != "" {
+		for i := range ln {
+			ln[i].Attr.Bg = ts.Attr.Bg
+		}
+	}
+	return ln
+}
+
+// scrollUp scrolls rows top to bot up by n -- lines scrolled off the top
+// of the main screen go to the history
+func (ts *TermScreen) scrollUp(top, bot, n int) {
+	n = clampInt(n, 0, bot-top+1)
+	if top == 0 && !ts.AltScreen {
+		ts.History = append(ts.History, ts.Lines[:n]...)
+		ts.trimHistory()
+	}
+	for i := top; i <= bot; i++ {
+		if i+n <= bot {
+			ts.Lines[i] = ts.Lines[i+n]
+		} else {
+			ts.Lines[i] = ts.blankLine()
+		}
+	}
+}
+
+// scrollDown scrolls rows top to bot down by n
+func (ts *TermScreen) scrollDown(top, bot, n int) {
+	n = clampInt(n, 0, bot-top+1)
+	for i := bot; i >= top; i-- {
+		if i-n >= top {
+			ts.Lines[i] = ts.Lines[i-n]
+		} else {
+			ts.Lines[i] = ts.blankLine()
+		}
+	}
+}
+
+// escape processes the char after an ESC
+func (ts *TermScreen) escape(c byte) {
+	ts.state = termGround
+	switch c {
+	case '[':
+		ts.state = termCSI
+		ts.params = ts.params[:0]
+	case ']':
+		ts.state = termOSC
+		ts.osc = ts.osc[:0]
+	case '(', ')', '*', '+', '#', '%':
+		ts.state = termCharset
+	case '7':
+		ts.saveCursor()
+	case '8':
+		ts.restoreCursor()
+	case 'D':
+		ts.lineFeed()
+	case 'E':
+		ts.Col = 0
+		ts.lineFeed()
+	case 'M':
+		ts.reverseLineFeed()
+	case 'c':
+		ts.Reset()
+	}
+}
+
+func (ts *TermScreen) saveCursor() {
+	ts.savedRow, ts.savedCol, ts.savedAttr = ts.Row, ts.Col, ts.Attr
+}
+
+func (ts *TermScreen) restoreCursor() {
+	ts.Row = clampInt(ts.savedRow, 0, ts.Rows-1)
+	ts.Col = clampInt(ts.savedCol, 0, ts.Cols-1)
+	ts.Attr = ts.savedAttr
+	ts.wrapNext = false
+}
+
+// oscDone processes an OSC sequence: only the window title and the
+// working directory are kept
+func (ts *TermScreen) oscDone() {
+	s := string(ts.osc)
+	switch {
+	case strings.HasPrefix(s, "0;") || strings.HasPrefix(s, "2;"):
+		ts.Title = s[2:]
+	case strings.HasPrefix(s, "7;"):
+		if u, err := url.Parse(s[2:]); err == nil && u.Scheme == "file" && u.Path != "" {
+			ts.Cwd = u.Path
+		}
+	}
+}
+
+// csiParams returns the numeric params of the CSI sequence, and whether it
+// is private (? prefix)
+func (ts *TermScreen) csiParams() ([]int, bool) {
+	ps := string(ts.params)
+	priv := false
+	if len(ps) > 0 && (ps[0] == '?' || ps[0] == '>' || ps[0] == '=') {
+		priv = ps[0] == '?'
+		ps = ps[1:]
+	}
+	ps = strings.TrimRight(ps, " !\"$'")
+	if ps == "" {
+		return nil, priv
+	}
+	flds := strings.Split(strings.Replace(ps, ":", ";", -1), ";")
+	pr := make([]int, len(flds))
+	for i, f := range flds {
+		pr[i], _ = strconv.Atoi(f)
+	}
+	return pr, priv
+}
+
+// termParam returns the ith param, or def if missing or 0
+func termParam(pr []int, i, def int) int {
+	if i < len(pr) && pr[i] > 0 {
+		return pr[i]
+	}
+	return def
+}
+
+// csi processes a CSI sequence with given final char
+func (ts *TermScreen) csi(f byte) {
+	pr, priv := ts.csiParams()
+	if len(ts.params) > 0 && ts.params[0] == '>' {
+		return // secondary attributes etc
+	}
+	ts.wrapNext = false
+	switch f {
+	case 'A':
+		ts.Row = clampInt(ts.Row-termParam(pr, 0, 1), 0, ts.Rows-1)
+	case 'B', 'e':
+		ts.Row = clampInt(ts.Row+termParam(pr, 0, 1), 0, ts.Rows-1)
+	case 'C', 'a':
+		ts.Col = clampInt(ts.Col+termParam(pr, 0, 1), 0, ts.Cols-1)
+	case 'D':
+		ts.Col = clampInt(ts.Col-termParam(pr, 0, 1), 0, ts.Cols-1)
+	case 'E':
+		ts.Row = clampInt(ts.Row+termParam(pr, 0, 1), 0, ts.Rows-1)
+		ts.Col = 0
+	case 'F':
+		ts.Row = clampInt(ts.Row-termParam(pr, 0, 1), 0, ts.Rows-1)
+		ts.Col = 0
+	case 'G', '`':
+		ts.Col = clampInt(termParam(pr, 0, 1)-1, 0, ts.Cols-1)
+	case 'd':
+		ts.Row = clampInt(termParam(pr, 0, 1)-1, 0, ts.Rows-1)
+	case 'H', 'f':
+		ts.Row = clampInt(termParam(pr, 0, 1)-1, 0, ts.Rows-1)
+		ts.Col = clampInt(termParam(pr, 1, 1)-1, 0, ts.Cols-1)
+	case 'J':
+		ts.eraseDisplay(termParam(pr, 0, 0))
+	case 'K':
+		ts.eraseLine(termParam(pr, 0, 0))
+	case 'L':
+		if ts.Row >= ts.Top && ts.Row <= ts.Bot {
+			ts.scrollDown(ts.Row, ts.Bot, termParam(pr, 0, 1))
+		}
+	case 'M':
+		if ts.Row >= ts.Top && ts.Row <= ts.Bot {
+			ts.scrollUpNoHist(ts.Row, ts.Bot, termParam(pr, 0, 1))
+		}
+	case 'S':
+		ts.scrollUp(ts.Top, ts.Bot, termParam(pr, 0, 1))
+	case 'T':
+		ts.scrollDown(ts.Top, ts.Bot, termParam(pr, 0, 1))
+	case '@':
+		ts.insertChars(termParam(pr, 0, 1))
+	case 'P':
+		ts.deleteChars(termParam(pr, 0, 1))
+	case 'X':
+		ts.eraseChars(ts.Col, ts.Col+termParam(pr, 0, 1))
+	case 'm':
+		ts.sgr(pr)
+	case 'r':
+		top := clampInt(termParam(pr, 0, 1)-1, 0, ts.Rows-1)
+		bot := clampInt(termParam(pr, 1, ts.Rows)-1, 0, ts.Rows-1)
+		if top < bot {
+			ts.Top, ts.Bot = top, bot
+			ts.Row, ts.Col = 0, 0
+		}
+	case 's':
+		ts.saveCursor()
+	case 'u':
+		ts.restoreCursor()
+	case 'h', 'l':
+		if priv {
+			for _, p := range pr {
+				ts.setMode(p, f == 'h')
+			}
+		}
+	case 'n':
+		if termParam(pr, 0, 0) == 6 {
+			ts.Reply = append(ts.Reply, fmt.Sprintf("\x1b[%d;%dR", ts.Row+1, ts.Col+1)...)
+		} else if termParam(pr, 0, 0) == 5 {
+			ts.Reply = append(ts.Reply, "\x1b[0n"...)
+		}
+	case 'c':
+		if !priv {
+			ts.Reply = append(ts.Reply, "\x1b[?1;2c"...)
+		}
+	}
+}
+
+// scrollUpNoHist scrolls up rows within the screen, e.g., deleting lines,
+// without adding to the history
+func (ts *TermScreen) scrollUpNoHist(top, bot, n int) {
+	alt := ts.AltScreen
+	ts.AltScreen = true
+	ts.scrollUp(top, bot, n)
+	ts.AltScreen = alt
+}
+
+// setMode sets or resets a private (DEC) mode
+func (ts *TermScreen) setMode(mode int, on bool) {
+	switch mode {
+	case 1:
+		ts.AppCursor = on
+	case 25:
+		ts.HideCursor = !on
+	case 2004:
+		ts.BracketPaste = on
+	case 47, 1047, 1049:
+		if on == ts.AltScreen {
+			return
+		}
+		if on {
+			if mode == 1049 {
+				ts.saveCursor()
+			}
+			ts.mainLines = ts.Lines
+			ts.mainRow, ts.mainCol = ts.Row, ts.Col
+			ts.AltScreen = true
+			ts.Lines = nil
+			ts.Lines = ts.resizeLines(nil, ts.Rows, ts.Cols, false)
+		} else {
+			ts.AltScreen = false
+			ts.Lines = ts.resizeLines(ts.mainLines, ts.Rows, ts.Cols, false)
+			ts.mainLines = nil
+			ts.Row, ts.Col = ts.mainRow, ts.mainCol
+			if mode == 1049 {
+				ts.restoreCursor()
+			}
+		}
+		ts.Top, ts.Bot = 0, ts.Rows-1
+	}
+}
+
+// eraseChars blanks the chars from st to ed (exclusive) of the cursor row
+func (ts *TermScreen) eraseChars(st, ed int) {
+	ln := ts.Lines[ts.Row]
+	st = clampInt(st, 0, ts.Cols)
+	ed = clampInt(ed, 0, ts.Cols)
+	for i := st; i < ed; i++ {
+		ln[i] = TermCell{Attr: TermAttr{Bg: ts.Attr.Bg}}
+	}
+}
+
+// eraseLine erases the line: 0 = to the end, 1 = to the start, 2 = all
+func (ts *TermScreen) eraseLine(md int) {
+	switch md {
+	case 0:
+		ts.eraseChars(ts.Col, ts.Cols)
+	case 1:
+		ts.eraseChars(0, ts.Col+1)
+	case 2:
+		ts.eraseChars(0, ts.Cols)
+	}
+}
+
+// eraseDisplay erases the screen: 0 = to the end, 1 = to the start, 2 = all,
+// 3 = all and the history
+func (ts *TermScreen) eraseDisplay(md int) {
+	switch md {
+	case 0:
+		ts.eraseLine(0)
+		for i := ts.Row + 1; i < ts.Rows; i++ {
+			ts.Lines[i] = ts.blankLine()
+		}
+	case 1:
+		ts.eraseLine(1)
+		for i := 0; i < ts.Row; i++ {
+			ts.Lines[i] = ts.blankLine()
+		}
+	case 2, 3:
+		for i := range ts.Lines {
+			ts.Lines[i] = ts.blankLine()
+		}
+		if md == 3 {
+			ts.History = nil
+		}
+	}
+}
+
+// insertChars inserts n blanks at the cursor, shifting the rest right
+func (ts *TermScreen) insertChars(n int) {
+	ln := ts.Lines[ts.Row]
+	n = clampInt(n, 0, ts.Cols-ts.Col)
+	copy(ln[ts.Col+n:], ln[ts.Col:ts.Cols-n])
+	ts.eraseChars(ts.Col, ts.Col+n)
+}
+
+// deleteChars deletes n chars at the cursor, shifting the rest left
+func (ts *TermScreen) deleteChars(n int) {
+	ln := ts.Lines[ts.Row]
+	n = clampInt(n, 0, ts.Cols-ts.Col)
+	copy(ln[ts.Col:], ln[ts.Col+n:])
+	ts.eraseChars(ts.Cols-n, ts.Cols)
+}
+
+// sgr sets the attributes from the params of a SGR sequence
+func (ts *TermScreen) sgr(pr []int) {
+	if len(pr) == 0 {
+		pr = []int{0}
+	}
+	for i := 0; i < len(pr); i++ {
+		p := pr[i]
+		switch {
+		case p == 0:
+			ts.Attr = TermAttr{}
+		case p == 1:
+			ts.Attr.Bold = true
+		case p == 3:
+			ts.Attr.Italic = true
+		case p == 4:
+			ts.Attr.Underline = true
+		case p == 7:
+			ts.Attr.Reverse = true
+		case p == 22:
+			ts.Attr.Bold = false
+		case p == 23:
+			ts.Attr.Italic = false
+		case p == 24:
+			ts.Attr.Underline = false
+		case p == 27:
+			ts.Attr.Reverse = false
+		case p >= 30 && p <= 37:
+			ts.Attr.Fg = TermColors[p-30]
+		case p == 39:
+			ts.Attr.Fg = ""
+		case p >= 40 && p <= 47:
+			ts.Attr.Bg = TermColors[p-40]
+		case p == 49:
+			ts.Attr.Bg = ""
+		case p >= 90 && p <= 97:
+			ts.Attr.Fg = TermColors[p-90+8]
+		case p >= 100 && p <= 107:
+			ts.Attr.Bg = TermColors[p-100+8]
+		case p == 38 || p == 48:
+			clr, adv := termExtColor(pr[i+1:])
+			i += adv
+			if p == 38 {
+				ts.Attr.Fg = clr
+			} else {
+				ts.Attr.Bg = clr
+			}
+		}
+	}
+}
+
+// termExtColor returns the 256 (5;n) or truecolor (2;r;g;b) color of the
+// params after a 38 or 48, and the number of params used
+func termExtColor(pr []int) (string, int) {
+	if len(pr) >= 2 && pr[0] == 5 {
+		return Term256Color(pr[1]), 2
+	}
+	if len(pr) >= 4 && pr[0] == 2 {
+		return fmt.Sprintf("#%02x%02x%02x", pr[1]&0xff, pr[2]&0xff, pr[3]&0xff), 4
+	}
+	return "", len(pr)
+}
+
+// Term256Color returns the hex color of the xterm 256 color palette
+func Term256Color(n int) string {
+	switch {
+	case n < 0 || n > 255:
+		return ""
+	case n < 16:
+		return TermColors[n]
+	case n < 232:
+		n -= 16
+		lv := func(v int) int {
+			if v == 0 {
+				return 0
+			}
+			return 55 + v*40
+		}
+		return fmt.Sprintf("#%02x%02x%02x", lv(n/36), lv((n/6)%6), lv(n%6))
+	default:
+		g := 8 + (n-232)*10
+		return fmt.Sprintf("#%02x%02x%02x", g, g, g)
+	}
+}
+
+// AllLines returns the history and the rows of the screen, for display --
+// the history is not shown on the alternate screen
+func (ts *TermScreen) AllLines() []TermLine {
+	if ts.AltScreen {
+		return ts.Lines
+	}
+	lns := make([]TermLine, 0, len(ts.History)+len(ts.Lines))
+	lns = append(lns, ts.History...)
+	return append(lns, ts.Lines...)
+}
@@ -0,0 +1,772 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+	"github.com/goki/pi/lex"
+)
+
+// TermCmdName is the name of the default terminal, and of its tab
+var TermCmdName = "Terminal"
+
+// TermRefreshDelay is the min time between redisplays of a terminal
+// producing output, so bursts of output are shown at once
+var TermRefreshDelay = 30 * time.Millisecond
+
+// TermView is a terminal tab: one or more panes, side by side or stacked,
+// each a shell running in a pseudo-terminal -- the toolbar acts on the
+// active pane, the last one focused
+type TermView struct {
+	gi.Layout
+	Gide     Gide      `json:"-" xml:"-" desc:"parent gide project"`
+	TermName string    `desc:"name of the terminal, and of its tab"`
+	Host     string    `desc:"if set, the panes are SSH sessions to the host of this name, instead of local shells"`
+	Vert     bool      `desc:"panes are stacked vertically, instead of side by side"`
+	NPanes   int       `desc:"number of panes made, for naming new ones"`
+	Active   *TermPane `json:"-" xml:"-" view:"-" desc:"the active pane"`
+}
+
+var KiT_TermView = kit.Types.AddType(&TermView{}, TermViewProps)
+
+// Config configures the view of the terminal of given name, starting a
+// pane in the project root if it has none
+func (tv *TermView) Config(ge Gide, name string) {
+	tv.ConfigDirs(ge, name, false, nil)
+}
+
+// ConfigDirs configures the view of the terminal of given name, starting
+// panes in given dirs, e.g., as saved in the session, if it has none --
+// stacked vertically if vert
+func (tv *TermView) ConfigDirs(ge Gide, name string, vert bool, dirs []string) {
+	tv.Gide = ge
+	tv.TermName = name
+	tv.Lay = gi.LayoutVert
+	tv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "termbar")
+	config.Add(gi.KiT_SplitView, "termsplit")
+	mods, updt := tv.ConfigChildren(config)
+	if !mods {
+		updt = tv.UpdateStart()
+	}
+	tv.ConfigToolbar()
+	split := tv.Split()
+	split.SetStretchMaxWidth()
+	split.SetStretchMaxHeight()
+	if !split.HasChildren() {
+		tv.Vert = vert
+		if len(dirs) == 0 {
+			dirs = []string{string(ge.ProjPrefs().ProjRoot)}
+		}
+		for _, dir := range dirs {
+			tv.AddPane(dir)
+		}
+	}
+	tv.SetDim()
+	tv.UpdateEnd(updt)
+	if ap := tv.ActivePane(); ap != nil {
+		ap.Text().GrabFocus()
+	}
+}
+
+// ConfigSSH configures the view of the terminal of given name as SSH
+// sessions to the host of given name, starting a pane in given dirs on the
+// host, or the Dir of the host if none
+func (tv *TermView) ConfigSSH(ge Gide, name, host string, vert bool, dirs []string) {
+	tv.Host = host
+	if len(dirs) == 0 {
+		dirs = []string{""}
+	}
+	tv.ConfigDirs(ge, name, vert, dirs)
+}
+
+// ToolBar returns the toolbar
+func (tv *TermView) ToolBar() *gi.ToolBar {
+	return tv.ChildByName("termbar", 0).(*gi.ToolBar)
+}
+
+// Split returns the split view of the panes
+func (tv *TermView) Split() *gi.SplitView {
+	return tv.ChildByName("termsplit", 1).(*gi.SplitView)
+}
+
+// Panes returns the panes, in order
+func (tv *TermView) Panes() []*TermPane {
+	var tps []*TermPane
+	for _, k := range *tv.Split().Children() {
+		if tp, ok := k.Embed(KiT_TermPane).(*TermPane); ok {
+			tps = append(tps, tp)
+		}
+	}
+	return tps
+}
+
+// ActivePane returns the active pane, or the first one
+func (tv *TermView) ActivePane() *TermPane {
+	tps := tv.Panes()
+	for _, tp := range tps {
+		if tp == tv.Active {
+			return tp
+		}
+	}
+	if len(tps) > 0 {
+		return tps[0]
+	}
+	return nil
+}
+
+// PaneName returns the name of the terminal of the ith pane made: that of
+// the view for the first one
+func (tv *TermView) PaneName(i int) string {
+	if i == 0 {
+		return tv.TermName
+	}
+	return fmt.Sprintf("%v %d", tv.TermName, i+1)
+}
+
+// AddPane adds a pane, starting its shell in given dir
+func (tv *TermView) AddPane(dir string) *TermPane {
+	split := tv.Split()
+	updt := split.UpdateStart()
+	split.SetChildAdded()
+	tp := split.AddNewChild(KiT_TermPane, fmt.Sprintf("pane-%d", tv.NPanes)).(*TermPane)
+	tp.Config(tv, tv.PaneName(tv.NPanes))
+	tp.Term.Host = tv.Host
+	tv.NPanes++
+	tv.Active = tp
+	split.EvenSplits()
+	split.UpdateEnd(updt)
+	tp.Start(dir)
+	return tp
+}
+
+// SetDim sets the direction of the split from Vert
+func (tv *TermView) SetDim() {
+	split := tv.Split()
+	if tv.Vert {
+		split.Dim = mat32.Y
+	} else {
+		split.Dim = mat32.X
+	}
+}
+
+// SplitPane adds a pane, next to the others or below them if vert, with a
+// shell in the working directory of the active pane
+func (tv *TermView) SplitPane(vert bool) {
+	dir := string(tv.Gide.ProjPrefs().ProjRoot)
+	if tv.Host != "" {
+		dir = ""
+	}
+	if ap := tv.ActivePane(); ap != nil {
+		dir = ap.Term.Cwd()
+	}
+	updt := tv.UpdateStart()
+	tv.Vert = vert
+	tv.SetDim()
+	tp := tv.AddPane(dir)
+	tv.SetFullReRender()
+	tv.UpdateEnd(updt)
+	tp.Text().GrabFocus()
+}
+
+// ClosePane closes the active pane, killing its shell -- closing the last
+// one closes the tab
+func (tv *TermView) ClosePane() {
+	ap := tv.ActivePane()
+	if ap == nil {
+		return
+	}
+	tps := tv.Panes()
+	if len(tps) <= 1 {
+		if tabs, ok := tv.ParentByType(gi.KiT_TabView, ki.Embeds).Embed(gi.KiT_TabView).(*gi.TabView); ok {
+			if idx, err := tabs.TabIndexByName(tv.TermName); err == nil {
+				tabs.DeleteTabIndexAction(idx)
+			}
+		}
+		return
+	}
+	split := tv.Split()
+	updt := tv.UpdateStart()
+	split.DeleteChild(ap.This(), true)
+	split.EvenSplits()
+	tv.Active = nil
+	tv.SetFullReRender()
+	tv.UpdateEnd(updt)
+	if ap := tv.ActivePane(); ap != nil {
+		ap.Text().GrabFocus()
+	}
+}
+
+// Rename renames the terminal, and its tab, to given name -- the name must
+// not be that of another tab
+func (tv *TermView) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || name == tv.TermName {
+		return nil
+	}
+	tabs, ok := tv.ParentByType(gi.KiT_TabView, ki.Embeds).Embed(gi.KiT_TabView).(*gi.TabView)
+	if !ok {
+		return fmt.Errorf("terminal %v is not in a tab", tv.TermName)
+	}
+	if _, err := tabs.TabIndexByName(name); err == nil {
+		return fmt.Errorf("there is already a tab named %v", name)
+	}
+	idx, err := tabs.TabIndexByName(tv.TermName)
+	if err != nil {
+		return err
+	}
+	_, tbut, _ := tabs.TabAtIndex(idx)
+	updt := tabs.UpdateStart()
+	tbut.SetName(name)
+	tbut.SetText(name)
+	tv.SetName(name)
+	tv.TermName = name
+	tps := tv.Panes()
+	for i, tp := range tps {
+		tp.Term.SetName(tv.Gide, tv.PaneName(i))
+	}
+	tv.NPanes = len(tps)
+	tabs.UpdateEnd(updt)
+	return nil
+}
+
+// RenamePrompt prompts for the new name of the terminal
+func (tv *TermView) RenamePrompt() {
+	gi.StringPromptDialog(tv.Viewport, tv.TermName, "name of the terminal",
+		gi.DlgOpts{Title: "Rename Terminal", Prompt: "new name of the terminal, and of its tab:"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			tvv, _ := recv.Embed(KiT_TermView).(*TermView)
+			if err := tvv.Rename(gi.StringPromptDialogValue(send.(*gi.Dialog))); err != nil {
+				gi.PromptDialog(tvv.Viewport, gi.DlgOpts{Title: "Rename Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+			}
+		})
+}
+
+// SendText sends given text to the shell of the active pane, as if typed,
+// with a newline at the end -- newlines are sent as Enter
+func (tv *TermView) SendText(txt string) error {
+	ap := tv.ActivePane()
+	if ap == nil {
+		return fmt.Errorf("terminal %v has no pane", tv.TermName)
+	}
+	txt = strings.Replace(strings.Replace(txt, "\r\n", "\n", -1), "\n", "\r", -1)
+	if !strings.HasSuffix(txt, "\r") {
+		txt += "\r"
+	}
+	return ap.Term.Send([]byte(txt))
+}
+
+// SessionTerm returns the state of the terminal to save in the session,
+// with the dirs relative to given project root -- those on the host for
+// SSH sessions
+func (tv *TermView) SessionTerm(root string) SessionTerm {
+	st := SessionTerm{Name: tv.TermName, Host: tv.Host, Vert: tv.Vert}
+	for _, tp := range tv.Panes() {
+		if tv.Host != "" {
+			st.Dirs = append(st.Dirs, tp.Term.Cwd())
+		} else {
+			st.Dirs = append(st.Dirs, SessionPath(root, tp.Term.Cwd()))
+		}
+	}
+	return st
+}
+
+// SetTitle shows the title of the active pane in the toolbar
+func (tv *TermView) SetTitle() {
+	title := ""
+	if ap := tv.ActivePane(); ap != nil && ap.Term.Screen != nil {
+		ap.Term.Screen.Mu.Lock()
+		title = ap.Term.Screen.Title
+		ap.Term.Screen.Mu.Unlock()
+	}
+	if lb, ok := tv.ToolBar().ChildByName("title", 0).(*gi.Label); ok && lb.Text != title {
+		lb.SetText(title)
+	}
+}
+
+// ConfigToolbar adds the toolbar actions
+func (tv *TermView) ConfigToolbar() {
+	tb := tv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	pane := func(recv ki.Ki) *TermPane {
+		tvv, _ := recv.Embed(KiT_TermView).(*TermView)
+		return tvv.ActivePane()
+	}
+	tb.AddAction(gi.ActOpts{Label: "Restart", Icon: "update", Tooltip: "restart the shell of the active pane, in its working directory"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			if tp := pane(recv); tp != nil {
+				tp.Start(tp.Term.Cwd())
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Interrupt", Icon: "stop", Tooltip: "send an interrupt (Ctrl+C) to the program running in the active pane"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			if tp := pane(recv); tp != nil {
+				tp.Term.Send([]byte{0x03})
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Clear", Icon: "close", Tooltip: "clear the screen and the scrollback of the active pane"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			if tp := pane(recv); tp != nil {
+				tp.Clear()
+			}
+		})
+	tb.AddSeparator("sep-copy")
+	tb.AddAction(gi.ActOpts{Label: "Copy", Icon: "copy", Tooltip: "copy the selected text (also Shift+Control+C)"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			if tp := pane(recv); tp != nil {
+				tp.Text().Copy(true)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Paste", Icon: "file-text", Tooltip: "paste the clipboard into the active pane (also Shift+Control+V)"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			if tp := pane(recv); tp != nil {
+				tp.Paste()
+			}
+		})
+	tb.AddSeparator("sep-split")
+	tb.AddAction(gi.ActOpts{Label: "Split Right", Icon: "plus", Tooltip: "add a pane side by side with the others, in the working directory of the active pane"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TermView).(*TermView)
+			tvv.SplitPane(false)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Split Down", Icon: "plus", Tooltip: "add a pane stacked below the others, in the working directory of the active pane"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TermView).(*TermView)
+			tvv.SplitPane(true)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Close Pane", Icon: "minus", Tooltip: "close the active pane, killing its shell -- closing the last one closes the tab"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TermView).(*TermView)
+			tvv.ClosePane()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Rename...", Icon: "gear", Tooltip: "rename the terminal, and its tab"}, tv.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TermView).(*TermView)
+			tvv.RenamePrompt()
+		})
+	tb.AddSeparator("sep-title")
+	gi.AddNewLabel(tb, "title", "")
+}
+
+// TermViewProps are style properties for TermView
+var TermViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  TermPane
+
+// TermPane is a pane of a terminal tab: a shell running in a
+// pseudo-terminal, with its screen shown in a TermText taking the keys
+type TermPane struct {
+	gi.Layout
+	View    *TermView  `json:"-" xml:"-" view:"-" desc:"the terminal view of the pane"`
+	Term    Term       `desc:"the terminal session"`
+	Pending bool       `json:"-" xml:"-" desc:"true while a redisplay is scheduled"`
+	Mu      sync.Mutex `json:"-" xml:"-" view:"-" desc:"mutex protecting Pending"`
+
+	links map[string]string
+}
+
+var KiT_TermPane = kit.Types.AddType(&TermPane{}, nil)
+
+// Config configures the pane, of given terminal view, for the terminal of
+// given name
+func (tp *TermPane) Config(tv *TermView, name string) {
+	tp.View = tv
+	tp.Term.Name = name
+	tp.Lay = gi.LayoutVert
+	tp.SetStretchMaxWidth()
+	tp.SetStretchMaxHeight()
+	tp.SetMinPrefWidth(units.NewValue(20, units.Ch))
+	tp.SetMinPrefHeight(units.NewValue(10, units.Ch))
+	if !tp.HasChildren() {
+		tt := tp.AddNewChild(KiT_TermText, "term").(*TermText)
+		tt.Pane = tp
+		tt.SetProp("line-nos", false)
+		tt.SetProp("white-space", gist.WhiteSpacePre)
+		tt.SetProp("font-family", gi.Prefs.MonoFont)
+		tt.SetStretchMaxWidth()
+		tt.SetStretchMaxHeight()
+		buf := giv.NewTextBuf()
+		buf.Opts.LineNos = false
+		buf.Info.Sup = filecat.NoSupport
+		tt.SetBuf(buf)
+	}
+	tp.Term.Mu.Lock()
+	tp.Term.Changed = tp.Changed
+	tp.Term.Mu.Unlock()
+}
+
+// Text returns the TermText showing the screen
+func (tp *TermPane) Text() *TermText {
+	return tp.Child(0).Embed(KiT_TermText).(*TermText)
+}
+
+// Start (re)starts the shell of the pane in given dir, sized to the
+// visible area of the pane
+func (tp *TermPane) Start(dir string) {
+	ge := tp.View.Gide
+	rows, cols := tp.VisRowsCols()
+	err := tp.Term.Start(ge, dir, TermEnv(ge), rows, cols)
+	if err != nil {
+		gi.PromptDialog(tp.Viewport, gi.DlgOpts{Title: "Terminal Failed", Prompt: "Could not start the shell: " + err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	tp.Changed()
+}
+
+// VisRowsCols returns the rows and columns visible in the TermText --
+// 24 x 80 until rendered
+func (tp *TermPane) VisRowsCols() (int, int) {
+	tt := tp.Text()
+	rows, cols := tt.VisSize.Y, tt.VisSize.X-1
+	if rows < 2 || cols < 10 {
+		return 24, 80
+	}
+	return rows, cols
+}
+
+// Clear clears the screen and the scrollback, and redraws the prompt
+func (tp *TermPane) Clear() {
+	sc := tp.Term.Screen
+	if sc == nil {
+		return
+	}
+	sc.Mu.Lock()
+	sc.Reset()
+	sc.Mu.Unlock()
+	tp.Term.Send([]byte{0x0c}) // Ctrl+L: redraw
+	tp.Changed()
+}
+
+// Paste sends the text of the clipboard to the terminal
+func (tp *TermPane) Paste() {
+	data := oswin.TheApp.ClipBoard(tp.ParentWindow().OSWin).Read([]string{filecat.TextPlain})
+	if data != nil {
+		tp.Term.Paste(data.TypeData(filecat.TextPlain))
+	}
+}
+
+// Changed schedules a redisplay of the screen after TermRefreshDelay,
+// unless one is scheduled
+func (tp *TermPane) Changed() {
+	tp.Mu.Lock()
+	defer tp.Mu.Unlock()
+	if tp.Pending {
+		return
+	}
+	tp.Pending = true
+	time.AfterFunc(TermRefreshDelay, tp.Redisplay)
+}
+
+// Redisplay shows the history and the screen of the terminal in the
+// TermText, with the cursor, resizing the terminal to the pane if changed
+func (tp *TermPane) Redisplay() {
+	tp.Mu.Lock()
+	tp.Pending = false
+	tp.Mu.Unlock()
+	if tp.This() == nil || tp.IsDeleted() || tp.IsDestroyed() {
+		return
+	}
+	sc := tp.Term.Screen
+	if sc == nil {
+		return
+	}
+	tt := tp.Text()
+	if rows, cols := tp.VisRowsCols(); tt.VisSize.Y > 0 {
+		tp.Term.Resize(rows, cols)
+	}
+	cwd := tp.Term.Cwd()
+	remote := tp.Term.Host != "" // files are on the host: no links
+	links := make(map[string]string)
+	sc.Mu.Lock()
+	lns := sc.AllLines()
+	txt := make([][]byte, len(lns))
+	mus := make([][]byte, len(lns))
+	curln := len(lns) - sc.Rows + sc.Row
+	curch := sc.Col
+	for i, ln := range lns {
+		s := ln.String()
+		mu, ok := tp.links[s]
+		if !ok && !remote {
+			mu, ok = TermMarkupLinks(s, cwd)
+		}
+		if ok {
+			links[s] = mu
+		} else {
+			mu = ln.Markup()
+		}
+		if i == curln {
+			if n := len([]rune(s)); n < curch {
+				pad := strings.Repeat(" ", curch-n)
+				s += pad
+				mu += pad
+			}
+		}
+		txt[i] = []byte(s)
+		mus[i] = []byte(mu)
+	}
+	bell := sc.Bell
+	sc.Bell = false
+	sc.Mu.Unlock()
+	tp.links = links
+
+	ge := tp.View.Gide
+	vp := ge.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	buf := tt.Buf
+	buf.SetTextLines(txt, false)
+	buf.MarkupMu.Lock()
+	for i := range mus {
+		if i < len(buf.Markup) {
+			buf.Markup[i] = mus[i]
+		}
+	}
+	buf.MarkupMu.Unlock()
+	buf.Refresh()
+	tt.SetCursorShow(lex.Pos{Ln: curln, Ch: curch})
+	tp.View.SetTitle()
+	if bell {
+		ge.SetStatus(tp.Term.Name + ": bell")
+	}
+}
+
+// TermMarkupLinks returns the markup of given line of a terminal with the
+// file paths and error positions as links, as in the output of the
+// commands -- relative paths are made absolute from given working
+// directory -- false if none, leaving the line to its colors
+func TermMarkupLinks(txt, cwd string) (string, bool) {
+	if len(txt) < 4 || !strings.ContainsAny(txt, "/.") {
+		return "", false
+	}
+	esc := string(giv.HTMLEscapeBytes([]byte(txt)))
+	mu := string(MarkupCmdOutput([]byte(esc)))
+	if mu == esc {
+		return "", false
+	}
+	const pfx = `href="file:///`
+	var sb strings.Builder
+	for {
+		i := strings.Index(mu, pfx)
+		if i < 0 {
+			break
+		}
+		i += len(pfx)
+		sb.WriteString(mu[:i])
+		mu = mu[i:]
+		ed := strings.IndexAny(mu, `#"`)
+		if ed < 0 {
+			break
+		}
+		fpath := mu[:ed]
+		if !filepath.IsAbs(fpath) && cwd != "" {
+			fpath = filepath.Join(cwd, fpath)
+		}
+		sb.WriteString(fpath)
+		mu = mu[ed:]
+	}
+	sb.WriteString(mu)
+	return sb.String(), true
+}
+
+// Destroy kills the shell of the terminal when the pane is deleted
+func (tp *TermPane) Destroy() {
+	tp.Term.Mu.Lock()
+	tp.Term.Changed = nil
+	tp.Term.Mu.Unlock()
+	tp.Term.Kill()
+	tp.Layout.Destroy()
+}
+
+//////////////////////////////////////////////////////////////////////////////
+//  TermText
+
+// TermText is the text view of the screen of a terminal: the keys typed
+// in it are sent to the terminal, other than copying the selection
+type TermText struct {
+	giv.TextView
+	Pane *TermPane `json:"-" xml:"-" view:"-" desc:"the terminal pane"`
+}
+
+var KiT_TermText = kit.Types.AddType(&TermText{}, giv.TextViewProps)
+
+// FocusChanged2D makes the pane of the text the active one of its view,
+// when focused
+func (tt *TermText) FocusChanged2D(change gi.FocusChanges) {
+	tt.TextView.FocusChanged2D(change)
+	if change == gi.FocusGot && tt.Pane != nil && tt.Pane.View != nil {
+		tt.Pane.View.Active = tt.Pane
+		tt.Pane.View.SetTitle()
+	}
+}
+
+// ConnectEvents2D takes the keys at high priority, before the text view
+func (tt *TermText) ConnectEvents2D() {
+	tt.TextViewEvents()
+	tt.ConnectEvent(oswin.KeyChordEvent, gi.HiPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ttt := recv.Embed(KiT_TermText).(*TermText)
+		kt := d.(*key.ChordEvent)
+		ttt.TermKeyInput(kt)
+	})
+}
+
+// TermKeyInput sends given key to the terminal -- Shift+Control+C and
+// Command+C copy the selection, and Shift+Control+V and Command+V paste
+func (tt *TermText) TermKeyInput(kt *key.ChordEvent) {
+	tp := tt.Pane
+	if tp == nil {
+		return
+	}
+	tp.View.Active = tp
+	ctrlShift := kt.HasAllModifier(key.Control, key.Shift)
+	meta := kt.HasAnyModifier(key.Meta)
+	if ctrlShift || meta {
+		switch kt.Code {
+		case key.CodeC:
+			kt.SetProcessed()
+			tt.Copy(true)
+			return
+		case key.CodeV:
+			kt.SetProcessed()
+			tp.Paste()
+			return
+		}
+		if meta {
+			return // other app shortcuts
+		}
+	}
+	if !tp.Term.IsRunning() {
+		if kt.Code == key.CodeReturnEnter {
+			kt.SetProcessed()
+			tp.Start(tp.Term.Cwd())
+		}
+		return
+	}
+	sc := tp.Term.Screen
+	sc.Mu.Lock()
+	app := sc.AppCursor
+	sc.Mu.Unlock()
+	b := TermKeyBytes(kt, app)
+	if b == nil {
+		return
+	}
+	kt.SetProcessed()
+	tt.SelectReset()
+	tp.Term.Send(b)
+}
+
+// TermKeyBytes returns the bytes sent to a terminal for given key, in the
+// application cursor keys mode if app -- nil if none
+func TermKeyBytes(kt *key.ChordEvent, app bool) []byte {
+	csi := "\x1b["
+	if app {
+		csi = "\x1bO"
+	}
+	var s string
+	switch kt.Code {
+	case key.CodeReturnEnter, key.CodeKeypadEnter:
+		s = "\r"
+	case key.CodeDeleteBackspace:
+		s = "\x7f"
+	case key.CodeTab:
+		s = "\t"
+		if kt.HasAnyModifier(key.Shift) {
+			s = "\x1b[Z"
+		}
+	case key.CodeEscape:
+		s = "\x1b"
+	case key.CodeUpArrow:
+		s = csi + "A"
+	case key.CodeDownArrow:
+		s = csi + "B"
+	case key.CodeRightArrow:
+		s = csi + "C"
+	case key.CodeLeftArrow:
+		s = csi + "D"
+	case key.CodeHome:
+		s = csi + "H"
+	case key.CodeEnd:
+		s = csi + "F"
+	case key.CodeInsert:
+		s = "\x1b[2~"
+	case key.CodeDeleteForward:
+		s = "\x1b[3~"
+	case key.CodePageUp:
+		s = "\x1b[5~"
+	case key.CodePageDown:
+		s = "\x1b[6~"
+	case key.CodeF1, key.CodeF2, key.CodeF3, key.CodeF4:
+		s = "\x1bO" + string(rune('P'+kt.Code-key.CodeF1))
+	case key.CodeF5:
+		s = "\x1b[15~"
+	case key.CodeF6, key.CodeF7, key.CodeF8:
+		s = "\x1b[" + string(rune('7'+kt.Code-key.CodeF6)) + "~"
+	case key.CodeF9, key.CodeF10:
+		s = "\x1b[2" + string(rune('0'+kt.Code-key.CodeF9)) + "~"
+	case key.CodeF11, key.CodeF12:
+		s = "\x1b[2" + string(rune('3'+kt.Code-key.CodeF11)) + "~"
+	}
+	if s != "" {
+		if kt.HasAnyModifier(key.Alt) && len(s) == 1 {
+			s = "\x1b" + s
+		}
+		return []byte(s)
+	}
+	r := kt.Rune
+	if r <= 0 {
+		return nil
+	}
+	if kt.HasAnyModifier(key.Control) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			r = r - 'a' + 1
+		case r >= 'A' && r <= 'Z':
+			r = r - 'A' + 1
+		case r >= '@' && r <= '_':
+			r = r - '@'
+		case r == ' ' || r == '2':
+			r = 0
+		case r == '/':
+			r = 0x1f
+		default:
+			return nil
+		}
+		s = string([]byte{byte(r)})
+	} else {
+		s = string(r)
+	}
+	if kt.HasAnyModifier(key.Alt) {
+		s = "\x1b" + s
+	}
+	return []byte(s)
+}
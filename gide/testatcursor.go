@@ -0,0 +1,302 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
+	"github.com/goki/pi/filecat"
+)
+
+// TestAtColor is the gutter color of the lines starting a test, benchmark
+// or t.Run subtest -- double-click on it to run it
+var TestAtColor = "#80cbc4"
+
+// TestLinesProp is the property of a TextBuf holding the lines marked as
+// starting a test or subtest, as distinct from breakpoints
+var TestLinesProp = "gide-test-lines"
+
+// SubTestRe matches a t.Run or b.Run subtest with a string literal name
+var SubTestRe = regexp.MustCompile(`\b\w+\.Run\(\s*("(?:[^"\\]|\\.)*"|` + "`[^`]*`" + `)\s*,`)
+
+// TestAt is the test or benchmark function at a line of a _test.go file,
+// and the t.Run subtests enclosing the line, outermost first
+type TestAt struct {
+	Func string   `desc:"name of the test function"`
+	Kind string   `desc:"kind of test: Test, Benchmark, Fuzz or Example"`
+	Subs []string `desc:"names of the enclosing subtests, outermost first"`
+	Line int      `desc:"0-based line of the innermost one"`
+}
+
+// Name returns the full name of the test, as reported by go test
+func (ta *TestAt) Name() string {
+	nm := ta.Func
+	for _, sb := range ta.Subs {
+		nm += "/" + SubTestName(sb)
+	}
+	return nm
+}
+
+// SubTestName returns the name of a subtest as rewritten by the testing
+// package: spaces as underscores
+func SubTestName(nm string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '\t' || r == '\n' {
+			return '_'
+		}
+		return r
+	}, nm)
+}
+
+// RunRegexp returns the -run (or -bench) regexp selecting only this test,
+// one anchored element per level -- braces are escaped as classes, so they
+// are left as is by the command arg vars
+func (ta *TestAt) RunRegexp() string {
+	els := []string{ta.Func}
+	for _, sb := range ta.Subs {
+		els = append(els, strings.Split(SubTestName(sb), "/")...)
+	}
+	br := strings.NewReplacer(`\{`, `[{]`, `\}`, `[}]`)
+	for i, el := range els {
+		els[i] = "^" + br.Replace(regexp.QuoteMeta(el)) + "$"
+	}
+	return strings.Join(els, "/")
+}
+
+// TestArgs returns the go test args running only this test, verbose --
+// benchmarks run without the tests
+func (ta *TestAt) TestArgs() []string {
+	re := ta.RunRegexp()
+	if ta.Kind == "Benchmark" {
+		return []string{"test", "-v", "-run", "^$", "-bench", re, "-benchmem", "."}
+	}
+	return []string{"test", "-v", "-run", re, "."}
+}
+
+// DebugArgs returns the args of the test binary running only this test,
+// for the debugger
+func (ta *TestAt) DebugArgs() []string {
+	re := ta.RunRegexp()
+	if ta.Kind == "Benchmark" {
+		return []string{"-test.run", "^$", "-test.bench", re}
+	}
+	return []string{"-test.run", re}
+}
+
+// braceDelta returns the change of brace depth over given line, skipping
+// strings, runes and line comments -- inRaw is the state of a raw string
+// spanning lines
+func braceDelta(ln []byte, inRaw *bool) int {
+	d := 0
+	var quote byte
+	if *inRaw {
+		quote = '`'
+	}
+	for i := 0; i < len(ln); i++ {
+		c := ln[i]
+		if quote != 0 {
+			switch {
+			case c == '\\' && quote != '`':
+				i++
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '/':
+			if i+1 < len(ln) && ln[i+1] == '/' {
+				return d
+			}
+		case '{':
+			d++
+		case '}':
+			d--
+		}
+	}
+	*inRaw = quote == '`'
+	return d
+}
+
+// TestFuncAt returns the name and kind of the test function declared at
+// given line, false if none
+func TestFuncAt(ln []byte) (string, string, bool) {
+	m := TestFuncRe.FindSubmatch(ln)
+	if m == nil || !IsTestFuncName(string(m[1]), string(m[2])) {
+		return "", "", false
+	}
+	return string(m[1]), string(m[2]), true
+}
+
+// SubTestAt returns the name of the subtest started at given line, false
+// if none
+func SubTestAt(ln []byte) (string, bool) {
+	m := SubTestRe.FindSubmatch(ln)
+	if m == nil {
+		return "", false
+	}
+	lit := string(m[1])
+	if lit[0] == '`' {
+		return lit[1 : len(lit)-1], true
+	}
+	nm, err := strconv.Unquote(lit)
+	return nm, err == nil
+}
+
+// TestAtLine returns the test function enclosing given 0-based line of the
+// lines of a _test.go file, with its subtests enclosing the line -- nil if
+// none
+func TestAtLine(lines [][]byte, ln int) *TestAt {
+	if ln < 0 || ln >= len(lines) {
+		return nil
+	}
+	st := -1
+	for i := ln; i >= 0; i-- {
+		if _, _, ok := TestFuncAt(lines[i]); ok {
+			st = i
+			break
+		}
+		if i < ln && len(lines[i]) > 0 && lines[i][0] == '}' {
+			return nil // end of a previous func
+		}
+	}
+	if st < 0 {
+		return nil
+	}
+	fn, kind, _ := TestFuncAt(lines[st])
+	ta := &TestAt{Func: fn, Kind: kind, Line: st}
+	type sub struct {
+		name  string
+		line  int
+		depth int
+	}
+	var subs []sub
+	depth := 0
+	inRaw := false
+	for i := st; i <= ln; i++ {
+		if i > st && !inRaw {
+			if nm, ok := SubTestAt(lines[i]); ok {
+				subs = append(subs, sub{nm, i, depth})
+			}
+		}
+		depth += braceDelta(lines[i], &inRaw)
+		for len(subs) > 0 && depth <= subs[len(subs)-1].depth && i > subs[len(subs)-1].line {
+			subs = subs[:len(subs)-1]
+		}
+		if depth <= 0 && i > st {
+			if i < ln {
+				return nil // after the end of the func
+			}
+			break
+		}
+	}
+	for _, sb := range subs {
+		ta.Subs = append(ta.Subs, sb.name)
+		ta.Line = sb.line
+	}
+	return ta
+}
+
+// TextBufLines returns the lines of given buffer
+func TextBufLines(tb *giv.TextBuf) [][]byte {
+	n := tb.NumLines()
+	lns := make([][]byte, n)
+	for ln := 0; ln < n; ln++ {
+		lns[ln] = tb.BytesLine(ln)
+	}
+	return lns
+}
+
+// IsTestFile returns true if given buffer is a Go _test.go file
+func IsTestFile(tb *giv.TextBuf) bool {
+	return tb != nil && tb.Info.Sup == filecat.Go && strings.HasSuffix(string(tb.Filename), "_test.go")
+}
+
+// TestAtBufLine returns the test at given line of given buffer, nil if
+// none or not a _test.go file
+func TestAtBufLine(tb *giv.TextBuf, ln int) *TestAt {
+	if !IsTestFile(tb) {
+		return nil
+	}
+	return TestAtLine(TextBufLines(tb), ln)
+}
+
+// MarkTests colors the line number gutter of the lines starting the test
+// functions and the string literal subtests of given _test.go buffer,
+// replacing the previous marks -- lines with other colors, e.g.,
+// breakpoints, are left as is -- returns the number of lines marked
+func MarkTests(tb *giv.TextBuf) int {
+	if tb == nil {
+		return 0
+	}
+	olns, had := tb.Prop(TestLinesProp).(map[int]bool)
+	if had {
+		for ln := range olns {
+			tb.DeleteLineColor(ln)
+		}
+		tb.DeleteProp(TestLinesProp)
+	}
+	mlns := map[int]bool{}
+	if IsTestFile(tb) {
+		for ln := 0; ln < tb.NumLines(); ln++ {
+			lb := tb.BytesLine(ln)
+			_, _, isfn := TestFuncAt(lb)
+			if !isfn && !SubTestRe.Match(lb) || tb.HasLineColor(ln) {
+				continue
+			}
+			tb.SetLineColor(ln, TestAtColor)
+			mlns[ln] = true
+		}
+	}
+	if len(mlns) > 0 {
+		tb.SetProp(TestLinesProp, mlns)
+	}
+	if had || len(mlns) > 0 {
+		tb.RefreshViews()
+	}
+	return len(mlns)
+}
+
+// UpdateTestMarks updates the test marks of given buffer for given edit,
+// only if it spans lines or is on a marked line or a line to be marked
+func UpdateTestMarks(tb *giv.TextBuf, tbe *textbuf.Edit) {
+	if tbe == nil || !IsTestFile(tb) {
+		return
+	}
+	ln := tbe.Reg.Start.Ln
+	if tbe.Reg.End.Ln != ln || IsTestLine(tb, ln) {
+		MarkTests(tb)
+		return
+	}
+	if ln < tb.NumLines() {
+		lb := tb.BytesLine(ln)
+		if bytes.Contains(lb, []byte(".Run(")) || TestFuncRe.Match(lb) {
+			MarkTests(tb)
+		}
+	}
+}
+
+// IsTestLine returns true if the color of given line of the buffer marks
+// the start of a test or subtest
+func IsTestLine(tb *giv.TextBuf, ln int) bool {
+	lns, ok := tb.Prop(TestLinesProp).(map[int]bool)
+	return ok && lns[ln]
+}
+
+// UnmarkTestLine removes given line from the test marks, when its color is
+// replaced, e.g., by a breakpoint
+func UnmarkTestLine(tb *giv.TextBuf, ln int) {
+	if lns, ok := tb.Prop(TestLinesProp).(map[int]bool); ok && lns[ln] {
+		delete(lns, ln)
+		tb.DeleteLineColor(ln)
+	}
+}
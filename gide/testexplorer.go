@@ -0,0 +1,852 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// TestStatus is the status of a test in the test explorer
+type TestStatus int32
+
+const (
+	// TestNotRun is a test that has not been run yet
+	TestNotRun TestStatus = iota
+
+	// TestRunning is a test being run
+	TestRunning
+
+	// TestPassed is a test that passed in its last run
+	TestPassed
+
+	// TestFailed is a test that failed in its last run (or whose package
+	// failed to build)
+	TestFailed
+
+	// TestSkipped is a test that was skipped in its last run
+	TestSkipped
+
+	// TestStatusN is the number of test statuses
+	TestStatusN
+)
+
+//go:generate stringer -type=TestStatus
+
+var KiT_TestStatus = kit.Enums.AddEnumAltLower(TestStatusN, kit.NotBitFlag, nil, "Test")
+
+func (ev TestStatus) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *TestStatus) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// TestStatusIcons are the symbols shown for each status in the test explorer
+var TestStatusIcons = [TestStatusN]string{"○", "…", "✔", "✖", "⊘"}
+
+// TestKinds are the prefixes of the names of the test functions, which
+// are also the Kind of their TestNode
+var TestKinds = []string{"Test", "Benchmark", "Fuzz", "Example"}
+
+// TestFuncRe matches the declaration of a test, benchmark, fuzz target or
+// example function, with the name and kind as submatches
+var TestFuncRe = regexp.MustCompile(`^func\s+((Test|Benchmark|Fuzz|Example)\w*)\s*\(`)
+
+// IsTestFuncName returns true if given name is that of a test function of
+// given kind: after the kind, it must not start with a lower-case letter
+// -- TestMain is not a test
+func IsTestFuncName(name, kind string) bool {
+	if name == "TestMain" || !strings.HasPrefix(name, kind) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(kind):])
+	return !unicode.IsLower(r)
+}
+
+// TestScanFile returns the test functions in given _test.go file, as
+// TestNodes
+func TestScanFile(fpath string) []*TestNode {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var tns []*TestNode
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for ln := 1; sc.Scan(); ln++ {
+		m := TestFuncRe.FindSubmatch(sc.Bytes())
+		if m == nil || !IsTestFuncName(string(m[1]), string(m[2])) {
+			continue
+		}
+		tn := &TestNode{Kind: string(m[2]), FPath: fpath, Line: ln}
+		tn.InitName(tn, string(m[1]))
+		tns = append(tns, tn)
+	}
+	return tns
+}
+
+// TestNode is a node in the tree of tests of the test explorer: a package
+// (directory), a _test.go file in it, or a test function in the file
+type TestNode struct {
+	ki.Node
+	Kind    string     `desc:"kind of node: package, file, or one of TestKinds for a test function"`
+	FPath   string     `desc:"directory of a package, or full path of the file of a file or function"`
+	Line    int        `desc:"line of the function in its file, starting at 1"`
+	Status  TestStatus `desc:"status of the last run"`
+	Elapsed float64    `desc:"seconds taken by the last run"`
+	Output  string     `desc:"output of the last run"`
+}
+
+var KiT_TestNode = kit.Types.AddType(&TestNode{}, ki.Props{"EnumType:Flag": ki.KiT_Flags})
+
+// IsFunc returns true if the node is a test function
+func (tn *TestNode) IsFunc() bool {
+	return tn.Kind != "package" && tn.Kind != "file"
+}
+
+// Label returns the name of the node with its status and time taken
+func (tn *TestNode) Label() string {
+	lbl := TestStatusIcons[tn.Status] + " " + tn.Nm
+	if tn.Status == TestPassed || tn.Status == TestFailed {
+		lbl += fmt.Sprintf(" (%.2fs)", tn.Elapsed)
+	}
+	return lbl
+}
+
+// Pkg returns the package node of the node
+func (tn *TestNode) Pkg() *TestNode {
+	for k := ki.Ki(tn); k != nil; k = k.Parent() {
+		if pn, ok := k.(*TestNode); ok && pn.Kind == "package" {
+			return pn
+		}
+	}
+	return nil
+}
+
+// Funcs returns the test function nodes in the node, of given kinds (all if none)
+func (tn *TestNode) Funcs(kinds ...string) []*TestNode {
+	var fns []*TestNode
+	tn.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d interface{}) bool {
+		fn := k.(*TestNode)
+		if !fn.IsFunc() {
+			return ki.Continue
+		}
+		if len(kinds) == 0 {
+			fns = append(fns, fn)
+			return ki.Continue
+		}
+		for _, kd := range kinds {
+			if fn.Kind == kd {
+				fns = append(fns, fn)
+				break
+			}
+		}
+		return ki.Continue
+	})
+	return fns
+}
+
+// ScanTests re-builds the tree of tests from the _test.go files in the
+// project at given root, except the excluded and ignored ones -- the
+// statuses of tests found before are kept
+func (tn *TestNode) ScanTests(root string, exclude []string, ign *FileIgnore) {
+	type prev struct {
+		stat TestStatus
+		el   float64
+		out  string
+	}
+	old := map[string]prev{}
+	for _, fn := range tn.Funcs() {
+		old[fn.FPath+":"+fn.Nm] = prev{fn.Status, fn.Elapsed, fn.Output}
+	}
+	files := map[string][]string{}
+	ign.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || pth == root {
+			return nil
+		}
+		rel, rerr := filepath.Rel(root, pth)
+		if rerr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if QuickOpenSkip(rel, info.IsDir(), exclude) || ign.Ignored(pth, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && strings.HasSuffix(pth, "_test.go") {
+			files[filepath.Dir(pth)] = append(files[filepath.Dir(pth)], pth)
+		}
+		return nil
+	})
+	dirs := make([]string, 0, len(files))
+	for d := range files {
+		dirs = append(dirs, d)
+	}
+	sort.Strings(dirs)
+	updt := tn.UpdateStart()
+	tn.DeleteChildren(ki.DestroyKids)
+	tn.Kind = "package"
+	tn.FPath = root
+	for _, d := range dirs {
+		rel, _ := filepath.Rel(root, d)
+		pn := tn.AddNewChild(KiT_TestNode, filepath.ToSlash(rel)).(*TestNode)
+		pn.Kind = "package"
+		pn.FPath = d
+		sort.Strings(files[d])
+		for _, f := range files[d] {
+			fns := TestScanFile(f)
+			if len(fns) == 0 {
+				continue
+			}
+			fnd := pn.AddNewChild(KiT_TestNode, filepath.Base(f)).(*TestNode)
+			fnd.Kind = "file"
+			fnd.FPath = f
+			for _, fn := range fns {
+				if p, has := old[fn.FPath+":"+fn.Nm]; has {
+					fn.Status, fn.Elapsed, fn.Output = p.stat, p.el, p.out
+				}
+				fnd.AddChild(fn)
+			}
+			fnd.UpdateStatus()
+		}
+		if pn.HasChildren() {
+			pn.UpdateStatus()
+		} else {
+			pn.Delete(ki.DestroyKids)
+		}
+	}
+	tn.UpdateEnd(updt)
+}
+
+// UpdateStatus sets the status of a file or package node from that of its
+// children: running or failed if any is, passed if any passed, and skipped
+// if all were skipped
+func (tn *TestNode) UpdateStatus() {
+	if tn.IsFunc() {
+		return
+	}
+	var n [TestStatusN]int
+	el := 0.0
+	for _, k := range tn.Kids {
+		cn := k.(*TestNode)
+		n[cn.Status]++
+		el += cn.Elapsed
+	}
+	switch {
+	case n[TestRunning] > 0:
+		tn.Status = TestRunning
+	case n[TestFailed] > 0:
+		tn.Status = TestFailed
+	case n[TestPassed] > 0:
+		tn.Status = TestPassed
+	case n[TestSkipped] > 0 && n[TestSkipped] == len(tn.Kids):
+		tn.Status = TestSkipped
+	default:
+		tn.Status = TestNotRun
+	}
+	if tn.Kind == "file" {
+		tn.Elapsed = el
+	}
+}
+
+// TestEvent is one event of the output of go test -json
+type TestEvent struct {
+	Action  string  `desc:"run, pause, cont, pass, bench, fail, output or skip"`
+	Package string  `desc:"import path of the package"`
+	Test    string  `desc:"name of the test, with / separated subtests -- empty for package events"`
+	Elapsed float64 `desc:"seconds taken, for pass and fail"`
+	Output  string  `desc:"output text, for output"`
+}
+
+// ApplyTestOutput applies given line of the output of go test -json, run
+// for package node pn, to the output and status of the package and of its
+// given test functions, by name -- returns the status of the package
+// given its status so far, stat, and true if the status of a function
+// changed.  Lines that are not events (build errors etc) are output of the
+// package.
+func ApplyTestOutput(pn *TestNode, funcs map[string]*TestNode, stat TestStatus, ln []byte) (TestStatus, bool) {
+	ev := TestEvent{}
+	if len(ln) == 0 || ln[0] != '{' || json.Unmarshal(ln, &ev) != nil {
+		pn.Output += string(ln) + "\n"
+		return stat, false
+	}
+	if ev.Test == "" {
+		switch ev.Action {
+		case "output":
+			pn.Output += ev.Output
+		case "pass":
+			stat = TestPassed
+			pn.Elapsed = ev.Elapsed
+		case "skip":
+			stat = TestSkipped
+		case "fail":
+			stat = TestFailed
+			pn.Elapsed = ev.Elapsed
+		}
+		return stat, false
+	}
+	top := strings.SplitN(ev.Test, "/", 2)[0]
+	fn, has := funcs[top]
+	if !has {
+		return stat, false
+	}
+	if ev.Action == "output" {
+		fn.Output += ev.Output
+		return stat, false
+	}
+	if ev.Test != top { // subtests only count for their output
+		return stat, false
+	}
+	switch ev.Action {
+	case "pass", "bench":
+		fn.Status, fn.Elapsed = TestPassed, ev.Elapsed
+	case "fail":
+		fn.Status, fn.Elapsed = TestFailed, ev.Elapsed
+	case "skip":
+		fn.Status, fn.Elapsed = TestSkipped, ev.Elapsed
+	default:
+		return stat, false
+	}
+	return stat, true
+}
+
+// TestRunArgs returns the go test -json arguments for running given test
+// functions of a package -- all of its tests and examples if none
+func TestRunArgs(fns []*TestNode) []string {
+	args := []string{"test", "-json"}
+	if len(fns) == 0 {
+		return append(args, ".")
+	}
+	var runs, benches []string
+	for _, fn := range fns {
+		if fn.Kind == "Benchmark" {
+			benches = append(benches, regexp.QuoteMeta(fn.Nm))
+		} else {
+			runs = append(runs, regexp.QuoteMeta(fn.Nm))
+		}
+	}
+	if len(runs) > 0 {
+		args = append(args, "-run", "^("+strings.Join(runs, "|")+")$")
+	} else {
+		args = append(args, "-run", "^$")
+	}
+	if len(benches) > 0 {
+		args = append(args, "-bench", "^("+strings.Join(benches, "|")+")$", "-benchmem")
+	}
+	return append(args, ".")
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    TestsView
+
+// TestsView is the test explorer: a tree of the tests of the project, by
+// package and file, with their status, for running them and showing their
+// output
+type TestsView struct {
+	gi.Layout
+	Gide    Gide      `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Tests   *TestNode `json:"-" xml:"-" copy:"-" desc:"root of the tree of tests"`
+	Cur     *TestNode `json:"-" xml:"-" copy:"-" desc:"node whose output is shown"`
+	Running bool      `json:"-" xml:"-" desc:"true while tests are being run"`
+}
+
+var KiT_TestsView = kit.Types.AddType(&TestsView{}, TestsViewProps)
+
+// TestsCmdName is the name of the test runs in the CmdRuns of the project,
+// for stopping them
+var TestsCmdName = "Test Explorer"
+
+// Config configures the view to show the tests of given project
+func (tv *TestsView) Config(ge Gide) {
+	tv.Gide = ge
+	tv.Lay = gi.LayoutVert
+	tv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "testsbar")
+	config.Add(gi.KiT_SplitView, "testssplit")
+	mods, updt := tv.ConfigChildren(config)
+	if !mods {
+		updt = tv.UpdateStart()
+	}
+	tv.ConfigToolbar()
+	tv.ConfigSplitView()
+	tv.UpdateEnd(updt)
+}
+
+// ToolBar returns the tests toolbar
+func (tv *TestsView) ToolBar() *gi.ToolBar {
+	return tv.ChildByName("testsbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the tree and the output
+func (tv *TestsView) SplitView() *gi.SplitView {
+	return tv.ChildByName("testssplit", 1).(*gi.SplitView)
+}
+
+// TreeView returns the tree view of the tests
+func (tv *TestsView) TreeView() *TestTreeView {
+	return tv.SplitView().ChildByName("tests", 0).Child(0).(*TestTreeView)
+}
+
+// OutView returns the TextView showing the output of the selected test
+func (tv *TestsView) OutView() *giv.TextView {
+	ly := tv.SplitView().ChildByName("output", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (tv *TestsView) ConfigToolbar() {
+	tb := tv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "re-scan the project files for tests"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TestsView).(*TestsView)
+			tvv.Scan()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Run", Icon: "play", Tooltip: "run the selected test, or the tests of the selected file or package"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TestsView).(*TestsView)
+			tvv.RunSelected()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Run All", Icon: "fast-fwd", Tooltip: "run the tests of all the packages"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TestsView).(*TestsView)
+			tvv.RunAll()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Run Failed", Icon: "reset", Tooltip: "run again only the tests that failed in their last run"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TestsView).(*TestsView)
+			tvv.RunFailed()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop the tests being run"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TestsView).(*TestsView)
+			tvv.Gide.CmdRuns().KillByName(TestsCmdName)
+		})
+}
+
+// ConfigSplitView configures the split view of the tree and the output
+func (tv *TestsView) ConfigSplitView() {
+	split := tv.SplitView()
+	split.Dim = mat32.X
+	if len(split.Kids) > 0 {
+		return
+	}
+	fr := gi.AddNewFrame(split, "tests", gi.LayoutVert)
+	fr.SetProp("height", units.NewEm(5)) // enables scrolling
+	fr.SetStretchMaxWidth()
+	fr.SetStretchMaxHeight()
+	tv.Tests = &TestNode{Kind: "package"}
+	tv.Tests.InitName(tv.Tests, "tests")
+	ttv := fr.AddNewChild(KiT_TestTreeView, "treeview").(*TestTreeView)
+	ttv.SetRootNode(tv.Tests)
+	ttv.TreeViewSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if data == nil || sig != int64(giv.TreeViewSelected) {
+			return
+		}
+		tvv, _ := recv.Embed(KiT_TestsView).(*TestsView)
+		ttn, _ := data.(ki.Ki).Embed(KiT_TestTreeView).(*TestTreeView)
+		if tn := ttn.TestNode(); tn != nil {
+			tvv.SelectTest(tn)
+		}
+	})
+	ly := gi.AddNewLayout(split, "output", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.4, .6)
+}
+
+// Scan re-scans the project files for tests, and shows them
+func (tv *TestsView) Scan() {
+	ge := tv.Gide
+	tv.Tests.ScanTests(string(ge.ProjPrefs().ProjRoot), FindGlobs(ge.ProjPrefs().Find.Exclude), ge.FileIgnore())
+	tv.UpdateTree()
+	ttv := tv.TreeView()
+	ttv.OpenAll()
+	ge.SetStatus(fmt.Sprintf("found %d tests in %d packages", len(tv.Tests.Funcs()), len(tv.Tests.Kids)))
+}
+
+// UpdateTree updates the tree view for changes of the tests
+func (tv *TestsView) UpdateTree() {
+	fr := tv.SplitView().ChildByName("tests", 0).(*gi.Frame)
+	updt := fr.UpdateStart()
+	fr.SetFullReRender()
+	tv.TreeView().ReSync()
+	fr.UpdateEnd(updt)
+}
+
+// SelectTest shows the output of the last run of given node, and the
+// function of a test in the editor
+func (tv *TestsView) SelectTest(tn *TestNode) {
+	tv.Cur = tn
+	tv.ShowOutput()
+	if tn.IsFunc() {
+		tv.Gide.ShowFile(tn.FPath, tn.Line)
+	}
+}
+
+// ShowOutput shows the output of the current node, with links to the
+// positions in it
+func (tv *TestsView) ShowOutput() {
+	otv := tv.OutView()
+	if otv.Buf == nil || tv.Cur == nil {
+		return
+	}
+	out := tv.Cur.Output
+	if !tv.Cur.IsFunc() {
+		var b strings.Builder
+		b.WriteString(out)
+		for _, fn := range tv.Cur.Funcs() {
+			b.WriteString(fn.Output)
+		}
+		out = b.String()
+	}
+	dir := tv.Cur.FPath
+	if tv.Cur.Kind != "package" {
+		dir = filepath.Dir(dir)
+	}
+	lns := bytes.Split([]byte(out), []byte("\n"))
+	mus := make([][]byte, len(lns))
+	for i, ln := range lns {
+		mus[i] = MarkupCmdOutput(TestOutputAbsPath(ln, dir))
+	}
+	otv.Buf.New(0)
+	otv.Buf.SetInactive(true)
+	otv.Buf.AppendTextMarkup([]byte(out), bytes.Join(mus, []byte("\n")), giv.EditSignal)
+}
+
+// TestOutputAbsPath returns given line of test output with a path:line
+// position at its start made absolute relative to given directory of the
+// package, so that it is a link to the file
+func TestOutputAbsPath(ln []byte, dir string) []byte {
+	m := ProblemRe.FindSubmatchIndex(ln)
+	if m == nil || filepath.IsAbs(string(ln[m[2]:m[3]])) {
+		return ln
+	}
+	pth := filepath.Join(dir, string(ln[m[2]:m[3]]))
+	return append(append(append([]byte{}, ln[:m[2]]...), pth...), ln[m[3]:]...)
+}
+
+// RunSelected runs the selected test, or the tests of the selected file or
+// package
+func (tv *TestsView) RunSelected() {
+	sel := tv.TreeView().SelectedSrcNodes()
+	if len(sel) == 0 {
+		tv.Gide.SetStatus("select a test, file or package to run")
+		return
+	}
+	tn := sel[0].(*TestNode)
+	switch {
+	case tn == tv.Tests:
+		tv.RunAll()
+	case tn.Kind == "package":
+		tv.Run(map[*TestNode][]*TestNode{tn: nil})
+	case tn.Kind == "file":
+		tv.Run(map[*TestNode][]*TestNode{tn.Pkg(): tn.Funcs()})
+	default:
+		tv.Run(map[*TestNode][]*TestNode{tn.Pkg(): {tn}})
+	}
+}
+
+// RunAll runs the tests of all the packages
+func (tv *TestsView) RunAll() {
+	pkgs := map[*TestNode][]*TestNode{}
+	for _, k := range tv.Tests.Kids {
+		pkgs[k.(*TestNode)] = nil
+	}
+	tv.Run(pkgs)
+}
+
+// RunFailed runs again the tests that failed in their last run
+func (tv *TestsView) RunFailed() {
+	pkgs := map[*TestNode][]*TestNode{}
+	for _, fn := range tv.Tests.Funcs() {
+		if fn.Status == TestFailed {
+			pkgs[fn.Pkg()] = append(pkgs[fn.Pkg()], fn)
+		}
+	}
+	if len(pkgs) == 0 {
+		tv.Gide.SetStatus("no failed tests")
+		return
+	}
+	tv.Run(pkgs)
+}
+
+// Run runs given test functions of each package (all of its tests if
+// none) in the background, one package at a time, updating their status
+// as they finish
+func (tv *TestsView) Run(pkgs map[*TestNode][]*TestNode) {
+	if tv.Running {
+		tv.Gide.SetStatus("tests are already running -- stop them first")
+		return
+	}
+	if len(pkgs) == 0 {
+		return
+	}
+	pns := make([]*TestNode, 0, len(pkgs))
+	for pn := range pkgs {
+		pns = append(pns, pn)
+	}
+	sort.Slice(pns, func(i, j int) bool {
+		return pns[i].FPath < pns[j].FPath
+	})
+	tv.Running = true
+	go func() {
+		defer func() { tv.Running = false }()
+		nfail := 0
+		for _, pn := range pns {
+			if !tv.RunPkg(pn, pkgs[pn]) {
+				nfail++
+			}
+		}
+		tv.Gide.SetStatus(fmt.Sprintf("tests run in %d packages: %d failed -- %v", len(pns), nfail, tv.Summary()))
+	}()
+}
+
+// Summary returns the number of tests passed and failed
+func (tv *TestsView) Summary() string {
+	var n [TestStatusN]int
+	for _, fn := range tv.Tests.Funcs() {
+		n[fn.Status]++
+	}
+	return fmt.Sprintf("%d passed, %d failed, %d skipped", n[TestPassed], n[TestFailed], n[TestSkipped])
+}
+
+// RunPkg runs given test functions of given package (all of its tests if
+// none) with go test -json, updating their status from its events as they
+// come in -- returns false if the package failed
+func (tv *TestsView) RunPkg(pn *TestNode, fns []*TestNode) bool {
+	ge := tv.Gide
+	args := TestRunArgs(fns)
+	if len(fns) == 0 {
+		fns = pn.Funcs("Test", "Fuzz", "Example")
+	}
+	tv.RunFuncs(pn, fns, args)
+	var out bytes.Buffer
+	out.WriteString(pn.Output)
+	for _, fn := range pn.Funcs() {
+		out.WriteString(fn.Output)
+	}
+	rel, _ := filepath.Rel(string(ge.ProjPrefs().ProjRoot), pn.FPath)
+	ge.SetProblems(TestsCmdName+" "+filepath.ToSlash(rel), ParseProblems(out.Bytes(), pn.FPath, TestsCmdName, ProblemError))
+	return pn.Status != TestFailed
+}
+
+// RunFuncs runs go test with given args in the directory of given package,
+// for given test functions
+func (tv *TestsView) RunFuncs(pn *TestNode, fns []*TestNode, args []string) {
+	ge := tv.Gide
+	funcs := map[string]*TestNode{}
+	for _, fn := range fns {
+		fn.Status = TestRunning
+		fn.Output = ""
+		funcs[fn.Nm] = fn
+	}
+	pn.Output = ""
+	pn.Status = TestRunning
+	tv.UpdateStatus(pn)
+	cmd := ge.ProjPrefs().ExecCmd(pn.FPath, nil, "go", args...)
+	cma := &CmdAndArgs{Cmd: "go", Args: args}
+	ge.CmdRuns().AddCmd(TestsCmdName, "go "+strings.Join(args, " "), cma, cmd)
+	defer ge.CmdRuns().DeleteByName(TestsCmdName)
+	stdout, err := cmd.StdoutPipe()
+	if err == nil {
+		cmd.Stderr = cmd.Stdout
+		err = cmd.Start()
+	}
+	if err != nil {
+		pn.Output = err.Error() + "\n"
+		pn.Status = TestFailed
+		tv.UpdateStatus(pn)
+		return
+	}
+	pkgStat := TestRunning
+	sc := bufio.NewScanner(stdout)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var updt bool
+		pkgStat, updt = ApplyTestOutput(pn, funcs, pkgStat, sc.Bytes())
+		if updt {
+			tv.UpdateStatus(pn)
+		}
+	}
+	if cmd.Wait() != nil && pkgStat == TestRunning {
+		pkgStat = TestFailed
+	}
+	for _, fn := range fns {
+		if fn.Status != TestRunning {
+			continue
+		}
+		if fn.Kind == "Benchmark" && pkgStat == TestPassed {
+			fn.Status = TestPassed // benchmarks only report output
+		} else if pkgStat == TestFailed {
+			fn.Status = TestFailed // did not build, or the run was stopped
+		} else {
+			fn.Status = TestNotRun
+		}
+	}
+	tv.UpdateStatus(pn)
+	if pkgStat == TestFailed {
+		pn.Status = TestFailed
+	}
+	tv.UpdateTreeSafe()
+}
+
+// UpdateStatus updates the status of the files of given package, and the
+// package, and the tree -- called as the status of its tests changes
+func (tv *TestsView) UpdateStatus(pn *TestNode) {
+	stat := pn.Status
+	for _, k := range pn.Kids {
+		k.(*TestNode).UpdateStatus()
+	}
+	pn.UpdateStatus()
+	if stat == TestRunning && pn.Status == TestNotRun {
+		pn.Status = TestRunning
+	}
+	tv.UpdateTreeSafe()
+}
+
+// UpdateTreeSafe updates the tree view and the output from the running
+// goroutine
+func (tv *TestsView) UpdateTreeSafe() {
+	if tv.This() == nil || tv.IsDeleted() || tv.IsDestroyed() {
+		return
+	}
+	vp := tv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	tv.UpdateTree()
+	if tv.Cur != nil {
+		tv.ShowOutput()
+	}
+	vp.TopUpdateEnd(wupdt)
+}
+
+// TestsViewProps are style properties for TestsView
+var TestsViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
+
+/////////////////////////////////////////////////////////////////////////////
+// TestTreeView
+
+// TestTreeView is a TreeView of TestNode nodes
+type TestTreeView struct {
+	giv.TreeView
+}
+
+var KiT_TestTreeView = kit.Types.AddType(&TestTreeView{}, nil)
+
+func init() {
+	kit.Types.SetProps(KiT_TestTreeView, TestTreeViewProps)
+}
+
+// TestNode returns the SrcNode as a TestNode
+func (tt *TestTreeView) TestNode() *TestNode {
+	tn := tt.SrcNode.Embed(KiT_TestNode)
+	if tn == nil {
+		return nil
+	}
+	return tn.(*TestNode)
+}
+
+// TestStatusColors are the colors of the tests of each status in the tree
+var TestStatusColors = [TestStatusN]string{"", "#1e88e5", "#43a047", "#e53935", "#9e9e9e"}
+
+func (tt *TestTreeView) Style2D() {
+	tn := tt.TestNode()
+	tt.Class = ""
+	if tn != nil {
+		switch {
+		case tn.Kind == "package":
+			tt.Icon = gi.IconName("folder")
+		case tn.Kind == "file":
+			tt.Icon = gi.IconName("file-code")
+		default:
+			tt.Icon = gi.IconName("function")
+		}
+		if clr := TestStatusColors[tn.Status]; clr != "" {
+			tt.SetProp("color", clr)
+		} else {
+			tt.DeleteProp("color")
+		}
+	}
+	tt.StyleTreeView()
+	tt.LayState.SetFromStyle(&tt.Sty.Layout) // also does reset
+}
+
+var TestTreeViewProps = ki.Props{
+	"EnumType:Flag":    giv.KiT_TreeViewFlags,
+	"indent":           units.NewValue(2, units.Ch),
+	"spacing":          units.NewValue(.5, units.Ch),
+	"border-width":     units.NewValue(0, units.Px),
+	"border-radius":    units.NewValue(0, units.Px),
+	"padding":          units.NewValue(0, units.Px),
+	"margin":           units.NewValue(1, units.Px),
+	"text-align":       gist.AlignLeft,
+	"vertical-align":   gist.AlignTop,
+	"color":            &gi.Prefs.Colors.Font,
+	"background-color": "inherit",
+	"#icon": ki.Props{
+		"width":   units.NewValue(1, units.Em),
+		"height":  units.NewValue(1, units.Em),
+		"margin":  units.NewValue(0, units.Px),
+		"padding": units.NewValue(0, units.Px),
+		"fill":    &gi.Prefs.Colors.Icon,
+		"stroke":  &gi.Prefs.Colors.Font,
+	},
+	"#branch": ki.Props{
+		"icon":             "wedge-down",
+		"icon-off":         "wedge-right",
+		"margin":           units.NewValue(0, units.Px),
+		"padding":          units.NewValue(0, units.Px),
+		"background-color": color.Transparent,
+		"max-width":        units.NewValue(.8, units.Em),
+		"max-height":       units.NewValue(.8, units.Em),
+	},
+	"#space": ki.Props{
+		"width": units.NewValue(.5, units.Em),
+	},
+	"#label": ki.Props{
+		"margin":    units.NewValue(0, units.Px),
+		"padding":   units.NewValue(0, units.Px),
+		"min-width": units.NewValue(16, units.Ch),
+	},
+	"#menu": ki.Props{
+		"indicator": "none",
+	},
+	giv.TreeViewSelectors[giv.TreeViewActive]: ki.Props{},
+	giv.TreeViewSelectors[giv.TreeViewSel]: ki.Props{
+		"background-color": &gi.Prefs.Colors.Select,
+	},
+	giv.TreeViewSelectors[giv.TreeViewFocus]: ki.Props{
+		"background-color": &gi.Prefs.Colors.Control,
+	},
+	"CtxtMenuActive": ki.PropSlice{},
+}
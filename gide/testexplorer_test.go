@@ -0,0 +1,72 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyTestOutput(t *testing.T) {
+	pn := &TestNode{Kind: "package"}
+	fa := &TestNode{Kind: "Test"}
+	fb := &TestNode{Kind: "Test"}
+	funcs := map[string]*TestNode{"TestA": fa, "TestB": fb}
+	tests := []struct {
+		ln   string
+		stat TestStatus
+		upd  bool
+	}{
+		{`# example.com/p`, TestRunning, false},
+		{`{"Action":"run","Test":"TestA"}`, TestRunning, false},
+		{`{"Action":"output","Test":"TestA","Output":"=== RUN   TestA\n"}`, TestRunning, false},
+		{`{"Action":"output","Test":"TestA/sub","Output":"    sub output\n"}`, TestRunning, false},
+		{`{"Action":"fail","Test":"TestA/sub","Elapsed":0.1}`, TestRunning, false},
+		{`{"Action":"pass","Test":"TestA","Elapsed":0.5}`, TestRunning, true},
+		{`{"Action":"skip","Test":"TestB","Elapsed":0}`, TestRunning, true},
+		{`{"Action":"pass","Test":"TestOther","Elapsed":1}`, TestRunning, false},
+		{`{"Action":"output","Output":"ok  \texample.com/p\t0.6s\n"}`, TestRunning, false},
+		{`{"Action":"fail","Elapsed":0.6}`, TestFailed, false},
+	}
+	stat := TestRunning
+	for _, tst := range tests {
+		var upd bool
+		stat, upd = ApplyTestOutput(pn, funcs, stat, []byte(tst.ln))
+		if stat != tst.stat || upd != tst.upd {
+			t.Errorf("ApplyTestOutput error: %v: should have been: %v %v  was: %v %v\n", tst.ln, tst.stat, tst.upd, stat, upd)
+		}
+	}
+	want := []interface{}{TestPassed, 0.5, "=== RUN   TestA\n    sub output\n", TestSkipped, "", TestNotRun}
+	was := []interface{}{fa.Status, fa.Elapsed, fa.Output, fb.Status, fb.Output, pn.Status}
+	if !reflect.DeepEqual(was, want) {
+		t.Errorf("ApplyTestOutput error: funcs should have been: %v  was: %v\n", want, was)
+	}
+	if pn.Elapsed != 0.6 || !strings.HasPrefix(pn.Output, "# example.com/p\n") || !strings.HasSuffix(pn.Output, "0.6s\n") {
+		t.Errorf("ApplyTestOutput error: package output was: %v %q\n", pn.Elapsed, pn.Output)
+	}
+}
+
+func TestTestRunArgs(t *testing.T) {
+	ta := &TestNode{Kind: "Test"}
+	ta.Nm = "TestA"
+	ex := &TestNode{Kind: "Example"}
+	ex.Nm = "Example_x"
+	bn := &TestNode{Kind: "Benchmark"}
+	bn.Nm = "BenchmarkB"
+	tests := []struct {
+		fns  []*TestNode
+		args string
+	}{
+		{nil, "test -json ."},
+		{[]*TestNode{ta, ex}, "test -json -run ^(TestA|Example_x)$ ."},
+		{[]*TestNode{bn}, "test -json -run ^$ -bench ^(BenchmarkB)$ -benchmem ."},
+	}
+	for _, tst := range tests {
+		if args := strings.Join(TestRunArgs(tst.fns), " "); args != tst.args {
+			t.Errorf("TestRunArgs error: should have been: %v  was: %v\n", tst.args, args)
+		}
+	}
+}
@@ -2,16 +2,22 @@ package gide
 
 import (
 	"image"
+	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
 	"github.com/goki/gi/giv"
+	"github.com/goki/gi/giv/textbuf"
 	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/dnd"
 	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mimedata"
 	"github.com/goki/gi/oswin/mouse"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
 	"github.com/goki/pi/lex"
 	"github.com/goki/pi/token"
 )
@@ -20,8 +26,19 @@ import (
 // setting / clearing breakpoints, etc
 type TextView struct {
 	giv.TextView
+	Sticky       *StickyView `json:"-" xml:"-" view:"-" desc:"sticky view showing the lines of the declarations enclosing the top visible line, if present"`
+	RenderCursor *lex.Pos    `json:"-" xml:"-" view:"-" desc:"if set, the cursor is moved here after the next render, without scrolling -- used with ScrollToCursorOnRender to restore both the scroll and cursor positions"`
+	Zoom         float32     `json:"-" xml:"-" view:"-" desc:"zoom factor of the font of the view, relative to the editor font size -- 0 or 1 if not zoomed"`
 }
 
+// TextViewZoomStep is the factor by which each step of zooming an editor
+// pane in or out changes the size of its font
+var TextViewZoomStep = float32(1.1)
+
+// TextViewFontSize is the default font size (in points) of the editors, when
+// the project does not set one, used as the base of their zoom
+var TextViewFontSize = float32(12)
+
 var KiT_TextView = kit.Types.AddType(&TextView{}, giv.TextViewProps)
 
 // AddNewTextView adds a new textview to given parent node, with given name.
@@ -29,6 +46,137 @@ func AddNewTextView(parent ki.Ki, name string) *TextView {
 	return parent.AddNewChild(KiT_TextView, name).(*TextView)
 }
 
+// ZoomFactor returns the zoom factor of the font of the view, 1 if not zoomed
+func (tv *TextView) ZoomFactor() float32 {
+	if tv.Zoom <= 0 {
+		return 1
+	}
+	return tv.Zoom
+}
+
+// ZoomSteps zooms the font of the view in (steps > 0) or out (steps < 0) by
+// TextViewZoomStep per step, between 1/4 and 4 times the normal size, or
+// resets it for 0 steps -- the font size must then be set by the Gide
+func (tv *TextView) ZoomSteps(steps int) {
+	if steps == 0 {
+		tv.Zoom = 0
+		return
+	}
+	zm := tv.ZoomFactor() * mat32.Pow(TextViewZoomStep, float32(steps))
+	zm = mat32.Clamp(zm, 0.25, 4)
+	if mat32.Abs(zm-1) < 0.01 {
+		zm = 0
+	}
+	tv.Zoom = zm
+}
+
+// ContextMenu offers the spelling corrections of the misspelled word at
+// the cursor, if any, else shows the context menu
+func (tv *TextView) ContextMenu() {
+	if !tv.HasSelection() && tv.Buf != nil && tv.Buf.Spell != nil && tv.Buf.IsSpellEnabled(tv.CursorPos) {
+		if tv.OfferCorrect() {
+			return
+		}
+	}
+	tv.WidgetBase.ContextMenu()
+}
+
+// OfferCorrect pops up the spelling corrections of the misspelled word at
+// the cursor, as the TextView does, with an action adding it to the
+// dictionary of the project -- returns false if there is no such word
+func (tv *TextView) OfferCorrect() bool {
+	sc := tv.Buf.Spell
+	if sc == nil || tv.ISearch.On || tv.QReplace.On || tv.IsInactive() {
+		return false
+	}
+	ge, ok := ParentGide(tv)
+	if !ok {
+		return tv.TextView.OfferCorrect()
+	}
+	vp := tv.Viewport
+	if vp == nil || vp.Win == nil {
+		return false
+	}
+	sel := tv.SelectReg
+	if !tv.SelectWord() {
+		tv.SelectReg = sel
+		return false
+	}
+	tbe := tv.Selection()
+	tv.SelectReg = sel
+	if tbe == nil {
+		return false
+	}
+	wb := string(tbe.ToBytes())
+	if strings.TrimLeft(wb, " \t") != wb {
+		return false // SelectWord captures leading whitespace
+	}
+	sugs, knwn := sc.CheckWord(wb)
+	learned := sc.IsLastLearned(wb)
+	if knwn && !learned {
+		return false
+	}
+	if !learned && len(sugs) == 1 && sugs[0] == wb {
+		return false
+	}
+	sc.SetWord(wb, sugs, tbe.Reg.Start.Ln, tbe.Reg.Start.Ch)
+	if cpop := vp.Win.CurPopup(); gi.PopupIsCorrector(cpop) {
+		vp.Win.SetDelPopup(cpop)
+	}
+
+	var m gi.Menu
+	if learned {
+		m.AddAction(gi.ActOpts{Label: "unlearn"},
+			sc.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				scf := recv.Embed(gi.KiT_Spell).(*gi.Spell)
+				scf.UnLearnLast()
+			})
+	} else {
+		if len(sugs) == 0 {
+			m.AddAction(gi.ActOpts{Label: "no suggestion"},
+				sc.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				})
+		}
+		for _, sg := range sugs {
+			m.AddAction(gi.ActOpts{Label: sg, Data: sg},
+				sc.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					scf := recv.Embed(gi.KiT_Spell).(*gi.Spell)
+					scf.Spell(data.(string))
+				})
+		}
+		m.AddSeparator("")
+		m.AddAction(gi.ActOpts{Label: "learn"},
+			sc.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				scf := recv.Embed(gi.KiT_Spell).(*gi.Spell)
+				scf.LearnWord()
+			})
+		m.AddAction(gi.ActOpts{Label: "ignore"},
+			sc.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				scf := recv.Embed(gi.KiT_Spell).(*gi.Spell)
+				scf.IgnoreWord()
+			})
+		m.AddAction(gi.ActOpts{Label: "add to project dictionary", Tooltip: "add the word to the dictionary file of the project, shared by the team"},
+			sc.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				scf := recv.Embed(gi.KiT_Spell).(*gi.Spell)
+				if err := ge.ProjSpellDict().Add(scf.Word); err != nil {
+					ge.SetStatus("could not add to the project dictionary: " + err.Error())
+					return
+				}
+				scf.IgnoreWord() // clears the misspelling
+				ge.SetStatus("added " + scf.Word + " to the project dictionary")
+			})
+	}
+	cpos := tv.CharStartPos(tv.CursorPos).ToPoint() // physical location
+	cpos.X += 5
+	cpos.Y += 10
+	tv.Buf.CurView = &tv.TextView
+	sc.Vp = vp
+	pvp := gi.PopupMenu(m, cpos.X, cpos.Y, vp, "tf-spellcheck-menu")
+	pvp.SetFlag(int(gi.VpFlagCorrector))
+	pvp.Child(0).SetProp("no-focus-name", true) // keys go to the popup, not the text
+	return true
+}
+
 // MakeContextMenu builds the textview context menu
 func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 	ac := m.AddAction(gi.ActOpts{Label: "Copy", ShortcutKey: gi.KeyFunCopy},
@@ -50,6 +198,12 @@ func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 				txf.Paste()
 			})
 		ac.SetActiveState(tv.HasSelection() && !tv.Buf.InComment(tv.CursorPos))
+		ac = m.AddAction(gi.ActOpts{Label: "Paste History...", ShortcutKey: gi.KeyFunPasteHist},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.PasteHist()
+			})
+		ac.SetActiveState(len(giv.TextViewClipHistory) > 0)
 
 		m.AddSeparator("sep-clip")
 
@@ -58,6 +212,68 @@ func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 				txf := recv.Embed(KiT_TextView).(*TextView)
 				txf.Lookup()
 			})
+		m.AddAction(gi.ActOpts{Label: "Send To Terminal"},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.SendToTerminal()
+			})
+		if _, ok := AvailRepls().ByLang(tv.Buf.Info.Sup); ok {
+			m.AddAction(gi.ActOpts{Label: "Eval In REPL"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					txf := recv.Embed(KiT_TextView).(*TextView)
+					txf.EvalInRepl()
+				})
+		}
+
+		m.AddSeparator("sep-hunk")
+		ac = m.AddAction(gi.ActOpts{Label: "Stage Hunk"},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.StageHunk(txf.CursorPos.Ln)
+			})
+		ac = m.AddAction(gi.ActOpts{Label: "Revert Hunk"},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.RevertHunk(txf.CursorPos.Ln)
+			})
+		ac = m.AddAction(gi.ActOpts{Label: "Copy Original Hunk"},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				txf.CopyOrigHunk(txf.CursorPos.Ln)
+			})
+
+		if tv.Buf.Info.Sup == filecat.Go {
+			ac = m.AddAction(gi.ActOpts{Label: "Run go:generate"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					txf := recv.Embed(KiT_TextView).(*TextView)
+					txf.RunGenerate(txf.CursorPos.Ln)
+				})
+			ac.SetActiveState(IsGenerateDirective(tv.Buf.BytesLine(tv.CursorPos.Ln)))
+		}
+		if IsTestFile(tv.Buf) {
+			ta := TestAtBufLine(tv.Buf, tv.CursorPos.Ln)
+			hasTest := ta != nil
+			ac = m.AddAction(gi.ActOpts{Label: "Run Test At Cursor"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					txf := recv.Embed(KiT_TextView).(*TextView)
+					txf.RunTestAt(txf.CursorPos.Ln)
+				})
+			ac.SetActiveState(hasTest)
+			ac = m.AddAction(gi.ActOpts{Label: "Debug Test At Cursor"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					txf := recv.Embed(KiT_TextView).(*TextView)
+					txf.DebugTestAt(txf.CursorPos.Ln)
+				})
+			ac.SetActiveState(hasTest)
+			ac = m.AddAction(gi.ActOpts{Label: "Fuzz Target At Cursor"},
+				tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					txf := recv.Embed(KiT_TextView).(*TextView)
+					if ge, ok := ParentGide(txf); ok {
+						ge.Fuzz()
+					}
+				})
+			ac.SetActiveState(ta != nil && ta.Kind == "Fuzz")
+		}
 
 		m.AddSeparator("sep-dbg")
 		hasDbg := false
@@ -93,6 +309,96 @@ func (tv *TextView) MakeContextMenu(m *gi.Menu) {
 	}
 }
 
+// Copy copies any selected text to the clipboard and its history, as the
+// TextView does, but not in the history if it looks like a secret
+func (tv *TextView) Copy(reset bool) *textbuf.Edit {
+	tbe := tv.TextView.Copy(reset)
+	ClipHistFilter()
+	return tbe
+}
+
+// Cut cuts any selected text to the clipboard and its history, as the
+// TextView does, but not in the history if it looks like a secret
+func (tv *TextView) Cut() *textbuf.Edit {
+	tbe := tv.TextView.Cut()
+	ClipHistFilter()
+	return tbe
+}
+
+// PasteHist pops up the clipboard history (the recent copies and cuts) at
+// the cursor, the chosen one being pasted there
+func (tv *TextView) PasteHist() {
+	ClipHistFilter()
+	ch := giv.TextViewClipHistory
+	if len(ch) == 0 {
+		if ge, ok := ParentGide(tv); ok {
+			ge.SetStatus("the clipboard history is empty")
+		}
+		return
+	}
+	var m gi.Menu
+	for i, clip := range ch {
+		tt := string(clip)
+		if len(tt) > 1000 {
+			tt = tt[:1000] + "..."
+		}
+		m.AddAction(gi.ActOpts{Label: ClipHistLabel(clip), Tooltip: tt, Data: i},
+			tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				txf := recv.Embed(KiT_TextView).(*TextView)
+				idx := data.(int)
+				if idx >= len(giv.TextViewClipHistory) {
+					return
+				}
+				clip := giv.TextViewClipHistory[idx]
+				wupdt := txf.TopUpdateStart()
+				defer txf.TopUpdateEnd(wupdt)
+				oswin.TheApp.ClipBoard(txf.ParentWindow().OSWin).Write(mimedata.NewTextBytes(clip))
+				txf.InsertAtCursor(clip)
+				txf.SavePosHistory(txf.CursorPos)
+			})
+	}
+	cpos := tv.CharStartPos(tv.CursorPos).ToPoint()
+	cpos.X += 5
+	cpos.Y += 10
+	gi.PopupMenu(m, cpos.X, cpos.Y, tv.Viewport, "tv-paste-hist")
+}
+
+// KeyInput handles the keys copying to and pasting from the clipboard
+// history, and passes the others to the TextView
+func (tv *TextView) KeyInput(kt *key.ChordEvent) {
+	if tv.ISearch.On || tv.QReplace.On {
+		tv.TextView.KeyInput(kt)
+		return
+	}
+	switch gi.KeyFun(kt.Chord()) {
+	case gi.KeyFunPasteHist:
+		if !tv.IsInactive() {
+			kt.SetProcessed()
+			tv.PasteHist()
+			return
+		}
+	case gi.KeyFunCopy, gi.KeyFunCut:
+		tv.TextView.KeyInput(kt)
+		ClipHistFilter()
+		return
+	}
+	tv.TextView.KeyInput(kt)
+}
+
+// Render2D renders the text view, moves the cursor to RenderCursor if set,
+// and then updates the Sticky view for the new top visible line
+func (tv *TextView) Render2D() {
+	tv.TextView.Render2D()
+	if tv.RenderCursor != nil && !tv.ScrollToCursorOnRender {
+		pos := *tv.RenderCursor
+		tv.RenderCursor = nil
+		tv.SetCursor(pos)
+	}
+	if tv.Sticky != nil {
+		tv.Sticky.UpdateLines()
+	}
+}
+
 func (tv *TextView) FocusChanged2D(change gi.FocusChanges) {
 	tv.TextView.FocusChanged2D(change)
 	ge, ok := ParentGide(tv)
@@ -124,6 +430,9 @@ func (tv *TextView) SetBreakpoint(ln int) {
 		return
 	}
 	// tv.Buf.SetLineIcon(ln, "stop")
+	UnmarkCoverLine(tv.Buf, ln)
+	UnmarkGenerateLine(tv.Buf, ln)
+	UnmarkTestLine(tv.Buf, ln)
 	tv.Buf.SetLineColor(ln, DebugBreakColors[DebugBreakInactive])
 	dbg.AddBreak(string(tv.Buf.Filename), ln+1)
 }
@@ -147,7 +456,7 @@ func (tv *TextView) HasBreakpoint(ln int) bool {
 		return false
 	}
 	_, has := tv.Buf.LineColors[ln]
-	return has
+	return has && !IsCoverLine(tv.Buf, ln) && !IsGenerateLine(tv.Buf, ln) && !IsTestLine(tv.Buf, ln)
 }
 
 func (tv *TextView) ToggleBreakpoint(ln int) {
@@ -181,6 +490,20 @@ func (tv *TextView) DebugVarValueAtPos(pos image.Point) string {
 	return ""
 }
 
+// LineNoAtPos returns the line whose line number is at given mouse
+// position, false if not over the line numbers
+func (tv *TextView) LineNoAtPos(pos image.Point) (int, bool) {
+	if tv.Buf == nil {
+		return 0, false
+	}
+	pt := tv.PointToRelPos(pos)
+	tpos := tv.PixelToCursor(pt)
+	if pt.X < 0 || pt.X >= int(tv.LineNoOff) || !tv.Buf.IsValidLine(tpos.Ln) {
+		return 0, false
+	}
+	return tpos.Ln, true
+}
+
 // FindFrames finds stack frames in the debugger containing this file and line
 func (tv *TextView) FindFrames(ln int) {
 	dbg, has := tv.CurDebug()
@@ -193,10 +516,96 @@ func (tv *TextView) FindFrames(ln int) {
 // LineNoDoubleClick processes double-clicks on the line-number section
 func (tv *TextView) LineNoDoubleClick(tpos lex.Pos) {
 	ln := tpos.Ln
+	if IsGenerateLine(tv.Buf, ln) {
+		tv.RunGenerate(ln)
+		return
+	}
+	if IsTestLine(tv.Buf, ln) {
+		tv.RunTestAt(ln)
+		return
+	}
 	tv.ToggleBreakpoint(ln)
 	tv.RenderLines(ln, ln)
 }
 
+// RunGenerate runs the //go:generate directive at given line
+func (tv *TextView) RunGenerate(ln int) {
+	if tv.Buf == nil || !IsGenerateDirective(tv.Buf.BytesLine(ln)) {
+		return
+	}
+	if ge, ok := ParentGide(tv); ok {
+		ge.RunGenerate(string(tv.Buf.Filename), ln)
+	}
+}
+
+// SendToTerminal sends the selection, or else the line of the cursor, to
+// the active terminal, as if typed -- after sending a line, the cursor moves
+// to the next one, for stepping through a script in a REPL
+func (tv *TextView) SendToTerminal() {
+	ge, ok := ParentGide(tv)
+	if !ok || tv.Buf == nil {
+		return
+	}
+	line := !tv.HasSelection()
+	var txt string
+	if line {
+		txt = string(tv.Buf.BytesLine(tv.CursorPos.Ln))
+	} else {
+		txt = string(tv.Selection().ToBytes())
+	}
+	if err := ge.SendToTerminal(txt); err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	if line && tv.CursorPos.Ln+1 < tv.Buf.NumLines() {
+		tv.SetCursorShow(lex.Pos{Ln: tv.CursorPos.Ln + 1})
+	}
+}
+
+// EvalInRepl evaluates the selection, or else the line of the cursor, in
+// the REPL console of the language of the file -- after evaluating a line,
+// the cursor moves to the next one
+func (tv *TextView) EvalInRepl() {
+	ge, ok := ParentGide(tv)
+	if !ok || tv.Buf == nil {
+		return
+	}
+	line := !tv.HasSelection()
+	var code string
+	if line {
+		code = string(tv.Buf.BytesLine(tv.CursorPos.Ln))
+	} else {
+		code = string(tv.Selection().ToBytes())
+	}
+	if err := ge.EvalInRepl(tv.Buf.Info.Sup, code); err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	if line && tv.CursorPos.Ln+1 < tv.Buf.NumLines() {
+		tv.SetCursorShow(lex.Pos{Ln: tv.CursorPos.Ln + 1})
+	}
+}
+
+// RunTestAt runs the test or subtest enclosing given line
+func (tv *TextView) RunTestAt(ln int) {
+	if TestAtBufLine(tv.Buf, ln) == nil {
+		return
+	}
+	if ge, ok := ParentGide(tv); ok {
+		ge.RunTestAt(string(tv.Buf.Filename), ln)
+	}
+}
+
+// DebugTestAt runs the debugger on the test or subtest enclosing given line
+func (tv *TextView) DebugTestAt(ln int) {
+	if TestAtBufLine(tv.Buf, ln) == nil {
+		return
+	}
+	if ge, ok := ParentGide(tv); ok {
+		ge.DebugTestAt(string(tv.Buf.Filename), ln)
+	}
+}
+
 // DoubleClickEvent processes double-clicks NOT on the line-number section
 func (tv *TextView) DoubleClickEvent(tpos lex.Pos) {
 	dbg, has := tv.CurDebug()
@@ -242,6 +651,12 @@ func (tv *TextView) HoverEvent() {
 		vv := tv.DebugVarValueAtPos(me.Pos())
 		if vv != "" {
 			tt = vv
+		} else if ln, ok := txf.LineNoAtPos(me.Pos()); ok && IsGenerateLine(txf.Buf, ln) {
+			tt = "double-click to run: " + string(txf.Buf.BytesLine(ln))
+		} else if ok && IsTestLine(txf.Buf, ln) {
+			if ta := TestAtBufLine(txf.Buf, ln); ta != nil {
+				tt = "double-click to run: " + ta.Name() + " (debug from the context menu)"
+			}
 		}
 		if tt != "" {
 			me.SetProcessed()
@@ -269,6 +684,58 @@ func (tv *TextView) TextViewEvents() {
 		kt := d.(*key.ChordEvent)
 		txf.KeyInput(kt)
 	})
+	tv.ConnectEvent(oswin.DNDEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		txf := recv.Embed(KiT_TextView).(*TextView)
+		de := d.(*dnd.Event)
+		switch de.Action {
+		case dnd.DropOnTarget:
+			txf.DropFile(de, true)
+		case dnd.External:
+			txf.DropFile(de, false)
+		}
+	})
+}
+
+// DropFile views the (first) file dropped onto the text view, either
+// dragged from the file tree (intl = true), or from outside the app
+func (tv *TextView) DropFile(de *dnd.Event, intl bool) {
+	fpath := ""
+	var ftv *giv.FileTreeView
+	if intl {
+		if de.Source == nil || len(de.Data) < 2 {
+			return
+		}
+		var ok bool
+		if ftv, ok = de.Source.Embed(giv.KiT_FileTreeView).(*giv.FileTreeView); !ok {
+			return // not files
+		}
+		fpath = string(de.Data[1].Data) // 0 = ki path, 1 = file path, 2 = file data
+	} else {
+		for _, d := range de.Data {
+			if d.Type == filecat.TextPlain {
+				fpath = strings.TrimPrefix(string(d.Data), "file://")
+				break
+			}
+		}
+	}
+	ge, ok := ParentGide(tv.This())
+	if !ok || fpath == "" {
+		return
+	}
+	vidx := ge.TextViewIndex(tv)
+	if vidx < 0 {
+		return
+	}
+	de.Target = tv.This()
+	de.SetProcessed()
+	if ftv != nil {
+		ftv.DragNDropFinalize(dnd.DropIgnore) // source files are left in place
+	}
+	fn := ge.FileNodeForFile(fpath, true)
+	if fn == nil || fn.IsDir() {
+		return
+	}
+	ge.ViewFileNode(tv, vidx, fn)
 }
 
 // ConnectEvents2D indirectly sets connections between mouse and key events and actions
@@ -286,7 +753,7 @@ func ConfigOutputTextView(ly *gi.Layout) *giv.TextView {
 	var tv *giv.TextView
 	updt := false
 	if ly.HasChildren() {
-		tv = ly.Child(0).Embed(giv.KiT_TextView).(*giv.TextView)
+		tv = ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView) // search bar can be before
 	} else {
 		updt = ly.UpdateStart()
 		ly.SetChildAdded()
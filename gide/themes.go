@@ -0,0 +1,453 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/histyle"
+	"github.com/goki/pi/token"
+)
+
+// ThemeScopes are the TextMate scopes of the tokens of the highlighting
+// styles, used for importing VSCode and TextMate themes: the rule of the
+// theme best matching the first of the scopes of a token with a match sets
+// its style
+var ThemeScopes = map[token.Tokens][]string{
+	token.Comment:             {"comment"},
+	token.CommentSingle:       {"comment.line", "comment"},
+	token.CommentMultiline:    {"comment.block", "comment"},
+	token.CommentPreproc:      {"meta.preprocessor", "keyword.control.directive", "comment"},
+	token.Keyword:             {"keyword.control", "keyword", "storage"},
+	token.KeywordConstant:     {"constant.language", "keyword"},
+	token.KeywordDeclaration:  {"storage.type", "keyword", "storage"},
+	token.KeywordReserved:     {"keyword.other", "keyword"},
+	token.KeywordType:         {"storage.type", "support.type", "entity.name.type"},
+	token.NameBuiltin:         {"support.function.builtin", "support.function", "entity.name.function"},
+	token.NameBuiltinPseudo:   {"variable.language"},
+	token.NameFunction:        {"entity.name.function", "support.function", "meta.function-call"},
+	token.NameMethod:          {"entity.name.function.member", "entity.name.function", "support.function"},
+	token.NameDecorator:       {"entity.name.function.decorator", "meta.decorator", "entity.name.function"},
+	token.NameType:            {"entity.name.type", "support.type", "storage.type"},
+	token.NameClass:           {"entity.name.class", "entity.name.type.class", "entity.name.type"},
+	token.NameStruct:          {"entity.name.type.struct", "entity.name.type"},
+	token.NameInterface:       {"entity.name.type.interface", "entity.name.type"},
+	token.NameConstant:        {"variable.other.constant", "constant.other", "constant"},
+	token.NameVar:             {"variable.other", "variable"},
+	token.NameVarParam:        {"variable.parameter", "variable"},
+	token.NameField:           {"variable.other.field", "variable.other.property", "variable.other.member"},
+	token.NameProperty:        {"variable.other.property", "support.variable.property", "variable.other.member"},
+	token.NameTag:             {"entity.name.tag"},
+	token.NameAttribute:       {"entity.other.attribute-name"},
+	token.NameException:       {"support.class.exception", "entity.name.exception", "entity.name.type"},
+	token.NameLabel:           {"entity.name.label", "entity.name"},
+	token.NamePackage:         {"entity.name.package", "entity.name.namespace", "entity.name.type.module"},
+	token.NameNamespace:       {"entity.name.namespace", "entity.name.type.module"},
+	token.Literal:             {"constant"},
+	token.LiteralBool:         {"constant.language.boolean", "constant.language"},
+	token.LitStr:              {"string"},
+	token.LitStrChar:          {"constant.character", "string.quoted.single", "string"},
+	token.LitStrBacktick:      {"string.quoted.raw", "string.quoted.other", "string"},
+	token.LitStrEscape:        {"constant.character.escape", "constant.character"},
+	token.LitStrRegex:         {"string.regexp", "string"},
+	token.LitNum:              {"constant.numeric"},
+	token.LitNumInteger:       {"constant.numeric.integer", "constant.numeric"},
+	token.LitNumFloat:         {"constant.numeric.float", "constant.numeric"},
+	token.Operator:            {"keyword.operator"},
+	token.Punctuation:         {"punctuation"},
+	token.Error:               {"invalid.illegal", "invalid"},
+	token.TextStyleHeading:    {"markup.heading", "entity.name.section"},
+	token.TextStyleSubheading: {"markup.heading.2", "markup.heading"},
+	token.TextStyleEmph:       {"markup.italic"},
+	token.TextStyleStrong:     {"markup.bold"},
+	token.TextStyleInserted:   {"markup.inserted"},
+	token.TextStyleDeleted:    {"markup.deleted"},
+	token.TextStyleUnderline:  {"markup.underline"},
+	token.TextStyleLink:       {"markup.underline.link", "string.other.link"},
+	token.TextStyleOutput:     {"markup.output", "markup.raw"},
+}
+
+// ThemeRule is a rule of a VSCode or TextMate theme: the style of the
+// text of its scope selectors
+type ThemeRule struct {
+	Scopes []string `desc:"the scope selectors of the rule"`
+	FG     string   `desc:"foreground color"`
+	BG     string   `desc:"background color"`
+	Font   string   `desc:"font style: bold, italic and / or underline, none for plain"`
+	HasFnt bool     `desc:"font style is set"`
+}
+
+// Theme is a VSCode or TextMate theme
+type Theme struct {
+	Name  string      `desc:"the name of the theme"`
+	FG    string      `desc:"foreground color of the editor"`
+	BG    string      `desc:"background color of the editor"`
+	Rules []ThemeRule `desc:"the rules of the theme, in order: later ones win over earlier ones of the same specificity"`
+}
+
+// OpenTheme opens the VSCode (JSON, with comments) or TextMate (.tmTheme
+// plist) theme of given file
+func OpenTheme(fname string) (*Theme, error) {
+	b, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	var th *Theme
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("<")) {
+		th, err = ParseTmTheme(b)
+	} else {
+		th, err = ParseVSCodeTheme(b)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", fname, err)
+	}
+	if th.Name == "" {
+		th.Name = strings.TrimSuffix(filepath.Base(fname), filepath.Ext(fname))
+	}
+	return th, nil
+}
+
+// ParseVSCodeTheme parses the JSON of a VSCode theme: its editor colors
+// and tokenColors (which may also be in a tmTheme file of the tokenColors
+// path, not supported here)
+func ParseVSCodeTheme(b []byte) (*Theme, error) {
+	var vt struct {
+		Name        string            `json:"name"`
+		Colors      map[string]string `json:"colors"`
+		TokenColors json.RawMessage   `json:"tokenColors"`
+	}
+	if err := json.Unmarshal(StripJSONComments(b), &vt); err != nil {
+		return nil, err
+	}
+	th := &Theme{Name: vt.Name, FG: vt.Colors["editor.foreground"], BG: vt.Colors["editor.background"]}
+	var tcs []struct {
+		Scope    json.RawMessage   `json:"scope"`
+		Settings map[string]string `json:"settings"`
+	}
+	if len(vt.TokenColors) > 0 && vt.TokenColors[0] == '[' {
+		if err := json.Unmarshal(vt.TokenColors, &tcs); err != nil {
+			return nil, err
+		}
+	}
+	for _, tc := range tcs {
+		var scs []string
+		var sc string
+		if json.Unmarshal(tc.Scope, &sc) == nil {
+			scs = strings.Split(sc, ",")
+		} else {
+			json.Unmarshal(tc.Scope, &scs)
+		}
+		if len(scs) == 0 { // global settings, as in tmTheme
+			if th.FG == "" {
+				th.FG = tc.Settings["foreground"]
+			}
+			if th.BG == "" {
+				th.BG = tc.Settings["background"]
+			}
+			continue
+		}
+		th.Rules = append(th.Rules, newThemeRule(scs, tc.Settings))
+	}
+	return th, nil
+}
+
+// newThemeRule returns the rule of given scope selectors and settings
+func newThemeRule(scs []string, sets map[string]string) ThemeRule {
+	tr := ThemeRule{FG: sets["foreground"], BG: sets["background"]}
+	tr.Font, tr.HasFnt = sets["fontStyle"]
+	for _, s := range scs {
+		if s = strings.TrimSpace(s); s != "" {
+			tr.Scopes = append(tr.Scopes, s)
+		}
+	}
+	return tr
+}
+
+// StripJSONComments returns given JSON with the // and /* */ comments, and
+// the trailing commas, of the JSON with comments of VSCode removed
+func StripJSONComments(b []byte) []byte {
+	var out bytes.Buffer
+	instr := false
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case instr:
+			out.WriteByte(c)
+			if c == '\\' && i+1 < len(b) {
+				i++
+				out.WriteByte(b[i])
+			} else if c == '"' {
+				instr = false
+			}
+		case c == '"':
+			instr = true
+			out.WriteByte(c)
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+			out.WriteByte('\n')
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			i += 2
+			for i+1 < len(b) && !(b[i] == '*' && b[i+1] == '/') {
+				i++
+			}
+			i++
+		case c == ']' || c == '}':
+			ob := bytes.TrimRight(out.Bytes(), " \t\r\n")
+			if len(ob) > 0 && ob[len(ob)-1] == ',' {
+				out.Truncate(len(ob) - 1)
+			}
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
+
+// ParseTmTheme parses the plist XML of a TextMate theme
+func ParseTmTheme(b []byte) (*Theme, error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	var root interface{}
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := t.(xml.StartElement); ok && se.Name.Local != "plist" {
+			root, err = plistValue(dec, se)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	rd, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a TextMate theme: no dict")
+	}
+	th := &Theme{}
+	th.Name, _ = rd["name"].(string)
+	sets, _ := rd["settings"].([]interface{})
+	for _, si := range sets {
+		sd, _ := si.(map[string]interface{})
+		ss, _ := sd["settings"].(map[string]interface{})
+		strs := map[string]string{}
+		for k, v := range ss {
+			if s, ok := v.(string); ok {
+				strs[k] = s
+			}
+		}
+		sc, _ := sd["scope"].(string)
+		if sc == "" {
+			if th.FG == "" {
+				th.FG = strs["foreground"]
+			}
+			if th.BG == "" {
+				th.BG = strs["background"]
+			}
+			continue
+		}
+		th.Rules = append(th.Rules, newThemeRule(strings.Split(sc, ","), strs))
+	}
+	return th, nil
+}
+
+// plistValue returns the value of given plist element: a map for a dict,
+// a slice for an array, and a string otherwise
+func plistValue(dec *xml.Decoder, se xml.StartElement) (interface{}, error) {
+	switch se.Name.Local {
+	case "dict":
+		d := map[string]interface{}{}
+		key := ""
+		for {
+			t, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch tt := t.(type) {
+			case xml.StartElement:
+				if tt.Name.Local == "key" {
+					var k string
+					if err = dec.DecodeElement(&k, &tt); err != nil {
+						return nil, err
+					}
+					key = k
+					continue
+				}
+				v, err := plistValue(dec, tt)
+				if err != nil {
+					return nil, err
+				}
+				d[key] = v
+			case xml.EndElement:
+				return d, nil
+			}
+		}
+	case "array":
+		var a []interface{}
+		for {
+			t, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			switch tt := t.(type) {
+			case xml.StartElement:
+				v, err := plistValue(dec, tt)
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, v)
+			case xml.EndElement:
+				return a, nil
+			}
+		}
+	}
+	var s string
+	err := dec.DecodeElement(&s, &se)
+	if err == io.EOF {
+		err = nil
+	}
+	return s, err
+}
+
+// selectorScope returns the scope matched by given selector: that of its
+// last element for descendant selectors -- empty for exclusions
+func selectorScope(sel string) string {
+	if strings.Contains(sel, " -") {
+		return ""
+	}
+	flds := strings.Fields(sel)
+	if len(flds) == 0 {
+		return ""
+	}
+	return flds[len(flds)-1]
+}
+
+// Match returns the index of the rule best matching given scope: the one
+// with the longest matching selector, the last one of those, -1 if none
+func (th *Theme) Match(scope string) int {
+	best, blen := -1, 0
+	for i, tr := range th.Rules {
+		for _, sel := range tr.Scopes {
+			ss := selectorScope(sel)
+			if ss == "" || !(scope == ss || strings.HasPrefix(scope, ss+".")) {
+				continue
+			}
+			if len(ss) >= blen {
+				best, blen = i, len(ss)
+			}
+		}
+	}
+	return best
+}
+
+// themeColor returns the color of given theme color string: #rgb,
+// #rrggbb, or #rrggbbaa, blended with given background color for an alpha
+// below 1
+func themeColor(s string, bg gist.Color) (gist.Color, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) == 3 || len(s) == 4 {
+		ex := ""
+		for _, c := range s {
+			ex += string(c) + string(c)
+		}
+		s = ex
+	}
+	if len(s) != 6 && len(s) != 8 {
+		return gist.Color{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return gist.Color{}, false
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xff
+	}
+	r, g, b, a := float32(v>>24), float32(v>>16&0xff), float32(v>>8&0xff), float32(v&0xff)/255
+	if a < 1 && !bg.IsNil() {
+		r = r*a + float32(bg.R)*(1-a)
+		g = g*a + float32(bg.G)*(1-a)
+		b = b*a + float32(bg.B)*(1-a)
+	}
+	return gist.Color{R: uint8(r + .5), G: uint8(g + .5), B: uint8(b + .5), A: 0xff}, true
+}
+
+// HiStyle returns the highlighting style of the theme, with the rules of
+// its ThemeScopes for the tokens
+func (th *Theme) HiStyle() *histyle.Style {
+	hs := histyle.Style{}
+	bg, _ := themeColor(th.BG, gist.Color{})
+	fg, hasFG := themeColor(th.FG, bg)
+	bse := &histyle.StyleEntry{Background: bg}
+	if hasFG {
+		bse.Color = fg
+		hs[token.Text] = &histyle.StyleEntry{Color: fg}
+	}
+	hs[token.Background] = bse
+	for tok, scs := range ThemeScopes {
+		for _, sc := range scs {
+			idx := th.Match(sc)
+			if idx < 0 {
+				continue
+			}
+			tr := th.Rules[idx]
+			se := &histyle.StyleEntry{}
+			if c, ok := themeColor(tr.FG, bg); ok {
+				se.Color = c
+			}
+			if tr.BG != "" {
+				if c, ok := themeColor(tr.BG, bg); ok && c != bg {
+					se.Background = c
+				}
+			}
+			if tr.HasFnt {
+				se.Bold, se.Italic, se.Underline = histyle.No, histyle.No, histyle.No
+				for _, f := range strings.Fields(tr.Font) {
+					switch f {
+					case "bold":
+						se.Bold = histyle.Yes
+					case "italic":
+						se.Italic = histyle.Yes
+					case "underline":
+						se.Underline = histyle.Yes
+					}
+				}
+			}
+			if !se.IsZero() {
+				hs[tok] = se
+			}
+			break
+		}
+	}
+	return &hs
+}
+
+// ImportTheme imports the VSCode or TextMate theme of given file as a
+// custom highlighting style, saved in the preferences, returning its name
+func ImportTheme(fname string) (string, error) {
+	th, err := OpenTheme(fname)
+	if err != nil {
+		return "", err
+	}
+	histyle.AvailStyle(histyle.StyleDefault) // makes sure the styles are loaded
+	if histyle.CustomStyles == nil {
+		histyle.CustomStyles = histyle.Styles{}
+	}
+	histyle.CustomStyles[th.Name] = th.HiStyle()
+	err = histyle.CustomStyles.SavePrefs()
+	SyncPrefsSaved()
+	return th.Name, err
+}
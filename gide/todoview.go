@@ -0,0 +1,352 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// TodoTags are the tags of the comments listed in the TODO panel
+var TodoTags = []string{"TODO", "FIXME", "HACK", "XXX"}
+
+// TodoMaxFileSize is the maximum size of files scanned for TODO comments
+var TodoMaxFileSize = int64(1000000)
+
+// TodoRegexp returns the regexp matching a comment starting with one of the
+// TodoTags, with the tag and the rest of the comment as submatches
+func TodoRegexp() *regexp.Regexp {
+	tags := make([]string, len(TodoTags))
+	for i, t := range TodoTags {
+		tags[i] = regexp.QuoteMeta(t)
+	}
+	return regexp.MustCompile(`(?://+|#+|/\*+|<!--|;+|--|^\s*\*)\s*(` + strings.Join(tags, "|") + `)\b[:\s]?\s*(.*)`)
+}
+
+// TodoItem is one TODO comment in a file
+type TodoItem struct {
+	Line int    `desc:"line of the comment (0-based)"`
+	Col  int    `desc:"column of the tag (0-based, in runes)"`
+	Tag  string `desc:"tag of the comment, one of TodoTags"`
+	Text string `desc:"text of the comment after the tag"`
+}
+
+// TodoScanFile returns the TODO comments in given file, skipping binary
+// files and those larger than TodoMaxFileSize
+func TodoScanFile(fpath string, re *regexp.Regexp) []TodoItem {
+	info, err := os.Stat(fpath)
+	if err != nil || !info.Mode().IsRegular() || info.Size() > TodoMaxFileSize {
+		return nil
+	}
+	src, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return nil
+	}
+	hd := src
+	if len(hd) > 8000 {
+		hd = hd[:8000]
+	}
+	if bytes.IndexByte(hd, 0) >= 0 {
+		return nil
+	}
+	var items []TodoItem
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	sc.Buffer(make([]byte, 0, 64*1024), len(src)+1)
+	for ln := 0; sc.Scan(); ln++ {
+		lb := sc.Bytes()
+		m := re.FindSubmatchIndex(lb)
+		if m == nil {
+			continue
+		}
+		txt := strings.TrimSpace(string(lb[m[4]:m[5]]))
+		txt = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(txt, "*/"), "-->"))
+		items = append(items, TodoItem{Line: ln, Col: utf8.RuneCount(lb[:m[2]]), Tag: string(lb[m[2]:m[3]]), Text: txt})
+	}
+	return items
+}
+
+// TodoList is the list of TODO comments in the files of a project, by
+// file path relative to the project root
+type TodoList struct {
+	Root  string                `desc:"root directory of the project"`
+	Files map[string][]TodoItem `desc:"TODO comments by file, relative to Root, / separated, for files having any"`
+	Mu    sync.Mutex            `desc:"mutex protecting the list"`
+}
+
+// Scan scans all the files in the project at given root, except the
+// excluded and ignored ones, as in SymIndex
+func (tl *TodoList) Scan(root string, exclude []string, ign *FileIgnore) {
+	re := TodoRegexp()
+	files := make(map[string][]TodoItem)
+	ign.Walk(root, func(pth string, info os.FileInfo, err error) error {
+		if err != nil || pth == root {
+			return nil
+		}
+		rel, rerr := filepath.Rel(root, pth)
+		if rerr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if QuickOpenSkip(rel, info.IsDir(), exclude) || ign.Ignored(pth, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if items := TodoScanFile(pth, re); len(items) > 0 {
+			files[rel] = items
+		}
+		return nil
+	})
+	tl.Mu.Lock()
+	tl.Root = root
+	tl.Files = files
+	tl.Mu.Unlock()
+}
+
+// IsScanned returns true if the project has been scanned
+func (tl *TodoList) IsScanned() bool {
+	tl.Mu.Lock()
+	defer tl.Mu.Unlock()
+	return tl.Files != nil
+}
+
+// UpdateFile re-scans given file (full path) after it has been saved or
+// changed -- returns true if its comments changed
+func (tl *TodoList) UpdateFile(fpath string, ign *FileIgnore) bool {
+	tl.Mu.Lock()
+	defer tl.Mu.Unlock()
+	if tl.Files == nil {
+		return false
+	}
+	rel, err := filepath.Rel(tl.Root, fpath)
+	if err != nil || RelOutside(rel) {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	var items []TodoItem
+	if !QuickOpenSkip(rel, false, nil) && !ign.Ignored(fpath, false) {
+		items = TodoScanFile(fpath, TodoRegexp())
+	}
+	old := tl.Files[rel]
+	if len(items) == 0 {
+		delete(tl.Files, rel)
+	} else {
+		tl.Files[rel] = items
+	}
+	if len(old) != len(items) {
+		return true
+	}
+	for i := range old {
+		if old[i] != items[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the total number of comments, and the number for each tag
+func (tl *TodoList) Count() (int, map[string]int) {
+	tl.Mu.Lock()
+	defer tl.Mu.Unlock()
+	n := 0
+	tags := make(map[string]int)
+	for _, items := range tl.Files {
+		for _, it := range items {
+			tags[it.Tag]++
+			n++
+		}
+	}
+	return n, tags
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    TodoView
+
+// TodoView is a widget that displays the TODO comments in the project files
+// (see TodoTags), grouped by file or by tag, with links to them
+type TodoView struct {
+	gi.Layout
+	Gide  Gide      `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	List  *TodoList `json:"-" xml:"-" copy:"-" desc:"the list of comments shown"`
+	ByTag bool      `desc:"group the comments by tag instead of by file"`
+}
+
+var KiT_TodoView = kit.Types.AddType(&TodoView{}, TodoViewProps)
+
+// Config configures the view to show given list
+func (tv *TodoView) Config(ge Gide, tl *TodoList) {
+	tv.Gide = ge
+	tv.List = tl
+	tv.Lay = gi.LayoutVert
+	tv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "todobar")
+	config.Add(gi.KiT_Layout, "todotext")
+	mods, updt := tv.ConfigChildren(config)
+	if !mods {
+		updt = tv.UpdateStart()
+	}
+	tv.ConfigToolbar()
+	ConfigOutputTextView(tv.TextViewLay())
+	tv.UpdateEnd(updt)
+}
+
+// ToolBar returns the todo toolbar
+func (tv *TodoView) ToolBar() *gi.ToolBar {
+	return tv.ChildByName("todobar", 0).(*gi.ToolBar)
+}
+
+// TextViewLay returns the todo results TextView layout
+func (tv *TodoView) TextViewLay() *gi.Layout {
+	return tv.ChildByName("todotext", 1).(*gi.Layout)
+}
+
+// TextView returns the todo results TextView
+func (tv *TodoView) TextView() *giv.TextView {
+	return tv.TextViewLay().ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// ConfigToolbar adds the toolbar actions
+func (tv *TodoView) ConfigToolbar() {
+	tb := tv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Refresh", Icon: "update", Tooltip: "re-scan all the project files for TODO comments"},
+		tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			tvv, _ := recv.Embed(KiT_TodoView).(*TodoView)
+			tvv.Gide.ScanTodos()
+		})
+	cb := gi.AddNewCheckBox(tb, "by-tag")
+	cb.SetText("By Tag")
+	cb.Tooltip = "group the comments by tag instead of by file"
+	cb.SetChecked(tv.ByTag)
+	cb.ButtonSig.Connect(tv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonToggled) {
+			tvv, _ := recv.Embed(KiT_TodoView).(*TodoView)
+			tvv.ByTag = send.(*gi.CheckBox).IsChecked()
+			tvv.ShowTodos()
+		}
+	})
+}
+
+// ShowTodos shows the comments of the list in the text view, grouped by
+// file or tag, with file:/// links to them
+func (tv *TodoView) ShowTodos() {
+	if tv.List == nil {
+		return
+	}
+	type todoLoc struct {
+		file string
+		it   TodoItem
+	}
+	tl := tv.List
+	tl.Mu.Lock()
+	var locs []todoLoc
+	for f, items := range tl.Files {
+		for _, it := range items {
+			locs = append(locs, todoLoc{f, it})
+		}
+	}
+	root := tl.Root
+	tl.Mu.Unlock()
+	sort.Slice(locs, func(i, j int) bool {
+		li, lj := locs[i], locs[j]
+		if tv.ByTag && li.it.Tag != lj.it.Tag {
+			return TodoTagIndex(li.it.Tag) < TodoTagIndex(lj.it.Tag)
+		}
+		if li.file != lj.file {
+			return li.file < lj.file
+		}
+		return li.it.Line < lj.it.Line
+	})
+	group := func(l todoLoc) string {
+		if tv.ByTag {
+			return l.it.Tag
+		}
+		return l.file
+	}
+	outlns := make([][]byte, 0, len(locs)+20)
+	outmus := make([][]byte, 0, len(locs)+20)
+	for i := 0; i < len(locs); {
+		grp := group(locs[i])
+		n := 1
+		for i+n < len(locs) && group(locs[i+n]) == grp {
+			n++
+		}
+		lstr := fmt.Sprintf("%v: %v", grp, n)
+		outlns = append(outlns, []byte(lstr))
+		outmus = append(outmus, []byte("<b>"+html.EscapeString(lstr)+"</b>"))
+		for _, l := range locs[i : i+n] {
+			loc := fmt.Sprintf("%v:%d:%d", l.file, l.it.Line+1, l.it.Col+1)
+			desc := l.it.Tag
+			if tv.ByTag {
+				desc = ""
+			}
+			if l.it.Text != "" {
+				desc = strings.TrimSpace(desc + " " + l.it.Text)
+			}
+			outlns = append(outlns, []byte(fmt.Sprintf("\t%v: %v", loc, desc)))
+			fp := filepath.Join(root, filepath.FromSlash(l.file))
+			outmus = append(outmus, []byte(fmt.Sprintf(`	<a href="file:///%v#L%dC%d">%v</a>: %v`, fp, l.it.Line+1, l.it.Col+1, loc, html.EscapeString(desc))))
+		}
+		outlns = append(outlns, []byte(""))
+		outmus = append(outmus, []byte(""))
+		i += n
+	}
+	if len(locs) == 0 {
+		outlns = append(outlns, []byte("no TODO comments found"))
+		outmus = append(outmus, []byte("no TODO comments found"))
+	}
+	ftv := tv.TextView()
+	fbuf := ftv.Buf
+	if fbuf == nil {
+		return
+	}
+	cpos := ftv.CursorPos
+	fbuf.New(0)
+	fbuf.SetInactive(true)
+	fbuf.AppendTextMarkup(bytes.Join(outlns, []byte("\n")), bytes.Join(outmus, []byte("\n")), giv.EditSignal)
+	ftv.SetCursorShow(cpos)
+}
+
+// TodoTagIndex returns the index of given tag in TodoTags, for sorting
+func TodoTagIndex(tag string) int {
+	for i, t := range TodoTags {
+		if t == tag {
+			return i
+		}
+	}
+	return len(TodoTags)
+}
+
+// TodoViewProps are style properties for TodoView
+var TodoViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
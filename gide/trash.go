@@ -0,0 +1,213 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TrashDirName is the name of the project-local trash directory at the
+// root of a project, used when files cannot be moved to the OS trash --
+// it is always ignored (see FileIgnore)
+var TrashDirName = ".gide-trash"
+
+// TrashMaxUndo is the maximum number of deletions that can be undone
+var TrashMaxUndo = 20
+
+// TrashedFile is a file or directory that has been moved to the trash
+type TrashedFile struct {
+	Orig    string `desc:"original path of the file"`
+	Trashed string `desc:"path of the file in the trash"`
+	Info    string `desc:"path of the freedesktop.org .trashinfo file for it, if any"`
+}
+
+// TrashDel is one deletion of files, undone together
+type TrashDel struct {
+	Files []TrashedFile `desc:"the files moved to the trash"`
+	Time  time.Time     `desc:"when they were deleted"`
+}
+
+// FileTrash moves deleted files of a project to the OS trash where
+// supported (the freedesktop.org trash on Linux and other unix systems,
+// ~/.Trash on macOS), and otherwise to the TrashDirName directory at the
+// project root, and keeps the recent deletions so they can be undone.
+type FileTrash struct {
+	Root string     `desc:"root directory of the project"`
+	Dels []TrashDel `desc:"recent deletions, most recent last, up to TrashMaxUndo"`
+	Mu   sync.Mutex `desc:"mutex protecting Dels"`
+}
+
+// OSTrashDir returns the OS trash directory for files, and whether it is a
+// freedesktop.org trash (with the files in its files subdirectory, and
+// .trashinfo files in its info subdirectory) -- "" if there is none
+func OSTrashDir() (string, bool) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, ".Trash"), false
+	case "windows", "plan9", "js", "android", "ios":
+		return "", false
+	}
+	data := os.Getenv("XDG_DATA_HOME")
+	if data == "" {
+		data = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(data, "Trash"), true
+}
+
+// TrashUniquePath returns a path in given directory for a file with given
+// name that does not yet exist, adding a number to the name as needed
+func TrashUniquePath(dir, nm string) string {
+	pth := filepath.Join(dir, nm)
+	ext := filepath.Ext(nm)
+	base := strings.TrimSuffix(nm, ext)
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(pth); os.IsNotExist(err) {
+			return pth
+		}
+		pth = filepath.Join(dir, fmt.Sprintf("%s.%d%s", base, i, ext))
+	}
+}
+
+// MoveToOSTrash moves given file to the OS trash
+func MoveToOSTrash(fpath string) (TrashedFile, error) {
+	tf := TrashedFile{Orig: fpath}
+	dir, fdo := OSTrashDir()
+	if dir == "" {
+		return tf, fmt.Errorf("no OS trash on %v", runtime.GOOS)
+	}
+	if !fdo {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return tf, err
+		}
+		tf.Trashed = TrashUniquePath(dir, filepath.Base(fpath))
+		return tf, os.Rename(fpath, tf.Trashed)
+	}
+	fdir := filepath.Join(dir, "files")
+	idir := filepath.Join(dir, "info")
+	if err := os.MkdirAll(fdir, 0700); err != nil {
+		return tf, err
+	}
+	if err := os.MkdirAll(idir, 0700); err != nil {
+		return tf, err
+	}
+	tf.Trashed = TrashUniquePath(fdir, filepath.Base(fpath))
+	tf.Info = filepath.Join(idir, filepath.Base(tf.Trashed)+".trashinfo")
+	u := url.URL{Path: fpath}
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", u.EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+	if err := ioutil.WriteFile(tf.Info, []byte(info), 0600); err != nil {
+		return tf, err
+	}
+	if err := os.Rename(fpath, tf.Trashed); err != nil {
+		os.Remove(tf.Info)
+		tf.Info = ""
+		return tf, err
+	}
+	return tf, nil
+}
+
+// MoveToLocalTrash moves given file to the TrashDirName directory of the
+// project, keeping its path relative to the project root
+func (ft *FileTrash) MoveToLocalTrash(fpath string) (TrashedFile, error) {
+	tf := TrashedFile{Orig: fpath}
+	rel, err := filepath.Rel(ft.Root, fpath)
+	if err != nil || RelOutside(rel) {
+		rel = filepath.Base(fpath)
+	}
+	dir := filepath.Join(ft.Root, TrashDirName, filepath.Dir(rel))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return tf, err
+	}
+	tf.Trashed = TrashUniquePath(dir, filepath.Base(rel))
+	return tf, os.Rename(fpath, tf.Trashed)
+}
+
+// MoveToTrash moves given files (and directories) to the trash, recording
+// them as one deletion that can be undone -- returns the files moved, and
+// an error for any that could not be moved (which are not deleted)
+func (ft *FileTrash) MoveToTrash(paths []string) ([]TrashedFile, error) {
+	var tfs []TrashedFile
+	var errs []string
+	for _, fp := range paths {
+		if _, err := os.Lstat(fp); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		tf, err := MoveToOSTrash(fp)
+		if err != nil {
+			tf, err = ft.MoveToLocalTrash(fp) // e.g., on a different device
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		tfs = append(tfs, tf)
+	}
+	if len(tfs) > 0 {
+		ft.Mu.Lock()
+		ft.Dels = append(ft.Dels, TrashDel{Files: tfs, Time: time.Now()})
+		if len(ft.Dels) > TrashMaxUndo {
+			ft.Dels = ft.Dels[len(ft.Dels)-TrashMaxUndo:]
+		}
+		ft.Mu.Unlock()
+	}
+	if len(errs) > 0 {
+		return tfs, fmt.Errorf("could not move to trash: %v", strings.Join(errs, "\n"))
+	}
+	return tfs, nil
+}
+
+// CanUndo returns true if there is a deletion to undo
+func (ft *FileTrash) CanUndo() bool {
+	ft.Mu.Lock()
+	defer ft.Mu.Unlock()
+	return len(ft.Dels) > 0
+}
+
+// Undo restores the files of the most recent deletion to where they were
+// -- returns those restored, and an error for any that could not be (e.g.,
+// if a file has since been created at that path)
+func (ft *FileTrash) Undo() ([]string, error) {
+	ft.Mu.Lock()
+	if len(ft.Dels) == 0 {
+		ft.Mu.Unlock()
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	del := ft.Dels[len(ft.Dels)-1]
+	ft.Dels = ft.Dels[:len(ft.Dels)-1]
+	ft.Mu.Unlock()
+	var restored []string
+	var errs []string
+	for _, tf := range del.Files {
+		if _, err := os.Lstat(tf.Orig); err == nil {
+			errs = append(errs, fmt.Sprintf("%v already exists -- it is still in the trash at: %v", tf.Orig, tf.Trashed))
+			continue
+		}
+		os.MkdirAll(filepath.Dir(tf.Orig), 0755)
+		if err := os.Rename(tf.Trashed, tf.Orig); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if tf.Info != "" {
+			os.Remove(tf.Info)
+		}
+		restored = append(restored, tf.Orig)
+	}
+	if len(errs) > 0 {
+		return restored, fmt.Errorf("%v", strings.Join(errs, "\n"))
+	}
+	return restored, nil
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// URLScheme is the scheme of the URLs opening files and projects in gide,
+// e.g., gide://open?file=/path/to/file.go&line=12&col=3 -- registered for
+// the OS with RegisterURLScheme, so links in browsers, chat, and coverage or
+// CI reports jump straight into the editor
+const URLScheme = "gide"
+
+// IsOpenURL returns true if given arg is a gide:// URL
+func IsOpenURL(arg string) bool {
+	return strings.HasPrefix(strings.ToLower(arg), URLScheme+"://")
+}
+
+// OpenURL returns the gide:// URL opening given file at given line and
+// column (starting at 1, 0 for none) -- the params are in that order, as
+// line and col apply to the file before them (not sorted, as by
+// url.Values.Encode)
+func OpenURL(fpath string, line, col int) string {
+	ur := URLScheme + "://open?file=" + url.QueryEscape(fpath)
+	if line > 0 {
+		ur += "&line=" + strconv.Itoa(line)
+	}
+	if col > 0 {
+		ur += "&col=" + strconv.Itoa(col)
+	}
+	return ur
+}
+
+// ParseOpenURL returns the OpenRequest of given gide:// URL:
+// gide://open?file=path&line=n&col=n, with any number of file params
+// (each at the line and col params following it, or as path:line:col), and
+// optionally a proj (.gide file) or path (directory) param -- relative file
+// paths are relative to proj or path
+func ParseOpenURL(ur string) (*OpenRequest, error) {
+	u, err := url.Parse(ur)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != URLScheme {
+		return nil, fmt.Errorf("not a %v:// URL: %v", URLScheme, ur)
+	}
+	if act := u.Host + strings.TrimSuffix(u.Path, "/"); act != "open" {
+		return nil, fmt.Errorf("%v:// URL: unknown action: %v, only open is supported", URLScheme, act)
+	}
+	req := &OpenRequest{}
+	root := ""
+	// params in order, so that line and col apply to the file before them
+	for _, kv := range strings.Split(u.RawQuery, "&") {
+		eq := strings.Index(kv, "=")
+		if eq < 0 {
+			continue
+		}
+		k, _ := url.QueryUnescape(kv[:eq])
+		v, err := url.QueryUnescape(kv[eq+1:])
+		if err != nil {
+			return nil, err
+		}
+		if v == "" { // not the current directory, as Abs would make it
+			continue
+		}
+		switch k {
+		case "proj", "project":
+			req.Proj, _ = filepath.Abs(v)
+			root = filepath.Dir(req.Proj)
+		case "path", "dir":
+			req.Path, _ = filepath.Abs(v)
+			root = req.Path
+		case "file":
+			if !filepath.IsAbs(v) && root != "" {
+				v = filepath.Join(root, v)
+			}
+			req.Files = append(req.Files, ParseFilePos(v))
+		case "line", "col":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || len(req.Files) == 0 {
+				continue
+			}
+			fp := &req.Files[len(req.Files)-1]
+			if k == "line" {
+				fp.Line = n
+			} else {
+				fp.Col = n
+			}
+		}
+	}
+	if req.IsEmpty() {
+		return nil, fmt.Errorf("%v:// URL opens nothing: %v", URLScheme, ur)
+	}
+	return req, nil
+}
+
+// urlSchemeExec returns the executable run by the OS for gide:// URLs:
+// gide-open if it is next to this gide (as it reuses a running gide),
+// otherwise this gide
+func urlSchemeExec() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", err
+	}
+	op := filepath.Join(filepath.Dir(exe), "gide-open"+filepath.Ext(exe))
+	if _, err := os.Stat(op); err == nil {
+		return op, nil
+	}
+	return exe, nil
+}
+
+// RegisterURLScheme registers gide as the handler of the gide:// URLs for
+// the OS, showing the result
+func (pf *Preferences) RegisterURLScheme() string {
+	if err := RegisterURLScheme(); err != nil {
+		return fmt.Sprintf("Could not register the %v:// URLs: %v", URLScheme, err)
+	}
+	return fmt.Sprintf("Registered gide for the %v:// URLs, e.g.: %v", URLScheme, OpenURL("/path/to/file.go", 12, 0))
+}
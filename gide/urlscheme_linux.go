@@ -0,0 +1,52 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build linux
+// +build linux
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// URLSchemeDesktopFile is the name of the desktop entry of the handler of
+// the gide:// URLs, in the applications directory of the user
+var URLSchemeDesktopFile = "gide-url-handler.desktop"
+
+// RegisterURLScheme registers gide as the handler of the gide:// URLs: as
+// the x-scheme-handler/gide of a desktop entry, made the default with
+// xdg-mime
+func RegisterURLScheme() error {
+	exe, err := urlSchemeExec()
+	if err != nil {
+		return err
+	}
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	dir = filepath.Join(dir, "applications")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	de := fmt.Sprintf("[Desktop Entry]\nType=Application\nName=Gide\nComment=Open gide:// links in gide\nExec=\"%v\" %%u\nTerminal=false\nNoDisplay=true\nMimeType=x-scheme-handler/%v;\n", exe, URLScheme)
+	if err := ioutil.WriteFile(filepath.Join(dir, URLSchemeDesktopFile), []byte(de), 0644); err != nil {
+		return err
+	}
+	if out, err := exec.Command("xdg-mime", "default", URLSchemeDesktopFile, "x-scheme-handler/"+URLScheme).CombinedOutput(); err != nil {
+		return fmt.Errorf("xdg-mime: %v: %s", err, bytes.TrimSpace(out))
+	}
+	exec.Command("update-desktop-database", dir).Run() // optional
+	return nil
+}
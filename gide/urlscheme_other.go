@@ -0,0 +1,38 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package gide
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// URLSchemePlist is the entry of the Info.plist of the gide.app bundle
+// declaring the gide:// URLs on macOS, where the scheme handlers are those
+// of the app bundles, registered by Launch Services when the app is
+// installed
+var URLSchemePlist = `<key>CFBundleURLTypes</key>
+<array>
+	<dict>
+		<key>CFBundleURLName</key>
+		<string>org.goki.gide</string>
+		<key>CFBundleURLSchemes</key>
+		<array>
+			<string>` + URLScheme + `</string>
+		</array>
+	</dict>
+</array>`
+
+// RegisterURLScheme returns an error here: on macOS the gide:// URLs are
+// declared by the Info.plist of the gide.app bundle (see URLSchemePlist)
+func RegisterURLScheme() error {
+	if runtime.GOOS == "darwin" {
+		return fmt.Errorf("on macOS, add the CFBundleURLTypes of gide.URLSchemePlist to the Info.plist of gide.app, and reinstall it")
+	}
+	return fmt.Errorf("registering URL handlers is not supported on %v", runtime.GOOS)
+}
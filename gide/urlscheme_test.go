@@ -0,0 +1,81 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseOpenURL(t *testing.T) {
+	abs := func(p string) string {
+		ap, _ := filepath.Abs(filepath.FromSlash(p))
+		return ap
+	}
+	tests := []struct {
+		url string
+		ok  bool
+		req OpenRequest
+	}{
+		{"gide://open?file=/tmp/a.go&line=12&col=3", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go"), Line: 12, Col: 3}}}},
+		{"gide://open/?file=/tmp/a.go&line=12", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go"), Line: 12}}}},
+		{"GIDE://open?file=/tmp/a.go", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go")}}}},
+		{"gide://open?file=/tmp/a.go:7:2", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go"), Line: 7, Col: 2}}}},
+		{"gide://open?file=%2Ftmp%2Fmy%20dir%2Fa%2Bb.go&line=5", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/my dir/a+b.go"), Line: 5}}}},
+		{"gide://open?file=/tmp/a+b.go", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a b.go")}}}},
+		{"gide://open?file=/tmp/a.go&line=1&file=/tmp/b.go&col=4", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go"), Line: 1}, {Path: abs("/tmp/b.go"), Col: 4}}}},
+		{"gide://open?line=3&file=/tmp/a.go", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go")}}}},
+		{"gide://open?file=/tmp/a.go&line=abc&col=-2", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go")}}}},
+		{"gide://open?file=/tmp/a.go&line=&col", true, OpenRequest{Files: []FilePos{{Path: abs("/tmp/a.go")}}}},
+		{"gide://open?path=/tmp/proj&file=sub/a.go&line=2", true, OpenRequest{Path: abs("/tmp/proj"), Files: []FilePos{{Path: abs("/tmp/proj/sub/a.go"), Line: 2}}}},
+		{"gide://open?proj=/tmp/proj/proj.gide&file=a.go", true, OpenRequest{Proj: abs("/tmp/proj/proj.gide"), Files: []FilePos{{Path: abs("/tmp/proj/a.go")}}}},
+		{"gide://open?dir=/tmp/proj", true, OpenRequest{Path: abs("/tmp/proj")}},
+		{"gide://open?file=", false, OpenRequest{}},
+		{"gide://open?proj=&path=", false, OpenRequest{}},
+		{"gide://open", false, OpenRequest{}},
+		{"gide://open?line=3", false, OpenRequest{}},
+		{"gide://open?file=%zz.go", false, OpenRequest{}},
+		{"gide://close?file=/tmp/a.go", false, OpenRequest{}},
+		{"gide:open?file=/tmp/a.go", false, OpenRequest{}},
+		{"http://open?file=/tmp/a.go", false, OpenRequest{}},
+		{"gide://open\x7f?file=/tmp/a.go", false, OpenRequest{}},
+	}
+	for _, tst := range tests {
+		req, err := ParseOpenURL(tst.url)
+		if (err == nil) != tst.ok {
+			t.Errorf("ParseOpenURL error: %q: should have been ok: %v  was: %v %+v\n", tst.url, tst.ok, err, req)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(*req, tst.req) {
+			t.Errorf("ParseOpenURL error: %q: should have been: %+v  was: %+v\n", tst.url, tst.req, *req)
+		}
+	}
+}
+
+func TestOpenURL(t *testing.T) {
+	tests := []struct {
+		fpath     string
+		line, col int
+	}{
+		{"/tmp/a.go", 12, 3},
+		{"/tmp/my dir/a+b&c=d.go", 1, 0},
+		{"/tmp/a.go", 0, 0},
+	}
+	for _, tst := range tests {
+		ur := OpenURL(tst.fpath, tst.line, tst.col)
+		if !IsOpenURL(ur) {
+			t.Errorf("IsOpenURL error: %q should be a gide URL\n", ur)
+		}
+		req, err := ParseOpenURL(ur)
+		want := FilePos{Path: filepath.Clean(tst.fpath), Line: tst.line, Col: tst.col}
+		if err != nil || len(req.Files) != 1 || req.Files[0] != want {
+			t.Errorf("OpenURL error: %q should have opened: %+v  was: %+v %v\n", ur, want, req, err)
+		}
+	}
+	if IsOpenURL("https://example.com") || IsOpenURL("/tmp/gide://x") {
+		t.Errorf("IsOpenURL error: only gide:// URLs are gide URLs\n")
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build windows
+// +build windows
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+)
+
+// RegisterURLScheme registers gide as the handler of the gide:// URLs: as
+// the open command of the gide URL protocol in the registry classes of the
+// user
+func RegisterURLScheme() error {
+	exe, err := urlSchemeExec()
+	if err != nil {
+		return err
+	}
+	key := `HKCU\Software\Classes\` + URLScheme
+	regs := [][]string{
+		{key, "/ve", "/d", "URL:Gide"},
+		{key, "/v", "URL Protocol", "/d", ""},
+		{key + `\shell\open\command`, "/ve", "/d", fmt.Sprintf(`"%v" "%%1"`, exe)},
+	}
+	for _, rg := range regs {
+		args := append([]string{"add"}, rg...)
+		if out, err := exec.Command("reg", append(args, "/f")...).CombinedOutput(); err != nil {
+			return fmt.Errorf("reg add %v: %v: %s", rg[0], err, bytes.TrimSpace(out))
+		}
+	}
+	return nil
+}
@@ -683,6 +683,163 @@ func (vv *SplitValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.
 
 }
 
+//////////////////////////////////////////////////////////////////////////////////////
+//  MacrosView
+
+// MacrosView opens a view of a keyboard macros table
+func MacrosView(pt *Macros) {
+	winm := "gide-macros"
+	width := 800
+	height := 800
+	win, recyc := gi.RecycleMainWindow(pt, winm, "Gide Keyboard Macros", width, height)
+	if recyc {
+		return
+	}
+
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+
+	title := mfr.AddNewChild(gi.KiT_Label, "title").(*gi.Label)
+	title.SetText("Available Keyboard Macros: record a macro and save it under a name from the Edit / Macros menu")
+	title.SetProp("width", units.NewValue(30, units.Ch)) // need for wrap
+	title.SetStretchMaxWidth()
+	title.SetProp("white-space", gist.WhiteSpaceNormal) // wrap
+
+	tv := mfr.AddNewChild(giv.KiT_TableView, "tv").(*giv.TableView)
+	tv.Viewport = vp
+	tv.SetSlice(pt)
+	tv.SetStretchMaxWidth()
+	tv.SetStretchMaxHeight()
+
+	AvailMacrosChanged = false
+	tv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		AvailMacrosChanged = true
+	})
+
+	mmen := win.MainMenu
+	giv.MainMenuView(pt, win, mmen)
+
+	inClosePrompt := false
+	win.OSWin.SetCloseReqFunc(func(w oswin.Window) {
+		if !AvailMacrosChanged || pt != &AvailMacros { // only for main avail map..
+			win.Close()
+			return
+		}
+		if inClosePrompt {
+			return
+		}
+		inClosePrompt = true
+		gi.ChoiceDialog(vp, gi.DlgOpts{Title: "Save Macros Before Closing?",
+			Prompt: "Do you want to save any changes to keyboard macros file before closing, or Cancel the close and do a Save to a different file?"},
+			[]string{"Save and Close", "Discard and Close", "Cancel"},
+			win.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case 0:
+					pt.SavePrefs()
+					fmt.Printf("Preferences Saved to %v\n", PrefsMacrosFileName)
+					win.Close()
+				case 1:
+					pt.OpenPrefs() // revert
+					win.Close()
+				case 2:
+					inClosePrompt = false
+					// default is to do nothing, i.e., cancel
+				}
+			})
+	})
+
+	win.MainMenuUpdated()
+
+	if !win.HasGeomPrefs() { // resize to contents
+		vpsz := vp.PrefSize(win.OSWin.Screen().PixSize)
+		win.SetSize(vpsz)
+	}
+
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  MacroValueView
+
+// ValueView registers MacroValueView as the viewer of MacroName
+func (kn MacroName) ValueView() giv.ValueView {
+	vv := &MacroValueView{}
+	ki.InitNode(vv)
+	return vv
+}
+
+// MacroValueView presents an action for displaying an MacroName and selecting
+type MacroValueView struct {
+	giv.ValueViewBase
+}
+
+var KiT_MacroValueView = kit.Types.AddType(&MacroValueView{}, nil)
+
+func (vv *MacroValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Action
+	return vv.WidgetTyp
+}
+
+func (vv *MacroValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ac := vv.Widget.(*gi.Action)
+	txt := kit.ToString(vv.Value.Interface())
+	if txt == "" {
+		txt = "(none)"
+	}
+	ac.SetText(txt)
+}
+
+func (vv *MacroValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	ac := vv.Widget.(*gi.Action)
+	ac.SetProp("border-radius", units.NewValue(4, units.Px))
+	ac.ActionSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		vvv, _ := recv.Embed(KiT_MacroValueView).(*MacroValueView)
+		ac := vvv.Widget.(*gi.Action)
+		vvv.Activate(ac.Viewport, nil, nil)
+	})
+	vv.UpdateWidget()
+}
+
+func (vv *MacroValueView) HasAction() bool {
+	return true
+}
+
+func (vv *MacroValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.RecvFunc) {
+	if vv.IsInactive() {
+		return
+	}
+	cur := kit.ToString(vv.Value.Interface())
+	curRow := -1
+	if cur != "" {
+		_, curRow, _ = AvailMacros.MacroByName(MacroName(cur))
+	}
+	desc, _ := vv.Tag("desc")
+	giv.TableViewSelectDialog(vp, &AvailMacros, giv.DlgOpts{Title: "Select a Named Keyboard Macro", Prompt: desc}, curRow, nil,
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig == int64(gi.DialogAccepted) {
+				ddlg, _ := send.(*gi.Dialog)
+				si := giv.TableViewSelectDialogValue(ddlg)
+				if si >= 0 {
+					pt := AvailMacros[si]
+					vv.SetValue(pt.Name)
+					vv.UpdateWidget()
+				}
+			}
+			if dlgRecv != nil && dlgFunc != nil {
+				dlgFunc(dlgRecv, send, sig, data)
+			}
+		})
+
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //  RegistersView
 
@@ -0,0 +1,620 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/vci"
+)
+
+// VcsRepo is a version control repository, as used by the file tree status
+// marks and the commit panel, so that they work the same way for all the
+// version control systems -- see NewVcsRepo for the implementations (git,
+// svn and hg).  All file paths are relative to the Root, / separated.
+type VcsRepo interface {
+	// Vcs returns the name of the version control system
+	Vcs() giv.VersCtrlName
+
+	// Root returns the root directory of the repository (working copy)
+	Root() string
+
+	// Changes returns the changed and untracked files, sorted by path
+	Changes() ([]VcsChange, error)
+
+	// Diff returns the uncommitted changes of given file -- for an
+	// untracked file, its whole contents
+	Diff(ch *VcsChange) ([]byte, error)
+
+	// Commit commits the changes of given files with given message:
+	// untracked ones are added first -- for a VcsStager, the staged changes
+	// are committed if no files are given
+	Commit(msg string, files []string) error
+
+	// Log returns the commits of given file (all if empty), optionally only
+	// those since a date-like expression the VCS understands
+	Log(fpath, since string) (vci.Log, error)
+
+	// Blame returns the file annotated with the revision that last changed
+	// each line
+	Blame(fpath string) ([]byte, error)
+
+	// FileContents returns the contents of given file at given revision
+	// (the last commit if empty)
+	FileContents(fpath, rev string) ([]byte, error)
+
+	// Add adds the file to the repository
+	Add(fpath string) error
+
+	// Move moves the file, keeping its history
+	Move(oldpath, newpath string) error
+
+	// Delete removes the file from the repository and the working copy
+	Delete(fpath string) error
+
+	// Revert discards the uncommitted changes of the file (destructive!)
+	Revert(fpath string) error
+}
+
+// VcsStager is a VcsRepo with a staging area (index), where the changes
+// to commit are collected (git)
+type VcsStager interface {
+	VcsRepo
+
+	// Stage adds the changes of given file to the index (all if empty)
+	Stage(fpath string) error
+
+	// Unstage removes the changes of given file from the index (all if
+	// empty), keeping them in the working copy
+	Unstage(fpath string) error
+
+	// Amend replaces the last commit with one with the staged changes too,
+	// and given message
+	Amend(msg string) error
+
+	// LastMsg returns the message of the last commit
+	LastMsg() (string, error)
+
+	// SetIndexContents sets the staged contents of given file, which must
+	// be in the repository -- FileContents with rev : gets them
+	SetIndexContents(fpath string, src []byte) error
+}
+
+// NewVcsRepo returns the repository that given directory is in (see
+// DetectVersCtrlRoot) -- an error if none, or its version control system
+// is not supported
+func NewVcsRepo(dir string) (VcsRepo, error) {
+	vc, root := DetectVersCtrlRoot(dir)
+	switch vc {
+	case "git":
+		return &GitVcs{CliVcs{RootDir: root, Cmd: "git"}}, nil
+	case "svn":
+		return &SvnVcs{CliVcs{RootDir: root, Cmd: "svn"}}, nil
+	case "hg":
+		return &HgVcs{CliVcs{RootDir: root, Cmd: "hg"}}, nil
+	case "":
+		return nil, fmt.Errorf("%v is not in a version control repository", dir)
+	}
+	return nil, fmt.Errorf("%v version control is not supported for %v", vc, dir)
+}
+
+// VcsChange is a file with uncommitted changes, or untracked.  Index and
+// Work are status letters as in git status --short, for the staged and the
+// other changes -- only Work is used if the VCS does not stage changes.
+type VcsChange struct {
+	Path  string `desc:"path of the file relative to the repository root, / separated"`
+	Index byte   `desc:"status of the staged changes -- space for none, ? for untracked"`
+	Work  byte   `desc:"status of the changes in the working copy -- space for none"`
+}
+
+// IsStaged returns true if the file has staged changes
+func (ch *VcsChange) IsStaged() bool {
+	return ch.Index != ' ' && ch.Index != '?'
+}
+
+// IsUntracked returns true if the file is not in the repository
+func (ch *VcsChange) IsUntracked() bool {
+	return ch.Index == '?'
+}
+
+// Status returns the two-letter status of the file, as in git status --short
+func (ch *VcsChange) Status() string {
+	return string([]byte{ch.Index, ch.Work})
+}
+
+// FileStatus returns the status of the file, as shown in the file tree
+func (ch *VcsChange) FileStatus() vci.FileStatus {
+	if ch.IsUntracked() {
+		return vci.Untracked
+	}
+	st := ch.Work
+	if st == ' ' {
+		st = ch.Index
+	}
+	switch st {
+	case 'M', 'R', 'C', 'T':
+		return vci.Modified
+	case 'A':
+		return vci.Added
+	case 'D':
+		return vci.Deleted
+	case 'U':
+		return vci.Conflicted
+	}
+	return vci.Stored
+}
+
+// UntrackedDiff returns the whole contents of given untracked file as a
+// diff adding it
+func UntrackedDiff(root, fpath string) ([]byte, error) {
+	src, err := ioutil.ReadFile(filepath.Join(root, filepath.FromSlash(fpath)))
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "untracked file: %v\n--- /dev/null\n+++ b/%v\n", fpath, fpath)
+	if bytes.IndexByte(src, 0) >= 0 {
+		b.WriteString("(binary file)\n")
+		return b.Bytes(), nil
+	}
+	for _, ln := range bytes.Split(bytes.TrimSuffix(src, []byte("\n")), []byte("\n")) {
+		b.WriteString("+")
+		b.Write(ln)
+		b.WriteString("\n")
+	}
+	return b.Bytes(), nil
+}
+
+// SortVcsChanges sorts the changes by path
+func SortVcsChanges(chg []VcsChange) {
+	sort.Slice(chg, func(i, j int) bool {
+		return chg[i].Path < chg[j].Path
+	})
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    CliVcs
+
+// CliVcs is the common part of the VcsRepo implementations, which run the
+// command line tool of the VCS in the root directory
+type CliVcs struct {
+	RootDir string `desc:"root directory of the repository"`
+	Cmd     string `desc:"command line tool of the VCS"`
+}
+
+// Root returns the root directory of the repository
+func (cv *CliVcs) Root() string {
+	return cv.RootDir
+}
+
+// Vcs returns the name of the version control system
+func (cv *CliVcs) Vcs() giv.VersCtrlName {
+	return giv.VersCtrlName(cv.Cmd)
+}
+
+// Run runs the VCS tool with given args in the root directory, returning
+// its standard output, which is data for many commands (file contents,
+// status) -- the error includes its standard error if it failed
+func (cv *CliVcs) Run(args ...string) ([]byte, error) {
+	return cv.RunInput(nil, args...)
+}
+
+// RunInput runs the VCS tool as Run, with given standard input, if not nil
+func (cv *CliVcs) RunInput(in []byte, args ...string) ([]byte, error) {
+	cmd := exec.Command(cv.Cmd, args...)
+	cmd.Dir = cv.RootDir
+	cmd.Env = AskPassCmdEnv()
+	if in != nil {
+		cmd.Stdin = bytes.NewReader(in)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		msg := out // some tools report on stdout, e.g., nothing to commit
+		if ee, ok := err.(*exec.ExitError); ok && len(bytes.TrimSpace(ee.Stderr)) > 0 {
+			msg = ee.Stderr
+		}
+		return nil, fmt.Errorf("%v %v: %v: %v", cv.Cmd, strings.Join(args, " "), err, strings.TrimSpace(string(msg)))
+	}
+	return out, nil
+}
+
+// RunErr runs the VCS tool with given args, returning only the error
+func (cv *CliVcs) RunErr(args ...string) error {
+	_, err := cv.Run(args...)
+	return err
+}
+
+// Blame returns the file annotated with the revision that last changed each line
+func (cv *CliVcs) Blame(fpath string) ([]byte, error) {
+	return cv.Run("blame", "--", fpath)
+}
+
+// Add adds the file to the repository
+func (cv *CliVcs) Add(fpath string) error {
+	return cv.RunErr("add", "--", fpath)
+}
+
+// Move moves the file, keeping its history
+func (cv *CliVcs) Move(oldpath, newpath string) error {
+	return cv.RunErr("mv", "--", oldpath, newpath)
+}
+
+// Delete removes the file from the repository and the working copy
+func (cv *CliVcs) Delete(fpath string) error {
+	return cv.RunErr("rm", "-f", "--", fpath)
+}
+
+// StatusChanges parses the output of a status command with one file per
+// line, the status letter first and the path at given column, where ? is
+// untracked and ! is missing (deleted)
+func StatusChanges(out []byte, col int) []VcsChange {
+	var chg []VcsChange
+	for _, ln := range strings.Split(string(out), "\n") {
+		ln = strings.TrimRight(ln, "\r")
+		if len(ln) <= col || ln[0] == ' ' {
+			continue
+		}
+		ch := VcsChange{Index: ' ', Work: ln[0], Path: filepath.ToSlash(strings.TrimSpace(ln[col:]))}
+		switch ch.Work {
+		case '?':
+			ch.Index = '?'
+		case '!':
+			ch.Work = 'D'
+		case 'X', 'I':
+			continue // externals, ignored
+		}
+		chg = append(chg, ch)
+	}
+	SortVcsChanges(chg)
+	return chg
+}
+
+// CommitUntracked returns the given files that are untracked in given changes
+func CommitUntracked(chg []VcsChange, files []string) []string {
+	var add []string
+	for _, f := range files {
+		for i := range chg {
+			if chg[i].Path == f && chg[i].IsUntracked() {
+				add = append(add, f)
+			}
+		}
+	}
+	return add
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    GitVcs
+
+// GitVcs is the VcsRepo for git, which stages changes
+type GitVcs struct {
+	CliVcs
+}
+
+// Changes returns the changed and untracked files, sorted by path
+func (gv *GitVcs) Changes() ([]VcsChange, error) {
+	out, err := gv.Run("status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	var chg []VcsChange
+	ents := strings.Split(string(out), "\x00")
+	for i := 0; i < len(ents); i++ {
+		ent := ents[i]
+		if len(ent) < 4 {
+			continue
+		}
+		ch := VcsChange{Index: ent[0], Work: ent[1], Path: ent[3:]}
+		if ch.Index == 'R' || ch.Index == 'C' {
+			i++ // skip the original path
+		}
+		chg = append(chg, ch)
+	}
+	SortVcsChanges(chg)
+	return chg, nil
+}
+
+// Diff returns the staged changes of the file, and then those not yet staged
+func (gv *GitVcs) Diff(ch *VcsChange) ([]byte, error) {
+	if ch.IsUntracked() {
+		return UntrackedDiff(gv.RootDir, ch.Path)
+	}
+	var b bytes.Buffer
+	if ch.IsStaged() {
+		out, err := gv.Run("diff", "--cached", "--", ch.Path)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("staged changes:\n")
+		b.Write(out)
+	}
+	if ch.Work != ' ' {
+		out, err := gv.Run("diff", "--", ch.Path)
+		if err != nil {
+			return nil, err
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("changes not staged:\n")
+		b.Write(out)
+	}
+	return b.Bytes(), nil
+}
+
+// Commit commits the staged changes, or all the changes of given files
+func (gv *GitVcs) Commit(msg string, files []string) error {
+	if len(files) == 0 {
+		return gv.RunErr("commit", "-m", msg)
+	}
+	for _, f := range files {
+		if err := gv.Stage(f); err != nil {
+			return err
+		}
+	}
+	return gv.RunErr(append([]string{"commit", "-m", msg, "--"}, files...)...)
+}
+
+// Log returns the commits of given file (all if empty)
+func (gv *GitVcs) Log(fpath, since string) (vci.Log, error) {
+	args := []string{"log", "--date=iso", "--format=%h%x1f%ad%x1f%an%x1f%ae%x1f%s"}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	if fpath != "" {
+		args = append(args, "--", fpath)
+	}
+	out, err := gv.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var lg vci.Log
+	for _, ln := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fs := strings.Split(ln, "\x1f")
+		if len(fs) == 5 {
+			lg.Add(fs[0], fs[1], fs[2], fs[3], fs[4])
+		}
+	}
+	return lg, nil
+}
+
+// FileContents returns the contents of given file at given revision -- :
+// for the staged contents (in the index)
+func (gv *GitVcs) FileContents(fpath, rev string) ([]byte, error) {
+	switch rev {
+	case "":
+		rev = "HEAD:"
+	case ":":
+	default:
+		rev += ":"
+	}
+	return gv.Run("show", rev+fpath)
+}
+
+// Revert discards the uncommitted changes of the file, staged or not
+func (gv *GitVcs) Revert(fpath string) error {
+	return gv.RunErr("checkout", "HEAD", "--", fpath)
+}
+
+// Stage adds the changes of given file to the index (all if empty)
+func (gv *GitVcs) Stage(fpath string) error {
+	if fpath == "" {
+		return gv.RunErr("add", "--all")
+	}
+	return gv.RunErr("add", "--all", "--", fpath)
+}
+
+// Unstage removes the changes of given file from the index (all if empty)
+func (gv *GitVcs) Unstage(fpath string) error {
+	if fpath == "" {
+		return gv.RunErr("reset", "-q")
+	}
+	return gv.RunErr("reset", "-q", "--", fpath)
+}
+
+// Amend replaces the last commit with one with the staged changes too
+func (gv *GitVcs) Amend(msg string) error {
+	return gv.RunErr("commit", "--amend", "-m", msg)
+}
+
+// LastMsg returns the message of the last commit
+func (gv *GitVcs) LastMsg() (string, error) {
+	out, err := gv.Run("log", "-1", "--format=%B")
+	return strings.TrimSpace(string(out)), err
+}
+
+// SetIndexContents sets the staged contents of given file
+func (gv *GitVcs) SetIndexContents(fpath string, src []byte) error {
+	ls, err := gv.Run("ls-files", "-s", "--", fpath)
+	if err != nil {
+		return err
+	}
+	fs := strings.Fields(string(ls))
+	if len(fs) < 4 {
+		return fmt.Errorf("%v is not in the index -- stage the whole file first", fpath)
+	}
+	sha, err := gv.RunInput(src, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return err
+	}
+	return gv.RunErr("update-index", "--cacheinfo", fs[0]+","+strings.TrimSpace(string(sha))+","+fpath)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    SvnVcs
+
+// SvnVcs is the VcsRepo for subversion
+type SvnVcs struct {
+	CliVcs
+}
+
+// Changes returns the changed and untracked files, sorted by path
+func (sv *SvnVcs) Changes() ([]VcsChange, error) {
+	out, err := sv.Run("status")
+	if err != nil {
+		return nil, err
+	}
+	ch := StatusChanges(out, 8)
+	for i := range ch {
+		if ch[i].Work == 'C' {
+			ch[i].Work = 'U' // conflicted
+		}
+	}
+	return ch, nil
+}
+
+// Diff returns the uncommitted changes of the file
+func (sv *SvnVcs) Diff(ch *VcsChange) ([]byte, error) {
+	if ch.IsUntracked() {
+		return UntrackedDiff(sv.RootDir, ch.Path)
+	}
+	return sv.Run("diff", ch.Path)
+}
+
+// Commit commits the changes of given files (all if none)
+func (sv *SvnVcs) Commit(msg string, files []string) error {
+	if chg, err := sv.Changes(); err == nil {
+		for _, f := range CommitUntracked(chg, files) {
+			if err := sv.Add(f); err != nil {
+				return err
+			}
+		}
+	}
+	return sv.RunErr(append([]string{"commit", "-m", msg}, files...)...)
+}
+
+// Log returns the commits of given file (all if empty) -- since is the
+// maximum number of commits for svn
+func (sv *SvnVcs) Log(fpath, since string) (vci.Log, error) {
+	args := []string{"log"}
+	if since != "" {
+		args = append(args, "--limit", since)
+	}
+	if fpath != "" {
+		args = append(args, fpath)
+	}
+	out, err := sv.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var lg vci.Log
+	var cm *vci.Commit
+	for _, ln := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(ln, "----------") {
+			cm = nil
+			continue
+		}
+		fs := strings.Split(ln, " | ")
+		if cm == nil && len(fs) >= 3 && strings.HasPrefix(ln, "r") {
+			date := fs[2]
+			if pi := strings.Index(date, " ("); pi > 0 {
+				date = date[:pi]
+			}
+			cm = lg.Add(fs[0], date, fs[1], "", "")
+			continue
+		}
+		if cm != nil && cm.Message == "" && strings.TrimSpace(ln) != "" {
+			cm.Message = strings.TrimSpace(ln)
+		}
+	}
+	return lg, nil
+}
+
+// FileContents returns the contents of given file at given revision
+func (sv *SvnVcs) FileContents(fpath, rev string) ([]byte, error) {
+	if rev == "" {
+		rev = "BASE"
+	}
+	return sv.Run("cat", "-r", strings.TrimPrefix(rev, "r"), fpath)
+}
+
+// Delete removes the file from the repository and the working copy
+func (sv *SvnVcs) Delete(fpath string) error {
+	return sv.RunErr("rm", "--force", "--", fpath)
+}
+
+// Revert discards the uncommitted changes of the file
+func (sv *SvnVcs) Revert(fpath string) error {
+	return sv.RunErr("revert", fpath)
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    HgVcs
+
+// HgVcs is the VcsRepo for mercurial
+type HgVcs struct {
+	CliVcs
+}
+
+// Changes returns the changed and untracked files, sorted by path
+func (hv *HgVcs) Changes() ([]VcsChange, error) {
+	out, err := hv.Run("status")
+	if err != nil {
+		return nil, err
+	}
+	ch := StatusChanges(out, 2)
+	for i := range ch {
+		if ch[i].Work == 'R' {
+			ch[i].Work = 'D' // removed
+		}
+	}
+	return ch, nil
+}
+
+// Diff returns the uncommitted changes of the file
+func (hv *HgVcs) Diff(ch *VcsChange) ([]byte, error) {
+	if ch.IsUntracked() {
+		return UntrackedDiff(hv.RootDir, ch.Path)
+	}
+	return hv.Run("diff", "--", ch.Path)
+}
+
+// Commit commits the changes of given files (all if none), adding the
+// untracked ones
+func (hv *HgVcs) Commit(msg string, files []string) error {
+	return hv.RunErr(append([]string{"commit", "-A", "-m", msg, "--"}, files...)...)
+}
+
+// Log returns the commits of given file (all if empty)
+func (hv *HgVcs) Log(fpath, since string) (vci.Log, error) {
+	args := []string{"log", "--template", "{node|short}\x1f{date|isodate}\x1f{author|person}\x1f{author|email}\x1f{desc|firstline}\n"}
+	if since != "" {
+		args = append(args, "--date", ">"+since)
+	}
+	if fpath != "" {
+		args = append(args, "--", fpath)
+	}
+	out, err := hv.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var lg vci.Log
+	for _, ln := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fs := strings.Split(ln, "\x1f")
+		if len(fs) == 5 {
+			lg.Add(fs[0], fs[1], fs[2], fs[3], fs[4])
+		}
+	}
+	return lg, nil
+}
+
+// FileContents returns the contents of given file at given revision
+func (hv *HgVcs) FileContents(fpath, rev string) ([]byte, error) {
+	if rev == "" {
+		rev = "."
+	}
+	return hv.Run("cat", "-r", rev, "--", fpath)
+}
+
+// Revert discards the uncommitted changes of the file
+func (hv *HgVcs) Revert(fpath string) error {
+	return hv.RunErr("revert", "--no-backup", "--", fpath)
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/vci"
+)
+
+// VcsStatusMarker returns the marker shown after the name of a file with
+// given version control status in the file tree ("" for none)
+func VcsStatusMarker(st vci.FileStatus) string {
+	switch st {
+	case vci.Untracked:
+		return "?"
+	case vci.Modified:
+		return "M"
+	case vci.Added:
+		return "A"
+	case vci.Deleted:
+		return "D"
+	case vci.Conflicted:
+		return "!"
+	case vci.Updated:
+		return "U"
+	}
+	return ""
+}
+
+// VcsStatusClass returns the style class for given version control status
+// ("" for none), as used by giv.FileTreeView
+func VcsStatusClass(st vci.FileStatus) string {
+	if st == vci.Stored {
+		return ""
+	}
+	return strings.ToLower(st.String())
+}
+
+// VcsStatusPriority returns how significant given status is for marking
+// the directories containing a file: conflicts first, then changes, then
+// untracked files -- 0 for files with no changes
+func VcsStatusPriority(st vci.FileStatus) int {
+	switch st {
+	case vci.Conflicted:
+		return 5
+	case vci.Modified, vci.Deleted:
+		return 4
+	case vci.Added:
+		return 3
+	case vci.Updated:
+		return 2
+	case vci.Untracked:
+		return 1
+	}
+	return 0
+}
+
+// VcsStatus gets the version control status of all the files in the
+// repositories of a file tree in the background, and applies it to the
+// file nodes, whose names are then marked in the tree (see
+// FileNode.Label).  Directories are marked with the most significant
+// status of the files within them (see VcsStatusPriority).  Update is
+// called after commands run, files are saved, and files are changed by
+// external tools.
+type VcsStatus struct {
+	Files    map[string]vci.FileStatus `desc:"status of files (full path) that differ from the repository, or are untracked"`
+	Dirs     map[string]vci.FileStatus `desc:"status of directories (full path) containing such files"`
+	Roots    []string                  `desc:"root directories of the repositories"`
+	Updating bool                      `desc:"update is in progress"`
+	Again    bool                      `desc:"another update was requested while updating"`
+	Mu       sync.Mutex                `desc:"mutex protecting fields"`
+}
+
+// Update gets the status of all the files in the repositories of given
+// tree and applies it to the tree and its view (if non-nil) -- files
+// ignored by ign (if non-nil) do not mark their directories.  Intended to
+// be run in a goroutine: if an update is already in progress, another is
+// done after it.
+func (vs *VcsStatus) Update(ft *giv.FileTree, ftv *FileTreeView, ign *FileIgnore) {
+	vs.Mu.Lock()
+	if vs.Updating {
+		vs.Again = true
+		vs.Mu.Unlock()
+		return
+	}
+	vs.Updating = true
+	vs.Mu.Unlock()
+	for {
+		vs.Read(ft, ign)
+		vs.Apply(ft, ftv)
+		vs.Mu.Lock()
+		if !vs.Again {
+			vs.Updating = false
+			vs.Mu.Unlock()
+			return
+		}
+		vs.Again = false
+		vs.Mu.Unlock()
+	}
+}
+
+// Read reads the status of the files in the repositories of given tree:
+// those of its directories that are repository roots (so nested
+// repositories have their own status), and the one the tree root is in,
+// of any VCS supported by VcsRepo
+func (vs *VcsStatus) Read(ft *giv.FileTree, ign *FileIgnore) {
+	dirs := []string{string(ft.FPath)}
+	ft.UpdtMu.Lock()
+	ft.FuncDownMeFirst(0, ft, func(k ki.Ki, level int, d interface{}) bool {
+		sfn, ok := k.Embed(giv.KiT_FileNode).(*giv.FileNode)
+		if !ok || !sfn.IsDir() {
+			return ki.Continue
+		}
+		if sfn.DirRepo != nil || IsVersCtrlRoot(string(sfn.FPath)) {
+			dirs = append(dirs, string(sfn.FPath))
+		}
+		return ki.Continue
+	})
+	ft.UpdtMu.Unlock()
+	var repos []VcsRepo
+	have := map[string]bool{}
+	for _, dir := range dirs {
+		repo, err := NewVcsRepo(dir)
+		if err != nil || have[repo.Root()] {
+			continue
+		}
+		have[repo.Root()] = true
+		repos = append(repos, repo)
+	}
+	files := make(map[string]vci.FileStatus)
+	dstat := make(map[string]vci.FileStatus)
+	roots := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		chg, err := repo.Changes()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		root := repo.Root()
+		roots = append(roots, root)
+		for i := range chg {
+			st := chg[i].FileStatus()
+			if st == vci.Stored {
+				continue
+			}
+			fpath := filepath.Join(root, filepath.FromSlash(chg[i].Path))
+			files[fpath] = st
+			if ign.Ignored(fpath, false) {
+				continue
+			}
+			pr := VcsStatusPriority(st)
+			for dir := filepath.Dir(fpath); len(dir) > len(root); dir = filepath.Dir(dir) {
+				if dst, has := dstat[dir]; has && VcsStatusPriority(dst) >= pr {
+					break
+				}
+				dstat[dir] = st
+			}
+		}
+	}
+	vs.Mu.Lock()
+	vs.Files = files
+	vs.Dirs = dstat
+	vs.Roots = roots
+	vs.Mu.Unlock()
+}
+
+// InRepo returns true if given path is within one of the repositories
+func (vs *VcsStatus) InRepo(fpath string) bool {
+	for _, r := range vs.Roots {
+		if strings.HasPrefix(fpath, r+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply sets the status of the nodes of given tree from the last Read,
+// and re-renders the view if non-nil
+func (vs *VcsStatus) Apply(ft *giv.FileTree, ftv *FileTreeView) {
+	updt := false
+	if ftv != nil {
+		updt = ftv.UpdateStart()
+		ftv.SetFullReRender()
+	}
+	ft.UpdtMu.Lock()
+	vs.Mu.Lock()
+	ft.FuncDownMeFirst(0, ft, func(k ki.Ki, level int, d interface{}) bool {
+		fn, ok := k.Embed(KiT_FileNode).(*FileNode)
+		if !ok || fn.IsIrregular() {
+			return ki.Continue
+		}
+		fpath := string(fn.FPath)
+		if !vs.InRepo(fpath) {
+			return ki.Continue
+		}
+		if fn.IsDir() {
+			st, has := vs.Dirs[fpath]
+			fn.DirVcs = st
+			fn.HasDirVcs = has
+			return ki.Continue
+		}
+		if st, has := vs.Files[fpath]; has {
+			fn.Info.Vcs = st
+		} else {
+			fn.Info.Vcs = vci.Stored
+		}
+		return ki.Continue
+	})
+	vs.Mu.Unlock()
+	ft.UpdtMu.Unlock()
+	if ftv != nil {
+		ftv.UpdateEnd(updt)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/goki/gi/giv"
+)
+
+func init() {
+	// fossil is not known to the file tree, but its commands are filtered
+	// by VersCtrlCmdNames like the others
+	giv.VersCtrlSystems = append(giv.VersCtrlSystems, "fossil")
+}
+
+// VersCtrlMarkers are the files or directories at the root of a repository
+// (checkout) that identify its version control system
+var VersCtrlMarkers = []struct {
+	File string
+	Name giv.VersCtrlName
+}{
+	{".git", "git"},
+	{".hg", "hg"},
+	{".fslckout", "fossil"},
+	{"_FOSSIL_", "fossil"},
+	{".bzr", "bzr"},
+	{".svn", "svn"},
+}
+
+// DetectVersCtrl returns the version control system of the repository that
+// given directory is in, looking for the VersCtrlMarkers in it and each of
+// its parents in turn -- "" if none
+func DetectVersCtrl(dir string) giv.VersCtrlName {
+	vc, _ := DetectVersCtrlRoot(dir)
+	return vc
+}
+
+// IsVersCtrlRoot returns true if given directory is the root of a
+// repository (has one of the VersCtrlMarkers)
+func IsVersCtrlRoot(dir string) bool {
+	for _, vm := range VersCtrlMarkers {
+		if _, err := os.Stat(filepath.Join(dir, vm.File)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectVersCtrlRoot returns the version control system of the repository
+// that given directory is in, and the root directory (checkout) of the
+// repository, where its marker is -- "" if none
+func DetectVersCtrlRoot(dir string) (giv.VersCtrlName, string) {
+	dir, _ = filepath.Abs(dir)
+	for {
+		for _, vm := range VersCtrlMarkers {
+			if _, err := os.Stat(filepath.Join(dir, vm.File)); err == nil {
+				return vm.Name, dir
+			}
+		}
+		pdir := filepath.Dir(dir)
+		if pdir == dir {
+			return "", ""
+		}
+		dir = pdir
+	}
+}
@@ -0,0 +1,520 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// VulnAdvisoryURL is the format of the link to the page of an advisory of
+// the Go vulnerability database
+var VulnAdvisoryURL = "https://pkg.go.dev/vuln/%s"
+
+// VulnOSV is the advisory of a vulnerability, in the OSV format of the
+// -json output of govulncheck (only the fields shown)
+type VulnOSV struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Database struct {
+		URL string `json:"url"`
+	} `json:"database_specific"`
+}
+
+// VulnFrame is a frame of the call path (trace) of a finding of
+// govulncheck: the first is the vulnerable symbol, the last the entry
+// point in the code of the project
+type VulnFrame struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Package  string `json:"package"`
+	Function string `json:"function"`
+	Receiver string `json:"receiver"`
+	Position *struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+	} `json:"position"`
+}
+
+// Func returns the name of the function of the frame, with its receiver
+func (vf *VulnFrame) Func() string {
+	nm := vf.Function
+	if vf.Receiver != "" {
+		nm = strings.TrimPrefix(vf.Receiver, "*") + "." + nm
+	}
+	if vf.Package != "" {
+		nm = vf.Package + "." + nm
+	}
+	return nm
+}
+
+// VulnFinding is a finding of govulncheck: a vulnerability in a module
+// required by the project, with the call path to it if it is called
+type VulnFinding struct {
+	OSV          string      `json:"osv"`
+	FixedVersion string      `json:"fixed_version"`
+	Trace        []VulnFrame `json:"trace"`
+}
+
+// VulnMessage is a message of the stream of the -json output of
+// govulncheck
+type VulnMessage struct {
+	OSV     *VulnOSV     `json:"osv"`
+	Finding *VulnFinding `json:"finding"`
+}
+
+// VulnLevels are the levels of a vulnerability, from the most to the least
+// severe: the vulnerable symbol is called, its package is imported, or
+// its module is only required
+var VulnLevels = []string{"called", "imported", "required"}
+
+// VulnEntry is a vulnerability affecting the project, with all of its
+// findings
+type VulnEntry struct {
+	ID       string        `desc:"id of the vulnerability in the Go vulnerability database"`
+	Level    string        `desc:"level of the vulnerability: called if the vulnerable code is called by the project, imported if only its package is imported, and required if only its module is required"`
+	Module   string        `width:"30" desc:"the module with the vulnerability"`
+	Found    string        `desc:"version of the module required"`
+	Fixed    string        `desc:"version of the module with the vulnerability fixed"`
+	Summary  string        `width:"50" desc:"summary of the advisory"`
+	Paths    int           `desc:"number of call paths to the vulnerable code"`
+	OSV      *VulnOSV      `view:"-" json:"-" desc:"the advisory"`
+	Findings []VulnFinding `view:"-" json:"-" desc:"the findings"`
+}
+
+// URL returns the link to the page of the advisory
+func (ve *VulnEntry) URL() string {
+	if ve.OSV != nil && ve.OSV.Database.URL != "" {
+		return ve.OSV.Database.URL
+	}
+	return fmt.Sprintf(VulnAdvisoryURL, ve.ID)
+}
+
+// vulnLevel returns the level of given finding
+func vulnLevel(vf *VulnFinding) int {
+	if len(vf.Trace) == 0 {
+		return 2
+	}
+	switch {
+	case vf.Trace[0].Function != "":
+		return 0
+	case vf.Trace[0].Package != "":
+		return 1
+	}
+	return 2
+}
+
+// ParseVulnJSON returns the vulnerabilities in the -json output of
+// govulncheck, the called ones first
+func ParseVulnJSON(r io.Reader) ([]VulnEntry, error) {
+	osvs := map[string]*VulnOSV{}
+	ents := map[string]*VulnEntry{}
+	lvls := map[string]int{}
+	dec := json.NewDecoder(r)
+	for {
+		var msg VulnMessage
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if msg.OSV != nil {
+			osvs[msg.OSV.ID] = msg.OSV
+		}
+		vf := msg.Finding
+		if vf == nil {
+			continue
+		}
+		ve, has := ents[vf.OSV]
+		if !has {
+			ve = &VulnEntry{ID: vf.OSV, Fixed: vf.FixedVersion}
+			if len(vf.Trace) > 0 {
+				ve.Module, ve.Found = vf.Trace[0].Module, vf.Trace[0].Version
+			}
+			ents[vf.OSV] = ve
+			lvls[vf.OSV] = 2
+		}
+		lvl := vulnLevel(vf)
+		if lvl < lvls[vf.OSV] {
+			lvls[vf.OSV] = lvl
+		}
+		if lvl == 0 {
+			ve.Paths++
+		}
+		ve.Findings = append(ve.Findings, *vf)
+	}
+	vens := make([]VulnEntry, 0, len(ents))
+	for id, ve := range ents {
+		ve.Level = VulnLevels[lvls[id]]
+		if osv, has := osvs[id]; has {
+			ve.OSV = osv
+			ve.Summary = osv.Summary
+		}
+		vens = append(vens, *ve)
+	}
+	sort.Slice(vens, func(i, j int) bool {
+		li, lj := lvls[vens[i].ID], lvls[vens[j].ID]
+		if li != lj {
+			return li < lj
+		}
+		return vens[i].ID < vens[j].ID
+	})
+	return vens, nil
+}
+
+// VulnView runs govulncheck on the packages of the project and lists the
+// vulnerabilities found, with the call paths to them, linked to the
+// calling source lines, and the advisories
+type VulnView struct {
+	gi.Layout
+	Gide    Gide        `json:"-" xml:"-" copy:"-" desc:"parent gide project"`
+	Dir     string      `desc:"directory where govulncheck was run"`
+	Vulns   []VulnEntry `desc:"the vulnerabilities found"`
+	Running bool        `json:"-" xml:"-" desc:"true while govulncheck is running"`
+}
+
+var KiT_VulnView = kit.Types.AddType(&VulnView{}, VulnViewProps)
+
+// VulnCmdName is the name of the govulncheck command run by the VulnView
+// in the CmdRuns of the project, for stopping it
+var VulnCmdName = "Check Vulnerabilities"
+
+// VulnCheckCmd is the govulncheck command, run with -json ./... -- if not
+// found, it is run with go run golang.org/x/vuln/cmd/govulncheck@latest
+var VulnCheckCmd = "govulncheck"
+
+// Config configures the view
+func (vv *VulnView) Config(ge Gide) {
+	vv.Gide = ge
+	vv.Lay = gi.LayoutVert
+	vv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+	config := kit.TypeAndNameList{}
+	config.Add(gi.KiT_ToolBar, "vulnbar")
+	config.Add(gi.KiT_SplitView, "vulnsplit")
+	mods, updt := vv.ConfigChildren(config)
+	if !mods {
+		updt = vv.UpdateStart()
+	}
+	vv.ConfigToolbar()
+	vv.ConfigSplitView()
+	vv.UpdateEnd(updt)
+}
+
+// ToolBar returns the toolbar
+func (vv *VulnView) ToolBar() *gi.ToolBar {
+	return vv.ChildByName("vulnbar", 0).(*gi.ToolBar)
+}
+
+// SplitView returns the split view of the vulnerabilities and their call
+// paths
+func (vv *VulnView) SplitView() *gi.SplitView {
+	return vv.ChildByName("vulnsplit", 1).(*gi.SplitView)
+}
+
+// TableView returns the table of the vulnerabilities
+func (vv *VulnView) TableView() *giv.TableView {
+	return vv.SplitView().ChildByName("vulns", 0).(*giv.TableView)
+}
+
+// TextView returns the TextView of the call paths
+func (vv *VulnView) TextView() *giv.TextView {
+	ly := vv.SplitView().ChildByName("out", 1).(*gi.Layout)
+	return ly.ChildByType(giv.KiT_TextView, ki.Embeds, 0).Embed(giv.KiT_TextView).(*giv.TextView)
+}
+
+// SelVuln returns the selected vulnerability, nil if none
+func (vv *VulnView) SelVuln() *VulnEntry {
+	idx := vv.TableView().SelectedIdx
+	if idx < 0 || idx >= len(vv.Vulns) {
+		vv.Gide.SetStatus("select a vulnerability first")
+		return nil
+	}
+	return &vv.Vulns[idx]
+}
+
+// ConfigToolbar adds the toolbar actions
+func (vv *VulnView) ConfigToolbar() {
+	tb := vv.ToolBar()
+	if tb.HasChildren() {
+		return
+	}
+	tb.SetStretchMaxWidth()
+	tb.AddAction(gi.ActOpts{Label: "Check", Icon: "play", Tooltip: "run govulncheck on the packages of the project"},
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			vvv, _ := recv.Embed(KiT_VulnView).(*VulnView)
+			vvv.Check()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stop", Icon: "stop", Tooltip: "stop govulncheck"},
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			vvv, _ := recv.Embed(KiT_VulnView).(*VulnView)
+			vvv.Gide.CmdRuns().KillByName(VulnCmdName)
+		})
+	tb.AddAction(gi.ActOpts{Label: "Paths", Icon: "info", Tooltip: "show the advisory of the selected vulnerability and the call paths to it from the project, with links to the calling lines (also by double-click)"},
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			vvv, _ := recv.Embed(KiT_VulnView).(*VulnView)
+			if ve := vvv.SelVuln(); ve != nil {
+				vvv.ShowVuln(ve)
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Advisory", Icon: "file-open", Tooltip: "open the advisory of the selected vulnerability in the browser"},
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			vvv, _ := recv.Embed(KiT_VulnView).(*VulnView)
+			if ve := vvv.SelVuln(); ve != nil {
+				oswin.TheApp.OpenURL(ve.URL())
+			}
+		})
+	tb.AddAction(gi.ActOpts{Label: "Upgrade", Icon: "wedge-up", Tooltip: "upgrade the module of the selected vulnerability to the version with it fixed, with go get module@version"},
+		vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			vvv, _ := recv.Embed(KiT_VulnView).(*VulnView)
+			if ve := vvv.SelVuln(); ve != nil {
+				vvv.Upgrade(ve)
+			}
+		})
+}
+
+// ConfigSplitView configures the split view of the vulnerabilities and
+// their call paths
+func (vv *VulnView) ConfigSplitView() {
+	split := vv.SplitView()
+	split.Dim = mat32.Y
+	if len(split.Kids) > 0 {
+		return
+	}
+	tv := split.AddNewChild(giv.KiT_TableView, "vulns").(*giv.TableView)
+	tv.SetStretchMax()
+	tv.NoAdd = true
+	tv.NoDelete = true
+	tv.SetInactive()
+	tv.SetSlice(&vv.Vulns)
+	tv.SliceViewSig.Connect(vv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(giv.SliceViewDoubleClicked) {
+			vvv, _ := recv.Embed(KiT_VulnView).(*VulnView)
+			if ve := vvv.SelVuln(); ve != nil {
+				vvv.ShowVuln(ve)
+			}
+		}
+	})
+	ly := gi.AddNewLayout(split, "out", gi.LayoutVert)
+	otv := ConfigOutputTextView(ly)
+	otv.SetBuf(giv.NewTextBuf())
+	split.SetSplits(.4, .6)
+}
+
+// Check runs govulncheck on the packages of the project in the background,
+// and then lists the vulnerabilities found
+func (vv *VulnView) Check() {
+	if vv.Running {
+		vv.Gide.SetStatus("govulncheck is already running")
+		return
+	}
+	ge := vv.Gide
+	root, _, ok := GoModule(string(ge.ProjPrefs().ProjRoot))
+	if !ok {
+		vv.ShowOutput("no go.mod in the project directory or above it")
+		return
+	}
+	vv.Dir = root
+	ex := VulnCheckCmd
+	args := []string{"-json", "./..."}
+	if _, err := exec.LookPath(ex); err != nil {
+		ex = "go"
+		args = append([]string{"run", "golang.org/x/vuln/cmd/govulncheck@latest"}, args...)
+	}
+	cmdstr := ex + " " + strings.Join(args, " ")
+	vv.Running = true
+	vv.ShowOutput(cmdstr + "\nchecking...")
+	go func() {
+		cmd := exec.Command(ex, args...)
+		cmd.Dir = root
+		cmd.Env = ge.ProjPrefs().CmdEnv()
+		var out, errb bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &errb
+		ge.CmdRuns().AddCmd(VulnCmdName, cmdstr, &CmdAndArgs{Cmd: ex, Args: args}, cmd)
+		err := cmd.Start()
+		if err == nil {
+			err = cmd.Wait()
+		}
+		ge.CmdRuns().DeleteByName(VulnCmdName)
+		vv.Running = false
+		if vv.This() == nil || vv.IsDeleted() || vv.IsDestroyed() {
+			return
+		}
+		vens, perr := ParseVulnJSON(&out)
+		if perr != nil || (err != nil && out.Len() == 0) {
+			msg := strings.TrimSpace(errb.String())
+			if err != nil {
+				msg = err.Error() + "\n" + msg
+			}
+			if perr != nil {
+				msg += "\n" + perr.Error()
+			}
+			vv.ShowOutput(cmdstr + "\n" + msg)
+			return
+		}
+		vv.SetVulns(vens)
+		ncall := 0
+		for i := range vens {
+			if vens[i].Level == VulnLevels[0] {
+				ncall++
+			}
+		}
+		msg := fmt.Sprintf("govulncheck: %d vulnerabilities, %d called by the project", len(vens), ncall)
+		vv.ShowOutput(cmdstr + "\n" + msg)
+		ge.SetStatus(msg)
+	}()
+}
+
+// SetVulns sets the vulnerabilities shown
+func (vv *VulnView) SetVulns(vens []VulnEntry) {
+	vp := vv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	vv.Vulns = vens
+	tv := vv.TableView()
+	updt := tv.UpdateStart()
+	tv.SetFullReRender()
+	tv.SetSlice(&vv.Vulns)
+	tv.UpdateEnd(updt)
+}
+
+// ShowOutput shows given output, or error, below the vulnerabilities
+func (vv *VulnView) ShowOutput(out string) {
+	lns := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	mus := make([]string, len(lns))
+	for i, ln := range lns {
+		mus[i] = html.EscapeString(ln)
+	}
+	vv.ShowMarkup(lns, mus)
+}
+
+// ShowMarkup shows given lines, with markup, below the vulnerabilities
+func (vv *VulnView) ShowMarkup(lns, mus []string) {
+	vp := vv.Gide.VPort()
+	wupdt := vp.TopUpdateStart()
+	defer vp.TopUpdateEnd(wupdt)
+	tbuf := vv.TextView().Buf
+	tbuf.New(0)
+	tbuf.SetInactive(true)
+	tbuf.AppendTextMarkup([]byte(strings.Join(lns, "\n")), []byte(strings.Join(mus, "\n")), giv.EditSignal)
+}
+
+// ShowVuln shows the advisory of given vulnerability and its call paths,
+// from the entry point in the project to the vulnerable symbol, with links
+// to the calling source lines
+func (vv *VulnView) ShowVuln(ve *VulnEntry) {
+	var lns, mus []string
+	add := func(ln, mu string) {
+		lns = append(lns, ln)
+		mus = append(mus, mu)
+	}
+	hd := ve.ID
+	if ve.OSV != nil && len(ve.OSV.Aliases) > 0 {
+		hd += " (" + strings.Join(ve.OSV.Aliases, ", ") + ")"
+	}
+	add(hd+": "+ve.Summary, fmt.Sprintf(`<b><a href="%s">%s</a></b>: %s`, html.EscapeString(ve.URL()), html.EscapeString(hd), html.EscapeString(ve.Summary)))
+	fixed := ve.Fixed
+	if fixed == "" {
+		fixed = "not fixed yet"
+	}
+	mod := fmt.Sprintf("module: %v@%v -- fixed in: %v -- %v", ve.Module, ve.Found, fixed, ve.Level)
+	add(mod, html.EscapeString(mod))
+	if ve.OSV != nil && ve.OSV.Details != "" {
+		add("", "")
+		for _, dl := range strings.Split(strings.TrimSpace(ve.OSV.Details), "\n") {
+			add(dl, html.EscapeString(dl))
+		}
+	}
+	np := 0
+	for _, vf := range ve.Findings {
+		if vulnLevel(&vf) != 0 {
+			continue
+		}
+		np++
+		add("", "")
+		hd := fmt.Sprintf("call path %d:", np)
+		add(hd, "<b>"+hd+"</b>")
+		for i := len(vf.Trace) - 1; i >= 0; i-- {
+			fr := &vf.Trace[i]
+			ln := "  " + fr.Func()
+			mu := "  " + html.EscapeString(fr.Func())
+			if i == 0 {
+				ln += " (vulnerable)"
+				mu += " <i>(vulnerable)</i>"
+			}
+			if fr.Position != nil && fr.Position.Filename != "" {
+				fn := fr.Position.Filename
+				if !filepath.IsAbs(fn) {
+					fn = filepath.Join(vv.Dir, fn)
+				}
+				pos := fmt.Sprintf("%v:%d:%d", fr.Position.Filename, fr.Position.Line, fr.Position.Column)
+				ln += " at " + pos
+				mu += fmt.Sprintf(` at <a href="file:///%s#L%dC%d">%s</a>`, fn, fr.Position.Line, fr.Position.Column, html.EscapeString(pos))
+			}
+			add(ln, mu)
+		}
+	}
+	if np == 0 {
+		add("", "")
+		msg := "the vulnerable code is not called by the project"
+		add(msg, html.EscapeString(msg))
+	}
+	vv.ShowMarkup(lns, mus)
+}
+
+// Upgrade upgrades the module of given vulnerability to the version with
+// it fixed, and then checks again
+func (vv *VulnView) Upgrade(ve *VulnEntry) {
+	if ve.Fixed == "" || ve.Module == "" || ve.Module == "stdlib" || ve.Module == "toolchain" {
+		vv.Gide.SetStatus("no fixed version of the module to upgrade to: " + ve.Module)
+		return
+	}
+	if vv.Running {
+		vv.Gide.SetStatus("govulncheck is already running")
+		return
+	}
+	cmdstr := fmt.Sprintf("go get %v@%v", ve.Module, ve.Fixed)
+	args := []string{"get", ve.Module + "@" + ve.Fixed}
+	vv.ShowOutput(cmdstr)
+	go func() {
+		out, err := GoModCmd(vv.Dir, vv.Gide.ProjPrefs().CmdEnv(), args...)
+		if vv.This() == nil || vv.IsDeleted() || vv.IsDestroyed() {
+			return
+		}
+		if err != nil {
+			vv.ShowOutput(cmdstr + "\n" + err.Error() + "\n" + string(out))
+			return
+		}
+		vv.Check()
+	}()
+}
+
+// VulnViewProps are style properties for VulnView
+var VulnViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_NodeFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebPreviewReloadPath is the url path that pages served by WebPreview poll
+// to find out when to reload
+var WebPreviewReloadPath = "/__gide_reload"
+
+// WebPreviewPollTimeout is how long a reload poll waits for a change before
+// returning, after which the page polls again
+var WebPreviewPollTimeout = 30 * time.Second
+
+// WebPreviewScript is injected at the end of each html page served by
+// WebPreview -- it polls WebPreviewReloadPath with the last version seen,
+// and reloads the page when the version changes
+var WebPreviewScript = `<script>
+(function() {
+	var vers = "";
+	function poll() {
+		fetch("` + "%s" + `?v=" + vers).then(function(r) { return r.text(); }).then(function(v) {
+			if (vers != "" && v != vers) {
+				location.reload();
+				return;
+			}
+			vers = v;
+			poll();
+		}).catch(function() { setTimeout(poll, 1000); });
+	}
+	poll();
+})();
+</script>
+`
+
+// WebPreview is a local web server for previewing the files in a project
+// directory (e.g., html, css, js) in an external browser.  Html pages that
+// it serves automatically reload whenever Reload is called, which is done
+// when files are saved.  It only listens on the local loopback interface.
+type WebPreview struct {
+	Root    string        `desc:"root directory of files served"`
+	Addr    string        `desc:"address that the server is listening on, host:port"`
+	Version int           `desc:"version number, incremented on each Reload"`
+	Changed chan struct{} `json:"-" xml:"-" view:"-" desc:"closed and renewed on each Reload, to wake up pending polls"`
+	Server  *http.Server  `json:"-" xml:"-" view:"-" desc:"the http server"`
+	Mu      sync.Mutex    `json:"-" xml:"-" view:"-" desc:"mutex protecting Version and Changed"`
+}
+
+// Start starts serving given root directory on given port (0 = choose a
+// free one)
+func (wp *WebPreview) Start(root string, port int) error {
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return err
+	}
+	wp.Root = root
+	wp.Addr = ln.Addr().String()
+	wp.Changed = make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc(WebPreviewReloadPath, wp.ServeReload)
+	mux.HandleFunc("/", wp.ServeFile)
+	wp.Server = &http.Server{Handler: mux}
+	go wp.Server.Serve(ln)
+	return nil
+}
+
+// Stop stops the server
+func (wp *WebPreview) Stop() {
+	if wp.Server == nil {
+		return
+	}
+	wp.Server.Close()
+	wp.Server = nil
+}
+
+// IsRunning returns true if the server has been started and not stopped
+func (wp *WebPreview) IsRunning() bool {
+	return wp.Server != nil
+}
+
+// Reload causes all html pages currently open from the server to reload
+func (wp *WebPreview) Reload() {
+	wp.Mu.Lock()
+	defer wp.Mu.Unlock()
+	wp.Version++
+	close(wp.Changed)
+	wp.Changed = make(chan struct{})
+}
+
+// URL returns the url for given file, which must be within Root -- returns
+// the root url if file is not within Root
+func (wp *WebPreview) URL(fname string) string {
+	ur := "http://" + wp.Addr + "/"
+	rel, err := filepath.Rel(wp.Root, fname)
+	if err != nil || fname == "" || RelOutside(rel) {
+		return ur
+	}
+	return ur + filepath.ToSlash(rel)
+}
+
+// ServeReload responds with the current version, once it differs from the
+// version given in the v parameter, or after WebPreviewPollTimeout
+func (wp *WebPreview) ServeReload(w http.ResponseWriter, r *http.Request) {
+	wp.Mu.Lock()
+	vers := fmt.Sprintf("%d", wp.Version)
+	ch := wp.Changed
+	wp.Mu.Unlock()
+	if v := r.URL.Query().Get("v"); v == vers {
+		select {
+		case <-ch:
+		case <-r.Context().Done():
+			return
+		case <-time.After(WebPreviewPollTimeout):
+		}
+		wp.Mu.Lock()
+		vers = fmt.Sprintf("%d", wp.Version)
+		wp.Mu.Unlock()
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(vers))
+}
+
+// ServeFile serves files within Root, injecting WebPreviewScript into html
+// pages (including the index.html of directories)
+func (wp *WebPreview) ServeFile(w http.ResponseWriter, r *http.Request) {
+	fpath := filepath.Join(wp.Root, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+	if fi, err := os.Stat(fpath); err == nil && fi.IsDir() {
+		if _, err := os.Stat(filepath.Join(fpath, "index.html")); err == nil {
+			fpath = filepath.Join(fpath, "index.html")
+		}
+	}
+	ext := strings.ToLower(filepath.Ext(fpath))
+	if ext != ".html" && ext != ".htm" {
+		w.Header().Set("Cache-Control", "no-store")
+		http.ServeFile(w, r, fpath)
+		return
+	}
+	b, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	scr := []byte(fmt.Sprintf(WebPreviewScript, WebPreviewReloadPath))
+	if i := bytes.LastIndex(bytes.ToLower(b), []byte("</body>")); i >= 0 {
+		b = append(b[:i:i], append(scr, b[i:]...)...)
+	} else {
+		b = append(b, scr...)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(b)
+}
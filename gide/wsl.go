@@ -0,0 +1,139 @@
+// Copyright (c) 2018, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gide
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+// WSLCmd is the command of the Windows Subsystem for Linux
+var WSLCmd = "wsl.exe"
+
+// WSLParams are the params for running the commands of a project in the
+// Windows Subsystem for Linux, on Windows
+type WSLParams struct {
+	Run       bool   `desc:"run the commands of the project (build, test, run, and debugging) in WSL, with the paths in their args, output and in the debugger translated between the Windows and /mnt forms"`
+	Distro    string `width:"20" desc:"WSL distro the commands run in -- the default one if empty, see wsl.exe -l"`
+	DebugPort int    `desc:"port of the API server of the headless dlv in WSL, reached on localhost -- 2345 by default"`
+}
+
+// On returns true if the commands run in WSL: only on Windows
+func (wp *WSLParams) On() bool {
+	return wp.Run && runtime.GOOS == "windows"
+}
+
+// Port returns the port of the API server of the debugger
+func (wp *WSLParams) Port() int {
+	if wp.DebugPort <= 0 {
+		return 2345
+	}
+	return wp.DebugPort
+}
+
+// WSLDistros returns the names of the WSL distros, the default one first
+func WSLDistros() ([]string, error) {
+	if runtime.GOOS != "windows" {
+		return nil, errors.New("WSL is only available on Windows")
+	}
+	out, err := exec.Command(WSLCmd, "-l", "-q").Output()
+	if err != nil {
+		return nil, err
+	}
+	var dl []string
+	for _, ln := range strings.Split(wslOutString(out), "\n") {
+		if ln = strings.TrimSpace(ln); ln != "" {
+			dl = append(dl, ln)
+		}
+	}
+	return dl, nil
+}
+
+// wslOutString returns the output of wsl.exe, which is UTF-16LE, as a string
+func wslOutString(out []byte) string {
+	if len(out) < 2 || out[1] != 0 {
+		return string(out)
+	}
+	u := make([]uint16, len(out)/2)
+	for i := range u {
+		u[i] = uint16(out[2*i]) | uint16(out[2*i+1])<<8
+	}
+	return string(utf16.Decode(u))
+}
+
+// WSLPath returns the path in WSL of given Windows path: C:\dir\f.go is
+// /mnt/c/dir/f.go, and \\wsl$\distro\dir is /dir -- others are unchanged
+func WSLPath(wpath string) string {
+	sp := strings.ReplaceAll(wpath, `\`, "/")
+	if len(sp) >= 2 && sp[1] == ':' && isDriveLetter(sp[0]) && (len(sp) == 2 || sp[2] == '/') {
+		return "/mnt/" + strings.ToLower(sp[:1]) + sp[2:]
+	}
+	for _, pfx := range []string{"//wsl$/", "//wsl.localhost/"} {
+		if strings.HasPrefix(sp, pfx) {
+			rest := sp[len(pfx):]
+			if si := strings.Index(rest, "/"); si >= 0 {
+				return rest[si:]
+			}
+			return "/"
+		}
+	}
+	return wpath
+}
+
+func isDriveLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// HostPath returns the Windows path of given path in WSL: /mnt/c/dir is
+// C:\dir, and other absolute paths are under \\wsl$\distro, if the Distro
+// is set -- others are unchanged
+func (wp *WSLParams) HostPath(lpath string) string {
+	if strings.HasPrefix(lpath, "/mnt/") && len(lpath) >= 6 && isDriveLetter(lpath[5]) && (len(lpath) == 6 || lpath[6] == '/') {
+		return strings.ToUpper(lpath[5:6]) + ":" + strings.ReplaceAll(lpath[6:], "/", `\`)
+	}
+	if strings.HasPrefix(lpath, "/") && wp.Distro != "" {
+		return `\\wsl$\` + wp.Distro + strings.ReplaceAll(lpath, "/", `\`)
+	}
+	return lpath
+}
+
+// wslPathRe matches Windows absolute paths in args and command lines
+var wslPathRe = regexp.MustCompile(`\b[A-Za-z]:[\\/][^\s"'|;&<>]*`)
+
+// WSLPathsIn returns given arg or command line with the Windows absolute
+// paths in it, e.g., those of the ArgVars, translated to WSL
+func WSLPathsIn(s string) string {
+	return wslPathRe.ReplaceAllStringFunc(s, WSLPath)
+}
+
+// Command returns the command running given executable, with args (with
+// the paths translated), in WSL, in the path there of given dir, with
+// given environment variables set there
+func (wp *WSLParams) Command(dir string, env []string, name string, args ...string) *exec.Cmd {
+	var wargs []string
+	if wp.Distro != "" {
+		wargs = append(wargs, "-d", wp.Distro)
+	}
+	wargs = append(wargs, "--cd", WSLPath(dir), "--exec")
+	if len(env) > 0 {
+		wargs = append(wargs, "env")
+		for _, ev := range env {
+			wargs = append(wargs, WSLPathsIn(ev))
+		}
+	}
+	wargs = append(wargs, WSLPathsIn(name))
+	for _, a := range args {
+		wargs = append(wargs, WSLPathsIn(a))
+	}
+	cmd := exec.Command(WSLCmd, wargs...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	return cmd
+}
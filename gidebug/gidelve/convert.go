@@ -45,9 +45,9 @@ func (gd *GiDelve) cvtThread(ds *api.Thread) *gidebug.Thread {
 	th := &gidebug.Thread{}
 	th.ID = ds.ID
 	th.PC = ds.PC
-	th.File = giv.RelFilePath(ds.File, gd.rootPath)
+	th.File = giv.RelFilePath(gd.hostPath(ds.File), gd.rootPath)
 	th.Line = ds.Line
-	th.FPath = ds.File
+	th.FPath = gd.hostPath(ds.File)
 	if ds.Function != nil {
 		th.Func = ds.Function.Name_
 	}
@@ -74,9 +74,9 @@ func (gd *GiDelve) cvtTask(ds *api.Goroutine) *gidebug.Task {
 	gr := &gidebug.Task{}
 	gr.ID = ds.ID
 	gr.PC = ds.UserCurrentLoc.PC
-	gr.File = giv.RelFilePath(ds.UserCurrentLoc.File, gd.rootPath)
+	gr.File = giv.RelFilePath(gd.hostPath(ds.UserCurrentLoc.File), gd.rootPath)
 	gr.Line = ds.UserCurrentLoc.Line
-	gr.FPath = ds.UserCurrentLoc.File
+	gr.FPath = gd.hostPath(ds.UserCurrentLoc.File)
 	if ds.UserCurrentLoc.Function != nil {
 		gr.Func = ds.UserCurrentLoc.Function.Name_
 	}
@@ -104,9 +104,9 @@ func (gd *GiDelve) cvtLocation(ds *api.Location) *gidebug.Location {
 	}
 	lc := &gidebug.Location{}
 	lc.PC = ds.PC
-	lc.File = giv.RelFilePath(ds.File, gd.rootPath)
+	lc.File = giv.RelFilePath(gd.hostPath(ds.File), gd.rootPath)
 	lc.Line = ds.Line
-	lc.FPath = ds.File
+	lc.FPath = gd.hostPath(ds.File)
 	if ds.Function != nil {
 		lc.Func = ds.Function.Name_
 	}
@@ -121,8 +121,8 @@ func (gd *GiDelve) cvtBreak(ds *api.Breakpoint) *gidebug.Break {
 	bp.On = true // if we're converting, it is on..
 	bp.ID = ds.ID
 	bp.PC = ds.Addr
-	bp.File = giv.RelFilePath(ds.File, gd.rootPath)
-	bp.FPath = ds.File
+	bp.File = giv.RelFilePath(gd.hostPath(ds.File), gd.rootPath)
+	bp.FPath = gd.hostPath(ds.File)
 	bp.Line = ds.Line
 	bp.Func = ds.FunctionName
 	bp.Cond = ds.Cond
@@ -149,9 +149,9 @@ func (gd *GiDelve) cvtFrame(ds *api.Stackframe, taskID int) *gidebug.Frame {
 	fr := &gidebug.Frame{}
 	fr.ThreadID = taskID
 	fr.PC = ds.Location.PC
-	fr.File = giv.RelFilePath(ds.Location.File, gd.rootPath)
+	fr.File = giv.RelFilePath(gd.hostPath(ds.Location.File), gd.rootPath)
 	fr.Line = ds.Location.Line
-	fr.FPath = ds.Location.File
+	fr.FPath = gd.hostPath(ds.Location.File)
 	if ds.Location.Function != nil {
 		fr.Func = ds.Location.Function.Name_
 	}
@@ -189,8 +189,7 @@ func (gd *GiDelve) cvtVar(ds *api.Variable) *gidebug.Variable {
 	if ds == nil {
 		return nil
 	}
-	vr := &gidebug.Variable{}
-	vr.InitName(vr, ds.Name)
+	vr := gidebug.NewVariable(ds.Name)
 	vr.Addr = uintptr(ds.Addr)
 	vr.FullTypeStr = ds.RealType
 	vr.TypeStr = ShortType(ds.RealType)
@@ -334,7 +333,8 @@ func (gd *GiDelve) fixVar(vr *gidebug.Variable, ec *api.EvalScope, lc *api.LoadC
 				if vrkr.Nm == "" {
 					vrkr.SetName(vnm)
 				}
-				vr.DeleteChildAtIndex(0, true)
+				vr.DeleteChildAtIndex(0, false)
+				vrk.Release()
 				vr.AddChild(vrkr)
 			}
 		}
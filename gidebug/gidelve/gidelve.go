@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"net"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -91,22 +92,26 @@ func (gd *GiDelve) Start(path, rootPath string, outbuf *giv.TextBuf, pars *gideb
 	gd.rootPath = rootPath
 	gd.params = *pars
 	gd.statFunc = pars.StatFunc
+	var targs []string
 	switch pars.Mode {
 	case gidebug.Exec:
-		targs := []string{"debug", "--headless", "--api-version=2"}
-		targs = append(targs, gd.params.Args...)
-		gd.cmd = exec.Command("dlv", targs...)
+		targs = []string{"debug", "--headless", "--api-version=2"}
 	case gidebug.Test:
-		targs := []string{"test", "--headless", "--api-version=2"}
-		targs = append(targs, gd.params.Args...)
-		gd.cmd = exec.Command("dlv", targs...)
+		targs = []string{"test", "--headless", "--api-version=2"}
 	case gidebug.Attach:
 		// note: --log here creates huge amounts of messages and doesn't work..
-		targs := []string{"attach", fmt.Sprintf("%d", gd.params.PID), "--headless", "--api-version=2"}
-		targs = append(targs, gd.params.Args...)
+		targs = []string{"attach", fmt.Sprintf("%d", gd.params.PID), "--headless", "--api-version=2"}
+	}
+	if gd.params.Listen != "" {
+		targs = append(targs, "--listen="+gd.params.Listen)
+	}
+	targs = append(targs, gd.params.Args...)
+	if gd.params.Exec != nil {
+		gd.cmd = gd.params.Exec(filepath.Dir(path), targs)
+	} else {
 		gd.cmd = exec.Command("dlv", targs...)
+		gd.cmd.Dir = filepath.Dir(path)
 	}
-	gd.cmd.Dir = filepath.Dir(path)
 	stdout, err := gd.cmd.StdoutPipe()
 	if err == nil {
 		gd.cmd.Stderr = gd.cmd.Stdout
@@ -134,6 +139,9 @@ func (gd *GiDelve) monitorOutput(out []byte) []byte {
 	}
 	if flds[0] == "API" && flds[1] == "server" && flds[2] == "listening" && flds[3] == "at:" {
 		gd.conn = flds[4]
+		if h, p, err := net.SplitHostPort(gd.conn); err == nil && (h == "::" || h == "0.0.0.0") {
+			gd.conn = net.JoinHostPort("127.0.0.1", p) // all interfaces, e.g., in a container
+		}
 		gd.dlv = rpc2.NewClient(gd.conn)
 		gd.SetParams(&gd.params)
 		if gd.statFunc != nil {
@@ -155,6 +163,29 @@ func (gd *GiDelve) monitorOutput(out []byte) []byte {
 	return out
 }
 
+// hostPath returns the path in the project of given path of the debugger,
+// for a RemoteRoot
+func (gd *GiDelve) hostPath(fpath string) string {
+	rr := gd.params.RemoteRoot
+	if rr == "" || (fpath != rr && !strings.HasPrefix(fpath, rr+"/")) {
+		return fpath
+	}
+	return filepath.Join(gd.rootPath, filepath.FromSlash(strings.TrimPrefix(fpath, rr)))
+}
+
+// remotePath returns the path for the debugger of given path in the
+// project, for a RemoteRoot
+func (gd *GiDelve) remotePath(fpath string) string {
+	if gd.params.RemoteRoot == "" {
+		return fpath
+	}
+	rel, err := filepath.Rel(gd.rootPath, fpath)
+	if err != nil || !filepath.IsAbs(fpath) || strings.HasPrefix(rel, "..") {
+		return fpath
+	}
+	return filepath.ToSlash(filepath.Join(gd.params.RemoteRoot, rel))
+}
+
 // IsActive returns whether debugger is active and ready for commands
 func (gd *GiDelve) IsActive() bool {
 	return gd.cmd != nil && gd.dlv != nil
@@ -377,7 +408,7 @@ func (gd *GiDelve) SetBreak(fname string, line int) (*gidebug.Break, error) {
 		return nil, err
 	}
 	bp := &api.Breakpoint{}
-	bp.File = fname
+	bp.File = gd.remotePath(fname)
 	bp.Line = line
 	ds, err := gd.dlv.CreateBreakpoint(bp)
 	gd.LogErr(err)
@@ -422,7 +453,7 @@ func (gd *GiDelve) AmendBreak(id int, fname string, line int, cond string, trace
 	}
 	bp := &api.Breakpoint{}
 	bp.ID = id
-	bp.File = fname
+	bp.File = gd.remotePath(fname)
 	bp.Line = line
 	bp.Cond = cond
 	bp.Tracepoint = trace
@@ -526,7 +557,7 @@ func (gd *GiDelve) InitAllState(all *gidebug.AllState) error {
 	if err != nil {
 		return err
 	}
-	all.Vars = vr
+	all.SetVars(vr, gd.params.VarMemMax)
 
 	all.CurBreak = 0
 	cf := all.StackFrame(0)
@@ -561,7 +592,7 @@ func (gd *GiDelve) UpdateAllState(all *gidebug.AllState, threadID int, frame int
 		if err != nil {
 			return err
 		}
-		all.Vars = vr
+		all.SetVars(vr, gd.params.VarMemMax)
 	}
 	return nil
 }
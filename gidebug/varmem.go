@@ -0,0 +1,164 @@
+// Copyright (c) 2020, The Gide Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gidebug
+
+import (
+	"sort"
+	"sync"
+)
+
+// VarMemMaxDefault is the default maximum number of bytes of variable data
+// kept for the current variables, if not set in the Params
+var VarMemMaxDefault = 32 * 1024 * 1024
+
+// varNodeSize is the approximate size of a Variable node itself
+const varNodeSize = 512
+
+// varPool holds the released Variable nodes, for reuse
+var varPool = sync.Pool{
+	New: func() interface{} { return &Variable{} },
+}
+
+// NewVariable returns a new Variable with given name, reusing a released one
+// if available -- debuggers should use this, so that the variables listed
+// at each step do not allocate new trees every time.
+func NewVariable(name string) *Variable {
+	vr := varPool.Get().(*Variable)
+	vr.InitName(vr, name)
+	return vr
+}
+
+// Release releases the variable and all of its data for reuse by
+// NewVariable -- it must not be used after this.
+func (vr *Variable) Release() {
+	vr.Evict()
+	*vr = Variable{}
+	varPool.Put(vr)
+}
+
+// ReleaseVars releases the given variables for reuse
+func ReleaseVars(vrs []*Variable) {
+	for _, vr := range vrs {
+		if vr != nil {
+			vr.Release()
+		}
+	}
+}
+
+// Evict releases the data below the Value of the variable: its children,
+// and its list and map contents, keeping the summary Value.
+func (vr *Variable) Evict() {
+	if vr.Kids != nil || vr.MapVar != nil || vr.List != nil || vr.Map != nil {
+		vr.Evicted = true
+	}
+	for _, k := range vr.Kids {
+		if kv, ok := k.(*Variable); ok {
+			kv.Release()
+		}
+	}
+	vr.Kids = nil
+	for _, mv := range vr.MapVar {
+		if mv != nil {
+			mv.Release()
+		}
+	}
+	vr.MapVar = nil
+	vr.List = nil
+	vr.Map = nil
+}
+
+// MemSize returns the approximate number of bytes used by the variable and
+// all of its data
+func (vr *Variable) MemSize() int {
+	sz := varNodeSize + len(vr.Nm) + len(vr.Value) + len(vr.ElValue) + len(vr.TypeStr) + len(vr.FullTypeStr) + len(vr.Loc.FPath)
+	for _, el := range vr.List {
+		sz += 16 + len(el)
+	}
+	for k, v := range vr.Map {
+		sz += 32 + len(k) + len(v)
+	}
+	for k, mv := range vr.MapVar {
+		sz += 16 + len(k)
+		if mv != nil {
+			sz += mv.MemSize()
+		}
+	}
+	for _, k := range vr.Kids {
+		if kv, ok := k.(*Variable); ok {
+			sz += kv.MemSize()
+		}
+	}
+	return sz
+}
+
+// TrimVars evicts the data of the given variables, the largest ones first,
+// until they use no more than max bytes (0 = VarMemMaxDefault).  Returns
+// the number of variables evicted.
+func TrimVars(vrs []*Variable, max int) int {
+	if max <= 0 {
+		max = VarMemMaxDefault
+	}
+	szs := make([]int, len(vrs))
+	tot := 0
+	for i, vr := range vrs {
+		if vr != nil {
+			szs[i] = vr.MemSize()
+			tot += szs[i]
+		}
+	}
+	if tot <= max {
+		return 0
+	}
+	idx := make([]int, len(vrs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(i, j int) bool {
+		return szs[idx[i]] > szs[idx[j]]
+	})
+	n := 0
+	for _, i := range idx {
+		if tot <= max {
+			break
+		}
+		vr := vrs[i]
+		if vr == nil {
+			continue
+		}
+		vr.Evict()
+		tot -= szs[i] - vr.MemSize()
+		n++
+	}
+	return n
+}
+
+// SetVars sets the current local variables, releasing the previous ones for
+// reuse, and trims the data of all the variables to given max bytes
+// (0 = VarMemMaxDefault)
+func (as *AllState) SetVars(vrs []*Variable, max int) {
+	old := as.Vars
+	as.Vars = vrs
+	ReleaseVars(old)
+	as.TrimVars(max)
+}
+
+// SetGlobalVars sets the global variables, releasing the previous ones for
+// reuse, and trims the data of all the variables to given max bytes
+// (0 = VarMemMaxDefault)
+func (as *AllState) SetGlobalVars(vrs []*Variable, max int) {
+	old := as.GlobalVars
+	as.GlobalVars = vrs
+	ReleaseVars(old)
+	as.TrimVars(max)
+}
+
+// TrimVars trims the data of the local and global variables together, to
+// given max bytes (0 = VarMemMaxDefault), returning the number evicted
+func (as *AllState) TrimVars(max int) int {
+	vrs := make([]*Variable, 0, len(as.Vars)+len(as.GlobalVars))
+	vrs = append(vrs, as.Vars...)
+	vrs = append(vrs, as.GlobalVars...)
+	return TrimVars(vrs, max)
+}
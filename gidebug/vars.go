@@ -6,6 +6,7 @@ package gidebug
 
 import (
 	"fmt"
+	"os/exec"
 	"sort"
 	"strings"
 
@@ -34,6 +35,7 @@ type Variable struct {
 	Map         map[string]string    `tableview:"-" desc:"if kind is a map, and elements are primitive types, this is the contents"`
 	MapVar      map[string]*Variable `tableview:"-" desc:"if kind is a map, and elements are not primitive types, this is the contents"`
 	Dbg         GiDebug              `view:"-" desc:"our debugger -- for getting further variable data"`
+	Evicted     bool                 `view:"-" tableview:"-" desc:"if true, the data below the Value of this variable has been evicted to save memory -- it is retrieved again when the variable is shown"`
 }
 
 var KiT_Variable = kit.Types.AddType(&Variable{}, nil)
@@ -54,6 +56,7 @@ func (vr *Variable) CopyFieldsFrom(frm interface{}) {
 	vr.Map = fr.Map
 	vr.MapVar = fr.MapVar
 	vr.Dbg = fr.Dbg
+	vr.Evicted = fr.Evicted
 }
 
 func init() {
@@ -222,12 +225,16 @@ type VarParams struct {
 
 // Params are overall debugger parameters
 type Params struct {
-	Mode     Modes             `xml:"-" json:"-" view:"-" desc:"mode for running the debugger"`
-	PID      uint64            `xml:"-" json:"-" view:"-" desc:"process id number to attach to, for Attach mode"`
-	Args     []string          `desc:"optional extra args to pass to the debugger.  Use double-dash -- and then add args to pass args to the executable (double-dash is by itself as a separate arg first)"`
-	StatFunc func(stat Status) `xml:"-" json:"-" view:"-" desc:"status function for debugger updating status"`
-	VarList  VarParams         `desc:"parameters for level of detail on overall list of variables"`
-	GetVar   VarParams         `desc:"parameters for level of detail retrieving a specific variable"`
+	Mode       Modes                                     `xml:"-" json:"-" view:"-" desc:"mode for running the debugger"`
+	PID        uint64                                    `xml:"-" json:"-" view:"-" desc:"process id number to attach to, for Attach mode"`
+	Args       []string                                  `desc:"optional extra args to pass to the debugger.  Use double-dash -- and then add args to pass args to the executable (double-dash is by itself as a separate arg first)"`
+	StatFunc   func(stat Status)                         `xml:"-" json:"-" view:"-" desc:"status function for debugger updating status"`
+	Listen     string                                    `xml:"-" json:"-" view:"-" desc:"address the debugger listens at, if not a local port of its choosing, e.g., 0.0.0.0:2345 in a container"`
+	RemoteRoot string                                    `xml:"-" json:"-" view:"-" desc:"root path of the project where the debugger runs, e.g., in a container, if not that of the project -- file paths are translated between them"`
+	Exec       func(dir string, args []string) *exec.Cmd `xml:"-" json:"-" view:"-" desc:"function returning the command running the debugger with given args, in given dir, e.g., in a container, if not run directly"`
+	VarList    VarParams                                 `desc:"parameters for level of detail on overall list of variables"`
+	GetVar     VarParams                                 `desc:"parameters for level of detail retrieving a specific variable"`
+	VarMemMax  int                                       `desc:"maximum number of bytes (approximately) of variable data kept for the current local and global variables -- beyond it, the data below their values is evicted, for the largest ones first (0 = VarMemMaxDefault)"`
 }
 
 // DefaultParams are default parameter values
@@ -246,4 +253,5 @@ var DefaultParams = Params{
 		MaxArrayValues:  1024,
 		MaxStructFields: -1,
 	},
+	VarMemMax: VarMemMaxDefault,
 }
@@ -13,6 +13,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"html"
+	"io/ioutil"
 	"log"
 	"net/url"
 	"os"
@@ -47,11 +49,13 @@ import (
 	"github.com/goki/vci"
 )
 
-// NTextViews is the number of text views to create -- to keep things simple
-// and consistent (e.g., splitter settings always have the same number of
-// values), we fix this degree of freedom, and have flexibility in the
-// splitter settings for what to actually show.
-const NTextViews = 2
+// NTextPanels is the number of main splitview panels holding text views --
+// to keep things simple and consistent (e.g., splitter settings always have
+// the same number of values), we fix this degree of freedom, and have
+// flexibility in the splitter settings for what to actually show.  Each of
+// these panels can then be split into a grid of editor panes, each with its
+// own text view.
+const NTextPanels = 2
 
 // These are then the fixed indices of the different elements in the splitview
 const (
@@ -75,14 +79,43 @@ type GideView struct {
 	Files             giv.FileTree            `desc:"all the files in the project directory and subdirectories"`
 	FilesView         *gide.FileTreeView      `json:"-" desc:"the files tree view"`
 	ActiveTextViewIdx int                     `json:"-" desc:"index of the currently-active textview -- new files will be viewed in other views if available"`
+	NPanes            int                     `json:"-" desc:"counter used for unique naming of editor panes"`
 	OpenNodes         gide.OpenNodes          `json:"-" desc:"list of open nodes, most recent first"`
+	NavHist           gide.NavHistory         `json:"-" desc:"navigation history of cursor locations across files, for moving back and forward"`
+	WebServer         gide.WebPreview         `json:"-" view:"-" desc:"local web server for previewing html pages in the project, which reload when files are saved"`
+	LiveRun           gide.LiveRun            `json:"-" view:"-" desc:"live-reload run of the RunExec, rebuilt and restarted when the Go files change"`
+	Plugins           gide.Plugins            `json:"-" view:"-" desc:"the plugins running for the project"`
+	SymIdx            gide.SymIndex           `json:"-" view:"-" desc:"index of the symbols and words in all the project files, built in the background"`
+	FilesLoad         gide.FilesLoad          `json:"-" view:"-" desc:"background loading of the file tree when the project is opened"`
+	LazyTabs          gide.LazyTabs           `json:"-" view:"-" desc:"tabs restored from the session whose contents are only made when first selected"`
+	FocusMode         gide.FocusMode          `json:"-" view:"-" desc:"state of the distraction-free mode, showing just the active editor pane"`
+	FileWatch         gide.FileWatcher        `json:"-" view:"-" desc:"watcher of the project directories, updating the file tree for changes made by external tools"`
+	Ignore            gide.FileIgnore         `json:"-" view:"-" desc:"files and directories ignored by the .gitignore and .gideignore files of the project"`
+	Dict              gide.SpellDict          `json:"-" view:"-" desc:"dictionary of the words accepted by the spell checking in the project"`
+	VcsStat           gide.VcsStatus          `json:"-" view:"-" desc:"version control status of the project files, updated in the background"`
+	Branch            string                  `json:"-" view:"-" desc:"current version control branch of the project, shown in the statusbar"`
+	Trash             gide.FileTrash          `json:"-" view:"-" desc:"trash that deleted files are moved to, for undoing deletions"`
+	TodoList          gide.TodoList           `json:"-" view:"-" desc:"TODO comments in the project files, scanned when the TODOs panel is first shown"`
+	ProbList          gide.ProblemList        `json:"-" view:"-" desc:"problems (errors, warnings) found by the commands run, shown in the Problems panel and marked in the files"`
+	Notifs            gide.NotifyList         `json:"-" view:"-" desc:"notifications of the events of the project (commands finished, lint results, files changed on disk..), shown in the Notifications panel"`
+	FileModsNotified  map[string]time.Time    `json:"-" view:"-" desc:"modification times on disk of the open files notified as changed, so as to notify each change once"`
+	ActLog            gide.ActionLog          `json:"-" view:"-" desc:"log of the operations done in the project (files saved, commands run, version control actions, refactorings), shown in the Action Log panel"`
+	Artifacts         gide.ArtifactList       `json:"-" view:"-" desc:"executables and other files made by the build commands run, shown in the Artifacts panel"`
+	Cover             gide.CoverProfile       `json:"-" view:"-" desc:"coverage of the last run of the tests with coverage, shown in the Coverage panel and marked in the files"`
+	LintRun           int                     `json:"-" view:"-" desc:"number of the last golangci-lint run, whose findings replace those of any earlier run still going"`
+	BuildSaveTimer    *time.Timer             `json:"-" view:"-" desc:"timer of the build on save, restarted by each save so a burst of saves builds once"`
+	BuildSaveBusy     bool                    `json:"-" view:"-" desc:"true while the build on save is running"`
+	BuildSaveAgain    bool                    `json:"-" view:"-" desc:"set when files are saved while the build on save is running, so it is run again when done"`
 	CmdBufs           map[string]*giv.TextBuf `json:"-" desc:"the command buffers for commands run in this project"`
 	CmdHistory        gide.CmdNames           `json:"-" desc:"history of commands executed in this session"`
 	RunningCmds       gide.CmdRuns            `json:"-" xml:"-" desc:"currently running commands in this project"`
+	BgJobs            gide.JobManager         `json:"-" xml:"-" desc:"background jobs of this project: indexing, linting, version control status, searches"`
 	ArgVals           gide.ArgVarVals         `json:"-" xml:"-" desc:"current arg var vals"`
 	Prefs             gide.ProjPrefs          `desc:"preferences for this project -- this is what is saved in a .gide project file"`
 	CurDbg            *gide.DebugView         `desc:"current debug view"`
 	KeySeq1           key.Chord               `desc:"first key in sequence if needs2 key pressed"`
+	MacroRecording    bool                    `json:"-" xml:"-" desc:"true if currently recording a keyboard macro"`
+	Macro             gide.Macro              `json:"-" xml:"-" desc:"last recorded keyboard macro"`
 	UpdtMu            sync.Mutex              `desc:"mutex for protecting overall updates to GideView"`
 }
 
@@ -109,6 +142,19 @@ func (ge *GideView) FileTree() *giv.FileTree {
 	return &ge.Files
 }
 
+func (ge *GideView) FileIgnore() *gide.FileIgnore {
+	return &ge.Ignore
+}
+
+func (ge *GideView) ProjSpellDict() *gide.SpellDict {
+	return &ge.Dict
+}
+
+func (ge *GideView) FileTrash() *gide.FileTrash {
+	ge.Trash.Root = string(ge.ProjRoot)
+	return &ge.Trash
+}
+
 func (ge *GideView) LastSaveTime() time.Time {
 	return ge.LastSaveTStamp
 }
@@ -120,10 +166,40 @@ func (ge *GideView) VersCtrl() giv.VersCtrlName {
 	return vc
 }
 
+// FileVersCtrl returns the version control system of the repository that
+// given file or directory is in, detected by walking up from it, else the
+// project one (VersCtrl)
+func (ge *GideView) FileVersCtrl(fpath string) giv.VersCtrlName {
+	if fpath == "" {
+		return ge.VersCtrl()
+	}
+	dir := fpath
+	if fi, err := os.Stat(fpath); err != nil || !fi.IsDir() {
+		dir = filepath.Dir(fpath)
+	}
+	if vc := gide.DetectVersCtrl(dir); vc != "" {
+		return vc
+	}
+	return ge.VersCtrl()
+}
+
+// ActiveVcsDir returns the directory of the active file, for finding the
+// repository it is in, or the project root if no file is open
+func (ge *GideView) ActiveVcsDir() string {
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil && tv.Buf.Filename != "" {
+		return filepath.Dir(string(tv.Buf.Filename))
+	}
+	return string(ge.ProjRoot)
+}
+
 func (ge *GideView) CmdRuns() *gide.CmdRuns {
 	return &ge.RunningCmds
 }
 
+func (ge *GideView) Jobs() *gide.JobManager {
+	return &ge.BgJobs
+}
+
 func (ge *GideView) ArgVarVals() *gide.ArgVarVals {
 	return &ge.ArgVals
 }
@@ -143,6 +219,27 @@ func (ge *GideView) UpdateFiles() {
 	}
 }
 
+// LoadFiles loads the file tree of the project in the background (see
+// gide.FilesLoad), so the window is usable while it loads, calling given
+// function once it is loaded, and then starting the indexing of the files,
+// watching them, the plugins and the version control status
+func (ge *GideView) LoadFiles(loaded func()) {
+	ge.FilesLoad.Start(&ge.Files, string(ge.ProjRoot), ge.SetStatus, func() {
+		if ge.FilesView != nil {
+			ge.FilesView.ReSync()
+		}
+		if loaded != nil {
+			loaded()
+		}
+		ge.UpdateSymIndex()
+		ge.WatchFiles()
+		ge.Plugins.Start(ge)
+		ge.UpdateVcsStatus()
+		gide.StartupDone("project loaded")
+		go gide.LoadSpell(func(fn func()) { RunOnEventLoop(ge, fn) })
+	})
+}
+
 func (ge *GideView) IsEmpty() bool {
 	return ge.ProjRoot == ""
 }
@@ -150,8 +247,8 @@ func (ge *GideView) IsEmpty() bool {
 // OpenRecent opens a recently-used file
 func (ge *GideView) OpenRecent(filename gi.FileName) {
 	if string(filename) == gide.GideViewResetRecents {
-		gide.SavedPaths = nil
-		gi.StringsAddExtras((*[]string)(&gide.SavedPaths), gide.SavedPathsExtras)
+		gide.RecentProjects.Reset()
+		gide.SaveRecentProjs()
 	} else if string(filename) == gide.GideViewEditRecents {
 		ge.EditRecents()
 	} else {
@@ -164,22 +261,74 @@ func (ge *GideView) OpenRecent(filename gi.FileName) {
 	}
 }
 
-// RecentsEdit opens a dialog editor for deleting from the recents project list
+// RecentsEdit opens a dialog editor for deleting from the recents project
+// list, and pinning projects
 func (ge *GideView) EditRecents() {
-	tmp := make([]string, len(gide.SavedPaths))
-	copy(tmp, gide.SavedPaths)
-	gi.StringsRemoveExtras((*[]string)(&tmp), gide.SavedPathsExtras)
-	opts := giv.DlgOpts{Title: "Recent Project Paths", Prompt: "Delete paths you no longer use", Ok: true, Cancel: true, NoAdd: true}
-	giv.SliceViewDialog(ge.Viewport, &tmp, opts,
+	tmp := make(gide.RecentProjs, len(gide.RecentProjects))
+	for i, rp := range gide.RecentProjects {
+		crp := *rp
+		tmp[i] = &crp
+	}
+	opts := giv.DlgOpts{Title: "Recent Projects", Prompt: "Delete projects you no longer use, and pin those you want to keep at the top", Ok: true, Cancel: true, NoAdd: true}
+	giv.TableViewDialog(ge.Viewport, &tmp, opts,
 		nil, ge, func(recv, send ki.Ki, sig int64, data interface{}) {
 			if sig == int64(gi.DialogAccepted) {
-				gide.SavedPaths = nil
-				gide.SavedPaths = append(gide.SavedPaths, tmp...)
-				gi.StringsAddExtras((*[]string)(&gide.SavedPaths), gide.SavedPathsExtras)
+				gide.RecentProjects = tmp
+				gide.RecentProjects.Sort()
+				gide.SaveRecentProjs()
+			}
+		})
+}
+
+// RecentProjs shows the recently-opened projects, with when they were last
+// opened, and opens the one selected -- shown at startup when no project is
+// given (see Preferences.StartupRecents)
+func (ge *GideView) RecentProjs() {
+	if len(gide.RecentProjects) == 0 {
+		ge.SetStatus("no recent projects")
+		return
+	}
+	desc := "Select a project to open -- pinned projects are listed first (use Edit Recents... to pin or delete)"
+	giv.TableViewSelectDialog(ge.Viewport, &gide.RecentProjects, giv.DlgOpts{Title: "Recent Projects", Prompt: desc}, 0, nil,
+		ge, func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			ddlg := send.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			si := giv.TableViewSelectDialogValue(ddlg)
+			if si >= 0 && si < len(gide.RecentProjects) {
+				gee := recv.Embed(KiT_GideView).(*GideView)
+				gee.OpenRecent(gi.FileName(gide.RecentProjects[si].Path))
 			}
 		})
 }
 
+// RecentProjPath returns the path of the current project in the recent
+// projects list: its project file if listed, otherwise its root directory
+func (ge *GideView) RecentProjPath() string {
+	if pf := string(ge.Prefs.ProjFilename); pf != "" && gide.RecentProjects.Find(pf) >= 0 {
+		return pf
+	}
+	return string(ge.ProjRoot)
+}
+
+// TogglePinProj pins or unpins the current project in the recent projects
+// list -- pinned projects are listed first and kept when the list is reset
+func (ge *GideView) TogglePinProj() {
+	pth := ge.RecentProjPath()
+	if ge.IsEmpty() || gide.RecentProjects.Find(pth) < 0 {
+		return
+	}
+	pin := !gide.RecentProjects.IsPinned(pth)
+	gide.RecentProjects.Pin(pth, pin)
+	gide.SaveRecentProjs()
+	if pin {
+		ge.SetStatus("pinned project: " + pth)
+	} else {
+		ge.SetStatus("unpinned project: " + pth)
+	}
+}
+
 // OpenFile opens file in an open project if it has the same path as the file
 // or in a new window.
 func (ge *GideView) OpenFile(fnm string) {
@@ -222,25 +371,31 @@ func (ge *GideView) OpenPath(path gi.FileName) (*gi.Window, *GideView) {
 	root, pnm, fnm, ok := ProjPathParse(string(path))
 	if ok {
 		os.Chdir(root)
-		gide.SavedPaths.AddPath(root, gi.Prefs.Params.SavedPathsMax)
-		gide.SavePaths()
+		gide.AddRecentProj(root)
 		ge.ProjRoot = gi.FileName(root)
 		ge.SetName(pnm)
 		ge.Prefs.ProjFilename = gi.FileName(filepath.Join(root, pnm+".gide"))
 		ge.ProjFilename = ge.Prefs.ProjFilename
 		ge.Prefs.ProjRoot = ge.ProjRoot
+		ge.Ignore.Open(root)
+		ge.Ignore.SetFilePrefs(&ge.Prefs.Files)
+		ge.OpenSpellDict()
+		ge.OpenActionLog()
 		ge.Config()
-		ge.GuessMainLang()
-		ge.LangDefaults()
 		win := ge.ParentWindow()
 		if win != nil {
 			winm := "gide-" + pnm
 			win.SetName(winm)
 			win.SetTitle(winm + ": " + root)
 		}
-		if fnm != "" {
-			ge.NextViewFile(gi.FileName(fnm))
-		}
+		ge.LoadFiles(func() {
+			ge.GuessMainLang()
+			ge.LangDefaults()
+			ge.GoModDefaults()
+			if fnm != "" {
+				ge.NextViewFile(gi.FileName(fnm))
+			}
+		})
 	}
 	return ge.ParentWindow(), ge
 }
@@ -257,10 +412,12 @@ func (ge *GideView) OpenProj(filename gi.FileName) (*gi.Window, *GideView) {
 	_, pnm, _, ok := ProjPathParse(string(ge.Prefs.ProjRoot))
 	if ok {
 		os.Chdir(string(ge.Prefs.ProjRoot))
-		gide.SavedPaths.AddPath(string(filename), gi.Prefs.Params.SavedPathsMax)
-		gide.SavePaths()
+		gide.AddRecentProj(string(filename))
 		ge.SetName(pnm)
 		ge.ApplyPrefs()
+		ge.Ignore.Open(string(ge.ProjRoot))
+		ge.OpenSpellDict()
+		ge.OpenActionLog()
 		ge.Config()
 		win := ge.ParentWindow()
 		if win != nil {
@@ -268,6 +425,7 @@ func (ge *GideView) OpenProj(filename gi.FileName) (*gi.Window, *GideView) {
 			win.SetName(winm)
 			win.SetTitle(winm + ": " + string(ge.Prefs.ProjRoot))
 		}
+		ge.LoadFiles(ge.RestoreSession)
 	}
 	return ge.ParentWindow(), ge
 }
@@ -291,14 +449,19 @@ func (ge *GideView) NewProj(path gi.FileName, folder string, mainLang filecat.Su
 	return win, nge
 }
 
-// NewFile creates a new file in the project
+// NewFile creates a new file in the project, with the text of its template
+// (see EditFileTemplate)
 func (ge *GideView) NewFile(filename string, addToVcs bool) {
 	np := filepath.Join(string(ge.ProjRoot), filename)
-	_, err := os.Create(np)
+	f, err := os.Create(np)
 	if err != nil {
 		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Couldn't Make File", Prompt: fmt.Sprintf("Could not make new file at: %v, err: %v", np, err)}, gi.AddOk, gi.NoCancel, nil, nil)
 		return
 	}
+	f.Close()
+	if err := gide.ApplyFileTemplate(ge, np); err != nil {
+		ge.SetStatus("could not write the template of the new file: " + err.Error())
+	}
 	ge.Files.UpdateNewFile(np)
 	if addToVcs {
 		nfn, ok := ge.Files.FindFile(np)
@@ -308,6 +471,40 @@ func (ge *GideView) NewFile(filename string, addToVcs bool) {
 	}
 }
 
+// EditFileTemplate opens in the editor the template of the new files of
+// given name (go for the .go files, _test.go, Makefile..) -- or HEADER for
+// the header of {Header} -- of the project, or of the user for all the
+// projects, creating it from the standard template if needed
+func (ge *GideView) EditFileTemplate(name string, project bool) {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "*")
+	if name == "" {
+		return
+	}
+	dir := gide.UserFileTemplatesDir()
+	if project {
+		dir = gide.FileTemplatesDir(string(ge.ProjRoot), ge.Prefs.Templates)
+	}
+	fnm := name
+	if name != gide.FileTemplateHeader {
+		fnm = strings.TrimSuffix(name, gide.FileTemplateExt) + gide.FileTemplateExt
+	}
+	fpath := filepath.Join(dir, fnm)
+	if _, err := os.Stat(fpath); os.IsNotExist(err) {
+		txt, _ := gide.StdFileTemplate(strings.TrimSuffix(fnm, gide.FileTemplateExt))
+		if err := os.MkdirAll(dir, 0755); err == nil {
+			err = ioutil.WriteFile(fpath, []byte(txt), 0644)
+		}
+		if err != nil {
+			ge.SetStatus("could not create the template: " + err.Error())
+			return
+		}
+		if project {
+			ge.Files.UpdateNewFile(fpath)
+		}
+	}
+	ge.NextViewFile(gi.FileName(fpath))
+}
+
 // SaveProj saves project file containing custom project settings, in a
 // standard JSON-formatted file
 func (ge *GideView) SaveProj() {
@@ -338,8 +535,7 @@ func (ge *GideView) SaveProjIfExists(saveAllFiles bool) bool {
 // returns true if the user was prompted, false otherwise
 func (ge *GideView) SaveProjAs(filename gi.FileName, saveAllFiles bool) bool {
 	spell.SaveIfLearn()
-	gide.SavedPaths.AddPath(string(filename), gi.Prefs.Params.SavedPathsMax)
-	gide.SavePaths()
+	gide.AddRecentProj(string(filename))
 	// ge.Files.UpdateNewFile(string(filename))
 	ge.Prefs.ProjFilename = filename
 	ge.ProjFilename = ge.Prefs.ProjFilename
@@ -462,21 +658,88 @@ func (ge *GideView) LangDefaults() {
 		repo, _ := ge.Files.FirstVCS()
 		if repo != nil {
 			ge.Prefs.VersCtrl = giv.VersCtrlName(repo.Vcs())
+		} else {
+			ge.Prefs.VersCtrl = gide.DetectVersCtrl(string(ge.Prefs.ProjRoot))
+		}
+	}
+}
+
+// GoModDefaults sets the project prefs for the Go module that the project
+// is in, if any: the main language is Go, built with Build Go Proj, and the
+// BuildDir and RunExec are those of the default main package of the module
+// (see gide.DetectGoModule), with a build configuration for each main
+// package -- returns false if not in a module
+func (ge *GideView) GoModDefaults() bool {
+	mi, ok := gide.DetectGoModule(string(ge.Prefs.ProjRoot))
+	if !ok {
+		return false
+	}
+	ge.Prefs.MainLang = filecat.Go
+	ge.Prefs.BuildCmds = gide.CmdNames{"Build Go Proj"}
+	ge.Prefs.BuildConfigs = nil
+	for _, mp := range mi.MainPkgs {
+		nm, err := filepath.Rel(mi.Root, mp)
+		if err != nil || nm == "." {
+			nm = filepath.Base(mp)
 		}
+		ge.Prefs.BuildConfigs = append(ge.Prefs.BuildConfigs, &gide.BuildConfig{Name: filepath.ToSlash(nm), MainPkg: gi.FileName(mp)})
+	}
+	if mp := mi.MainPkg(); mp != "" {
+		ge.Prefs.BuildDir = gi.FileName(mp)
+		ge.Prefs.BuildTarg = gi.FileName(mp)
+		ge.Prefs.RunExec = gi.FileName(mi.GoExecPath(mp))
+		ge.Prefs.BuildConfig = ge.Prefs.BuildConfigs[0].Name
 	}
+	return true
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
 //   TextViews
 
-// ConfigTextBuf configures the text buf according to prefs
+// ConfigTextBuf configures the text buf according to prefs -- the markup
+// and the line endings of the buffer are only set up the first time, and
+// then updated when it is reopened (on TextBufNew)
 func (ge *GideView) ConfigTextBuf(tb *giv.TextBuf) {
-	tb.SetHiStyle(gi.Prefs.Colors.HiStyle)
+	tb.SetHiStyle(ge.Prefs.ProjHiStyle())
 	tb.Opts.EditorPrefs = ge.Prefs.Editor
-	tb.ConfigSupported()
+	gide.ConfigSupported(tb)
 	if tb.Complete != nil {
 		tb.Complete.LookupFunc = ge.LookupFun
 	}
+	if cfg, _ := tb.Prop("gide-configured").(bool); cfg {
+		return
+	}
+	tb.SetProp("gide-configured", true)
+	gide.ConfigMarkup(tb)
+	gide.DetectLineEnds(tb, ge.Prefs.LineEnds)
+	tb.TextBufSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_GideView).(*GideView)
+		tbb := send.Embed(giv.KiT_TextBuf).(*giv.TextBuf)
+		switch sig {
+		case int64(giv.TextBufDone):
+			gide.SaveLineEnds(tbb)
+		case int64(giv.TextBufClosed):
+			gee.FileClosedTabs(tbb.Filename)
+		case int64(giv.TextBufNew):
+			gide.DetectLineEnds(tbb, gee.Prefs.LineEnds)
+			gide.ClearDocStruct(tbb)
+			gee.MarkFileProblems(tbb)
+			gee.MarkFileCoverage(tbb)
+			gide.MarkGenerate(tbb)
+			gide.MarkTests(tbb)
+		case int64(giv.TextBufInsert), int64(giv.TextBufDelete):
+			gide.ClearDocStruct(tbb)
+			if tbe, ok := data.(*textbuf.Edit); ok && tbe != nil {
+				gide.UpdateGenerateMarks(tbb, tbe)
+				gide.UpdateTestMarks(tbb, tbe)
+				pos := tbe.Reg.End
+				if tbe.Delete {
+					pos = tbe.Reg.Start
+				}
+				gee.NavHist.SetLastEdit(gide.NavPos{Filename: tbb.Filename, Pos: pos})
+			}
+		}
+	})
 
 	// these are now set in std textbuf..
 	// tb.SetSpellCorrect(tb, giv.SpellCorrectEdit)                    // always set -- option can override
@@ -489,10 +752,9 @@ func (ge *GideView) ActiveTextView() *gide.TextView {
 	return ge.TextViewByIndex(ge.ActiveTextViewIdx)
 }
 
-// TextViewIndex finds index of given textview (0 or 1)
+// TextViewIndex finds index of given textview among all the editor panes
 func (ge *GideView) TextViewIndex(av *gide.TextView) int {
-	for i := 0; i < NTextViews; i++ {
-		tv := ge.TextViewByIndex(i)
+	for i, tv := range ge.TextViews() {
 		if tv.This() == av.This() {
 			return i
 		}
@@ -507,9 +769,8 @@ func (ge *GideView) TextViewForFileNode(fn *giv.FileNode) (*gide.TextView, int,
 		return nil, -1, false
 	}
 	ge.ConfigTextBuf(fn.Buf)
-	for i := 0; i < NTextViews; i++ {
-		tv := ge.TextViewByIndex(i)
-		if tv != nil && tv.Buf != nil && tv.Buf.This() == fn.Buf.This() && ge.PanelIsOpen(i+TextView1Idx) {
+	for i, tv := range ge.TextViews() {
+		if tv.Buf != nil && tv.Buf.This() == fn.Buf.This() && ge.TextViewIsOpen(i) {
 			return tv, i, true
 		}
 	}
@@ -573,7 +834,7 @@ func (ge *GideView) SetActiveTextViewIdx(idx int) *gide.TextView {
 	wupdt := ge.TopUpdateStart()
 	defer ge.TopUpdateEnd(wupdt)
 
-	if idx < 0 || idx >= NTextViews {
+	if idx < 0 || idx >= ge.NTextViews() {
 		log.Printf("GideView SetActiveTextViewIdx: text view index out of range: %v\n", idx)
 		return nil
 	}
@@ -596,28 +857,30 @@ func (ge *GideView) NextTextView() (*gide.TextView, int) {
 	if av.Buf == nil {
 		return av, ge.ActiveTextViewIdx
 	}
-	nxt := (ge.ActiveTextViewIdx + 1) % NTextViews
-	if !ge.PanelIsOpen(nxt + TextView1Idx) {
+	nxt := (ge.ActiveTextViewIdx + 1) % ge.NTextViews()
+	if !ge.TextViewIsOpen(nxt) {
 		return av, ge.ActiveTextViewIdx
 	}
 	return ge.TextViewByIndex(nxt), nxt
 }
 
-// SwapTextViews switches the buffers for the two open textviews
-// only operates if both panels are open
+// SwapTextViews switches the buffers for the first textviews in each of the
+// two text view panels -- only operates if both panels are open
 func (ge *GideView) SwapTextViews() bool {
-	if !ge.PanelIsOpen(TextView1Idx) || !ge.PanelIsOpen(TextView1Idx+1) {
+	if !ge.PanelIsOpen(TextView1Idx) || !ge.PanelIsOpen(TextView2Idx) {
 		return false
 	}
 	wupdt := ge.TopUpdateStart()
 	defer ge.TopUpdateEnd(wupdt)
 
-	tva := ge.TextViewByIndex(0)
-	tvb := ge.TextViewByIndex(1)
+	tva := ge.TextViewByIndex(ge.PanelTextViewIdx(TextView1Idx))
+	tvb := ge.TextViewByIndex(ge.PanelTextViewIdx(TextView2Idx))
 	bufa := tva.Buf
 	bufb := tvb.Buf
 	tva.SetBuf(bufb)
 	tvb.SetBuf(bufa)
+	ge.UpdateFileTabs(tva)
+	ge.UpdateFileTabs(tvb)
 	ge.SetStatus("swapped buffers")
 	return true
 }
@@ -631,15 +894,23 @@ func (ge *GideView) SaveActiveView() {
 	if tv.Buf != nil {
 		ge.LastSaveTStamp = time.Now()
 		if tv.Buf.Filename != "" {
-			tv.Buf.Save()
+			err := tv.Buf.Save()
 			ge.SetStatus("File Saved")
 			fnm := string(tv.Buf.Filename)
+			ge.LogAction(gide.ActionSave, "save", fnm, err)
 			updt := ge.FilesView.UpdateStart()
 			ge.FilesView.SetFullReRender()
 			fpath, _ := filepath.Split(fnm)
 			ge.Files.UpdateNewFile(fpath) // update everything in dir -- will have removed autosave
 			ge.FilesView.UpdateEnd(updt)
 			ge.RunPostCmdsActiveView()
+			ge.WebPreviewReload()
+			ge.SymIdx.UpdateFile(fnm)
+			ge.UpdateTodoFile(fnm)
+			ge.LintOnSave(fnm)
+			ge.BuildOnSave()
+			ge.LiveRun.Changed(fnm)
+			ge.Plugins.Event("save", fnm, "")
 		} else {
 			giv.CallMethod(ge, "SaveActiveViewAs", ge.Viewport) // uses fileview
 		}
@@ -660,13 +931,14 @@ func (ge *GideView) SaveActiveViewAs(filename gi.FileName) {
 				return
 			}
 			ge.SetStatus(fmt.Sprintf("File %v Saved As: %v", ofn, filename))
+			ge.LogAction(gide.ActionSave, "save as", string(filename), nil)
 			// ge.RunPostCmdsActiveView() // doesn't make sense..
 			ge.Files.UpdateNewFile(string(filename)) // update everything in dir -- will have removed autosave
 			fnk, ok := ge.Files.FindFile(string(filename))
 			if ok {
 				fn := fnk.This().Embed(giv.KiT_FileNode).(*giv.FileNode)
 				if fn.Buf != nil {
-					fn.Buf.Revert()
+					gide.RevertBuf(fn.Buf)
 				}
 				ge.ViewFileNode(tv, ge.ActiveTextViewIdx, fn)
 			}
@@ -675,12 +947,32 @@ func (ge *GideView) SaveActiveViewAs(filename gi.FileName) {
 	ge.SaveProjIfExists(false) // no saveall
 }
 
+// ExportActiveView exports the selection of the active text view, or all of
+// its file if nothing is selected, with its syntax highlighting, to given
+// file: as a PDF if it has the .pdf extension (using an external converter,
+// see gide.PDFConverters), else as a standalone HTML page
+func (ge *GideView) ExportActiveView(filename gi.FileName, lineNos bool) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil || filename == "" {
+		return
+	}
+	reg := textbuf.Region{}
+	if tv.HasSelection() {
+		reg = tv.SelectReg
+	}
+	if err := gide.ExportFile(tv.Buf, reg, string(filename), lineNos); err != nil {
+		ge.SetStatus(fmt.Sprintf("Export of %v failed: %v", tv.Buf.Filename, html.EscapeString(err.Error())))
+		return
+	}
+	ge.SetStatus(fmt.Sprintf("Exported %v to: %v", tv.Buf.Filename, filename))
+}
+
 // RevertActiveView revert active view to saved version
 func (ge *GideView) RevertActiveView() {
 	tv := ge.ActiveTextView()
 	if tv.Buf != nil {
 		ge.ConfigTextBuf(tv.Buf)
-		tv.Buf.Revert()
+		gide.RevertBuf(tv.Buf)
 		tv.Buf.Undos.Reset() // key implication of revert
 		fpath, _ := filepath.Split(string(tv.Buf.Filename))
 		ge.Files.UpdateNewFile(fpath) // update everything in dir -- will have removed autosave
@@ -718,13 +1010,11 @@ func (ge *GideView) RunPostCmdsActiveView() bool {
 // -- returns true if commands were run and file was reverted after that --
 // uses MainLang to disambiguate if multiple languages associated with extension.
 func (ge *GideView) RunPostCmdsFileNode(fn *giv.FileNode) bool {
-	lang := fn.Info.Sup
-	if lopt, has := gide.AvailLangs[lang]; has {
-		if len(lopt.PostSaveCmds) > 0 {
-			ge.ExecCmdsFileNode(fn, lopt.PostSaveCmds, false, true) // no select, yes clear
-			fn.Buf.Revert()
-			return true
-		}
+	cmds := ge.Prefs.LangPostSaveCmds(fn.Info.Sup)
+	if len(cmds) > 0 {
+		ge.ExecCmdsFileNode(fn, cmds, false, true) // no select, yes clear
+		gide.RevertBuf(fn.Buf)
+		return true
 	}
 	return false
 }
@@ -762,10 +1052,21 @@ func (ge *GideView) OpenFileNode(fn *giv.FileNode) (bool, error) {
 	if fn.IsDir() {
 		return false, fmt.Errorf("cannot open directory: %v", fn.FPath)
 	}
-	giv.FileNodeHiStyle = gi.Prefs.Colors.HiStyle // must be set prior to OpenBuf
+	giv.FileNodeHiStyle = ge.Prefs.ProjHiStyle() // must be set prior to OpenBuf
+	// make the buffer as OpenBuf does, so that it does not load the spelling model
+	if fn.Buf == nil {
+		fn.Buf = &giv.TextBuf{}
+		fn.Buf.InitName(fn.Buf, fn.Nm)
+		fn.Buf.AddFileNode(fn)
+	}
+	gide.DeferSpell(fn.Buf)
 	nw, err := fn.OpenBuf()
 	if err == nil {
 		ge.ConfigTextBuf(fn.Buf)
+		ge.MarkFileProblems(fn.Buf)
+		ge.MarkFileCoverage(fn.Buf)
+		gide.MarkGenerate(fn.Buf)
+		gide.MarkTests(fn.Buf)
 		ge.OpenNodes.Add(fn)
 		fn.SetOpen()
 		// updt := ge.FilesView.UpdateStart()
@@ -790,10 +1091,15 @@ func (ge *GideView) ViewFileNode(tv *gide.TextView, vidx int, fn *giv.FileNode)
 	}
 	nw, err := ge.OpenFileNode(fn)
 	if err == nil {
+		if tv.Buf != nil && tv.Buf != fn.Buf {
+			ge.NavHist.Save(gide.NavPos{Filename: tv.Buf.Filename, Pos: tv.CursorPos})
+		}
 		tv.StyleTextView() // make sure
 		tv.SetBuf(fn.Buf)
+		ge.UpdateFileTabs(tv)
 		if nw {
 			ge.AutoSaveCheck(tv, vidx, fn)
+			ge.Plugins.Event("open", string(fn.FPath), "")
 		}
 		ge.SetActiveTextViewIdx(vidx) // this calls FileModCheck
 	}
@@ -906,16 +1212,17 @@ func (ge *GideView) ViewFileInIdx(fnm gi.FileName, idx int) (*gide.TextView, int
 	return tv, idx, true
 }
 
-// LinkViewFileNode opens the file node in the 2nd textview, which is next to
-// the tabs where links are clicked, if it is not collapsed -- else 1st
+// LinkViewFileNode opens the file node in the first textview of the 2nd
+// panel, which is next to the tabs where links are clicked, if it is not
+// collapsed -- else 1st
 func (ge *GideView) LinkViewFileNode(fn *giv.FileNode) (*gide.TextView, int) {
 	wupdt := ge.TopUpdateStart()
 	defer ge.TopUpdateEnd(wupdt)
 
 	if ge.PanelIsOpen(TextView2Idx) {
-		ge.SetActiveTextViewIdx(1)
+		ge.SetActiveTextViewIdx(ge.PanelTextViewIdx(TextView2Idx))
 	} else {
-		ge.SetActiveTextViewIdx(0)
+		ge.SetActiveTextViewIdx(ge.PanelTextViewIdx(TextView1Idx))
 	}
 	tv := ge.ActiveTextView()
 	idx := ge.ActiveTextViewIdx
@@ -930,13 +1237,15 @@ func (ge *GideView) LinkViewFile(fnm gi.FileName) (*gide.TextView, int, bool) {
 	if fn == nil {
 		return nil, -1, false
 	}
+	ge.SaveNavPos()
 	tv, idx, ok := ge.TextViewForFileNode(fn)
 	if ok {
-		if idx == 1 {
+		lidx := ge.PanelTextViewIdx(TextView2Idx)
+		if idx != ge.PanelTextViewIdx(TextView1Idx) {
 			return tv, idx, true
 		}
 		if ge.SwapTextViews() {
-			return ge.TextViewByIndex(1), 1, true
+			return ge.TextViewByIndex(lidx), lidx, true
 		}
 	}
 	nv, nidx := ge.LinkViewFileNode(fn)
@@ -954,6 +1263,28 @@ func (ge *GideView) ShowFile(fname string, ln int) (*gide.TextView, error) {
 	return nil, fmt.Errorf("ShowFile: file named: %v not found\n", fname)
 }
 
+// ViewFilePos views given file at the given line and column (if set) in
+// the next text view -- the view is scrolled to it when next rendered, so it
+// works for a just-opened window too
+func (ge *GideView) ViewFilePos(fp gide.FilePos) bool {
+	tv, _, ok := ge.NextViewFile(gi.FileName(fp.Path))
+	if !ok || tv.Buf == nil {
+		return false
+	}
+	if fp.Line == 0 {
+		return true
+	}
+	cpos := tv.Buf.ValidPos(fp.Pos())
+	top := cpos.Ln - tv.VisSize.Y/3 // some context above
+	if top < 0 {
+		top = 0
+	}
+	tv.CursorPos = lex.Pos{Ln: top}
+	tv.ScrollToCursorOnRender = true
+	tv.RenderCursor = &cpos
+	return true
+}
+
 // GideViewOpenNodes gets list of open nodes for submenu-func
 func GideViewOpenNodes(it interface{}, vp *gi.Viewport2D) []string {
 	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
@@ -973,6 +1304,66 @@ func (ge *GideView) ViewOpenNodeName(name string) {
 	ge.ViewFileNode(tv, ge.ActiveTextViewIdx, nb)
 }
 
+// SearchFile opens the incremental search bar for the active text view,
+// starting with the selected text, if any
+func (ge *GideView) SearchFile() {
+	tv := ge.SearchTextView()
+	if tv == nil {
+		return
+	}
+	find := ""
+	if tv.HasSelection() {
+		find = string(tv.Selection().ToBytes())
+	}
+	gide.OpenSearchBar(tv, find)
+}
+
+// SearchTextView returns the TextView that SearchFile searches: the one
+// with the keyboard focus (e.g., command output or the debug console), or
+// whose search bar has the focus, else the active textview
+func (ge *GideView) SearchTextView() *giv.TextView {
+	if win := ge.ParentWindow(); win != nil {
+		if fk := win.EventMgr.CurFocus(); fk != nil {
+			if ftv, ok := fk.Embed(giv.KiT_TextView).(*giv.TextView); ok {
+				return ftv
+			}
+			if sb, ok := fk.ParentByType(gide.KiT_SearchBar, ki.Embeds).(*gide.SearchBar); ok {
+				return sb.View
+			}
+		}
+	}
+	atv := ge.ActiveTextView()
+	if atv == nil {
+		return nil
+	}
+	return &atv.TextView
+}
+
+// QuickOpen opens a dialog for finding any file in the project by typing
+// any part of its path, which is then viewed in the active textview --
+// files are ranked by how recently they were viewed and how shallow they
+// are, and the Find Exclude globs are honored.
+func (ge *GideView) QuickOpen() {
+	if ge.IsEmpty() {
+		return
+	}
+	qo := &gide.QuickOpen{}
+	qo.ReadFiles(string(ge.ProjRoot), gide.FindGlobs(ge.Prefs.Find.Exclude), &ge.Ignore)
+	for _, fn := range ge.OpenNodes {
+		qo.AddRecent(string(fn.FPath))
+	}
+	gide.QuickOpenDialog(ge.Viewport, qo, giv.DlgOpts{Title: "Quick Open", Prompt: "Type any part of the path of the file to open -- characters need not be adjacent"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.DialogAccepted) {
+			return
+		}
+		gee := recv.Embed(KiT_GideView).(*GideView)
+		fnm := gide.QuickOpenDialogValue(send.(*gi.Dialog), qo)
+		if fnm != "" {
+			gee.ViewFile(gi.FileName(fnm))
+		}
+	})
+}
+
 // SelectOpenNode pops up a menu to select an open node (aka buffer) to view
 // in current active textview
 func (ge *GideView) SelectOpenNode() {
@@ -1010,6 +1401,23 @@ func (ge *GideView) CloneActiveView() (*gide.TextView, int) {
 	return nil, -1
 }
 
+// UndoDelete restores the files of the most recent deletion from the file
+// tree, which were moved to the trash
+func (ge *GideView) UndoDelete() {
+	restored, err := ge.FileTrash().Undo()
+	if len(restored) > 0 {
+		updt := ge.FilesView.UpdateStart()
+		ge.FilesView.SetFullReRender()
+		ge.Files.UpdateDir()
+		ge.FilesView.UpdateEnd(updt)
+		ge.UpdateVcsStatus()
+		ge.SetStatus(fmt.Sprintf("restored %d file(s) from the trash", len(restored)))
+	}
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could Not Undo Delete", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+}
+
 // SaveAllOpenNodes saves all of the open filenodes to their current file names
 func (ge *GideView) SaveAllOpenNodes() {
 	for _, ond := range ge.OpenNodes {
@@ -1017,10 +1425,18 @@ func (ge *GideView) SaveAllOpenNodes() {
 			continue
 		}
 		if ond.Buf.IsChanged() {
-			ond.Buf.Save()
+			err := ond.Buf.Save()
+			ge.LogAction(gide.ActionSave, "save", string(ond.FPath), err)
 			ge.RunPostCmdsFileNode(ond)
+			ge.SymIdx.UpdateFile(string(ond.FPath))
+			ge.UpdateTodoFile(string(ond.FPath))
+			ge.LintOnSave(string(ond.FPath))
+			ge.BuildOnSave()
+			ge.LiveRun.Changed(string(ond.FPath))
+			ge.Plugins.Event("save", string(ond.FPath), "")
 		}
 	}
+	ge.WebPreviewReload()
 }
 
 // SaveAll saves all of the open filenodes to their current file names
@@ -1055,6 +1471,24 @@ func (ge *GideView) CloseOpenNodes(nodes []*gide.FileNode) {
 	}
 }
 
+// RenameOpenNodes updates the open nodes for files at or within orgpath
+// having been renamed (moved) to newpath, so their buffers and views
+// refer to the new path
+func (ge *GideView) RenameOpenNodes(orgpath, newpath string) {
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf == nil {
+			continue
+		}
+		fp := string(ond.Buf.Filename)
+		if fp != orgpath && !strings.HasPrefix(fp, orgpath+string(filepath.Separator)) {
+			continue
+		}
+		np := newpath + fp[len(orgpath):]
+		ge.OpenNodes.Rename(ond, ge.FileNodeForFile(np, false), np)
+	}
+	ge.UpdateTextButtons()
+}
+
 // TextViewSig handles all signals from the textviews
 func (ge *GideView) TextViewSig(tv *gide.TextView, sig giv.TextViewSignals) {
 	ge.SetActiveTextView(tv) // if we're sending signals, we're the active one!
@@ -1153,8 +1587,12 @@ func TextLinkHandler(tl girl.TextLink) bool {
 		switch {
 		case strings.HasPrefix(ur, "find:///"):
 			ge.OpenFindURL(ur, ftv)
+		case strings.HasPrefix(ur, "findsel:///"), strings.HasPrefix(ur, "findfold:///"), strings.HasPrefix(ur, "finddel:///"):
+			ge.FindLinkURL(ur, ftv)
 		case strings.HasPrefix(ur, "file:///"):
 			ge.OpenFileURL(ur, ftv)
+		case strings.HasPrefix(ur, "tab:///"), strings.HasPrefix(ur, "gide:///"), strings.HasPrefix(ur, "reload:///"):
+			ge.NotifyLinkURL(ur)
 		default:
 			oswin.TheApp.OpenURL(ur)
 		}
@@ -1164,6 +1602,39 @@ func TextLinkHandler(tl girl.TextLink) bool {
 	return true
 }
 
+// NotifyLinkURL handles the links of the actions of the notifications:
+// tab:/// selects a tab, gide:/// calls a method of the project, and
+// reload:/// reverts a file from disk
+func (ge *GideView) NotifyLinkURL(ur string) {
+	up, err := url.Parse(ur)
+	if err != nil {
+		return
+	}
+	arg := strings.TrimPrefix(up.Path, "/")
+	switch up.Scheme {
+	case "tab":
+		if _, err := ge.TabByNameTry(arg); err != nil {
+			ge.SetStatus("the tab is closed: " + arg)
+			return
+		}
+		ge.SelectTabByName(arg)
+		ge.FocusOnPanel(TabsIdx)
+	case "gide":
+		giv.CallMethod(ge, arg, ge.Viewport)
+	case "reload":
+		fn, ok := ge.Files.FindFile(arg)
+		if !ok || fn.Buf == nil {
+			return
+		}
+		if fn.Buf.IsChanged() {
+			ge.LinkViewFile(gi.FileName(arg)) // asks what to do
+			return
+		}
+		gide.RevertBuf(fn.Buf)
+		ge.SetStatus("reloaded: " + ge.Files.RelPath(gi.FileName(arg)))
+	}
+}
+
 // // URLHandler is the GideView handler for urls --
 // func URLHandler(url string) bool {
 // 	return true
@@ -1191,6 +1662,11 @@ func (ge *GideView) OpenFileURL(ur string, ftv *giv.TextView) bool {
 			}
 		}
 	}
+	if cp := &ge.Prefs.Container; cp.On() { // paths in the container
+		fpath = cp.HostPath(string(ge.ProjRoot), fpath)
+	} else if ge.Prefs.WSL.On() {
+		fpath = ge.Prefs.WSL.HostPath(fpath)
+	}
 	pos := up.Fragment
 	tv, _, ok := ge.LinkViewFile(gi.FileName(fpath))
 	if !ok {
@@ -1275,6 +1751,162 @@ func QuitReq() bool {
 	return true
 }
 
+// GideViewForFile returns the GideView of the open project window whose
+// project contains given file (full path), the innermost one if several do
+func GideViewForFile(fpath string) (*GideView, bool) {
+	var fge *GideView
+	for _, win := range gi.MainWindows {
+		if !strings.HasPrefix(win.Nm, "gide-") {
+			continue
+		}
+		mfr, err := win.MainWidget()
+		if err != nil {
+			continue
+		}
+		gek := mfr.ChildByName("gide", 0)
+		if gek == nil {
+			continue
+		}
+		ge := gek.Embed(KiT_GideView).(*GideView)
+		root := string(ge.ProjRoot)
+		if root == "" || (fpath != root && !strings.HasPrefix(fpath, root+string(filepath.Separator))) {
+			continue
+		}
+		if fge == nil || len(root) > len(fge.ProjRoot) {
+			fge = ge
+		}
+	}
+	return fge, fge != nil
+}
+
+// FirstGideView returns the GideView of the first open project window, nil
+// if there is none
+func FirstGideView() *GideView {
+	for _, win := range gi.MainWindows {
+		if !strings.HasPrefix(win.Nm, "gide-") {
+			continue
+		}
+		if mfr, err := win.MainWidget(); err == nil {
+			if gek := mfr.ChildByName("gide", 0); gek != nil {
+				return gek.Embed(KiT_GideView).(*GideView)
+			}
+		}
+	}
+	return nil
+}
+
+// EventLoopFunc is a function run on the event loop of a project window,
+// sent to it as the data of a custom event (see RunOnEventLoop)
+type EventLoopFunc func()
+
+// RunOnEventLoop runs given function on the event loop of the window of
+// given GideView, or of the first project window if nil, as the GUI is
+// driven from there, for the requests coming from other goroutines --
+// directly if there is no project window
+func RunOnEventLoop(ge *GideView, fn func()) {
+	if ge == nil {
+		ge = FirstGideView()
+	}
+	if ge != nil {
+		if win := ge.ParentWindow(); win != nil {
+			win.SendCustomEvent(EventLoopFunc(fn))
+			return
+		}
+	}
+	fn()
+}
+
+// PostOpenReq performs given request of another invocation of gide (see
+// OpenReq) on the event loop of a project window -- for the
+// gide.InstanceServer, which gets the requests on its own goroutines
+func PostOpenReq(req *gide.OpenRequest) {
+	RunOnEventLoop(nil, func() {
+		OpenReq(req)
+	})
+}
+
+// OpenFilePos opens given files at their positions, each in the open
+// project window containing it, or else in a new window for the project it
+// is in (see gide.ProjRootForFile) -- returns the GideView of the last one
+func OpenFilePos(fps []gide.FilePos) *GideView {
+	var lge *GideView
+	for _, fp := range fps {
+		ge, ok := GideViewForFile(fp.Path)
+		if !ok {
+			if _, ge = NewGideProjPath(gide.ProjRootForFile(fp.Path)); ge == nil {
+				continue
+			}
+		}
+		fp := fp
+		gev := ge
+		ge.FilesLoad.WhenLoaded(func() {
+			if !gev.ViewFilePos(fp) {
+				gev.SetStatus(fmt.Sprintf("could not open file: %v", fp.Path))
+			}
+		})
+		if win := ge.ParentWindow(); win != nil {
+			win.OSWin.Raise()
+		}
+		lge = ge
+	}
+	return lge
+}
+
+// AskPass prompts for a credential requested by a command run by gide, in
+// a dialog of the window of the project the command runs in (see
+// gide.AskPassServer) -- it is not called on the window event loop: it
+// opens the dialog there (see RunOnEventLoop), and blocks until the
+// credential is entered or the dialog is canceled
+func AskPass(req *gide.AskPassRequest) (string, bool) {
+	ge, ok := GideViewForFile(req.Dir)
+	if !ok {
+		ge = FirstGideView()
+	}
+	if ge == nil {
+		return "", false
+	}
+	rep := make(chan gide.AskPassReply, 1)
+	RunOnEventLoop(ge, func() {
+		dlg := gi.StringPromptDialog(ge.Viewport, "", "",
+			gi.DlgOpts{Title: "Credential Required", Prompt: html.EscapeString(req.Prompt)},
+			ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+				switch sig {
+				case int64(gi.DialogAccepted):
+					rep <- gide.AskPassReply{Answer: gi.StringPromptDialogValue(send.(*gi.Dialog)), Ok: true}
+				case int64(gi.DialogCanceled):
+					rep <- gide.AskPassReply{}
+				}
+			})
+		if gide.AskPassIsSecret(req.Prompt) {
+			if tf, ok := dlg.Frame().ChildByName("str-field", 0).(*gi.TextField); ok {
+				tf.NoEcho = true
+			}
+		}
+	})
+	r := <-rep
+	return r.Answer, r.Ok
+}
+
+// OpenReq performs a request from another invocation of gide to open a
+// project and / or files (see gide.InstanceServer) -- with nothing to open,
+// it opens a new window, as starting gide does
+func OpenReq(req *gide.OpenRequest) {
+	switch {
+	case req.Proj != "":
+		OpenGideProj(req.Proj)
+	case req.Path != "":
+		NewGideProjPath(req.Path)
+	case len(req.Files) == 0:
+		_, ge := NewGideProjPath("")
+		if ge != nil && gide.Prefs.StartupRecents && len(gide.RecentProjects) > 0 {
+			ge.RecentProjs()
+		}
+	}
+	if len(req.Files) > 0 {
+		OpenFilePos(req.Files)
+	}
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //   Panels
 
@@ -1311,10 +1943,8 @@ func (ge *GideView) FocusOnPanel(panel int) bool {
 	sv := ge.SplitView()
 	win := ge.ParentWindow()
 	switch panel {
-	case TextView1Idx:
-		ge.SetActiveTextViewIdx(0)
-	case TextView2Idx:
-		ge.SetActiveTextViewIdx(1)
+	case TextView1Idx, TextView2Idx:
+		ge.SetActiveTextViewIdx(ge.PanelTextViewIdx(panel))
 	case TabsIdx:
 		tv := ge.Tabs()
 		ct, _, has := tv.CurTab()
@@ -1327,6 +1957,9 @@ func (ge *GideView) FocusOnPanel(panel int) bool {
 		ski := sv.Kids[panel]
 		win.EventMgr.FocusNext(ski)
 	}
+	if gide.Prefs.Access.Announce {
+		ge.AnnounceFocus()
+	}
 	return true
 }
 
@@ -1367,45 +2000,513 @@ func (ge *GideView) FocusPrevPanel() {
 }
 
 //////////////////////////////////////////////////////////////////////////////////////
-//    Tabs
+//   Panes
 
-// TabByName returns a tab with given name, nil if not found.
-func (ge *GideView) TabByName(label string) gi.Node2D {
-	tv := ge.Tabs()
-	return tv.TabByName(label)
+// TextViews returns all of the TextViews in the editor panes of the text view
+// panels, in order -- their index in this list is the text view index used
+// throughout
+func (ge *GideView) TextViews() []*gide.TextView {
+	split := ge.SplitView()
+	var tvs []*gide.TextView
+	for i := 0; i < NTextPanels; i++ {
+		tvs = PaneTextViews(split.Child(TextView1Idx+i), tvs)
+	}
+	return tvs
 }
 
-// TabByNameTry returns a tab with given name, error if not found.
-func (ge *GideView) TabByNameTry(label string) (gi.Node2D, error) {
-	tv := ge.Tabs()
-	return tv.TabByNameTry(label)
+// NTextViews returns the total number of text views in all the editor panes
+func (ge *GideView) NTextViews() int {
+	return len(ge.TextViews())
 }
 
-// SelectTabByName Selects given main tab, and returns all of its contents as well.
-func (ge *GideView) SelectTabByName(label string) gi.Node2D {
-	tv := ge.Tabs()
-	if tv == nil {
-		return nil
+// PaneTextViews appends all of the TextViews within given pane to list,
+// recursively for panes that have been split
+func PaneTextViews(pane ki.Ki, tvs []*gide.TextView) []*gide.TextView {
+	if psv, ok := pane.(*gi.SplitView); ok {
+		for _, k := range psv.Kids {
+			tvs = PaneTextViews(k, tvs)
+		}
+		return tvs
 	}
-	return tv.SelectTabByName(label)
+	return append(tvs, PaneTextView(pane))
 }
 
-// RecycleTab returns a tab with given name, first by looking for an existing one,
-// and if not found, making a new one with widget of given type.
-// If sel, then select it.  returns widget for tab.
-func (ge *GideView) RecycleTab(label string, typ reflect.Type, sel bool) gi.Node2D {
-	tv := ge.Tabs()
-	if tv == nil {
-		return nil
-	}
-	return tv.RecycleTab(label, typ, sel)
+// PaneTextView returns the TextView within given (unsplit) editor pane
+func PaneTextView(pane ki.Ki) *gide.TextView {
+	return pane.Child(1).ChildByType(gide.KiT_TextView, ki.Embeds, 0).Embed(gide.KiT_TextView).(*gide.TextView)
 }
 
-// RecycleTabTextView returns a tab with given
-// name, first by looking for an existing one, and if not found, making a new
-// one with a Layout and then a TextView in it.  if sel, then select it.
-// returns widget
-func (ge *GideView) RecycleTabTextView(label string, sel bool) *giv.TextView {
+// PaneFileTabs returns the FileTabs within given (unsplit) editor pane
+func PaneFileTabs(pane ki.Ki) *gide.FileTabs {
+	return pane.Child(0).Child(1).Embed(gide.KiT_FileTabs).(*gide.FileTabs)
+}
+
+// TextViewFileTabs returns the FileTabs for the editor pane of given text view
+func TextViewFileTabs(tv *gide.TextView) *gide.FileTabs {
+	return PaneFileTabs(tv.Parent().Parent())
+}
+
+// PanelTextViewIdx returns the index of the first text view within given
+// main text view panel (TextView1Idx or TextView2Idx)
+func (ge *GideView) PanelTextViewIdx(panel int) int {
+	split := ge.SplitView()
+	var tvs []*gide.TextView
+	for i := TextView1Idx; i < panel; i++ {
+		tvs = PaneTextViews(split.Child(i), tvs)
+	}
+	return len(tvs)
+}
+
+// TextViewIsOpen returns true if the text view at given index is visible:
+// neither its main panel nor any of the panes containing it are collapsed
+func (ge *GideView) TextViewIsOpen(idx int) bool {
+	tv := ge.TextViewByIndex(idx)
+	if tv == nil {
+		return false
+	}
+	split := ge.SplitView()
+	pane := tv.Parent().Parent()
+	for pane.Parent() != split.This() {
+		psv := pane.Parent().Embed(gi.KiT_SplitView).(*gi.SplitView)
+		pi, _ := pane.IndexInParent()
+		if pi < len(psv.Splits) && psv.Splits[pi] <= 0.01 {
+			return false
+		}
+		pane = psv.This()
+	}
+	pi, _ := pane.IndexInParent()
+	return ge.PanelIsOpen(pi)
+}
+
+// SplitPaneHoriz splits the active editor pane in two, with a new pane to the
+// right of it viewing the same file
+func (ge *GideView) SplitPaneHoriz() {
+	ge.SplitPane(mat32.X)
+}
+
+// SplitPaneVert splits the active editor pane in two, with a new pane below
+// it viewing the same file
+func (ge *GideView) SplitPaneVert() {
+	ge.SplitPane(mat32.Y)
+}
+
+// SplitPane splits the active editor pane in two along given dimension, with
+// the new pane after it viewing the same file, and active -- returns the
+// text view in the new pane
+func (ge *GideView) SplitPane(dim mat32.Dims) *gide.TextView {
+	av := ge.ActiveTextView()
+	if av == nil {
+		return nil
+	}
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+
+	split := ge.SplitView()
+	updt := split.UpdateStart()
+	pane := av.Parent().Parent()
+	psv := pane.Parent().Embed(gi.KiT_SplitView).(*gi.SplitView)
+	at, _ := pane.IndexInParent()
+	if psv.This() == split.This() || psv.Dim != dim {
+		nsv := psv.InsertNewChild(gi.KiT_SplitView, at, ge.NewPaneName("panesplit")).(*gi.SplitView)
+		nsv.Dim = dim
+		ki.MoveToParent(pane, nsv)
+		psv, at = nsv, 0
+	}
+	psv.UpdateSplits()
+	hs := psv.Splits[at] / 2
+	ntv := ge.AddTextPane(psv, at+1)
+	ntv.Zoom = av.Zoom
+	psv.Splits[at] = hs
+	psv.Splits = append(psv.Splits[:at+1], append([]float32{hs}, psv.Splits[at+1:]...)...)
+	ge.ConfigTextViews()
+	if av.Buf != nil {
+		ntv.SetBuf(av.Buf)
+		ge.UpdateFileTabs(ntv)
+	}
+	split.SetFullReRender()
+	split.UpdateEnd(updt)
+	ge.SetActiveTextViewIdx(ge.TextViewIndex(ntv))
+	return ntv
+}
+
+// ClosePane closes the active editor pane -- the only pane remaining in a
+// main text view panel cannot be closed, but the panel can be collapsed using
+// the splitter
+func (ge *GideView) ClosePane() bool {
+	av := ge.ActiveTextView()
+	if av == nil {
+		return false
+	}
+	split := ge.SplitView()
+	pane := av.Parent().Parent()
+	if pane.Parent() == split.This() {
+		ge.SetStatus("Cannot close the only pane in a panel -- collapse it with the splitter instead")
+		return false
+	}
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+
+	updt := split.UpdateStart()
+	idx := ge.ActiveTextViewIdx
+	av.SetBuf(nil)
+	psv := pane.Parent().Embed(gi.KiT_SplitView).(*gi.SplitView)
+	pi, _ := pane.IndexInParent()
+	psv.DeleteChild(pane, true)
+	if pi < len(psv.Splits) {
+		psv.Splits = append(psv.Splits[:pi], psv.Splits[pi+1:]...)
+	}
+	psv.UpdateSplits()
+	if psv.NumChildren() == 1 { // replace the split with its remaining pane
+		gp := psv.Parent()
+		at, _ := psv.IndexInParent()
+		kid := psv.Child(0)
+		ki.SetParent(kid, nil)
+		psv.DeleteChild(kid, false)
+		gp.InsertChild(kid, at)
+		gp.DeleteChild(psv.This(), true)
+	}
+	split.SetFullReRender()
+	split.UpdateEnd(updt)
+	if idx > 0 {
+		idx--
+	}
+	ge.SetActiveTextViewIdx(idx)
+	return true
+}
+
+// FocusNextPane moves the keyboard focus to the next open editor pane
+func (ge *GideView) FocusNextPane() {
+	ge.FocusPaneDelta(1)
+}
+
+// FocusPrevPane moves the keyboard focus to the previous open editor pane
+func (ge *GideView) FocusPrevPane() {
+	ge.FocusPaneDelta(-1)
+}
+
+// ToggleFocusMode toggles the distraction-free mode, in which just the
+// active editor pane is shown, centered with a maximum width (see
+// gide.FocusMode) -- the layout is restored when it is turned off
+func (ge *GideView) ToggleFocusMode() {
+	tv := ge.ActiveTextView()
+	if tv == nil && !ge.FocusMode.On {
+		return
+	}
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+	updt := ge.UpdateStart()
+	ge.SetFullReRender()
+	if ge.FocusMode.On {
+		ge.FocusMode.Restore()
+		ge.UpdateEnd(updt)
+		ge.SetStatus("distraction-free mode off")
+		return
+	}
+	fm := &ge.FocusMode
+	fm.On = true
+	split := ge.SplitView()
+	pane := tv.Parent().Parent() // the textlay of the pane
+	for k := pane; k != nil && k.This() != split.This(); k = k.Parent() {
+		if psv, ok := k.Parent().(*gi.SplitView); ok {
+			fm.Collapse(psv, k)
+		}
+	}
+	if tb := ge.ToolBar(); tb != nil {
+		fm.Hide(&tb.WidgetBase)
+	}
+	if sb := ge.StatusBar(); sb != nil {
+		fm.Hide(&sb.WidgetBase)
+	}
+	if bly, ok := pane.Child(0).(*gi.Layout); ok { // the pane button
+		fm.Hide(&bly.WidgetBase)
+	}
+	if tily, ok := tv.Parent().(*gi.Layout); ok {
+		fm.SetMaxWidth(&tily.WidgetBase)
+	}
+	ge.UpdateEnd(updt)
+	tv.GrabFocus()
+	ge.SetStatus("distraction-free mode on")
+}
+
+// CommandPalette opens a dialog for fuzzy finding any command of the main
+// menu, by its menu path (e.g., File > Save), and runs the chosen one --
+// all the commands are thus reachable from the keyboard
+func (ge *GideView) CommandPalette() {
+	win := ge.ParentWindow()
+	if win == nil || win.MainMenu == nil {
+		return
+	}
+	acts := map[string]*gi.Action{}
+	qo := &gide.QuickOpen{}
+	var addMenu func(path string, m gi.Menu)
+	addMenu = func(path string, m gi.Menu) {
+		for _, mi := range m {
+			ac, ok := mi.(*gi.Action)
+			if !ok {
+				continue // separators
+			}
+			lbl := strings.TrimSuffix(ac.Text, "...")
+			if path != "" {
+				lbl = path + " > " + lbl
+			}
+			switch {
+			case ac.MakeMenuFunc != nil:
+				continue // dynamic menus, e.g., Open Recent
+			case ac.HasMenu():
+				addMenu(lbl, ac.Menu)
+			case !ac.IsInactive() && ac.Text != "":
+				if _, has := acts[lbl]; !has {
+					acts[lbl] = ac
+					qo.Files = append(qo.Files, lbl)
+				}
+			}
+		}
+	}
+	win.MainMenuUpdateActives()
+	for _, mi := range win.MainMenu.Kids {
+		if ac, ok := mi.(*gi.Action); ok && ac.Menu != nil {
+			addMenu(ac.Text, ac.Menu)
+		}
+	}
+	dlg := gide.QuickOpenDialog(ge.Viewport, qo, giv.DlgOpts{Title: "Command Palette", Prompt: "Type any part of the menu path of the command to run -- characters need not be adjacent"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.DialogAccepted) {
+			return
+		}
+		if ac, has := acts[gide.QuickOpenDialogMatch(send.(*gi.Dialog), qo)]; has {
+			ac.Trigger()
+		}
+	})
+	if tf, ok := dlg.Frame().ChildByName("pattern", 0).(*gi.TextField); ok {
+		tf.Placeholder = "type any part of the command.."
+	}
+}
+
+// FocusContextMenu opens the context menu of the widget with the keyboard
+// focus, e.g., the file operations of the selected files of the file tree
+func (ge *GideView) FocusContextMenu() {
+	win := ge.ParentWindow()
+	if win == nil {
+		return
+	}
+	if nii, ok := win.EventMgr.CurFocus().(gi.Node2D); ok {
+		nii.ContextMenu()
+	}
+}
+
+// FocusDescription returns the description of the widget with the keyboard
+// focus: its role, name and state, and the panel it is in
+func (ge *GideView) FocusDescription() string {
+	win := ge.ParentWindow()
+	if win == nil {
+		return ""
+	}
+	cf := win.EventMgr.CurFocus()
+	if cf == nil {
+		return "nothing has the focus"
+	}
+	desc := gide.AccessName(cf)
+	panel := ""
+	switch cp := ge.CurPanel(); cp {
+	case FileTreeIdx:
+		panel = "Files"
+	case TextView1Idx, TextView2Idx:
+		panel = fmt.Sprintf("Editor %d", cp)
+	case TabsIdx:
+		panel = "Panels"
+		if _, idx, has := ge.Tabs().CurTab(); has {
+			panel += ", tab " + ge.Tabs().TabName(idx)
+		}
+	default:
+		if cf.ParentLevel(ge.ToolBar()) >= 0 {
+			panel = "Toolbar"
+		}
+	}
+	if panel != "" {
+		desc += " -- in " + panel
+	}
+	return desc
+}
+
+// AnnounceFocus announces the widget with the keyboard focus, in the
+// statusbar and with the speech command of the accessibility preferences
+func (ge *GideView) AnnounceFocus() {
+	desc := ge.FocusDescription()
+	if desc == "" {
+		return
+	}
+	ge.SetStatus(desc)
+	if err := gide.Speak(gide.Prefs.Access.SpeakCmd, desc); err != nil {
+		log.Printf("gide: speech command %v failed: %v\n", gide.Prefs.Access.SpeakCmd, err)
+	}
+}
+
+// DescribeFocus announces the widget with the keyboard focus, even when the
+// focus announcements are turned off in the preferences
+func (ge *GideView) DescribeFocus() {
+	ge.AnnounceFocus()
+}
+
+// FocusPaneDelta moves the keyboard focus to the open editor pane at given
+// offset from the active one, wrapping around
+func (ge *GideView) FocusPaneDelta(delta int) {
+	n := ge.NTextViews()
+	idx := ge.ActiveTextViewIdx
+	for i := 0; i < n; i++ {
+		idx = (idx + delta + n) % n
+		if ge.TextViewIsOpen(idx) {
+			ge.SetActiveTextViewIdx(idx)
+			return
+		}
+	}
+}
+
+// UpdateFileTabs updates the file tabs for the pane of given text view to
+// show the file it is currently viewing
+func (ge *GideView) UpdateFileTabs(tv *gide.TextView) {
+	fnm := gi.FileName("")
+	if tv.Buf != nil {
+		fnm = tv.Buf.Filename
+	}
+	TextViewFileTabs(tv).SetCurFile(fnm)
+}
+
+// FileHasTab returns true if there is a tab for given file in any of the
+// editor panes
+func (ge *GideView) FileHasTab(fnm gi.FileName) bool {
+	for _, tv := range ge.TextViews() {
+		if _, _, has := TextViewFileTabs(tv).TabByFile(fnm); has {
+			return true
+		}
+	}
+	return false
+}
+
+// FileTabsSig handles all signals from the file tabs of the editor panes --
+// closing the last tab for a file closes its buffer
+func (ge *GideView) FileTabsSig(fts *gide.FileTabs, sig gide.FileTabsSignals, fnm gi.FileName) {
+	tv := PaneTextView(fts.Parent().Parent())
+	switch sig {
+	case gide.FileTabSelected, gide.FileTabDropped:
+		if fn := ge.FileNodeForFile(string(fnm), true); fn != nil {
+			ge.ViewFileNode(tv, ge.TextViewIndex(tv), fn)
+		}
+	case gide.FileTabClosed:
+		if tv.Buf != nil && tv.Buf.Filename == fnm {
+			tv.SetBuf(nil)
+			ge.SetStatus("")
+		}
+		if ge.FileHasTab(fnm) {
+			return
+		}
+		fn := ge.FileNodeForFile(string(fnm), false)
+		if fn == nil || fn.Buf == nil {
+			return
+		}
+		fn.Buf.Close(func(canceled bool) {
+			if canceled {
+				ge.SetStatus(fmt.Sprintf("File %v NOT closed", fn.FPath))
+				return
+			}
+			ge.SetStatus(fmt.Sprintf("File %v closed", fn.FPath))
+		})
+	}
+}
+
+// FileClosedTabs removes the tabs for given file, whose buffer has been
+// closed, from all of the editor panes -- panes viewing it switch to the
+// neighboring tab
+func (ge *GideView) FileClosedTabs(fnm gi.FileName) {
+	for i, tv := range ge.TextViews() {
+		next, has := TextViewFileTabs(tv).DeleteTab(fnm)
+		if !has || next == "" || tv.Buf == nil || tv.Buf.Filename != fnm {
+			continue
+		}
+		if fn := ge.FileNodeForFile(string(next), false); fn != nil {
+			ge.ViewFileNode(tv, i, fn)
+		}
+	}
+}
+
+// PaneLayouts returns the current layout of editor panes within each of the
+// main text view panels, for saving in the project prefs
+func (ge *GideView) PaneLayouts() []*gide.PaneLayout {
+	split := ge.SplitView()
+	pls := make([]*gide.PaneLayout, NTextPanels)
+	for i := range pls {
+		pls[i] = PaneLayoutOf(split.Child(TextView1Idx + i))
+	}
+	return pls
+}
+
+// PaneLayoutOf returns the layout of given editor pane
+func PaneLayoutOf(pane ki.Ki) *gide.PaneLayout {
+	pl := &gide.PaneLayout{}
+	if psv, ok := pane.(*gi.SplitView); ok {
+		pl.Dim = psv.Dim
+		pl.Splits = append([]float32{}, psv.Splits...)
+		for _, k := range psv.Kids {
+			pl.Panes = append(pl.Panes, PaneLayoutOf(k))
+		}
+	}
+	return pl
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Tabs
+
+// TabByName returns a tab with given name, nil if not found.
+func (ge *GideView) TabByName(label string) gi.Node2D {
+	tv := ge.Tabs()
+	return tv.TabByName(label)
+}
+
+// TabByNameTry returns a tab with given name, error if not found.
+func (ge *GideView) TabByNameTry(label string) (gi.Node2D, error) {
+	tv := ge.Tabs()
+	return tv.TabByNameTry(label)
+}
+
+// SelectTabByName Selects given main tab, and returns all of its contents as well.
+func (ge *GideView) SelectTabByName(label string) gi.Node2D {
+	tv := ge.Tabs()
+	if tv == nil {
+		return nil
+	}
+	return tv.SelectTabByName(label)
+}
+
+// RecycleTab returns a tab with given name, first by looking for an existing one,
+// and if not found, making a new one with widget of given type.
+// If sel, then select it.  returns widget for tab.
+func (ge *GideView) RecycleTab(label string, typ reflect.Type, sel bool) gi.Node2D {
+	tv := ge.Tabs()
+	if tv == nil {
+		return nil
+	}
+	ge.LazyTabs.Config(label) // before the caller uses it
+	return tv.RecycleTab(label, typ, sel)
+}
+
+// AddLazyTab adds a tab with given label and widget type, if there is none,
+// whose contents are configured by given function when it is first selected
+// (see gide.LazyTabs), with optional data, e.g., its session state
+func (ge *GideView) AddLazyTab(label string, typ reflect.Type, cfg func(), data interface{}) {
+	tv := ge.Tabs()
+	if tv == nil {
+		return
+	}
+	if _, err := tv.TabByNameTry(label); err == nil {
+		return
+	}
+	tv.RecycleTab(label, typ, false)
+	ge.LazyTabs.Add(label, cfg, data)
+}
+
+// RecycleTabTextView returns a tab with given
+// name, first by looking for an existing one, and if not found, making a new
+// one with a Layout and then a TextView in it.  if sel, then select it.
+// returns widget
+func (ge *GideView) RecycleTabTextView(label string, sel bool) *giv.TextView {
 	retab := ge.RecycleTab(label, gi.KiT_Layout, sel)
 	if retab == nil {
 		return nil
@@ -1494,6 +2595,90 @@ func (ge *GideView) ExecCmdNameFileName(fn string, cmdNm gide.CmdName, sel bool,
 	cmd.Run(ge, cbuf)
 }
 
+// GenerateCmdName is the name of the command running a single
+// //go:generate directive, and its output tab
+var GenerateCmdName = "Generate Go Directive"
+
+// RunGenerate runs the //go:generate directive at given 0-based line of
+// given file, with go generate -run selecting only it, showing the output
+// in the command tab, with its problems
+func (ge *GideView) RunGenerate(fpath string, ln int) {
+	tb := ge.TextBufForFile(fpath, false)
+	if tb == nil {
+		return
+	}
+	dln := string(tb.BytesLine(ln))
+	if !gide.IsGenerateDirective([]byte(dln)) {
+		return
+	}
+	cmd := &gide.Command{Name: GenerateCmdName, Desc: "run the go:generate directive " + dln, Lang: filecat.Go,
+		Cmds: []gide.CmdAndArgs{{Cmd: "go", Args: []string{"generate", "-run", gide.GenerateRunRegexp(dln), "{FileName}"}}},
+		Dir:  "{FileDirPath}", Wait: gide.CmdNoWait, Focus: gide.CmdNoFocus, Confirm: gide.CmdNoConfirm}
+	ge.SaveAllCheck(true, func() { // true = cancel option
+		ge.ArgVals.Set(fpath, &ge.Prefs, nil)
+		cbuf, _, _ := ge.RecycleCmdTab(cmd.Name, true, true)
+		cmd.Run(ge, cbuf)
+	})
+}
+
+// TestAtCmdName is the name of the command running the test at the
+// cursor
+var TestAtCmdName = "Test At Cursor"
+
+// RunTestAt runs the test, benchmark or subtest enclosing given 0-based
+// line of given _test.go file, with the output shown as for the other
+// commands
+func (ge *GideView) RunTestAt(fpath string, ln int) {
+	ta := gide.TestAtBufLine(ge.TextBufForFile(fpath, false), ln)
+	if ta == nil {
+		return
+	}
+	cmd := &gide.Command{Name: TestAtCmdName, Desc: "run the test " + ta.Name(), Lang: filecat.Go,
+		Cmds: []gide.CmdAndArgs{{Cmd: "go", Args: ta.TestArgs()}},
+		Dir:  "{FileDirPath}", Wait: gide.CmdNoWait, Focus: gide.CmdNoFocus, Confirm: gide.CmdNoConfirm}
+	ge.SaveAllCheck(true, func() { // true = cancel option
+		ge.ArgVals.Set(fpath, &ge.Prefs, nil)
+		cbuf, _, _ := ge.RecycleCmdTab(cmd.Name, true, true)
+		cmd.Run(ge, cbuf)
+	})
+}
+
+// DebugTestAt runs the debugger on the test, benchmark or subtest
+// enclosing given 0-based line of given _test.go file
+func (ge *GideView) DebugTestAt(fpath string, ln int) {
+	ta := gide.TestAtBufLine(ge.TextBufForFile(fpath, false), ln)
+	if ta == nil {
+		return
+	}
+	ge.DebugTestArgs(fpath, ta.DebugArgs())
+}
+
+// DebugTestArgs runs the debugger on the tests of the package of given
+// _test.go file, with given args of the test binary, e.g., -test.run --
+// they are added to the debug args only for this run
+func (ge *GideView) DebugTestArgs(tstPath string, args []string) {
+	pars := &ge.Prefs.Debug
+	oargs := pars.Args
+	dargs := append([]string{}, oargs...)
+	hasDash := false
+	for _, a := range dargs {
+		if a == "--" {
+			hasDash = true
+		}
+	}
+	if !hasDash {
+		dargs = append(dargs, "--")
+	}
+	pars.Args = append(dargs, args...)
+	pars.Mode = gidebug.Test
+	dir := filepath.Base(filepath.Dir(tstPath))
+	dv := ge.RecycleTab("Debug "+dir, gide.KiT_DebugView, true).Embed(gide.KiT_DebugView).(*gide.DebugView)
+	dv.Config(ge, ge.Prefs.MainLang, tstPath)
+	pars.Args = oargs
+	ge.FocusOnPanel(TabsIdx)
+	ge.CurDbg = dv
+}
+
 // ExecCmds gets list of available commands for current active file, as a submenu-func
 func ExecCmds(it interface{}, vp *gi.Viewport2D) []string {
 	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
@@ -1506,7 +2691,7 @@ func ExecCmds(it interface{}, vp *gi.Viewport2D) []string {
 	}
 	var cmds []string
 
-	vc := ge.VersCtrl()
+	vc := ge.FileVersCtrl(ge.ActiveVcsDir())
 	if ge.ActiveLang == filecat.NoSupport {
 		cmds = gide.AvailCmds.FilterCmdNames(ge.Prefs.MainLang, vc)
 	} else {
@@ -1535,7 +2720,7 @@ func (ge *GideView) ExecCmd() {
 		return
 	}
 	var cmds []string
-	vc := ge.VersCtrl()
+	vc := ge.FileVersCtrl(ge.ActiveVcsDir())
 	if ge.ActiveLang == filecat.NoSupport {
 		cmds = gide.AvailCmds.FilterCmdNames(ge.Prefs.MainLang, vc)
 	} else {
@@ -1560,7 +2745,7 @@ func (ge *GideView) ExecCmd() {
 // and shows output in Tab with name of command
 func (ge *GideView) ExecCmdFileNode(fn *giv.FileNode) {
 	lang := fn.Info.Sup
-	vc := ge.VersCtrl()
+	vc := ge.FileVersCtrl(string(fn.FPath))
 	cmds := gide.AvailCmds.FilterCmdNames(lang, vc)
 	gi.StringsChooserPopup(cmds, "", ge, func(recv, send ki.Ki, sig int64, data interface{}) {
 		ac := send.(*gi.Action)
@@ -1617,22 +2802,354 @@ func (ge *GideView) Run() {
 	ge.ExecCmds(ge.Prefs.RunCmds, true, true)
 }
 
-// Commit commits the current changes using relevant VCS tool.
-// Checks for VCS setting and for unsaved files.
-func (ge *GideView) Commit() {
-	vc := ge.VersCtrl()
-	if vc == "" {
-		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Version Control System Found", Prompt: fmt.Sprintf("No version control system detected in file system, or defined in project prefs -- define in project prefs if viewing a sub-directory within a larger repository")}, gi.AddOk, gi.NoCancel, nil, nil)
+// WebPreview opens the active file (if it is within the project) or the
+// project root in the browser, served by a local web server that is started
+// if not already running -- the html pages reload when files are saved.
+func (ge *GideView) WebPreview() {
+	if !ge.WebServer.IsRunning() {
+		err := ge.WebServer.Start(string(ge.ProjRoot), ge.Prefs.WebPort)
+		if err != nil {
+			gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Web Preview Failed", Prompt: fmt.Sprintf("Could not start the web preview server: %v", err)}, gi.AddOk, gi.NoCancel, nil, nil)
+			return
+		}
+	}
+	fnm := ""
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+		fnm = string(tv.Buf.Filename)
+	}
+	ur := ge.WebServer.URL(fnm)
+	ge.SetStatus(fmt.Sprintf("Web preview at: %v", ur))
+	oswin.TheApp.OpenURL(ur)
+}
+
+// WebPreviewStop stops the web preview server
+func (ge *GideView) WebPreviewStop() {
+	ge.WebServer.Stop()
+	ge.SetStatus("Web preview stopped")
+}
+
+// LiveRunStart starts the live-reload run of the RunExec: it is built and
+// run, and rebuilt and restarted whenever the Go files of the project
+// change, with the restart events and its output in the Live Run tab
+func (ge *GideView) LiveRunStart() {
+	if ge.LiveRun.IsOn() {
+		ge.SelectTabByName(gide.LiveRunCmdName)
+		return
+	}
+	if !ge.Prefs.RunExecIsExec() && len(gide.LiveRunCmds(&ge.Prefs)) == 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Build Commands", Prompt: "Live run needs BuildCmds in the Project Preferences, or a Go project, to build the RunExec"}, gi.AddOk, gi.NoCancel, nil, nil)
 		return
 	}
 	ge.SaveAllCheck(true, func() { // true = cancel option
-		ge.CommitNoChecks()
+		ge.ArgVals.Set(string(ge.ActiveFilename), &ge.Prefs, nil)
+		cbuf, _, _ := ge.RecycleCmdTab(gide.LiveRunCmdName, true, true)
+		ge.LiveRun.Start(ge, cbuf)
 	})
 }
 
+// Terminal opens the Terminal tab: a shell, running in a pseudo-terminal in
+// the project root, with GIDE_FILE, GIDE_DIR and GIDE_PROJ set to the
+// active file, its directory and the project root
+func (ge *GideView) Terminal() {
+	if ge.IsEmpty() {
+		return
+	}
+	tv := ge.RecycleTab(gide.TermCmdName, gide.KiT_TermView, true).Embed(gide.KiT_TermView).(*gide.TermView)
+	tv.Config(ge, gide.TermCmdName) // focuses the terminal
+}
+
+// ActiveTermView returns the terminal to send text to: that of the selected
+// tab, or else the first terminal tab -- opening the Terminal if none
+func (ge *GideView) ActiveTermView() *gide.TermView {
+	tabs := ge.Tabs()
+	if ct, _, ok := tabs.CurTab(); ok {
+		if tmv, ok := ct.Embed(gide.KiT_TermView).(*gide.TermView); ok {
+			return tmv
+		}
+	}
+	for i := 0; i < tabs.NTabs(); i++ {
+		if widg, _, ok := tabs.TabAtIndex(i); ok {
+			if tmv, ok := widg.Embed(gide.KiT_TermView).(*gide.TermView); ok {
+				tabs.SelectTabIndex(i)
+				return tmv
+			}
+		}
+	}
+	tv := ge.ActiveTextView()
+	ge.Terminal()
+	if tv != nil {
+		tv.GrabFocus() // keep editing
+	}
+	tmv, _ := tabs.TabByName(gide.TermCmdName).Embed(gide.KiT_TermView).(*gide.TermView)
+	return tmv
+}
+
+// SendToTerminal writes given text to the active terminal, as if typed,
+// ending with a newline -- opening the Terminal if none
+func (ge *GideView) SendToTerminal(txt string) error {
+	tmv := ge.ActiveTermView()
+	if tmv == nil {
+		return fmt.Errorf("no terminal to send to")
+	}
+	return tmv.SendText(txt)
+}
+
+// SendSelectionToTerminal sends the selection of the active view, or else
+// the line of the cursor, to the active terminal, for running it in the
+// shell or a REPL (python, psql, etc) running there -- after sending a line,
+// the cursor moves to the next one
+func (ge *GideView) SendSelectionToTerminal() {
+	if tv := ge.ActiveTextView(); tv != nil {
+		tv.SendToTerminal()
+	}
+}
+
+// Repl pops up a menu to select a REPL, of AvailRepls, and opens its
+// console
+func (ge *GideView) Repl() {
+	if ge.IsEmpty() {
+		return
+	}
+	gi.StringsChooserPopup(gide.AvailRepls().Names(), "", ge, func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		if rp, ok := gide.AvailRepls().ByName(ac.Text); ok {
+			rv := ge.OpenRepl(rp)
+			rv.InView().GrabFocus()
+		}
+	})
+}
+
+// OpenRepl opens the console of given REPL, starting it if not running
+func (ge *GideView) OpenRepl(rp *gide.Repl) *gide.ReplView {
+	rv := ge.RecycleTab(gide.ReplTabName(rp.Name), gide.KiT_ReplView, true).Embed(gide.KiT_ReplView).(*gide.ReplView)
+	rv.Config(ge, rp)
+	return rv
+}
+
+// GoScratch opens the Go scratchpad, in which Go snippets are run with
+// Control+Enter, showing the value of each expression at the end of its
+// line -- its text is kept in the project
+func (ge *GideView) GoScratch() {
+	svi := ge.RecycleTab(gide.ScratchTabName, gide.KiT_ScratchView, true)
+	if svi == nil {
+		return
+	}
+	sv := svi.Embed(gide.KiT_ScratchView).(*gide.ScratchView)
+	sv.Config(ge)
+	sv.CodeView().GrabFocus()
+}
+
+// EvalInRepl evaluates given code in the REPL console of given language,
+// opening it if not open
+func (ge *GideView) EvalInRepl(lang filecat.Supported, code string) error {
+	rp, ok := gide.AvailRepls().ByLang(lang)
+	if !ok {
+		return fmt.Errorf("no REPL for %v -- add one to the Repls preferences", lang)
+	}
+	tv := ge.ActiveTextView()
+	rv := ge.OpenRepl(rp)
+	if tv != nil {
+		tv.GrabFocus() // keep editing
+	}
+	return rv.Eval(code)
+}
+
+// EvalSelectionInRepl evaluates the selection of the active view, or else
+// the line of the cursor, in the REPL console of the language of its file --
+// after evaluating a line, the cursor moves to the next one
+func (ge *GideView) EvalSelectionInRepl() {
+	if tv := ge.ActiveTextView(); tv != nil {
+		tv.EvalInRepl()
+	}
+}
+
+// NewTerminal opens a new terminal tab, named Terminal 2, Terminal 3, etc --
+// terminals can be renamed, and split into panes, from their toolbar
+func (ge *GideView) NewTerminal() {
+	if ge.IsEmpty() {
+		return
+	}
+	tabs := ge.Tabs()
+	name := gide.TermCmdName
+	for i := 2; ; i++ {
+		if _, err := tabs.TabIndexByName(name); err != nil {
+			break
+		}
+		name = fmt.Sprintf("%v %d", gide.TermCmdName, i)
+	}
+	tv := ge.RecycleTab(name, gide.KiT_TermView, true).Embed(gide.KiT_TermView).(*gide.TermView)
+	tv.Config(ge, name)
+}
+
+// SerialConsole shows the Serial Console tab, with the output of the serial
+// port of the project, e.g., of a microcontroller, connecting to it
+func (ge *GideView) SerialConsole() {
+	if ge.IsEmpty() {
+		return
+	}
+	sv := ge.RecycleTab(gide.SerialCmdName, gide.KiT_SerialView, true).Embed(gide.KiT_SerialView).(*gide.SerialView)
+	sv.Config(ge)
+	if !sv.IsOpen() {
+		sv.Connect()
+	}
+	sv.SendField().GrabFocus()
+}
+
+// PluginCommand pops up a menu to choose a command added by the plugins,
+// sent to its plugin
+func (ge *GideView) PluginCommand() {
+	pcs := ge.Plugins.Commands()
+	if len(pcs) == 0 {
+		ge.SetStatus(fmt.Sprintf("no plugin commands -- plugins are in %v, see New Plugin", gide.PluginsDir()))
+		return
+	}
+	gi.StringsChooserPopup(pluginCmdLabels(pcs), "", ge.ActiveTextView(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		idx := ac.Data.(int)
+		ge.Plugins.RunCommand(&pcs[idx])
+	})
+}
+
+// pluginCmdLabels returns the labels of given plugin commands
+func pluginCmdLabels(pcs []gide.PluginCmd) []string {
+	lbls := make([]string, len(pcs))
+	for i, pc := range pcs {
+		lbls[i] = pc.Label()
+	}
+	return lbls
+}
+
+// NewPlugin creates a plugin of given name in the plugins directory, from
+// the lua template, and opens it -- it is started by Reload Plugins
+func (ge *GideView) NewPlugin(name string) {
+	fn, err := gide.NewPlugin(name, gide.PluginLuaExt)
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could Not Create Plugin", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.NextViewFile(gi.FileName(fn))
+}
+
+// ReloadPlugins restarts the plugins of the plugins directory
+func (ge *GideView) ReloadPlugins() {
+	if ge.IsEmpty() {
+		return
+	}
+	ge.Plugins.Start(ge)
+	ge.SetStatus(fmt.Sprintf("plugins started from: %v", gide.PluginsDir()))
+}
+
+// WSLDistroNames gets the list of WSL distros, after Off, as a submenu-func
+func WSLDistroNames(it interface{}, vp *gi.Viewport2D) []string {
+	dl, _ := gide.WSLDistros()
+	return append([]string{"Off"}, dl...)
+}
+
+// SetWSLDistro runs the commands of the project in the WSL distro of given
+// name, with the paths translated, or on Windows itself for Off
+func (ge *GideView) SetWSLDistro(name string) {
+	if _, err := gide.WSLDistros(); err != nil {
+		ge.SetStatus(err.Error())
+		return
+	}
+	wp := &ge.Prefs.WSL
+	if name == "Off" {
+		wp.Run = false
+		ge.SetStatus("commands run on Windows")
+	} else {
+		wp.Run = true
+		wp.Distro = name
+		ge.SetStatus(fmt.Sprintf("commands run in WSL: %v", name))
+	}
+	ge.Changed = true
+}
+
+// SSHTerminal pops up a menu to select a host, of the SSHHosts preferences
+// and of ~/.ssh/config, and opens a terminal tab with an SSH session to it
+func (ge *GideView) SSHTerminal() {
+	if ge.IsEmpty() {
+		return
+	}
+	hosts := gide.SSHHostNames()
+	if len(hosts) == 0 {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No SSH Hosts", Prompt: "There are no hosts: add them to the SSHHosts preferences, or to your ~/.ssh/config"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	gi.StringsChooserPopup(hosts, "", ge, func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		ge.OpenSSHTerminal(ac.Text)
+	})
+}
+
+// OpenSSHTerminal opens a terminal tab with an SSH session to the host of
+// given name, named SSH host, SSH host 2, etc
+func (ge *GideView) OpenSSHTerminal(host string) {
+	tabs := ge.Tabs()
+	name := "SSH " + host
+	for i := 2; ; i++ {
+		if _, err := tabs.TabIndexByName(name); err != nil {
+			break
+		}
+		name = fmt.Sprintf("SSH %v %d", host, i)
+	}
+	tv := ge.RecycleTab(name, gide.KiT_TermView, true).Embed(gide.KiT_TermView).(*gide.TermView)
+	tv.ConfigSSH(ge, name, host, false, nil)
+}
+
+// LiveRunStop stops the live-reload run, and the RunExec
+func (ge *GideView) LiveRunStop() {
+	ge.LiveRun.Stop()
+}
+
+// LiveRunPause pauses or resumes the reloading of the live run -- changes
+// made while paused are rebuilt on resume
+func (ge *GideView) LiveRunPause() {
+	ge.LiveRun.SetPaused(!ge.LiveRun.Paused)
+}
+
+// WebPreviewReload reloads the pages open from the web preview server, if running
+func (ge *GideView) WebPreviewReload() {
+	if ge.WebServer.IsRunning() {
+		ge.WebServer.Reload()
+	}
+}
+
+// Commit commits the current changes using relevant VCS tool.
+// Checks for VCS setting and for unsaved files -- opens the commit panel
+// to stage the changes and enter the message (see CommitPanel), if the
+// VCS is supported by gide.VcsRepo.  The repository is the one the active
+// file is in (which can be nested within the project), else the project one.
+func (ge *GideView) Commit() {
+	dir := ge.ActiveVcsDir()
+	vc := ge.FileVersCtrl(dir)
+	if vc == "" {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No Version Control System Found", Prompt: fmt.Sprintf("No version control system detected in file system, or defined in project prefs -- define in project prefs if viewing a sub-directory within a larger repository")}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.SaveAllCheck(true, func() { // true = cancel option
+		if repo, err := gide.NewVcsRepo(dir); err == nil {
+			ge.CommitPanel(repo)
+			return
+		}
+		ge.CommitNoChecks()
+	})
+}
+
+// CommitPanel shows the Commit tab, for staging the changed files of the
+// given repository, reviewing their diffs, and committing them
+func (ge *GideView) CommitPanel(repo gide.VcsRepo) {
+	if ge.IsEmpty() {
+		return
+	}
+	cv := ge.RecycleTab("Commit", gide.KiT_CommitView, true).Embed(gide.KiT_CommitView).(*gide.CommitView)
+	cv.Config(ge, repo)
+	cv.Refresh()
+	ge.FocusOnPanel(TabsIdx)
+}
+
 // CommitNoChecks does the commit without any further checks for VCS, and unsaved files
 func (ge *GideView) CommitNoChecks() {
-	vc := ge.VersCtrl()
+	vc := ge.FileVersCtrl(ge.ActiveVcsDir())
 	cmds := gide.AvailCmds.FilterCmdNames(ge.ActiveLang, vc)
 	cmdnm := ""
 	for _, cm := range cmds {
@@ -1671,6 +3188,310 @@ func (ge *GideView) VCSUpdateAll() {
 	ge.FilesView.UpdateEnd(updt)
 }
 
+// IsGit returns true if the project is in a git repository
+func (ge *GideView) IsGit() bool {
+	return ge.VersCtrl() == "git"
+}
+
+// UpdateBranch updates the current branch of the project repository shown
+// in the statusbar
+func (ge *GideView) UpdateBranch() {
+	br := ""
+	if ge.IsGit() {
+		br, _ = gide.GitCurBranch(string(ge.ProjRoot))
+	}
+	if br == ge.Branch {
+		return
+	}
+	ge.Branch = br
+	sb := ge.StatusBar()
+	if sb == nil {
+		return
+	}
+	bb, ok := sb.ChildByName("sb-branch", 1).(*gi.MenuButton)
+	if !ok {
+		return
+	}
+	updt := sb.UpdateStart()
+	bb.SetText(ge.BranchLabel())
+	sb.UpdateEnd(updt)
+}
+
+// BranchLabel returns the label of the statusbar branch button
+func (ge *GideView) BranchLabel() string {
+	if ge.Branch == "" {
+		return "no branch"
+	}
+	return "branch: " + ge.Branch
+}
+
+// LineEndsMenu makes the menu of the statusbar button of the line endings
+// of the active file, to convert them
+func (ge *GideView) LineEndsMenu(obj ki.Ki, m *gi.Menu) {
+	*m = gi.Menu{}
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	cur := gide.BufLineEnds(tv.Buf)
+	for _, le := range []gide.LineEnds{gide.LineEndsLF, gide.LineEndsCRLF} {
+		lbl := "  Convert To " + le.Label()
+		if le == cur {
+			lbl = "* " + le.Label()
+		}
+		m.AddAction(gi.ActOpts{Label: lbl, Data: le}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			ac := send.(*gi.Action)
+			ge.ConvertLineEnds(ac.Data.(gide.LineEnds))
+		})
+	}
+}
+
+// BranchMenu makes the menu of the statusbar branch button: the branches to
+// switch to (the current one marked with *), then those of the remotes, and
+// actions to create a new branch and fetch from the remotes
+func (ge *GideView) BranchMenu(obj ki.Ki, m *gi.Menu) {
+	*m = gi.Menu{}
+	if !ge.IsGit() {
+		ac := m.AddAction(gi.ActOpts{Label: "Not in a git repository"}, nil, nil)
+		ac.SetInactive()
+		return
+	}
+	local, remote, err := gide.GitBranches(string(ge.ProjRoot))
+	if err != nil {
+		ge.SetStatus(err.Error())
+	}
+	brfun := func(recv, send ki.Ki, sig int64, data interface{}) {
+		ac := send.(*gi.Action)
+		ge.SwitchBranch(ac.Data.(string))
+	}
+	for _, br := range local {
+		lbl := "  " + br
+		if br == ge.Branch {
+			lbl = "* " + br
+		}
+		m.AddAction(gi.ActOpts{Label: lbl, Data: br}, ge.This(), brfun)
+	}
+	if len(remote) > 0 {
+		m.AddSeparator("remote-sep")
+		for _, br := range remote {
+			m.AddAction(gi.ActOpts{Label: "  " + br + " (remote)", Data: br}, ge.This(), brfun)
+		}
+	}
+	m.AddSeparator("cmd-sep")
+	m.AddAction(gi.ActOpts{Label: "New Branch..."}, ge.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			giv.CallMethod(ge, "NewBranch", ge.Viewport)
+		})
+	m.AddAction(gi.ActOpts{Label: "Fetch / Prune"}, ge.This(),
+		func(recv, send ki.Ki, sig int64, data interface{}) {
+			ge.FetchPrune()
+		})
+}
+
+// BranchNames gets list of the local branches of the project repository, as a submenu-func
+func BranchNames(it interface{}, vp *gi.Viewport2D) []string {
+	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ok || !ge.IsGit() {
+		return nil
+	}
+	local, _, _ := gide.GitBranches(string(ge.ProjRoot))
+	return local
+}
+
+// SwitchBranch switches the project repository to given branch (which is
+// created tracking the remote one if it is only on a remote), after saving
+// any unsaved files, using the Switch Branch Git command
+func (ge *GideView) SwitchBranch(branch string) {
+	if branch == "" || branch == ge.Branch {
+		return
+	}
+	ge.SaveAllCheck(true, func() { // true = cancel option
+		ge.ExecBranchCmd("Switch Branch Git", branch)
+		if ge.Branch != branch {
+			gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Switch Branch Failed", Prompt: fmt.Sprintf("Could not switch to branch: %v -- see the Switch Branch Git tab for the output of git (e.g., local changes that would be overwritten must be committed or stashed first)", branch)}, gi.AddOk, gi.NoCancel, nil, nil)
+		}
+	})
+}
+
+// NewBranch creates a new branch of given name from the current one in the
+// project repository, and switches to it, using the New Branch Git command
+func (ge *GideView) NewBranch(branch string) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return
+	}
+	ge.ExecBranchCmd("New Branch Git", branch)
+	if ge.Branch != branch {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "New Branch Failed", Prompt: fmt.Sprintf("Could not create branch: %v -- see the New Branch Git tab for the output of git", branch)}, gi.AddOk, gi.NoCancel, nil, nil)
+	}
+}
+
+// FetchPrune fetches the branches of all the remotes of the project
+// repository, removing those deleted there, using the Fetch Prune Git command
+func (ge *GideView) FetchPrune() {
+	if !ge.IsGit() {
+		return
+	}
+	ge.ExecCmdName("Fetch Prune Git", true, true)
+}
+
+// TagNames gets the tags of the project repository, as a submenu-func
+func TagNames(it interface{}, vp *gi.Viewport2D) []string {
+	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ok || !ge.IsGit() {
+		return nil
+	}
+	tags, _ := gide.GitTags(string(ge.ProjRoot))
+	return tags
+}
+
+// ListTags shows the tags of the project repository, highest version
+// first, with the first line of their messages
+func (ge *GideView) ListTags() {
+	if !ge.IsGit() {
+		return
+	}
+	desc, err := gide.GitTagsDesc(string(ge.ProjRoot))
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "List Tags Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	if desc == "" {
+		desc = "no tags"
+	}
+	giv.TextViewDialog(ge.Viewport, []byte(desc), giv.DlgOpts{Title: "Tags: " + ge.Nm, Ok: true})
+}
+
+// NewTag creates an annotated tag of given name and message for the
+// current commit, using the New Tag Git command
+func (ge *GideView) NewTag(tag, msg string) bool {
+	tag = strings.TrimSpace(tag)
+	if tag == "" || !ge.IsGit() {
+		return false
+	}
+	if strings.TrimSpace(msg) == "" {
+		msg = tag
+	}
+	ge.ExecTagCmd("New Tag Git", tag, msg)
+	tags, _ := gide.GitTags(string(ge.ProjRoot))
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "New Tag Failed", Prompt: fmt.Sprintf("Could not create tag: %v -- see the New Tag Git tab for the output of git", tag)}, gi.AddOk, gi.NoCancel, nil, nil)
+	return false
+}
+
+// DeleteTag deletes given tag from the project repository (not from the
+// remotes), using the Delete Tag Git command
+func (ge *GideView) DeleteTag(tag string) {
+	if tag == "" || !ge.IsGit() {
+		return
+	}
+	ge.ExecTagCmd("Delete Tag Git", tag, "")
+}
+
+// PushTag pushes given tag to the origin remote, using the Push Tag Git
+// command -- all of the tags if tag is empty (Push Tags Git)
+func (ge *GideView) PushTag(tag string) {
+	if !ge.IsGit() {
+		return
+	}
+	if tag == "" {
+		ge.ExecCmdName("Push Tags Git", true, true)
+		return
+	}
+	ge.ExecTagCmd("Push Tag Git", tag, "")
+}
+
+// ExecTagCmd runs given tag command with given tag and message as its
+// prompt strings
+func (ge *GideView) ExecTagCmd(cmdNm gide.CmdName, tag, msg string) {
+	ge.SetArgVarVals() // need to set before setting prompt string below..
+	ge.ArgVals["{PromptString1}"] = tag
+	ge.ArgVals["{PromptString2}"] = msg
+	gide.CmdNoUserPrompt = true // don't re-prompt!
+	ge.ExecCmdName(cmdNm, true, true)
+}
+
+// TagRelease tags the current commit as a release: it suggests the next
+// patch version after the latest semantic version tag (with the next minor
+// and major ones in the prompt), then prompts for the message, and offers
+// to push the new tag to the origin remote
+func (ge *GideView) TagRelease() {
+	if !ge.IsGit() {
+		return
+	}
+	tags, _ := gide.GitTags(string(ge.ProjRoot))
+	next := "v0.1.0"
+	prompt := "There are no version tags yet -- enter the first release version"
+	if last, ok := gide.LatestSemVer(tags); ok {
+		patch, minor, major := last.Next()
+		next = patch.String()
+		prompt = fmt.Sprintf("Latest version is: <b>%v</b> -- next patch: <b>%v</b>, minor: <b>%v</b>, major: <b>%v</b>", last, patch, minor, major)
+	}
+	gi.StringPromptDialog(ge.Viewport, next, "release version",
+		gi.DlgOpts{Title: "Tag Release", Prompt: prompt},
+		ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			tag := strings.TrimSpace(gi.StringPromptDialogValue(send.(*gi.Dialog)))
+			if tag == "" {
+				return
+			}
+			gi.StringPromptDialog(ge.Viewport, "Release "+tag, "release message",
+				gi.DlgOpts{Title: "Release Message", Prompt: "Message of the annotated tag " + tag},
+				ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+					if sig != int64(gi.DialogAccepted) {
+						return
+					}
+					msg := gi.StringPromptDialogValue(send.(*gi.Dialog))
+					if !ge.NewTag(tag, msg) {
+						return
+					}
+					gi.ChoiceDialog(ge.Viewport, gi.DlgOpts{Title: "Push Release Tag",
+						Prompt: fmt.Sprintf("Tagged the current commit as: <b>%v</b> -- push the tag to the origin remote now?", tag)},
+						[]string{"Push Tag", "Not Now"},
+						ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+							if sig == 0 {
+								ge.PushTag(tag)
+							}
+						})
+				})
+		})
+}
+
+// PullRequests opens the Pull Requests panel, listing the open pull / merge
+// requests of the GitHub or GitLab repository of the project
+func (ge *GideView) PullRequests() {
+	if !ge.IsGit() {
+		return
+	}
+	root := string(ge.ProjRoot)
+	fr, ok := gide.DetectForgeRepo(root)
+	if !ok {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No GitHub / GitLab Remote", Prompt: "None of the remotes of the project repository is on GitHub or GitLab -- self-hosted GitLab servers can be added in Preferences / Forge"}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	fv := ge.RecycleTab("Pull Requests", gide.KiT_ForgeView, true).Embed(gide.KiT_ForgeView).(*gide.ForgeView)
+	fv.Config(ge, fr, root)
+	fv.Refresh()
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// ExecBranchCmd runs given branch command with given branch as its prompt
+// string, waiting for it to finish, and then updates the branch and files
+func (ge *GideView) ExecBranchCmd(cmdNm gide.CmdName, branch string) {
+	ge.SetArgVarVals() // need to set before setting prompt string below..
+	ge.ArgVals["{PromptString1}"] = branch
+	gide.CmdNoUserPrompt = true // don't re-prompt!
+	ge.ExecCmdName(cmdNm, true, true)
+	ge.UpdateBranch()
+	ge.UpdateFiles()
+}
+
 // VCSLog shows the VCS log of commits for this file, optionally with a
 // since date qualifier: If since is non-empty, it should be
 // a date-like expression that the VCS will understand, such as
@@ -1689,226 +3510,1193 @@ func (ge *GideView) VCSLog(since string) (vci.Log, error) {
 		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "No VCS Repository", Prompt: "No VCS Repository found in current active file or Root path: Open a file in a repository and try again"}, gi.AddOk, gi.NoCancel, nil, nil)
 		return nil, errors.New("No VCS Repository found in current active file or Root path")
 	}
-	return ond.LogVcs(true, since)
+	return ond.LogVcs(true, since)
+}
+
+// FileHistory shows the history of commits of the active file in the File
+// History tab, for diffs between its revisions, or against the working
+// copy, and viewing the file at a revision
+func (ge *GideView) FileHistory() {
+	atv := ge.ActiveTextView()
+	if atv == nil || atv.Buf == nil {
+		return
+	}
+	ge.FileHistoryPath(string(atv.Buf.Filename))
+}
+
+// FileHistoryPath shows the history of commits of given file in the File
+// History tab
+func (ge *GideView) FileHistoryPath(fpath string) {
+	fn := ge.FileNodeForFile(fpath, false)
+	var repo vci.Repo
+	if fn != nil {
+		repo, _ = fn.Repo()
+	}
+	if repo == nil || fn.Info.Vcs == vci.Untracked {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Not in VCS Repository", Prompt: fmt.Sprintf("File: %v is not in a version control repository, or not yet added to it", fpath)}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	lg, err := repo.Log(fpath, "")
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "File History Failed", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	hv := ge.RecycleTab("File History", gide.KiT_FileHistView, true).Embed(gide.KiT_FileHistView).(*gide.FileHistView)
+	hv.Config(ge, repo, fpath, lg)
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// ViewTable shows the active file, if a delimited text file (.csv, .tsv),
+// as a table in a main tab
+func (ge *GideView) ViewTable() {
+	atv := ge.ActiveTextView()
+	if atv == nil || atv.Buf == nil {
+		return
+	}
+	ge.ViewTablePath(string(atv.Buf.Filename))
+}
+
+// ViewTablePath shows given delimited text file (.csv, .tsv) as a table in
+// a main tab: the cells edited are written back to the text of its buffer,
+// which is saved as usual
+func (ge *GideView) ViewTablePath(fpath string) {
+	if !gide.IsCSVFile(fpath) {
+		ge.SetStatus(fmt.Sprintf("not a delimited text file (.csv, .tsv): %v", fpath))
+		return
+	}
+	tb := ge.TextBufForFile(fpath, true)
+	if tb == nil {
+		ge.SetStatus(fmt.Sprintf("could not open: %v", fpath))
+		return
+	}
+	cvi := ge.RecycleTab(gide.CSVViewTabName(fpath), gide.KiT_CSVView, true)
+	if cvi == nil {
+		return
+	}
+	cv := cvi.Embed(gide.KiT_CSVView).(*gide.CSVView)
+	cv.Config(ge, fpath, tb)
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// DocStructure shows the structure of the active file, if a JSON or YAML
+// document, as a tree in the Structure tab: clicking on a value selects it
+func (ge *GideView) DocStructure() {
+	atv := ge.ActiveTextView()
+	if atv == nil || atv.Buf == nil {
+		return
+	}
+	if gide.DocStructLang(atv.Buf) == "" {
+		ge.SetStatus(fmt.Sprintf("not a JSON or YAML file: %v", atv.Buf.Filename))
+		return
+	}
+	dvi := ge.RecycleTab("Structure", gide.KiT_DocStructView, true)
+	if dvi == nil {
+		return
+	}
+	dv := dvi.Embed(gide.KiT_DocStructView).(*gide.DocStructView)
+	dv.Config(ge, atv.Buf)
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// FormatJSON indents the active file, if a JSON document, with tabs or
+// spaces as set in the editor prefs
+func (ge *GideView) FormatJSON() {
+	ge.FormatJSONActive(false)
+}
+
+// MinifyJSON removes all the white space of the active file, if a JSON
+// document, as a single line
+func (ge *GideView) MinifyJSON() {
+	ge.FormatJSONActive(true)
+}
+
+// FormatJSONActive formats, or minifies, the active file if a JSON document,
+// as an edit that can be undone
+func (ge *GideView) FormatJSONActive(minify bool) {
+	atv := ge.ActiveTextView()
+	if atv == nil || atv.Buf == nil {
+		return
+	}
+	if gide.DocStructLang(atv.Buf) != "json" {
+		ge.SetStatus(fmt.Sprintf("not a JSON file: %v", atv.Buf.Filename))
+		return
+	}
+	if err := gide.FormatJSON(atv.Buf, minify); err != nil {
+		ge.SetStatus("could not format: " + html.EscapeString(err.Error()))
+		return
+	}
+	ge.SetStatus("formatted: " + string(atv.Buf.Filename))
+}
+
+// OpenConsoleTab opens a main tab displaying console output (stdout, stderr)
+func (ge *GideView) OpenConsoleTab() {
+	ctv := ge.RecycleTabTextView("Console", true)
+	if ctv == nil {
+		return
+	}
+	ctv.SetInactive()
+	if ctv.Buf == nil || ctv.Buf != gide.TheConsole.Buf {
+		ctv.SetBuf(gide.TheConsole.Buf)
+		gide.TheConsole.Buf.TextBufSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+			gee, _ := recv.Embed(KiT_GideView).(*GideView)
+			gee.SelectTabByName("Console")
+		})
+	}
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    TextView functions
+
+// CursorToHistPrev moves cursor to previous position on history list --
+// returns true if moved
+func (ge *GideView) CursorToHistPrev() bool {
+	tv := ge.ActiveTextView()
+	return tv.CursorToHistPrev()
+}
+
+// CursorToHistNext moves cursor to next position on history list --
+// returns true if moved
+func (ge *GideView) CursorToHistNext() bool {
+	tv := ge.ActiveTextView()
+	return tv.CursorToHistNext()
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Navigation History
+
+// SaveNavPos saves the cursor position in the active text view to the
+// navigation history, prior to jumping to another location
+func (ge *GideView) SaveNavPos() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	ge.NavHist.Save(gide.NavPos{Filename: tv.Buf.Filename, Pos: tv.CursorPos})
+}
+
+// NavGoTo views the file at given navigation location and moves the cursor
+// there, without saving to the navigation history
+func (ge *GideView) NavGoTo(np gide.NavPos) bool {
+	ge.NavHist.Moving = true
+	defer func() { ge.NavHist.Moving = false }()
+	tv, _, ok := ge.ViewFile(np.Filename)
+	if !ok || tv == nil {
+		return false
+	}
+	tv.SetCursorShow(np.Pos)
+	tv.GrabFocus()
+	return true
+}
+
+// NavBack moves back to the previous location in the navigation history,
+// which is saved across files when jumping to links, find results,
+// definitions, etc -- returns true if moved
+func (ge *GideView) NavBack() bool {
+	var cur gide.NavPos
+	tv := ge.ActiveTextView()
+	if tv != nil && tv.Buf != nil {
+		cur = gide.NavPos{Filename: tv.Buf.Filename, Pos: tv.CursorPos}
+	}
+	np, ok := ge.NavHist.Back(cur)
+	if !ok {
+		ge.SetStatus("At start of navigation history")
+		return false
+	}
+	return ge.NavGoTo(np)
+}
+
+// NavForward moves forward to the next location in the navigation history --
+// returns true if moved
+func (ge *GideView) NavForward() bool {
+	np, ok := ge.NavHist.Forward()
+	if !ok {
+		ge.SetStatus("At end of navigation history")
+		return false
+	}
+	return ge.NavGoTo(np)
+}
+
+// GoToLastEdit moves to the location of the last edit made in any file --
+// returns true if moved
+func (ge *GideView) GoToLastEdit() bool {
+	le := ge.NavHist.LastEdit
+	if le.Filename == "" {
+		ge.SetStatus("No edits made yet")
+		return false
+	}
+	ge.SaveNavPos()
+	return ge.NavGoTo(le)
+}
+
+// LookupFun is the completion system Lookup function that makes a custom
+// textview dialog that has option to edit resulting file.
+func (ge *GideView) LookupFun(data interface{}, text string, posLn, posCh int) (ld complete.Lookup) {
+	sfs := data.(*pi.FileStates)
+	if sfs == nil {
+		log.Printf("LookupFun: data is nil not FileStates or is nil - can't lookup\n")
+		return ld
+	}
+	lp, err := pi.LangSupport.Props(sfs.Sup)
+	if err != nil {
+		log.Printf("LookupFun: %v\n", err)
+		return ld
+	}
+	if lp.Lang == nil {
+		return ld
+	}
+
+	// note: must have this set to ture to allow viewing of AST
+	// must set it in pi/parse directly -- so it is changed in the fileparse too
+	parse.GuiActive = true // note: this is key for debugging -- runs slower but makes the tree unique
+
+	ld = lp.Lang.Lookup(sfs, text, lex.Pos{posLn, posCh})
+	if len(ld.Text) > 0 {
+		giv.TextViewDialog(nil, ld.Text, giv.DlgOpts{Title: "Lookup: " + text, Data: text})
+		return ld
+	}
+	if ld.Filename == "" {
+		return ld
+	}
+
+	txt, err := textbuf.FileBytes(ld.Filename)
+	if err != nil {
+		return ld
+	}
+	if ld.StLine > 0 {
+		lns := bytes.Split(txt, []byte("\n"))
+		comLn, comSt, comEd := textbuf.SupportedComments(ld.Filename)
+		ld.StLine = textbuf.PreCommentStart(lns, ld.StLine, comLn, comSt, comEd, 10) // just go back 10 max
+	}
+
+	prmpt := ""
+	if ld.EdLine > ld.StLine {
+		prmpt = fmt.Sprintf("%v [%d -- %d]", ld.Filename, ld.StLine, ld.EdLine)
+	} else {
+		prmpt = fmt.Sprintf("%v:%d", ld.Filename, ld.StLine)
+	}
+	opts := giv.DlgOpts{Title: "Lookup: " + text, Prompt: prmpt}
+
+	dlg, recyc := gi.RecycleStdDialog(prmpt, opts.ToGiOpts(), gi.NoOk, gi.NoCancel)
+	if recyc {
+		return ld
+	}
+	frame := dlg.Frame()
+	_, prIdx := dlg.PromptWidget(frame)
+
+	tb := &giv.TextBuf{}
+	tb.InitName(tb, "text-view-dialog-buf")
+	tb.Filename = gi.FileName(ld.Filename)
+	tb.Hi.Style = gi.Prefs.Colors.HiStyle
+	tb.Opts.LineNos = ge.Prefs.Editor.LineNos
+	tb.Stat() // update markup
+
+	tlv := frame.InsertNewChild(gi.KiT_Layout, prIdx+1, "text-lay").(*gi.Layout)
+	tlv.SetProp("width", units.NewCh(80))
+	tlv.SetProp("height", units.NewEm(40))
+	tlv.SetStretchMax()
+	tv := giv.AddNewTextView(tlv, "text-view")
+	tv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
+	tv.SetInactive()
+	tv.SetProp("font-family", gi.Prefs.MonoFont)
+	tv.SetBuf(tb)
+	tv.CursorPos = lex.Pos{Ln: ld.StLine}
+	tv.ScrollToCursorOnRender = true
+
+	tb.SetText(txt) // calls remarkup
+
+	bbox, _ := dlg.ButtonBox(frame)
+	if bbox == nil {
+		bbox = dlg.AddButtonBox(frame)
+	}
+	ofb := gi.AddNewButton(bbox, "open-file")
+	ofb.SetText("Open File")
+	ofb.SetIcon("file-open")
+	ofb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonClicked) {
+			ge.ViewFile(gi.FileName(ld.Filename))
+			dlg.Close()
+		}
+	})
+	cpb := gi.AddNewButton(bbox, "copy-to-clip")
+	cpb.SetText("Copy To Clipboard")
+	cpb.SetIcon("copy")
+	cpb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonClicked) {
+			ddlg := recv.Embed(gi.KiT_Dialog).(*gi.Dialog)
+			oswin.TheApp.ClipBoard(ddlg.Win.OSWin).Write(mimedata.NewTextBytes(txt))
+		}
+	})
+	dlg.UpdateEndNoSig(true) // going to be shown
+	dlg.Open(0, 0, ge.Viewport, nil)
+	return ld
+}
+
+//////////////////////////////////////////////////////////////////////////////////////
+//    Find / Replace
+
+// Find does Find / Replace in files, using given options and filters -- opens up a
+// main tab with the results and further controls.
+func (ge *GideView) Find(find, repl string, ignoreCase, regExp bool, loc gide.FindLoc, langs []filecat.Supported) {
+	if find == "" {
+		return
+	}
+	ge.Prefs.Find.IgnoreCase = ignoreCase
+	ge.Prefs.Find.Langs = langs
+	ge.Prefs.Find.Loc = loc
+
+	fbuf, _ := ge.RecycleCmdBuf("Find", true)
+	fvi := ge.RecycleTab("Find", gide.KiT_FindView, true) // sel
+	if fvi == nil {
+		return
+	}
+	fv := fvi.Embed(gide.KiT_FindView).(*gide.FindView)
+	fv.Config(ge)
+	fv.Time = time.Now()
+	ftv := fv.TextView()
+	ftv.SetInactive()
+	ftv.SetBuf(fbuf)
+
+	fv.SaveFindString(find)
+	fv.SaveReplString(repl)
+
+	root := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
+
+	atv := ge.ActiveTextView()
+	ond, _, got := ge.OpenNodeForTextView(atv)
+	adir := ""
+	if got {
+		adir, _ = filepath.Split(string(ond.FPath))
+	}
+
+	var res []gide.FileSearchResults
+	fv.CancelSearch()
+	if loc == gide.FindLocFile {
+		if got {
+			if regExp {
+				re, err := gide.FindRegexp(find, ignoreCase)
+				if err != nil {
+					log.Println(err)
+				} else {
+					cnt, matches := atv.Buf.SearchRegexp(re)
+					res = append(res, gide.FileSearchResults{ond, cnt, matches})
+				}
+			} else {
+				cnt, matches := atv.Buf.Search([]byte(find), ignoreCase, false)
+				res = append(res, gide.FileSearchResults{ond, cnt, matches})
+			}
+		}
+	} else if loc == gide.FindLocOpen {
+		fp := &ge.Prefs.Find
+		res = gide.OpenNodesSearch(ge.OpenNodes, find, ignoreCase, regExp, langs, gide.FindGlobs(fp.Include), gide.FindGlobs(fp.Exclude))
+	} else {
+		fp := &ge.Prefs.Find
+		var ifilt func(sfn *giv.FileNode) bool
+		if !regExp {
+			ifilt = ge.SymIdx.FindFilter(find)
+		}
+		filter := func(sfn *giv.FileNode) bool {
+			if ge.Ignore.Ignored(string(sfn.FPath), false) {
+				return false
+			}
+			return ifilt == nil || ifilt(sfn)
+		}
+		var re *regexp.Regexp
+		if regExp {
+			var err error
+			re, err = gide.FindRegexp(find, ignoreCase)
+			if err != nil {
+				log.Println(err)
+				return
+			}
+		}
+		files := gide.FileTreeSearchFiles(root, loc, adir, fp.Recursive, langs, gide.FindGlobs(fp.Include), gide.FindGlobs(fp.Exclude), filter)
+		fv.StartSearch(files, find, ignoreCase, re) // results shown as found
+		ge.FocusOnPanel(TabsIdx)
+		return
+	}
+	fv.ShowResults(res)
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// Spell checks spelling in active text view
+func (ge *GideView) Spell() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	spell.OpenCheck() // make sure latest file opened
+	ge.OpenSpellDict()
+	sv := ge.RecycleTab("Spell", gide.KiT_SpellView, true).Embed(gide.KiT_SpellView).(*gide.SpellView)
+	sv.Config(ge, tv)
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// OpenSpellDict reads the dictionary of the words accepted by the spell
+// checking in the project, if modified since last read
+func (ge *GideView) OpenSpellDict() {
+	if ge.IsEmpty() {
+		return
+	}
+	if err := ge.Dict.Open(gide.SpellDictPath(string(ge.ProjRoot), ge.Prefs.SpellDict)); err != nil {
+		log.Printf("gide: could not read the project dictionary: %v\n", err)
+	}
+}
+
+// OpenActionLog reads the action log of the project, logging the
+// operations done in it from then on
+func (ge *GideView) OpenActionLog() {
+	if ge.IsEmpty() {
+		return
+	}
+	if err := ge.ActLog.Open(string(ge.ProjRoot)); err != nil {
+		log.Printf("gide: could not read the action log: %v\n", err)
+	}
+}
+
+// LogAction logs an operation of given kind (gide.ActionSave,
+// gide.ActionCmd..), name and arguments, failed if err is not nil, in the
+// action log of the project
+func (ge *GideView) LogAction(kind, op, args string, err error) {
+	if lerr := ge.ActLog.Add(kind, op, args, err); lerr != nil {
+		log.Printf("gide: could not write the action log: %v\n", lerr)
+	}
+}
+
+// ActionLog shows the log of the operations done in the project (files
+// saved, commands run with their arguments and exit codes, version control
+// actions, files renamed or deleted, refactorings), most recent first, in
+// the Action Log panel, which can filter them by a query
+func (ge *GideView) ActionLog() {
+	tbuf, _ := ge.RecycleCmdBuf("Action Log", false)
+	av := ge.RecycleTab("Action Log", gide.KiT_ActionLogView, true).Embed(gide.KiT_ActionLogView).(*gide.ActionLogView)
+	av.Config(ge, &ge.ActLog)
+	atv := av.TextView()
+	atv.SetInactive()
+	atv.SetBuf(tbuf)
+	av.ShowActions()
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// EditSpellDict opens the dictionary file of the project in the editor,
+// one word per line, creating it if needed
+func (ge *GideView) EditSpellDict() {
+	ge.OpenSpellDict()
+	fn := ge.Dict.Path
+	if _, err := os.Stat(fn); os.IsNotExist(err) {
+		hdr := "# words accepted by the spell checking in this project, one per line\n"
+		if err := ioutil.WriteFile(fn, []byte(hdr), 0644); err != nil {
+			ge.SetStatus("could not create the project dictionary: " + err.Error())
+			return
+		}
+		ge.Files.UpdateNewFile(fn)
+	}
+	ge.NextViewFile(gi.FileName(fn))
+}
+
+// Symbols displays the Symbols of a file or package
+func (ge *GideView) Symbols() {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	sv := ge.RecycleTab("Symbols", gide.KiT_SymbolsView, true).Embed(gide.KiT_SymbolsView).(*gide.SymbolsView)
+	sv.Config(ge, ge.Prefs.Symbols)
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// Todos shows the TODO comments in the project files in the TODOs tab,
+// scanning the files in the background if not yet scanned
+func (ge *GideView) Todos() {
+	ge.ShowTodos(false)
+}
+
+// ScanTodos re-scans all the project files for TODO comments, and shows
+// them in the TODOs tab
+func (ge *GideView) ScanTodos() {
+	ge.ShowTodos(true)
+}
+
+// ShowTodos shows the TODO comments in the TODOs tab, scanning the files in
+// the background if not yet scanned, or if rescan
+func (ge *GideView) ShowTodos(rescan bool) {
+	if ge.IsEmpty() {
+		return
+	}
+	tbuf, _ := ge.RecycleCmdBuf("TODOs", false)
+	tv := ge.RecycleTab("TODOs", gide.KiT_TodoView, true).Embed(gide.KiT_TodoView).(*gide.TodoView)
+	tv.Config(ge, &ge.TodoList)
+	ftv := tv.TextView()
+	ftv.SetInactive()
+	ftv.SetBuf(tbuf)
+	ge.FocusOnPanel(TabsIdx)
+	if !rescan && ge.TodoList.IsScanned() {
+		tv.ShowTodos()
+		return
+	}
+	ge.SetStatus("scanning for TODO comments...")
+	ge.BgJobs.Run("TODO scan", gide.JobLow, func(jb *gide.Job) {
+		ge.TodoList.Scan(string(ge.ProjRoot), gide.FindGlobs(ge.Prefs.Find.Exclude), &ge.Ignore)
+		if jb.IsCancelled() {
+			return
+		}
+		wupdt := ge.TopUpdateStart()
+		tv.ShowTodos()
+		n, _ := ge.TodoList.Count()
+		ge.SetStatus(fmt.Sprintf("found %d TODO comments", n))
+		ge.TopUpdateEnd(wupdt)
+	})
+}
+
+// UpdateTodoFile re-scans given file for TODO comments after it has been
+// saved or changed, updating the TODOs tab if open and the comments changed
+func (ge *GideView) UpdateTodoFile(fpath string) {
+	ge.UpdateTodoFiles([]string{fpath})
+}
+
+// UpdateTodoFiles re-scans given files for TODO comments after they have
+// been changed, updating the TODOs tab once if open and the comments changed
+func (ge *GideView) UpdateTodoFiles(fpaths []string) {
+	chg := false
+	for _, fpath := range fpaths {
+		if ge.TodoList.UpdateFile(fpath, &ge.Ignore) {
+			chg = true
+		}
+	}
+	if !chg {
+		return
+	}
+	tvi, err := ge.Tabs().TabByNameTry("TODOs")
+	if err != nil {
+		return
+	}
+	if tv, ok := tvi.Embed(gide.KiT_TodoView).(*gide.TodoView); ok {
+		wupdt := ge.TopUpdateStart()
+		tv.ShowTodos()
+		ge.TopUpdateEnd(wupdt)
+	}
+}
+
+// Problems shows the problems found by the build, test, vet and lint
+// commands in the Problems tab
+func (ge *GideView) Problems() {
+	if ge.IsEmpty() {
+		return
+	}
+	tbuf, _ := ge.RecycleCmdBuf("Problems", false)
+	pv := ge.RecycleTab("Problems", gide.KiT_ProblemsView, true).Embed(gide.KiT_ProblemsView).(*gide.ProblemsView)
+	pv.Config(ge, &ge.ProbList)
+	ptv := pv.TextView()
+	ptv.SetInactive()
+	ptv.SetBuf(tbuf)
+	pv.ShowProblems()
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// Notify adds a notification of an event of the project, with given
+// severity, source and plain text message, and actions offered as links --
+// shown in the Notifications panel, and counted in the statusbar until seen
+func (ge *GideView) Notify(sev gide.ProblemSeverity, source, msg string, acts ...gide.NotifyAction) {
+	ge.Notifs.Add(gide.Notification{Severity: sev, Source: source, Msg: msg, Actions: acts})
+	ge.UpdateNotifs()
+}
+
+// Notifications shows the notifications of the project in the
+// Notifications panel, marking them as seen
+func (ge *GideView) Notifications() {
+	tbuf, _ := ge.RecycleCmdBuf("Notifications", false)
+	nv := ge.RecycleTab("Notifications", gide.KiT_NotifyView, true).Embed(gide.KiT_NotifyView).(*gide.NotifyView)
+	nv.Config(ge, &ge.Notifs)
+	ntv := nv.TextView()
+	ntv.SetInactive()
+	ntv.SetBuf(tbuf)
+	nv.ShowNotifs()
+	ge.UpdateNotifyStatus()
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// UpdateNotifs updates the Notifications panel if open, and the count of
+// the unread notifications in the statusbar
+func (ge *GideView) UpdateNotifs() {
+	if ge.IsDeleted() || ge.IsDestroyed() {
+		return
+	}
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+	if tvi, err := ge.Tabs().TabByNameTry("Notifications"); err == nil {
+		cur, _, _ := ge.Tabs().CurTab()
+		if nv, ok := tvi.Embed(gide.KiT_NotifyView).(*gide.NotifyView); ok && cur == tvi {
+			nv.ShowNotifs()
+		}
+	}
+	ge.UpdateNotifyStatus()
+}
+
+// UpdateNotifyStatus shows the number of unread notifications in the
+// statusbar, in the color of the most severe one
+func (ge *GideView) UpdateNotifyStatus() {
+	sb := ge.StatusBar()
+	if sb == nil {
+		return
+	}
+	na, ok := sb.ChildByName("sb-notify", 2).(*gi.Action)
+	if !ok {
+		return
+	}
+	text := ""
+	n, sev := ge.Notifs.UnreadCounts()
+	if n > 0 {
+		text = fmt.Sprintf("🔔 %d", n)
+	}
+	if text == na.Text {
+		return
+	}
+	updt := sb.UpdateStart()
+	na.SetText(text)
+	na.SetProp("color", gide.ProblemColors[sev])
+	na.Tooltip = fmt.Sprintf("%d new notifications -- click to see them", n)
+	sb.UpdateEnd(updt)
+}
+
+// SetProblems sets the problems found by given source (a command, linter
+// or language server), replacing its previous ones, and updates the
+// Problems tab if open and the marks in the open files
+func (ge *GideView) SetProblems(source string, probs []gide.Problem) {
+	if !ge.ProbList.Set(source, probs) {
+		return
+	}
+	ge.UpdateProblems()
+}
+
+// AddArtifacts adds given artifacts made by a build to the list shown in
+// the Artifacts panel, updating it if open
+func (ge *GideView) AddArtifacts(afs []gide.Artifact) {
+	ge.Artifacts.Add(afs)
+	tvi, err := ge.Tabs().TabByNameTry("Artifacts")
+	if err != nil {
+		return
+	}
+	if av, ok := tvi.Embed(gide.KiT_ArtifactsView).(*gide.ArtifactsView); ok {
+		av.ShowArtifacts()
+	}
+}
+
+// ArtifactsPanel shows the Artifacts tab, listing the executables and
+// other files made by the build commands, for running them, revealing them
+// in the file manager, and copying their path
+func (ge *GideView) ArtifactsPanel() {
+	if ge.IsEmpty() {
+		return
+	}
+	av := ge.RecycleTab("Artifacts", gide.KiT_ArtifactsView, true).Embed(gide.KiT_ArtifactsView).(*gide.ArtifactsView)
+	av.Config(ge, &ge.Artifacts)
+	av.ShowArtifacts()
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// ClearProblems removes all the problems
+func (ge *GideView) ClearProblems() {
+	ge.ProbList.Clear()
+	ge.UpdateProblems()
+}
+
+// UpdateProblems updates the Problems tab if open, and the marks of the
+// problems in the open files
+func (ge *GideView) UpdateProblems() {
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+	for _, ond := range ge.OpenNodes {
+		ge.MarkFileProblems(ond.Buf)
+	}
+	tvi, err := ge.Tabs().TabByNameTry("Problems")
+	if err != nil {
+		return
+	}
+	if pv, ok := tvi.Embed(gide.KiT_ProblemsView).(*gide.ProblemsView); ok {
+		pv.ShowProblems()
+	}
+}
+
+// MarkFileProblems marks the problems in the file of given buffer
+func (ge *GideView) MarkFileProblems(tb *giv.TextBuf) {
+	if tb == nil || tb.Filename == "" {
+		return
+	}
+	gide.MarkProblems(tb, ge.ProbList.File(string(tb.Filename)))
+}
+
+// NextProblem goes to the next problem after the cursor in the active
+// view, showing its message in the status bar
+func (ge *GideView) NextProblem() {
+	ge.GoToProblem(false)
+}
+
+// PrevProblem goes to the previous problem before the cursor in the
+// active view, showing its message in the status bar
+func (ge *GideView) PrevProblem() {
+	ge.GoToProblem(true)
+}
+
+// GoToProblem goes to the next, or previous, problem from the cursor
+func (ge *GideView) GoToProblem(prev bool) {
+	var cur gide.FilePos
+	if tv := ge.ActiveTextView(); tv != nil && tv.Buf != nil {
+		cur = gide.FilePos{Path: string(tv.Buf.Filename), Line: tv.CursorPos.Ln + 1}
+		ln := tv.Buf.Line(tv.CursorPos.Ln)
+		if tv.CursorPos.Ch <= len(ln) {
+			cur.Col = len(string(ln[:tv.CursorPos.Ch])) + 1
+		}
+	}
+	pb, ok := ge.ProbList.Next(cur, prev)
+	if !ok {
+		ge.SetStatus("no problems")
+		return
+	}
+	ge.SaveNavPos()
+	ge.ViewFilePos(pb.Pos())
+	ge.SetStatus(html.EscapeString(fmt.Sprintf("%v %v:%v: %v [%v]", gide.ProblemIcons[pb.Severity], filepath.Base(pb.Path), pb.Loc(), pb.Msg, pb.Source)))
+}
+
+// Lint runs golangci-lint on all the packages of the project in the
+// background, showing its findings in the Problems tab and marking them
+// in the files -- see the Lint project preferences
+func (ge *GideView) Lint() {
+	if ge.IsEmpty() {
+		return
+	}
+	ge.SaveAllCheck(true, func() { // true = cancel option
+		ge.Problems()
+		ge.RunLint(string(ge.ProjRoot), true)
+	})
+}
+
+// LintOnSave runs golangci-lint on the package of given saved file, if
+// it is a Go file and the Lint OnSave project preference is set
+func (ge *GideView) LintOnSave(fpath string) {
+	lp := &ge.Prefs.Lint
+	if !lp.OnSave || lp.Disabled || filepath.Ext(fpath) != ".go" {
+		return
+	}
+	ge.RunLint(filepath.Dir(fpath), false)
+}
+
+// BuildOnSave runs the build on save, if on for the project, after the
+// BuildOnSaveDelay with no further saves
+func (ge *GideView) BuildOnSave() {
+	if !ge.Prefs.BuildOnSave {
+		return
+	}
+	if ge.BuildSaveTimer != nil {
+		ge.BuildSaveTimer.Stop()
+	}
+	ge.BuildSaveTimer = time.AfterFunc(gide.BuildOnSaveDelay, ge.RunBuildOnSave)
+}
+
+// RunBuildOnSave runs the BuildCmds of the project, or go build ./..., in
+// the background, showing pass / fail in the statusbar, with the errors in
+// the Problems panel -- if it is already running, it is run again when done
+func (ge *GideView) RunBuildOnSave() {
+	if ge.IsDeleted() || ge.IsDestroyed() {
+		return
+	}
+	if ge.BuildSaveBusy {
+		ge.BuildSaveAgain = true
+		return
+	}
+	cmds := gide.BuildOnSaveCmds(&ge.Prefs)
+	if len(cmds) == 0 {
+		ge.SetBuildStatus("build: no BuildCmds", "set the BuildCmds of the project to build on save")
+		return
+	}
+	ge.BuildSaveBusy = true
+	ge.SetArgVarVals()
+	ge.SetBuildStatus("build: ...", "building")
+	go func() {
+		for {
+			ok, failed := gide.RunBuildOnSave(ge, cmds)
+			if ge.IsDeleted() || ge.IsDestroyed() {
+				return
+			}
+			if !ge.BuildSaveAgain {
+				var srcs []string
+				for _, cm := range cmds {
+					srcs = append(srcs, cm.Name)
+				}
+				n := ge.ProbList.SourceCounts(srcs...)
+				if ok {
+					ge.SetBuildStatus(`build: <span style="color: green">✔</span>`, "the build on save passed -- click to show the Problems")
+				} else {
+					ge.SetBuildStatus(fmt.Sprintf(`build: <span style="color: red">✖ %d</span>`, n[gide.ProblemError]), fmt.Sprintf("%v failed with %d errors -- click to show the Problems", failed, n[gide.ProblemError]))
+				}
+				ge.BuildSaveBusy = false
+				return
+			}
+			ge.BuildSaveAgain = false
+		}
+	}()
+}
+
+// SetBuildStatus sets the build on save indicator of the statusbar
+func (ge *GideView) SetBuildStatus(text, tooltip string) {
+	sb := ge.StatusBar()
+	if sb == nil {
+		return
+	}
+	ba, ok := sb.ChildByName("sb-build", 1).(*gi.Action)
+	if !ok {
+		return
+	}
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+	updt := sb.UpdateStart()
+	ba.SetText(text)
+	ba.Tooltip = tooltip
+	sb.UpdateEnd(updt)
+}
+
+// RunLint runs golangci-lint in the background in given directory, on all
+// the packages in it if all is set, else just its package, replacing the
+// previous findings for them -- any earlier run still going is stopped
+func (ge *GideView) RunLint(dir string, all bool) {
+	root := string(ge.ProjRoot)
+	ge.CmdRuns().KillByName(gide.LintCmdName)
+	ge.LintRun++
+	run := ge.LintRun
+	ge.SetStatus("running golangci-lint in: " + dir)
+	ge.BgJobs.Run(gide.LintCmdName, gide.JobNormal, func(jb *gide.Job) {
+		pkg := "."
+		if all {
+			pkg = "./..."
+		}
+		bydir, err := gide.RunLint(ge, &ge.Prefs.Lint, dir, pkg)
+		if run != ge.LintRun || ge.IsDeleted() || ge.IsDestroyed() {
+			return
+		}
+		if err != nil {
+			if !ge.Prefs.Lint.Installed() {
+				ge.Prefs.Lint.Disabled = true
+			}
+			ge.SetStatus(html.EscapeString("golangci-lint: " + err.Error()))
+			ge.Notify(gide.ProblemError, gide.LintCmdName, err.Error())
+			return
+		}
+		bysrc := map[string][]gide.Problem{}
+		n := 0
+		for pd, probs := range bydir {
+			bysrc[gide.LintSource(root, pd)] = probs
+			n += len(probs)
+		}
+		if all {
+			if ge.ProbList.SetPrefix(gide.LintCmdName+" ", bysrc) {
+				ge.UpdateProblems()
+			}
+		} else {
+			ge.SetProblems(gide.LintSource(root, dir), bysrc[gide.LintSource(root, dir)])
+		}
+		ge.SetStatus(fmt.Sprintf("golangci-lint: %d issues", n))
+		sev := gide.ProblemInfo
+		if n > 0 {
+			sev = gide.ProblemWarning
+		}
+		ge.Notify(sev, gide.LintCmdName, fmt.Sprintf("%d issues in: %v", n, gide.LintSource(root, dir)), gide.NotifyAction{Label: "Problems", URL: "gide:///Problems"})
+	})
+}
+
+// Tests shows the test explorer in the Tests tab: the tests of the project
+// by package and file, for running them
+func (ge *GideView) Tests() {
+	if ge.IsEmpty() {
+		return
+	}
+	tv := ge.RecycleTab("Tests", gide.KiT_TestsView, true).Embed(gide.KiT_TestsView).(*gide.TestsView)
+	tv.Config(ge)
+	if !tv.Tests.HasChildren() {
+		tv.Scan()
+	}
+	ge.FocusOnPanel(TabsIdx)
 }
 
-// OpenConsoleTab opens a main tab displaying console output (stdout, stderr)
-func (ge *GideView) OpenConsoleTab() {
-	ctv := ge.RecycleTabTextView("Console", true)
-	if ctv == nil {
+// Coverage shows the coverage of the last run of the tests with coverage
+// in the Coverage tab, for running them
+func (ge *GideView) Coverage() {
+	if ge.IsEmpty() {
 		return
 	}
+	tbuf, _ := ge.RecycleCmdBuf("Coverage", false)
+	cv := ge.RecycleTab("Coverage", gide.KiT_CoverView, true).Embed(gide.KiT_CoverView).(*gide.CoverView)
+	cv.Config(ge, &ge.Cover)
+	ctv := cv.TextView()
 	ctv.SetInactive()
-	if ctv.Buf == nil || ctv.Buf != gide.TheConsole.Buf {
-		ctv.SetBuf(gide.TheConsole.Buf)
-		gide.TheConsole.Buf.TextBufSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-			gee, _ := recv.Embed(KiT_GideView).(*GideView)
-			gee.SelectTabByName("Console")
-		})
-	}
+	ctv.SetBuf(tbuf)
+	cv.ShowCoverage()
+	ge.FocusOnPanel(TabsIdx)
 }
 
-//////////////////////////////////////////////////////////////////////////////////////
-//    TextView functions
-
-// CursorToHistPrev moves cursor to previous position on history list --
-// returns true if moved
-func (ge *GideView) CursorToHistPrev() bool {
-	tv := ge.ActiveTextView()
-	return tv.CursorToHistPrev()
+// Profile shows the Profile tab, for profiling the tests or a running
+// program and viewing the top functions and call tree of the profile
+func (ge *GideView) Profile() {
+	if ge.IsEmpty() {
+		return
+	}
+	pv := ge.RecycleTab("Profile", gide.KiT_ProfView, true).Embed(gide.KiT_ProfView).(*gide.ProfView)
+	pv.Config(ge)
+	ge.FocusOnPanel(TabsIdx)
 }
 
-// CursorToHistNext moves cursor to next position on history list --
-// returns true if moved
-func (ge *GideView) CursorToHistNext() bool {
-	tv := ge.ActiveTextView()
-	return tv.CursorToHistNext()
+// GoMod shows the Go Mod tab, listing the requirements of the go.mod of
+// the project with their latest versions, for upgrading, downgrading and
+// tidying them, and showing why a module is needed
+func (ge *GideView) GoMod() {
+	if ge.IsEmpty() {
+		return
+	}
+	mv := ge.RecycleTab("Go Mod", gide.KiT_ModView, true).Embed(gide.KiT_ModView).(*gide.ModView)
+	mv.Config(ge)
+	mv.Refresh(true)
+	ge.FocusOnPanel(TabsIdx)
 }
 
-// LookupFun is the completion system Lookup function that makes a custom
-// textview dialog that has option to edit resulting file.
-func (ge *GideView) LookupFun(data interface{}, text string, posLn, posCh int) (ld complete.Lookup) {
-	sfs := data.(*pi.FileStates)
-	if sfs == nil {
-		log.Printf("LookupFun: data is nil not FileStates or is nil - can't lookup\n")
-		return ld
+// Fuzz shows the Fuzz tab, for running go test -fuzz on a fuzz target of
+// the package of the active file -- the one at the cursor if any -- and
+// running or debugging it on the failing inputs found
+func (ge *GideView) Fuzz() {
+	if ge.IsEmpty() {
+		return
 	}
-	lp, err := pi.LangSupport.Props(sfs.Sup)
-	if err != nil {
-		log.Printf("LookupFun: %v\n", err)
-		return ld
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		ge.SetStatus("no active file for fuzzing its package")
+		return
 	}
-	if lp.Lang == nil {
-		return ld
+	fpath := string(tv.Buf.Filename)
+	fn := ""
+	if ta := gide.TestAtBufLine(tv.Buf, tv.CursorPos.Ln); ta != nil && ta.Kind == "Fuzz" {
+		fn = ta.Func
 	}
+	fv := ge.RecycleTab("Fuzz", gide.KiT_FuzzView, true).Embed(gide.KiT_FuzzView).(*gide.FuzzView)
+	fv.Config(ge, filepath.Dir(fpath), fn)
+	ge.FocusOnPanel(TabsIdx)
+}
 
-	// note: must have this set to ture to allow viewing of AST
-	// must set it in pi/parse directly -- so it is changed in the fileparse too
-	parse.GuiActive = true // note: this is key for debugging -- runs slower but makes the tree unique
+// CheckVulns shows the Vulnerabilities tab and runs govulncheck on the
+// packages of the project, listing the vulnerabilities found with the
+// call paths to them from the project
+func (ge *GideView) CheckVulns() {
+	if ge.IsEmpty() {
+		return
+	}
+	vv := ge.RecycleTab("Vulnerabilities", gide.KiT_VulnView, true).Embed(gide.KiT_VulnView).(*gide.VulnView)
+	vv.Config(ge)
+	vv.Check()
+	ge.FocusOnPanel(TabsIdx)
+}
 
-	ld = lp.Lang.Lookup(sfs, text, lex.Pos{posLn, posCh})
-	if len(ld.Text) > 0 {
-		giv.TextViewDialog(nil, ld.Text, giv.DlgOpts{Title: "Lookup: " + text, Data: text})
-		return ld
+// BuildMatrix shows the Build Matrix tab, for building the main package
+// for all the targets (GOOS / GOARCH / tags) of the project in parallel
+func (ge *GideView) BuildMatrix() {
+	if ge.IsEmpty() {
+		return
 	}
-	if ld.Filename == "" {
-		return ld
+	mv := ge.RecycleTab("Build Matrix", gide.KiT_MatrixView, true).Embed(gide.KiT_MatrixView).(*gide.MatrixView)
+	mv.Config(ge)
+	ge.FocusOnPanel(TabsIdx)
+}
+
+// TestCoverage runs the tests of the package of the active file with
+// coverage, showing it in the Coverage tab and marking the covered and
+// uncovered lines in the line number gutter of the files
+func (ge *GideView) TestCoverage() {
+	ge.Coverage()
+	if tvi, err := ge.Tabs().TabByNameTry("Coverage"); err == nil {
+		tvi.Embed(gide.KiT_CoverView).(*gide.CoverView).Run(false)
 	}
+}
 
-	txt, err := textbuf.FileBytes(ld.Filename)
+// UpdateCoverage updates the Coverage tab if open, and the coverage marks
+// in the open files
+func (ge *GideView) UpdateCoverage() {
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+	for _, ond := range ge.OpenNodes {
+		ge.MarkFileCoverage(ond.Buf)
+	}
+	tvi, err := ge.Tabs().TabByNameTry("Coverage")
 	if err != nil {
-		return ld
+		return
 	}
-	if ld.StLine > 0 {
-		lns := bytes.Split(txt, []byte("\n"))
-		comLn, comSt, comEd := textbuf.SupportedComments(ld.Filename)
-		ld.StLine = textbuf.PreCommentStart(lns, ld.StLine, comLn, comSt, comEd, 10) // just go back 10 max
+	if cv, ok := tvi.Embed(gide.KiT_CoverView).(*gide.CoverView); ok {
+		cv.ShowCoverage()
 	}
+}
 
-	prmpt := ""
-	if ld.EdLine > ld.StLine {
-		prmpt = fmt.Sprintf("%v [%d -- %d]", ld.Filename, ld.StLine, ld.EdLine)
-	} else {
-		prmpt = fmt.Sprintf("%v:%d", ld.Filename, ld.StLine)
-	}
-	opts := giv.DlgOpts{Title: "Lookup: " + text, Prompt: prmpt}
+// ClearCoverage removes the coverage and its marks in the files
+func (ge *GideView) ClearCoverage() {
+	ge.Cover.Clear()
+	ge.UpdateCoverage()
+}
 
-	dlg, recyc := gi.RecycleStdDialog(prmpt, opts.ToGiOpts(), gi.NoOk, gi.NoCancel)
-	if recyc {
-		return ld
+// MarkFileCoverage marks the covered and uncovered lines of the file of
+// given buffer
+func (ge *GideView) MarkFileCoverage(tb *giv.TextBuf) {
+	if tb == nil || tb.Filename == "" {
+		return
 	}
-	frame := dlg.Frame()
-	_, prIdx := dlg.PromptWidget(frame)
-
-	tb := &giv.TextBuf{}
-	tb.InitName(tb, "text-view-dialog-buf")
-	tb.Filename = gi.FileName(ld.Filename)
-	tb.Hi.Style = gi.Prefs.Colors.HiStyle
-	tb.Opts.LineNos = ge.Prefs.Editor.LineNos
-	tb.Stat() // update markup
+	var lns map[int]bool
+	if cf := ge.Cover.File(string(tb.Filename)); cf != nil {
+		lns = cf.Lines()
+	}
+	gide.MarkCoverage(tb, lns)
+}
 
-	tlv := frame.InsertNewChild(gi.KiT_Layout, prIdx+1, "text-lay").(*gi.Layout)
-	tlv.SetProp("width", units.NewCh(80))
-	tlv.SetProp("height", units.NewEm(40))
-	tlv.SetStretchMax()
-	tv := giv.AddNewTextView(tlv, "text-view")
-	tv.Viewport = dlg.Embed(gi.KiT_Viewport2D).(*gi.Viewport2D)
-	tv.SetInactive()
-	tv.SetProp("font-family", gi.Prefs.MonoFont)
-	tv.SetBuf(tb)
-	tv.CursorPos = lex.Pos{Ln: ld.StLine}
-	tv.ScrollToCursorOnRender = true
+// UpdateSymIndex updates the index of the symbols and words in the project
+// files in the background, loading the saved index first if not yet loaded
+func (ge *GideView) UpdateSymIndex() {
+	if ge.IsEmpty() {
+		return
+	}
+	root, exclude := string(ge.ProjRoot), gide.FindGlobs(ge.Prefs.Find.Exclude)
+	ge.BgJobs.Run("symbol index", gide.JobLow, func(jb *gide.Job) {
+		ge.SymIdx.Update(root, exclude, &ge.Ignore)
+	})
+}
 
-	tb.SetText(txt) // calls remarkup
+// WatchFiles starts watching the project directories for files created,
+// removed, renamed or changed by external tools, updating the file tree,
+// the symbol index and the version control status for them
+func (ge *GideView) WatchFiles() {
+	if ge.IsEmpty() {
+		return
+	}
+	go ge.FileWatch.Start(&ge.Files, ge.FilesView, gide.FindGlobs(ge.Prefs.Find.Exclude), &ge.Ignore, ge.FilesChanged)
+}
 
-	bbox, _ := dlg.ButtonBox(frame)
-	if bbox == nil {
-		bbox = dlg.AddButtonBox(frame)
+// FilesChanged is called by the FileWatcher with the files changed by
+// external tools since its last update: updates the symbol index (all of
+// it, for more than FileWatchMaxFiles) and TODO comments, re-reads the
+// ignore files and the project dictionary if one of them was changed, and
+// updates the version control status, once for all the files
+func (ge *GideView) FilesChanged(fpaths []string) {
+	if len(fpaths) > gide.FileWatchMaxFiles {
+		ge.UpdateSymIndex()
+	} else {
+		for _, fpath := range fpaths {
+			ge.SymIdx.UpdateFile(fpath)
+		}
 	}
-	ofb := gi.AddNewButton(bbox, "open-file")
-	ofb.SetText("Open File")
-	ofb.SetIcon("file-open")
-	ofb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-		if sig == int64(gi.ButtonClicked) {
-			ge.ViewFile(gi.FileName(ld.Filename))
-			dlg.Close()
+	ge.UpdateTodoFiles(fpaths)
+	ign := false
+	for _, fpath := range fpaths {
+		ge.LiveRun.Changed(fpath)
+		if gide.IsIgnoreFile(fpath) {
+			ign = true
 		}
-	})
-	cpb := gi.AddNewButton(bbox, "copy-to-clip")
-	cpb.SetText("Copy To Clipboard")
-	cpb.SetIcon("copy")
-	cpb.ButtonSig.Connect(dlg.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-		if sig == int64(gi.ButtonClicked) {
-			ddlg := recv.Embed(gi.KiT_Dialog).(*gi.Dialog)
-			oswin.TheApp.ClipBoard(ddlg.Win.OSWin).Write(mimedata.NewTextBytes(txt))
+		if fpath == ge.Dict.Path {
+			ge.OpenSpellDict()
 		}
-	})
-	dlg.UpdateEndNoSig(true) // going to be shown
-	dlg.Open(0, 0, ge.Viewport, nil)
-	return ld
+		ge.NotifyFileChanged(fpath)
+	}
+	if ign {
+		ge.Ignore.Open(string(ge.ProjRoot))
+		ge.ReRenderFiles()
+	}
+	ge.UpdateVcsStatus()
 }
 
-//////////////////////////////////////////////////////////////////////////////////////
-//    Find / Replace
-
-// Find does Find / Replace in files, using given options and filters -- opens up a
-// main tab with the results and further controls.
-func (ge *GideView) Find(find, repl string, ignoreCase, regExp bool, loc gide.FindLoc, langs []filecat.Supported) {
-	if find == "" {
+// NotifyFileChanged notifies that given file, open in a buffer, changed on
+// disk since opened or saved, once per change -- offering to reload it if
+// the buffer has no edits
+func (ge *GideView) NotifyFileChanged(fpath string) {
+	var fn *giv.FileNode
+	for _, ofn := range ge.OpenNodes {
+		if string(ofn.FPath) == fpath {
+			fn = ofn
+			break
+		}
+	}
+	if fn == nil || fn.Buf == nil || fn.Buf.HasFlag(int(giv.TextBufFileModOk)) {
 		return
 	}
-	ge.Prefs.Find.IgnoreCase = ignoreCase
-	ge.Prefs.Find.Langs = langs
-	ge.Prefs.Find.Loc = loc
-
-	fbuf, _ := ge.RecycleCmdBuf("Find", true)
-	fvi := ge.RecycleTab("Find", gide.KiT_FindView, true) // sel
-	if fvi == nil {
+	st, err := os.Stat(fpath)
+	if err != nil || st.ModTime().Equal(time.Time(fn.Buf.Info.ModTime)) {
 		return
 	}
-	fv := fvi.Embed(gide.KiT_FindView).(*gide.FindView)
-	fv.Config(ge)
-	fv.Time = time.Now()
-	ftv := fv.TextView()
-	ftv.SetInactive()
-	ftv.SetBuf(fbuf)
-
-	fv.SaveFindString(find)
-	fv.SaveReplString(repl)
-
-	root := ge.Files.Embed(giv.KiT_FileNode).(*giv.FileNode)
-
-	atv := ge.ActiveTextView()
-	ond, _, got := ge.OpenNodeForTextView(atv)
-	adir := ""
-	if got {
-		adir, _ = filepath.Split(string(ond.FPath))
+	if ge.FileModsNotified == nil {
+		ge.FileModsNotified = map[string]time.Time{}
+	}
+	if ge.FileModsNotified[fpath].Equal(st.ModTime()) {
+		return
+	}
+	ge.FileModsNotified[fpath] = st.ModTime()
+	acts := []gide.NotifyAction{{Label: "Open", URL: "file:///" + fpath}}
+	if !fn.Buf.IsChanged() {
+		acts = append(acts, gide.NotifyAction{Label: "Reload", URL: "reload:///" + fpath})
 	}
+	ge.Notify(gide.ProblemWarning, "file", "changed on disk: "+ge.Files.RelPath(gi.FileName(fpath)), acts...)
+}
 
-	var res []gide.FileSearchResults
-	if loc == gide.FindLocFile {
-		if got {
-			if regExp {
-				re, err := regexp.Compile(find)
-				if err != nil {
-					log.Println(err)
-				} else {
-					cnt, matches := atv.Buf.SearchRegexp(re)
-					res = append(res, gide.FileSearchResults{ond, cnt, matches})
-				}
-			} else {
-				cnt, matches := atv.Buf.Search([]byte(find), ignoreCase, false)
-				res = append(res, gide.FileSearchResults{ond, cnt, matches})
-			}
-		}
+// UpdateVcsStatus updates the version control status of the files in the
+// file tree in the background, marking them in the tree
+func (ge *GideView) UpdateVcsStatus() {
+	if ge.IsEmpty() {
+		return
+	}
+	ge.BgJobs.Run("version control status", gide.JobNormal, func(jb *gide.Job) {
+		ge.VcsStat.Update(&ge.Files, ge.FilesView, &ge.Ignore)
+	})
+	ge.UpdateBranch()
+}
+
+// ToggleShowIgnored toggles whether files and directories ignored by the
+// .gitignore and .gideignore files are shown grayed out in the file tree,
+// or hidden
+func (ge *GideView) ToggleShowIgnored() {
+	ge.Prefs.Files.ShowIgnored = !ge.Prefs.Files.ShowIgnored
+	ge.Prefs.Changed = true
+	ge.ReRenderFiles()
+	if ge.Prefs.Files.ShowIgnored {
+		ge.SetStatus("showing ignored files")
 	} else {
-		res = gide.FileTreeSearch(root, find, ignoreCase, regExp, loc, adir, langs)
+		ge.SetStatus("hiding ignored files")
 	}
-	fv.ShowResults(res)
-	ge.FocusOnPanel(TabsIdx)
 }
 
-// Spell checks spelling in active text view
-func (ge *GideView) Spell() {
-	tv := ge.ActiveTextView()
-	if tv == nil || tv.Buf == nil {
+// ReRenderFiles fully re-renders the file tree view, e.g., after the
+// ignored files change
+func (ge *GideView) ReRenderFiles() {
+	if ge.FilesView == nil {
 		return
 	}
-	spell.OpenCheck() // make sure latest file opened
-	sv := ge.RecycleTab("Spell", gide.KiT_SpellView, true).Embed(gide.KiT_SpellView).(*gide.SpellView)
-	sv.Config(ge, tv)
-	ge.FocusOnPanel(TabsIdx)
+	updt := ge.FilesView.UpdateStart()
+	ge.FilesView.SetFullReRender()
+	ge.FilesView.UpdateEnd(updt)
 }
 
-// Symbols displays the Symbols of a file or package
-func (ge *GideView) Symbols() {
-	tv := ge.ActiveTextView()
-	if tv == nil || tv.Buf == nil {
+// WorkspaceSymbols opens a dialog for finding any symbol declared in the
+// project by typing any part of its name, using the project symbol index,
+// and shows the declaration of the one chosen
+func (ge *GideView) WorkspaceSymbols() {
+	if ge.IsEmpty() {
 		return
 	}
-	sv := ge.RecycleTab("Symbols", gide.KiT_SymbolsView, true).Embed(gide.KiT_SymbolsView).(*gide.SymbolsView)
-	sv.Config(ge, ge.Prefs.Symbols)
-	ge.FocusOnPanel(TabsIdx)
+	if !ge.SymIdx.IsReady() {
+		ge.SetStatus("project symbol index is still being built -- try again soon")
+		return
+	}
+	gide.SymIndexDialog(ge.Viewport, &ge.SymIdx, giv.DlgOpts{Title: "Workspace Symbols", Prompt: "Type any part of the name of a symbol declared in the project -- characters need not be adjacent"}, ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig != int64(gi.DialogAccepted) {
+			return
+		}
+		dlg := send.(*gi.Dialog)
+		fpath, ln, ok := gide.SymIndexDialogValue(dlg, &ge.SymIdx)
+		if ok {
+			ge.ShowFile(fpath, ln+1)
+		}
+	})
+}
+
+// SavedSearches shows the panel of saved searches, for re-running them
+func (ge *GideView) SavedSearches() {
+	sv := ge.RecycleTab("Saved Searches", gide.KiT_SavedSearchView, true).Embed(gide.KiT_SavedSearchView).(*gide.SavedSearchView)
+	sv.Config(ge)
 }
 
 // Debug starts the debugger on the RunExec executable.
@@ -1965,12 +4753,61 @@ func (ge *GideView) ChooseRunExec(exePath gi.FileName) {
 	if exePath != "" {
 		ge.Prefs.RunExec = exePath
 		ge.Prefs.BuildDir = gi.FileName(filepath.Dir(string(exePath)))
+		ge.Prefs.BuildConfig = "" // chosen directly instead
 		if !ge.Prefs.RunExecIsExec() {
 			gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Not Executable", Prompt: fmt.Sprintf("RunExec file: %v is not exectable", exePath)}, gi.AddOk, gi.NoCancel, nil, nil)
 		}
 	}
 }
 
+// BuildConfigNames gets list of build configurations of the project, as a submenu-func
+func BuildConfigNames(it interface{}, vp *gi.Viewport2D) []string {
+	ge, ok := it.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ok {
+		return nil
+	}
+	return ge.Prefs.BuildConfigs.Names()
+}
+
+// SetBuildConfig makes the build configuration of given name the active
+// one, which sets the BuildDir, BuildTarg and RunExec used by Build, Run and
+// Debug, and the flags and environment of the Go build commands
+func (ge *GideView) SetBuildConfig(name string) {
+	if !ge.Prefs.SetBuildConfig(name) {
+		ge.SetStatus(fmt.Sprintf("no build config named: %v -- add them in Project Prefs", name))
+		return
+	}
+	ge.Changed = true
+	ge.SetStatus(fmt.Sprintf("build config: %v  BuildDir: %v  RunExec: %v", name, ge.Prefs.BuildDir, ge.Prefs.RunExec))
+}
+
+// RunModeNames gets list of run modes, as a submenu-func
+func RunModeNames(it interface{}, vp *gi.Viewport2D) []string {
+	return gide.RunModeNames[:]
+}
+
+// SetRunMode sets the mode of given name in which the project is built,
+// and then run: normally, or with the race detector (Race) or the memory
+// (MSan) or address (ASan) sanitizer -- Build to rebuild in the new mode
+func (ge *GideView) SetRunMode(name string) {
+	rm, ok := gide.RunModeByName(name)
+	if !ok {
+		ge.SetStatus(fmt.Sprintf("no run mode named: %v", name))
+		return
+	}
+	goos, goarch := "", ""
+	if bc := ge.Prefs.ActiveBuildConfig(); bc != nil {
+		goos, goarch = bc.GOOS, bc.GOARCH
+	}
+	if !rm.Supported(goos, goarch) {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Run Mode Not Supported", Prompt: fmt.Sprintf("Run mode %v is not supported for the target platform of the build config -- it is supported for: %v", name, strings.Join(gide.RunModePlatforms[rm], ", "))}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.Prefs.RunMode = rm
+	ge.Changed = true
+	ge.SetStatus(fmt.Sprintf("run mode: %v -- Build to rebuild in this mode", name))
+}
+
 // ParseOpenFindURL parses and opens given find:/// url from Find, return text
 // region encoded in url, and starting line of results in find buffer, and
 // number of results returned -- for parsing all the find results
@@ -2000,6 +4837,17 @@ func (ge *GideView) ParseOpenFindURL(ur string, ftv *giv.TextView) (tv *gide.Tex
 	return
 }
 
+// FindLinkURL handles given Find results link other than find:/// ones,
+// e.g., for collapsing files or dismissing matches -- delegates to FindView
+func (ge *GideView) FindLinkURL(ur string, ftv *giv.TextView) bool {
+	fvk := ftv.ParentByType(gide.KiT_FindView, true)
+	if fvk == nil {
+		return false
+	}
+	fv := fvk.(*gide.FindView)
+	return fv.LinkURL(ur)
+}
+
 // OpenFindURL opens given find:/// url from Find -- delegates to FindView
 func (ge *GideView) OpenFindURL(ur string, ftv *giv.TextView) bool {
 	fvk := ftv.ParentByType(gide.KiT_FindView, true)
@@ -2105,6 +4953,80 @@ func (ge *GideView) RegisterPaste(name gide.RegisterName) bool {
 	return true
 }
 
+// MacroRecord starts recording a new keyboard macro, or stops recording if
+// currently recording -- all keys pressed while recording are saved, and can
+// be played back using MacroPlay
+func (ge *GideView) MacroRecord() {
+	if ge.MacroRecording {
+		ge.MacroRecording = false
+		ge.SetStatus(fmt.Sprintf("keyboard macro recorded: %v keys", len(ge.Macro.Keys)))
+		return
+	}
+	ge.Macro = gide.Macro{}
+	ge.MacroRecording = true
+	ge.SetStatus("recording keyboard macro..")
+}
+
+// MacroPlay plays back the last recorded keyboard macro, repeat times
+func (ge *GideView) MacroPlay(repeat int) {
+	if ge.MacroRecording {
+		ge.SetStatus("cannot play keyboard macro while recording")
+		return
+	}
+	ge.MacroPlayKeys(&ge.Macro, repeat)
+}
+
+// MacroPlayNamed plays back the saved keyboard macro of given name, repeat times
+func (ge *GideView) MacroPlayNamed(name gide.MacroName, repeat int) {
+	if ge.MacroRecording {
+		ge.SetStatus("cannot play keyboard macro while recording")
+		return
+	}
+	mc, _, ok := gide.AvailMacros.MacroByName(name)
+	if !ok {
+		return
+	}
+	ge.MacroPlayKeys(mc, repeat)
+}
+
+// MacroPlayKeys sends the keys of given macro to the window, repeat times --
+// keys are processed in order through the normal event loop, exactly as if
+// they had been typed
+func (ge *GideView) MacroPlayKeys(mc *gide.Macro, repeat int) {
+	if len(mc.Keys) == 0 {
+		ge.SetStatus("no keyboard macro to play")
+		return
+	}
+	win := ge.ParentWindow()
+	if win == nil {
+		return
+	}
+	if repeat < 1 {
+		repeat = 1
+	}
+	for r := 0; r < repeat; r++ {
+		for i := range mc.Keys {
+			win.OSWin.Send(mc.Keys[i].Event())
+		}
+	}
+}
+
+// MacroSaveAs saves the last recorded keyboard macro under given name, and
+// saves to prefs file
+func (ge *GideView) MacroSaveAs(name, desc string) {
+	if len(ge.Macro.Keys) == 0 {
+		ge.SetStatus("no keyboard macro recorded to save")
+		return
+	}
+	gide.AvailMacros.Add(name, desc, ge.Macro.Keys)
+	gide.AvailMacros.SavePrefs()
+}
+
+// MacrosEdit opens the MacrosView editor to customize saved keyboard macros
+func (ge *GideView) MacrosEdit() {
+	gide.MacrosView(&gide.AvailMacros)
+}
+
 // CommentOut comments-out selected lines in active text view
 // and uncomments if already commented
 // If multiple lines are selected and any line is uncommented all will be commented
@@ -2194,6 +5116,29 @@ func (ge *GideView) SpacesToTabs() {
 	}
 }
 
+// ConvertLineEnds converts the line endings of the file of the active view
+// to given ones (LF or CRLF), written when it is saved
+func (ge *GideView) ConvertLineEnds(le gide.LineEnds) {
+	tv := ge.ActiveTextView()
+	if tv == nil || tv.Buf == nil {
+		return
+	}
+	gide.ConvertLineEnds(tv.Buf, le)
+	ge.SetStatus(fmt.Sprintf("line endings converted to %v -- save the file to write them", le.Label()))
+}
+
+// ConvertToLF converts the line endings of the file of the active view to
+// LF (\n, as on Unix and macOS)
+func (ge *GideView) ConvertToLF() {
+	ge.ConvertLineEnds(gide.LineEndsLF)
+}
+
+// ConvertToCRLF converts the line endings of the file of the active view to
+// CRLF (\r\n, as on Windows)
+func (ge *GideView) ConvertToCRLF() {
+	ge.ConvertLineEnds(gide.LineEndsCRLF)
+}
+
 //////////////////////////////////////////////////////////////////////////////////////
 //    StatusBar
 
@@ -2211,11 +5156,13 @@ func (ge *GideView) SetStatus(msg string) {
 	fnm := ""
 	ln := 0
 	ch := 0
+	dpath := ""
 	tv := ge.ActiveTextView()
 	if tv != nil {
 		ln = tv.CursorPos.Ln + 1
 		ch = tv.CursorPos.Ch
 		if tv.Buf != nil {
+			dpath = gide.DocPathAt(tv.Buf, tv.CursorPos)
 			fnm = ge.Files.RelPath(tv.Buf.Filename)
 			if tv.Buf.IsChanged() {
 				fnm += "*"
@@ -2232,8 +5179,21 @@ func (ge *GideView) SetStatus(msg string) {
 		}
 	}
 
-	str := fmt.Sprintf("%v\t<b>%v:</b>\t(%v,%v)\t%v", ge.Nm, fnm, ln, ch, msg)
+	pos := fmt.Sprintf("(%v,%v)", ln, ch)
+	if dpath != "" {
+		pos += " " + html.EscapeString(dpath)
+	}
+	str := fmt.Sprintf("%v\t<b>%v:</b>\t%v\t%v", ge.Nm, fnm, pos, msg)
 	lbl.SetText(str)
+	if leb, ok := sb.ChildByName("sb-lineends", 1).(*gi.MenuButton); ok {
+		le := ""
+		if tv != nil && tv.Buf != nil {
+			le = gide.BufLineEnds(tv.Buf).Label()
+		}
+		if leb.Text != le {
+			leb.SetText(le)
+		}
+	}
 	sb.UpdateEnd(updt)
 	ge.UpdateTextButtons()
 }
@@ -2254,9 +5214,149 @@ func (ge *GideView) Defaults() {
 // GrabPrefs grabs the current project preference settings from various
 // places, e.g., prior to saving or editing.
 func (ge *GideView) GrabPrefs() {
+	if ge.FocusMode.On { // save the layout of the normal mode
+		ge.FocusMode.SwapSplits()
+		defer ge.FocusMode.SwapSplits()
+	}
 	sv := ge.SplitView()
 	ge.Prefs.Splits = sv.Splits
-	ge.Prefs.Dirs = ge.Files.Dirs
+	ge.Prefs.Panes = ge.PaneLayouts()
+	if !ge.FilesLoad.IsLoading() { // otherwise the open dirs are not all open yet
+		ge.Prefs.Dirs = ge.Files.Dirs
+	}
+	ge.Prefs.Session = ge.GrabSession()
+}
+
+// GrabSession returns the current session state: the files, cursor and
+// scroll positions of the editor panes, the open files, and the tabs
+func (ge *GideView) GrabSession() gide.Session {
+	root := string(ge.ProjRoot)
+	ss := gide.Session{ActiveView: ge.ActiveTextViewIdx}
+	for _, tv := range ge.TextViews() {
+		sv := gide.SessionView{Zoom: tv.Zoom}
+		if tv.Buf != nil && tv.Buf.Filename != "" {
+			sv.File = gide.SessionPath(root, string(tv.Buf.Filename))
+			sv.Line = tv.CursorPos.Ln
+			sv.Col = tv.CursorPos.Ch
+			sv.TopLine = tv.FirstVisibleLine(0)
+		}
+		ss.Views = append(ss.Views, sv)
+	}
+	for _, ond := range ge.OpenNodes {
+		if ond.Buf != nil && ond.Buf.Filename != "" {
+			ss.Open = append(ss.Open, gide.SessionPath(root, string(ond.Buf.Filename)))
+		}
+	}
+	tv := ge.Tabs()
+	for i := 0; i < tv.NTabs(); i++ {
+		ss.Tabs = append(ss.Tabs, tv.TabName(i))
+		if widg, _, ok := tv.TabAtIndex(i); ok {
+			if tmv, ok := widg.Embed(gide.KiT_TermView).(*gide.TermView); ok {
+				if st, has := ge.LazyTabs.TabData(tv.TabName(i)); has {
+					ss.Terms = append(ss.Terms, st.(gide.SessionTerm))
+				} else {
+					ss.Terms = append(ss.Terms, tmv.SessionTerm(root))
+				}
+			}
+		}
+	}
+	if _, idx, ok := tv.CurTab(); ok {
+		ss.ActiveTab = tv.TabName(idx)
+	}
+	return ss
+}
+
+// RestoreSession restores the session state saved in the project prefs:
+// re-opens the files, views them in the editor panes at their saved cursor
+// and scroll positions, and recreates the command output tabs (without
+// their prior output) -- files that no longer exist are skipped
+func (ge *GideView) RestoreSession() {
+	ss := &ge.Prefs.Session
+	root := string(ge.ProjRoot)
+	fileNode := func(spath string) *giv.FileNode {
+		fpath := gide.SessionFullPath(root, spath)
+		if fpath == "" {
+			return nil
+		}
+		if _, err := os.Stat(fpath); err != nil {
+			return nil
+		}
+		return ge.FileNodeForFile(fpath, true)
+	}
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+
+	tvs := ge.TextViews()
+	for i, sv := range ss.Views {
+		if i >= len(tvs) {
+			break
+		}
+		if sv.Zoom != tvs[i].Zoom {
+			tvs[i].Zoom = sv.Zoom
+			ge.ConfigTextView(tvs[i])
+		}
+		fn := fileNode(sv.File)
+		if fn == nil {
+			continue
+		}
+		tv := tvs[i]
+		ge.ViewFileNode(tv, i, fn)
+		if tv.Buf != fn.Buf {
+			continue
+		}
+		cpos := tv.Buf.ValidPos(lex.Pos{Ln: sv.Line, Ch: sv.Col})
+		tv.CursorPos = tv.Buf.ValidPos(lex.Pos{Ln: sv.TopLine})
+		tv.ScrollToCursorOnRender = true
+		tv.RenderCursor = &cpos
+	}
+	for i := len(ss.Open) - 1; i >= 0; i-- { // oldest first, so order is restored
+		if fn := fileNode(ss.Open[i]); fn != nil {
+			ge.OpenFileNode(fn)
+		}
+	}
+	// the command, symbols and terminal tabs are only made when first selected
+	for _, tab := range ss.Tabs {
+		if tab == "Symbols" {
+			ge.AddLazyTab(tab, gide.KiT_SymbolsView, func() {
+				sv := ge.RecycleTab("Symbols", gide.KiT_SymbolsView, false).Embed(gide.KiT_SymbolsView).(*gide.SymbolsView)
+				sv.Config(ge, ge.Prefs.Symbols)
+			}, nil)
+			continue
+		}
+		if _, _, ok := gide.AvailCmds.CmdByName(gide.CmdName(tab), false); ok {
+			tab := tab
+			ge.AddLazyTab(tab, gi.KiT_Layout, func() {
+				ge.RecycleCmdTab(tab, false, false)
+			}, nil)
+		}
+	}
+	for _, st := range ss.Terms {
+		st := st
+		ge.AddLazyTab(st.Name, gide.KiT_TermView, func() {
+			tmv := ge.RecycleTab(st.Name, gide.KiT_TermView, false).Embed(gide.KiT_TermView).(*gide.TermView)
+			if st.Host != "" {
+				tmv.ConfigSSH(ge, st.Name, st.Host, st.Vert, st.Dirs)
+				return
+			}
+			dirs := make([]string, len(st.Dirs))
+			for i, dir := range st.Dirs {
+				dirs[i] = gide.SessionFullPath(root, dir)
+				if fi, err := os.Stat(dirs[i]); err != nil || !fi.IsDir() {
+					dirs[i] = root
+				}
+			}
+			tmv.ConfigDirs(ge, st.Name, st.Vert, dirs)
+		}, st)
+	}
+	if ss.ActiveTab != "" {
+		if idx, err := ge.Tabs().TabIndexByName(ss.ActiveTab); err == nil {
+			ge.Tabs().SelectTabIndex(idx)
+			ge.LazyTabs.Config(ss.ActiveTab)
+		}
+	}
+	if ss.ActiveView >= 0 && ss.ActiveView < len(tvs) {
+		ge.SetActiveTextViewIdx(ss.ActiveView)
+	}
 }
 
 // ApplyPrefs applies current project preference settings into places where
@@ -2266,9 +5366,10 @@ func (ge *GideView) ApplyPrefs() {
 	ge.ProjRoot = ge.Prefs.ProjRoot
 	ge.Files.Dirs = ge.Prefs.Dirs
 	ge.Files.DirsOnTop = ge.Prefs.Files.DirsOnTop
+	ge.Ignore.SetFilePrefs(&ge.Prefs.Files)
+	ge.Prefs.ApplyBuildConfig()
 	if len(ge.Kids) > 0 {
-		for i := 0; i < NTextViews; i++ {
-			tv := ge.TextViewByIndex(i)
+		for _, tv := range ge.TextViews() {
 			if tv.Buf != nil {
 				ge.ConfigTextBuf(tv.Buf)
 			}
@@ -2290,6 +5391,19 @@ func (ge *GideView) ApplyPrefsAction() {
 	ge.SetStatus("Applied prefs")
 }
 
+// ImportHiStyle imports the VSCode (.json) or TextMate (.tmTheme) color theme
+// of given file as a custom highlighting style, and uses it for the project
+func (ge *GideView) ImportHiStyle(fname gi.FileName) {
+	nm, err := gide.ImportTheme(string(fname))
+	if err != nil {
+		gi.PromptDialog(ge.Viewport, gi.DlgOpts{Title: "Could not Import Color Theme", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+		return
+	}
+	ge.Prefs.HiStyle = gi.HiStyleName(nm)
+	ge.ApplyPrefsAction()
+	ge.SetStatus(fmt.Sprintf("imported color theme: %v as highlighting style", nm))
+}
+
 // EditProjPrefs allows editing of project preferences (settings specific to this project)
 func (ge *GideView) EditProjPrefs() {
 	sv, _ := gide.ProjPrefsView(&ge.Prefs)
@@ -2307,8 +5421,13 @@ func (ge *GideView) SplitsSetView(split gide.SplitName) {
 	if ok {
 		sv.SetSplitsAction(sp.Splits...)
 		ge.Prefs.SplitName = split
-		if !ge.PanelIsOpen(ge.ActiveTextViewIdx + TextView1Idx) {
-			ge.SetActiveTextViewIdx((ge.ActiveTextViewIdx + 1) % 2)
+		if !ge.TextViewIsOpen(ge.ActiveTextViewIdx) {
+			for i := 0; i < ge.NTextViews(); i++ {
+				if ge.TextViewIsOpen(i) {
+					ge.SetActiveTextViewIdx(i)
+					break
+				}
+			}
 		}
 	}
 }
@@ -2357,7 +5476,7 @@ func (ge *GideView) Config() {
 	gi.AddNewSplitView(ge, "splitview")
 	gi.AddNewFrame(ge, "statusbar", gi.LayoutHoriz)
 
-	ge.UpdateFiles()
+	gide.InitFileTree(&ge.Files, string(ge.ProjRoot)) // loaded by LoadFiles
 	ge.ConfigSplitView()
 	ge.ConfigToolbar()
 	ge.ConfigStatusBar()
@@ -2395,17 +5514,24 @@ func (ge *GideView) FileTreeView() *gide.FileTreeView {
 	return ge.SplitView().Child(FileTreeIdx).Child(0).(*gide.FileTreeView)
 }
 
-// TextViewByIndex returns the TextView by index (0 or 1), nil if not found
+// TextViewByIndex returns the TextView by index among all the editor panes,
+// nil if not found
 func (ge *GideView) TextViewByIndex(idx int) *gide.TextView {
-	split := ge.SplitView()
-	svk := split.Child(TextView1Idx + idx).Child(1).Child(0)
-	return svk.Embed(gide.KiT_TextView).(*gide.TextView)
+	tvs := ge.TextViews()
+	if idx < 0 || idx >= len(tvs) {
+		return nil
+	}
+	return tvs[idx]
 }
 
-// TextViewButtonByIndex returns the top textview menu button by index (0 or 1)
+// TextViewButtonByIndex returns the top textview menu button by index
+// among all the editor panes
 func (ge *GideView) TextViewButtonByIndex(idx int) *gi.MenuButton {
-	split := ge.SplitView()
-	svk := split.Child(TextView1Idx + idx).Child(0).Child(0)
+	tv := ge.TextViewByIndex(idx)
+	if tv == nil {
+		return nil
+	}
+	svk := tv.Parent().Parent().Child(0).Child(0)
 	return svk.Embed(gi.KiT_MenuButton).(*gi.MenuButton)
 }
 
@@ -2459,6 +5585,64 @@ func (ge *GideView) ConfigStatusBar() {
 	lbl.SetProp("margin", 0)
 	lbl.SetProp("padding", 0)
 	lbl.SetProp("tab-size", 4)
+	leb := gi.AddNewMenuButton(sb, "sb-lineends")
+	leb.Tooltip = "line endings of the active file -- click to convert them"
+	leb.SetProp("margin", 0)
+	leb.SetProp("padding", units.NewValue(1, units.Px))
+	leb.MakeMenuFunc = ge.LineEndsMenu
+	bsa := gi.AddNewAction(sb, "sb-build")
+	bsa.SetProp("margin", 0)
+	bsa.SetProp("padding", units.NewValue(1, units.Px))
+	bsa.ActionSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_GideView).(*GideView)
+		gee.Problems()
+	})
+	nta := gi.AddNewAction(sb, "sb-notify")
+	nta.SetProp("margin", 0)
+	nta.SetProp("padding", units.NewValue(1, units.Px))
+	nta.ActionSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_GideView).(*GideView)
+		gee.Notifications()
+	})
+	brb := gi.AddNewMenuButton(sb, "sb-branch")
+	brb.SetText(ge.BranchLabel())
+	brb.Tooltip = "current version control branch of the project -- click to switch branches, create a new one, or fetch from the remotes"
+	brb.SetProp("margin", 0)
+	brb.SetProp("padding", units.NewValue(1, units.Px))
+	brb.MakeMenuFunc = ge.BranchMenu
+	jbl := sb.AddNewChild(gi.KiT_Label, "sb-jobs").(*gi.Label)
+	jbl.SetProp("margin", 0)
+	jbl.SetProp("padding", units.NewValue(1, units.Px))
+	jbl.SetProp("color", "grey")
+	ge.BgJobs.Mu.Lock()
+	ge.BgJobs.ChangeFunc = ge.UpdateJobsStatus
+	ge.BgJobs.Mu.Unlock()
+}
+
+// UpdateJobsStatus updates the statusbar indicator of the background jobs
+// of the project, shown while any are running or waiting
+func (ge *GideView) UpdateJobsStatus() {
+	sb := ge.StatusBar()
+	if sb == nil || ge.IsDeleted() || ge.IsDestroyed() {
+		return
+	}
+	jbl, ok := sb.ChildByName("sb-jobs", 3).(*gi.Label)
+	if !ok {
+		return
+	}
+	text := ""
+	if nrun, nq := ge.BgJobs.Counts(); nrun+nq > 0 {
+		text = fmt.Sprintf("⟳ %d", nrun+nq)
+	}
+	if text == jbl.Text {
+		return
+	}
+	wupdt := ge.TopUpdateStart()
+	defer ge.TopUpdateEnd(wupdt)
+	updt := sb.UpdateStart()
+	jbl.SetText(text)
+	jbl.Tooltip = ge.BgJobs.Status()
+	sb.UpdateEnd(updt)
 }
 
 // ConfigToolbar adds a GideView toolbar.
@@ -2510,48 +5694,12 @@ func (ge *GideView) ConfigSplitView() {
 		}
 	})
 
-	for i := 0; i < NTextViews; i++ {
-		txnm := fmt.Sprintf("%d", i)
-		txly := gi.AddNewLayout(split, "textlay-"+txnm, gi.LayoutVert)
-		txly.SetStretchMaxWidth()
-		txly.SetStretchMaxHeight()
-		txly.SetReRenderAnchor() // anchor here: SplitView will only anchor Frame, but we just have layout
-
-		// need to sandbox the button in its own layer to isolate FullReRender issues
-		txbly := gi.AddNewLayout(txly, "butlay-"+txnm, gi.LayoutVert)
-		txbly.SetProp("spacing", units.NewEm(0))
-		txbly.SetStretchMaxWidth()
-		txbly.SetReRenderAnchor() // anchor here!
-
-		txbut := gi.AddNewMenuButton(txbly, "textbut-"+txnm)
-		txbut.SetStretchMaxWidth()
-		txbut.SetText("textview: " + txnm)
-		txbut.MakeMenuFunc = ge.TextViewButtonMenu
-		txbut.ButtonSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-			if sig == int64(gi.ButtonClicked) {
-				gee, _ := recv.Embed(KiT_GideView).(*GideView)
-				idx := 0
-				nm := send.Name()
-				nln := len(nm)
-				if nm[nln-1] == '1' {
-					idx = 1
-				}
-				gee.SetActiveTextViewIdx(idx)
-			}
-		})
-
-		txily := gi.AddNewLayout(txly, "textilay-"+txnm, gi.LayoutVert)
-		txily.SetStretchMaxWidth()
-		txily.SetStretchMaxHeight()
-		txily.SetMinPrefWidth(units.NewCh(80))
-		txily.SetMinPrefHeight(units.NewEm(40))
-
-		ted := gide.AddNewTextView(txily, "textview-"+txnm)
-		ted.TextViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-			gee, _ := recv.Embed(KiT_GideView).(*GideView)
-			tee := send.Embed(gide.KiT_TextView).(*gide.TextView)
-			gee.TextViewSig(tee, giv.TextViewSignals(sig))
-		})
+	for i := 0; i < NTextPanels; i++ {
+		var pl *gide.PaneLayout
+		if i < len(ge.Prefs.Panes) {
+			pl = ge.Prefs.Panes[i]
+		}
+		ge.ConfigPaneLayout(split, pl)
 	}
 
 	ge.ConfigTextViews()
@@ -2562,7 +5710,10 @@ func (ge *GideView) ConfigSplitView() {
 		gee, _ := recv.Embed(KiT_GideView).(*GideView)
 		tvsig := gi.TabViewSignals(sig)
 		switch tvsig {
+		case gi.TabSelected:
+			gee.LazyTabs.Config(gee.Tabs().TabName(data.(int)))
 		case gi.TabDeleted:
+			gee.LazyTabs.Remove(data.(string))
 			gee.TabDeleted(data.(string))
 			if data == "Find" {
 				ge.ActiveTextView().ClearHighlights()
@@ -2574,18 +5725,143 @@ func (ge *GideView) ConfigSplitView() {
 	split.UpdateEnd(updt)
 }
 
+// ConfigPaneLayout adds editor panes to given parent according to given
+// layout, which can be nil for a single pane
+func (ge *GideView) ConfigPaneLayout(par ki.Ki, pl *gide.PaneLayout) {
+	if pl == nil || len(pl.Panes) == 0 {
+		ge.AddTextPane(par, par.NumChildren())
+		return
+	}
+	psv := gi.AddNewSplitView(par, ge.NewPaneName("panesplit"))
+	psv.Dim = pl.Dim
+	for _, sp := range pl.Panes {
+		ge.ConfigPaneLayout(psv, sp)
+	}
+	psv.SetSplits(pl.Splits...)
+}
+
+// NewPaneName returns a unique name for a new editor pane element, using given prefix
+func (ge *GideView) NewPaneName(prefix string) string {
+	nm := fmt.Sprintf("%s-%d", prefix, ge.NPanes)
+	ge.NPanes++
+	return nm
+}
+
+// AddTextPane adds a new editor pane, with menu button and text view, to
+// given parent at given index -- returns the text view
+func (ge *GideView) AddTextPane(par ki.Ki, at int) *gide.TextView {
+	txnm := fmt.Sprintf("%d", ge.NPanes)
+	ge.NPanes++
+	txly := par.InsertNewChild(gi.KiT_Layout, at, "textlay-"+txnm).(*gi.Layout)
+	txly.Lay = gi.LayoutVert
+	txly.SetStretchMaxWidth()
+	txly.SetStretchMaxHeight()
+	txly.SetReRenderAnchor() // anchor here: SplitView will only anchor Frame, but we just have layout
+
+	// need to sandbox the button in its own layer to isolate FullReRender issues
+	txbly := gi.AddNewLayout(txly, "butlay-"+txnm, gi.LayoutVert)
+	txbly.SetProp("spacing", units.NewEm(0))
+	txbly.SetStretchMaxWidth()
+	txbly.SetReRenderAnchor() // anchor here!
+
+	txbut := gi.AddNewMenuButton(txbly, "textbut-"+txnm)
+	txbut.SetStretchMaxWidth()
+	txbut.SetText("textview: " + txnm)
+	txbut.MakeMenuFunc = ge.TextViewButtonMenu
+	txbut.ButtonSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		if sig == int64(gi.ButtonClicked) {
+			gee, _ := recv.Embed(KiT_GideView).(*GideView)
+			tee := PaneTextView(send.Parent().Parent())
+			gee.SetActiveTextViewIdx(gee.TextViewIndex(tee))
+		}
+	})
+
+	fts := gide.AddNewFileTabs(txbly, "filetabs-"+txnm)
+	fts.FileTabSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_GideView).(*GideView)
+		ftt := send.Embed(gide.KiT_FileTabs).(*gide.FileTabs)
+		gee.FileTabsSig(ftt, gide.FileTabsSignals(sig), data.(gi.FileName))
+	})
+
+	stv := gide.AddNewStickyView(txbly, "sticky-"+txnm)
+
+	txily := gi.AddNewLayout(txly, "textilay-"+txnm, gi.LayoutVert)
+	txily.SetStretchMaxWidth()
+	txily.SetStretchMaxHeight()
+	txily.SetMinPrefWidth(units.NewCh(80))
+	txily.SetMinPrefHeight(units.NewEm(40))
+
+	ted := gide.AddNewTextView(txily, "textview-"+txnm)
+	ted.Sticky = stv
+	stv.View = ted
+	ted.TextViewSig.Connect(ge.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
+		gee, _ := recv.Embed(KiT_GideView).(*GideView)
+		tee := send.Embed(gide.KiT_TextView).(*gide.TextView)
+		gee.TextViewSig(tee, giv.TextViewSignals(sig))
+	})
+	return ted
+}
+
 // ConfigTextViews configures text views according to current settings
 func (ge *GideView) ConfigTextViews() {
-	for i := 0; i < NTextViews; i++ {
-		tv := ge.TextViewByIndex(i)
-		if ge.Prefs.Editor.WordWrap {
-			tv.SetProp("white-space", gist.WhiteSpacePreWrap)
-		} else {
-			tv.SetProp("white-space", gist.WhiteSpacePre)
+	for _, tv := range ge.TextViews() {
+		ge.ConfigTextView(tv)
+	}
+}
+
+// ConfigTextView configures given text view according to current settings,
+// and its zoom
+func (ge *GideView) ConfigTextView(tv *gide.TextView) {
+	if ge.Prefs.Editor.WordWrap {
+		tv.SetProp("white-space", gist.WhiteSpacePreWrap)
+	} else {
+		tv.SetProp("white-space", gist.WhiteSpacePre)
+	}
+	tv.SetProp("tab-size", ge.Prefs.Editor.TabSize)
+	tv.SetProp("font-family", gi.Prefs.MonoFont)
+	fsz := ge.Prefs.FontSize
+	if zm := tv.ZoomFactor(); zm != 1 {
+		if fsz <= 0 {
+			fsz = gide.TextViewFontSize
 		}
-		tv.SetProp("tab-size", ge.Prefs.Editor.TabSize)
-		tv.SetProp("font-family", gi.Prefs.MonoFont)
+		fsz *= zm
+	}
+	if fsz > 0 {
+		tv.SetProp("font-size", units.NewPt(fsz))
+	} else {
+		tv.DeleteProp("font-size")
+	}
+}
+
+// ZoomPane zooms the font of the active editor pane in (steps > 0) or out
+// (steps < 0) by TextViewZoomStep per step, or resets it for 0 steps -- the
+// zoom of each pane is saved in the session
+func (ge *GideView) ZoomPane(steps int) {
+	tv := ge.ActiveTextView()
+	if tv == nil {
+		return
 	}
+	tv.ZoomSteps(steps)
+	updt := ge.UpdateStart()
+	ge.ConfigTextView(tv)
+	ge.SetFullReRender()
+	ge.UpdateEnd(updt)
+	ge.SetStatus(fmt.Sprintf("pane zoom: %d%%", int(mat32.Round(tv.ZoomFactor()*100))))
+}
+
+// ZoomPaneIn zooms in the font of the active editor pane
+func (ge *GideView) ZoomPaneIn() {
+	ge.ZoomPane(1)
+}
+
+// ZoomPaneOut zooms out the font of the active editor pane
+func (ge *GideView) ZoomPaneOut() {
+	ge.ZoomPane(-1)
+}
+
+// ZoomPaneReset resets the font of the active editor pane to the normal size
+func (ge *GideView) ZoomPaneReset() {
+	ge.ZoomPane(0)
 }
 
 // UpdateTextButtons updates textview menu buttons
@@ -2593,9 +5869,8 @@ func (ge *GideView) ConfigTextViews() {
 // doesn't do anything unless a change is required -- safe to call frequently.
 func (ge *GideView) UpdateTextButtons() {
 	ati := ge.ActiveTextViewIdx
-	for i := 0; i < NTextViews; i++ {
-		tv := ge.TextViewByIndex(i)
-		mb := ge.TextViewButtonByIndex(i)
+	for i, tv := range ge.TextViews() {
+		mb := tv.Parent().Parent().Child(0).Child(0).Embed(gi.KiT_MenuButton).(*gi.MenuButton)
 		txnm := "<no file>"
 		if tv.Buf != nil {
 			txnm = giv.DirAndFile(string(tv.Buf.Filename))
@@ -2614,12 +5889,8 @@ func (ge *GideView) UpdateTextButtons() {
 }
 
 func (ge *GideView) TextViewButtonMenu(obj ki.Ki, m *gi.Menu) {
-	idx := 0
-	nm := obj.Name()
-	nln := len(nm)
-	if nm[nln-1] == '1' {
-		idx = 1
-	}
+	tv := PaneTextView(obj.Parent().Parent())
+	idx := ge.TextViewIndex(tv)
 	opn := ge.OpenNodes.Strings()
 	*m = gi.Menu{}
 
@@ -2630,7 +5901,6 @@ func (ge *GideView) TextViewButtonMenu(obj ki.Ki, m *gi.Menu) {
 
 	m.AddSeparator("file-sep")
 
-	tv := ge.TextViewByIndex(idx)
 	for i, n := range opn {
 		m.AddAction(gi.ActOpts{Label: n, Data: i}, ge.This(),
 			func(recv, send ki.Ki, sig int64, data interface{}) {
@@ -2666,8 +5936,18 @@ func (ge *GideView) FileNodeOpened(fn *giv.FileNode, tvn *gide.FileTreeView) {
 	switch fn.Info.Cat {
 	case filecat.Folder:
 		if !fn.IsOpen() {
+			gfn, isg := fn.This().Embed(gide.KiT_FileNode).(*gide.FileNode)
+			if isg {
+				if msg := gfn.LinkDirBlocked(); msg != "" {
+					tvn.SetClosed()
+					ge.SetStatus(msg)
+					return
+				}
+			}
 			tvn.SetOpen()
-			fn.OpenDir()
+			if !isg || !gfn.OpenDirLazy() {
+				fn.OpenDir()
+			}
 		}
 		return
 	case filecat.Exe:
@@ -2684,7 +5964,7 @@ func (ge *GideView) FileNodeOpened(fn *giv.FileNode, tvn *gide.FileTreeView) {
 		return
 	case filecat.Font, filecat.Video, filecat.Model, filecat.Audio, filecat.Sheet, filecat.Bin,
 		filecat.Archive, filecat.Image:
-		ge.ExecCmdNameFileNode(fn, gide.CmdName("Open File"), true, true) // sel, clear
+		ge.ViewAsset(fn)
 		return
 	}
 
@@ -2700,7 +5980,7 @@ func (ge *GideView) FileNodeOpened(fn *giv.FileNode, tvn *gide.FileTreeView) {
 		}
 	}
 	if !edit {
-		ge.ExecCmdNameFileNode(fn, gide.CmdName("Open File"), true, true) // sel, clear
+		ge.ViewAsset(fn)
 		return
 	}
 	// program, document, data
@@ -2722,6 +6002,19 @@ func (ge *GideView) FileNodeOpened(fn *giv.FileNode, tvn *gide.FileTreeView) {
 
 }
 
+// ViewAsset views given file node in a main tab, for files that are not
+// edited as text: images are shown with zoom controls, and other files show
+// a summary, with an option to open them in an external application
+func (ge *GideView) ViewAsset(fn *giv.FileNode) *gide.AssetView {
+	avi := ge.RecycleTab(gide.AssetViewTabName(string(fn.FPath)), gide.KiT_AssetView, true) // sel
+	if avi == nil {
+		return nil
+	}
+	av := avi.Embed(gide.KiT_AssetView).(*gide.AssetView)
+	av.Config(ge, string(fn.FPath))
+	return av
+}
+
 // FileNodeClosed is called whenever file tree browser node is closed
 func (ge *GideView) FileNodeClosed(fn *giv.FileNode, tvn *gide.FileTreeView) {
 	if fn.IsDir() {
@@ -2738,7 +6031,12 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 		fmt.Printf("GideView KeyInput: %v\n", ge.Path())
 	}
 	gkf := gi.KeyFun(kc)
+	if ge.MacroRecording {
+		ge.Macro.Keys = append(ge.Macro.Keys, gide.NewMacroKey(kt))
+	}
+	nkeys := 1 // number of keys in sequence
 	if ge.KeySeq1 != "" {
+		nkeys = 2
 		kf = gide.KeyFun(ge.KeySeq1, kc)
 		seqstr := string(ge.KeySeq1) + " " + string(kc)
 		if kf == gide.KeyFunNil || kc == "Escape" {
@@ -2778,11 +6076,18 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 	switch gkf {
 	case gi.KeyFunFind:
 		kt.SetProcessed()
-		tv := ge.ActiveTextView()
-		if tv != nil && tv.HasSelection() {
-			ge.Prefs.Find.Find = string(tv.Selection().ToBytes())
+		ge.SearchFile()
+	case gi.KeyFunZoomIn, gi.KeyFunZoomOut:
+		// in an editor pane, zoom just its font -- elsewhere, the window
+		// zooms the whole user interface
+		if tv := ge.ActiveTextView(); tv != nil && tv.HasFocus() {
+			kt.SetProcessed()
+			if gkf == gi.KeyFunZoomIn {
+				ge.ZoomPane(1)
+			} else {
+				ge.ZoomPane(-1)
+			}
 		}
-		giv.CallMethod(ge, "Find", ge.Viewport)
 	}
 	if kt.IsProcessed() {
 		return
@@ -2794,9 +6099,52 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 	case gide.KeyFunPrevPanel:
 		kt.SetProcessed()
 		ge.FocusPrevPanel()
+	case gide.KeyFunNextPane:
+		kt.SetProcessed()
+		ge.FocusNextPane()
+	case gide.KeyFunPrevPane:
+		kt.SetProcessed()
+		ge.FocusPrevPane()
+	case gide.KeyFunNavBack:
+		kt.SetProcessed()
+		ge.NavBack()
+	case gide.KeyFunNavForward:
+		kt.SetProcessed()
+		ge.NavForward()
+	case gide.KeyFunLastEdit:
+		kt.SetProcessed()
+		ge.GoToLastEdit()
 	case gide.KeyFunFileOpen:
 		kt.SetProcessed()
 		giv.CallMethod(ge, "ViewFile", ge.Viewport)
+	case gide.KeyFunQuickOpen:
+		kt.SetProcessed()
+		ge.QuickOpen()
+	case gide.KeyFunNextProblem:
+		kt.SetProcessed()
+		ge.NextProblem()
+	case gide.KeyFunPrevProblem:
+		kt.SetProcessed()
+		ge.PrevProblem()
+	case gide.KeyFunFocusMode:
+		kt.SetProcessed()
+		ge.ToggleFocusMode()
+	case gide.KeyFunCmdPalette:
+		kt.SetProcessed()
+		ge.CommandPalette()
+	case gide.KeyFunContextMenu:
+		kt.SetProcessed()
+		ge.FocusContextMenu()
+	case gide.KeyFunDescribeFocus:
+		kt.SetProcessed()
+		ge.DescribeFocus()
+	case gide.KeyFunFindInFiles:
+		kt.SetProcessed()
+		tv := ge.ActiveTextView()
+		if tv != nil && tv.HasSelection() {
+			ge.Prefs.Find.Find = string(tv.Selection().ToBytes())
+		}
+		giv.CallMethod(ge, "Find", ge.Viewport)
 	case gide.KeyFunBufSelect:
 		kt.SetProcessed()
 		ge.SelectOpenNode()
@@ -2852,6 +6200,18 @@ func (ge *GideView) GideViewKeys(kt *key.ChordEvent) {
 	case gide.KeyFunRunProj:
 		kt.SetProcessed()
 		ge.Run()
+	case gide.KeyFunMacroRec:
+		kt.SetProcessed()
+		if ge.MacroRecording && len(ge.Macro.Keys) >= nkeys { // don't record the stop keys
+			ge.Macro.Keys = ge.Macro.Keys[:len(ge.Macro.Keys)-nkeys]
+		}
+		ge.MacroRecord()
+	case gide.KeyFunMacroPlay:
+		kt.SetProcessed()
+		if ge.MacroRecording && len(ge.Macro.Keys) >= nkeys {
+			ge.Macro.Keys = ge.Macro.Keys[:len(ge.Macro.Keys)-nkeys]
+		}
+		ge.MacroPlay(1)
 	}
 }
 
@@ -2874,6 +6234,18 @@ func (ge *GideView) OSFileEvent() {
 	})
 }
 
+// EventLoopEvent runs the EventLoopFunc sent to the window (see
+// RunOnEventLoop)
+func (ge *GideView) EventLoopEvent() {
+	ge.ConnectEvent(oswin.CustomEventType, gi.RegPri, func(recv, send ki.Ki, sig int64, d interface{}) {
+		ce := d.(*oswin.CustomEvent)
+		if fn, ok := ce.Data.(EventLoopFunc); ok && !ce.IsProcessed() {
+			ce.SetProcessed()
+			fn()
+		}
+	})
+}
+
 func (ge *GideView) Render2D() {
 	if len(ge.Kids) > 0 {
 		ge.ToolBar().UpdateActions()
@@ -2894,6 +6266,7 @@ func (ge *GideView) ConnectEvents2D() {
 	}
 	ge.KeyChordEvent()
 	ge.OSFileEvent()
+	ge.EventLoopEvent()
 }
 
 // GideViewInactiveEmptyFunc is an ActionUpdateFunc that inactivates action if project is empty
@@ -2927,6 +6300,16 @@ var GideViewInactiveTextSelectionFunc = giv.ActionUpdateFunc(func(gei interface{
 	}
 })
 
+// GideViewInactiveJSONFunc is an ActionUpdateFunc that inactivates action if the active file is not a JSON document
+var GideViewInactiveJSONFunc = giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+	ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+	if !ge.IsConfiged() {
+		return
+	}
+	atv := ge.ActiveTextView()
+	act.SetActiveState(atv != nil && atv.Buf != nil && gide.DocStructLang(atv.Buf) == "json")
+})
+
 var GideViewProps = ki.Props{
 	"EnumType:Flag":    gi.KiT_NodeFlags,
 	"background-color": &gi.Prefs.Colors.Background,
@@ -2996,23 +6379,29 @@ var GideViewProps = ki.Props{
 			},
 		}},
 		{"sep-find", ki.BlankProp{}},
-		{"CursorToHistPrev", ki.Props{
-			"icon":     "wedge-left",
-			"shortcut": gi.KeyFunHistPrev,
-			"label":    "",
-			"desc":     "move cursor to previous location in active text view",
+		{"NavBack", ki.Props{
+			"icon":  "wedge-left",
+			"label": "",
+			"desc":  "move back to previous location in navigation history, across files",
+			"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+				return key.Chord(gide.ChordForFun(gide.KeyFunNavBack).String())
+			}),
 		}},
-		{"CursorToHistNext", ki.Props{
-			"icon":     "wedge-right",
-			"shortcut": gi.KeyFunHistNext,
-			"label":    "",
-			"desc":     "move cursor to next location in active text view",
+		{"NavForward", ki.Props{
+			"icon":  "wedge-right",
+			"label": "",
+			"desc":  "move forward to next location in navigation history, across files",
+			"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+				return key.Chord(gide.ChordForFun(gide.KeyFunNavForward).String())
+			}),
 		}},
 		{"Find", ki.Props{
-			"label":    "Find...",
-			"icon":     "search",
-			"desc":     "Find / replace in all open folders in file browser",
-			"shortcut": gi.KeyFunFind,
+			"label": "Find...",
+			"icon":  "search",
+			"desc":  "Find / replace in all open folders in file browser",
+			"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+				return key.Chord(gide.ChordForFun(gide.KeyFunFindInFiles).String())
+			}),
 			"Args": ki.PropSlice{
 				{"Search For", ki.Props{
 					"default-field": "Prefs.Find.Find",
@@ -3048,6 +6437,24 @@ var GideViewProps = ki.Props{
 			"label": "Spelling",
 			"icon":  "spelling",
 		}},
+		{"Todos", ki.Props{
+			"label": "TODOs",
+			"icon":  "file-text",
+			"desc":  "show the TODO, FIXME, HACK and XXX comments in the project files",
+		}},
+		{"Problems", ki.Props{
+			"icon": "info",
+			"desc": "show the errors and warnings found by the build, test, vet and lint commands",
+		}},
+		{"ActionLog", ki.Props{
+			"label": "Action Log",
+			"icon":  "file-text",
+			"desc":  "show the log of the operations done in the project -- files saved, commands run with their arguments and exit codes, version control actions, refactorings -- filtered by a query, e.g., kind:cmd since:1h",
+		}},
+		{"Notifications", ki.Props{
+			"icon": "file-text",
+			"desc": "show the notifications of the project: commands finished, lint results, files changed on disk, updates available -- with links to act on them",
+		}},
 		{"sep-file", ki.BlankProp{}},
 		{"Build", ki.Props{
 			"icon": "terminal",
@@ -3063,6 +6470,24 @@ var GideViewProps = ki.Props{
 				return key.Chord(gide.ChordForFun(gide.KeyFunRunProj).String())
 			}),
 		}},
+		{"SetBuildConfig", ki.Props{
+			"icon":         "gear",
+			"label":        "Build Config",
+			"desc":         "select the build configuration (target) used by Build, Run and Debug -- configurations are defined in Project Prefs",
+			"submenu-func": giv.SubMenuFunc(BuildConfigNames),
+			"Args": ki.PropSlice{
+				{"Config Name", ki.Props{}},
+			},
+		}},
+		{"SetRunMode", ki.Props{
+			"icon":         "play",
+			"label":        "Run Mode",
+			"desc":         "select the mode in which Build builds the project, and Run runs it: Normal, or with the race detector (Race), or the memory (MSan) or address (ASan) sanitizer -- their reports in the output are grouped by race, with links to the stack frames",
+			"submenu-func": giv.SubMenuFunc(RunModeNames),
+			"Args": ki.PropSlice{
+				{"Mode Name", ki.Props{}},
+			},
+		}},
 		{"Debug", ki.Props{
 			"icon": "terminal",
 			"desc": "debug currently selected executable -- if none selected, prompts to select one",
@@ -3133,6 +6558,15 @@ var GideViewProps = ki.Props{
 					{"File Name", ki.Props{}},
 				},
 			}},
+			{"RecentProjs", ki.Props{
+				"label": "Recent Projects...",
+				"desc":  "select from the recently-opened projects, showing when they were last opened",
+			}},
+			{"TogglePinProj", ki.Props{
+				"label":    "Pin Project",
+				"desc":     "pin (or unpin) this project in the recent projects -- pinned projects are listed first, and kept when the list is reset or full",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"OpenProj", ki.Props{
 				"shortcut": gi.KeyFunMenuOpen,
 				"label":    "Open Project...",
@@ -3177,7 +6611,20 @@ var GideViewProps = ki.Props{
 						{"File Name", ki.Props{
 							"width": 60,
 						}},
-						{"Add To Version Control", ki.Props{}},
+						{"Add To Version Control", ki.Props{}},
+					},
+				}},
+				{"EditFileTemplate", ki.Props{
+					"label":    "Edit File Template...",
+					"desc":     "edit the template of the new files of a given name: go for the .go files, _test.go, Makefile... -- or HEADER for the header inserted by {Header} (e.g., a license), commented out for each language -- of this project, or of the user for all projects.  Variables: {FileName}, {FileNameNoExt}, {FileDir}, {FileDirRel}, {Package}, {Project}, {Author}, {Email}, {Date}, {Year}, {Header}",
+					"updtfunc": GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Name", ki.Props{
+							"width": 30,
+						}},
+						{"Project", ki.Props{
+							"default": true,
+						}},
 					},
 				}},
 			}},
@@ -3215,6 +6662,14 @@ var GideViewProps = ki.Props{
 					}},
 				},
 			}},
+			{"QuickOpen", ki.Props{
+				"label": "Quick Open...",
+				"desc":  "find and open any file in the project by typing any part of its path",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunQuickOpen).String())
+				}),
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"SaveActiveView", ki.Props{
 				"label": "Save File",
 				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
@@ -3235,6 +6690,17 @@ var GideViewProps = ki.Props{
 					}},
 				},
 			}},
+			{"ExportActiveView", ki.Props{
+				"label":    "Export...",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"desc":     "export the selection of the active text view, or all of its file, with its syntax highlighting: as a PDF if the file name ends in .pdf (using wkhtmltopdf or chromium), else as a standalone HTML page",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".html,.pdf",
+					}},
+					{"Line Numbers", ki.Props{}},
+				},
+			}},
 			{"RevertActiveView", ki.Props{
 				"desc":     "Revert active file to last saved version: this will lose all active changes -- are you sure?",
 				"confirm":  true,
@@ -3248,11 +6714,29 @@ var GideViewProps = ki.Props{
 					return key.Chord(gide.ChordForFun(gide.KeyFunBufClose).String())
 				}),
 			}},
+			{"UndoDelete", ki.Props{
+				"label": "Undo Delete",
+				"desc":  "restore the files of the most recent deletion in the file tree from the trash",
+				"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+					ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+					act.SetInactiveState(!ge.Trash.CanUndo())
+				}),
+			}},
 			{"sep-prefs", ki.BlankProp{}},
 			{"EditProjPrefs", ki.Props{
 				"label":    "Project Prefs...",
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"ImportHiStyle", ki.Props{
+				"label":    "Import Color Theme...",
+				"desc":     "import a VSCode (.json) or TextMate (.tmTheme) color theme as a custom highlighting style, with its scopes mapped to the token categories, and use it for this project",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".json,.tmTheme",
+					}},
+				},
+			}},
 			{"sep-close", ki.BlankProp{}},
 			{"Close Window", ki.BlankProp{}},
 		}},
@@ -3299,6 +6783,56 @@ var GideViewProps = ki.Props{
 					},
 				}},
 			}},
+			{"Macros", ki.PropSlice{
+				{"MacroRecord", ki.Props{
+					"label": "Record / Stop",
+					"desc":  "start recording a new keyboard macro, or stop recording if currently recording",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(gide.ChordForFun(gide.KeyFunMacroRec).String())
+					}),
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"MacroPlay", ki.Props{
+					"label": "Play...",
+					"desc":  "play back the last recorded keyboard macro, given number of times",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(gide.ChordForFun(gide.KeyFunMacroPlay).String())
+					}),
+					"updtfunc": GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Repeat Count", ki.Props{
+							"default": 1,
+						}},
+					},
+				}},
+				{"MacroPlayNamed", ki.Props{
+					"label":    "Play Named...",
+					"desc":     "play back a saved named keyboard macro, given number of times",
+					"updtfunc": GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Macro Name", ki.Props{}},
+						{"Repeat Count", ki.Props{
+							"default": 1,
+						}},
+					},
+				}},
+				{"MacroSaveAs", ki.Props{
+					"label":    "Save As...",
+					"desc":     "save the last recorded keyboard macro under a name, in preferences",
+					"updtfunc": GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Name", ki.Props{
+							"width": 60,
+						}},
+						{"Desc", ki.Props{
+							"width": 60,
+						}},
+					},
+				}},
+				{"MacrosEdit", ki.Props{
+					"label": "Edit...",
+				}},
+			}},
 			{"sep-undo", ki.BlankProp{}},
 			{"Undo", ki.Props{
 				"keyfun": gi.KeyFunUndo,
@@ -3307,9 +6841,17 @@ var GideViewProps = ki.Props{
 				"keyfun": gi.KeyFunRedo,
 			}},
 			{"sep-find", ki.BlankProp{}},
-			{"Find", ki.Props{
-				"label":    "Find...",
+			{"SearchFile", ki.Props{
+				"label":    "Search In File",
 				"shortcut": gi.KeyFunFind,
+				"desc":     "open the incremental search bar for the active file, which highlights all matches as you type",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Find", ki.Props{
+				"label": "Find...",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunFindInFiles).String())
+				}),
 				"desc":     "Find / replace in all open folders in file browser",
 				"updtfunc": GideViewInactiveEmptyFunc,
 				"Args": ki.PropSlice{
@@ -3340,6 +6882,16 @@ var GideViewProps = ki.Props{
 					}},
 				},
 			}},
+			{"SavedSearches", ki.Props{
+				"label":    "Saved Searches",
+				"desc":     "show the panel of named searches saved from the Find panel, to re-run them with one click",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"WorkspaceSymbols", ki.Props{
+				"label":    "Workspace Symbols...",
+				"desc":     "find any symbol declared in the project by typing any part of its name, using the project symbol index",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"ReplaceInActive", ki.Props{
 				"label":    "Replace In Active...",
 				"shortcut": gi.KeyFunReplace,
@@ -3350,6 +6902,11 @@ var GideViewProps = ki.Props{
 				"label":    "Spelling...",
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"EditSpellDict", ki.Props{
+				"label":    "Edit Project Dictionary",
+				"desc":     "edit the dictionary file of the words accepted by the spell checking in this project, one per line",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"ShowCompletions", ki.Props{
 				"keyfun":   gi.KeyFunComplete,
 				"updtfunc": GideViewInactiveEmptyFunc,
@@ -3394,6 +6951,26 @@ var GideViewProps = ki.Props{
 				"confirm":  true,
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"ConvertToLF", ki.Props{
+				"label":    "Convert To LF",
+				"desc":     "converts the line endings of the active file to LF (\\n, as on Unix and macOS), written when it is saved",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"ConvertToCRLF", ki.Props{
+				"label":    "Convert To CRLF",
+				"desc":     "converts the line endings of the active file to CRLF (\\r\\n, as on Windows), written when it is saved",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"FormatJSON", ki.Props{
+				"label":    "Format JSON",
+				"desc":     "indents the active file, if a JSON document, with tabs or spaces as set in the editor prefs",
+				"updtfunc": GideViewInactiveJSONFunc,
+			}},
+			{"MinifyJSON", ki.Props{
+				"label":    "Minify JSON",
+				"desc":     "removes all the white space of the active file, if a JSON document, as a single line",
+				"updtfunc": GideViewInactiveJSONFunc,
+			}},
 		}},
 		{"View", ki.PropSlice{
 			{"Panels", ki.PropSlice{
@@ -3419,6 +6996,53 @@ var GideViewProps = ki.Props{
 					"updtfunc": GideViewInactiveEmptyFunc,
 				}},
 			}},
+			{"Panes", ki.PropSlice{
+				{"SplitPaneHoriz", ki.Props{
+					"label":    "Split Right",
+					"desc":     "split the active editor pane in two, side-by-side, with the new pane viewing the same file",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"SplitPaneVert", ki.Props{
+					"label":    "Split Down",
+					"desc":     "split the active editor pane in two, one above the other, with the new pane viewing the same file",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"ClosePane", ki.Props{
+					"label":    "Close Pane",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"sep-pane", ki.BlankProp{}},
+				{"FocusNextPane", ki.Props{
+					"label": "Focus Next",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(gide.ChordForFun(gide.KeyFunNextPane).String())
+					}),
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"FocusPrevPane", ki.Props{
+					"label": "Focus Prev",
+					"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+						return key.Chord(gide.ChordForFun(gide.KeyFunPrevPane).String())
+					}),
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"sep-zoom", ki.BlankProp{}},
+				{"ZoomPaneIn", ki.Props{
+					"label":    "Zoom In",
+					"desc":     "zoom in the font of the active editor pane -- the zoom keys (Ctrl+=) do this when the pane has the focus, and zoom the whole user interface elsewhere",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"ZoomPaneOut", ki.Props{
+					"label":    "Zoom Out",
+					"desc":     "zoom out the font of the active editor pane -- the zoom keys (Ctrl+-) do this when the pane has the focus, and zoom the whole user interface elsewhere",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"ZoomPaneReset", ki.Props{
+					"label":    "Reset Zoom",
+					"desc":     "reset the font of the active editor pane to the normal size",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+			}},
 			{"Splits", ki.PropSlice{
 				{"SplitsSetView", ki.Props{
 					"label":    "Set View",
@@ -3454,11 +7078,101 @@ var GideViewProps = ki.Props{
 					"label":    "Edit...",
 				}},
 			}},
+			{"ToggleShowIgnored", ki.Props{
+				"label":    "Show Ignored Files",
+				"desc":     "toggle showing files and directories ignored by .gitignore and .gideignore files grayed out in the file tree, instead of hiding them",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"ToggleFocusMode", ki.Props{
+				"label": "Distraction Free",
+				"desc":  "toggle the distraction-free mode, showing just the active editor pane, centered, without the file tree, tabs, toolbar and statusbar",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunFocusMode).String())
+				}),
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"CommandPalette", ki.Props{
+				"label": "Command Palette...",
+				"desc":  "find any command of the menus by typing part of its menu path, and run it",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunCmdPalette).String())
+				}),
+			}},
+			{"DescribeFocus", ki.Props{
+				"label": "Describe Focus",
+				"desc":  "announce the widget with the keyboard focus -- its role, name and state, and its panel -- in the statusbar, and with the speech command of the accessibility preferences",
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunDescribeFocus).String())
+				}),
+			}},
+			{"ViewTable", ki.Props{
+				"label": "View As Table",
+				"desc":  "show the active file, if a delimited text file (.csv, .tsv), as a table, with sortable and hideable columns, and editable cells written back to its text",
+				"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+					ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+					if !ge.IsConfiged() {
+						return
+					}
+					atv := ge.ActiveTextView()
+					act.SetActiveState(atv != nil && atv.Buf != nil && gide.IsCSVFile(string(atv.Buf.Filename)))
+				}),
+			}},
+			{"DocStructure", ki.Props{
+				"label": "Document Structure",
+				"desc":  "show the structure of the active file, if a JSON or YAML document, as a tree: clicking on a value selects it",
+				"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+					ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+					if !ge.IsConfiged() {
+						return
+					}
+					atv := ge.ActiveTextView()
+					act.SetActiveState(atv != nil && atv.Buf != nil && gide.DocStructLang(atv.Buf) != "")
+				}),
+			}},
 			{"OpenConsoleTab", ki.Props{
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
 		}},
 		{"Navigate", ki.PropSlice{
+			{"NavBack", ki.Props{
+				"label":    "Back",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunNavBack).String())
+				}),
+			}},
+			{"NavForward", ki.Props{
+				"label":    "Forward",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunNavForward).String())
+				}),
+			}},
+			{"GoToLastEdit", ki.Props{
+				"label":    "Last Edit",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunLastEdit).String())
+				}),
+			}},
+			{"NextProblem", ki.Props{
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunNextProblem).String())
+				}),
+			}},
+			{"PrevProblem", ki.Props{
+				"label":    "Previous Problem",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"shortcut-func": giv.ShortcutFunc(func(gei interface{}, act *gi.Action) key.Chord {
+					return key.Chord(gide.ChordForFun(gide.KeyFunPrevProblem).String())
+				}),
+			}},
+			{"Problems", ki.Props{
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Notifications", ki.Props{}},
+			{"sep-nav", ki.BlankProp{}},
 			{"Cursor", ki.PropSlice{
 				{"Back", ki.Props{
 					"keyfun": gi.KeyFunHistPrev,
@@ -3486,6 +7200,52 @@ var GideViewProps = ki.Props{
 			}},
 			{"Debug", ki.Props{}},
 			{"DebugTest", ki.Props{}},
+			{"Tests", ki.Props{
+				"label":    "Test Explorer",
+				"desc":     "show the tests of the project by package and file, for running them",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Lint", ki.Props{
+				"label":    "Lint (golangci-lint)",
+				"desc":     "run golangci-lint on all the packages of the project, showing its findings in the Problems panel -- set its path and config, and whether to run it on save, in the Lint project preferences",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"TestCoverage", ki.Props{
+				"label":    "Test With Coverage",
+				"desc":     "run the tests of the package of the active file with go test -coverprofile, marking the covered and uncovered lines in the line number gutter",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Coverage", ki.Props{
+				"desc":     "show the coverage of the packages and files from the last run of the tests with coverage",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"ArtifactsPanel", ki.Props{
+				"label":    "Artifacts",
+				"desc":     "list the executables and other files made by the build commands and the build matrix, with their size, build time and target, for running them, revealing them in the file manager, or copying their path",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"BuildMatrix", ki.Props{
+				"desc":     "build the main package for a list of targets (GOOS / GOARCH / tags) in parallel, showing the status of each and collecting the executables in a directory",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"GoMod", ki.Props{
+				"label":    "Go Mod Dependencies",
+				"desc":     "list the requirements of the go.mod of the project, with the latest versions from go list -m -u, for upgrading, downgrading and tidying them, and showing why a module is needed (its path in the module graph)",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Fuzz", ki.Props{
+				"desc":     "run go test -fuzz on a fuzz target of the package of the active file (the one at the cursor if any), with live counters of the corpus and crashes, and list the failing inputs written to testdata, for running or debugging the target on one",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"CheckVulns", ki.Props{
+				"label":    "Check Vulnerabilities",
+				"desc":     "run govulncheck on the packages of the project, listing the known vulnerabilities of the modules required, with the call paths to them from the project, linked to the calling lines, and the advisories",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Profile", ki.Props{
+				"desc":     "profile the tests or benchmarks of the package of the active file (cpu or memory), or a running program serving net/http/pprof, showing the top functions and the call tree of the profile, with links to the source -- or open it in the pprof web UI",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"DebugAttach", ki.Props{
 				"desc": "attach to an already running process: enter the process PID",
 				"Args": ki.PropSlice{
@@ -3500,6 +7260,101 @@ var GideViewProps = ki.Props{
 					}},
 				},
 			}},
+			{"WebPreview", ki.Props{
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"desc":     "open the active file (or project root) in the browser, served by a local web server -- html pages reload when files are saved",
+			}},
+			{"WebPreviewStop", ki.Props{
+				"label": "Stop Web Preview",
+				"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+					ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+					act.SetInactiveState(!ge.WebServer.IsRunning())
+				}),
+			}},
+			{"LiveRunStart", ki.Props{
+				"label":    "Live Run",
+				"desc":     "run the RunExec, rebuilding (with the BuildCmds, or Build Go Proj) and restarting it whenever the Go files of the project change, like air or reflex -- the restart events and its output are in the Live Run tab",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"LiveRunPause", ki.Props{
+				"label": "Pause / Resume Live Reload",
+				"desc":  "pause the rebuilding and restarting of the live run on changes, or resume it, rebuilding for the changes made while paused",
+				"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+					ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+					act.SetInactiveState(!ge.LiveRun.IsOn())
+				}),
+			}},
+			{"LiveRunStop", ki.Props{
+				"label": "Stop Live Run",
+				"updtfunc": giv.ActionUpdateFunc(func(gei interface{}, act *gi.Action) {
+					ge := gei.(ki.Ki).Embed(KiT_GideView).(*GideView)
+					act.SetInactiveState(!ge.LiveRun.IsOn())
+				}),
+			}},
+			{"Terminal", ki.Props{
+				"desc":     "open a terminal running your shell in the project root, in the Terminal tab -- GIDE_FILE, GIDE_DIR and GIDE_PROJ are set to the active file, its directory and the project root",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"NewTerminal", ki.Props{
+				"desc":     "open another terminal, in a new tab -- terminals can be renamed and split into panes from their toolbar, and are restarted in the working directories of their panes when the project is opened again",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"SSHTerminal", ki.Props{
+				"label":    "SSH Terminal...",
+				"desc":     "open a terminal with an SSH session to a host, of the SSHHosts preferences or of your ~/.ssh/config, in a new tab -- authentication is that of ssh (keys and the ssh agent), and the session is reopened, in the same directories, when the project is opened again",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Repl", ki.Props{
+				"label":    "REPL...",
+				"desc":     "open the console of a REPL: python, node, yaegi for Go, or those of the Repls preferences -- the input is evaluated with Control+Enter, and Control+Up / Control+Down step through the history of the project",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"EvalSelectionInRepl", ki.Props{
+				"label":    "Eval In REPL",
+				"desc":     "evaluate the selection, or else the line of the cursor, in the REPL console of the language of the file, opening it if needed -- after evaluating a line, the cursor moves to the next one",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"GoScratch", ki.Props{
+				"label":    "Go Scratchpad",
+				"desc":     "open the Go scratchpad, a playground for trying APIs without creating files: its statements are run with Control+Enter (in process by the embedded yaegi interpreter if only the standard library is imported, else built with go in the project, so that its packages can be imported), showing the value of each expression and assignment at the end of its line -- its text is kept in the project",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"SerialConsole", ki.Props{
+				"desc":     "open the Serial Console: the output of the serial port of the project (e.g., of a microcontroller flashed with TinyGo), with links to the files in it, optionally logged to a file, and a line for sending text to the port -- the device, baud rate and framing are set in the Serial project preferences, or from its toolbar",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"PluginCommand", ki.Props{
+				"label":    "Plugin Command...",
+				"desc":     "choose a command added by the plugins, which are lua scripts run by the Lua runtime embedded in gide, or executables or scripts (python, node, yaegi, sh) run as processes, in the plugins directory of the preferences directory, started for each project, handling its events (start, open, save, command) and using its buffers, selection, commands and panels -- the processes as JSON lines",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"NewPlugin", ki.Props{
+				"label": "New Plugin...",
+				"desc":  "create a plugin in the plugins directory, from a lua template showing the API, and open it -- Reload Plugins to start it",
+				"Args": ki.PropSlice{
+					{"Plugin Name", ki.Props{
+						"width": 20,
+					}},
+				},
+			}},
+			{"ReloadPlugins", ki.Props{
+				"desc":     "restart the plugins of the plugins directory, e.g., after adding or editing one",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"SetWSLDistro", ki.Props{
+				"label":        "WSL Distro",
+				"desc":         "on Windows, run the commands of the project (build, test, run, and debugging) in the selected distro of the Windows Subsystem for Linux, with the paths in their args, output links and in the debugger translated between the Windows and /mnt forms -- or Off to run them on Windows",
+				"updtfunc":     GideViewInactiveEmptyFunc,
+				"submenu-func": giv.SubMenuFunc(WSLDistroNames),
+				"Args": ki.PropSlice{
+					{"Distro Name", ki.Props{}},
+				},
+			}},
+			{"SendSelectionToTerminal", ki.Props{
+				"label":    "Send To Terminal",
+				"desc":     "send the selection, or else the line of the cursor, to the active terminal, as if typed -- for running it in the shell or a REPL (python, psql, etc) running there; after sending a line, the cursor moves to the next one",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"sep-run", ki.BlankProp{}},
 			{"Commit", ki.Props{
 				"updtfunc": GideViewInactiveEmptyFunc,
@@ -3512,10 +7367,76 @@ var GideViewProps = ki.Props{
 					{"Since Date", ki.Props{}},
 				},
 			}},
+			{"FileHistory", ki.Props{
+				"desc":     "show the history of commits of the active file, for diffs between its revisions, or against the working copy, and viewing it at a revision",
+				"updtfunc": GideViewInactiveTextViewFunc,
+			}},
 			{"VCSUpdateAll", ki.Props{
 				"label":    "VCS Update All",
 				"updtfunc": GideViewInactiveEmptyFunc,
 			}},
+			{"SwitchBranch", ki.Props{
+				"desc":         "switch the project repository to another branch",
+				"submenu-func": giv.SubMenuFunc(BranchNames),
+				"updtfunc":     GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Branch", ki.Props{}},
+				},
+			}},
+			{"NewBranch", ki.Props{
+				"desc":     "create a new branch from the current one in the project repository, and switch to it",
+				"updtfunc": GideViewInactiveEmptyFunc,
+				"Args": ki.PropSlice{
+					{"Branch Name", ki.Props{}},
+				},
+			}},
+			{"FetchPrune", ki.Props{
+				"label":    "Fetch / Prune",
+				"desc":     "fetch the branches of all the remotes of the project repository, removing those deleted there",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
+			{"Tags", ki.PropSlice{
+				{"ListTags", ki.Props{
+					"desc":     "show the tags of the project repository, highest version first, with their messages",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"NewTag", ki.Props{
+					"desc":     "create an annotated tag of the current commit",
+					"updtfunc": GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Tag Name", ki.Props{}},
+						{"Message", ki.Props{
+							"width": 60,
+						}},
+					},
+				}},
+				{"TagRelease", ki.Props{
+					"label":    "Tag Release...",
+					"desc":     "tag the current commit as a release, with the next semantic version suggested from the existing tags, and optionally push it",
+					"updtfunc": GideViewInactiveEmptyFunc,
+				}},
+				{"DeleteTag", ki.Props{
+					"desc":         "delete a tag from the project repository (not from the remotes)",
+					"submenu-func": giv.SubMenuFunc(TagNames),
+					"updtfunc":     GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Tag", ki.Props{}},
+					},
+				}},
+				{"PushTag", ki.Props{
+					"desc":         "push a tag to the origin remote",
+					"submenu-func": giv.SubMenuFunc(TagNames),
+					"updtfunc":     GideViewInactiveEmptyFunc,
+					"Args": ki.PropSlice{
+						{"Tag", ki.Props{}},
+					},
+				}},
+			}},
+			{"PullRequests", ki.Props{
+				"label":    "Pull Requests",
+				"desc":     "list the open pull / merge requests of the GitHub or GitLab repository of the project, to check them out, review their diffs, and open them and their linked issues in the browser",
+				"updtfunc": GideViewInactiveEmptyFunc,
+			}},
 			{"sep-cmd", ki.BlankProp{}},
 			{"ExecCmdNameActive", ki.Props{
 				"label":        "Exec Cmd",
@@ -3526,6 +7447,8 @@ var GideViewProps = ki.Props{
 				},
 			}},
 			{"DiffFiles", ki.Props{
+				"label":    "Compare Files...",
+				"desc":     "show the differences between any two files side by side, regardless of version control -- also in the file tree context menu, for two selected files",
 				"updtfunc": GideViewInactiveEmptyFunc,
 				"Args": ki.PropSlice{
 					{"File Name 1", ki.Props{}},
@@ -3561,6 +7484,11 @@ var GideViewProps = ki.Props{
 			},
 		}},
 		{"ExecCmd", ki.Props{}},
+		{"NewBranch", ki.Props{
+			"Args": ki.PropSlice{
+				{"Branch Name", ki.Props{}},
+			},
+		}},
 		{"ChooseRunExec", ki.Props{
 			"Args": ki.PropSlice{
 				{"Exec File Name", ki.Props{}},
@@ -3592,6 +7520,76 @@ func OpenGideProj(projfile string) (*gi.Window, *GideView) {
 	return NewGideWindow(projfile, projnm, root, false)
 }
 
+// ToolStatus is the exit status of gide run as a diff or merge tool, e.g.,
+// by git difftool or mergetool: 0 if resolved, 1 if not, 2 on error
+var ToolStatus = 0
+
+// toolWindow returns a new main window for the diff and merge tools
+func toolWindow(winm, title string) *gi.Window {
+	width := 1600
+	height := 1280
+	sc := oswin.TheApp.Screen(0)
+	if sc != nil {
+		scsz := sc.Geometry.Size()
+		width = int(.9 * float64(scsz.X))
+		height = int(.8 * float64(scsz.Y))
+	}
+	return gi.NewMainWindow(winm, title, width, height)
+}
+
+// DiffTool opens a window with the diffs of given files, as git difftool
+// -- ToolStatus is 0 when it is closed
+func DiffTool(afile, bfile string) error {
+	ab, err := ioutil.ReadFile(afile)
+	if err != nil {
+		return err
+	}
+	bb, err := ioutil.ReadFile(bfile)
+	if err != nil {
+		return err
+	}
+	win := toolWindow("gide-diff", "gide diff: "+afile+" "+bfile)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+	dv := mfr.AddNewChild(giv.KiT_DiffView, "diff-view").(*giv.DiffView)
+	dv.SetStretchMax()
+	dv.FileA = afile
+	dv.FileB = bfile
+	dv.DiffStrings(textbuf.BytesToLineStrings(ab, false), textbuf.BytesToLineStrings(bb, false))
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return nil
+}
+
+// MergeTool opens a window with the 3-way merge of given files, as git
+// mergetool, with the conflicts of the merged file resolved in it --
+// ToolStatus is 0 if the merge is finished as resolved, 1 if aborted
+func MergeTool(base, local, remote, merged string) error {
+	win := toolWindow("gide-merge", "gide merge: "+merged)
+	vp := win.WinViewport2D()
+	updt := vp.UpdateStart()
+	mfr := win.SetMainFrame()
+	mfr.Lay = gi.LayoutVert
+	mv := mfr.AddNewChild(gide.KiT_MergeView, "merge-view").(*gide.MergeView)
+	mv.Viewport = vp
+	if err := mv.Config(base, local, remote, merged); err != nil {
+		vp.UpdateEndNoSig(updt)
+		return err
+	}
+	ToolStatus = 1
+	mv.Done = func(ok bool) {
+		if ok {
+			ToolStatus = 0
+		}
+		win.Close()
+	}
+	vp.UpdateEndNoSig(updt)
+	win.GoStartEventLoop()
+	return nil
+}
+
 // NewGideWindow is common code for Open GideWindow from Proj or Path
 func NewGideWindow(path, projnm, root string, doPath bool) (*gi.Window, *GideView) {
 	winm := "gide-" + projnm
@@ -3619,6 +7617,7 @@ func NewGideWindow(path, projnm, root string, doPath bool) (*gi.Window, *GideVie
 
 	vp := win.WinViewport2D()
 	updt := vp.UpdateStart()
+	gide.Prefs.Access.ApplyFocusRing(vp)
 
 	mfr := win.SetMainFrame()
 	ge := mfr.AddNewChild(KiT_GideView, "gide").(*GideView)
@@ -3646,6 +7645,12 @@ func NewGideWindow(path, projnm, root string, doPath bool) (*gi.Window, *GideVie
 	})
 
 	win.OSWin.SetCloseCleanFunc(func(w oswin.Window) {
+		ge.WebServer.Stop()
+		ge.LiveRun.Stop()
+		ge.Plugins.Stop()
+		ge.FileWatch.Stop()
+		ge.BgJobs.CancelAll()
+		ge.SymIdx.Save()
 		if gi.MainWindows.Len() <= 1 {
 			go oswin.TheApp.Quit() // once main window is closed, quit
 		}
@@ -3656,6 +7661,7 @@ func NewGideWindow(path, projnm, root string, doPath bool) (*gi.Window, *GideVie
 	vp.UpdateEndNoSig(updt)
 
 	win.GoStartEventLoop()
+	gide.StartupMark("window")
 
 	return win, ge
 }
@@ -1,6 +1,7 @@
 module github.com/goki/gide
 
 require (
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/go-delve/delve v1.5.1
 	github.com/goki/gi v1.2.2
 	github.com/goki/ki v1.1.1
@@ -9,6 +10,8 @@ require (
 	github.com/goki/vci v1.0.0
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/sirupsen/logrus v1.7.0 // indirect
+	github.com/traefik/yaegi v0.9.23
+	github.com/yuin/gopher-lua v0.0.0-20191220021717-ab39c6098bdb
 	golang.org/x/arch v0.0.0-20201008161808-52c3e6f60cff // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v2 v2.3.0 // indirect
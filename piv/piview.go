@@ -834,7 +834,7 @@ func (pv *PiView) MonitorOut() {
 	}
 	pv.OutMonRunning = true
 	pv.OutMonMu.Unlock()
-	obuf := giv.OutBuf{}
+	obuf := gide.OutBuf{}
 	fs := &pv.FileState
 	obuf.Init(fs.ParseState.Trace.OutRead, &pv.OutBuf, 0, gide.MarkupCmdOutput)
 	obuf.MonOut()